@@ -0,0 +1,113 @@
+// Package anchor notarizes a channel on an external public chain: an
+// Anchorer periodically signs the hash of the latest block with a hybrid
+// MSP identity and publishes it through the chain's RPC, recording a
+// receipt per anchor. Auditors later fetch the anchors back from the chain
+// and check their signatures and, when they hold the blocks, their hashes.
+package anchor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// domain separates anchor signatures from other uses of the identity
+const domain = "QLANCHOR1"
+
+var (
+	// ErrNotFound is returned when the chain has no transaction for a receipt
+	ErrNotFound = errors.New("anchor transaction not found")
+	// ErrMismatch is returned when the anchor on chain differs from the receipt
+	ErrMismatch = errors.New("anchor on chain does not match the receipt")
+	// ErrInvalidSignature is returned for anchors whose hybrid signature does
+	// not verify
+	ErrInvalidSignature = errors.New("invalid anchor signature")
+	// ErrBlockHashMismatch is returned when an anchored hash differs from the
+	// hash of the local block
+	ErrBlockHashMismatch = errors.New("anchored hash does not match the block")
+)
+
+// Anchor is the statement published on the external chain: the hash of a
+// block of a channel, signed by the creator
+type Anchor struct {
+	Channel   string    `json:"channel"`
+	Number    uint64    `json:"number"`
+	BlockHash []byte    `json:"block_hash"`
+	Time      time.Time `json:"time"`
+	// Creator is the serialized MSP identity of the signer
+	Creator   []byte `json:"creator"`
+	Signature []byte `json:"signature"`
+}
+
+// New signs the hash of block number of channel with id
+func New(id *msp.SigningIdentity, channel string, number uint64, blockHash []byte, now time.Time) (*Anchor, error) {
+	a := &Anchor{
+		Channel:   channel,
+		Number:    number,
+		BlockHash: blockHash,
+		Time:      now.UTC().Truncate(time.Second),
+		Creator:   id.Serialize(),
+	}
+	sig, err := id.Sign(a.signedBytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign anchor of block %d: %w", number, err)
+	}
+	a.Signature = sig
+	return a, nil
+}
+
+// Unmarshal decodes an anchor published on chain
+func Unmarshal(payload []byte) (*Anchor, error) {
+	a := &Anchor{}
+	if err := json.Unmarshal(payload, a); err != nil {
+		return nil, fmt.Errorf("invalid anchor: %w", err)
+	}
+	return a, nil
+}
+
+// Marshal is the payload published on chain
+func (a *Anchor) Marshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// Verify checks the signature of a with an identity accepted by d and
+// returns the signer
+func (a *Anchor) Verify(d *msp.Deserializer) (*msp.Identity, error) {
+	id, err := d.DeserializeIdentity(a.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid anchor creator: %w", err)
+	}
+	digest, err := d.CSP.Hash(a.signedBytes(), &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, err
+	}
+	valid, err := d.CSP.Verify(id.Key, a.Signature, digest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+	return id, nil
+}
+
+// equal reports whether a and b are the same signed anchor
+func (a *Anchor) equal(b *Anchor) bool {
+	return bytes.Equal(a.signedBytes(), b.signedBytes()) && bytes.Equal(a.Signature, b.Signature)
+}
+
+// signedBytes is the encoding covered by the signature
+func (a *Anchor) signedBytes() core.SignedBytes {
+	return core.NewSignedBytes(domain).
+		AppendBytes([]byte(a.Channel)).
+		AppendUint64(a.Number).
+		AppendBytes(a.BlockHash).
+		AppendUint64(uint64(a.Time.Unix())).
+		AppendBytes(a.Creator)
+}
@@ -0,0 +1,202 @@
+package anchor
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+// memoryChain is a Chain keeping transactions in a map
+type memoryChain struct {
+	mutex sync.Mutex
+	txs   map[string][]byte
+}
+
+func (c *memoryChain) Publish(_ context.Context, payload []byte) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.txs == nil {
+		c.txs = map[string][]byte{}
+	}
+	id := fmt.Sprintf("0x%02x", len(c.txs))
+	c.txs[id] = payload
+	return id, nil
+}
+
+func (c *memoryChain) Fetch(_ context.Context, txID string) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	payload, ok := c.txs[txID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return payload, nil
+}
+
+// blockSource serves the block set last
+type blockSource struct {
+	block *fabproto.Block
+}
+
+func (s *blockSource) LatestBlock(context.Context) (*fabproto.Block, error) {
+	return s.block, nil
+}
+
+func setup(t *testing.T) (*msp.SigningIdentity, *orderer.BlockSigner, *msp.Deserializer, *blockverify.Verifier) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	id, err := root.Issue(ca.Request{CommonName: "orderer0.example.com", OrganizationalUnit: "orderer"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(mspDir, root, id, ca.MSPOptions{}))
+
+	signer, err := msp.LoadSigningIdentity(csp, mspDir, "OrdererMSP")
+	require.NoError(t, err)
+	cas, err := msp.LoadCACertificates(mspDir)
+	require.NoError(t, err)
+	sigs, err := orderer.BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": mspDir}}.NewVerifier(csp)
+	require.NoError(t, err)
+	d := &msp.Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"OrdererMSP": cas}}
+	return signer, orderer.NewBlockSigner(signer), d, blockverify.NewVerifier(sigs)
+}
+
+func block(t *testing.T, signer *orderer.BlockSigner, n uint64) *fabproto.Block {
+	data := [][]byte{[]byte(fmt.Sprintf("tx%d", n))}
+	b := &fabproto.Block{
+		Header: &fabproto.BlockHeader{Number: n, DataHash: blockverify.DataHash(data)},
+		Data:   data,
+	}
+	require.NoError(t, signer.SignBlock(b, nil))
+	return b
+}
+
+func TestAnchorAndAudit(t *testing.T) {
+	id, blockSigner, d, verifier := setup(t)
+	chain := &memoryChain{}
+	source := &blockSource{block: block(t, blockSigner, 5)}
+	journal := NewJournal(filepath.Join(t.TempDir(), "anchors.jsonl"))
+	a := &Anchorer{Channel: "mychannel", Source: source, Signer: id, Chain: chain, Journal: journal, Verifier: verifier}
+
+	ctx := context.Background()
+	r, err := a.AnchorLatest(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, uint64(5), r.Anchor.Number)
+
+	// The same block is anchored once
+	r, err = a.AnchorLatest(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	source.block = block(t, blockSigner, 6)
+	_, err = a.AnchorLatest(ctx)
+	require.NoError(t, err)
+
+	receipts, err := ReadJournal(journal.path)
+	require.NoError(t, err)
+	require.Len(t, receipts, 2)
+
+	local := map[uint64][]byte{6: blockverify.HeaderHash(source.block.Header)}
+	auditor := &Auditor{Chain: chain, Deserializer: d, BlockHash: func(n uint64) ([]byte, error) { return local[n], nil }}
+	for _, r := range receipts {
+		signer, err := auditor.Audit(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, "OrdererMSP", signer.MSPID)
+	}
+
+	// A local block differing from the anchored hash
+	local[5] = []byte("other")
+	_, err = auditor.Audit(ctx, receipts[0])
+	assert.True(t, errors.Is(err, ErrBlockHashMismatch), err)
+
+	// A receipt not matching the chain
+	forged := *receipts[1].Anchor
+	forged.Number = 7
+	_, err = auditor.Audit(ctx, &Receipt{TxID: receipts[1].TxID, Anchor: &forged})
+	assert.True(t, errors.Is(err, ErrMismatch), err)
+
+	// An anchor tampered with on chain
+	forgedPayload, err := forged.Marshal()
+	require.NoError(t, err)
+	txID, err := chain.Publish(ctx, forgedPayload)
+	require.NoError(t, err)
+	_, err = auditor.Audit(ctx, &Receipt{TxID: txID})
+	assert.True(t, errors.Is(err, ErrInvalidSignature), err)
+}
+
+func TestAnchorerSkipsUnverifiedBlocks(t *testing.T) {
+	id, blockSigner, _, verifier := setup(t)
+	b := block(t, blockSigner, 1)
+	b.Data = [][]byte{[]byte("forged")}
+	a := &Anchorer{Channel: "mychannel", Source: &blockSource{block: b}, Signer: id, Chain: &memoryChain{}, Verifier: verifier}
+	_, err := a.AnchorLatest(context.Background())
+	assert.True(t, errors.Is(err, blockverify.ErrDataHashMismatch), err)
+}
+
+func TestEthereumRPC(t *testing.T) {
+	txs := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_sendTransaction":
+			var tx map[string]string
+			require.NoError(t, json.Unmarshal(req.Params[0], &tx))
+			assert.Equal(t, "0xfrom", tx["from"])
+			id := "0x" + hex.EncodeToString([]byte{byte(len(txs))})
+			txs[id] = tx["data"]
+			resp["result"] = id
+		case "eth_getTransactionByHash":
+			var id string
+			require.NoError(t, json.Unmarshal(req.Params[0], &id))
+			if input, ok := txs[id]; ok {
+				resp["result"] = map[string]string{"hash": id, "input": input}
+			} else {
+				resp["result"] = nil
+			}
+		default:
+			resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	rpc := &EthereumRPC{URL: srv.URL, From: "0xfrom", To: "0xfrom"}
+	txID, err := rpc.Publish(ctx, []byte("anchor"))
+	require.NoError(t, err)
+	payload, err := rpc.Fetch(ctx, txID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("anchor"), payload)
+
+	_, err = rpc.Fetch(ctx, "0xff")
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+
+	err = rpc.call(ctx, "eth_unknown", nil, new(string))
+	assert.ErrorContains(t, err, "method not found")
+}
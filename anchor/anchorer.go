@@ -0,0 +1,209 @@
+package anchor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// BlockSource returns the latest block of the channel
+type BlockSource interface {
+	LatestBlock(ctx context.Context) (*fabproto.Block, error)
+}
+
+// FileSource reads the latest block from a file kept up to date outside the
+// anchorer, e.g. by `peer channel fetch newest`
+type FileSource string
+
+// LatestBlock reads and decodes the block file
+func (f FileSource) LatestBlock(context.Context) (*fabproto.Block, error) {
+	raw, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, err
+	}
+	return fabproto.UnmarshalBlock(raw)
+}
+
+// Receipt records where an anchor was published
+type Receipt struct {
+	TxID   string  `json:"tx_id"`
+	Anchor *Anchor `json:"anchor"`
+}
+
+// Journal appends receipts to a JSON lines file
+type Journal struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewJournal appends to the file at path, creating it if needed
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Append writes r at the end of the journal
+func (j *Journal) Append(r *Receipt) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal returns the receipts of the journal at path, oldest first
+func ReadJournal(path string) ([]*Receipt, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var receipts []*Receipt
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		r := &Receipt{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		receipts = append(receipts, r)
+	}
+	return receipts, scanner.Err()
+}
+
+// Anchorer publishes the hash of the latest block of a channel
+type Anchorer struct {
+	Channel string
+	Source  BlockSource
+	Signer  *msp.SigningIdentity
+	Chain   Chain
+	// Journal, when set, records a receipt per anchor
+	Journal *Journal
+	// Verifier, when set, checks the orderer signatures of a block before
+	// it is anchored
+	Verifier *blockverify.Verifier
+	// OnError is called by Run for anchoring rounds that failed
+	OnError func(error)
+
+	last *uint64
+}
+
+// AnchorLatest anchors the latest block. It returns a nil receipt when that
+// block was already anchored.
+func (a *Anchorer) AnchorLatest(ctx context.Context) (*Receipt, error) {
+	block, err := a.Source.LatestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latest block: %w", err)
+	}
+	if block.Header == nil {
+		return nil, fmt.Errorf("block has no header")
+	}
+	if a.last != nil && block.Header.Number <= *a.last {
+		return nil, nil
+	}
+	if a.Verifier != nil {
+		if _, err := a.Verifier.VerifyBlock(block); err != nil {
+			return nil, err
+		}
+	}
+
+	anchor, err := New(a.Signer, a.Channel, block.Header.Number, blockverify.HeaderHash(block.Header), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	payload, err := anchor.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	txID, err := a.Chain.Publish(ctx, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish anchor of block %d: %w", anchor.Number, err)
+	}
+	r := &Receipt{TxID: txID, Anchor: anchor}
+	// Published anchors are not repeated even if the journal fails
+	number := anchor.Number
+	a.last = &number
+	if a.Journal != nil {
+		if err := a.Journal.Append(r); err != nil {
+			return r, fmt.Errorf("anchor of block %d published in %s but not journaled: %w", number, txID, err)
+		}
+	}
+	return r, nil
+}
+
+// Run anchors the latest block every interval until ctx is done
+func (a *Anchorer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.AnchorLatest(ctx); err != nil && a.OnError != nil {
+			a.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Auditor checks published anchors against their receipts
+type Auditor struct {
+	Chain        Chain
+	Deserializer *msp.Deserializer
+	// BlockHash, when set, returns the hash of a local copy of block number,
+	// or nil when the block is not available
+	BlockHash func(number uint64) ([]byte, error)
+}
+
+// Audit fetches the anchor of r from the chain, checks that it matches the
+// receipt and is signed by an identity the deserializer accepts, and
+// compares it with the local block when available. It returns the signer.
+func (a *Auditor) Audit(ctx context.Context, r *Receipt) (*msp.Identity, error) {
+	payload, err := a.Chain.Fetch(ctx, r.TxID)
+	if err != nil {
+		return nil, err
+	}
+	onChain, err := Unmarshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.TxID, err)
+	}
+	if r.Anchor != nil && !onChain.equal(r.Anchor) {
+		return nil, fmt.Errorf("%s: %w", r.TxID, ErrMismatch)
+	}
+	id, err := onChain.Verify(a.Deserializer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.TxID, err)
+	}
+	if a.BlockHash != nil {
+		hash, err := a.BlockHash(onChain.Number)
+		if err != nil {
+			return nil, err
+		}
+		if hash != nil && !bytes.Equal(hash, onChain.BlockHash) {
+			return nil, fmt.Errorf("%s: block %d: %w", r.TxID, onChain.Number, ErrBlockHashMismatch)
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,117 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Chain is the external chain anchors are published to
+type Chain interface {
+	// Publish submits payload in a transaction and returns its ID
+	Publish(ctx context.Context, payload []byte) (string, error)
+	// Fetch returns the payload of a transaction published earlier
+	Fetch(ctx context.Context, txID string) ([]byte, error)
+}
+
+// EthereumRPC publishes anchors as the data of transactions sent through
+// an Ethereum JSON-RPC endpoint. From must be an account unlocked on the
+// node (or managed by a signing proxy such as Clef); To is usually From
+// itself or a contract ignoring its calldata.
+type EthereumRPC struct {
+	URL  string
+	From string
+	To   string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+
+	id atomic.Uint64
+}
+
+// Publish sends an eth_sendTransaction carrying payload as calldata
+func (e *EthereumRPC) Publish(ctx context.Context, payload []byte) (string, error) {
+	tx := map[string]string{"from": e.From, "to": e.To, "data": "0x" + hex.EncodeToString(payload)}
+	var txID string
+	if err := e.call(ctx, "eth_sendTransaction", []any{tx}, &txID); err != nil {
+		return "", err
+	}
+	return txID, nil
+}
+
+// Fetch reads the calldata of txID with eth_getTransactionByHash
+func (e *EthereumRPC) Fetch(ctx context.Context, txID string) ([]byte, error) {
+	var tx *struct {
+		Input string `json:"input"`
+	}
+	if err := e.call(ctx, "eth_getTransactionByHash", []any{txID}, &tx); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%s: %w", txID, ErrNotFound)
+	}
+	payload, err := hex.DecodeString(strings.TrimPrefix(tx.Input, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid transaction input: %w", txID, err)
+	}
+	return payload, nil
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// call performs a JSON-RPC 2.0 request and decodes its result into result
+func (e *EthereumRPC) call(ctx context.Context, method string, params []any, result any) error {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      e.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", method, resp.Status)
+	}
+
+	var out struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if out.Error != nil {
+		return fmt.Errorf("%s: %w", method, out.Error)
+	}
+	if len(out.Result) == 0 {
+		return fmt.Errorf("%s: response has no result", method)
+	}
+	return json.Unmarshal(out.Result, result)
+}
@@ -0,0 +1,175 @@
+// Package attestation produces and verifies key attestation reports: a
+// hybrid public key signed by itself and, for peers running in SGX or
+// SEV-SNP, a TEE quote whose report data binds the key to the enclave
+// measurement.
+package attestation
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// ReportVersion is the version of reports created by NewReport
+const ReportVersion = 1
+
+// bindingDomain separates report data bindings from other uses of SHA-512
+const bindingDomain = "QLATTEST"
+
+var (
+	// ErrNoQuote is returned when a quote is required but missing
+	ErrNoQuote = errors.New("attestation report has no TEE quote")
+	// ErrBindingMismatch is returned when the quote does not bind the key
+	ErrBindingMismatch = errors.New("TEE quote report data does not bind the attested key")
+	// ErrUnknownMeasurement is returned for enclaves outside the allowlist
+	ErrUnknownMeasurement = errors.New("enclave measurement not allowed")
+	// ErrNonceMismatch is returned when the report answers another challenge
+	ErrNonceMismatch = errors.New("attestation nonce mismatch")
+)
+
+// Report describes an attested key
+type Report struct {
+	Version   int       `json:"version"`
+	PublicKey []byte    `json:"public_key"`
+	SKI       []byte    `json:"ski"`
+	Created   time.Time `json:"created"`
+	Nonce     []byte    `json:"nonce,omitempty"`
+	Quote     *Quote    `json:"quote,omitempty"`
+}
+
+// SignedReport is a report signed by the attested key, which proves
+// possession of the private key. Report holds the exact signed bytes.
+type SignedReport struct {
+	Report    json.RawMessage `json:"report"`
+	Signature []byte          `json:"signature"`
+}
+
+// Binding is the TEE report data that binds publicKey (composite DER) and
+// the verifier's nonce: SHA-512 of domain, key and nonce
+func Binding(publicKey, nonce []byte) [64]byte {
+	h := sha512.New()
+	h.Write([]byte(bindingDomain))
+	h.Write(publicKey)
+	h.Write(nonce)
+	var out [64]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// NewReport attests key. nonce is the verifier's challenge, if any; when
+// provider is not nil the report embeds a quote binding the key.
+func NewReport(csp bccsp.BCCSP, key bccsp.Key, nonce []byte, provider QuoteProvider) (*SignedReport, error) {
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	der, err := hybrid.MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{
+		Version:   ReportVersion,
+		PublicKey: der,
+		SKI:       key.SKI(),
+		Created:   time.Now().UTC(),
+		Nonce:     nonce,
+	}
+	if provider != nil {
+		if r.Quote, err = provider.Quote(Binding(der, nonce)); err != nil {
+			return nil, fmt.Errorf("failed to obtain TEE quote: %w", err)
+		}
+	}
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := csp.Hash(raw, &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := csp.Sign(key, digest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attestation report: %w", err)
+	}
+	return &SignedReport{Report: raw, Signature: sig}, nil
+}
+
+// VerifyOptions configures Verify
+type VerifyOptions struct {
+	// Nonce, when set, must match the report nonce
+	Nonce []byte
+	// RequireQuote rejects reports without a TEE quote
+	RequireQuote bool
+	// QuoteVerifiers check quotes by Quote.Type
+	QuoteVerifiers map[string]QuoteVerifier
+	// Measurements, when not empty, lists the allowed enclave measurements
+	Measurements [][]byte
+}
+
+// Verify checks the report signature and, if present, its quote. The
+// returned evidence is nil for reports without a quote.
+func Verify(csp bccsp.BCCSP, signed *SignedReport, opts VerifyOptions) (*Report, *Evidence, error) {
+	var r Report
+	if err := json.Unmarshal(signed.Report, &r); err != nil {
+		return nil, nil, fmt.Errorf("invalid attestation report: %w", err)
+	}
+	if r.Version != ReportVersion {
+		return nil, nil, fmt.Errorf("unsupported attestation report version %d", r.Version)
+	}
+	key, err := csp.KeyImport(r.PublicKey, &hybrid.HybridPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid attested key: %w", err)
+	}
+	digest, err := csp.Hash(signed.Report, &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, nil, err
+	}
+	valid, err := csp.Verify(key, signed.Signature, digest, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not verify attestation report: %w", err)
+	}
+	if !valid {
+		return nil, nil, errors.New("invalid attestation report signature")
+	}
+	if opts.Nonce != nil && !bytes.Equal(opts.Nonce, r.Nonce) {
+		return nil, nil, ErrNonceMismatch
+	}
+
+	if r.Quote == nil {
+		if opts.RequireQuote {
+			return nil, nil, ErrNoQuote
+		}
+		return &r, nil, nil
+	}
+	verifier, ok := opts.QuoteVerifiers[r.Quote.Type]
+	if !ok {
+		return nil, nil, fmt.Errorf("no verifier for %s quotes", r.Quote.Type)
+	}
+	evidence, err := verifier.VerifyQuote(r.Quote.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s quote: %w", r.Quote.Type, err)
+	}
+	if evidence.ReportData != Binding(r.PublicKey, r.Nonce) {
+		return nil, nil, ErrBindingMismatch
+	}
+	if len(opts.Measurements) > 0 && !containsMeasurement(opts.Measurements, evidence.Measurement) {
+		return nil, nil, fmt.Errorf("%w: %x", ErrUnknownMeasurement, evidence.Measurement)
+	}
+	return &r, evidence, nil
+}
+
+func containsMeasurement(allowed [][]byte, m []byte) bool {
+	for _, a := range allowed {
+		if bytes.Equal(a, m) {
+			return true
+		}
+	}
+	return false
+}
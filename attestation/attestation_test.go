@@ -0,0 +1,88 @@
+package attestation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// fakeEnclave produces unsigned DCAP v3 quotes for a fixed MRENCLAVE
+type fakeEnclave struct {
+	mrenclave []byte
+}
+
+func (f *fakeEnclave) Quote(reportData [64]byte) (*Quote, error) {
+	q := make([]byte, sgxReportBodyEnd+64)
+	binary.LittleEndian.PutUint16(q, 3)
+	copy(q[sgxMREnclaveOffset:], f.mrenclave)
+	copy(q[sgxReportDataOffset:], reportData[:])
+	return &Quote{Type: QuoteSGX, Data: q}, nil
+}
+
+func TestReportWithQuote(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	mrenclave := bytes.Repeat([]byte{0xAB}, 32)
+	nonce := []byte("challenge")
+	signed, err := NewReport(csp, key, nonce, &fakeEnclave{mrenclave: mrenclave})
+	require.NoError(t, err)
+
+	opts := VerifyOptions{
+		Nonce:          nonce,
+		RequireQuote:   true,
+		QuoteVerifiers: map[string]QuoteVerifier{QuoteSGX: QuoteVerifierFunc(ParseSGXQuote)},
+		Measurements:   [][]byte{mrenclave},
+	}
+	r, evidence, err := Verify(csp, signed, opts)
+	require.NoError(t, err)
+	assert.Equal(t, key.SKI(), r.SKI)
+	assert.Equal(t, mrenclave, evidence.Measurement)
+
+	opts.Measurements = [][]byte{bytes.Repeat([]byte{0xCD}, 32)}
+	_, _, err = Verify(csp, signed, opts)
+	assert.True(t, errors.Is(err, ErrUnknownMeasurement), err)
+
+	opts.Measurements = nil
+	opts.Nonce = []byte("other challenge")
+	_, _, err = Verify(csp, signed, opts)
+	assert.True(t, errors.Is(err, ErrNonceMismatch), err)
+
+	// Reports without a quote are rejected when one is required
+	other, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	otherReport, err := NewReport(csp, other, nonce, nil)
+	require.NoError(t, err)
+	_, _, err = Verify(csp, otherReport, VerifyOptions{RequireQuote: true})
+	assert.True(t, errors.Is(err, ErrNoQuote), err)
+
+	// A quote produced for another key does not transfer
+	q, err := (&fakeEnclave{mrenclave: mrenclave}).Quote(Binding([]byte("someone else"), nonce))
+	require.NoError(t, err)
+	evidence, err = ParseSGXQuote(q.Data)
+	require.NoError(t, err)
+	verifier := QuoteVerifierFunc(func([]byte) (*Evidence, error) { return evidence, nil })
+	_, _, err = Verify(csp, signed, VerifyOptions{QuoteVerifiers: map[string]QuoteVerifier{QuoteSGX: verifier}})
+	assert.True(t, errors.Is(err, ErrBindingMismatch), err)
+}
+
+func TestParseSNPReport(t *testing.T) {
+	report := make([]byte, snpReportSize)
+	copy(report[snpReportDataOffset:], bytes.Repeat([]byte{1}, 64))
+	copy(report[snpMeasurementOffset:], bytes.Repeat([]byte{2}, 48))
+	e, err := ParseSNPReport(report)
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{2}, 48), e.Measurement)
+	assert.Equal(t, byte(1), e.ReportData[63])
+
+	_, err = ParseSNPReport(report[:100])
+	assert.Error(t, err)
+}
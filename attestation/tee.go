@@ -0,0 +1,114 @@
+package attestation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Quote types
+const (
+	// QuoteSGX is an Intel SGX DCAP quote (version 3)
+	QuoteSGX = "sgx-dcap"
+	// QuoteSNP is an AMD SEV-SNP attestation report
+	QuoteSNP = "sev-snp"
+)
+
+// Quote is TEE evidence embedded in a report
+type Quote struct {
+	Type string `json:"type"`
+	Data []byte `json:"data"`
+}
+
+// QuoteProvider obtains a quote over reportData from the TEE the process
+// runs in
+type QuoteProvider interface {
+	Quote(reportData [64]byte) (*Quote, error)
+}
+
+// Evidence is what a verified quote attests
+type Evidence struct {
+	Measurement []byte
+	ReportData  [64]byte
+}
+
+// QuoteVerifier checks the vendor signature chain of a quote (Intel PCS
+// collateral, AMD VCEK) and returns its evidence. Implementations usually
+// wrap the vendor libraries and use ParseSGXQuote or ParseSNPReport for the
+// fields once the signature is verified.
+type QuoteVerifier interface {
+	VerifyQuote(data []byte) (*Evidence, error)
+}
+
+// QuoteVerifierFunc adapts a function to QuoteVerifier
+type QuoteVerifierFunc func(data []byte) (*Evidence, error)
+
+// VerifyQuote implements QuoteVerifier
+func (f QuoteVerifierFunc) VerifyQuote(data []byte) (*Evidence, error) {
+	return f(data)
+}
+
+// SGX DCAP v3 quote layout: 48 byte header followed by the enclave report body
+const (
+	sgxHeaderSize       = 48
+	sgxMREnclaveOffset  = sgxHeaderSize + 64
+	sgxReportDataOffset = sgxHeaderSize + 320
+	sgxReportBodyEnd    = sgxHeaderSize + 384
+)
+
+// ParseSGXQuote extracts MRENCLAVE and REPORTDATA from a DCAP v3 quote. It
+// does not verify the quote signature.
+func ParseSGXQuote(quote []byte) (*Evidence, error) {
+	if len(quote) < sgxReportBodyEnd {
+		return nil, errors.New("SGX quote too short")
+	}
+	if v := binary.LittleEndian.Uint16(quote); v != 3 {
+		return nil, fmt.Errorf("unsupported SGX quote version %d", v)
+	}
+	e := &Evidence{Measurement: append([]byte(nil), quote[sgxMREnclaveOffset:sgxMREnclaveOffset+32]...)}
+	copy(e.ReportData[:], quote[sgxReportDataOffset:sgxReportDataOffset+64])
+	return e, nil
+}
+
+// SEV-SNP attestation report layout (AMD SEV-SNP ABI, table 21)
+const (
+	snpReportDataOffset  = 0x50
+	snpMeasurementOffset = 0x90
+	snpReportSize        = 0x4a0
+)
+
+// ParseSNPReport extracts MEASUREMENT and REPORT_DATA from an SEV-SNP
+// attestation report. It does not verify the report signature.
+func ParseSNPReport(report []byte) (*Evidence, error) {
+	if len(report) < snpReportSize {
+		return nil, errors.New("SEV-SNP report too short")
+	}
+	e := &Evidence{Measurement: append([]byte(nil), report[snpMeasurementOffset:snpMeasurementOffset+48]...)}
+	copy(e.ReportData[:], report[snpReportDataOffset:snpReportDataOffset+64])
+	return e, nil
+}
+
+// GramineQuoteProvider obtains SGX quotes through Gramine's attestation
+// pseudo-files
+type GramineQuoteProvider struct {
+	// Dir defaults to /dev/attestation
+	Dir string
+}
+
+// Quote implements QuoteProvider
+func (p *GramineQuoteProvider) Quote(reportData [64]byte) (*Quote, error) {
+	dir := p.Dir
+	if dir == "" {
+		dir = "/dev/attestation"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user_report_data"), reportData[:], 0); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "quote"))
+	if err != nil {
+		return nil, err
+	}
+	return &Quote{Type: QuoteSGX, Data: data}, nil
+}
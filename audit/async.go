@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrQueueFull is returned by AsyncSink.Emit for events dropped because
+	// the queue is full
+	ErrQueueFull = errors.New("audit queue full")
+	// ErrSinkClosed is returned by AsyncSink.Emit after Close
+	ErrSinkClosed = errors.New("audit sink closed")
+)
+
+// queued is an event, or a flush marker if flushed is not nil
+type queued struct {
+	event   Event
+	flushed chan struct{}
+}
+
+// AsyncSink forwards events to another sink from a background goroutine, so
+// that a slow or unreachable collector never delays the audited operation.
+// The queue is bounded: events that do not fit are dropped and counted.
+type AsyncSink struct {
+	sink    Sink
+	onError func(Event, error)
+
+	mutex  sync.RWMutex
+	closed bool
+	queue  chan queued
+	done   chan struct{}
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewAsyncSink queues up to size events for sink. onError, if not nil, is
+// called from the background goroutine with the events sink rejected.
+func NewAsyncSink(sink Sink, size int, onError func(Event, error)) *AsyncSink {
+	if size <= 0 {
+		size = 1
+	}
+	s := &AsyncSink{sink: sink, onError: onError, queue: make(chan queued, size), done: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+	for q := range s.queue {
+		if q.flushed != nil {
+			close(q.flushed)
+			continue
+		}
+		if err := s.sink.Emit(q.event); err != nil {
+			s.failed.Add(1)
+			if s.onError != nil {
+				s.onError(q.event, err)
+			}
+		}
+	}
+}
+
+// Emit queues e without blocking
+func (s *AsyncSink) Emit(e Event) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+	select {
+	case s.queue <- queued{event: e}:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Flush waits until the events queued so far have been forwarded
+func (s *AsyncSink) Flush() {
+	s.mutex.RLock()
+	if s.closed {
+		s.mutex.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	s.queue <- queued{flushed: flushed}
+	s.mutex.RUnlock()
+	<-flushed
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (s *AsyncSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns the number of events the wrapped sink rejected
+func (s *AsyncSink) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Close forwards the queued events, then closes the wrapped sink
+func (s *AsyncSink) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mutex.Unlock()
+	<-s.done
+	return s.sink.Close()
+}
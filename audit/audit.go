@@ -0,0 +1,110 @@
+// Package audit records crypto operations (key generation, signing,
+// verification) and forwards them to pluggable sinks.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Operation is the audited crypto operation
+type Operation string
+
+const (
+	OpKeyGen Operation = "keygen"
+	OpSign   Operation = "sign"
+	OpVerify Operation = "verify"
+	// OpListKeys, OpReadAudit and OpHandshake are signing daemon calls
+	OpListKeys  Operation = "list_keys"
+	OpReadAudit Operation = "read_audit"
+	OpHandshake Operation = "handshake"
+	// OpKeyGenWrapped generates a key whose private material leaves the
+	// daemon, encrypted to a recipient
+	OpKeyGenWrapped Operation = "keygen_wrapped"
+)
+
+// Outcome is the result of an audited operation
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	// OutcomeInvalid is a verification that completed but rejected the signature
+	OutcomeInvalid Outcome = "invalid"
+	OutcomeFailure Outcome = "failure"
+	// OutcomeDenied is an operation the caller was not authorized to perform
+	OutcomeDenied Outcome = "denied"
+)
+
+// Event describes a single crypto operation
+type Event struct {
+	Time      time.Time `json:"time"`
+	Operation Operation `json:"operation"`
+	Outcome   Outcome   `json:"outcome"`
+	// SKI is the hex encoded subject key identifier, if known
+	SKI    string `json:"ski,omitempty"`
+	Policy string `json:"policy,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Identity is the authenticated caller, for operations requested remotely
+	Identity string `json:"identity,omitempty"`
+}
+
+// Sink receives audit events
+type Sink interface {
+	Emit(Event) error
+	Close() error
+}
+
+// WriterSink writes events as JSON lines to w
+type WriterSink struct {
+	mutex sync.Mutex
+	enc   *json.Encoder
+	w     io.Writer
+}
+
+// NewWriterSink returns a sink writing one JSON object per line to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w), w: w}
+}
+
+// Emit writes e
+func (s *WriterSink) Emit(e Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.enc.Encode(e)
+}
+
+// Close closes the underlying writer if it is an io.Closer
+func (s *WriterSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// MultiSink fans events out to several sinks
+type MultiSink []Sink
+
+// Emit forwards e to every sink, even if some fail
+func (m MultiSink) Emit(e Event) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Emit(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (m MultiSink) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+var testEvent = Event{
+	Time:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	Operation: OpVerify,
+	Outcome:   OutcomeFailure,
+	SKI:       "abcd",
+	Policy:    "AND",
+	Error:     `bad "sig" ]`,
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewWriterSink(&buf).Emit(testEvent))
+
+	var got Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, testEvent, got)
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString(']')
+		received <- line
+	}()
+
+	s, err := NewSyslogSink(SyslogConfig{Network: "tcp", Address: ln.Addr().String(), AppName: "qltest", Hostname: "host"})
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.Emit(testEvent))
+
+	msg := <-received
+	// octet-counting prefix, then PRI authpriv.warning = 10*8+4
+	assert.Regexp(t, `^\d+ <84>1 2024-05-01T12:00:00Z host qltest \d+ verify \[qlaudit@32473`, msg)
+
+	full := s.format(testEvent)
+	assert.Contains(t, full, `error="bad \"sig\" \]"`)
+	assert.True(t, strings.HasSuffix(full, "] verify failure"))
+}
+
+// brokerTLS returns a server configuration with a self-signed certificate
+// for 127.0.0.1 and a client configuration trusting it
+func brokerTLS(t *testing.T) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "kafka"},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		&tls.Config{RootCAs: roots}
+}
+
+func TestKafkaSink(t *testing.T) {
+	serverTLS, clientTLS := brokerTLS(t)
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "audit"), kfake.TLS(serverTLS))
+	require.NoError(t, err)
+	defer cluster.Close()
+	brokers := cluster.ListenAddrs()
+
+	_, err = NewKafkaSink(KafkaConfig{Topic: "audit"})
+	assert.ErrorContains(t, err, "broker")
+	_, err = NewKafkaSink(KafkaConfig{Brokers: brokers})
+	assert.ErrorContains(t, err, "topic")
+	// The broker only speaks TLS
+	_, err = NewKafkaSink(KafkaConfig{Brokers: brokers, Topic: "audit", Timeout: 500 * time.Millisecond})
+	assert.ErrorContains(t, err, "failed to connect")
+
+	s, err := NewKafkaSink(KafkaConfig{Brokers: brokers, Topic: "audit", TLS: clientTLS})
+	require.NoError(t, err)
+	require.NoError(t, s.Emit(testEvent))
+	anonymous := testEvent
+	anonymous.SKI = ""
+	require.NoError(t, s.Emit(anonymous))
+	require.NoError(t, s.Close())
+
+	consumer, err := kgo.NewClient(kgo.SeedBrokers(brokers...), kgo.DialTLSConfig(clientTLS),
+		kgo.ConsumeTopics("audit"), kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()))
+	require.NoError(t, err)
+	defer consumer.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < 2 && ctx.Err() == nil {
+		records = append(records, consumer.PollFetches(ctx).Records()...)
+	}
+	require.Len(t, records, 2)
+
+	// Records are keyed by SKI
+	assert.Equal(t, "abcd", string(records[0].Key))
+	assert.Nil(t, records[1].Key)
+	var got Event
+	require.NoError(t, json.Unmarshal(records[0].Value, &got))
+	assert.Equal(t, testEvent, got)
+}
+
+// blockingSink records events once release is closed
+type blockingSink struct {
+	*MemorySink
+	release chan struct{}
+	closed  bool
+}
+
+func (s *blockingSink) Emit(e Event) error {
+	<-s.release
+	if e.Operation == OpKeyGen {
+		return errors.New("rejected")
+	}
+	return s.MemorySink.Emit(e)
+}
+
+func (s *blockingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestAsyncSink(t *testing.T) {
+	inner := &blockingSink{MemorySink: NewMemorySink(10), release: make(chan struct{})}
+	var rejected []Event
+	s := NewAsyncSink(inner, 2, func(e Event, err error) { rejected = append(rejected, e) })
+
+	// The first event is taken by the background goroutine, two more fill
+	// the queue and the fourth one is dropped without blocking
+	require.NoError(t, s.Emit(Event{Operation: OpSign}))
+	require.Eventually(t, func() bool { return len(s.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, s.Emit(Event{Operation: OpVerify}))
+	require.NoError(t, s.Emit(Event{Operation: OpKeyGen}))
+	assert.ErrorIs(t, s.Emit(Event{Operation: OpVerify}), ErrQueueFull)
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	close(inner.release)
+	s.Flush()
+	events := inner.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, OpSign, events[0].Operation)
+	assert.Equal(t, OpVerify, events[1].Operation)
+	assert.Equal(t, uint64(1), s.Failed())
+	require.Len(t, rejected, 1)
+	assert.Equal(t, OpKeyGen, rejected[0].Operation)
+
+	require.NoError(t, s.Emit(Event{Operation: OpSign}))
+	require.NoError(t, s.Close())
+	assert.True(t, inner.closed)
+	assert.Len(t, inner.Events(), 3)
+	assert.ErrorIs(t, s.Emit(Event{Operation: OpSign}), ErrSinkClosed)
+	s.Flush()
+}
+
+func TestMemorySink(t *testing.T) {
+	s := NewMemorySink(2)
+	assert.Empty(t, s.Events())
+	for _, op := range []Operation{OpKeyGen, OpSign, OpVerify} {
+		require.NoError(t, s.Emit(Event{Operation: op}))
+	}
+	events := s.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, OpSign, events[0].Operation)
+	assert.Equal(t, OpVerify, events[1].Operation)
+}
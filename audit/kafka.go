@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// KafkaConfig configures a KafkaSink
+type KafkaConfig struct {
+	// Brokers are the seed brokers, as host:port
+	Brokers []string
+	Topic   string
+	// TLS, if not nil, encrypts the connections to the brokers
+	TLS *tls.Config
+	// ClientID defaults to the executable name
+	ClientID string
+	// Timeout bounds the connection check and the delivery of each event,
+	// 5s by default
+	Timeout time.Duration
+}
+
+// KafkaSink publishes events as JSON records keyed by SKI, so the events of
+// a key land on the same partition in order. Each Emit waits for the
+// brokers to acknowledge the record; wrap the sink in an AsyncSink so that
+// audited operations do not.
+type KafkaSink struct {
+	client  *kgo.Client
+	topic   string
+	timeout time.Duration
+}
+
+// NewKafkaSink connects to the brokers
+func NewKafkaSink(config KafkaConfig) (*KafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("kafka audit sink needs at least one broker")
+	}
+	if config.Topic == "" {
+		return nil, errors.New("kafka audit sink needs a topic")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.ClientID == "" {
+		config.ClientID = "quantum-ledger"
+		if len(os.Args) > 0 {
+			config.ClientID = baseName(os.Args[0])
+		}
+	}
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.DefaultProduceTopic(config.Topic),
+		kgo.ClientID(config.ClientID),
+		kgo.ProduceRequestTimeout(config.Timeout),
+	}
+	if config.TLS != nil {
+		opts = append(opts, kgo.DialTLSConfig(config.TLS.Clone()))
+	}
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka configuration: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to kafka at %v: %w", config.Brokers, err)
+	}
+	return &KafkaSink{client: client, topic: config.Topic, timeout: config.Timeout}, nil
+}
+
+// Emit publishes e and waits for its acknowledgement
+func (s *KafkaSink) Emit(e Event) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	record := &kgo.Record{Value: value}
+	if e.SKI != "" {
+		record.Key = []byte(e.SKI)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if err := s.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return fmt.Errorf("failed to publish audit event to %s: %w", s.topic, err)
+	}
+	return nil
+}
+
+// Close flushes the records still buffered and disconnects
+func (s *KafkaSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	err := s.client.Flush(ctx)
+	s.client.Close()
+	return err
+}
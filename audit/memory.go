@@ -0,0 +1,47 @@
+package audit
+
+import "sync"
+
+// MemorySink keeps the most recent events in memory, e.g. to serve them to
+// auditors over an API
+type MemorySink struct {
+	mutex  sync.Mutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// NewMemorySink returns a sink retaining the last size events
+func NewMemorySink(size int) *MemorySink {
+	if size < 1 {
+		size = 1
+	}
+	return &MemorySink{events: make([]Event, size)}
+}
+
+// Emit records e, dropping the oldest event when the sink is full
+func (s *MemorySink) Emit(e Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events[s.next] = e
+	s.next = (s.next + 1) % len(s.events)
+	if s.next == 0 {
+		s.full = true
+	}
+	return nil
+}
+
+// Events returns the retained events, oldest first
+func (s *MemorySink) Events() []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.full {
+		return append([]Event(nil), s.events[:s.next]...)
+	}
+	return append(append([]Event(nil), s.events[s.next:]...), s.events[:s.next]...)
+}
+
+// Close does nothing
+func (s *MemorySink) Close() error {
+	return nil
+}
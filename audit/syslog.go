@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Syslog facilities (RFC 5424 section 6.2.1)
+const (
+	FacilityAuth     = 4
+	FacilityAuthPriv = 10
+	FacilityLocal0   = 16
+)
+
+// Syslog severities used for audit events
+const (
+	severityWarning = 4
+	severityInfo    = 6
+)
+
+// sdID is the structured data element carrying the event fields
+const sdID = "qlaudit@32473"
+
+// SyslogConfig configures a SyslogSink
+type SyslogConfig struct {
+	// Network is "udp", "tcp" or "unix"
+	Network string
+	Address string
+	// Facility defaults to FacilityAuthPriv
+	Facility int
+	// AppName defaults to the executable name
+	AppName  string
+	Hostname string
+}
+
+// SyslogSink sends events as RFC 5424 messages. Stream transports use
+// octet-counting framing (RFC 6587).
+type SyslogSink struct {
+	mutex  sync.Mutex
+	config SyslogConfig
+	conn   net.Conn
+	procID string
+}
+
+// NewSyslogSink connects to the syslog collector
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	if config.Facility == 0 {
+		config.Facility = FacilityAuthPriv
+	}
+	if config.AppName == "" {
+		config.AppName = "quantum-ledger"
+		if len(os.Args) > 0 {
+			config.AppName = baseName(os.Args[0])
+		}
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	s := &SyslogSink{config: config, procID: fmt.Sprint(os.Getpid())}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) connect() error {
+	conn, err := net.Dial(s.config.Network, s.config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", s.config.Address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Emit sends e, reconnecting once if the connection was dropped
+func (s *SyslogSink) Emit(e Event) error {
+	msg := s.format(e)
+	if s.config.Network != "udp" && s.config.Network != "unixgram" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		if err := s.connect(); err != nil {
+			return err
+		}
+		if _, err := s.conn.Write([]byte(msg)); err != nil {
+			return fmt.Errorf("failed to write syslog message: %w", err)
+		}
+	}
+	return nil
+}
+
+// format renders e as an RFC 5424 message
+func (s *SyslogSink) format(e Event) string {
+	severity := severityInfo
+	if e.Outcome != OutcomeSuccess {
+		severity = severityWarning
+	}
+	ts := e.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[" + sdID)
+	param := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sd, ` %s="%s"`, name, sdEscape(value))
+		}
+	}
+	param("outcome", string(e.Outcome))
+	param("ski", e.SKI)
+	param("policy", e.Policy)
+	param("error", e.Error)
+	param("identity", e.Identity)
+	sd.WriteString("]")
+
+	msg := fmt.Sprintf("%s %s", e.Operation, e.Outcome)
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		s.config.Facility*8+severity,
+		ts.UTC().Format(time.RFC3339Nano),
+		nilValue(s.config.Hostname),
+		nilValue(s.config.AppName),
+		nilValue(s.procID),
+		nilValue(string(e.Operation)),
+		sd.String(),
+		msg,
+	)
+}
+
+// Close closes the connection
+func (s *SyslogSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// sdEscape escapes '"', '\' and ']' in structured data parameter values
+func sdEscape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+func nilValue(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(v, " ", "_")
+}
+
+func baseName(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
@@ -0,0 +1,36 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/audit"
+)
+
+// emitAudit queues a crypto operation for the audit sink, if any. Neither a
+// full queue nor sink failures fail the operation; they are counted instead.
+func (h *HybridBCCSP) emitAudit(op audit.Operation, k bccsp.Key, policy string, valid bool, err error) {
+	if h.auditSink == nil {
+		return
+	}
+	e := audit.Event{
+		Time:      time.Now().UTC(),
+		Operation: op,
+		Outcome:   audit.OutcomeSuccess,
+		Policy:    policy,
+	}
+	if !isNilKey(k) {
+		e.SKI = hex.EncodeToString(k.SKI())
+	}
+	switch {
+	case err != nil:
+		e.Outcome = audit.OutcomeFailure
+		e.Error = err.Error()
+	case !valid:
+		e.Outcome = audit.OutcomeInvalid
+	}
+	if err := h.auditSink.Emit(e); err != nil {
+		h.metrics.AuditEventsDropped.With("operation", string(op)).Add(1)
+	}
+}
@@ -0,0 +1,56 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/audit"
+)
+
+type recordingSink struct {
+	events []audit.Event
+}
+
+func (s *recordingSink) Emit(e audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestAuditEvents(t *testing.T) {
+	sink := &recordingSink{}
+	csp, err := New(WithAuditSink(sink))
+	require.NoError(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("audited"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	other := sha256.Sum256([]byte("tampered"))
+	valid, err := csp.Verify(k, sig, other[:], nil)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	_, err = csp.Verify(k, []byte{0xff}, digest[:], nil)
+	require.Error(t, err)
+
+	// Events reach the sink in the background
+	require.NoError(t, csp.(*HybridBCCSP).Close())
+	require.Len(t, sink.events, 4)
+	ski := hex.EncodeToString(k.SKI())
+	assert.Equal(t, audit.OpKeyGen, sink.events[0].Operation)
+	assert.Equal(t, ski, sink.events[0].SKI)
+	assert.Equal(t, audit.OpSign, sink.events[1].Operation)
+	assert.Equal(t, audit.OutcomeSuccess, sink.events[1].Outcome)
+	assert.Equal(t, audit.OutcomeInvalid, sink.events[2].Outcome)
+	assert.Equal(t, "AND", sink.events[2].Policy)
+	assert.Equal(t, audit.OutcomeFailure, sink.events[3].Outcome)
+	assert.NotEmpty(t, sink.events[3].Error)
+}
@@ -0,0 +1,187 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen matches every *CircuitOpenError via errors.Is
+	ErrCircuitOpen = errors.New("PQC backend circuit breaker is open")
+	// ErrBackendBusy is returned when the PQC backend already runs the
+	// maximum number of concurrent calls
+	ErrBackendBusy = errors.New("PQC backend is busy")
+)
+
+// CircuitOpenError is returned instead of calling the PQC backend while the
+// breaker is open
+type CircuitOpenError struct {
+	// RetryAfter is the time left before a probe call is let through
+	RetryAfter time.Duration
+	// Cause is the backend error that opened the breaker
+	Cause error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("PQC backend circuit breaker is open, retry after %s: %v", e.RetryAfter, e.Cause)
+}
+
+// Is makes errors.Is(err, ErrCircuitOpen) succeed
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every call fast until the cooldown elapses
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe call through
+	BreakerHalfOpen
+)
+
+var breakerStateNames = map[BreakerState]string{
+	BreakerClosed:   "closed",
+	BreakerOpen:     "open",
+	BreakerHalfOpen: "half-open",
+}
+
+func (s BreakerState) String() string {
+	if name, ok := breakerStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerConfig configures a CircuitBreaker
+type BreakerConfig struct {
+	// Failures is the number of consecutive backend errors opening the breaker
+	Failures int
+	// Cooldown is how long the breaker stays open before probing the backend
+	Cooldown time.Duration
+	// MaxInFlight caps concurrent backend calls, zero means no cap
+	MaxInFlight int
+}
+
+// CircuitBreaker guards the liboqs backend: after Failures consecutive
+// errors, e.g. following a bad library upgrade, calls fail fast with a
+// *CircuitOpenError instead of piling up, then a single probe decides
+// whether the backend recovered
+type CircuitBreaker struct {
+	mutex    sync.Mutex
+	config   BreakerConfig
+	state    BreakerState
+	failures int
+	cause    error
+	openedAt time.Time
+	inFlight int
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a closed breaker. Failures below 1 count as 1.
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	if config.Failures < 1 {
+		config.Failures = 1
+	}
+	return &CircuitBreaker{config: config, now: time.Now}
+}
+
+// State returns the current state, moving an open breaker whose cooldown
+// elapsed to half-open
+func (b *CircuitBreaker) State() BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.config.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Call runs fn unless the breaker is open or the backend is busy, and
+// records its outcome
+func (b *CircuitBreaker) Call(fn func() error) error {
+	_, err := b.call(fn)
+	return err
+}
+
+// call is Call, also reporting whether fn's failure opened the breaker
+func (b *CircuitBreaker) call(fn func() error) (bool, error) {
+	probe, err := b.acquire()
+	if err != nil {
+		return false, err
+	}
+	err = fn()
+	return b.release(probe, err), err
+}
+
+func (b *CircuitBreaker) acquire() (bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	probe := false
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.config.Cooldown - b.now().Sub(b.openedAt); wait > 0 {
+			return false, &CircuitOpenError{RetryAfter: wait, Cause: b.cause}
+		}
+		b.state, probe = BreakerHalfOpen, true
+	case BreakerHalfOpen:
+		// Only the probe goes through until it completes
+		return false, &CircuitOpenError{Cause: b.cause}
+	}
+	if b.config.MaxInFlight > 0 && b.inFlight >= b.config.MaxInFlight {
+		if probe {
+			b.state = BreakerOpen
+		}
+		return false, ErrBackendBusy
+	}
+	b.inFlight++
+	return probe, nil
+}
+
+func (b *CircuitBreaker) release(probe bool, err error) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.inFlight--
+
+	if err == nil {
+		b.state, b.failures, b.cause = BreakerClosed, 0, nil
+		return false
+	}
+	b.failures++
+	if !probe && (b.state != BreakerClosed || b.failures < b.config.Failures) {
+		return false
+	}
+	b.state, b.cause, b.openedAt = BreakerOpen, err, b.now()
+	return true
+}
+
+// WithCircuitBreaker routes ML-DSA signing and verification through b
+func WithCircuitBreaker(b *CircuitBreaker) Option {
+	return func(h *HybridBCCSP) {
+		h.breaker = b
+	}
+}
+
+// pqcCall runs a liboqs call through the circuit breaker, if any, and
+// reports the breaker state
+func (h *HybridBCCSP) pqcCall(fn func() error) error {
+	if h.breaker == nil {
+		return fn()
+	}
+	tripped, err := h.breaker.call(fn)
+	if tripped {
+		h.metrics.PQCBreakerTrips.Add(1)
+	}
+	degraded := 0.0
+	if h.breaker.State() != BreakerClosed {
+		degraded = 1
+	}
+	h.metrics.PQCBackendDegraded.Set(degraded)
+	return err
+}
@@ -0,0 +1,115 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(BreakerConfig{Failures: 2, Cooldown: time.Second})
+	b.now = func() time.Time { return now }
+
+	backendErr := errors.New("liboqs: OQS_SIG_new failed")
+	fail := func() error { return backendErr }
+	ok := func() error { return nil }
+
+	require.ErrorIs(t, b.Call(fail), backendErr)
+	require.NoError(t, b.Call(ok))
+	// Failures must be consecutive
+	require.ErrorIs(t, b.Call(fail), backendErr)
+	assert.Equal(t, BreakerClosed, b.State())
+	require.ErrorIs(t, b.Call(fail), backendErr)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	called := false
+	err := b.Call(func() error { called = true; return nil })
+	assert.False(t, called)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, time.Second, openErr.RetryAfter)
+	assert.Equal(t, backendErr, openErr.Cause)
+
+	// A failed probe opens the breaker again
+	now = now.Add(time.Second)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	require.ErrorIs(t, b.Call(fail), backendErr)
+	require.ErrorIs(t, b.Call(ok), ErrCircuitOpen)
+
+	now = now.Add(time.Second)
+	require.NoError(t, b.Call(ok))
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreakerMaxInFlight(t *testing.T) {
+	b := NewCircuitBreaker(BreakerConfig{Failures: 1, MaxInFlight: 1})
+	var inner error
+	require.NoError(t, b.Call(func() error {
+		inner = b.Call(func() error { return nil })
+		return nil
+	}))
+	require.ErrorIs(t, inner, ErrBackendBusy)
+	// Rejections are not backend failures
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestVerifyCircuitBreaker(t *testing.T) {
+	gauge := &metricsfakes.Gauge{}
+	gauge.WithReturns(gauge)
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider := &metricsfakes.Provider{}
+	provider.NewGaugeStub = func(o metrics.GaugeOpts) metrics.Gauge {
+		if o.Name == pqcBackendDegradedOpts.Name {
+			return gauge
+		}
+		return &metricsfakes.Gauge{}
+	}
+	provider.NewCounterStub = func(o metrics.CounterOpts) metrics.Counter {
+		if o.Name == pqcBreakerTripsOpts.Name {
+			return counter
+		}
+		c := &metricsfakes.Counter{}
+		c.WithReturns(c)
+		return c
+	}
+
+	csp, err := New(
+		WithCircuitBreaker(NewCircuitBreaker(BreakerConfig{Failures: 2, Cooldown: time.Hour})),
+		WithMetricsProvider(provider),
+		WithPolicy(PolicyPQC),
+	)
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("breaker"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	// A truncated ML-DSA public key makes the backend fail
+	key := k.(*hybridKey)
+	broken := &hybridKey{ecdsaKey: key.ecdsaKey, pqcPub: key.pqcPub[:10]}
+	for i := 0; i < 2; i++ {
+		_, err = csp.Verify(broken, sig, digest[:], nil)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	_, err = csp.Verify(k, sig, digest[:], nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// Sign, two failed verifications, the rejected one
+	require.Equal(t, 4, gauge.SetCallCount())
+	assert.Equal(t, 0.0, gauge.SetArgsForCall(1))
+	assert.Equal(t, 1.0, gauge.SetArgsForCall(2))
+	assert.Equal(t, 1.0, gauge.SetArgsForCall(3))
+	assert.Equal(t, 1, counter.AddCallCount())
+}
@@ -0,0 +1,93 @@
+package hybrid
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// CanaryDiscrepancy is a PQC verification on which the default backend,
+// core.PQCBackend, and the canary backend, core.CanaryBackend, disagree
+type CanaryDiscrepancy struct {
+	// SKI identifies the hybrid key
+	SKI       []byte
+	PublicKey []byte
+	// Message is the message of the PQC signature, the digest or the
+	// framed message
+	Message     []byte
+	Signature   []byte
+	Valid       bool
+	Err         error
+	CanaryValid bool
+	CanaryErr   error
+}
+
+func (d *CanaryDiscrepancy) String() string {
+	return fmt.Sprintf("PQC backends disagree on a signature of key %s: %s %s, %s %s",
+		skiLabel(d.SKI), core.PQCBackend, canaryOutcome(d.Valid, d.Err), core.CanaryBackend, canaryOutcome(d.CanaryValid, d.CanaryErr))
+}
+
+func canaryOutcome(valid bool, err error) string {
+	switch {
+	case err != nil:
+		return fmt.Sprintf("failed (%v)", err)
+	case valid:
+		return "valid"
+	}
+	return "invalid"
+}
+
+// verifyPQCCanary is the canary backend, swapped in tests for one that
+// disagrees
+var verifyPQCCanary = core.VerifyPQCCanary
+
+// CanaryStats counts the verifications repeated with the canary backend
+type CanaryStats struct {
+	Checks        uint64
+	Discrepancies uint64
+}
+
+// canary is the state of WithCanary
+type canary struct {
+	onDiscrepancy                func(*CanaryDiscrepancy)
+	checkCount, discrepancyCount atomic.Uint64
+}
+
+// WithCanary verifies every PQC signature a second time with
+// core.CanaryBackend, crypto/mldsa next to liboqs, and reports the results
+// that differ through bccsp_hybrid_pqc_canary_verifications and
+// onDiscrepancy, if not nil, e.g. to log or page. It gives confidence
+// before switching the default backend in production. Verify takes twice
+// as long, the verify timeout included; the result of the default backend
+// is returned either way. New fails in builds without a canary backend.
+func WithCanary(onDiscrepancy func(*CanaryDiscrepancy)) Option {
+	return func(h *HybridBCCSP) {
+		h.canary = &canary{onDiscrepancy: onDiscrepancy}
+	}
+}
+
+// compareCanary verifies with the canary backend the signature the default
+// one found valid or not, or failed on with err
+func (h *HybridBCCSP) compareCanary(key *hybridKey, msg, signature []byte, valid bool, err error) {
+	canaryValid, canaryErr := verifyPQCCanary(key.pqcPub, msg, signature)
+	h.canary.checkCount.Add(1)
+	if canaryValid == valid && (canaryErr == nil) == (err == nil) {
+		h.metrics.PQCCanaryVerifications.With("result", "agree").Add(1)
+		return
+	}
+	h.canary.discrepancyCount.Add(1)
+	h.metrics.PQCCanaryVerifications.With("result", "discrepancy").Add(1)
+	if h.canary.onDiscrepancy != nil {
+		h.canary.onDiscrepancy(&CanaryDiscrepancy{
+			SKI:         skiOf(key),
+			PublicKey:   key.pqcPub,
+			Message:     msg,
+			Signature:   signature,
+			Valid:       valid,
+			Err:         err,
+			CanaryValid: canaryValid,
+			CanaryErr:   canaryErr,
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestCanary(t *testing.T) {
+	if core.CanaryBackend == "" {
+		_, err := New(WithCanary(nil))
+		assert.ErrorIs(t, err, core.ErrNoCanaryBackend)
+		return
+	}
+	var discrepancies []*CanaryDiscrepancy
+	csp, err := New(WithCanary(func(d *CanaryDiscrepancy) { discrepancies = append(discrepancies, d) }))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("canary"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	valid, err := csp.Verify(k, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, CanaryStats{Checks: 1}, csp.(StatsProvider).Stats().Canary)
+	assert.Empty(t, discrepancies)
+
+	// The default backend decides, the canary only reports
+	defer func(v func(publicKey, msg, sig []byte) (bool, error)) { verifyPQCCanary = v }(verifyPQCCanary)
+	verifyPQCCanary = func(publicKey, msg, sig []byte) (bool, error) { return false, nil }
+	valid, err = csp.Verify(k, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, CanaryStats{Checks: 2, Discrepancies: 1}, csp.(StatsProvider).Stats().Canary)
+	require.Len(t, discrepancies, 1)
+	assert.True(t, discrepancies[0].Valid)
+	assert.False(t, discrepancies[0].CanaryValid)
+	assert.Equal(t, k.SKI(), discrepancies[0].SKI)
+	assert.Contains(t, discrepancies[0].String(), core.PQCBackend+" valid, go invalid")
+}
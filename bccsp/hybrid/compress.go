@@ -0,0 +1,59 @@
+package hybrid
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// noCompression is CompressionNone. It never detects anything: data no
+// compressor claims is read as it is.
+type noCompression struct{}
+
+func (noCompression) AppendCompress(dst, src []byte) ([]byte, error) {
+	return append(dst, src...), nil
+}
+
+func (noCompression) Decompress(src []byte, max int) ([]byte, error) {
+	return src, nil
+}
+
+func (noCompression) Detect([]byte) bool {
+	return false
+}
+
+// zstdMagic starts every zstd frame
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll and
+// DecodeAll calls. Single segment frames always record their size.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithSingleSegment(true))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecodeAllCapLimit(true))
+)
+
+// zstdCompressor is CompressionZstd. ML-DSA signatures are close to random
+// and barely compress; the DER and COSE framing around them does.
+type zstdCompressor struct{}
+
+func (zstdCompressor) AppendCompress(dst, src []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(src, dst), nil
+}
+
+// Decompress reads the size from the frame header, so a small frame cannot
+// expand past max; frames that do not record it are rejected
+func (zstdCompressor) Decompress(src []byte, max int) ([]byte, error) {
+	var h zstd.Header
+	if err := h.Decode(src); err != nil {
+		return nil, err
+	}
+	if !h.HasFCS || h.FrameContentSize > uint64(max) {
+		return nil, fmt.Errorf("frame content size missing or over %d bytes", max)
+	}
+	return zstdDecoder.DecodeAll(src, make([]byte, 0, h.FrameContentSize))
+}
+
+func (zstdCompressor) Detect(data []byte) bool {
+	return bytes.HasPrefix(data, zstdMagic)
+}
@@ -0,0 +1,201 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"gopkg.in/yaml.v3"
+)
+
+// HashNone disables the digest length check of DigestPolicy
+const HashNone = "none"
+
+// ProviderConfig is the YAML form of the provider options, so that a node's
+// settings can be checked before it signs anything. Empty fields keep the
+// defaults of New.
+type ProviderConfig struct {
+	// Algorithm is the ML-DSA parameter set, which must be the one of the
+	// compiled backend
+	Algorithm string `yaml:"algorithm,omitempty"`
+	// Policy is AND, OR, CLASSICAL or PQC
+	Policy string `yaml:"policy,omitempty"`
+	// Hash names the hash of the digests, e.g. SHA-256 or SHA3-256, or none
+	Hash      string `yaml:"hash,omitempty"`
+	PureMLDSA bool   `yaml:"pure_mldsa,omitempty"`
+	// EnvelopeFormat is standard or little-endian
+	EnvelopeFormat string `yaml:"envelope_format,omitempty"`
+	// Encoding and Compression name a registered encoder and compressor
+	Encoding            string        `yaml:"encoding,omitempty"`
+	Compression         string        `yaml:"compression,omitempty"`
+	DowngradeProtection *bool         `yaml:"downgrade_protection,omitempty"`
+	LegacyECDSA         bool          `yaml:"legacy_ecdsa,omitempty"`
+	MaxSignatures       uint64        `yaml:"max_signatures,omitempty"`
+	VerifyTimeout       time.Duration `yaml:"verify_timeout,omitempty"`
+	// Canary verifies PQC signatures with the canary backend too, see
+	// WithCanary
+	Canary bool `yaml:"canary,omitempty"`
+	// MemoryLocking locks secret keys in memory, see WithMemoryLocking
+	MemoryLocking bool `yaml:"memory_locking,omitempty"`
+}
+
+// LoadProviderConfig reads a ProviderConfig YAML file, e.g.
+//
+//	algorithm: ML-DSA-65
+//	policy: AND
+//	hash: SHA-256
+//	compression: zstd
+//	verify_timeout: 50ms
+//
+// Unknown keys are errors, a misspelled key would otherwise be ignored.
+func LoadProviderConfig(path string) (*ProviderConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider config: %w", err)
+	}
+	c := &ProviderConfig{}
+	if err := DecodeYAMLStrict(raw, c); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config %s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// DecodeYAMLStrict decodes raw into v, failing on keys v has no field for.
+// An empty document leaves v unchanged.
+func DecodeYAMLStrict(raw []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate resolves every name against the compiled backend and the
+// registered encoders and compressors, and reports all the problems found
+func (c *ProviderConfig) Validate() error {
+	var problems []error
+	if c.Algorithm != "" && c.Algorithm != PQCAlgorithm {
+		if _, known := core.PQCAlgorithmOID(c.Algorithm); known {
+			problems = append(problems, fmt.Errorf("algorithm: %s is not supported by the %s backend, which implements %s", c.Algorithm, core.PQCBackend, PQCAlgorithm))
+		} else {
+			problems = append(problems, fmt.Errorf("algorithm: unknown algorithm %q, expected %s", c.Algorithm, PQCAlgorithm))
+		}
+	}
+	if c.Policy != "" {
+		if _, err := ParsePolicy(c.Policy); err != nil {
+			problems = append(problems, fmt.Errorf("policy: %w", err))
+		}
+	}
+	if _, err := c.hash(); err != nil {
+		problems = append(problems, fmt.Errorf("hash: %w", err))
+	}
+	if c.PureMLDSA && c.Hash == HashNone {
+		problems = append(problems, errors.New("pure_mldsa: requires a hash to check messages against their digest"))
+	}
+	if c.EnvelopeFormat != "" {
+		if _, err := ParseEnvelopeFormat(c.EnvelopeFormat); err != nil {
+			problems = append(problems, fmt.Errorf("envelope_format: %w", err))
+		}
+	}
+	if c.Encoding != "" && !slices.Contains(EnvelopeEncoders(), c.Encoding) {
+		problems = append(problems, fmt.Errorf("encoding: unknown envelope encoder %q, expected one of %v", c.Encoding, EnvelopeEncoders()))
+	}
+	if c.Compression != "" && !slices.Contains(Compressors(), c.Compression) {
+		problems = append(problems, fmt.Errorf("compression: unknown compressor %q, expected one of %v", c.Compression, Compressors()))
+	}
+	if c.Canary && core.CanaryBackend == "" {
+		problems = append(problems, fmt.Errorf("canary: %w", core.ErrNoCanaryBackend))
+	}
+	if c.VerifyTimeout < 0 {
+		problems = append(problems, fmt.Errorf("verify_timeout: negative timeout %s", c.VerifyTimeout))
+	}
+	return errors.Join(problems...)
+}
+
+// hash resolves the Hash name, SHA-256 if empty
+func (c *ProviderConfig) hash() (crypto.Hash, error) {
+	switch c.Hash {
+	case "":
+		return crypto.SHA256, nil
+	case HashNone:
+		return 0, nil
+	}
+	for h := crypto.MD5; h <= crypto.BLAKE2b_512; h++ {
+		if strings.EqualFold(h.String(), c.Hash) {
+			if !h.Available() {
+				return 0, fmt.Errorf("hash %s is not linked into the binary", h)
+			}
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hash %q", c.Hash)
+}
+
+// Effective returns the configuration New applies: defaults filled in and
+// names in their canonical spelling. c must be valid.
+func (c *ProviderConfig) Effective() ProviderConfig {
+	e := *c
+	e.Algorithm = PQCAlgorithm
+	policy := PolicyHybridAND
+	if c.Policy != "" {
+		policy, _ = ParsePolicy(c.Policy)
+	}
+	e.Policy = policy.String()
+	if hash, _ := c.hash(); hash != 0 {
+		e.Hash = hash.String()
+	} else {
+		e.Hash = HashNone
+	}
+	format := FormatStandard
+	if c.EnvelopeFormat != "" {
+		format, _ = ParseEnvelopeFormat(c.EnvelopeFormat)
+	}
+	e.EnvelopeFormat = format.String()
+	if e.Encoding == "" {
+		e.Encoding = EncodingBinary
+	}
+	if e.Compression == "" {
+		e.Compression = CompressionNone
+	}
+	downgrade := c.DowngradeProtection == nil || *c.DowngradeProtection
+	e.DowngradeProtection = &downgrade
+	return e
+}
+
+// Options returns the provider options of c, which must be valid
+func (c *ProviderConfig) Options() []Option {
+	e := c.Effective()
+	policy, _ := ParsePolicy(e.Policy)
+	hash, _ := c.hash()
+	format, _ := ParseEnvelopeFormat(e.EnvelopeFormat)
+	opts := []Option{
+		WithPolicy(policy),
+		WithDigestPolicy(DigestPolicy{Hash: hash, PureMLDSA: e.PureMLDSA}),
+		WithEnvelopeFormat(format),
+		WithEnvelopeEncoding(e.Encoding, e.Compression),
+		WithDowngradeProtection(*e.DowngradeProtection),
+		WithLegacyECDSA(e.LegacyECDSA),
+		WithVerifyTimeout(e.VerifyTimeout),
+	}
+	if e.MaxSignatures > 0 {
+		opts = append(opts, WithMaxSignatures(e.MaxSignatures))
+	}
+	if e.Canary {
+		opts = append(opts, WithCanary(nil))
+	}
+	if e.MemoryLocking {
+		opts = append(opts, WithMemoryLocking(true))
+	}
+	return opts
+}
@@ -0,0 +1,64 @@
+package hybrid
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestProviderConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	c, err := LoadProviderConfig(write("ok.yaml", "algorithm: "+PQCAlgorithm+"\npolicy: or\nhash: sha3-256\ncompression: zstd\nverify_timeout: 50ms\n"))
+	require.NoError(t, err)
+	e := c.Effective()
+	assert.Equal(t, "OR", e.Policy)
+	assert.Equal(t, crypto.SHA3_256.String(), e.Hash)
+	assert.Equal(t, FormatStandard.String(), e.EnvelopeFormat)
+	assert.Equal(t, EncodingBinary, e.Encoding)
+	assert.Equal(t, CompressionZstd, e.Compression)
+	assert.Equal(t, 50*time.Millisecond, e.VerifyTimeout)
+	assert.True(t, *e.DowngradeProtection)
+
+	// The options configure a working provider
+	csp, err := New((&ProviderConfig{}).Options()...)
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("config"))
+	_, err = csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	// Misspelled keys are rejected
+	_, err = LoadProviderConfig(write("typo.yaml", "polcy: AND\n"))
+	assert.ErrorContains(t, err, "polcy")
+
+	// Every problem is reported at once
+	c = &ProviderConfig{Algorithm: "ML-DSA-87", Policy: "XOR", Hash: "SHA-999", Encoding: "cbor", Compression: "lz4", VerifyTimeout: -time.Second}
+	err = c.Validate()
+	for _, field := range []string{"algorithm", "policy", "hash", "encoding", "compression", "verify_timeout"} {
+		assert.ErrorContains(t, err, field+":")
+	}
+	assert.ErrorContains(t, c.Validate(), "not supported by the")
+	assert.ErrorContains(t, (&ProviderConfig{Algorithm: "Dilithium9"}).Validate(), "unknown algorithm")
+	assert.Error(t, (&ProviderConfig{Hash: HashNone, PureMLDSA: true}).Validate())
+	assert.NoError(t, (&ProviderConfig{Hash: HashNone}).Validate())
+	if core.CanaryBackend == "" {
+		assert.ErrorIs(t, (&ProviderConfig{Canary: true}).Validate(), core.ErrNoCanaryBackend)
+	} else {
+		assert.NoError(t, (&ProviderConfig{Canary: true}).Validate())
+	}
+}
@@ -0,0 +1,212 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cutover warning defaults
+const (
+	DefaultCutoverWarnBefore = 30 * 24 * time.Hour
+	DefaultCutoverWarnBlocks = 10000
+	DefaultCutoverWarnEvery  = time.Hour
+)
+
+// CutoverConfig schedules the consortium-wide end of classical-only
+// signatures: from the cutover on, channels whose policy is OR or
+// CLASSICAL verify under AND, so legacy ECDSA keys and envelopes without
+// the PQC component are rejected. Every peer loads the same config and
+// switches on its own, at the date or the block height reached first. Both
+// are read from the block being validated, never from the peer's clock, so
+// peers agree and replayed blocks verify as they did when committed.
+type CutoverConfig struct {
+	// Time is the cutover date, compared with the timestamp of the block
+	// being validated; zero disables the date trigger. Fabric timestamps
+	// are set by clients, so Height is the trigger to rely on when
+	// backdated transactions matter.
+	Time time.Time `yaml:"time,omitempty"`
+	// Height is the first block number verified under AND; zero disables
+	// the height trigger
+	Height uint64 `yaml:"height,omitempty"`
+	// WarnBefore is how long before Time warnings start,
+	// DefaultCutoverWarnBefore if zero
+	WarnBefore time.Duration `yaml:"warn_before,omitempty"`
+	// WarnBlocks is how many blocks before Height warnings start,
+	// DefaultCutoverWarnBlocks if zero
+	WarnBlocks uint64 `yaml:"warn_blocks,omitempty"`
+	// WarnEvery is the minimum interval between warnings for a channel,
+	// DefaultCutoverWarnEvery if zero
+	WarnEvery time.Duration `yaml:"warn_every,omitempty"`
+}
+
+// LoadCutoverConfig reads a CutoverConfig YAML file, e.g.
+//
+//	time: 2025-06-01T00:00:00Z
+//	height: 1200000
+//	warn_before: 720h
+func LoadCutoverConfig(path string) (CutoverConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return CutoverConfig{}, fmt.Errorf("failed to read cutover config: %w", err)
+	}
+	var c CutoverConfig
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return CutoverConfig{}, fmt.Errorf("failed to parse cutover config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// CutoverWarning reports an approaching or reached cutover on a channel
+type CutoverWarning struct {
+	Channel string
+	// Enforced is set once, when the channel switches to AND
+	Enforced bool
+	// Remaining is the time left from the block timestamp to the cutover
+	// date, when set
+	Remaining time.Duration
+	// RemainingBlocks is the number of blocks left before the cutover
+	// height, when set
+	RemainingBlocks uint64
+}
+
+func (w CutoverWarning) String() string {
+	if w.Enforced {
+		return fmt.Sprintf("channel %q: hybrid cutover reached, classical-only signatures are now rejected", w.Channel)
+	}
+	s := fmt.Sprintf("channel %q: hybrid cutover approaching, classical-only signatures will be rejected", w.Channel)
+	if w.Remaining > 0 {
+		s += fmt.Sprintf(" in %v", w.Remaining.Round(time.Minute))
+	}
+	if w.RemainingBlocks > 0 {
+		s += fmt.Sprintf(" in %d blocks", w.RemainingBlocks)
+	}
+	return s
+}
+
+// CutoverResolver is a BlockPolicyResolver applying a CutoverConfig on top
+// of another resolver. Validators pass the block being validated in
+// HybridSignerOpts.Block; policies resolved without a block keep the
+// fallback policies.
+type CutoverResolver struct {
+	config   CutoverConfig
+	fallback PolicyResolver
+	warn     func(CutoverWarning)
+	// now only paces the warnings
+	now func() time.Time
+
+	// Warning state only: enforcement depends on the block alone
+	mutex    sync.Mutex
+	warned   map[string]time.Time
+	enforced map[string]bool
+}
+
+// NewCutoverResolver returns a resolver escalating the OR and CLASSICAL
+// policies of fallback to AND at the cutover. warn, when not nil, is called
+// as the cutover approaches, at most every WarnEvery per channel, and once
+// when it is reached; it must not block.
+func NewCutoverResolver(config CutoverConfig, fallback PolicyResolver, warn func(CutoverWarning)) (*CutoverResolver, error) {
+	if config.Time.IsZero() && config.Height == 0 {
+		return nil, errors.New("cutover needs a time or a height")
+	}
+	if fallback == nil {
+		return nil, errors.New("cutover needs a fallback policy resolver")
+	}
+	if config.WarnBefore == 0 {
+		config.WarnBefore = DefaultCutoverWarnBefore
+	}
+	if config.WarnBlocks == 0 {
+		config.WarnBlocks = DefaultCutoverWarnBlocks
+	}
+	if config.WarnEvery == 0 {
+		config.WarnEvery = DefaultCutoverWarnEvery
+	}
+	return &CutoverResolver{
+		config:   config,
+		fallback: fallback,
+		warn:     warn,
+		now:      time.Now,
+		warned:   map[string]time.Time{},
+		enforced: map[string]bool{},
+	}, nil
+}
+
+// Enforced reports whether block is past the cutover
+func (r *CutoverResolver) Enforced(block BlockRef) bool {
+	enforced, _ := r.check(block)
+	return enforced
+}
+
+// ResolvePolicy implements PolicyResolver: without a block the cutover is
+// unknown and the fallback policy applies
+func (r *CutoverResolver) ResolvePolicy(channel, mspID string) Policy {
+	return r.fallback.ResolvePolicy(channel, mspID)
+}
+
+// ResolveBlockPolicy implements BlockPolicyResolver. Channels already
+// requiring the PQC component are left alone and never warned about.
+func (r *CutoverResolver) ResolveBlockPolicy(channel, mspID string, block BlockRef) Policy {
+	p := r.fallback.ResolvePolicy(channel, mspID)
+	if p != PolicyHybridOR && p != PolicyClassical {
+		return p
+	}
+	enforced, w := r.check(block)
+	w.Channel = channel
+	if r.warnDue(w) && r.warn != nil {
+		r.warn(w)
+	}
+	if enforced {
+		return PolicyHybridAND
+	}
+	return p
+}
+
+// check reports whether block is past the cutover, and the warning it
+// calls for, with Remaining and RemainingBlocks set when approaching
+func (r *CutoverResolver) check(block BlockRef) (bool, CutoverWarning) {
+	var w CutoverWarning
+	if !r.config.Time.IsZero() && !block.Timestamp.IsZero() {
+		w.Remaining = r.config.Time.Sub(block.Timestamp)
+		w.Enforced = w.Remaining <= 0
+	}
+	if r.config.Height > 0 {
+		if block.Number >= r.config.Height {
+			w.Enforced = true
+		} else {
+			w.RemainingBlocks = r.config.Height - block.Number
+		}
+	}
+	if w.Enforced {
+		return true, CutoverWarning{Enforced: true}
+	}
+	return false, w
+}
+
+// warnDue reports whether w is to be emitted: the enforcement once per
+// channel, an approaching cutover at most every WarnEvery
+func (r *CutoverResolver) warnDue(w CutoverWarning) bool {
+	now := r.now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if w.Enforced {
+		if r.enforced[w.Channel] {
+			return false
+		}
+		r.enforced[w.Channel] = true
+		return true
+	}
+	approaching := (w.Remaining > 0 && w.Remaining <= r.config.WarnBefore) ||
+		(w.RemainingBlocks > 0 && w.RemainingBlocks <= r.config.WarnBlocks)
+	if !approaching {
+		return false
+	}
+	if last, ok := r.warned[w.Channel]; ok && now.Sub(last) < r.config.WarnEvery {
+		return false
+	}
+	r.warned[w.Channel] = now
+	return true
+}
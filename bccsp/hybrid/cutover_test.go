@@ -0,0 +1,129 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCutoverResolver(t *testing.T) {
+	_, err := NewCutoverResolver(CutoverConfig{}, staticPolicy(PolicyHybridOR), nil)
+	assert.Error(t, err, "neither time nor height")
+
+	cutover := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policies := &ChannelPolicies{
+		Default:  PolicyHybridOR,
+		Channels: map[string]ChannelPolicy{"strict": {Default: PolicyPQC}},
+	}
+	var warnings []CutoverWarning
+	r, err := NewCutoverResolver(CutoverConfig{Time: cutover, Height: 1000, WarnBefore: 48 * time.Hour, WarnBlocks: 100},
+		policies, func(w CutoverWarning) { warnings = append(warnings, w) })
+	require.NoError(t, err)
+	now := cutover.Add(-72 * time.Hour)
+	r.now = func() time.Time { return now }
+
+	// Policies resolved without a block keep the fallback
+	assert.Equal(t, PolicyHybridOR, r.ResolvePolicy("mychannel", "Org1MSP"))
+
+	// Far from both triggers: OR, no warning
+	block := func(n uint64, ts time.Time) BlockRef { return BlockRef{Number: n, Timestamp: ts} }
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("mychannel", "Org1MSP", block(500, cutover.Add(-72*time.Hour))))
+	assert.Empty(t, warnings)
+
+	// Close to the height: one warning per WarnEvery
+	near := block(950, cutover.Add(-72*time.Hour))
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("mychannel", "Org1MSP", near))
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("mychannel", "Org1MSP", near))
+	require.Len(t, warnings, 1)
+	assert.Equal(t, CutoverWarning{Channel: "mychannel", Remaining: 72 * time.Hour, RemainingBlocks: 50}, warnings[0])
+	assert.Contains(t, warnings[0].String(), "in 50 blocks")
+
+	// The height is reached first on mychannel, the date applies elsewhere
+	reached := block(1000, cutover.Add(-48*time.Hour))
+	assert.Equal(t, PolicyHybridAND, r.ResolveBlockPolicy("mychannel", "Org1MSP", reached))
+	assert.Equal(t, PolicyHybridAND, r.ResolveBlockPolicy("mychannel", "Org2MSP", reached))
+	require.Len(t, warnings, 2)
+	assert.True(t, warnings[1].Enforced)
+	assert.Equal(t, "mychannel", warnings[1].Channel)
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("other", "Org1MSP", block(10, cutover.Add(-72*time.Hour))))
+	assert.Len(t, warnings, 2)
+
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("other", "Org1MSP", block(11, cutover.Add(-time.Hour))))
+	require.Len(t, warnings, 3)
+	assert.Equal(t, time.Hour, warnings[2].Remaining)
+
+	// Stricter policies are left alone and not warned about
+	assert.Equal(t, PolicyPQC, r.ResolveBlockPolicy("strict", "Org1MSP", block(11, cutover)))
+	assert.Len(t, warnings, 3)
+
+	// The date is that of the block, not of the peer's clock
+	now = cutover.Add(time.Hour)
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("other", "Org1MSP", block(11, cutover.Add(-time.Hour))))
+	require.Len(t, warnings, 4)
+	assert.False(t, warnings[3].Enforced)
+	assert.Equal(t, PolicyHybridAND, r.ResolveBlockPolicy("other", "Org1MSP", block(12, cutover)))
+	assert.True(t, r.Enforced(block(12, cutover)))
+	require.Len(t, warnings, 5)
+	assert.Equal(t, "channel \"other\": hybrid cutover reached, classical-only signatures are now rejected", warnings[4].String())
+
+	// Replayed blocks resolve as they did when committed
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("mychannel", "Org1MSP", block(500, cutover.Add(-72*time.Hour))))
+	assert.Equal(t, PolicyHybridOR, r.ResolveBlockPolicy("other", "Org1MSP", block(11, cutover.Add(-time.Hour))))
+	assert.False(t, r.Enforced(block(999, time.Time{})))
+}
+
+func TestCutoverRejectsClassicalSignatures(t *testing.T) {
+	r, err := NewCutoverResolver(CutoverConfig{Height: 10}, staticPolicy(PolicyHybridOR), nil)
+	require.NoError(t, err)
+	csp, err := New(WithLegacyECDSA(true), WithPolicyResolver(r))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+
+	priv, err := h.sw.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("client proposal"))
+	sig, err := h.sw.Sign(priv, digest[:], nil)
+	require.NoError(t, err)
+
+	before := &HybridSignerOpts{Channel: "mychannel", MSPID: "Org1MSP", Block: &BlockRef{Number: 9}}
+	valid, err := csp.Verify(pub, sig, digest[:], before)
+	require.NoError(t, err)
+	assert.True(t, valid, "classical signatures are admitted before the cutover")
+
+	after := &HybridSignerOpts{Channel: "mychannel", MSPID: "Org1MSP", Block: &BlockRef{Number: 10}}
+	_, err = csp.Verify(pub, sig, digest[:], after)
+	assert.ErrorIs(t, err, ErrClassicalKey)
+
+	// Blocks on both sides of the cutover validated concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts := before
+			if i%2 == 1 {
+				opts = after
+			}
+			valid, err := csp.Verify(pub, sig, digest[:], opts)
+			assert.Equal(t, opts == before, valid && err == nil, "block %d", opts.Block.Number)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLoadCutoverConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cutover.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("time: 2025-06-01T00:00:00Z\nheight: 1200000\nwarn_before: 720h\n"), 0o600))
+	c, err := LoadCutoverConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, CutoverConfig{Time: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Height: 1200000, WarnBefore: 720 * time.Hour}, c)
+}
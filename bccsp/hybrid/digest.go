@@ -0,0 +1,79 @@
+package hybrid
+
+import (
+	"crypto"
+	_ "crypto/sha256" // registers the hashes DigestPolicy may name
+	_ "crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	_ "golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrInvalidDigest is returned when a digest does not fit the digest policy
+	ErrInvalidDigest = errors.New("invalid digest")
+	// ErrMessageRequired is returned in pure ML-DSA mode when opts do not
+	// carry the original message
+	ErrMessageRequired = errors.New("pure ML-DSA mode requires the original message")
+	// ErrDigestMismatch is returned when the digest is not the hash of the
+	// message carried by opts
+	ErrDigestMismatch = errors.New("digest does not match message")
+)
+
+// DigestLengthError reports a digest of the wrong length for its hash
+type DigestLengthError struct {
+	Hash   crypto.Hash
+	Length int
+}
+
+func (e *DigestLengthError) Error() string {
+	return fmt.Sprintf("invalid digest: %d bytes, %s digests are %d bytes", e.Length, e.Hash, e.Hash.Size())
+}
+
+// Is makes errors.Is(err, ErrInvalidDigest) true
+func (e *DigestLengthError) Is(target error) bool {
+	return target == ErrInvalidDigest
+}
+
+// DigestPolicy controls the digests Sign accepts
+type DigestPolicy struct {
+	// Hash is the algorithm callers hash with, unless SignerOpts name another
+	// one. Digests of a different length are rejected; zero disables the check.
+	Hash crypto.Hash
+	// PureMLDSA makes the PQC component sign the original message, passed in
+	// HybridSignerOpts.Message, instead of the digest
+	PureMLDSA bool
+}
+
+// WithDigestPolicy replaces the default policy (SHA-256 digests, ML-DSA over
+// the digest)
+func WithDigestPolicy(p DigestPolicy) Option {
+	return func(h *HybridBCCSP) {
+		h.digestPolicy = p
+	}
+}
+
+// digestHash returns the hash digest is expected to come from
+func (h *HybridBCCSP) digestHash(opts bccsp.SignerOpts) crypto.Hash {
+	if opts != nil && opts.HashFunc() != 0 {
+		return opts.HashFunc()
+	}
+	return h.digestPolicy.Hash
+}
+
+// checkDigest validates the length of digest
+func (h *HybridBCCSP) checkDigest(digest []byte, opts bccsp.SignerOpts) error {
+	hash := h.digestHash(opts)
+	if hash == 0 {
+		if len(digest) == 0 {
+			return fmt.Errorf("%w: empty digest", ErrInvalidDigest)
+		}
+		return nil
+	}
+	if len(digest) != hash.Size() {
+		return &DigestLengthError{Hash: hash, Length: len(digest)}
+	}
+	return nil
+}
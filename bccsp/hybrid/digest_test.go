@@ -0,0 +1,68 @@
+package hybrid
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestLength(t *testing.T) {
+	csp, err := New()
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	_, err = csp.Sign(k, []byte("not a digest"), nil)
+	var lengthErr *DigestLengthError
+	require.True(t, errors.As(err, &lengthErr))
+	assert.Equal(t, crypto.SHA256, lengthErr.Hash)
+	assert.True(t, errors.Is(err, ErrInvalidDigest))
+
+	// SignerOpts naming another hash take precedence
+	digest384 := sha512.Sum384([]byte("msg"))
+	_, err = csp.Sign(k, digest384[:], crypto.SHA384)
+	require.NoError(t, err)
+	_, err = csp.Sign(k, digest384[:], nil)
+	assert.True(t, errors.Is(err, ErrInvalidDigest))
+}
+
+func TestPureMLDSA(t *testing.T) {
+	csp, err := New(WithDigestPolicy(DigestPolicy{Hash: crypto.SHA256, PureMLDSA: true}))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	msg := []byte("transaction proposal")
+	digest := sha256.Sum256(msg)
+	_, err = csp.Sign(k, digest[:], nil)
+	assert.True(t, errors.Is(err, ErrMessageRequired))
+	_, err = csp.Sign(k, digest[:], &HybridSignerOpts{Message: []byte("other")})
+	assert.True(t, errors.Is(err, ErrDigestMismatch))
+
+	sig, err := csp.Sign(k, digest[:], &HybridSignerOpts{Message: msg})
+	require.NoError(t, err)
+	env, err := ParseEnvelope(sig)
+	require.NoError(t, err)
+	assert.Equal(t, "hybrid+pure", env.Modes.String())
+
+	valid, err := csp.Verify(k, sig, digest[:], &HybridSignerOpts{Message: msg})
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Any verifier needs the message for pure signatures
+	plain, err := New()
+	require.NoError(t, err)
+	_, err = plain.Verify(k, sig, digest[:], nil)
+	assert.True(t, errors.Is(err, ErrMessageRequired))
+
+	pqcOnly := PolicyPQC
+	valid, err = plain.Verify(k, sig, digest[:], &HybridSignerOpts{Message: msg, Policy: &pqcOnly})
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
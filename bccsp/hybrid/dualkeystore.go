@@ -0,0 +1,167 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// DualKeyStore keeps the two halves of hybrid keys in separate trust
+// domains, for separation of duties: the ECDSA half in a classical BCCSP,
+// e.g. Fabric's PKCS#11 provider in front of an HSM administered by one
+// team, and the PQC half in a directory of PQC key files operated by
+// another. The halves are matched by the SKI of the ECDSA half, and a
+// hybrid signature needs both domains.
+//
+// The PQC halves are always encrypted with a passphrase, as KeyStore does
+// after SetPassphrase, since their directory has none of the protections
+// of an HSM.
+type DualKeyStore struct {
+	classical bccsp.BCCSP
+	dir       string
+	cipher    *keystoreCipher
+}
+
+// NewDualKeyStore joins the ECDSA halves held by classical with the PQC
+// halves in dir, created if missing and encrypted with passphrase, e.g.
+// read with secret.Read. The passphrase is required.
+func NewDualKeyStore(classical bccsp.BCCSP, dir string, passphrase []byte) (*DualKeyStore, error) {
+	if classical == nil {
+		return nil, errors.New("dual keystore needs a classical provider")
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("dual keystore needs a passphrase for its PQC halves")
+	}
+	c, err := newKeystoreCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create PQC keystore directory %s: %w", dir, err)
+	}
+	return &DualKeyStore{classical: classical, dir: dir, cipher: c}, nil
+}
+
+// keyGen generates the ECDSA half with the classical provider, which keeps
+// it, and stores the PQC half under the same SKI
+func (d *DualKeyStore) keyGen(opts bccsp.KeyGenOpts) (*hybridKey, error) {
+	ecdsaKey, err := d.classical.KeyGen(opts)
+	if err != nil {
+		return nil, wrapError(OpKeyGen, AlgorithmECDSA, nil, fmt.Errorf("ECDSA KeyGen in the classical domain failed: %w", err))
+	}
+	signer, err := NewPQCSigner()
+	if err != nil {
+		return nil, wrapError(OpKeyGen, AlgorithmMLDSA, nil, fmt.Errorf("PQC KeyGen failed: %w", err))
+	}
+	key := &hybridKey{ecdsaKey: ecdsaKey, pqcPriv: signer, pqcPub: signer.PublicKey(), ecdsaCSP: d.classical}
+	if err := d.storePQC(key.SKI(), signer.PrivateKey(), key.pqcPub); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// StorePQCKey adds the PQC half of the key whose ECDSA half the classical
+// provider holds under ski, e.g. an HSM key created by a ceremony
+func (d *DualKeyStore) StorePQCKey(ski, priv, pub []byte) error {
+	if len(ski) == 0 {
+		return ErrEmptySKI
+	}
+	if _, err := d.classical.GetKey(ski); err != nil {
+		return fmt.Errorf("%w: ECDSA half %x not in the classical domain: %w", ErrKeyNotFound, ski, err)
+	}
+	if _, err := NewPQCSignerFromKeyPair(priv, pub); err != nil {
+		return err
+	}
+	return d.storePQC(ski, priv, pub)
+}
+
+func (d *DualKeyStore) storePQC(ski, priv, pub []byte) error {
+	raw, err := d.cipher.seal(ski, priv, pub)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.pqcPath(ski), raw, 0o600)
+}
+
+// GetKey joins the halves stored under ski. A half missing on either side
+// is reported with ErrKeyNotFound naming its domain.
+func (d *DualKeyStore) GetKey(ski []byte) (bccsp.Key, error) {
+	if len(ski) == 0 {
+		return nil, ErrEmptySKI
+	}
+	raw, err := os.ReadFile(d.pqcPath(ski))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: PQC half %x not in %s", ErrKeyNotFound, ski, d.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != encryptedPQCKeyPEMType {
+		return nil, fmt.Errorf("PQC half %x is not encrypted", ski)
+	}
+	pqc, err := d.cipher.open(ski, block)
+	if err != nil {
+		return nil, fmt.Errorf("PQC half %x: %w", ski, err)
+	}
+	ecdsaKey, err := d.classical.GetKey(ski)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ECDSA half %x not in the classical domain: %w", ErrKeyNotFound, ski, err)
+	}
+	if !ecdsaKey.Private() {
+		return nil, fmt.Errorf("%w: the classical domain holds only the public ECDSA half of %x", ErrPublicKeyOnly, ski)
+	}
+	signer, err := NewPQCSignerFromKeyPair(pqc.PrivateKey, pqc.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &hybridKey{ecdsaKey: ecdsaKey, pqcPriv: signer, pqcPub: pqc.PublicKey, ecdsaCSP: d.classical}, nil
+}
+
+// ListKeys returns the SKIs of the PQC halves, sorted. Their ECDSA halves
+// are listed by the classical domain's own tooling.
+func (d *DualKeyStore) ListKeys() ([][]byte, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	var skis [][]byte
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), pqcKeySuffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if ski, err := hex.DecodeString(name); err == nil {
+			skis = append(skis, ski)
+		}
+	}
+	return skis, nil
+}
+
+func (d *DualKeyStore) pqcPath(ski []byte) string {
+	return filepath.Join(d.dir, hex.EncodeToString(ski)+pqcKeySuffix)
+}
+
+// WithDualKeyStore generates non-ephemeral hybrid keys in the two domains
+// of d and makes GetKey look them up there. Sign and Verify use the
+// classical provider of d for the ECDSA half. It excludes WithKeyStore.
+func WithDualKeyStore(d *DualKeyStore) Option {
+	return func(h *HybridBCCSP) {
+		h.dual = d
+	}
+}
+
+// ecdsaProvider returns the provider holding the ECDSA half of key
+func (h *HybridBCCSP) ecdsaProvider(key *hybridKey) bccsp.BCCSP {
+	if key.ecdsaCSP != nil {
+		return key.ecdsaCSP
+	}
+	return h.sw
+}
@@ -0,0 +1,153 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/bccsp/sw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hsmCSP stands for the classical provider of another trust domain, e.g.
+// PKCS#11, and counts the signatures it makes
+type hsmCSP struct {
+	bccsp.BCCSP
+	signs atomic.Int32
+}
+
+func (c *hsmCSP) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	c.signs.Add(1)
+	return c.BCCSP.Sign(k, digest, opts)
+}
+
+func TestDualKeyStore(t *testing.T) {
+	hsmDir, pqcDir := t.TempDir(), t.TempDir()
+	passphrase := []byte("pqc domain")
+	classical, err := sw.NewDefaultSecurityLevel(hsmDir)
+	require.NoError(t, err)
+	hsm := &hsmCSP{BCCSP: classical}
+	dual, err := NewDualKeyStore(hsm, pqcDir, passphrase)
+	require.NoError(t, err)
+	csp, err := New(WithDualKeyStore(dual))
+	require.NoError(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("split trust"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hsm.signs.Load(), "the ECDSA half signs in its own domain")
+
+	// Each domain holds one half only
+	held, err := classical.GetKey(k.SKI())
+	require.NoError(t, err)
+	assert.True(t, held.Private())
+	entries, err := os.ReadDir(pqcDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, hex.EncodeToString(k.SKI())+pqcKeySuffix, entries[0].Name())
+	raw, err := os.ReadFile(filepath.Join(pqcDir, entries[0].Name()))
+	require.NoError(t, err)
+	block, _ := pem.Decode(raw)
+	require.NotNil(t, block)
+	assert.Equal(t, encryptedPQCKeyPEMType, block.Type, "the PQC half is encrypted")
+	skis, err := dual.ListKeys()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{k.SKI()}, skis)
+
+	// Another provider joins the halves again, any provider verifies
+	restarted, err := New(WithDualKeyStore(dual))
+	require.NoError(t, err)
+	loaded, err := restarted.GetKey(k.SKI())
+	require.NoError(t, err)
+	sig2, err := restarted.Sign(loaded, digest[:], nil)
+	require.NoError(t, err)
+	verifier, err := New()
+	require.NoError(t, err)
+	pub, err := loaded.PublicKey()
+	require.NoError(t, err)
+	composite, err := MarshalPublicKey(pub)
+	require.NoError(t, err)
+	imported, err := verifier.KeyImport(composite, &HybridPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	for _, s := range [][]byte{sig, sig2} {
+		valid, err := verifier.Verify(imported, s, digest[:], nil)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+	valid, err := restarted.Verify(pub, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Ephemeral keys stay in the provider
+	eph, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	_, err = dual.GetKey(eph.SKI())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// Neither half signs alone
+	otherHSM, err := sw.NewDefaultSecurityLevel(t.TempDir())
+	require.NoError(t, err)
+	pqcOnly, err := NewDualKeyStore(otherHSM, pqcDir, passphrase)
+	require.NoError(t, err)
+	_, err = pqcOnly.GetKey(k.SKI())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorContains(t, err, "classical domain")
+	ecdsaOnly, err := NewDualKeyStore(hsm, t.TempDir(), passphrase)
+	require.NoError(t, err)
+	_, err = ecdsaOnly.GetKey(k.SKI())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorContains(t, err, "PQC half")
+
+	// The PQC domain needs its passphrase, and never holds plain halves
+	_, err = NewDualKeyStore(hsm, pqcDir, nil)
+	assert.ErrorContains(t, err, "passphrase")
+	wrong, err := NewDualKeyStore(hsm, pqcDir, []byte("wrong"))
+	require.NoError(t, err)
+	_, err = wrong.GetKey(k.SKI())
+	assert.ErrorIs(t, err, ErrKeyStorePassphrase)
+	signer, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer signer.Clean()
+	plain, err := marshalPQCKeyFile(signer.PrivateKey(), signer.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(pqcDir, entries[0].Name()), plain, 0o600))
+	_, err = dual.GetKey(k.SKI())
+	assert.ErrorContains(t, err, "not encrypted")
+}
+
+func TestDualKeyStoreStorePQCKey(t *testing.T) {
+	classical, err := sw.NewDefaultSecurityLevel(t.TempDir())
+	require.NoError(t, err)
+	dual, err := NewDualKeyStore(classical, t.TempDir(), []byte("pqc domain"))
+	require.NoError(t, err)
+	signer, err := NewPQCSigner()
+	require.NoError(t, err)
+
+	// The ECDSA half must exist in the classical domain first
+	err = dual.StorePQCKey([]byte{1, 2, 3}, signer.PrivateKey(), signer.PublicKey())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	ecdsaKey, err := classical.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	other, err := NewPQCSigner()
+	require.NoError(t, err)
+	err = dual.StorePQCKey(ecdsaKey.SKI(), signer.PrivateKey(), other.PublicKey())
+	assert.ErrorIs(t, err, ErrKeyPairMismatch)
+	require.NoError(t, dual.StorePQCKey(ecdsaKey.SKI(), signer.PrivateKey(), signer.PublicKey()))
+	k, err := dual.GetKey(ecdsaKey.SKI())
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey(), k.(*hybridKey).pqcPub)
+
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	_, err = New(WithKeyStore(ks, "Org1MSP"), WithDualKeyStore(dual))
+	assert.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package hybrid
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EnvelopeEncoder is a wire format of hybrid envelopes. Sign emits the one
+// selected with WithEnvelopeEncoding; Verify detects the format of each
+// signature, so it accepts every registered encoder.
+type EnvelopeEncoder interface {
+	// AppendEncode appends the encoding of e to dst
+	AppendEncode(dst []byte, e *Envelope) ([]byte, error)
+	// Decode parses an envelope, rejecting components over limits
+	Decode(data []byte, limits EnvelopeLimits) (*Envelope, error)
+	// Detect reports whether data, a non-empty signature, is in this
+	// format. Formats are told apart by their first bytes and must not
+	// overlap.
+	Detect(data []byte) bool
+}
+
+// Compressor compresses encoded envelopes. Like encoders, compressed
+// signatures are recognised by their first bytes.
+type Compressor interface {
+	// AppendCompress appends the compression of src to dst
+	AppendCompress(dst, src []byte) ([]byte, error)
+	// Decompress fails rather than return more than max bytes
+	Decompress(src []byte, max int) ([]byte, error)
+	// Detect reports whether data is compressed by this compressor
+	Detect(data []byte) bool
+}
+
+// Built-in encoders and compressors
+const (
+	// EncodingBinary is the [version][modes][length] layout of
+	// Envelope.Marshal, the default
+	EncodingBinary = "binary"
+	// EncodingASN1 is a DER SEQUENCE, for toolchains that parse ASN.1
+	EncodingASN1 = "asn1"
+	// EncodingCOSE is a detached COSE_Sign with one signature per component
+	EncodingCOSE = "cose"
+
+	// CompressionNone leaves envelopes as encoded, the default
+	CompressionNone = "none"
+	// CompressionZstd compresses envelopes with zstd
+	CompressionZstd = "zstd"
+)
+
+// maxDecompressedSize bounds decompression when limits are disabled
+const maxDecompressedSize = 1 << 20
+
+// encodingOverhead is more than any built-in encoding adds to the components
+const encodingOverhead = 64
+
+type namedEncoder struct {
+	name string
+	EnvelopeEncoder
+}
+
+type namedCompressor struct {
+	name string
+	Compressor
+}
+
+// codecs holds the registered formats in registration order, the order
+// they are detected in
+var codecs = struct {
+	mutex       sync.RWMutex
+	encoders    []namedEncoder
+	compressors []namedCompressor
+}{}
+
+func init() {
+	RegisterEnvelopeEncoder(EncodingBinary, binaryEncoder{})
+	RegisterEnvelopeEncoder(EncodingASN1, asn1Encoder{})
+	RegisterEnvelopeEncoder(EncodingCOSE, coseEncoder{})
+	RegisterCompressor(CompressionNone, noCompression{})
+	RegisterCompressor(CompressionZstd, zstdCompressor{})
+}
+
+// RegisterEnvelopeEncoder makes e available to WithEnvelopeEncoding and
+// Verify under name. It panics if name is empty or taken.
+func RegisterEnvelopeEncoder(name string, e EnvelopeEncoder) {
+	codecs.mutex.Lock()
+	defer codecs.mutex.Unlock()
+	if name == "" || e == nil || lookupEncoder(name) != nil {
+		panic(fmt.Sprintf("hybrid: envelope encoder %q is empty or already registered", name))
+	}
+	codecs.encoders = append(codecs.encoders, namedEncoder{name, e})
+}
+
+// RegisterCompressor makes c available to WithEnvelopeEncoding and Verify
+// under name. It panics if name is empty or taken.
+func RegisterCompressor(name string, c Compressor) {
+	codecs.mutex.Lock()
+	defer codecs.mutex.Unlock()
+	if name == "" || c == nil || lookupCompressor(name) != nil {
+		panic(fmt.Sprintf("hybrid: compressor %q is empty or already registered", name))
+	}
+	codecs.compressors = append(codecs.compressors, namedCompressor{name, c})
+}
+
+// EnvelopeEncoders returns the names of the registered encoders, sorted
+func EnvelopeEncoders() []string {
+	codecs.mutex.RLock()
+	defer codecs.mutex.RUnlock()
+	names := make([]string, len(codecs.encoders))
+	for i, e := range codecs.encoders {
+		names[i] = e.name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compressors returns the names of the registered compressors, sorted
+func Compressors() []string {
+	codecs.mutex.RLock()
+	defer codecs.mutex.RUnlock()
+	names := make([]string, len(codecs.compressors))
+	for i, c := range codecs.compressors {
+		names[i] = c.name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupEncoder(name string) EnvelopeEncoder {
+	for _, e := range codecs.encoders {
+		if e.name == name {
+			return e.EnvelopeEncoder
+		}
+	}
+	return nil
+}
+
+func lookupCompressor(name string) Compressor {
+	for _, c := range codecs.compressors {
+		if c.name == name {
+			return c.Compressor
+		}
+	}
+	return nil
+}
+
+// WithEnvelopeEncoding selects the registered encoder and compressor of new
+// signatures, EncodingBinary and CompressionNone by default. New fails for
+// unknown names. The envelope version, see WithEnvelopeFormat, is kept in
+// every encoding since the components sign it.
+func WithEnvelopeEncoding(encoder, compressor string) Option {
+	return func(h *HybridBCCSP) {
+		h.encoderName, h.compressorName = encoder, compressor
+	}
+}
+
+// resolveEncoding looks up the encoder and compressor names of h; the
+// defaults stay nil so Sign keeps its allocation-free path
+func (h *HybridBCCSP) resolveEncoding() error {
+	codecs.mutex.RLock()
+	defer codecs.mutex.RUnlock()
+	if h.encoderName != "" && h.encoderName != EncodingBinary {
+		if h.encoder = lookupEncoder(h.encoderName); h.encoder == nil {
+			return fmt.Errorf("unknown envelope encoder %q, expected one of %v", h.encoderName, EnvelopeEncoders())
+		}
+	}
+	if h.compressorName != "" && h.compressorName != CompressionNone {
+		if h.compressor = lookupCompressor(h.compressorName); h.compressor == nil {
+			return fmt.Errorf("unknown compressor %q, expected one of %v", h.compressorName, Compressors())
+		}
+	}
+	return nil
+}
+
+// appendEnvelope appends env in the encoding of h
+func (h *HybridBCCSP) appendEnvelope(dst []byte, env *Envelope) ([]byte, error) {
+	if h.encoder == nil && h.compressor == nil {
+		if dst == nil {
+			dst = make([]byte, 0, env.Size())
+		}
+		return env.AppendMarshal(dst), nil
+	}
+	var encoder EnvelopeEncoder = binaryEncoder{}
+	if h.encoder != nil {
+		encoder = h.encoder
+	}
+	if h.compressor == nil {
+		return encoder.AppendEncode(dst, env)
+	}
+	encoded, err := encoder.AppendEncode(nil, env)
+	if err != nil {
+		return nil, err
+	}
+	return h.compressor.AppendCompress(dst, encoded)
+}
+
+// DecodeEnvelope parses a signature in any registered encoding, compressed
+// or not
+func DecodeEnvelope(signature []byte, limits EnvelopeLimits) (*Envelope, error) {
+	if len(signature) == 0 {
+		return nil, fmt.Errorf("%w: signature too short", ErrMalformedEnvelope)
+	}
+	codecs.mutex.RLock()
+	compressors, encoders := codecs.compressors, codecs.encoders
+	codecs.mutex.RUnlock()
+
+	for _, c := range compressors {
+		if !c.Detect(signature) {
+			continue
+		}
+		max := maxDecompressedSize
+		if limits.MaxECDSA > 0 && limits.MaxPQC > 0 {
+			max = limits.MaxECDSA + limits.MaxPQC + encodingOverhead
+		}
+		decompressed, err := c.Decompress(signature, max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrMalformedEnvelope, c.name, err)
+		}
+		if len(decompressed) == 0 {
+			return nil, fmt.Errorf("%w: signature too short", ErrMalformedEnvelope)
+		}
+		signature = decompressed
+		break
+	}
+	for _, e := range encoders {
+		if e.Detect(signature) {
+			return e.Decode(signature, limits)
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown signature encoding %#x", ErrMalformedEnvelope, signature[0])
+}
+
+// checkHeader validates the version and modes of a decoded envelope
+func checkHeader(version byte, modes Modes) error {
+	switch version {
+	case EnvelopeV1:
+		if modes != ModeClassical|ModePQC {
+			return fmt.Errorf("%w: v1 envelopes carry both components", ErrMalformedEnvelope)
+		}
+	case EnvelopeV2, EnvelopeV2LE:
+		if !modes.Valid() {
+			return fmt.Errorf("%w: invalid signature modes %#x", ErrMalformedEnvelope, byte(modes))
+		}
+	default:
+		return fmt.Errorf("%w: unsupported signature envelope version %#x", ErrMalformedEnvelope, version)
+	}
+	return nil
+}
+
+// binaryEncoder is Envelope.Marshal and ParseEnvelopeLimits
+type binaryEncoder struct{}
+
+func (binaryEncoder) AppendEncode(dst []byte, e *Envelope) ([]byte, error) {
+	if err := checkHeader(e.Version, e.Modes); err != nil {
+		return nil, err
+	}
+	return e.AppendMarshal(dst), nil
+}
+
+func (binaryEncoder) Decode(data []byte, limits EnvelopeLimits) (*Envelope, error) {
+	return ParseEnvelopeLimits(data, limits)
+}
+
+func (binaryEncoder) Detect(data []byte) bool {
+	return data[0] == EnvelopeV1 || data[0] == EnvelopeV2 || data[0] == EnvelopeV2LE
+}
+
+// asn1Envelope is
+//
+//	HybridSignature ::= SEQUENCE {
+//	  version  INTEGER (0..255),
+//	  modes    INTEGER (0..255),
+//	  ecdsa    OCTET STRING,
+//	  pqc      OCTET STRING
+//	}
+type asn1Envelope struct {
+	Version int
+	Modes   int
+	ECDSA   []byte
+	PQC     []byte
+}
+
+type asn1Encoder struct{}
+
+func (asn1Encoder) AppendEncode(dst []byte, e *Envelope) ([]byte, error) {
+	if err := checkHeader(e.Version, e.Modes); err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(asn1Envelope{Version: int(e.Version), Modes: int(e.Modes), ECDSA: e.ECDSASignature, PQC: e.PQCSignature})
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, der...), nil
+}
+
+func (asn1Encoder) Decode(data []byte, limits EnvelopeLimits) (*Envelope, error) {
+	var a asn1Envelope
+	if rest, err := asn1.Unmarshal(data, &a); err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: invalid ASN.1 envelope", ErrMalformedEnvelope)
+	}
+	if a.Version < 0 || a.Version > 0xFF || a.Modes < 0 || a.Modes > 0xFF {
+		return nil, fmt.Errorf("%w: invalid ASN.1 envelope header", ErrMalformedEnvelope)
+	}
+	env := &Envelope{Version: byte(a.Version), Modes: Modes(a.Modes), ECDSASignature: a.ECDSA, PQCSignature: a.PQC}
+	if err := checkHeader(env.Version, env.Modes); err != nil {
+		return nil, err
+	}
+	if err := limits.Check(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func (asn1Encoder) Detect(data []byte) bool {
+	return data[0] == 0x30 // SEQUENCE
+}
@@ -0,0 +1,311 @@
+package hybrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// coseEncoder writes envelopes as a tagged COSE_Sign (RFC 9052) with a
+// detached payload, the digest, and one COSE_Signature per component:
+//
+//	98([
+//	  << { -65537: version, -65538: modes } >>,
+//	  {},
+//	  null,
+//	  [ [ << { 1: -7 } >>, {}, ecdsa ], [ << { 1: -49 } >>, {}, pqc ] ]
+//	])
+//
+// Version and modes use private header labels; -7 is ES256 and -49 the
+// ML-DSA-65 code point of the COSE ML-DSA draft. Components that are
+// absent are left out.
+type coseEncoder struct{}
+
+const (
+	coseSignTag      = 98
+	coseLabelVersion = -65537
+	coseLabelModes   = -65538
+	coseHeaderAlg    = 1
+	coseAlgES256     = -7
+	coseAlgMLDSA65   = -49
+)
+
+// CBOR major types and the null simple value
+const (
+	cborUnsigned   = 0
+	cborNegative   = 1
+	cborByteString = 2
+	cborArrayType  = 4
+	cborMapType    = 5
+	cborTagType    = 6
+
+	cborNull byte = 0xF6
+)
+
+func (coseEncoder) AppendEncode(dst []byte, e *Envelope) ([]byte, error) {
+	if err := checkHeader(e.Version, e.Modes); err != nil {
+		return nil, err
+	}
+	var protected []byte
+	protected = cborAppendHead(protected, cborMapType, 2)
+	protected = cborAppendInt(protected, coseLabelVersion)
+	protected = cborAppendInt(protected, int64(e.Version))
+	protected = cborAppendInt(protected, coseLabelModes)
+	protected = cborAppendInt(protected, int64(e.Modes))
+
+	type component struct {
+		alg int64
+		sig []byte
+	}
+	var components []component
+	if len(e.ECDSASignature) > 0 {
+		components = append(components, component{coseAlgES256, e.ECDSASignature})
+	}
+	if len(e.PQCSignature) > 0 {
+		components = append(components, component{coseAlgMLDSA65, e.PQCSignature})
+	}
+
+	dst = cborAppendHead(dst, cborTagType, coseSignTag)
+	dst = cborAppendHead(dst, cborArrayType, 4)
+	dst = cborAppendBytes(dst, protected)
+	dst = cborAppendHead(dst, cborMapType, 0)
+	dst = append(dst, cborNull)
+	dst = cborAppendHead(dst, cborArrayType, uint64(len(components)))
+	for _, c := range components {
+		header := cborAppendHead(nil, cborMapType, 1)
+		header = cborAppendInt(cborAppendInt(header, coseHeaderAlg), c.alg)
+		dst = cborAppendHead(dst, cborArrayType, 3)
+		dst = cborAppendBytes(dst, header)
+		dst = cborAppendHead(dst, cborMapType, 0)
+		dst = cborAppendBytes(dst, c.sig)
+	}
+	return dst, nil
+}
+
+func (coseEncoder) Decode(data []byte, limits EnvelopeLimits) (*Envelope, error) {
+	env, err := decodeCOSE(&cborReader{data: data}, limits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid COSE envelope: %w", ErrMalformedEnvelope, err)
+	}
+	return env, nil
+}
+
+func decodeCOSE(r *cborReader, limits EnvelopeLimits) (*Envelope, error) {
+	if err := r.expect(cborTagType, coseSignTag); err != nil {
+		return nil, err
+	}
+	if err := r.expect(cborArrayType, 4); err != nil {
+		return nil, err
+	}
+	protected, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	header := &cborReader{data: protected}
+	if err := header.expect(cborMapType, 2); err != nil {
+		return nil, err
+	}
+	var fields [2]int64
+	for i, label := range []int64{coseLabelVersion, coseLabelModes} {
+		if err := header.expectInt(label); err != nil {
+			return nil, err
+		}
+		if fields[i], err = header.int(); err != nil {
+			return nil, err
+		}
+		if fields[i] < 0 || fields[i] > 0xFF {
+			return nil, fmt.Errorf("header value %d out of range", fields[i])
+		}
+	}
+	if !header.done() {
+		return nil, errors.New("trailing protected header bytes")
+	}
+	env := &Envelope{Version: byte(fields[0]), Modes: Modes(fields[1])}
+	if err := checkHeader(env.Version, env.Modes); err != nil {
+		return nil, err
+	}
+
+	if err := r.expect(cborMapType, 0); err != nil {
+		return nil, err
+	}
+	if !r.next(cborNull) {
+		return nil, errors.New("payload is not detached")
+	}
+	major, n, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborArrayType || n > 2 {
+		return nil, errors.New("expected at most two signatures")
+	}
+	for i := uint64(0); i < n; i++ {
+		if err := r.expect(cborArrayType, 3); err != nil {
+			return nil, err
+		}
+		protected, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		alg := &cborReader{data: protected}
+		if err := alg.expect(cborMapType, 1); err != nil {
+			return nil, err
+		}
+		if err := alg.expectInt(coseHeaderAlg); err != nil {
+			return nil, err
+		}
+		id, err := alg.int()
+		if err != nil {
+			return nil, err
+		}
+		if !alg.done() {
+			return nil, errors.New("trailing signature header bytes")
+		}
+		if err := r.expect(cborMapType, 0); err != nil {
+			return nil, err
+		}
+		sig, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case id == coseAlgES256 && env.ECDSASignature == nil:
+			env.ECDSASignature = sig
+		case id == coseAlgMLDSA65 && env.PQCSignature == nil:
+			env.PQCSignature = sig
+		default:
+			return nil, fmt.Errorf("unexpected or repeated algorithm %d", id)
+		}
+	}
+	if !r.done() {
+		return nil, errors.New("trailing bytes")
+	}
+	if err := limits.Check(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func (coseEncoder) Detect(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{cborTagType<<5 | 24, coseSignTag})
+}
+
+func cborAppendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= 0xFF:
+		return append(b, major<<5|24, byte(n))
+	case n <= 0xFFFF:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= 0xFFFFFFFF:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+}
+
+func cborAppendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return cborAppendHead(b, cborNegative, uint64(-1-v))
+	}
+	return cborAppendHead(b, cborUnsigned, uint64(v))
+}
+
+func cborAppendBytes(b, v []byte) []byte {
+	return append(cborAppendHead(b, cborByteString, uint64(len(v))), v...)
+}
+
+// cborReader reads the definite-length CBOR written by coseEncoder
+type cborReader struct {
+	data []byte
+}
+
+func (r *cborReader) done() bool {
+	return len(r.data) == 0
+}
+
+func (r *cborReader) next(b byte) bool {
+	if len(r.data) == 0 || r.data[0] != b {
+		return false
+	}
+	r.data = r.data[1:]
+	return true
+}
+
+func (r *cborReader) head() (major byte, n uint64, err error) {
+	if len(r.data) == 0 {
+		return 0, 0, errors.New("truncated")
+	}
+	major, info := r.data[0]>>5, r.data[0]&0x1F
+	r.data = r.data[1:]
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, fmt.Errorf("unsupported additional information %d", info)
+	}
+	size := 1 << (info - 24)
+	if len(r.data) < size {
+		return 0, 0, errors.New("truncated")
+	}
+	for _, b := range r.data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	r.data = r.data[size:]
+	return major, n, nil
+}
+
+func (r *cborReader) expect(major byte, n uint64) error {
+	m, got, err := r.head()
+	if err != nil {
+		return err
+	}
+	if m != major || got != n {
+		return fmt.Errorf("unexpected item %d(%d), expected %d(%d)", m, got, major, n)
+	}
+	return nil
+}
+
+func (r *cborReader) int() (int64, error) {
+	major, n, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+	if n > 1<<62 {
+		return 0, errors.New("integer out of range")
+	}
+	switch major {
+	case cborUnsigned:
+		return int64(n), nil
+	case cborNegative:
+		return -1 - int64(n), nil
+	}
+	return 0, fmt.Errorf("unexpected item %d, expected an integer", major)
+}
+
+func (r *cborReader) expectInt(v int64) error {
+	got, err := r.int()
+	if err != nil {
+		return err
+	}
+	if got != v {
+		return fmt.Errorf("unexpected label %d, expected %d", got, v)
+	}
+	return nil
+}
+
+func (r *cborReader) bytes() ([]byte, error) {
+	major, n, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborByteString {
+		return nil, fmt.Errorf("unexpected item %d, expected a byte string", major)
+	}
+	if n > uint64(len(r.data)) {
+		return nil, errors.New("truncated")
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
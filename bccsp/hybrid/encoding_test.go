@@ -0,0 +1,106 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeEncodings(t *testing.T) {
+	assert.Equal(t, []string{EncodingASN1, EncodingBinary, EncodingCOSE}, EnvelopeEncoders())
+	assert.Equal(t, []string{CompressionNone, CompressionZstd}, Compressors())
+
+	envs := []*Envelope{
+		{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{1, 2, 3}, PQCSignature: make([]byte, 300)},
+		{Version: EnvelopeV2LE, Modes: ModePQC | ModePure, PQCSignature: []byte{4, 5}},
+		{Version: EnvelopeV1, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{6}, PQCSignature: []byte{7}},
+	}
+	for _, name := range EnvelopeEncoders() {
+		encoder := lookupEncoder(name)
+		for _, compressor := range Compressors() {
+			for _, env := range envs {
+				encoded, err := encoder.AppendEncode(nil, env)
+				require.NoError(t, err, name)
+				encoded, err = lookupCompressor(compressor).AppendCompress(nil, encoded)
+				require.NoError(t, err)
+
+				decoded, err := DecodeEnvelope(encoded, DefaultEnvelopeLimits)
+				require.NoError(t, err, "%s/%s", name, compressor)
+				assert.Equal(t, env.Version, decoded.Version)
+				assert.Equal(t, env.Modes, decoded.Modes)
+				assert.Equal(t, len(env.ECDSASignature), len(decoded.ECDSASignature))
+				assert.Equal(t, env.PQCSignature, decoded.PQCSignature, "%s/%s", name, compressor)
+
+				// The binary PQC component runs to the end of the envelope
+				if name != EncodingBinary || compressor != CompressionNone {
+					_, err = DecodeEnvelope(encoded[:len(encoded)-1], DefaultEnvelopeLimits)
+					assert.ErrorIs(t, err, ErrMalformedEnvelope, "%s/%s truncated", name, compressor)
+				}
+			}
+		}
+	}
+
+	large := &Envelope{Version: EnvelopeV2, Modes: ModePQC, PQCSignature: make([]byte, 200)}
+	for _, name := range EnvelopeEncoders() {
+		encoded, err := lookupEncoder(name).AppendEncode(nil, large)
+		require.NoError(t, err)
+		_, err = DecodeEnvelope(encoded, EnvelopeLimits{MaxPQC: 100})
+		assert.ErrorIs(t, err, ErrComponentTooLarge, name)
+		compressed, err := zstdCompressor{}.AppendCompress(nil, encoded)
+		require.NoError(t, err)
+		_, err = DecodeEnvelope(compressed, EnvelopeLimits{MaxECDSA: 1, MaxPQC: 1})
+		assert.ErrorIs(t, err, ErrMalformedEnvelope, "%s: decompression is bounded by the limits", name)
+	}
+
+	_, err := DecodeEnvelope([]byte{0xFF, 1, 2}, DefaultEnvelopeLimits)
+	assert.ErrorIs(t, err, ErrMalformedEnvelope)
+	_, err = asn1Encoder{}.AppendEncode(nil, &Envelope{Version: EnvelopeV2})
+	assert.ErrorIs(t, err, ErrMalformedEnvelope, "no modes")
+}
+
+func TestWithEnvelopeEncoding(t *testing.T) {
+	_, err := New(WithEnvelopeEncoding("protobuf", CompressionNone))
+	assert.ErrorContains(t, err, "unknown envelope encoder")
+	_, err = New(WithEnvelopeEncoding(EncodingBinary, "brotli"))
+	assert.ErrorContains(t, err, "unknown compressor")
+
+	verifier, err := New()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("encoding"))
+	for _, tc := range []struct {
+		encoder, compressor string
+		prefix              []byte
+	}{
+		{EncodingBinary, CompressionNone, []byte{EnvelopeV2}},
+		{EncodingASN1, CompressionNone, []byte{0x30}},
+		{EncodingCOSE, CompressionNone, []byte{0xD8, 98}},
+		{EncodingCOSE, CompressionZstd, zstdMagic},
+	} {
+		csp, err := New(WithEnvelopeEncoding(tc.encoder, tc.compressor))
+		require.NoError(t, err)
+		key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+		require.NoError(t, err)
+		signature, err := csp.Sign(key, digest[:], nil)
+		require.NoError(t, err)
+		assert.Equal(t, tc.prefix, signature[:len(tc.prefix)], tc.encoder)
+
+		// Verify detects the encoding, whatever its own provider emits
+		pub, err := key.PublicKey()
+		require.NoError(t, err)
+		valid, err := verifier.Verify(pub, signature, digest[:], nil)
+		require.NoError(t, err, "%s/%s", tc.encoder, tc.compressor)
+		assert.True(t, valid)
+
+		other := sha256.Sum256([]byte("other"))
+		valid, _ = verifier.Verify(pub, signature, other[:], nil)
+		assert.False(t, valid)
+	}
+}
+
+func TestRegisterEnvelopeEncoderDuplicate(t *testing.T) {
+	assert.Panics(t, func() { RegisterEnvelopeEncoder(EncodingBinary, binaryEncoder{}) })
+	assert.Panics(t, func() { RegisterCompressor("", noCompression{}) })
+}
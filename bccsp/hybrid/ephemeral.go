@@ -0,0 +1,117 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EphemeralKey describes a temporary key held by the provider
+type EphemeralKey struct {
+	SKI       []byte
+	CreatedAt time.Time
+	// OnDisk is set for keys whose ECDSA half the SW keystore wrote to its
+	// temporary directory
+	OnDisk bool
+}
+
+// ephemeralKeys tracks the temporary keys of a provider: keys generated or
+// imported with Temporary, and every key of a provider without a namespace
+// keystore. The PQC half of the latter is never persisted, so the ECDSA file
+// the SW keystore leaves in the temporary directory outlives a key that can
+// no longer be loaded.
+type ephemeralKeys struct {
+	mutex sync.Mutex
+	keys  map[string]*trackedKey
+	// dir is the directory of the SW keystore, empty if it is not on disk
+	dir string
+}
+
+type trackedKey struct {
+	key     *hybridKey
+	created time.Time
+	onDisk  bool
+}
+
+// trackEphemeral records k if it is temporary
+func (h *HybridBCCSP) trackEphemeral(k *hybridKey, temporary bool) {
+	if !temporary && h.keystore != nil {
+		return
+	}
+	e := &h.ephemeral
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.keys == nil {
+		e.keys = map[string]*trackedKey{}
+	}
+	e.keys[hex.EncodeToString(k.SKI())] = &trackedKey{key: k, created: time.Now(), onDisk: !temporary && e.dir != ""}
+	h.metrics.EphemeralKeys.Set(float64(len(e.keys)))
+}
+
+// EphemeralKeys lists the temporary keys held by the provider, oldest first
+func (h *HybridBCCSP) EphemeralKeys() []EphemeralKey {
+	e := &h.ephemeral
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	keys := make([]EphemeralKey, 0, len(e.keys))
+	for _, t := range e.keys {
+		keys = append(keys, EphemeralKey{SKI: t.key.SKI(), CreatedAt: t.created, OnDisk: t.onDisk})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// PurgeEphemeral forgets the temporary keys created more than olderThan ago,
+// drops their PQC private key and deletes their files from the SW keystore
+// directory. Purged keys can no longer sign. It returns the number of keys
+// purged and the file deletions that failed.
+func (h *HybridBCCSP) PurgeEphemeral(olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	e := &h.ephemeral
+	e.mutex.Lock()
+	var purged []*trackedKey
+	for ski, t := range e.keys {
+		if !t.created.After(cutoff) {
+			purged = append(purged, t)
+			delete(e.keys, ski)
+		}
+	}
+	h.metrics.EphemeralKeys.Set(float64(len(e.keys)))
+	dir := e.dir
+	e.mutex.Unlock()
+
+	var errs []error
+	for _, t := range purged {
+		if t.key.pqcPriv != nil {
+			t.key.pqcPriv.Clean()
+		}
+		unlockKey(t.key)
+		if !t.onDisk {
+			continue
+		}
+		// File names of the Fabric SW keystore
+		name := hex.EncodeToString(t.key.SKI())
+		for _, suffix := range []string{"_sk", "_pk"} {
+			if err := os.Remove(filepath.Join(dir, name+suffix)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	h.metrics.EphemeralKeysPurged.Add(float64(len(purged)))
+	return len(purged), errors.Join(errs...)
+}
+
+// Close purges every temporary key of the provider and waits for the queued
+// audit events to reach the audit sink
+func (h *HybridBCCSP) Close() error {
+	if h.auditSink != nil {
+		h.auditSink.Flush()
+	}
+	_, err := h.PurgeEphemeral(0)
+	return err
+}
@@ -0,0 +1,79 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeEphemeral(t *testing.T) {
+	gauge := &metricsfakes.Gauge{}
+	gauge.WithReturns(gauge)
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider := &metricsfakes.Provider{}
+	provider.NewGaugeReturns(gauge)
+	provider.NewCounterReturns(counter)
+
+	csp, err := New(WithMetricsProvider(provider))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+	dir := t.TempDir()
+	h.ephemeral.dir = dir
+
+	old, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	// Without a namespace keystore, SW writes the ECDSA half to its directory
+	stored, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	skFile := filepath.Join(dir, hex.EncodeToString(stored.SKI())+"_sk")
+	require.NoError(t, os.WriteFile(skFile, []byte("key"), 0o600))
+	_, err = csp.KeyGen(&HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	keys := h.EphemeralKeys()
+	require.Len(t, keys, 2, "KEM keys hold no PQC signer and are not tracked")
+	assert.Equal(t, old.SKI(), keys[0].SKI)
+	assert.False(t, keys[0].OnDisk)
+	assert.True(t, keys[1].OnDisk)
+	assert.Equal(t, 2.0, gauge.SetArgsForCall(gauge.SetCallCount()-1))
+
+	h.ephemeral.keys[hex.EncodeToString(old.SKI())].created = time.Now().Add(-2 * time.Hour)
+	n, err := h.PurgeEphemeral(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, h.EphemeralKeys(), 1)
+	_, err = csp.Sign(old, make([]byte, 32), nil)
+	assert.Error(t, err, "purged keys cannot sign")
+	assert.FileExists(t, skFile)
+
+	require.NoError(t, h.Close())
+	assert.Empty(t, h.EphemeralKeys())
+	assert.NoFileExists(t, skFile)
+	assert.Equal(t, 0.0, gauge.SetArgsForCall(gauge.SetCallCount()-1))
+	assert.Equal(t, 1.0, counter.AddArgsForCall(counter.AddCallCount()-1))
+}
+
+func TestEphemeralWithKeyStore(t *testing.T) {
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithKeyStore(ks, "Org1MSP"))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+	_, err = csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	tmp, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	keys := h.EphemeralKeys()
+	require.Len(t, keys, 1, "stored keys are not ephemeral")
+	assert.Equal(t, tmp.SKI(), keys[0].SKI)
+	assert.False(t, keys[0].OnDisk)
+}
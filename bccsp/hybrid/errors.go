@@ -0,0 +1,135 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// Errors returned for invalid arguments to the BCCSP methods
+var (
+	// ErrNilKey is returned for a nil key, including typed nil pointers
+	ErrNilKey = errors.New("key is nil")
+	// ErrNilOpts is returned when required opts are nil
+	ErrNilOpts = errors.New("opts are nil")
+	// ErrNilKeyMaterial is returned by KeyImport for nil raw material
+	ErrNilKeyMaterial = errors.New("raw key material is nil")
+	// ErrEmptySKI is returned by GetKey for an empty SKI
+	ErrEmptySKI = errors.New("SKI is empty")
+	// ErrEmptySignature is returned by Verify for an empty signature
+	ErrEmptySignature = errors.New("signature is empty")
+	// ErrPublicKeyOnly is returned when signing or decrypting with a key
+	// that has no private part
+	ErrPublicKeyOnly = errors.New("key has no private part")
+)
+
+// isNilKey reports whether k is nil or a nil pointer
+func isNilKey(k bccsp.Key) bool {
+	if k == nil {
+		return true
+	}
+	v := reflect.ValueOf(k)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// isNilOpts reports whether opts is nil or a nil pointer
+func isNilOpts(opts interface{}) bool {
+	if opts == nil {
+		return true
+	}
+	v := reflect.ValueOf(opts)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// Operations of a CryptoError
+const (
+	OpSign      = "sign"
+	OpVerify    = "verify"
+	OpKeyGen    = "keygen"
+	OpKeyImport = "keyimport"
+	OpKeyDeriv  = "keyderiv"
+	OpGetKey    = "getkey"
+	OpHash      = "hash"
+	OpEncrypt   = "encrypt"
+	OpDecrypt   = "decrypt"
+	OpWrapKey   = "wrapkey"
+	OpUnwrapKey = "unwrapkey"
+)
+
+// skiLogBytes is how much of an SKI a CryptoError prints, enough to find
+// the key in a keystore listing
+const skiLogBytes = 8
+
+// CryptoError is the context of a failed provider operation: the operation,
+// the algorithm of the step that failed (AlgorithmHybrid when it was not a
+// single component) and the key. Every provider method returns its errors
+// wrapped in one, so peer logs name the key and step without a debug build;
+// errors.Is still matches the sentinel errors it wraps.
+type CryptoError struct {
+	Op        string
+	Algorithm string
+	SKI       []byte
+	Err       error
+}
+
+func (e *CryptoError) Error() string {
+	msg := "hybrid " + e.Op
+	if e.Algorithm != "" {
+		msg += " " + e.Algorithm
+	}
+	if len(e.SKI) > 0 {
+		ski := hex.EncodeToString(e.SKI[:min(len(e.SKI), skiLogBytes)])
+		if len(e.SKI) > skiLogBytes {
+			ski += "..."
+		}
+		msg += " key " + ski
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *CryptoError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError wraps err in a CryptoError, unless a step below already did
+func wrapError(op, algorithm string, ski []byte, err error) error {
+	var ce *CryptoError
+	if err == nil || errors.As(err, &ce) {
+		return err
+	}
+	return &CryptoError{Op: op, Algorithm: algorithm, SKI: ski, Err: err}
+}
+
+// wrapKeyError wraps err in a CryptoError for key k
+func wrapKeyError(op string, k bccsp.Key, err error) error {
+	if err == nil || isNilKey(k) {
+		return wrapError(op, "", nil, err)
+	}
+	return wrapError(op, algorithmOf(k), skiOf(k), err)
+}
+
+// skiOf returns the SKI of k, nil for a hybrid key without its ECDSA half
+func skiOf(k bccsp.Key) []byte {
+	if hk, ok := k.(*hybridKey); ok && hk.ecdsaKey == nil {
+		return nil
+	}
+	return k.SKI()
+}
+
+// algorithmOf names the algorithm of k for a CryptoError
+func algorithmOf(k bccsp.Key) string {
+	if _, ok := k.(*hybridKEMKey); ok {
+		return KEMAlgorithm
+	}
+	switch KindOf(k) {
+	case KindHybrid:
+		return AlgorithmHybrid
+	case KindLMS:
+		return AlgorithmLMS
+	case KindClassical:
+		return AlgorithmECDSA
+	}
+	return ""
+}
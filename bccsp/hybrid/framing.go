@@ -0,0 +1,194 @@
+package hybrid
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+var (
+	// ErrInvalidFrame is returned when a message does not have the layout
+	// its framer expects
+	ErrInvalidFrame = errors.New("message does not fit the framing")
+	// ErrFrameMismatch is returned by Verify when the signature was not made
+	// with the framing the verifier selected
+	ErrFrameMismatch = errors.New("signature framing does not match")
+)
+
+// frameDomain prefixes the frames of named framers
+const frameDomain = "QLFRAME1"
+
+// MessageFramer defines the exact bytes the ML-DSA component signs in place
+// of the digest. HybridSignerOpts.Framer selects one per call; without it
+// the digest policy decides between RawDigest and PureMessage.
+//
+// The frame is still bound to the envelope header like the digest, see
+// Envelope.SignedMessages. The ECDSA component always signs the digest.
+type MessageFramer interface {
+	// Frame returns the PQC message for message, HybridSignerOpts.Message,
+	// nil when the caller did not pass one. A nil frame means the PQC
+	// component signs the digest; otherwise the provider checks that the
+	// message hashes to the digest.
+	Frame(message []byte) ([]byte, error)
+}
+
+// FramerFunc adapts a function to MessageFramer
+type FramerFunc func(message []byte) ([]byte, error)
+
+// Frame implements MessageFramer
+func (f FramerFunc) Frame(message []byte) ([]byte, error) {
+	return f(message)
+}
+
+var (
+	// RawDigest signs the digest, the default
+	RawDigest MessageFramer = FramerFunc(func([]byte) ([]byte, error) { return nil, nil })
+	// PureMessage signs the message unchanged, the layout of
+	// DigestPolicy.PureMLDSA
+	PureMessage MessageFramer = FramerFunc(pureFrame)
+	// ProposalFraming signs the bytes of a Fabric peer.Proposal, rejecting
+	// anything without a valid channel header
+	ProposalFraming = NewFramer("proposal", checkProposal)
+	// TBSCertificateFraming signs the DER of a TBSCertificate, e.g. the
+	// pre-TBS of a composite certificate
+	TBSCertificateFraming = NewFramer("tbs-certificate", checkTBSCertificate)
+)
+
+func pureFrame(message []byte) ([]byte, error) {
+	if message == nil {
+		return nil, ErrMessageRequired
+	}
+	return message, nil
+}
+
+// NewFramer returns a framer for one kind of message. Its frames are
+// [QLFRAME1][len(name)][name][message], so a signature over one kind of
+// message never verifies as another. check, when not nil, validates the
+// message.
+func NewFramer(name string, check func(message []byte) error) MessageFramer {
+	if name == "" || len(name) > 255 {
+		panic("hybrid: framer name must be 1 to 255 bytes")
+	}
+	return &namedFramer{name: name, check: check}
+}
+
+type namedFramer struct {
+	name  string
+	check func([]byte) error
+}
+
+func (f *namedFramer) Frame(message []byte) ([]byte, error) {
+	if message == nil {
+		return nil, ErrMessageRequired
+	}
+	if f.check != nil {
+		if err := f.check(message); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidFrame, f.name, err)
+		}
+	}
+	frame := make([]byte, 0, len(frameDomain)+1+len(f.name)+len(message))
+	frame = append(frame, frameDomain...)
+	frame = append(frame, byte(len(f.name)))
+	frame = append(frame, f.name...)
+	return append(frame, message...), nil
+}
+
+func (f *namedFramer) String() string {
+	return f.name
+}
+
+func checkProposal(message []byte) error {
+	_, err := fabproto.ProposalChannelHeader(message)
+	return err
+}
+
+func checkTBSCertificate(message []byte) error {
+	var tbs asn1.RawValue
+	rest, err := asn1.Unmarshal(message, &tbs)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 || tbs.Class != asn1.ClassUniversal || tbs.Tag != asn1.TagSequence {
+		return errors.New("not a DER SEQUENCE")
+	}
+	return nil
+}
+
+// optsFramer returns the framer selected by opts, nil if none
+func optsFramer(opts bccsp.SignerOpts) MessageFramer {
+	if o, ok := opts.(*HybridSignerOpts); ok && o != nil {
+		return o.Framer
+	}
+	return nil
+}
+
+// frame returns what the PQC component signs in place of the digest, nil
+// for the digest itself. Framed messages must hash to digest.
+func (h *HybridBCCSP) frame(f MessageFramer, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	var message []byte
+	if o, ok := opts.(*HybridSignerOpts); ok && o != nil {
+		message = o.Message
+	}
+	frame, err := f.Frame(message)
+	if err != nil || frame == nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, ErrMessageRequired
+	}
+	if err := h.checkMessage(digest, message, opts); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// signFrame returns the PQC message of Sign: the framer of opts, else the
+// one of the digest policy
+func (h *HybridBCCSP) signFrame(digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	f := optsFramer(opts)
+	if f == nil {
+		f = RawDigest
+		if h.digestPolicy.PureMLDSA {
+			f = PureMessage
+		}
+	}
+	return h.frame(f, digest, opts)
+}
+
+// verifyFrame returns the PQC message of Verify. Without a framer in opts,
+// pure envelopes are checked as PureMessage.
+func (h *HybridBCCSP) verifyFrame(env *Envelope, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	f := optsFramer(opts)
+	if f == nil {
+		if !env.Modes.Has(ModePure) {
+			return nil, nil
+		}
+		return h.frame(PureMessage, digest, opts)
+	}
+	frame, err := h.frame(f, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	if (frame != nil) != env.Modes.Has(ModePure) {
+		return nil, fmt.Errorf("%w: envelope modes %s", ErrFrameMismatch, env.Modes)
+	}
+	return frame, nil
+}
+
+// checkMessage checks that digest is the hash of message
+func (h *HybridBCCSP) checkMessage(digest, message []byte, opts bccsp.SignerOpts) error {
+	hash := h.digestHash(opts)
+	if hash == 0 || !hash.Available() {
+		return fmt.Errorf("%w: no usable hash configured to check the message", ErrInvalidDigest)
+	}
+	hh := hash.New()
+	hh.Write(message)
+	if !bytes.Equal(hh.Sum(nil), digest) {
+		return ErrDigestMismatch
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func testProposal() []byte {
+	chdr := &fabproto.ChannelHeader{Type: 3, ChannelId: "mychannel", TxId: "tx1"}
+	hdr := &fabproto.Header{ChannelHeader: chdr.Marshal(), SignatureHeader: []byte("creator")}
+	return (&fabproto.Proposal{Header: hdr.Marshal(), Payload: []byte("invoke")}).Marshal()
+}
+
+func TestFrameLayout(t *testing.T) {
+	frame, err := NewFramer("test", nil).Frame([]byte("msg"))
+	require.NoError(t, err)
+	assert.Equal(t, "514c4652414d4531"+"04"+"74657374"+"6d7367", hex.EncodeToString(frame))
+
+	frame, err = RawDigest.Frame([]byte("msg"))
+	require.NoError(t, err)
+	assert.Nil(t, frame)
+	frame, err = PureMessage.Frame([]byte("msg"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("msg"), frame)
+
+	_, err = ProposalFraming.Frame([]byte("not a proposal"))
+	assert.True(t, errors.Is(err, ErrInvalidFrame))
+	_, err = TBSCertificateFraming.Frame(testProposal())
+	assert.True(t, errors.Is(err, ErrInvalidFrame))
+	_, err = TBSCertificateFraming.Frame(nil)
+	assert.True(t, errors.Is(err, ErrMessageRequired))
+}
+
+func TestFramedSignature(t *testing.T) {
+	csp, err := New()
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	proposal := testProposal()
+	digest := sha256.Sum256(proposal)
+	_, err = csp.Sign(k, digest[:], &HybridSignerOpts{Framer: ProposalFraming})
+	assert.True(t, errors.Is(err, ErrMessageRequired), "a digest alone cannot be framed")
+	other := sha256.Sum256([]byte("other"))
+	_, err = csp.Sign(k, other[:], &HybridSignerOpts{Framer: ProposalFraming, Message: proposal})
+	assert.True(t, errors.Is(err, ErrDigestMismatch))
+
+	opts := &HybridSignerOpts{Framer: ProposalFraming, Message: proposal}
+	sig, err := csp.Sign(k, digest[:], opts)
+	require.NoError(t, err)
+	env, err := ParseEnvelope(sig)
+	require.NoError(t, err)
+	assert.True(t, env.Modes.Has(ModePure))
+	valid, err := csp.Verify(k, sig, digest[:], opts)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// The same bytes framed as another kind of message, or as a plain pure
+	// message, do not verify
+	custom := NewFramer("custom", nil)
+	valid, err = csp.Verify(k, sig, digest[:], &HybridSignerOpts{Framer: custom, Message: proposal})
+	require.NoError(t, err)
+	assert.False(t, valid)
+	valid, err = csp.Verify(k, sig, digest[:], &HybridSignerOpts{Message: proposal})
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// A digest signature is not accepted where a framed one is expected
+	plain, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	_, err = csp.Verify(k, plain, digest[:], opts)
+	assert.True(t, errors.Is(err, ErrFrameMismatch))
+	valid, err = csp.Verify(k, plain, digest[:], &HybridSignerOpts{Framer: RawDigest})
+	require.NoError(t, err)
+	assert.True(t, valid)
+	_, err = csp.Verify(k, sig, digest[:], &HybridSignerOpts{Framer: RawDigest})
+	assert.True(t, errors.Is(err, ErrFrameMismatch))
+}
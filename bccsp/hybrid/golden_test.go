@@ -0,0 +1,119 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/golden"
+)
+
+// ML-DSA-65 sizes of the fixed PQC inputs
+const (
+	goldenPQCPublicKeySize  = 1952
+	goldenPQCPrivateKeySize = 4032
+	goldenPQCSignatureSize  = 3309
+)
+
+// goldenBytes expands label into n fixed bytes. The golden files are built
+// from these rather than from generated keys, so they only change with the
+// formats.
+func goldenBytes(label string, n int) []byte {
+	var out []byte
+	for i := uint32(0); len(out) < n; i++ {
+		h := sha256.New()
+		h.Write([]byte(label))
+		binary.Write(h, binary.BigEndian, i)
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+func goldenComposite(t *testing.T) []byte {
+	t.Helper()
+	ecdsaKey, err := ecdh.P256().NewPrivateKey(goldenBytes("ecdsa", 32))
+	require.NoError(t, err)
+	spki, err := x509.MarshalPKIXPublicKey(ecdsaKey.PublicKey())
+	require.NoError(t, err)
+	der, err := MarshalCompositePublicKey(spki, goldenBytes("pqc public key", goldenPQCPublicKeySize))
+	require.NoError(t, err)
+	return der
+}
+
+func TestGoldenKeys(t *testing.T) {
+	der := goldenComposite(t)
+	golden.Assert(t, "composite_public_key.der", der)
+	ecdsaDER, pqcPub, err := core.ParseCompositePublicKey(golden.Read(t, "composite_public_key.der"))
+	require.NoError(t, err)
+	assert.Equal(t, goldenBytes("pqc public key", goldenPQCPublicKeySize), pqcPub)
+	_, err = x509.ParsePKIXPublicKey(ecdsaDER)
+	require.NoError(t, err)
+
+	j, err := core.NewPublicKeyJSON(der, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	raw, err := json.MarshalIndent(j, "", "  ")
+	require.NoError(t, err)
+	golden.Assert(t, "public_key.json", append(raw, '\n'))
+	parsed, err := core.ParsePublicKeyJSON(golden.Read(t, "public_key.json"))
+	require.NoError(t, err)
+	composite, err := parsed.Composite()
+	require.NoError(t, err)
+	assert.Equal(t, der, composite)
+}
+
+// TestGoldenKeystoreFile pins the PQC key files; the ECDSA ones are written
+// by the Fabric SW keystore
+func TestGoldenKeystoreFile(t *testing.T) {
+	priv := goldenBytes("pqc private key", goldenPQCPrivateKeySize)
+	pub := goldenBytes("pqc public key", goldenPQCPublicKeySize)
+	raw, err := marshalPQCKeyFile(priv, pub)
+	require.NoError(t, err)
+	golden.Assert(t, "keystore_pqc.pem", raw)
+
+	key, err := parsePQCKeyFile(golden.Read(t, "keystore_pqc.pem"))
+	require.NoError(t, err)
+	assert.Equal(t, priv, key.PrivateKey)
+	assert.Equal(t, pub, key.PublicKey)
+}
+
+func TestGoldenEnvelopes(t *testing.T) {
+	// The ECDSA component is an ASN.1 SEQUENCE { r, s } with low s
+	ecdsaSig, err := asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).SetBytes(goldenBytes("r", 31)),
+		new(big.Int).SetBytes(goldenBytes("s", 31)),
+	})
+	require.NoError(t, err)
+	golden.Assert(t, "signature_ecdsa.der", ecdsaSig)
+	pqcSig := goldenBytes("pqc signature", goldenPQCSignatureSize)
+
+	for _, c := range []struct {
+		name string
+		env  Envelope
+	}{
+		{"signature_v1.bin", Envelope{Version: core.EnvelopeV1, Modes: core.ModeClassical | core.ModePQC, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}},
+		{"signature_v2.bin", Envelope{Version: core.EnvelopeV2, Modes: core.ModeClassical | core.ModePQC, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}},
+		{"signature_v2le.bin", Envelope{Version: core.EnvelopeV2LE, Modes: core.ModeClassical | core.ModePQC, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}},
+		{"signature_v2_classical.bin", Envelope{Version: core.EnvelopeV2, Modes: core.ModeClassical, ECDSASignature: ecdsaSig}},
+		{"signature_v2_pure.bin", Envelope{Version: core.EnvelopeV2, Modes: core.ModePQC | core.ModePure, PQCSignature: pqcSig}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			golden.Assert(t, c.name, c.env.Marshal())
+			parsed, err := ParseEnvelope(golden.Read(t, c.name))
+			require.NoError(t, err)
+			assert.Equal(t, c.env.Version, parsed.Version)
+			assert.Equal(t, c.env.Modes, parsed.Modes)
+			assert.True(t, bytes.Equal(c.env.ECDSASignature, parsed.ECDSASignature), "ECDSA component")
+			assert.True(t, bytes.Equal(c.env.PQCSignature, parsed.PQCSignature), "PQC component")
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/lms"
+)
+
+// LMS is the algorithm identifier of stateful LMS (RFC 8554) keys, meant for
+// long-term root-of-trust keys that sign rarely
+const LMS = "LMS"
+
+// lmsKeySuffix names the LMS private keys of a keystore namespace
+const lmsKeySuffix = "_lms"
+
+// PEM types of the LMS private keys stored by a keystore, holding the
+// lms.MarshalPrivateKey encoding
+const (
+	lmsPrivateKeyPEMType          = "LMS PRIVATE KEY"
+	encryptedLMSPrivateKeyPEMType = "ENCRYPTED LMS PRIVATE KEY"
+)
+
+// ErrNoDurableState is returned by LMS key generation, import and lookup on
+// a provider whose usage store does not survive a restart: the signing
+// state would go back and one-time keys would be used twice
+var ErrNoDurableState = errors.New("LMS keys need a durable usage store")
+
+// LMSKeyGenOpts contains options for generating an LMS key pair
+type LMSKeyGenOpts struct {
+	// Type defaults to LMS_SHA256_M32_H10 (1024 signatures)
+	Type lms.Type
+	// OTSType defaults to LMOTS_SHA256_N32_W8
+	OTSType   lms.OTSType
+	Temporary bool
+}
+
+// Algorithm returns the key generation algorithm identifier (to be used).
+func (opts *LMSKeyGenOpts) Algorithm() string {
+	return LMS
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *LMSKeyGenOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// LMSPrivateKeyImportOpts contains options for importing an LMS private key
+// encoded by lms.MarshalPrivateKey. The signing state of the key is the
+// counter of its SKI in the provider's usage store: a key that signed
+// elsewhere must come with that counter, or it reuses one-time keys.
+type LMSPrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *LMSPrivateKeyImportOpts) Algorithm() string {
+	return LMS
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *LMSPrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// LMSPublicKeyImportOpts contains options for importing an RFC 8554 encoded LMS public key
+type LMSPublicKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *LMSPublicKeyImportOpts) Algorithm() string {
+	return LMS
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *LMSPublicKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// lmsKey is an LMS key; priv is nil for public keys
+type lmsKey struct {
+	pub  *lms.PublicKey
+	priv *lms.PrivateKey
+}
+
+func (k *lmsKey) Bytes() ([]byte, error) {
+	return k.pub.Bytes(), nil
+}
+
+func (k *lmsKey) SKI() []byte {
+	return k.pub.SKI()
+}
+
+func (k *lmsKey) Symmetric() bool {
+	return false
+}
+
+func (k *lmsKey) Private() bool {
+	return k.priv != nil
+}
+
+func (k *lmsKey) PublicKey() (bccsp.Key, error) {
+	return &lmsKey{pub: k.pub}, nil
+}
+
+// lmsState returns the usage store keeping the signing state of LMS keys,
+// and checks that non-temporary keys can be stored in a keystore namespace,
+// the only place they can be reloaded from
+func (h *HybridBCCSP) lmsState(temporary bool) (lms.StateStore, error) {
+	if h.usage == nil {
+		return nil, fmt.Errorf("%w, see WithUsageStore", ErrNoDurableState)
+	}
+	if _, ok := h.usage.(*MemoryUsageStore); ok {
+		return nil, fmt.Errorf("%w, the memory usage store forgets it on restart", ErrNoDurableState)
+	}
+	if !temporary && h.keystore == nil {
+		return nil, errors.New("non-temporary LMS keys need a keystore namespace, see WithKeyStore")
+	}
+	return h.usage, nil
+}
+
+// lmsKeyGen generates an LMS key whose state is kept in the provider's usage
+// store, and stores non-temporary keys in the keystore namespace
+func (h *HybridBCCSP) lmsKeyGen(opts *LMSKeyGenOpts) (*lmsKey, error) {
+	t, ots := opts.Type, opts.OTSType
+	if t == 0 {
+		t = lms.LMS_SHA256_M32_H10
+	}
+	if ots == 0 {
+		ots = lms.LMOTS_SHA256_N32_W8
+	}
+	store, err := h.lmsState(opts.Temporary)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := lms.GenerateKey(rand.Reader, t, ots, store)
+	if err != nil {
+		return nil, fmt.Errorf("LMS KeyGen failed: %w", err)
+	}
+	k := &lmsKey{pub: &priv.PublicKey, priv: priv}
+	if !opts.Temporary {
+		if err := h.keystore.StoreKey(h.namespace, k); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// importLMSPrivateKey imports a key encoded by lms.MarshalPrivateKey
+func (h *HybridBCCSP) importLMSPrivateKey(raw interface{}, temporary bool) (*lmsKey, error) {
+	der, ok := raw.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid raw material, expected []byte")
+	}
+	store, err := h.lmsState(temporary)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := lms.ParsePrivateKey(der, store)
+	if err != nil {
+		return nil, err
+	}
+	k := &lmsKey{pub: &priv.PublicKey, priv: priv}
+	if !temporary {
+		if err := h.keystore.StoreKey(h.namespace, k); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// getLMSKey loads an LMS key from the provider's keystore namespace
+func (h *HybridBCCSP) getLMSKey(ski []byte) (bccsp.Key, error) {
+	der, err := h.keystore.readLMSKey(h.namespace, ski)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+	store, err := h.lmsState(false)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := lms.ParsePrivateKey(der, store)
+	if err != nil {
+		return nil, fmt.Errorf("key %x in %s: %w", ski, h.namespace, err)
+	}
+	if !bytes.Equal(priv.PublicKey.SKI(), ski) {
+		return nil, fmt.Errorf("%w: LMS key stored as %x has SKI %x", ErrSKIMismatch, ski, priv.PublicKey.SKI())
+	}
+	return &lmsKey{pub: &priv.PublicKey, priv: priv}, nil
+}
+
+// storeLMSKey writes the private LMS key k to ns, encrypted if the keystore
+// has a passphrase. The signing state is not part of the file.
+func (s *KeyStore) storeLMSKey(ns string, k *lmsKey) error {
+	if k.priv == nil {
+		return ErrPublicKeyOnly
+	}
+	dir, _, err := s.namespace(ns)
+	if err != nil {
+		return err
+	}
+	der := lms.MarshalPrivateKey(k.priv)
+	defer clear(der)
+	raw := pem.EncodeToMemory(&pem.Block{Type: lmsPrivateKeyPEMType, Bytes: der})
+	if c := s.keyCipher(); c != nil {
+		if raw, err = c.sealDER(k.SKI(), der, encryptedLMSPrivateKeyPEMType); err != nil {
+			return err
+		}
+	}
+	if err := writeFileSync(filepath.Join(dir, hex.EncodeToString(k.SKI())+lmsKeySuffix), raw); err != nil {
+		return fmt.Errorf("failed to store LMS key: %w", err)
+	}
+	return nil
+}
+
+// readLMSKey returns the lms.MarshalPrivateKey encoding of the LMS key ski
+// of ns, decrypted
+func (s *KeyStore) readLMSKey(ns string, ski []byte) ([]byte, error) {
+	dir, _, err := s.namespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, hex.EncodeToString(ski)+lmsKeySuffix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %x in %s", ErrKeyNotFound, ski, ns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	switch {
+	case block == nil:
+		return nil, fmt.Errorf("key %x in %s: no LMS private key PEM block", ski, ns)
+	case block.Type == lmsPrivateKeyPEMType:
+		return block.Bytes, nil
+	case block.Type == encryptedLMSPrivateKeyPEMType:
+		c := s.keyCipher()
+		if c == nil {
+			return nil, fmt.Errorf("key %x in %s: %w: the LMS key is encrypted and the keystore has no passphrase", ski, ns, ErrKeyStorePassphrase)
+		}
+		der, err := c.openDER(ski, block)
+		if err != nil {
+			return nil, fmt.Errorf("key %x in %s: %w", ski, ns, err)
+		}
+		return der, nil
+	default:
+		return nil, fmt.Errorf("key %x in %s: unexpected PEM block %q", ski, ns, block.Type)
+	}
+}
+
+func (h *HybridBCCSP) lmsSign(k *lmsKey, digest []byte) ([]byte, error) {
+	if k.priv == nil {
+		return nil, fmt.Errorf("LMS public key cannot sign: %w", ErrPublicKeyOnly)
+	}
+	return k.priv.Sign(rand.Reader, digest)
+}
+
+func importLMSPublicKey(raw interface{}) (*lmsKey, error) {
+	der, ok := raw.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid raw material, expected []byte")
+	}
+	pub, err := lms.ParsePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	return &lmsKey{pub: pub}, nil
+}
@@ -0,0 +1,164 @@
+package hybrid
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/lms"
+)
+
+func TestLMSSignVerify(t *testing.T) {
+	store, err := NewFileUsageStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithUsageStore(store))
+	require.NoError(t, err)
+
+	k, err := csp.KeyGen(&LMSKeyGenOpts{Type: lms.LMS_SHA256_M32_H5, Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("root certificate"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	// The index was persisted before the signature was returned
+	n, err := store.Count(k.SKI())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	raw, err := pub.Bytes()
+	require.NoError(t, err)
+	imported, err := csp.KeyImport(raw[:8], &LMSPublicKeyImportOpts{})
+	assert.Error(t, err)
+	assert.True(t, imported == nil, "no typed nil key")
+	imported, err = csp.KeyImport(raw, &LMSPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+
+	valid, err := csp.Verify(imported, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = csp.Sign(imported, digest[:], nil)
+	require.Error(t, err)
+}
+
+func TestLMSNeedsDurableState(t *testing.T) {
+	opts := &LMSKeyGenOpts{Type: lms.LMS_SHA256_M32_H5, Temporary: true}
+	for name, options := range map[string][]Option{
+		"no usage store":     nil,
+		"memory usage store": {WithUsageStore(NewMemoryUsageStore())},
+		"max signatures":     {WithMaxSignatures(10)},
+	} {
+		csp, err := New(options...)
+		require.NoError(t, err)
+		_, err = csp.KeyGen(opts)
+		assert.ErrorIs(t, err, ErrNoDurableState, name)
+		_, err = csp.KeyImport(make([]byte, 56), &LMSPrivateKeyImportOpts{Temporary: true})
+		assert.ErrorIs(t, err, ErrNoDurableState, name)
+	}
+
+	store, err := NewFileUsageStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithUsageStore(store))
+	require.NoError(t, err)
+	_, err = csp.KeyGen(&LMSKeyGenOpts{Type: lms.LMS_SHA256_M32_H5})
+	assert.ErrorContains(t, err, "keystore", "a non-temporary key could not be reloaded")
+}
+
+// lmsIndex returns q, the index of the one-time key of an LMS signature
+func lmsIndex(sig []byte) uint32 {
+	return binary.BigEndian.Uint32(sig)
+}
+
+func TestLMSRestart(t *testing.T) {
+	dir := t.TempDir()
+	open := func() bccsp.BCCSP {
+		t.Helper()
+		ks, err := NewKeyStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, ks.SetPassphrase([]byte("lms keystore passphrase")))
+		store, err := NewFileUsageStore(filepath.Join(dir, "usage"))
+		require.NoError(t, err)
+		csp, err := New(WithKeyStore(ks, "RootCA"), WithUsageStore(store))
+		require.NoError(t, err)
+		return csp
+	}
+
+	csp := open()
+	k, err := csp.KeyGen(&LMSKeyGenOpts{Type: lms.LMS_SHA256_M32_H5})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(filepath.Join(dir, "RootCA", hex.EncodeToString(k.SKI())+lmsKeySuffix))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), encryptedLMSPrivateKeyPEMType)
+
+	digest := sha256.Sum256([]byte("root certificate"))
+	var last uint32
+	for i := 0; i < 2; i++ {
+		sig, err := csp.Sign(k, digest[:], nil)
+		require.NoError(t, err)
+		last = lmsIndex(sig)
+	}
+	assert.Equal(t, uint32(1), last)
+
+	// A restarted provider reloads the key and resumes after the last index
+	for restart := 0; restart < 2; restart++ {
+		csp = open()
+		reloaded, err := csp.GetKey(k.SKI())
+		require.NoError(t, err)
+		assert.Equal(t, KindLMS, KindOf(reloaded))
+		sig, err := csp.Sign(reloaded, digest[:], nil)
+		require.NoError(t, err)
+		assert.Greater(t, lmsIndex(sig), last, "restart %d", restart)
+		last = lmsIndex(sig)
+		valid, err := csp.Verify(reloaded, sig, digest[:], nil)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	// Without the passphrase the key does not load
+	ks, err := NewKeyStore(dir)
+	require.NoError(t, err)
+	store, err := NewFileUsageStore(filepath.Join(dir, "usage"))
+	require.NoError(t, err)
+	locked, err := New(WithKeyStore(ks, "RootCA"), WithUsageStore(store))
+	require.NoError(t, err)
+	_, err = locked.GetKey(k.SKI())
+	assert.ErrorIs(t, err, ErrKeyStorePassphrase)
+	_, err = locked.GetKey([]byte{1, 2, 3})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestLMSPrivateKeyImport(t *testing.T) {
+	store, err := NewFileUsageStore(t.TempDir())
+	require.NoError(t, err)
+	priv, err := lms.GenerateKey(rand.Reader, lms.LMS_SHA256_M32_H5, lms.LMOTS_SHA256_N32_W8, store)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("signed before the import"))
+	_, err = priv.Sign(rand.Reader, digest[:])
+	require.NoError(t, err)
+
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithKeyStore(ks, "RootCA"), WithUsageStore(store))
+	require.NoError(t, err)
+	_, err = csp.KeyImport(lms.MarshalPrivateKey(priv)[:10], &LMSPrivateKeyImportOpts{})
+	assert.Error(t, err)
+	k, err := csp.KeyImport(lms.MarshalPrivateKey(priv), &LMSPrivateKeyImportOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, priv.PublicKey.SKI(), k.SKI())
+
+	// The imported key shares the counter of its SKI and is in the keystore
+	reloaded, err := csp.GetKey(k.SKI())
+	require.NoError(t, err)
+	sig, err := csp.Sign(reloaded, digest[:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), lmsIndex(sig))
+}
@@ -2,58 +2,366 @@
 package hybrid
 
 import (
+	"crypto"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"hash"
+	"sync"
+	"time"
+
 	"github.com/hyperledger/fabric-lib-go/bccsp"
 	"github.com/hyperledger/fabric-lib-go/bccsp/sw"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/disabled"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/core"
 )
 
 // HybridBCCSP implements BCCSP with hybrid ECDSA + ML-DSA-65 cryptography
 type HybridBCCSP struct {
 	sw bccsp.BCCSP
+
+	mutex    sync.RWMutex
+	policies PolicyResolver
+
+	downgradeProtection bool
+	legacyECDSA         bool
+
+	limiter   *RateLimiter
+	metrics   *Metrics
+	auditSink *audit.AsyncSink
+
+	usage         UsageStore
+	maxSignatures uint64
+
+	digestPolicy   DigestPolicy
+	envelopeFormat EnvelopeFormat
+	envelopeLimits EnvelopeLimits
+
+	encoderName, compressorName string
+	encoder                     EnvelopeEncoder
+	compressor                  Compressor
+
+	pool      *WorkerPool
+	verifiers *VerifierCache
+
+	keystore  *KeyStore
+	namespace string
+	dual      *DualKeyStore
+
+	breaker       *CircuitBreaker
+	verifyTimeout time.Duration
+	canary        *canary
+	memoryLocking bool
+
+	preload   *PreloadConfig
+	preloaded map[string]bccsp.Key
+
+	ephemeral ephemeralKeys
+
+	stats providerStats
+
+	profiling bool
+}
+
+// Option configures a HybridBCCSP
+type Option func(*HybridBCCSP)
+
+// WithPolicyResolver sets the resolver used to pick the verification policy
+func WithPolicyResolver(r PolicyResolver) Option {
+	return func(h *HybridBCCSP) {
+		h.policies = r
+	}
+}
+
+// WithPolicy verifies every signature with the same policy
+func WithPolicy(p Policy) Option {
+	return WithPolicyResolver(staticPolicy(p))
+}
+
+// WithDowngradeProtection controls whether Verify rejects envelopes missing a
+// component the signer offered, even when the policy would accept the rest.
+// It is enabled by default.
+func WithDowngradeProtection(enabled bool) Option {
+	return func(h *HybridBCCSP) {
+		h.downgradeProtection = enabled
+	}
+}
+
+// WithRateLimiter rejects Sign calls once the signing key exceeds its quota
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(h *HybridBCCSP) {
+		h.limiter = l
+	}
+}
+
+// WithMetricsProvider reports provider metrics through p
+func WithMetricsProvider(p metrics.Provider) Option {
+	return func(h *HybridBCCSP) {
+		h.metrics = NewMetrics(p)
+	}
+}
+
+// auditQueueSize is the number of audit events waiting for the sink before
+// new ones are dropped
+const auditQueueSize = 4096
+
+// WithAuditSink reports every KeyGen, Sign and Verify to s. Events are
+// queued and forwarded in the background, so a slow sink never delays an
+// operation; events that overflow the queue are dropped and counted. s is
+// not closed by the provider.
+func WithAuditSink(s audit.Sink) Option {
+	return func(h *HybridBCCSP) {
+		h.auditSink = audit.NewAsyncSink(s, auditQueueSize, func(e audit.Event, _ error) {
+			h.metrics.AuditEmitFailures.With("operation", string(e.Operation)).Add(1)
+		})
+	}
+}
+
+// WithUsageStore counts the signatures of every key in s. LMS keys keep
+// their signing state in it and need a durable store, e.g. a FileUsageStore.
+func WithUsageStore(s UsageStore) Option {
+	return func(h *HybridBCCSP) {
+		h.usage = s
+	}
+}
+
+// WithMaxSignatures makes Sign fail once a key produced n signatures. Without
+// a usage store, counters are kept in memory.
+func WithMaxSignatures(n uint64) Option {
+	return func(h *HybridBCCSP) {
+		h.maxSignatures = n
+	}
 }
 
 // New creates a new HybridBCCSP instance
-func New() (bccsp.BCCSP, error) {
+func New(opts ...Option) (bccsp.BCCSP, error) {
 	swBCCSP, err := sw.NewDefaultSecurityLevel(os.TempDir())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SW BCCSP: %w", err)
 	}
-	return &HybridBCCSP{sw: swBCCSP}, nil
+	h := &HybridBCCSP{
+		sw:       swBCCSP,
+		policies: staticPolicy(PolicyHybridAND),
+
+		downgradeProtection: true,
+		metrics:             NewMetrics(&disabled.Provider{}),
+		digestPolicy:        DigestPolicy{Hash: crypto.SHA256},
+		envelopeFormat:      FormatStandard,
+		envelopeLimits:      DefaultEnvelopeLimits,
+		stats:               providerStats{since: time.Now()},
+		ephemeral:           ephemeralKeys{dir: os.TempDir()},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.keystore != nil && h.dual != nil {
+		return nil, errors.New("a provider cannot use both a keystore and a dual keystore")
+	}
+	if h.keystore != nil {
+		if err := ValidateNamespace(h.namespace); err != nil {
+			return nil, err
+		}
+		// Le metà ECDSA vivono solo nel namespace, non nel keystore SW condiviso
+		if h.sw, err = sw.NewDefaultSecurityLevelWithKeystore(sw.NewDummyKeyStore()); err != nil {
+			return nil, fmt.Errorf("failed to create SW BCCSP: %w", err)
+		}
+		h.ephemeral.dir = ""
+	}
+	if !h.envelopeFormat.Valid() {
+		return nil, fmt.Errorf("unsupported envelope format %s", h.envelopeFormat)
+	}
+	if err := h.resolveEncoding(); err != nil {
+		return nil, err
+	}
+	if h.canary != nil && core.CanaryBackend == "" {
+		return nil, fmt.Errorf("canary verification with the %s backend: %w", core.PQCBackend, core.ErrNoCanaryBackend)
+	}
+	h.enableMemoryLocking()
+	if h.maxSignatures > 0 && h.usage == nil {
+		h.usage = NewMemoryUsageStore()
+	}
+	if h.preload != nil {
+		if err := h.preloadKeys(); err != nil {
+			return nil, fmt.Errorf("failed to preload keys: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// SetPolicyResolver replaces the policy resolver, e.g. after a channel config update
+func (h *HybridBCCSP) SetPolicyResolver(r PolicyResolver) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.policies = r
+}
+
+// resolvePolicy picks the policy for the channel/MSP carried by opts
+func (h *HybridBCCSP) resolvePolicy(opts bccsp.SignerOpts) Policy {
+	var channel, mspID string
+	var block *BlockRef
+	if o, ok := opts.(*HybridSignerOpts); ok && o != nil {
+		if o.Policy != nil {
+			return *o.Policy
+		}
+		channel, mspID, block = o.Channel, o.MSPID, o.Block
+	}
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if r, ok := h.policies.(BlockPolicyResolver); ok && block != nil {
+		return r.ResolveBlockPolicy(channel, mspID, *block)
+	}
+	return h.policies.ResolvePolicy(channel, mspID)
 }
 
 // KeyDeriv delegates to SW BCCSP
-func (h *HybridBCCSP) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (bccsp.Key, error) {
+func (h *HybridBCCSP) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (_ bccsp.Key, err error) {
+	defer func() { err = wrapKeyError(OpKeyDeriv, k, err) }()
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	if isNilOpts(opts) {
+		return nil, ErrNilOpts
+	}
 	return h.sw.KeyDeriv(k, opts)
 }
 
-// KeyImport delegates to SW BCCSP
-func (h *HybridBCCSP) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (bccsp.Key, error) {
+// KeyImport handles composite and hybrid KEM keys and delegates everything else to SW BCCSP
+func (h *HybridBCCSP) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (_ bccsp.Key, err error) {
+	defer func() {
+		algorithm := ""
+		if err != nil && !isNilOpts(opts) {
+			algorithm = opts.Algorithm()
+		}
+		err = wrapError(OpKeyImport, algorithm, nil, err)
+	}()
+	if isNilOpts(opts) {
+		return nil, ErrNilOpts
+	}
+	if isNilOpts(raw) {
+		return nil, ErrNilKeyMaterial
+	}
+	switch o := opts.(type) {
+	case *HybridPublicKeyImportOpts:
+		return h.importPublicKey(raw)
+	case *HybridPrivateKeyImportOpts:
+		return h.importPrivateKey(raw, o.Temporary)
+	case *ExternalKeyImportOpts:
+		return h.importExternalKey(raw, o)
+	case *HybridKEMPublicKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid raw material, expected []byte")
+		}
+		k, err := parseKEMPublicKey(der)
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	case *HybridKEMPrivateKeyImportOpts:
+		der, ok := raw.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid raw material, expected []byte")
+		}
+		k, err := parseKEMPrivateKey(der)
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	case *LMSPrivateKeyImportOpts:
+		k, err := h.importLMSPrivateKey(raw, o.Temporary)
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	case *LMSPublicKeyImportOpts:
+		k, err := importLMSPublicKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
 	return h.sw.KeyImport(raw, opts)
 }
 
-// GetKey delegates to SW BCCSP
-func (h *HybridBCCSP) GetKey(ski []byte) (bccsp.Key, error) {
-	return h.sw.GetKey(ski)
+// GetKey looks the key up in the provider's keystore namespace or dual
+// keystore, if any, and delegates to SW BCCSP otherwise
+func (h *HybridBCCSP) GetKey(ski []byte) (_ bccsp.Key, err error) {
+	defer func() { err = wrapError(OpGetKey, "", ski, err) }()
+	if len(ski) == 0 {
+		return nil, ErrEmptySKI
+	}
+	if k, ok := h.preloaded[hex.EncodeToString(ski)]; ok {
+		return k, nil
+	}
+	var k bccsp.Key
+	switch {
+	case h.keystore != nil:
+		k, err = h.keystore.GetKey(h.namespace, ski)
+		if errors.Is(err, ErrKeyNotFound) {
+			k, err = h.getLMSKey(ski)
+		}
+	case h.dual != nil:
+		k, err = h.dual.GetKey(ski)
+	default:
+		return h.sw.GetKey(ski)
+	}
+	if hk, ok := k.(*hybridKey); ok && err == nil {
+		h.lockKey(hk)
+	}
+	return k, err
 }
 
 // Hash delegates to SW BCCSP
 func (h *HybridBCCSP) Hash(msg []byte, opts bccsp.HashOpts) ([]byte, error) {
-	return h.sw.Hash(msg, opts)
+	if isNilOpts(opts) {
+		return nil, wrapError(OpHash, "", nil, ErrNilOpts)
+	}
+	digest, err := h.sw.Hash(msg, opts)
+	return digest, wrapError(OpHash, opts.Algorithm(), nil, err)
 }
 
 // GetHash delegates to SW BCCSP
 func (h *HybridBCCSP) GetHash(opts bccsp.HashOpts) (hash.Hash, error) {
-	return h.sw.GetHash(opts)
+	if isNilOpts(opts) {
+		return nil, wrapError(OpHash, "", nil, ErrNilOpts)
+	}
+	hf, err := h.sw.GetHash(opts)
+	return hf, wrapError(OpHash, opts.Algorithm(), nil, err)
 }
 
-// Encrypt delegates to SW BCCSP
-func (h *HybridBCCSP) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) ([]byte, error) {
+// Encrypt performs hybrid KEM encryption for HybridKEMEncrypterOpts and delegates everything else to SW BCCSP
+func (h *HybridBCCSP) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) (_ []byte, err error) {
+	defer func() { err = wrapKeyError(OpEncrypt, k, err) }()
+	if isNilOpts(opts) {
+		return nil, ErrNilOpts
+	}
+	if kemOpts, ok := opts.(*HybridKEMEncrypterOpts); ok {
+		// A nil interface is allowed here, the recipient may come from opts
+		if k != nil && isNilKey(k) {
+			return nil, ErrNilKey
+		}
+		return kemEncrypt(k, plaintext, kemOpts)
+	}
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
 	return h.sw.Encrypt(k, plaintext, opts)
 }
 
-// Decrypt delegates to SW BCCSP
-func (h *HybridBCCSP) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) ([]byte, error) {
+// Decrypt performs hybrid KEM decryption for HybridKEMDecrypterOpts and delegates everything else to SW BCCSP
+func (h *HybridBCCSP) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) (_ []byte, err error) {
+	defer func() { err = wrapKeyError(OpDecrypt, k, err) }()
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	if isNilOpts(opts) {
+		return nil, ErrNilOpts
+	}
+	if kemOpts, ok := opts.(*HybridKEMDecrypterOpts); ok {
+		return kemDecrypt(k, ciphertext, kemOpts)
+	}
 	return h.sw.Decrypt(k, ciphertext, opts)
-}
\ No newline at end of file
+}
@@ -145,6 +145,35 @@ func TestKeyGenDifferentKeys(t *testing.T) {
 	assert.NotEqual(t, hk1.pqcPub, hk2.pqcPub, "PQC public keys should be different")
 }
 
+func TestKeyGenBatch(t *testing.T) {
+	csp, err := New()
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+
+	var reported []int
+	keys, err := h.KeyGenBatch(5, &bccsp.ECDSAP256KeyGenOpts{Temporary: true}, func(done, total int) {
+		assert.Equal(t, 5, total)
+		reported = append(reported, done)
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 5)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, reported)
+	skis := map[string]bool{}
+	for _, k := range keys {
+		assert.Equal(t, KindHybrid, KindOf(k))
+		skis[string(k.SKI())] = true
+	}
+	assert.Len(t, skis, 5)
+
+	_, err = h.KeyGenBatch(3, nil, nil)
+	assert.ErrorIs(t, err, ErrNilOpts)
+	_, err = h.KeyGenBatch(-1, &bccsp.ECDSAP256KeyGenOpts{Temporary: true}, nil)
+	assert.Error(t, err)
+	keys, err = h.KeyGenBatch(0, &bccsp.ECDSAP256KeyGenOpts{Temporary: true}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
 func BenchmarkKeyGen(b *testing.B) {
 	h, _ := New()
 	opts := &bccsp.ECDSAP256KeyGenOpts{Temporary: true}
@@ -184,6 +213,73 @@ func BenchmarkVerify(b *testing.B) {
 	}
 }
 
+// BenchmarkVerifyKeyCache reports cold-path and warm-path verification:
+// cold imports the public key and initializes the ML-DSA verifier for
+// every signature, warm reuses both
+func BenchmarkVerifyKeyCache(b *testing.B) {
+	cache := NewVerifierCache(16, nil)
+	h, _ := New(WithVerifierCache(cache))
+	key, _ := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	raw, _ := MarshalPublicKey(key)
+	digest := sha256.Sum256([]byte("benchmark message"))
+	signature, _ := h.Sign(key, digest[:], nil)
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cache.Purge()
+			pub, _ := h.KeyImport(raw, &HybridPublicKeyImportOpts{Temporary: true})
+			_, _ = h.Verify(pub, signature, digest[:], nil)
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		pub, _ := h.KeyImport(raw, &HybridPublicKeyImportOpts{Temporary: true})
+		_, _ = h.Verify(pub, signature, digest[:], nil)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = h.Verify(pub, signature, digest[:], nil)
+		}
+	})
+}
+
+func BenchmarkAppendSignature(b *testing.B) {
+	h, _ := New()
+	key, _ := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	digest := sha256.Sum256([]byte("benchmark message"))
+	signer := h.(AppendSigner)
+	buf := make([]byte, 0, MaxSignatureSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = signer.AppendSignature(buf[:0], key, digest[:], nil)
+	}
+}
+
+func TestSignInto(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("sign into"))
+	signer := h.(AppendSigner)
+
+	buf := make([]byte, MaxSignatureSize)
+	n, err := signer.SignInto(key, digest[:], buf, nil)
+	require.NoError(t, err)
+	valid, err := h.Verify(key, buf[:n], digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = signer.SignInto(key, digest[:], make([]byte, 16), nil)
+	assert.ErrorIs(t, err, ErrShortBuffer)
+
+	// The envelope is written into the caller's buffer, not a new one
+	dst := make([]byte, 0, MaxSignatureSize)
+	sig, err := signer.AppendSignature(dst, key, digest[:], nil)
+	require.NoError(t, err)
+	assert.Same(t, &dst[:1][0], &sig[0])
+}
+
 func TestPQCSigner(t *testing.T) {
 	signer, err := NewPQCSigner()
 	if err != nil {
@@ -203,4 +299,29 @@ func TestPQCSigner(t *testing.T) {
 	if !valid {
 		t.Fatal("signature verification failed")
 	}
-}
\ No newline at end of file
+}
+func TestMarshalImportPublicKey(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	der, err := MarshalPublicKey(key)
+	require.NoError(t, err)
+
+	imported, err := h.KeyImport(der, &HybridPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	assert.False(t, imported.Private())
+	assert.Equal(t, key.SKI(), imported.SKI())
+
+	digest := sha256.Sum256([]byte("composite"))
+	signature, err := h.Sign(key, digest[:], nil)
+	require.NoError(t, err)
+	valid, err := h.Verify(imported, signature, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = h.KeyImport(der[:len(der)-1], &HybridPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+}
@@ -0,0 +1,277 @@
+// Package hybridx509 issues and verifies composite certificates: standard
+// ECDSA-signed X.509 certificates that also carry an ML-DSA public key and an
+// ML-DSA signature of the issuer in the alternative public key and signature
+// extensions of ITU-T X.509 (10/2019), section 9.8.
+//
+// Classical verifiers ignore the non-critical extensions; hybrid-aware
+// verifiers check both signatures.
+package hybridx509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+var (
+	OIDSubjectAltPublicKeyInfo = asn1.ObjectIdentifier{2, 5, 29, 72}
+	OIDAltSignatureAlgorithm   = asn1.ObjectIdentifier{2, 5, 29, 73}
+	OIDAltSignatureValue       = asn1.ObjectIdentifier{2, 5, 29, 74}
+)
+
+// ErrNotComposite is returned when a certificate lacks the alternative key or signature
+var ErrNotComposite = errors.New("certificate is not a composite certificate")
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// Certificate is a parsed composite certificate
+type Certificate struct {
+	*x509.Certificate
+
+	// PQCPublicKey is the subject's ML-DSA public key
+	PQCPublicKey []byte
+	// CoSigners replaces PQCPublicKey for a subject whose ML-DSA signature
+	// is split among co-signers
+	CoSigners *CoSignerSet
+	// AltSignature is the issuer's ML-DSA signature over the certificate
+	// without the AltSignatureValue extension
+	AltSignature []byte
+}
+
+// CreateCertificate issues a composite certificate for pub signed by priv.
+// A nil parent issues a self-signed certificate from template. As with
+// x509.CreateCertificate, the template's ExtraExtensions are preserved.
+func CreateCertificate(template, parent *x509.Certificate, pub *PublicKey, priv *PrivateKey) ([]byte, error) {
+	if priv == nil || priv.ECDSA == nil || priv.PQC == nil {
+		return nil, errors.New("composite private key is incomplete")
+	}
+	return CreateCertificateWith(template, parent, pub, priv.ECDSA, priv.PQC.Sign)
+}
+
+// CreateCertificateWith is CreateCertificate for issuers whose keys are not
+// a PrivateKey: signer makes the ECDSA signature and altSign the alternative
+// signature of the TBS certificate, e.g. the co-signatures encoded by
+// MarshalCoSignatures
+func CreateCertificateWith(template, parent *x509.Certificate, pub *PublicKey, signer crypto.Signer, altSign func(tbs []byte) ([]byte, error)) ([]byte, error) {
+	if pub == nil || pub.ECDSA == nil || (len(pub.PQC) == 0) == (pub.CoSigners == nil) {
+		return nil, errors.New("composite public key is incomplete")
+	}
+	if signer == nil || altSign == nil {
+		return nil, errors.New("composite private key is incomplete")
+	}
+	if parent == nil {
+		parent = template
+	}
+
+	altKey, err := marshalAltPublicKeyInfo(pub)
+	if err != nil {
+		return nil, err
+	}
+	altAlg, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: pqcOID()})
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := *template
+	tmpl.ExtraExtensions = append(append([]pkix.Extension(nil), template.ExtraExtensions...),
+		pkix.Extension{Id: OIDSubjectAltPublicKeyInfo, Value: altKey},
+		pkix.Extension{Id: OIDAltSignatureAlgorithm, Value: altAlg},
+	)
+	if parent == template {
+		parent = &tmpl
+	}
+
+	// First pass: the TBS certificate the alternative signature covers
+	pre, err := x509.CreateCertificate(rand.Reader, &tmpl, parent, pub.ECDSA, signer)
+	if err != nil {
+		return nil, err
+	}
+	preCert, err := x509.ParseCertificate(pre)
+	if err != nil {
+		return nil, err
+	}
+	altSig, err := altSign(preCert.RawTBSCertificate)
+	if err != nil {
+		return nil, err
+	}
+	altValue, err := asn1.Marshal(asn1.BitString{Bytes: altSig, BitLength: 8 * len(altSig)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Second pass: the same TBS certificate plus the alternative signature
+	tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: OIDAltSignatureValue, Value: altValue})
+	return x509.CreateCertificate(rand.Reader, &tmpl, parent, pub.ECDSA, signer)
+}
+
+// ParseCertificate parses a DER certificate. Classical certificates parse
+// without error; IsComposite reports whether the PQC parts are present.
+func ParseCertificate(der []byte) (*Certificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return FromX509(cert)
+}
+
+// FromX509 extracts the PQC parts of an already parsed certificate
+func FromX509(cert *x509.Certificate) (*Certificate, error) {
+	c := &Certificate{Certificate: cert}
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(OIDSubjectAltPublicKeyInfo):
+			var spki subjectPublicKeyInfo
+			if rest, err := asn1.Unmarshal(ext.Value, &spki); err != nil || len(rest) != 0 {
+				return nil, errors.New("invalid subjectAltPublicKeyInfo extension")
+			}
+			switch alg := spki.Algorithm.Algorithm; {
+			case alg.Equal(pqcOID()):
+				c.PQCPublicKey = spki.PublicKey.Bytes
+			case alg.Equal(OIDCoSignerSet):
+				set, err := ParseCoSignerSet(spki.PublicKey.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				c.CoSigners = set
+			default:
+				return nil, fmt.Errorf("unsupported alternative public key algorithm %s", alg)
+			}
+		case ext.Id.Equal(OIDAltSignatureValue):
+			var sig asn1.BitString
+			if rest, err := asn1.Unmarshal(ext.Value, &sig); err != nil || len(rest) != 0 {
+				return nil, errors.New("invalid altSignatureValue extension")
+			}
+			c.AltSignature = sig.Bytes
+		}
+	}
+	return c, nil
+}
+
+// IsComposite reports whether the certificate carries a PQC key, or a
+// co-signer set, and signature
+func (c *Certificate) IsComposite() bool {
+	return (len(c.PQCPublicKey) > 0 || c.CoSigners != nil) && len(c.AltSignature) > 0
+}
+
+// CompositePublicKey returns the subject key in the format accepted by
+// hybrid.HybridPublicKeyImportOpts. Co-signed subjects have none.
+func (c *Certificate) CompositePublicKey() ([]byte, error) {
+	if len(c.PQCPublicKey) == 0 {
+		return nil, ErrNotComposite
+	}
+	return hybrid.MarshalCompositePublicKey(c.RawSubjectPublicKeyInfo, c.PQCPublicKey)
+}
+
+// CheckSignatureFrom verifies both the ECDSA and the ML-DSA signature of
+// parent on c. A co-signed parent needs the signatures of its threshold of
+// co-signers.
+func (c *Certificate) CheckSignatureFrom(parent *Certificate) error {
+	if err := c.Certificate.CheckSignatureFrom(parent.Certificate); err != nil {
+		return fmt.Errorf("classical signature: %w", err)
+	}
+	if !c.IsComposite() || (len(parent.PQCPublicKey) == 0 && parent.CoSigners == nil) {
+		return ErrNotComposite
+	}
+	preTBS, err := stripAltSignature(c.RawTBSCertificate, tbsCertificateExtensions)
+	if err != nil {
+		return err
+	}
+	if parent.CoSigners != nil {
+		return parent.CoSigners.Verify(preTBS, c.AltSignature)
+	}
+	valid, err := hybrid.VerifyPQC(parent.PQCPublicKey, preTBS, c.AltSignature)
+	if err != nil {
+		return fmt.Errorf("alternative signature: %w", err)
+	}
+	if !valid {
+		return errors.New("alternative signature is invalid")
+	}
+	return nil
+}
+
+// Context-specific tags of the extensions of a TBS certificate, a TBS
+// certificate list and an OCSP single response
+const (
+	tbsCertificateExtensions = 3
+	tbsCertListExtensions    = 0
+	singleResponseExtensions = 1
+)
+
+// stripAltSignature re-encodes a TBS certificate, certificate list or OCSP
+// single response without the AltSignatureValue extension, keeping every
+// other element byte for byte. The extensions are the last element, tagged
+// extensionsTag.
+func stripAltSignature(tbs []byte, extensionsTag int) ([]byte, error) {
+	var elems []asn1.RawValue
+	if rest, err := asn1.Unmarshal(tbs, &elems); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid TBS certificate")
+	}
+
+	var body bytes.Buffer
+	for i, e := range elems {
+		if i != len(elems)-1 || e.Class != asn1.ClassContextSpecific || e.Tag != extensionsTag {
+			body.Write(e.FullBytes)
+			continue
+		}
+		var exts []asn1.RawValue
+		if rest, err := asn1.Unmarshal(e.Bytes, &exts); err != nil || len(rest) != 0 {
+			return nil, errors.New("invalid TBS certificate extensions")
+		}
+		var kept bytes.Buffer
+		for _, raw := range exts {
+			var ext pkix.Extension
+			if _, err := asn1.Unmarshal(raw.FullBytes, &ext); err != nil {
+				return nil, errors.New("invalid TBS certificate extension")
+			}
+			if !ext.Id.Equal(OIDAltSignatureValue) {
+				kept.Write(raw.FullBytes)
+			}
+		}
+		seq, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: kept.Bytes()})
+		if err != nil {
+			return nil, err
+		}
+		wrapped, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: extensionsTag, IsCompound: true, Bytes: seq})
+		if err != nil {
+			return nil, err
+		}
+		body.Write(wrapped)
+	}
+	return asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: body.Bytes()})
+}
+
+func marshalAltPublicKeyInfo(pub *PublicKey) ([]byte, error) {
+	if pub.CoSigners == nil {
+		return marshalPQCPublicKeyInfo(pub.PQC)
+	}
+	set, err := pub.CoSigners.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: OIDCoSignerSet},
+		PublicKey: asn1.BitString{Bytes: set, BitLength: 8 * len(set)},
+	})
+}
+
+func marshalPQCPublicKeyInfo(pub []byte) ([]byte, error) {
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: pqcOID()},
+		PublicKey: asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	})
+}
+
+func pqcOID() asn1.ObjectIdentifier {
+	oid, _ := hybrid.PQCAlgorithmOID(hybrid.PQCAlgorithm)
+	return oid
+}
@@ -0,0 +1,201 @@
+package hybridx509
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func TestCompositeCertificateChain(t *testing.T) {
+	caKey, err := GenerateKey()
+	require.NoError(t, err)
+	now := time.Now()
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := CreateCertificate(caTmpl, nil, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err := ParseCertificate(caDER)
+	require.NoError(t, err)
+	require.True(t, ca.IsComposite())
+	require.NoError(t, ca.CheckSignatureFrom(ca))
+
+	leafKey, err := GenerateKey()
+	require.NoError(t, err)
+	leafDER, err := CreateCertificate(&x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "peer0.org1.example.com"},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca.Certificate, leafKey.Public(), caKey)
+	require.NoError(t, err)
+	leaf, err := ParseCertificate(leafDER)
+	require.NoError(t, err)
+	require.NoError(t, leaf.CheckSignatureFrom(ca))
+	assert.Equal(t, leafKey.PQC.PublicKey(), leaf.PQCPublicKey)
+
+	// Classical verification is unaffected by the extensions
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	require.NoError(t, err)
+
+	// A different issuer PQC key is rejected
+	otherKey, err := GenerateKey()
+	require.NoError(t, err)
+	forged := *ca
+	forged.PQCPublicKey = otherKey.PQC.PublicKey()
+	require.Error(t, leaf.CheckSignatureFrom(&forged))
+}
+
+func TestCoSignedCertificate(t *testing.T) {
+	caKey, err := GenerateKey()
+	require.NoError(t, err)
+	set := &CoSignerSet{Threshold: 2}
+	var coSigners []*hybrid.PQCSigner
+	for i := 0; i < 3; i++ {
+		s, err := hybrid.NewPQCSigner()
+		require.NoError(t, err)
+		coSigners = append(coSigners, s)
+		set.Keys = append(set.Keys, s.PublicKey())
+	}
+	// coSign collects the signatures of the first n co-signers
+	coSign := func(n int) func([]byte) ([]byte, error) {
+		return func(tbs []byte) ([]byte, error) {
+			var sigs []CoSignature
+			for i, s := range coSigners[:n] {
+				sig, err := s.Sign(tbs)
+				if err != nil {
+					return nil, err
+				}
+				sigs = append(sigs, CoSignature{Signer: i + 1, Signature: sig})
+			}
+			return MarshalCoSignatures(sigs)
+		}
+	}
+
+	now := time.Now()
+	caDER, err := CreateCertificateWith(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root.consortium.example.com"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &PublicKey{ECDSA: &caKey.ECDSA.PublicKey, CoSigners: set}, caKey.ECDSA, coSign(2))
+	require.NoError(t, err)
+	ca, err := ParseCertificate(caDER)
+	require.NoError(t, err)
+	require.True(t, ca.IsComposite())
+	assert.Equal(t, set, ca.CoSigners)
+	require.NoError(t, ca.CheckSignatureFrom(ca))
+
+	leafKey, err := GenerateKey()
+	require.NoError(t, err)
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "ca.org1.example.com"},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+	}
+	leafDER, err := CreateCertificateWith(leafTmpl, ca.Certificate, leafKey.Public(), caKey.ECDSA, coSign(1))
+	require.NoError(t, err)
+	leaf, err := ParseCertificate(leafDER)
+	require.NoError(t, err)
+	assert.ErrorIs(t, leaf.CheckSignatureFrom(ca), ErrCoSignature, "below the threshold")
+
+	leafDER, err = CreateCertificateWith(leafTmpl, ca.Certificate, leafKey.Public(), caKey.ECDSA, coSign(3))
+	require.NoError(t, err)
+	leaf, err = ParseCertificate(leafDER)
+	require.NoError(t, err)
+	require.NoError(t, leaf.CheckSignatureFrom(ca))
+
+	_, err = (&CoSignerSet{Threshold: 4, Keys: set.Keys}).Marshal()
+	assert.Error(t, err)
+}
+
+func TestPrivateKeyPEMRoundTrip(t *testing.T) {
+	k, err := GenerateKey()
+	require.NoError(t, err)
+	ecdsaPEM, err := MarshalECDSAPrivateKeyPEM(k)
+	require.NoError(t, err)
+	pqcPEM, err := MarshalPQCPrivateKeyPEM(k)
+	require.NoError(t, err)
+
+	parsed, err := ParsePrivateKeyPEM(ecdsaPEM, pqcPEM)
+	require.NoError(t, err)
+	assert.True(t, k.ECDSA.Equal(parsed.ECDSA))
+	assert.Equal(t, k.PQC.PublicKey(), parsed.PQC.PublicKey())
+
+	sig, err := parsed.PQC.Sign([]byte("msg"))
+	require.NoError(t, err)
+	valid, err := k.PQC.Verify([]byte("msg"), sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestCompositeRevocationList(t *testing.T) {
+	caKey, err := GenerateKey()
+	require.NoError(t, err)
+	now := time.Now()
+	caDER, err := CreateCertificate(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err := ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	der, err := CreateRevocationList(&x509.RevocationList{
+		Number:                    big.NewInt(7),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: big.NewInt(2), RevocationTime: now}},
+	}, ca, caKey)
+	require.NoError(t, err)
+	crl, err := ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NotEmpty(t, crl.AltSignature)
+	require.NoError(t, crl.CheckSignatureFrom(ca))
+	assert.Equal(t, big.NewInt(7), crl.Number)
+
+	// Classical relying parties read a plain CRL
+	classical, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NoError(t, classical.CheckSignatureFrom(ca.Certificate))
+	require.Len(t, classical.RevokedCertificateEntries, 1)
+
+	// A different issuer PQC key is rejected
+	otherKey, err := GenerateKey()
+	require.NoError(t, err)
+	forged := *ca
+	forged.PQCPublicKey = otherKey.PQC.PublicKey()
+	assert.Error(t, crl.CheckSignatureFrom(&forged))
+
+	// So is a classical CRL of a composite issuer
+	plain, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{Number: big.NewInt(8), ThisUpdate: now, NextUpdate: now.Add(time.Hour)}, ca.Certificate, caKey.ECDSA)
+	require.NoError(t, err)
+	crl, err = ParseRevocationList(plain)
+	require.NoError(t, err)
+	assert.ErrorIs(t, crl.CheckSignatureFrom(ca), ErrNotComposite)
+}
@@ -0,0 +1,95 @@
+package hybridx509
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// OIDCoSignerSet identifies an alternative public key made of the ML-DSA
+// keys of several co-signers, a threshold of which must sign. It is in the
+// experimental 1.3.9999 arc liboqs uses for algorithms without a registered
+// identifier.
+var OIDCoSignerSet = asn1.ObjectIdentifier{1, 3, 9999, 99, 1}
+
+// ErrCoSignature is returned when the co-signatures of a certificate do not
+// reach the issuer's threshold
+var ErrCoSignature = errors.New("invalid co-signatures")
+
+// CoSignerSet is the alternative public key of an issuer whose ML-DSA
+// signature is split among co-signers, such as a key ceremony root
+type CoSignerSet struct {
+	// Threshold is the number of co-signatures a certificate needs
+	Threshold int
+	// Keys are the ML-DSA public keys; co-signer i holds Keys[i-1]
+	Keys [][]byte
+}
+
+// CoSignature is the ML-DSA signature of one co-signer
+type CoSignature struct {
+	// Signer is the 1-based index of the co-signer's key
+	Signer    int
+	Signature []byte
+}
+
+func (s *CoSignerSet) validate() error {
+	if s.Threshold < 1 || s.Threshold > len(s.Keys) {
+		return fmt.Errorf("co-signer threshold %d out of range for %d keys", s.Threshold, len(s.Keys))
+	}
+	return nil
+}
+
+// Marshal encodes s as SEQUENCE { threshold INTEGER, keys SEQUENCE OF OCTET STRING }
+func (s *CoSignerSet) Marshal() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(*s)
+}
+
+// ParseCoSignerSet decodes a set encoded by Marshal
+func ParseCoSignerSet(der []byte) (*CoSignerSet, error) {
+	var s CoSignerSet
+	if rest, err := asn1.Unmarshal(der, &s); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid co-signer set")
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MarshalCoSignatures encodes the alternative signature of a co-signed
+// certificate as SEQUENCE OF SEQUENCE { signer INTEGER, signature OCTET STRING }
+func MarshalCoSignatures(sigs []CoSignature) ([]byte, error) {
+	return asn1.Marshal(sigs)
+}
+
+// Verify checks that sigs, encoded by MarshalCoSignatures, holds valid
+// signatures of msg by at least Threshold distinct co-signers
+func (s *CoSignerSet) Verify(msg, sigs []byte) error {
+	var parsed []CoSignature
+	if rest, err := asn1.Unmarshal(sigs, &parsed); err != nil || len(rest) != 0 {
+		return fmt.Errorf("%w: malformed", ErrCoSignature)
+	}
+	seen := map[int]bool{}
+	for _, sig := range parsed {
+		if sig.Signer < 1 || sig.Signer > len(s.Keys) || seen[sig.Signer] {
+			return fmt.Errorf("%w: unexpected co-signer %d", ErrCoSignature, sig.Signer)
+		}
+		valid, err := hybrid.VerifyPQC(s.Keys[sig.Signer-1], msg, sig.Signature)
+		if err != nil {
+			return fmt.Errorf("%w: co-signer %d: %w", ErrCoSignature, sig.Signer, err)
+		}
+		if !valid {
+			return fmt.Errorf("%w: co-signer %d signature is invalid", ErrCoSignature, sig.Signer)
+		}
+		seen[sig.Signer] = true
+	}
+	if len(seen) < s.Threshold {
+		return fmt.Errorf("%w: %d of %d required", ErrCoSignature, len(seen), s.Threshold)
+	}
+	return nil
+}
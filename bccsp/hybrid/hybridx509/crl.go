@@ -0,0 +1,106 @@
+package hybridx509
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// RevocationList is a parsed composite CRL
+type RevocationList struct {
+	*x509.RevocationList
+
+	// AltSignature is the issuer's ML-DSA signature over the CRL without the
+	// AltSignatureValue extension
+	AltSignature []byte
+}
+
+// CreateRevocationList issues a composite CRL: an ECDSA-signed CRL whose
+// extensions carry the ML-DSA signature of issuer, as CreateCertificate does
+// for certificates. Classical relying parties read it as a plain CRL.
+func CreateRevocationList(template *x509.RevocationList, issuer *Certificate, priv *PrivateKey) ([]byte, error) {
+	if priv == nil || priv.ECDSA == nil || priv.PQC == nil {
+		return nil, errors.New("composite private key is incomplete")
+	}
+	altAlg, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: pqcOID()})
+	if err != nil {
+		return nil, err
+	}
+	tmpl := *template
+	tmpl.ExtraExtensions = append(append([]pkix.Extension(nil), template.ExtraExtensions...),
+		pkix.Extension{Id: OIDAltSignatureAlgorithm, Value: altAlg})
+
+	// First pass: the TBS certificate list the alternative signature covers
+	pre, err := x509.CreateRevocationList(rand.Reader, &tmpl, issuer.Certificate, priv.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	preCRL, err := x509.ParseRevocationList(pre)
+	if err != nil {
+		return nil, err
+	}
+	altSig, err := priv.PQC.Sign(preCRL.RawTBSRevocationList)
+	if err != nil {
+		return nil, err
+	}
+	altValue, err := asn1.Marshal(asn1.BitString{Bytes: altSig, BitLength: 8 * len(altSig)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Second pass: the same TBS certificate list plus the alternative signature
+	tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: OIDAltSignatureValue, Value: altValue})
+	return x509.CreateRevocationList(rand.Reader, &tmpl, issuer.Certificate, priv.ECDSA)
+}
+
+// ParseRevocationList parses a DER CRL. Classical CRLs parse without error
+// and have no AltSignature.
+func ParseRevocationList(der []byte) (*RevocationList, error) {
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		return nil, err
+	}
+	l := &RevocationList{RevocationList: crl}
+	for _, ext := range crl.Extensions {
+		if ext.Id.Equal(OIDAltSignatureValue) {
+			var sig asn1.BitString
+			if rest, err := asn1.Unmarshal(ext.Value, &sig); err != nil || len(rest) != 0 {
+				return nil, errors.New("invalid altSignatureValue extension")
+			}
+			l.AltSignature = sig.Bytes
+		}
+	}
+	return l, nil
+}
+
+// CheckSignatureFrom verifies both the ECDSA and the ML-DSA signature of
+// issuer on l. CRLs of co-signed issuers are not supported.
+func (l *RevocationList) CheckSignatureFrom(issuer *Certificate) error {
+	if !bytes.Equal(l.RawIssuer, issuer.RawSubject) {
+		return errors.New("CRL issuer does not match the certificate subject")
+	}
+	if err := l.RevocationList.CheckSignatureFrom(issuer.Certificate); err != nil {
+		return fmt.Errorf("classical signature: %w", err)
+	}
+	if len(l.AltSignature) == 0 || len(issuer.PQCPublicKey) == 0 {
+		return ErrNotComposite
+	}
+	preTBS, err := stripAltSignature(l.RawTBSRevocationList, tbsCertListExtensions)
+	if err != nil {
+		return err
+	}
+	valid, err := hybrid.VerifyPQC(issuer.PQCPublicKey, preTBS, l.AltSignature)
+	if err != nil {
+		return fmt.Errorf("alternative signature: %w", err)
+	}
+	if !valid {
+		return errors.New("alternative signature is invalid")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package hybridx509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// PQC private keys are PEM blocks of this type holding pqcPrivateKey
+const pqcPrivateKeyPEMType = "PQC PRIVATE KEY"
+
+// PrivateKey is a composite ECDSA P-256 + ML-DSA private key
+type PrivateKey struct {
+	ECDSA *ecdsa.PrivateKey
+	PQC   *hybrid.PQCSigner
+}
+
+// PublicKey is a composite ECDSA + ML-DSA public key
+type PublicKey struct {
+	ECDSA *ecdsa.PublicKey
+	PQC   []byte
+	// CoSigners replaces PQC for issuers whose ML-DSA signature is split
+	// among co-signers
+	CoSigners *CoSignerSet
+}
+
+// GenerateKey creates a new composite key pair
+func GenerateKey() (*PrivateKey, error) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	pqc, err := hybrid.NewPQCSigner()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{ECDSA: ecdsaKey, PQC: pqc}, nil
+}
+
+// GenerateKeys creates n composite key pairs in parallel, for provisioning
+// many identities at once. progress may be nil.
+func GenerateKeys(n int, progress hybrid.BatchProgress) ([]*PrivateKey, error) {
+	keys := make([]*PrivateKey, max(n, 0))
+	err := hybrid.GenerateBatch(n, progress, func(i int) (err error) {
+		keys[i], err = GenerateKey()
+		return err
+	})
+	if err != nil {
+		for _, k := range keys {
+			if k != nil {
+				k.PQC.Clean()
+			}
+		}
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Public returns the public half of k
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{ECDSA: &k.ECDSA.PublicKey, PQC: k.PQC.PublicKey()}
+}
+
+// Raw returns the key in the form accepted by hybrid.HybridPrivateKeyImportOpts
+func (k *PrivateKey) Raw() *hybrid.HybridPrivateKey {
+	return &hybrid.HybridPrivateKey{
+		ECDSA:         k.ECDSA,
+		PQCPrivateKey: k.PQC.PrivateKey(),
+		PQCPublicKey:  k.PQC.PublicKey(),
+	}
+}
+
+// pqcPrivateKey is SEQUENCE { algorithm OID, privateKey OCTET STRING, publicKey OCTET STRING }
+type pqcPrivateKey struct {
+	Algorithm  asn1.ObjectIdentifier
+	PrivateKey []byte
+	PublicKey  []byte
+}
+
+// MarshalECDSAPrivateKeyPEM encodes the classical half as a PKCS#8 PEM block,
+// the format Fabric MSP keystores expect
+func MarshalECDSAPrivateKeyPEM(k *PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPQCPrivateKeyPEM encodes the PQC half together with its public key
+func MarshalPQCPrivateKeyPEM(k *PrivateKey) ([]byte, error) {
+	der, err := asn1.Marshal(pqcPrivateKey{
+		Algorithm:  pqcOID(),
+		PrivateKey: k.PQC.PrivateKey(),
+		PublicKey:  k.PQC.PublicKey(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pqcPrivateKeyPEMType, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM rebuilds a composite key from its two PEM encoded halves
+func ParsePrivateKeyPEM(ecdsaPEM, pqcPEM []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(ecdsaPEM)
+	if block == nil {
+		return nil, errors.New("no PEM block in ECDSA private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA private key: %w", err)
+	}
+	ecdsaKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid key type, expected *ecdsa.PrivateKey")
+	}
+
+	block, _ = pem.Decode(pqcPEM)
+	if block == nil || block.Type != pqcPrivateKeyPEMType {
+		return nil, errors.New("no PQC private key PEM block")
+	}
+	var raw pqcPrivateKey
+	if rest, err := asn1.Unmarshal(block.Bytes, &raw); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid PQC private key")
+	}
+	if !raw.Algorithm.Equal(pqcOID()) {
+		return nil, fmt.Errorf("unsupported PQC algorithm %s", raw.Algorithm)
+	}
+	pqc, err := hybrid.NewPQCSignerFromKeyPair(raw.PrivateKey, raw.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{ECDSA: ecdsaKey, PQC: pqc}, nil
+}
+
+// EncodeCertificatePEM encodes a DER certificate as PEM
+func EncodeCertificatePEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// ParseCertificatePEM parses the first certificate of a PEM file
+func ParseCertificatePEM(raw []byte) (*Certificate, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no CERTIFICATE PEM block")
+	}
+	return ParseCertificate(block.Bytes)
+}
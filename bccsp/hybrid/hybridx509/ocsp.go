@@ -0,0 +1,134 @@
+package hybridx509
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPResponse is a parsed composite OCSP response
+type OCSPResponse struct {
+	*ocsp.Response
+
+	// AltSignature is the issuer's ML-DSA signature over the single response
+	// without the AltSignatureValue extension
+	AltSignature []byte
+}
+
+// CreateOCSPResponse issues an OCSP response signed by the CA itself: the
+// ECDSA signature of RFC 6960 and an ML-DSA signature in the extensions of
+// the single response. The ML-DSA signature covers the certificate ID, the
+// status and the validity, not the production time, which the responder
+// sets to the minute of each signing pass.
+func CreateOCSPResponse(issuer *Certificate, template ocsp.Response, priv *PrivateKey) ([]byte, error) {
+	if priv == nil || priv.ECDSA == nil || priv.PQC == nil {
+		return nil, errors.New("composite private key is incomplete")
+	}
+	altAlg, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: pqcOID()})
+	if err != nil {
+		return nil, err
+	}
+	template.Certificate = nil
+	template.ExtraExtensions = append(append([]pkix.Extension(nil), template.ExtraExtensions...),
+		pkix.Extension{Id: OIDAltSignatureAlgorithm, Value: altAlg})
+
+	// First pass: the single response the alternative signature covers
+	pre, err := ocsp.CreateResponse(issuer.Certificate, issuer.Certificate, template, priv.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	preResp, err := ocsp.ParseResponse(pre, nil)
+	if err != nil {
+		return nil, err
+	}
+	single, err := singleResponse(preResp.TBSResponseData)
+	if err != nil {
+		return nil, err
+	}
+	altSig, err := priv.PQC.Sign(single)
+	if err != nil {
+		return nil, err
+	}
+	altValue, err := asn1.Marshal(asn1.BitString{Bytes: altSig, BitLength: 8 * len(altSig)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Second pass: the same single response plus the alternative signature
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: OIDAltSignatureValue, Value: altValue})
+	return ocsp.CreateResponse(issuer.Certificate, issuer.Certificate, template, priv.ECDSA)
+}
+
+// ParseOCSPResponse parses a DER OCSP response and checks its ECDSA
+// signature by issuer; CheckSignatureFrom checks both.
+func ParseOCSPResponse(der []byte, issuer *Certificate) (*OCSPResponse, error) {
+	resp, err := ocsp.ParseResponse(der, issuer.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	r := &OCSPResponse{Response: resp}
+	for _, ext := range resp.Extensions {
+		if ext.Id.Equal(OIDAltSignatureValue) {
+			var sig asn1.BitString
+			if rest, err := asn1.Unmarshal(ext.Value, &sig); err != nil || len(rest) != 0 {
+				return nil, errors.New("invalid altSignatureValue extension")
+			}
+			r.AltSignature = sig.Bytes
+		}
+	}
+	return r, nil
+}
+
+// CheckSignatureFrom verifies both the ECDSA and the ML-DSA signature of
+// issuer on r. Responses of delegated responders are not supported.
+func (r *OCSPResponse) CheckSignatureFrom(issuer *Certificate) error {
+	if r.Response.Certificate != nil {
+		return errors.New("delegated OCSP responders are not supported")
+	}
+	if err := r.Response.CheckSignatureFrom(issuer.Certificate); err != nil {
+		return fmt.Errorf("classical signature: %w", err)
+	}
+	if len(r.AltSignature) == 0 || len(issuer.PQCPublicKey) == 0 {
+		return ErrNotComposite
+	}
+	single, err := singleResponse(r.TBSResponseData)
+	if err != nil {
+		return err
+	}
+	preSingle, err := stripAltSignature(single, singleResponseExtensions)
+	if err != nil {
+		return err
+	}
+	valid, err := hybrid.VerifyPQC(issuer.PQCPublicKey, preSingle, r.AltSignature)
+	if err != nil {
+		return fmt.Errorf("alternative signature: %w", err)
+	}
+	if !valid {
+		return errors.New("alternative signature is invalid")
+	}
+	return nil
+}
+
+// singleResponse returns the only SingleResponse of a DER ResponseData
+func singleResponse(tbs []byte) ([]byte, error) {
+	var elems []asn1.RawValue
+	if rest, err := asn1.Unmarshal(tbs, &elems); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid OCSP response data")
+	}
+	// responses is the only universal SEQUENCE of ResponseData
+	for _, e := range elems {
+		if e.Class != asn1.ClassUniversal || e.Tag != asn1.TagSequence {
+			continue
+		}
+		var responses []asn1.RawValue
+		if rest, err := asn1.Unmarshal(e.FullBytes, &responses); err != nil || len(rest) != 0 || len(responses) != 1 {
+			return nil, errors.New("expected one OCSP single response")
+		}
+		return responses[0].FullBytes, nil
+	}
+	return nil, errors.New("invalid OCSP response data")
+}
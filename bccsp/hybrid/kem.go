@@ -0,0 +1,392 @@
+package hybrid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KEMAlgorithm is the post-quantum KEM combined with ECDH P-256 for hybrid encryption
+const KEMAlgorithm = "ML-KEM-768"
+
+// HybridKEM identifies hybrid KEM keys and opts
+const HybridKEM = "HYBRID_KEM"
+
+// kemCiphertextVersion is the first byte of every hybrid KEM ciphertext
+const kemCiphertextVersion byte = 0x01
+
+const kemInfoPrefix = "quantum-ledger/hybrid-kem/v1"
+
+// HybridKEMKeyGenOpts contains options for generating an ECDH P-256 + ML-KEM-768 key pair
+type HybridKEMKeyGenOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key generation algorithm identifier (to be used).
+func (opts *HybridKEMKeyGenOpts) Algorithm() string {
+	return HybridKEM
+}
+
+// Ephemeral returns true if the key to generate has to be ephemeral,
+// false otherwise.
+func (opts *HybridKEMKeyGenOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// HybridKEMPublicKeyImportOpts contains options for importing a marshaled hybrid KEM public key
+type HybridKEMPublicKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *HybridKEMPublicKeyImportOpts) Algorithm() string {
+	return HybridKEM
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *HybridKEMPublicKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// HybridKEMPrivateKeyImportOpts contains options for importing a hybrid KEM
+// private key marshaled by MarshalKEMPrivateKey
+type HybridKEMPrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *HybridKEMPrivateKeyImportOpts) Algorithm() string {
+	return HybridKEM
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *HybridKEMPrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// HybridKEMEncrypterOpts selects hybrid KEM encryption in Encrypt.
+type HybridKEMEncrypterOpts struct {
+	// RecipientPublicKey is the marshaled hybrid KEM public key of the
+	// recipient, used when Encrypt is called with a nil key.
+	RecipientPublicKey []byte
+	// AAD is authenticated but not encrypted; Decrypt must be given the same value.
+	AAD []byte
+	// KDFInfo is mixed into the key derivation; Decrypt must be given the same value.
+	KDFInfo []byte
+}
+
+// HybridKEMDecrypterOpts selects hybrid KEM decryption in Decrypt.
+type HybridKEMDecrypterOpts struct {
+	AAD     []byte
+	KDFInfo []byte
+}
+
+// hybridKEMKey holds an ECDH P-256 key and an ML-KEM-768 key
+type hybridKEMKey struct {
+	ecdhPriv *ecdh.PrivateKey
+	ecdhPub  *ecdh.PublicKey
+	kemPub   []byte
+	kemPriv  []byte
+}
+
+// Bytes returns the marshaled public key, private keys are not exportable
+func (k *hybridKEMKey) Bytes() ([]byte, error) {
+	if k.Private() {
+		return nil, errors.New("not supported")
+	}
+	return marshalKEMPublicKey(k.ecdhPub.Bytes(), k.kemPub), nil
+}
+
+func (k *hybridKEMKey) SKI() []byte {
+	hash := sha256.New()
+	hash.Write(k.ecdhPub.Bytes())
+	hash.Write(k.kemPub)
+	return hash.Sum(nil)
+}
+
+func (k *hybridKEMKey) Symmetric() bool {
+	return false
+}
+
+func (k *hybridKEMKey) Private() bool {
+	return k.ecdhPriv != nil
+}
+
+func (k *hybridKEMKey) PublicKey() (bccsp.Key, error) {
+	return &hybridKEMKey{ecdhPub: k.ecdhPub, kemPub: k.kemPub}, nil
+}
+
+// marshalKEMPublicKey creates: [2 bytes ECDH len][ECDH pub][ML-KEM pub]
+func marshalKEMPublicKey(ecdhPub, kemPub []byte) []byte {
+	out := make([]byte, 2, 2+len(ecdhPub)+len(kemPub))
+	binary.BigEndian.PutUint16(out, uint16(len(ecdhPub)))
+	out = append(out, ecdhPub...)
+	return append(out, kemPub...)
+}
+
+func parseKEMPublicKey(raw []byte) (*hybridKEMKey, error) {
+	if len(raw) < 2 {
+		return nil, errors.New("hybrid KEM public key too short")
+	}
+	ecdhLen := int(binary.BigEndian.Uint16(raw[:2]))
+	if ecdhLen > len(raw)-2 {
+		return nil, errors.New("invalid hybrid KEM public key: ECDH length exceeds key size")
+	}
+	ecdhPub, err := ecdh.P256().NewPublicKey(raw[2 : 2+ecdhLen])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDH public key: %w", err)
+	}
+	kemPub := raw[2+ecdhLen:]
+	if len(kemPub) == 0 {
+		return nil, errors.New("invalid hybrid KEM public key: ML-KEM component is empty")
+	}
+	return &hybridKEMKey{ecdhPub: ecdhPub, kemPub: append([]byte(nil), kemPub...)}, nil
+}
+
+// MarshalKEMPrivateKey exports a hybrid KEM private key for devices that
+// keep it in platform secure storage rather than a keystore, e.g. the
+// mobile bindings. The layout is
+// [2 bytes ECDH len][ECDH priv][2 bytes ML-KEM pub len][ML-KEM pub][ML-KEM priv];
+// the ML-KEM private key is in the format of the PQC backend, so the key
+// must be imported by a build with the same backend.
+func MarshalKEMPrivateKey(k bccsp.Key) ([]byte, error) {
+	priv, ok := k.(*hybridKEMKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid key type, expected *hybridKEMKey")
+	}
+	if !priv.Private() {
+		return nil, fmt.Errorf("hybrid KEM private key export: %w", ErrPublicKeyOnly)
+	}
+	ecdhPriv := priv.ecdhPriv.Bytes()
+	out := make([]byte, 0, 4+len(ecdhPriv)+len(priv.kemPub)+len(priv.kemPriv))
+	out = binary.BigEndian.AppendUint16(out, uint16(len(ecdhPriv)))
+	out = append(out, ecdhPriv...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(priv.kemPub)))
+	out = append(out, priv.kemPub...)
+	return append(out, priv.kemPriv...), nil
+}
+
+func parseKEMPrivateKey(raw []byte) (*hybridKEMKey, error) {
+	ecdhRaw, rest, err := readUint16Prefixed(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDH private key: %w", err)
+	}
+	kemPub, kemPriv, err := readUint16Prefixed(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ML-KEM public key: %w", err)
+	}
+	if len(kemPub) == 0 || len(kemPriv) == 0 {
+		return nil, errors.New("invalid hybrid KEM private key: ML-KEM component is empty")
+	}
+	ecdhPriv, err := ecdh.P256().NewPrivateKey(ecdhRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDH private key: %w", err)
+	}
+	return &hybridKEMKey{
+		ecdhPriv: ecdhPriv,
+		ecdhPub:  ecdhPriv.PublicKey(),
+		kemPub:   append([]byte(nil), kemPub...),
+		kemPriv:  append([]byte(nil), kemPriv...),
+	}, nil
+}
+
+// kemKeyGen generates a hybrid KEM key pair
+func kemKeyGen() (*hybridKEMKey, error) {
+	ecdhPriv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH KeyGen failed: %w", err)
+	}
+
+	kemPub, kemPriv, err := mlkemKeyGen()
+	if err != nil {
+		return nil, err
+	}
+
+	return &hybridKEMKey{
+		ecdhPriv: ecdhPriv,
+		ecdhPub:  ecdhPriv.PublicKey(),
+		kemPub:   kemPub,
+		kemPriv:  kemPriv,
+	}, nil
+}
+
+// deriveKEMKey combines both shared secrets with the transcript into an AES-256 key
+func deriveKEMKey(ssECDH, ssKEM, ephPub, recipientPub, kdfInfo []byte) ([]byte, error) {
+	secret := make([]byte, 0, len(ssKEM)+len(ssECDH)+len(ephPub)+len(recipientPub))
+	secret = append(secret, ssKEM...)
+	secret = append(secret, ssECDH...)
+	secret = append(secret, ephPub...)
+	secret = append(secret, recipientPub...)
+	info := append([]byte(kemInfoPrefix), kdfInfo...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// kemRecipient resolves the recipient key from k or, when k is nil, from its marshaled form
+func kemRecipient(k bccsp.Key, raw []byte) (*hybridKEMKey, error) {
+	if k == nil {
+		if len(raw) == 0 {
+			return nil, fmt.Errorf("%w: a nil key requires RecipientPublicKey in opts", ErrNilKey)
+		}
+		return parseKEMPublicKey(raw)
+	}
+	recipient, ok := k.(*hybridKEMKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid key type, expected *hybridKEMKey")
+	}
+	return recipient, nil
+}
+
+// kemEncapsulate derives a fresh AES-256 key for recipient.
+// The returned header is: [version][2 bytes eph len][eph ECDH pub][2 bytes ct len][ML-KEM ct]
+func kemEncapsulate(recipient *hybridKEMKey, kdfInfo []byte) (header, key []byte, err error) {
+	eph, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ECDH ephemeral KeyGen failed: %w", err)
+	}
+	ssECDH, err := eph.ECDH(recipient.ecdhPub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ECDH failed: %w", err)
+	}
+
+	kemCt, ssKEM, err := mlkemEncapsulate(recipient.kemPub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ephPub := eph.PublicKey().Bytes()
+	key, err = deriveKEMKey(ssECDH, ssKEM, ephPub, recipient.ecdhPub.Bytes(), kdfInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	header = make([]byte, 0, 1+2+len(ephPub)+2+len(kemCt))
+	header = append(header, kemCiphertextVersion)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(ephPub)))
+	header = append(header, ephPub...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(kemCt)))
+	header = append(header, kemCt...)
+	return header, key, nil
+}
+
+// kemDecapsulate parses the header written by kemEncapsulate and recovers the AES-256 key
+func kemDecapsulate(k bccsp.Key, data, kdfInfo []byte) (key, rest []byte, err error) {
+	priv, ok := k.(*hybridKEMKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid key type, expected *hybridKEMKey")
+	}
+	if !priv.Private() {
+		return nil, nil, fmt.Errorf("hybrid KEM decapsulation: %w", ErrPublicKeyOnly)
+	}
+
+	if len(data) < 1 || data[0] != kemCiphertextVersion {
+		return nil, nil, errors.New("unsupported hybrid KEM ciphertext version")
+	}
+	ephPub, rest, err := readUint16Prefixed(data[1:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	kemCt, rest, err := readUint16Prefixed(rest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid KEM ciphertext: %w", err)
+	}
+
+	eph, err := ecdh.P256().NewPublicKey(ephPub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	ssECDH, err := priv.ecdhPriv.ECDH(eph)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ECDH failed: %w", err)
+	}
+
+	ssKEM, err := mlkemDecapsulate(priv.kemPriv, kemCt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err = deriveKEMKey(ssECDH, ssKEM, ephPub, priv.ecdhPub.Bytes(), kdfInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+	return key, rest, nil
+}
+
+// kemEncrypt creates: [KEM header][nonce][AES-GCM ciphertext]
+func kemEncrypt(k bccsp.Key, plaintext []byte, opts *HybridKEMEncrypterOpts) ([]byte, error) {
+	recipient, err := kemRecipient(k, opts.RecipientPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	header, key, err := kemEncapsulate(recipient, opts.KDFInfo)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, opts.AAD), nil
+}
+
+// kemDecrypt reverses kemEncrypt with the recipient's private key
+func kemDecrypt(k bccsp.Key, ciphertext []byte, opts *HybridKEMDecrypterOpts) ([]byte, error) {
+	key, rest, err := kemDecapsulate(k, ciphertext, opts.KDFInfo)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("hybrid KEM ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], opts.AAD)
+	if err != nil {
+		return nil, fmt.Errorf("hybrid KEM decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// readUint16Prefixed splits [2 bytes len][value] from the head of buf
+func readUint16Prefixed(buf []byte) (value, rest []byte, err error) {
+	if len(buf) < 2 {
+		return nil, nil, errors.New("length prefix missing")
+	}
+	n := int(binary.BigEndian.Uint16(buf[:2]))
+	if n > len(buf)-2 {
+		return nil, nil, errors.New("length exceeds buffer size")
+	}
+	return buf[2 : 2+n], buf[2+n:], nil
+}
@@ -0,0 +1,52 @@
+//go:build !purego && !verifyonly
+
+package hybrid
+
+import (
+	"fmt"
+
+	"github.com/open-quantum-safe/liboqs-go/oqs"
+)
+
+// mlkemKeyGen generates an ML-KEM key pair with liboqs
+func mlkemKeyGen() (pub, priv []byte, err error) {
+	kem := oqs.KeyEncapsulation{}
+	if err := kem.Init(KEMAlgorithm, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to init KEM: %w", err)
+	}
+	defer kem.Clean()
+
+	pub, err = kem.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ML-KEM KeyGen failed: %w", err)
+	}
+	return pub, kem.ExportSecretKey(), nil
+}
+
+// mlkemEncapsulate returns a ciphertext for pub and its shared secret
+func mlkemEncapsulate(pub []byte) (ct, ss []byte, err error) {
+	kem := oqs.KeyEncapsulation{}
+	if err := kem.Init(KEMAlgorithm, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to init KEM: %w", err)
+	}
+	defer kem.Clean()
+	ct, ss, err = kem.EncapSecret(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ML-KEM encapsulation failed: %w", err)
+	}
+	return ct, ss, nil
+}
+
+// mlkemDecapsulate recovers the shared secret of ct with priv
+func mlkemDecapsulate(priv, ct []byte) ([]byte, error) {
+	kem := oqs.KeyEncapsulation{}
+	if err := kem.Init(KEMAlgorithm, priv); err != nil {
+		return nil, fmt.Errorf("failed to init KEM with private key: %w", err)
+	}
+	defer kem.Clean()
+	ss, err := kem.DecapSecret(ct)
+	if err != nil {
+		return nil, fmt.Errorf("ML-KEM decapsulation failed: %w", err)
+	}
+	return ss, nil
+}
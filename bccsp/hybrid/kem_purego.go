@@ -0,0 +1,44 @@
+//go:build (purego || verifyonly) && go1.27
+
+package hybrid
+
+import (
+	"crypto/mlkem"
+	"fmt"
+)
+
+// With the purego and verifyonly build tags ML-KEM comes from crypto/mlkem.
+// Private keys are the 64-byte seed rather than liboqs' expanded key, see
+// core.PQCBackend.
+
+// mlkemKeyGen generates an ML-KEM key pair
+func mlkemKeyGen() (pub, priv []byte, err error) {
+	dk, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ML-KEM KeyGen failed: %w", err)
+	}
+	return dk.EncapsulationKey().Bytes(), dk.Bytes(), nil
+}
+
+// mlkemEncapsulate returns a ciphertext for pub and its shared secret
+func mlkemEncapsulate(pub []byte) (ct, ss []byte, err error) {
+	ek, err := mlkem.NewEncapsulationKey768(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ML-KEM encapsulation failed: %w", err)
+	}
+	ss, ct = ek.Encapsulate()
+	return ct, ss, nil
+}
+
+// mlkemDecapsulate recovers the shared secret of ct with priv
+func mlkemDecapsulate(priv, ct []byte) ([]byte, error) {
+	dk, err := mlkem.NewDecapsulationKey768(priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init KEM with private key: %w", err)
+	}
+	ss, err := dk.Decapsulate(ct)
+	if err != nil {
+		return nil, fmt.Errorf("ML-KEM decapsulation failed: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,138 @@
+package hybrid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKEMEncryptDecrypt(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+
+	priv, err := h.KeyGen(&HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	assert.True(t, priv.Private())
+
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+	raw, err := pub.Bytes()
+	require.NoError(t, err)
+
+	plaintext := []byte("off-chain document")
+	encOpts := &HybridKEMEncrypterOpts{AAD: []byte("doc-42"), KDFInfo: []byte("docstore")}
+	decOpts := &HybridKEMDecrypterOpts{AAD: []byte("doc-42"), KDFInfo: []byte("docstore")}
+
+	ct, err := h.Encrypt(pub, plaintext, encOpts)
+	require.NoError(t, err)
+	pt, err := h.Decrypt(priv, ct, decOpts)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, pt)
+
+	// Recipient passed through the opts with a nil key
+	ct, err = h.Encrypt(nil, plaintext, &HybridKEMEncrypterOpts{RecipientPublicKey: raw, AAD: encOpts.AAD, KDFInfo: encOpts.KDFInfo})
+	require.NoError(t, err)
+	pt, err = h.Decrypt(priv, ct, decOpts)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, pt)
+
+	// Imported public key behaves like the original one
+	imported, err := h.KeyImport(raw, &HybridKEMPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	assert.Equal(t, pub.SKI(), imported.SKI())
+	imported, err = h.KeyImport(raw[:1], &HybridKEMPublicKeyImportOpts{})
+	assert.Error(t, err)
+	assert.True(t, imported == nil, "no typed nil key")
+
+	_, err = h.Decrypt(priv, ct, &HybridKEMDecrypterOpts{AAD: []byte("other"), KDFInfo: decOpts.KDFInfo})
+	assert.Error(t, err, "AAD mismatch should fail")
+
+	_, err = h.Decrypt(pub, ct, decOpts)
+	assert.Error(t, err, "public key cannot decrypt")
+
+	ct[len(ct)-1] ^= 0xFF
+	_, err = h.Decrypt(priv, ct, decOpts)
+	assert.Error(t, err, "tampered ciphertext should fail")
+}
+
+func TestKEMPrivateKeyExport(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+	priv, err := h.KeyGen(&HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+
+	raw, err := MarshalKEMPrivateKey(priv)
+	require.NoError(t, err)
+	imported, err := h.KeyImport(raw, &HybridKEMPrivateKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	assert.True(t, imported.Private())
+	assert.Equal(t, priv.SKI(), imported.SKI())
+
+	ct, err := h.Encrypt(pub, []byte("attachment"), &HybridKEMEncrypterOpts{})
+	require.NoError(t, err)
+	pt, err := h.Decrypt(imported, ct, &HybridKEMDecrypterOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("attachment"), pt)
+
+	_, err = MarshalKEMPrivateKey(pub)
+	assert.ErrorIs(t, err, ErrPublicKeyOnly)
+	imported, err = h.KeyImport(raw[:10], &HybridKEMPrivateKeyImportOpts{})
+	assert.Error(t, err)
+	assert.True(t, imported == nil, "no typed nil key")
+}
+
+func TestAESKeyWrapRFC3394(t *testing.T) {
+	// RFC 3394, section 4.1: wrap 128 bits of key data with a 128-bit KEK
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	expected, _ := hex.DecodeString("1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+
+	wrapped, err := aesKeyWrap(kek, key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := aesKeyUnwrap(kek, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 0x01
+	_, err = aesKeyUnwrap(kek, wrapped)
+	assert.Error(t, err)
+}
+
+func TestWrapUnwrapKey(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+	hb := h.(*HybridBCCSP)
+
+	priv, err := h.KeyGen(&HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+
+	aesKey := make([]byte, 32)
+	_, err = rand.Read(aesKey)
+	require.NoError(t, err)
+
+	wrapped, err := hb.WrapKey(pub, aesKey)
+	require.NoError(t, err)
+
+	unwrapped, err := hb.UnwrapKey(priv, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, aesKey, unwrapped)
+
+	_, err = hb.UnwrapKey(pub, wrapped)
+	assert.Error(t, err, "public key cannot unwrap")
+
+	wrapped[len(wrapped)-1] ^= 0xFF
+	_, err = hb.UnwrapKey(priv, wrapped)
+	assert.Error(t, err, "tampered wrapped key should fail")
+
+	_, err = hb.WrapKey(pub, []byte("short"))
+	assert.Error(t, err)
+}
@@ -10,6 +10,11 @@ type hybridKey struct {
 	ecdsaKey bccsp.Key
 	pqcPriv  *PQCSigner
 	pqcPub   []byte
+	// ecdsaCSP holds ecdsaKey when it is not a SW key, e.g. the classical
+	// provider of a DualKeyStore
+	ecdsaCSP bccsp.BCCSP
+	// locked is the ECDSA scalar, if locked by WithMemoryLocking
+	locked []byte
 }
 
 func (k *hybridKey) Bytes() ([]byte, error) {
@@ -37,6 +42,7 @@ func (k *hybridKey) PublicKey() (bccsp.Key, error) {
 		ecdsaKey: ecdsaPub,
 		pqcPub:   k.pqcPub,
 		pqcPriv:  nil, // Public key has no private component
+		ecdsaCSP: k.ecdsaCSP,
 	}, nil
 }
 
@@ -0,0 +1,250 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Problems reported by KeyStore.Audit
+var (
+	// ErrOrphanedHalf is reported for a key stored with only one of its halves
+	ErrOrphanedHalf = errors.New("orphaned key half")
+	// ErrSKIMismatch is reported for an ECDSA key stored under the SKI of
+	// another key
+	ErrSKIMismatch = errors.New("SKI does not match the ECDSA key")
+)
+
+// auditMessage is signed by both halves of every audited key
+var auditMessage = sha256.Sum256([]byte("quantum-ledger keystore audit"))
+
+// KeyAudit is the result of auditing the files stored under one SKI
+type KeyAudit struct {
+	Namespace string
+	// SKI is the one in the file names
+	SKI []byte
+	// Problems wrap ErrOrphanedHalf, ErrSKIMismatch or ErrKeyPairMismatch,
+	// or report files that do not parse
+	Problems []error
+}
+
+// OK reports whether the key has no problems
+func (a KeyAudit) OK() bool {
+	return len(a.Problems) == 0
+}
+
+// keyFiles are the files of a key in a namespace directory
+type keyFiles struct {
+	dir                  string
+	ecdsa, ecdsaPub, pqc bool
+}
+
+// Audit checks every key file of every namespace, including namespaces
+// holding only ECDSA halves: that each key has both halves, that the SKI
+// recomputed from the ECDSA key matches the file names, and that each half
+// signs a test message its stored public key verifies. Keys are sorted by
+// namespace and SKI. Audit only reads the keystore.
+func (s *KeyStore) Audit() ([]KeyAudit, error) {
+	files := map[[2]string]*keyFiles{}
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		ns := filepath.ToSlash(rel)
+		if ValidateNamespace(ns) != nil {
+			return nil
+		}
+		// File names of the Fabric SW keystore and of the PQC halves
+		name, suffix, ok := cutKeySuffix(d.Name())
+		if !ok {
+			return nil
+		}
+		if _, err := hex.DecodeString(name); err != nil {
+			return nil
+		}
+		f := files[[2]string{ns, name}]
+		if f == nil {
+			f = &keyFiles{dir: filepath.Dir(path)}
+			files[[2]string{ns, name}] = f
+		}
+		switch suffix {
+		case "_sk":
+			f.ecdsa = true
+		case "_pk":
+			f.ecdsaPub = true
+		case pqcKeySuffix:
+			f.pqc = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audits := make([]KeyAudit, 0, len(files))
+	for id, f := range files {
+		ski, _ := hex.DecodeString(id[1])
+		audits = append(audits, KeyAudit{Namespace: id[0], SKI: ski, Problems: s.auditKey(f, id[1])})
+	}
+	sort.Slice(audits, func(i, j int) bool {
+		if audits[i].Namespace != audits[j].Namespace {
+			return audits[i].Namespace < audits[j].Namespace
+		}
+		return hex.EncodeToString(audits[i].SKI) < hex.EncodeToString(audits[j].SKI)
+	})
+	return audits, nil
+}
+
+func cutKeySuffix(file string) (name, suffix string, ok bool) {
+	for _, suffix := range []string{"_sk", "_pk", pqcKeySuffix} {
+		if name, ok := strings.CutSuffix(file, suffix); ok {
+			return name, suffix, true
+		}
+	}
+	return "", "", false
+}
+
+// auditKey checks the files of the key stored as name in f.dir
+func (s *KeyStore) auditKey(f *keyFiles, name string) []error {
+	var problems []error
+	switch {
+	case f.pqc && !f.ecdsa && f.ecdsaPub:
+		problems = append(problems, fmt.Errorf("%w: PQC half without ECDSA private half, only its public key", ErrOrphanedHalf))
+	case f.pqc && !f.ecdsa:
+		problems = append(problems, fmt.Errorf("%w: PQC half without ECDSA half", ErrOrphanedHalf))
+	case f.ecdsa && !f.pqc:
+		problems = append(problems, fmt.Errorf("%w: ECDSA half without PQC half", ErrOrphanedHalf))
+	}
+
+	if f.ecdsa {
+		problems = append(problems, auditECDSA(f, name, s.keyCipher().secret())...)
+	}
+	if f.pqc {
+		if err := s.auditPQC(filepath.Join(f.dir, name+pqcKeySuffix), name); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// auditECDSA recomputes the SKI of the ECDSA half, as Fabric does, and
+// checks it against the file name and the stored public key
+func auditECDSA(f *keyFiles, name string, passphrase []byte) []error {
+	raw, err := os.ReadFile(filepath.Join(f.dir, name+"_sk"))
+	if err != nil {
+		return []error{err}
+	}
+	key, err := parseECDSAKeyFile(raw, passphrase)
+	if err != nil {
+		return []error{fmt.Errorf("ECDSA half: %w", err)}
+	}
+	point, err := key.PublicKey.ECDH()
+	if err != nil {
+		return []error{fmt.Errorf("ECDSA half: %w", err)}
+	}
+	var problems []error
+	ski := sha256.Sum256(point.Bytes())
+	if hex.EncodeToString(ski[:]) != name {
+		problems = append(problems, fmt.Errorf("%w: stored as %s, key has SKI %x", ErrSKIMismatch, name, ski))
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, key, auditMessage[:])
+	if err != nil || !ecdsa.VerifyASN1(&key.PublicKey, auditMessage[:], sig) {
+		problems = append(problems, errors.New("ECDSA half does not sign"))
+	}
+	if f.ecdsaPub {
+		if err := auditECDSAPublic(filepath.Join(f.dir, name+"_pk"), key, passphrase); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// auditECDSAPublic checks that the stored ECDSA public key belongs to key
+func auditECDSAPublic(path string, key *ecdsa.PrivateKey, passphrase []byte) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return errors.New("ECDSA public key: no PEM block")
+	}
+	der, err := decryptKeyPEM(block, passphrase)
+	if err != nil {
+		return fmt.Errorf("ECDSA public key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return fmt.Errorf("ECDSA public key: %w", err)
+	}
+	if pub, ok := parsed.(*ecdsa.PublicKey); !ok || !pub.Equal(&key.PublicKey) {
+		return errors.New("stored ECDSA public key does not match the private key")
+	}
+	return nil
+}
+
+// auditPQC checks that the PQC half signs a message its public key verifies
+func (s *KeyStore) auditPQC(path, name string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ski, _ := hex.DecodeString(name)
+	pqc, err := s.openPQCKeyFile(ski, raw)
+	if err != nil {
+		return fmt.Errorf("PQC half: %w", err)
+	}
+	signer, err := NewPQCSignerFromKeyPair(pqc.PrivateKey, pqc.PublicKey)
+	if err != nil {
+		return fmt.Errorf("PQC half: %w", err)
+	}
+	sig, err := signer.Sign(auditMessage[:])
+	if err != nil {
+		return fmt.Errorf("PQC half: %w", err)
+	}
+	if valid, err := VerifyPQC(pqc.PublicKey, auditMessage[:], sig); err != nil || !valid {
+		return fmt.Errorf("PQC half: %w", ErrKeyPairMismatch)
+	}
+	return nil
+}
+
+// parseECDSAKeyFile parses the PEM private keys written by the Fabric SW
+// keystore, PKCS#8 or SEC 1, encrypted with passphrase if it has one
+func parseECDSAKeyFile(raw, passphrase []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	// Encrypted keys are SEC 1 whatever their PEM type
+	sec1 := block.Type == "EC PRIVATE KEY" || x509.IsEncryptedPEMBlock(block)
+	der, err := decryptKeyPEM(block, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if sec1 {
+		return x509.ParseECPrivateKey(der)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid key type, expected *ecdsa.PrivateKey")
+	}
+	return key, nil
+}
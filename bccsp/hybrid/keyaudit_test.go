@@ -0,0 +1,94 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyFiles writes a key as the Fabric SW keystore and StoreKey do,
+// returning its hex SKI
+func writeKeyFiles(t *testing.T, dir string, ecdsaHalf bool, pqc *PQCSigner) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	point, err := key.PublicKey.ECDH()
+	require.NoError(t, err)
+	ski := sha256.Sum256(point.Bytes())
+	name := hex.EncodeToString(ski[:])
+	if ecdsaHalf {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		raw := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+"_sk"), raw, 0o600))
+	}
+	if pqc != nil {
+		raw, err := marshalPQCKeyFile(pqc.PrivateKey(), pqc.PublicKey())
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+pqcKeySuffix), raw, 0o600))
+	}
+	return name
+}
+
+func TestKeyStoreAudit(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := NewKeyStore(dir)
+	require.NoError(t, err)
+	org1 := filepath.Join(dir, "Org1MSP")
+	signer := func() *PQCSigner {
+		s, err := NewPQCSigner()
+		require.NoError(t, err)
+		return s
+	}
+
+	good := writeKeyFiles(t, org1, true, signer())
+	orphanPQC := writeKeyFiles(t, org1, false, signer())
+	orphanECDSA := writeKeyFiles(t, filepath.Join(dir, "Org2MSP"), true, nil)
+
+	// A manual copy put one key's ECDSA half under another's SKI
+	copied := writeKeyFiles(t, org1, true, signer())
+	raw, err := os.ReadFile(filepath.Join(org1, good+"_sk"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(org1, copied+"_sk"), raw, 0o600))
+
+	// PQC halves of two key pairs mixed up
+	a, b := signer(), signer()
+	mixed := writeKeyFiles(t, org1, true, nil)
+	pqcRaw, err := marshalPQCKeyFile(a.PrivateKey(), b.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(org1, mixed+pqcKeySuffix), pqcRaw, 0o600))
+
+	audits, err := ks.Audit()
+	require.NoError(t, err)
+	byKey := map[string]KeyAudit{}
+	for _, a := range audits {
+		byKey[a.Namespace+"/"+hex.EncodeToString(a.SKI)] = a
+	}
+	require.Len(t, byKey, 5)
+
+	assert.True(t, byKey["Org1MSP/"+good].OK())
+	assert.ErrorIs(t, byKey["Org1MSP/"+orphanPQC].Problems[0], ErrOrphanedHalf)
+	assert.ErrorIs(t, byKey["Org2MSP/"+orphanECDSA].Problems[0], ErrOrphanedHalf)
+	require.Len(t, byKey["Org1MSP/"+copied].Problems, 1)
+	assert.ErrorIs(t, byKey["Org1MSP/"+copied].Problems[0], ErrSKIMismatch)
+	require.Len(t, byKey["Org1MSP/"+mixed].Problems, 1)
+	assert.ErrorIs(t, byKey["Org1MSP/"+mixed].Problems[0], ErrKeyPairMismatch)
+
+	// Sorted by namespace, then SKI
+	for i := 1; i < len(audits); i++ {
+		prev, cur := audits[i-1], audits[i]
+		assert.True(t, prev.Namespace < cur.Namespace ||
+			prev.Namespace == cur.Namespace && hex.EncodeToString(prev.SKI) < hex.EncodeToString(cur.SKI))
+	}
+}
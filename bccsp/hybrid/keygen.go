@@ -2,28 +2,175 @@ package hybrid
 
 import (
 	"fmt"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/audit"
 )
 
 // KeyGen genera una chiave ibrida (ECDSA + PQC)
-func (h *HybridBCCSP) KeyGen(opts bccsp.KeyGenOpts) (bccsp.Key, error) {
+func (h *HybridBCCSP) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
+	defer func(start time.Time) {
+		h.stats.keyGen.observe(start, true, err)
+		h.emitAudit(audit.OpKeyGen, k, "", true, err)
+	}(time.Now())
+	algorithm := AlgorithmHybrid
+	defer func() { err = wrapError(OpKeyGen, algorithm, nil, err) }()
+
+	if isNilOpts(opts) {
+		return nil, ErrNilOpts
+	}
+
+	// Chiavi KEM ibride (ECDH + ML-KEM) per Encrypt/Decrypt
+	if _, ok := opts.(*HybridKEMKeyGenOpts); ok {
+		algorithm = KEMAlgorithm
+		kemKey, err := kemKeyGen()
+		if err != nil {
+			return nil, err
+		}
+		return kemKey, nil
+	}
+	// Chiavi hash-based stateful (LMS)
+	if lmsOpts, ok := opts.(*LMSKeyGenOpts); ok {
+		algorithm = AlgorithmLMS
+		lk, err := h.lmsKeyGen(lmsOpts)
+		if err != nil {
+			return nil, err
+		}
+		return lk, nil
+	}
+
+	// Con un keystore duale le due metà nascono ciascuna nel proprio dominio
+	if h.dual != nil && !opts.Ephemeral() {
+		key, err := h.dual.keyGen(opts)
+		if err != nil {
+			return nil, err
+		}
+		h.lockKey(key)
+		return key, nil
+	}
+
 	// 1️⃣ ECDSA
-	ecdsaKey, err := h.sw.KeyGen(opts)
+	prof := h.profiler(nil)
+	var ecdsaKey bccsp.Key
+	prof.do("keygen", AlgorithmECDSA, func() { ecdsaKey, err = h.sw.KeyGen(h.swKeyGenOpts(opts)) })
 	if err != nil {
-		return nil, fmt.Errorf("ECDSA KeyGen failed: %w", err)
+		return nil, wrapError(OpKeyGen, AlgorithmECDSA, nil, fmt.Errorf("ECDSA KeyGen failed: %w", err))
 	}
 
 	// 2️⃣ PQC
-	pqcSigner, err := NewPQCSigner()
+	var pqcSigner *PQCSigner
+	prof.do("keygen", AlgorithmMLDSA, func() { pqcSigner, err = NewPQCSigner() })
 	if err != nil {
-		return nil, fmt.Errorf("PQC KeyGen failed: %w", err)
+		return nil, wrapError(OpKeyGen, AlgorithmMLDSA, nil, fmt.Errorf("PQC KeyGen failed: %w", err))
 	}
 
 	// 3️⃣ hybridKey
-	return &hybridKey{
+	key := &hybridKey{
 		ecdsaKey: ecdsaKey,
 		pqcPub:   pqcSigner.PublicKey(),
 		pqcPriv:  pqcSigner, // memorizziamo il signer completo
-	}, nil
+	}
+	h.lockKey(key)
+
+	// 4️⃣ keystore del namespace, per le chiavi non effimere
+	if h.keystore != nil && !opts.Ephemeral() {
+		if err := h.keystore.StoreKey(h.namespace, key); err != nil {
+			return nil, wrapError(OpKeyGen, AlgorithmHybrid, key.SKI(), fmt.Errorf("failed to store hybrid key: %w", err))
+		}
+	}
+	h.trackEphemeral(key, opts.Ephemeral())
+	return key, nil
+}
+
+// swKeyGenOpts restituisce le opts della metà ECDSA. Con un keystore di
+// namespace è il keystore ibrido a salvare la chiave intera, mentre quello
+// SW è un dummy che rifiuta di salvare: la metà ECDSA nasce temporanea.
+func (h *HybridBCCSP) swKeyGenOpts(opts bccsp.KeyGenOpts) bccsp.KeyGenOpts {
+	if h.keystore == nil || opts.Ephemeral() {
+		return opts
+	}
+	switch opts.(type) {
+	case *bccsp.ECDSAKeyGenOpts:
+		return &bccsp.ECDSAKeyGenOpts{Temporary: true}
+	case *bccsp.ECDSAP256KeyGenOpts:
+		return &bccsp.ECDSAP256KeyGenOpts{Temporary: true}
+	case *bccsp.ECDSAP384KeyGenOpts:
+		return &bccsp.ECDSAP384KeyGenOpts{Temporary: true}
+	}
+	return opts
+}
+
+// BatchProgress riceve il numero di chiavi generate su total, da una
+// goroutine alla volta
+type BatchProgress func(done, total int)
+
+// BatchKeyGenerator è implementato dal provider restituito da New
+type BatchKeyGenerator interface {
+	KeyGenBatch(n int, opts bccsp.KeyGenOpts, progress BatchProgress) ([]bccsp.Key, error)
+}
+
+// KeyGenBatch genera n chiavi con opts in parallelo, una goroutine per CPU.
+// Serve al provisioning di molte identità di test: ML-DSA domina il costo e
+// scala con i core. progress può essere nil.
+func (h *HybridBCCSP) KeyGenBatch(n int, opts bccsp.KeyGenOpts, progress BatchProgress) ([]bccsp.Key, error) {
+	keys := make([]bccsp.Key, max(n, 0))
+	err := GenerateBatch(n, progress, func(i int) (err error) {
+		keys[i], err = h.KeyGen(opts)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError(OpKeyGen, AlgorithmHybrid, nil, err)
+	}
+	return keys, nil
+}
+
+// GenerateBatch chiama gen(i) per i in [0, n) su GOMAXPROCS goroutine e
+// riporta l'avanzamento. Il primo errore ferma la distribuzione dei lavori
+// e viene restituito.
+func GenerateBatch(n int, progress BatchProgress, gen func(i int) error) error {
+	if n < 0 {
+		return fmt.Errorf("invalid batch size %d", n)
+	}
+	var (
+		mutex    sync.Mutex
+		done     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for w := 0; w < min(runtime.GOMAXPROCS(0), n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := gen(i)
+				mutex.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if err == nil {
+					done++
+					if progress != nil {
+						progress(done, n)
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		mutex.Lock()
+		failed := firstErr != nil
+		mutex.Unlock()
+		if failed {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
 }
@@ -0,0 +1,305 @@
+package hybrid
+
+import (
+	"context"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/bccsp/sw"
+)
+
+// pqcKeySuffix names the PQC half of a key; the ECDSA half is the
+// <hex ski>_sk file of the Fabric SW keystore in the same directory
+const pqcKeySuffix = "_pqc"
+
+// ErrKeyNotFound is returned by KeyStore.GetKey for keys not in the namespace
+var ErrKeyNotFound = errors.New("key not found in keystore namespace")
+
+// namespaceSegment is one level of a namespace, e.g. an MSP ID or a channel
+var namespaceSegment = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateNamespace checks a keystore namespace: one or more segments of
+// letters, digits, '.', '_' and '-' separated by '/', e.g. "Org1MSP" or
+// "Org1MSP/mychannel"
+func ValidateNamespace(ns string) error {
+	for _, segment := range strings.Split(ns, "/") {
+		if !namespaceSegment.MatchString(segment) {
+			return fmt.Errorf("invalid keystore namespace %q", ns)
+		}
+	}
+	return nil
+}
+
+// KeyStore persists hybrid keys in isolated namespaces, one directory each,
+// so a single signing daemon can hold the keys of several organizations or
+// channels without SKI collisions or cross-namespace lookups
+type KeyStore struct {
+	mutex  sync.Mutex
+	dir    string
+	stores map[string]bccsp.KeyStore
+	retry  RetryPolicy
+	cipher *keystoreCipher
+}
+
+// NewKeyStore opens or creates a namespaced keystore rooted at dir
+func NewKeyStore(dir string) (*KeyStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory %s: %w", dir, err)
+	}
+	return &KeyStore{dir: dir, stores: map[string]bccsp.KeyStore{}}, nil
+}
+
+// SetRetryPolicy retries transient read failures of GetKey, e.g. a keystore
+// on a network file system that is briefly unreachable at peer startup
+func (s *KeyStore) SetRetryPolicy(p RetryPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.retry = p
+}
+
+// namespace returns the directory and SW keystore of ns
+func (s *KeyStore) namespace(ns string) (string, bccsp.KeyStore, error) {
+	if err := ValidateNamespace(ns); err != nil {
+		return "", nil, err
+	}
+	dir := filepath.Join(s.dir, filepath.FromSlash(ns))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ks, ok := s.stores[ns]; ok {
+		return dir, ks, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", nil, fmt.Errorf("failed to create keystore namespace %s: %w", ns, err)
+	}
+	ks, err := sw.NewFileBasedKeyStore(s.cipher.secret(), dir, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open keystore namespace %s: %w", ns, err)
+	}
+	s.stores[ns] = ks
+	return dir, ks, nil
+}
+
+// StoreKey saves a private hybrid or LMS key in ns
+func (s *KeyStore) StoreKey(ns string, k bccsp.Key) error {
+	if lk, ok := k.(*lmsKey); ok && lk != nil {
+		return s.storeLMSKey(ns, lk)
+	}
+	key, ok := k.(*hybridKey)
+	if !ok || isNilKey(k) {
+		return fmt.Errorf("invalid key type, expected *hybridKey")
+	}
+	if key.pqcPriv == nil || !key.Private() {
+		return ErrPublicKeyOnly
+	}
+	dir, ks, err := s.namespace(ns)
+	if err != nil {
+		return err
+	}
+	raw, err := s.sealPQCKeyFile(key.SKI(), key.pqcPriv.PrivateKey(), key.pqcPub)
+	if err != nil {
+		return err
+	}
+	if err := ks.StoreKey(key.ecdsaKey); err != nil {
+		return fmt.Errorf("failed to store ECDSA key: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, hex.EncodeToString(key.SKI())+pqcKeySuffix), raw, 0o600)
+}
+
+// GetKey loads the key with the given SKI from ns only
+func (s *KeyStore) GetKey(ns string, ski []byte) (k bccsp.Key, err error) {
+	if len(ski) == 0 {
+		return nil, ErrEmptySKI
+	}
+	s.mutex.Lock()
+	policy := s.retry
+	s.mutex.Unlock()
+	err = policy.Do(context.Background(), func() (err error) {
+		k, err = s.getKey(ns, ski)
+		return err
+	})
+	return k, err
+}
+
+func (s *KeyStore) getKey(ns string, ski []byte) (bccsp.Key, error) {
+	dir, ks, err := s.namespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, hex.EncodeToString(ski)+pqcKeySuffix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %x in %s", ErrKeyNotFound, ski, ns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pqc, err := s.openPQCKeyFile(ski, raw)
+	if err != nil {
+		return nil, fmt.Errorf("key %x in %s: %w", ski, ns, err)
+	}
+	ecdsaKey, err := ks.GetKey(ski)
+	if err != nil {
+		return nil, fmt.Errorf("key %x in %s: %w", ski, ns, err)
+	}
+	signer, err := NewPQCSignerFromKeyPair(pqc.PrivateKey, pqc.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &hybridKey{ecdsaKey: ecdsaKey, pqcPriv: signer, pqcPub: pqc.PublicKey}, nil
+}
+
+// ListKeys returns the SKIs of the keys in ns, sorted
+func (s *KeyStore) ListKeys(ns string) ([][]byte, error) {
+	dir, _, err := s.namespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var skis [][]byte
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), pqcKeySuffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if ski, err := hex.DecodeString(name); err == nil {
+			skis = append(skis, ski)
+		}
+	}
+	return skis, nil
+}
+
+// DeleteKey removes the key with the given SKI from ns: its PQC half and
+// the files of the SW keystore. The key can no longer sign.
+func (s *KeyStore) DeleteKey(ns string, ski []byte) error {
+	if len(ski) == 0 {
+		return ErrEmptySKI
+	}
+	dir, _, err := s.namespace(ns)
+	if err != nil {
+		return err
+	}
+	name := hex.EncodeToString(ski)
+	if err := os.Remove(filepath.Join(dir, name+pqcKeySuffix)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %x in %s", ErrKeyNotFound, ski, ns)
+		}
+		return err
+	}
+	// File names of the Fabric SW keystore
+	var errs []error
+	for _, suffix := range []string{"_sk", "_pk"} {
+		if err := os.Remove(filepath.Join(dir, name+suffix)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Namespaces returns every namespace holding at least one key, sorted
+func (s *KeyStore) Namespaces() ([]string, error) {
+	seen := map[string]bool{}
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), pqcKeySuffix) {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if ns := filepath.ToSlash(rel); ValidateNamespace(ns) == nil {
+			seen[ns] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(seen))
+	for ns := range seen {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// Check verifies that the keystore directory can be listed and written, so
+// a misconfigured volume is reported before the first key generation
+func (s *KeyStore) Check() error {
+	if _, err := s.Namespaces(); err != nil {
+		return fmt.Errorf("keystore %s is not readable: %w", s.dir, err)
+	}
+	f, err := os.CreateTemp(s.dir, ".probe-*")
+	if err != nil {
+		return fmt.Errorf("keystore %s is not writable: %w", s.dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// WithKeyStore persists non-ephemeral hybrid keys generated or imported by
+// the provider in namespace ns of ks, and makes GetKey look them up there
+// before the SW keystore. Providers for different namespaces can share ks.
+func WithKeyStore(ks *KeyStore, ns string) Option {
+	return func(h *HybridBCCSP) {
+		h.keystore, h.namespace = ks, ns
+	}
+}
+
+func pqcOID() asn1.ObjectIdentifier {
+	oid, _ := PQCAlgorithmOID(PQCAlgorithm)
+	return oid
+}
+
+// pqcPrivateKeyPEMType matches the PQC key files written by qlca
+const pqcPrivateKeyPEMType = "PQC PRIVATE KEY"
+
+// pqcPrivateKey is SEQUENCE { algorithm OID, privateKey OCTET STRING, publicKey OCTET STRING }
+type pqcPrivateKey struct {
+	Algorithm  asn1.ObjectIdentifier
+	PrivateKey []byte
+	PublicKey  []byte
+}
+
+// marshalPQCKeyFile encodes the PQC half of a stored key
+func marshalPQCKeyFile(priv, pub []byte) ([]byte, error) {
+	der, err := asn1.Marshal(pqcPrivateKey{Algorithm: pqcOID(), PrivateKey: priv, PublicKey: pub})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pqcPrivateKeyPEMType, Bytes: der}), nil
+}
+
+// parsePQCKeyFile decodes a file written by marshalPQCKeyFile
+func parsePQCKeyFile(raw []byte) (*pqcPrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != pqcPrivateKeyPEMType {
+		return nil, errors.New("no PQC private key PEM block")
+	}
+	return parsePQCKeyDER(block.Bytes)
+}
+
+// parsePQCKeyDER decodes the DER of a PQC half
+func parsePQCKeyDER(der []byte) (*pqcPrivateKey, error) {
+	var pqc pqcPrivateKey
+	if rest, err := asn1.Unmarshal(der, &pqc); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid PQC private key")
+	}
+	if !pqc.Algorithm.Equal(pqcOID()) {
+		return nil, fmt.Errorf("unsupported PQC algorithm %s", pqc.Algorithm)
+	}
+	return &pqc, nil
+}
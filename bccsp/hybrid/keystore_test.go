@@ -0,0 +1,109 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStoreNamespaces(t *testing.T) {
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+
+	org1, err := New(WithKeyStore(ks, "Org1MSP"))
+	require.NoError(t, err)
+	org2, err := New(WithKeyStore(ks, "Org2MSP/mychannel"))
+	require.NoError(t, err)
+
+	k, err := org1.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	eph, err := org1.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+
+	loaded, err := org1.GetKey(k.SKI())
+	require.NoError(t, err)
+	assert.Equal(t, k.SKI(), loaded.SKI())
+
+	digest := sha256.Sum256([]byte("namespaced"))
+	sig, err := org1.Sign(loaded, digest[:], nil)
+	require.NoError(t, err)
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	valid, err := org2.Verify(pub, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Other namespaces cannot see the key, ephemeral keys are not stored
+	_, err = org2.GetKey(k.SKI())
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+	_, err = org1.GetKey(eph.SKI())
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	skis, err := ks.ListKeys("Org1MSP")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{k.SKI()}, skis)
+	skis, err = ks.ListKeys("Org2MSP/mychannel")
+	require.NoError(t, err)
+	assert.Empty(t, skis)
+
+	namespaces, err := ks.Namespaces()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP"}, namespaces)
+}
+
+func TestKeyStoreDeleteKey(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := NewKeyStore(dir)
+	require.NoError(t, err)
+	csp, err := New(WithKeyStore(ks, "Org1MSP"))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	kept, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+
+	require.NoError(t, ks.DeleteKey("Org1MSP", k.SKI()))
+	_, err = ks.GetKey("Org1MSP", k.SKI())
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = os.Stat(filepath.Join(dir, "Org1MSP", hex.EncodeToString(k.SKI())+"_sk"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	skis, err := ks.ListKeys("Org1MSP")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{kept.SKI()}, skis)
+
+	assert.ErrorIs(t, ks.DeleteKey("Org1MSP", k.SKI()), ErrKeyNotFound)
+	assert.ErrorIs(t, ks.DeleteKey("Org1MSP", nil), ErrEmptySKI)
+}
+
+func TestKeyStoreInvalidNamespace(t *testing.T) {
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	for _, ns := range []string{"", "../Org1MSP", "Org1MSP/", "/Org1MSP", "Org1MSP/.hidden"} {
+		_, err := New(WithKeyStore(ks, ns))
+		assert.Error(t, err, ns)
+		_, err = ks.ListKeys(ns)
+		assert.Error(t, err, ns)
+	}
+}
+
+func TestKeyStoreCheck(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	ks, err := NewKeyStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, ks.Check())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the probe file must be removed")
+
+	// A volume unmounted under the daemon
+	require.NoError(t, os.RemoveAll(dir))
+	assert.Error(t, ks.Check())
+}
@@ -0,0 +1,261 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedPQCKeyPEMType is the PEM type of the PQC halves stored by a
+// keystore with a passphrase
+const encryptedPQCKeyPEMType = "ENCRYPTED PQC PRIVATE KEY"
+
+// ErrKeyStorePassphrase is returned for stored keys that the passphrase of
+// the keystore does not decrypt, or that are encrypted while the keystore
+// has no passphrase
+var ErrKeyStorePassphrase = errors.New("wrong keystore passphrase or corrupted key")
+
+// scrypt parameters of the PQC halves, those of identity bundles
+const (
+	keystoreScryptN = 1 << 15
+	keystoreScryptR = 8
+	keystoreScryptP = 1
+)
+
+// keystoreScryptMaxN bounds the cost read from key files, 128 MiB of
+// memory with r = 8, so that a planted file cannot exhaust the process
+const keystoreScryptMaxN = 1 << 17
+
+// encryptedPQCKey is
+//
+//	SEQUENCE {
+//	  kdf         SEQUENCE { salt OCTET STRING, n, r, p INTEGER }  -- scrypt
+//	  nonce       OCTET STRING
+//	  ciphertext  OCTET STRING  -- AES-256-GCM of the pqcPrivateKey DER
+//	}
+//
+// The SKI of the key is the additional data of the encryption, so halves
+// cannot be swapped between keys. Stored LMS keys are encrypted the same
+// way.
+type encryptedPQCKey struct {
+	KDF        keystoreKDF
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+type keystoreKDF struct {
+	Salt    []byte
+	N, R, P int
+}
+
+// validate checks parameters read from a key file: no weaker than those
+// the keystore writes, and bounded in memory and time
+func (k keystoreKDF) validate() error {
+	switch {
+	case len(k.Salt) < 16 || len(k.Salt) > 64:
+		return fmt.Errorf("invalid scrypt salt length %d", len(k.Salt))
+	case k.N < keystoreScryptN || k.N > keystoreScryptMaxN || k.N&(k.N-1) != 0:
+		return fmt.Errorf("unexpected scrypt cost N=%d", k.N)
+	case k.R != keystoreScryptR || k.P != keystoreScryptP:
+		return fmt.Errorf("unexpected scrypt parameters r=%d p=%d", k.R, k.P)
+	}
+	return nil
+}
+
+// keystoreCipher holds the passphrase of a KeyStore and the keys derived
+// from it, by KDF parameters: a keystore writes every half with the same
+// salt, so scrypt runs once per process rather than once per GetKey
+type keystoreCipher struct {
+	passphrase []byte
+	kdf        keystoreKDF
+
+	mutex sync.Mutex
+	aeads map[string]cipher.AEAD
+}
+
+// newKeystoreCipher returns the cipher of a non-empty passphrase, with a
+// fresh salt
+func newKeystoreCipher(passphrase []byte) (*keystoreCipher, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &keystoreCipher{
+		passphrase: bytes.Clone(passphrase),
+		kdf:        keystoreKDF{Salt: salt, N: keystoreScryptN, R: keystoreScryptR, P: keystoreScryptP},
+		aeads:      map[string]cipher.AEAD{},
+	}, nil
+}
+
+// SetPassphrase protects the keys stored from now on with passphrase, e.g.
+// read with secret.Read from the kernel keyring or a systemd credential:
+// the ECDSA half with the PEM encryption of the Fabric SW keystore, the PQC
+// half with AES-256-GCM under a scrypt key. Keys stored without a
+// passphrase stay readable, and can be encrypted by importing them again.
+// An empty passphrase stores keys in the clear. Call it before the
+// keystore is used.
+func (s *KeyStore) SetPassphrase(passphrase []byte) error {
+	var c *keystoreCipher
+	if len(passphrase) > 0 {
+		var err error
+		if c, err = newKeystoreCipher(passphrase); err != nil {
+			return err
+		}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cipher = c
+	// The SW keystores of the namespaces hold the previous passphrase
+	s.stores = map[string]bccsp.KeyStore{}
+	return nil
+}
+
+// keyCipher returns the cipher of the keystore, nil without a passphrase
+func (s *KeyStore) keyCipher() *keystoreCipher {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cipher
+}
+
+// secret returns the passphrase, nil for keystores without one
+func (c *keystoreCipher) secret() []byte {
+	if c == nil {
+		return nil
+	}
+	return c.passphrase
+}
+
+// aead derives the AES-256-GCM key of kdf from the passphrase
+func (c *keystoreCipher) aead(kdf keystoreKDF) (cipher.AEAD, error) {
+	id := fmt.Sprintf("%x/%d/%d/%d", kdf.Salt, kdf.N, kdf.R, kdf.P)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if aead, ok := c.aeads[id]; ok {
+		return aead, nil
+	}
+	key, err := scrypt.Key(c.passphrase, kdf.Salt, kdf.N, kdf.R, kdf.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PQC key encryption: %w", err)
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	c.aeads[id] = aead
+	return aead, nil
+}
+
+// sealPQCKeyFile encodes the PQC half of the key ski, encrypted if the
+// keystore has a passphrase
+func (s *KeyStore) sealPQCKeyFile(ski, priv, pub []byte) ([]byte, error) {
+	c := s.keyCipher()
+	if c == nil {
+		return marshalPQCKeyFile(priv, pub)
+	}
+	return c.seal(ski, priv, pub)
+}
+
+// openPQCKeyFile decodes the PQC half of the key ski, decrypting it if the
+// keystore encrypted it
+func (s *KeyStore) openPQCKeyFile(ski, raw []byte) (*pqcPrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != encryptedPQCKeyPEMType {
+		return parsePQCKeyFile(raw)
+	}
+	c := s.keyCipher()
+	if c == nil {
+		return nil, fmt.Errorf("%w: the PQC half is encrypted and the keystore has no passphrase", ErrKeyStorePassphrase)
+	}
+	return c.open(ski, block)
+}
+
+// seal encrypts the PQC half of the key ski into a PEM file
+func (c *keystoreCipher) seal(ski, priv, pub []byte) ([]byte, error) {
+	der, err := asn1.Marshal(pqcPrivateKey{Algorithm: pqcOID(), PrivateKey: priv, PublicKey: pub})
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+	return c.sealDER(ski, der, encryptedPQCKeyPEMType)
+}
+
+// sealDER encrypts the DER of the key ski into a PEM file of type pemType
+func (c *keystoreCipher) sealDER(ski, der []byte, pemType string) ([]byte, error) {
+	aead, err := c.aead(c.kdf)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	enc, err := asn1.Marshal(encryptedPQCKey{KDF: c.kdf, Nonce: nonce, Ciphertext: aead.Seal(nil, nonce, der, ski)})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: enc}), nil
+}
+
+// open decrypts the PQC half of the key ski sealed in block
+func (c *keystoreCipher) open(ski []byte, block *pem.Block) (*pqcPrivateKey, error) {
+	der, err := c.openDER(ski, block)
+	if err != nil {
+		return nil, err
+	}
+	return parsePQCKeyDER(der)
+}
+
+// openDER decrypts the DER of the key ski sealed in block by sealDER
+func (c *keystoreCipher) openDER(ski []byte, block *pem.Block) ([]byte, error) {
+	var enc encryptedPQCKey
+	if rest, err := asn1.Unmarshal(block.Bytes, &enc); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid encrypted private key")
+	}
+	if err := enc.KDF.validate(); err != nil {
+		return nil, fmt.Errorf("invalid encrypted private key: %w", err)
+	}
+	aead, err := c.aead(enc.KDF)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid encrypted private key nonce")
+	}
+	der, err := aead.Open(nil, enc.Nonce, enc.Ciphertext, ski)
+	if err != nil {
+		return nil, ErrKeyStorePassphrase
+	}
+	return der, nil
+}
+
+// decryptKeyPEM returns the DER of a key file of the Fabric SW keystore,
+// decrypting the legacy PEM encryption it uses with a password
+func decryptKeyPEM(block *pem.Block, passphrase []byte) ([]byte, error) {
+	// Deprecated and unauthenticated, but what the Fabric SW keystore writes
+	if !x509.IsEncryptedPEMBlock(block) {
+		return block.Bytes, nil
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("%w: the key is encrypted and the keystore has no passphrase", ErrKeyStorePassphrase)
+	}
+	der, err := x509.DecryptPEMBlock(block, passphrase)
+	if err != nil {
+		return nil, ErrKeyStorePassphrase
+	}
+	return der, nil
+}
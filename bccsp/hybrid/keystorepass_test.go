@@ -0,0 +1,114 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStorePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := NewKeyStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, ks.SetPassphrase([]byte("correct horse")))
+	csp, err := New(WithKeyStore(ks, "Org1MSP"))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	pqcFile := func(k bccsp.Key) string {
+		return filepath.Join(dir, "Org1MSP", hex.EncodeToString(k.SKI())+pqcKeySuffix)
+	}
+	raw, err := os.ReadFile(pqcFile(k))
+	require.NoError(t, err)
+	block, _ := pem.Decode(raw)
+	require.NotNil(t, block)
+	assert.Equal(t, encryptedPQCKeyPEMType, block.Type)
+
+	loaded, err := csp.GetKey(k.SKI())
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("passphrase"))
+	_, err = csp.Sign(loaded, digest[:], nil)
+	require.NoError(t, err)
+
+	// A keystore without the passphrase, or with another, cannot load the
+	// encrypted key
+	for _, passphrase := range []string{"", "wrong"} {
+		other, err := NewKeyStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, other.SetPassphrase([]byte(passphrase)))
+		_, err = other.GetKey("Org1MSP", k.SKI())
+		assert.True(t, errors.Is(err, ErrKeyStorePassphrase), err)
+	}
+
+	// Halves cannot be swapped between keys
+	k2, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	require.NoError(t, err)
+	raw, err = os.ReadFile(pqcFile(k2))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(pqcFile(k), raw, 0o600))
+	_, err = ks.GetKey("Org1MSP", k.SKI())
+	assert.True(t, errors.Is(err, ErrKeyStorePassphrase), err)
+
+	// scrypt parameters of the file are bounded before any derivation
+	var enc encryptedPQCKey
+	_, err = asn1.Unmarshal(block.Bytes, &enc)
+	require.NoError(t, err)
+	for name, kdf := range map[string]keystoreKDF{
+		"huge N":   {Salt: enc.KDF.Salt, N: 1 << 30, R: 8, P: 1},
+		"weak N":   {Salt: enc.KDF.Salt, N: 1 << 10, R: 8, P: 1},
+		"odd N":    {Salt: enc.KDF.Salt, N: 1<<15 + 1, R: 8, P: 1},
+		"huge r":   {Salt: enc.KDF.Salt, N: 1 << 15, R: 1 << 20, P: 1},
+		"huge p":   {Salt: enc.KDF.Salt, N: 1 << 15, R: 8, P: 1 << 20},
+		"no salt":  {N: 1 << 15, R: 8, P: 1},
+		"negative": {Salt: enc.KDF.Salt, N: -1, R: 8, P: 1},
+	} {
+		forged := enc
+		forged.KDF = kdf
+		der, err := asn1.Marshal(forged)
+		require.NoError(t, err)
+		_, err = ks.openPQCKeyFile(k.SKI(), pem.EncodeToMemory(&pem.Block{Type: encryptedPQCKeyPEMType, Bytes: der}))
+		assert.ErrorContains(t, err, "scrypt", name)
+	}
+
+	// PQC halves stored in the clear stay readable
+	signer, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer signer.Clean()
+	plain, err := marshalPQCKeyFile(signer.PrivateKey(), signer.PublicKey())
+	require.NoError(t, err)
+	pqc, err := ks.openPQCKeyFile(k.SKI(), plain)
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey(), pqc.PublicKey)
+}
+
+func TestParseEncryptedECDSAKeyFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	// As the Fabric SW keystore writes keys with a password
+	block, err := x509.EncryptPEMBlock(rand.Reader, "PRIVATE KEY", der, []byte("correct horse"), x509.PEMCipherAES256)
+	require.NoError(t, err)
+	raw := pem.EncodeToMemory(block)
+
+	parsed, err := parseECDSAKeyFile(raw, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+	_, err = parseECDSAKeyFile(raw, nil)
+	assert.ErrorIs(t, err, ErrKeyStorePassphrase)
+	_, err = parseECDSAKeyFile(raw, []byte("wrong"))
+	assert.Error(t, err)
+}
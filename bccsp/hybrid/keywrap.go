@@ -0,0 +1,55 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// keyWrapKDFInfo separates key-wrapping KEKs from Encrypt keys
+var keyWrapKDFInfo = []byte("aes-kw")
+
+// WrapKey wraps an AES key under the recipient's hybrid KEM public key.
+// The result is: [KEM header][AES-KW(KEK, key)]
+func (h *HybridBCCSP) WrapKey(recipient bccsp.Key, key []byte) (_ []byte, err error) {
+	defer func() { err = wrapKeyError(OpWrapKey, recipient, err) }()
+	if isNilKey(recipient) {
+		return nil, ErrNilKey
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("invalid AES key length %d", len(key))
+	}
+	pub, err := kemRecipient(recipient, nil)
+	if err != nil {
+		return nil, err
+	}
+	header, kek, err := kemEncapsulate(pub, keyWrapKDFInfo)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := aesKeyWrap(kek, key)
+	if err != nil {
+		return nil, fmt.Errorf("key wrap failed: %w", err)
+	}
+	return append(header, wrapped...), nil
+}
+
+// UnwrapKey recovers an AES key wrapped by WrapKey using the recipient's private KEM key.
+// The returned bytes can be imported with bccsp.AES256ImportKeyOpts.
+func (h *HybridBCCSP) UnwrapKey(k bccsp.Key, wrapped []byte) (_ []byte, err error) {
+	defer func() { err = wrapKeyError(OpUnwrapKey, k, err) }()
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	kek, rest, err := kemDecapsulate(k, wrapped, keyWrapKDFInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) == 0 {
+		return nil, errors.New("wrapped key is empty")
+	}
+	return aesKeyUnwrap(kek, rest)
+}
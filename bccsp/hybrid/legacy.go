@@ -0,0 +1,103 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// ErrClassicalKey is returned by Verify for a plain ECDSA key when legacy
+// verification is disabled, or when the policy requires the PQC component
+var ErrClassicalKey = errors.New("classical key not accepted")
+
+// KeyKind labels verification keys, and the results Verify reports for them
+type KeyKind int
+
+const (
+	KindUnknown KeyKind = iota
+	// KindHybrid is a composite ECDSA + ML-DSA key
+	KindHybrid
+	// KindClassical is a plain ECDSA key of a legacy identity
+	KindClassical
+	// KindLMS is a stateful hash-based key
+	KindLMS
+)
+
+var kindNames = map[KeyKind]string{
+	KindUnknown:   "unknown",
+	KindHybrid:    "hybrid",
+	KindClassical: "classical",
+	KindLMS:       "lms",
+}
+
+func (k KeyKind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("KeyKind(%d)", int(k))
+}
+
+// KindOf returns the kind of k. Plain ECDSA keys are the ones of the SW
+// provider, e.g. imported with bccsp.X509PublicKeyImportOpts.
+func KindOf(k bccsp.Key) KeyKind {
+	if isNilKey(k) {
+		return KindUnknown
+	}
+	switch k.(type) {
+	case *hybridKey:
+		return KindHybrid
+	case *lmsKey:
+		return KindLMS
+	}
+	if k.Symmetric() {
+		return KindUnknown
+	}
+	if k.Private() {
+		pub, err := k.PublicKey()
+		if err != nil {
+			return KindUnknown
+		}
+		k = pub
+	}
+	raw, err := k.Bytes()
+	if err != nil {
+		return KindUnknown
+	}
+	if pub, err := x509.ParsePKIXPublicKey(raw); err == nil {
+		if _, ok := pub.(*ecdsa.PublicKey); ok {
+			return KindClassical
+		}
+	}
+	return KindUnknown
+}
+
+// WithLegacyECDSA makes Verify accept plain ECDSA keys and their ASN.1
+// signatures, checked by the SW provider, for organizations that still sign
+// classically during the migration. They only verify under policies that
+// accept the classical component alone: OR and CLASSICAL, e.g. as MSP
+// overrides in ChannelPolicies.
+func WithLegacyECDSA(enabled bool) Option {
+	return func(h *HybridBCCSP) {
+		h.legacyECDSA = enabled
+	}
+}
+
+// verifyClassical verifies the plain ECDSA signature of a legacy key
+func (h *HybridBCCSP) verifyClassical(prof *profiler, k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	if !h.legacyECDSA {
+		return false, fmt.Errorf("%w: legacy ECDSA verification is disabled", ErrClassicalKey)
+	}
+	switch policy := h.resolvePolicy(opts); policy {
+	case PolicyHybridOR, PolicyClassical:
+	default:
+		return false, fmt.Errorf("%w: policy %s requires the PQC component", ErrClassicalKey, policy)
+	}
+	prof.do("verify", AlgorithmECDSA, func() { valid, err = h.sw.Verify(k, signature, digest, nil) })
+	if err != nil {
+		return false, fmt.Errorf("ECDSA verification failed: %w", err)
+	}
+	return valid, nil
+}
@@ -0,0 +1,70 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyECDSA(t *testing.T) {
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider := &metricsfakes.Provider{}
+	provider.NewCounterStub = func(o metrics.CounterOpts) metrics.Counter {
+		if o.Name == verificationsOpts.Name {
+			return counter
+		}
+		return &metricsfakes.Counter{}
+	}
+	provider.NewGaugeReturns(&metricsfakes.Gauge{})
+
+	policies := &ChannelPolicies{
+		Default:  PolicyHybridAND,
+		Channels: map[string]ChannelPolicy{"mychannel": {Default: PolicyHybridAND, MSPs: map[string]Policy{"LegacyMSP": PolicyClassical}}},
+	}
+	csp, err := New(WithLegacyECDSA(true), WithPolicyResolver(policies), WithMetricsProvider(provider))
+	require.NoError(t, err)
+
+	// A classical identity signs with a plain ECDSA key of the SW provider
+	priv, err := csp.(*HybridBCCSP).sw.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("legacy transaction"))
+	sig, err := csp.(*HybridBCCSP).sw.Sign(priv, digest[:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, KindClassical, KindOf(pub))
+	assert.Equal(t, KindClassical, KindOf(priv))
+
+	legacy := &HybridSignerOpts{Channel: "mychannel", MSPID: "LegacyMSP"}
+	valid, err := csp.Verify(pub, sig, digest[:], legacy)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []string{"kind", "classical", "valid", "true"}, counter.WithArgsForCall(0))
+
+	// MSPs under the hybrid policy cannot fall back to ECDSA
+	_, err = csp.Verify(pub, sig, digest[:], &HybridSignerOpts{Channel: "mychannel", MSPID: "Org1MSP"})
+	assert.ErrorIs(t, err, ErrClassicalKey)
+	assert.Equal(t, []string{"kind", "classical", "valid", "false"}, counter.WithArgsForCall(1))
+
+	hk, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	assert.Equal(t, KindHybrid, KindOf(hk))
+	hsig, err := csp.Sign(hk, digest[:], nil)
+	require.NoError(t, err)
+	valid, err = csp.Verify(hk, hsig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []string{"kind", "hybrid", "valid", "true"}, counter.WithArgsForCall(2))
+
+	// Disabled by default
+	strict, err := New(WithPolicy(PolicyClassical))
+	require.NoError(t, err)
+	_, err = strict.Verify(pub, sig, digest[:], nil)
+	assert.ErrorIs(t, err, ErrClassicalKey)
+}
@@ -0,0 +1,258 @@
+package lms
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StateStore persists the number of one-time keys consumed per key. The
+// hybrid provider's usage stores implement it.
+type StateStore interface {
+	// Reserve durably increments the counter of id and returns the new
+	// value, failing once max would be exceeded
+	Reserve(id []byte, max uint64) (uint64, error)
+}
+
+// ErrStateRollback is returned when the state store hands out an index that
+// was already used by this key, e.g. after restoring an old backup
+var ErrStateRollback = errors.New("LMS state went backwards, refusing to reuse a one-time key")
+
+// PublicKey is an LMS public key
+type PublicKey struct {
+	Type    Type
+	OTSType OTSType
+	ID      [16]byte
+	Root    [n]byte
+}
+
+// PrivateKey is an LMS private key together with its Merkle tree
+type PrivateKey struct {
+	PublicKey
+
+	seed  [n]byte
+	tree  [][n]byte // tree[r] is node r, 1 is the root
+	store StateStore
+
+	mutex sync.Mutex
+	next  uint64 // lowest index not yet used by this instance
+}
+
+// GenerateKey creates a key with the given parameter sets. Signing state is
+// kept in store.
+func GenerateKey(rand io.Reader, t Type, ots OTSType, store StateStore) (*PrivateKey, error) {
+	var id [16]byte
+	var seed [n]byte
+	if _, err := io.ReadFull(rand, id[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand, seed[:]); err != nil {
+		return nil, err
+	}
+	return newPrivateKey(t, ots, id, seed, store)
+}
+
+func newPrivateKey(t Type, ots OTSType, id [16]byte, seed [n]byte, store StateStore) (*PrivateKey, error) {
+	if store == nil {
+		return nil, errors.New("LMS keys require a state store")
+	}
+	h, err := t.Height()
+	if err != nil {
+		return nil, err
+	}
+	if h > maxKeyGenHeight {
+		return nil, fmt.Errorf("LMS tree height %d is too large to build in memory", h)
+	}
+	p, err := ots.params()
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := 1 << h
+	tree := make([][n]byte, 2*leaves)
+	for q := 0; q < leaves; q++ {
+		k := otsPublic(p, id[:], uint32(q), seed[:])
+		tree[leaves+q] = leafHash(id[:], uint32(leaves+q), k[:])
+	}
+	for r := leaves - 1; r >= 1; r-- {
+		tree[r] = interiorHash(id[:], uint32(r), tree[2*r][:], tree[2*r+1][:])
+	}
+
+	return &PrivateKey{
+		PublicKey: PublicKey{Type: t, OTSType: ots, ID: id, Root: tree[1]},
+		seed:      seed,
+		tree:      tree,
+		store:     store,
+	}, nil
+}
+
+func leafHash(id []byte, r uint32, k []byte) [n]byte {
+	buf := make([]byte, 0, 16+4+2+n)
+	buf = append(buf, id...)
+	buf = binary.BigEndian.AppendUint32(buf, r)
+	buf = binary.BigEndian.AppendUint16(buf, dLEAF)
+	return sha256.Sum256(append(buf, k...))
+}
+
+func interiorHash(id []byte, r uint32, left, right []byte) [n]byte {
+	buf := make([]byte, 0, 16+4+2+2*n)
+	buf = append(buf, id...)
+	buf = binary.BigEndian.AppendUint32(buf, r)
+	buf = binary.BigEndian.AppendUint16(buf, dINTR)
+	buf = append(buf, left...)
+	return sha256.Sum256(append(buf, right...))
+}
+
+// Sign reserves the next one-time key in the state store and signs msg with
+// it (RFC 8554 section 5.4.1). rand provides the LM-OTS randomizer C.
+func (k *PrivateKey) Sign(rand io.Reader, msg []byte) ([]byte, error) {
+	h, _ := k.Type.Height()
+	p, _ := k.OTSType.params()
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	count, err := k.store.Reserve(k.PublicKey.SKI(), uint64(1)<<h)
+	if err != nil {
+		return nil, err
+	}
+	idx := count - 1
+	if idx < k.next {
+		return nil, ErrStateRollback
+	}
+	k.next = idx + 1
+	q := uint32(idx)
+
+	c := make([]byte, n)
+	if _, err := io.ReadFull(rand, c); err != nil {
+		return nil, err
+	}
+
+	sig := make([]byte, 0, 4+p.sigLen()+4+h*n)
+	sig = append(sig, u32str(q)...)
+	sig = append(sig, otsSign(k.OTSType, p, k.ID[:], q, k.seed[:], c, msg)...)
+	sig = append(sig, u32str(uint32(k.Type))...)
+	for node, i := (1<<h)+int(q), 0; i < h; node, i = node>>1, i+1 {
+		sig = append(sig, k.tree[node^1][:]...)
+	}
+	return sig, nil
+}
+
+// Remaining returns how many signatures this instance can still produce
+// according to its own view of the state
+func (k *PrivateKey) Remaining() uint64 {
+	h, _ := k.Type.Height()
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return uint64(1)<<h - k.next
+}
+
+// Verify checks an LMS signature (RFC 8554 Algorithm 6a)
+func (pub *PublicKey) Verify(msg, sig []byte) bool {
+	return pub.verify(msg, sig) == nil
+}
+
+func (pub *PublicKey) verify(msg, sig []byte) error {
+	h, err := pub.Type.Height()
+	if err != nil {
+		return err
+	}
+	p, err := pub.OTSType.params()
+	if err != nil {
+		return err
+	}
+	if len(sig) != 4+p.sigLen()+4+h*n {
+		return fmt.Errorf("invalid LMS signature length %d", len(sig))
+	}
+	q := binary.BigEndian.Uint32(sig)
+	if uint64(q) >= uint64(1)<<h {
+		return fmt.Errorf("LMS leaf index %d out of range", q)
+	}
+	otsSig := sig[4 : 4+p.sigLen()]
+	rest := sig[4+p.sigLen():]
+	if Type(binary.BigEndian.Uint32(rest)) != pub.Type {
+		return fmt.Errorf("LMS type does not match public key")
+	}
+	path := rest[4:]
+
+	kc, err := otsCandidate(pub.OTSType, pub.ID[:], q, otsSig, msg)
+	if err != nil {
+		return err
+	}
+	node := uint32(1<<h) + q
+	tmp := leafHash(pub.ID[:], node, kc[:])
+	for i := 0; node > 1; i, node = i+1, node>>1 {
+		sibling := path[i*n : (i+1)*n]
+		if node&1 == 1 {
+			tmp = interiorHash(pub.ID[:], node>>1, sibling, tmp[:])
+		} else {
+			tmp = interiorHash(pub.ID[:], node>>1, tmp[:], sibling)
+		}
+	}
+	if subtle.ConstantTimeCompare(tmp[:], pub.Root[:]) != 1 {
+		return errors.New("LMS signature does not match public key")
+	}
+	return nil
+}
+
+// Bytes encodes the public key as u32str(type) || u32str(otstype) || I || T[1]
+func (pub *PublicKey) Bytes() []byte {
+	out := make([]byte, 0, 8+16+n)
+	out = append(out, u32str(uint32(pub.Type))...)
+	out = append(out, u32str(uint32(pub.OTSType))...)
+	out = append(out, pub.ID[:]...)
+	return append(out, pub.Root[:]...)
+}
+
+// SKI is the SHA-256 hash of the encoded public key. It also keys the
+// signing state in the StateStore.
+func (pub *PublicKey) SKI() []byte {
+	sum := sha256.Sum256(pub.Bytes())
+	return sum[:]
+}
+
+// ParsePublicKey decodes a public key produced by Bytes
+func ParsePublicKey(raw []byte) (*PublicKey, error) {
+	if len(raw) != 8+16+n {
+		return nil, fmt.Errorf("invalid LMS public key length %d", len(raw))
+	}
+	pub := &PublicKey{
+		Type:    Type(binary.BigEndian.Uint32(raw)),
+		OTSType: OTSType(binary.BigEndian.Uint32(raw[4:])),
+	}
+	if _, err := pub.Type.Height(); err != nil {
+		return nil, err
+	}
+	if _, err := pub.OTSType.params(); err != nil {
+		return nil, err
+	}
+	copy(pub.ID[:], raw[8:24])
+	copy(pub.Root[:], raw[24:])
+	return pub, nil
+}
+
+// MarshalPrivateKey encodes the key as u32str(type) || u32str(otstype) || I || SEED.
+// The signing state is not part of the encoding; it lives in the StateStore.
+func MarshalPrivateKey(k *PrivateKey) []byte {
+	out := make([]byte, 0, 8+16+n)
+	out = append(out, u32str(uint32(k.Type))...)
+	out = append(out, u32str(uint32(k.OTSType))...)
+	out = append(out, k.ID[:]...)
+	return append(out, k.seed[:]...)
+}
+
+// ParsePrivateKey decodes a key produced by MarshalPrivateKey and rebuilds its tree
+func ParsePrivateKey(raw []byte, store StateStore) (*PrivateKey, error) {
+	if len(raw) != 8+16+n {
+		return nil, fmt.Errorf("invalid LMS private key length %d", len(raw))
+	}
+	var id [16]byte
+	var seed [n]byte
+	copy(id[:], raw[8:24])
+	copy(seed[:], raw[24:])
+	return newPrivateKey(Type(binary.BigEndian.Uint32(raw)), OTSType(binary.BigEndian.Uint32(raw[4:])), id, seed, store)
+}
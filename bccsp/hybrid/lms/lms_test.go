@@ -0,0 +1,82 @@
+package lms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memStore struct {
+	counts map[string]uint64
+}
+
+func (s *memStore) Reserve(id []byte, max uint64) (uint64, error) {
+	k := hex.EncodeToString(id)
+	if s.counts[k] >= max {
+		return 0, errors.New("exhausted")
+	}
+	s.counts[k]++
+	return s.counts[k], nil
+}
+
+func TestCoef(t *testing.T) {
+	// Example from RFC 8554 section 3.1.3
+	s := []byte{0x12, 0x34}
+	assert.Equal(t, uint(0), coef(s, 7, 1))
+	assert.Equal(t, uint(1), coef(s, 0, 4))
+	assert.Equal(t, uint(2), coef(s, 1, 4))
+	assert.Equal(t, uint(3), coef(s, 5, 2))
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, ots := range []OTSType{LMOTS_SHA256_N32_W1, LMOTS_SHA256_N32_W2, LMOTS_SHA256_N32_W4, LMOTS_SHA256_N32_W8} {
+		store := &memStore{counts: map[string]uint64{}}
+		k, err := GenerateKey(rand.Reader, LMS_SHA256_M32_H5, ots, store)
+		require.NoError(t, err)
+
+		msg := []byte("root of trust")
+		sig, err := k.Sign(rand.Reader, msg)
+		require.NoError(t, err)
+
+		pub, err := ParsePublicKey(k.PublicKey.Bytes())
+		require.NoError(t, err)
+		assert.True(t, pub.Verify(msg, sig))
+		assert.False(t, pub.Verify([]byte("other"), sig))
+
+		sig[len(sig)-1] ^= 0x01
+		assert.False(t, pub.Verify(msg, sig))
+	}
+}
+
+func TestStateExhaustionAndRestore(t *testing.T) {
+	store := &memStore{counts: map[string]uint64{}}
+	k, err := GenerateKey(rand.Reader, LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8, store)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := k.Sign(rand.Reader, []byte{byte(i)})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(29), k.Remaining())
+
+	// A reloaded key continues from the persisted state
+	k2, err := ParsePrivateKey(MarshalPrivateKey(k), store)
+	require.NoError(t, err)
+	assert.Equal(t, k.PublicKey, k2.PublicKey)
+	sig, err := k2.Sign(rand.Reader, []byte("next"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 3}, sig[:4])
+
+	// Rolling the state back is detected
+	store.counts[hex.EncodeToString(k2.SKI())] = 1
+	_, err = k2.Sign(rand.Reader, []byte("again"))
+	require.ErrorIs(t, err, ErrStateRollback)
+
+	store.counts[hex.EncodeToString(k2.SKI())] = 32
+	_, err = k2.Sign(rand.Reader, []byte("exhausted"))
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package lms
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// chainHasher computes H(I || u32str(q) || u16str(i) || u8str(j) || tmp)
+type chainHasher struct {
+	prefix [16 + 4 + 2 + 1]byte
+	buf    []byte
+}
+
+func newChainHasher(id []byte, q uint32) *chainHasher {
+	c := &chainHasher{}
+	copy(c.prefix[:16], id)
+	binary.BigEndian.PutUint32(c.prefix[16:20], q)
+	c.buf = make([]byte, 0, len(c.prefix)+n)
+	return c
+}
+
+func (c *chainHasher) hash(i uint16, j uint8, tmp []byte) [n]byte {
+	binary.BigEndian.PutUint16(c.prefix[20:22], i)
+	c.prefix[22] = j
+	c.buf = append(append(c.buf[:0], c.prefix[:]...), tmp...)
+	return sha256.Sum256(c.buf)
+}
+
+// otsPrivate derives x_q[i] from the seed (RFC 8554 Appendix A)
+func otsPrivate(id []byte, q uint32, i uint16, seed []byte) [n]byte {
+	buf := make([]byte, 0, 16+4+2+1+len(seed))
+	buf = append(buf, id...)
+	buf = binary.BigEndian.AppendUint32(buf, q)
+	buf = binary.BigEndian.AppendUint16(buf, i)
+	buf = append(buf, 0xff)
+	buf = append(buf, seed...)
+	return sha256.Sum256(buf)
+}
+
+// otsPublic computes the LM-OTS public key K of leaf q
+func otsPublic(p otsParams, id []byte, q uint32, seed []byte) [n]byte {
+	c := newChainHasher(id, q)
+	h := sha256.New()
+	h.Write(id)
+	h.Write(u32str(q))
+	h.Write([]byte{dPBLC >> 8, dPBLC & 0xff})
+	for i := 0; i < p.p; i++ {
+		tmp := otsPrivate(id, q, uint16(i), seed)
+		for j := 0; j < 1<<p.w-1; j++ {
+			tmp = c.hash(uint16(i), uint8(j), tmp[:])
+		}
+		h.Write(tmp[:])
+	}
+	var k [n]byte
+	copy(k[:], h.Sum(nil))
+	return k
+}
+
+// messageDigest computes Q || Cksm(Q)
+func messageDigest(p otsParams, id []byte, q uint32, c, msg []byte) []byte {
+	h := sha256.New()
+	h.Write(id)
+	h.Write(u32str(q))
+	h.Write([]byte{dMESG >> 8, dMESG & 0xff})
+	h.Write(c)
+	h.Write(msg)
+	sum := h.Sum(nil)
+	return append(sum, p.checksum(sum)...)
+}
+
+// otsSign produces an LM-OTS signature of msg with randomizer c
+func otsSign(t OTSType, p otsParams, id []byte, q uint32, seed, c, msg []byte) []byte {
+	digest := messageDigest(p, id, q, c, msg)
+	ch := newChainHasher(id, q)
+	sig := make([]byte, 0, p.sigLen())
+	sig = append(sig, u32str(uint32(t))...)
+	sig = append(sig, c...)
+	for i := 0; i < p.p; i++ {
+		tmp := otsPrivate(id, q, uint16(i), seed)
+		a := int(coef(digest, i, p.w))
+		for j := 0; j < a; j++ {
+			tmp = ch.hash(uint16(i), uint8(j), tmp[:])
+		}
+		sig = append(sig, tmp[:]...)
+	}
+	return sig
+}
+
+// otsCandidate computes the candidate public key Kc from an LM-OTS signature
+// (RFC 8554 Algorithm 4b)
+func otsCandidate(expected OTSType, id []byte, q uint32, sig, msg []byte) ([n]byte, error) {
+	var kc [n]byte
+	if len(sig) < 4 {
+		return kc, fmt.Errorf("LM-OTS signature too short")
+	}
+	t := OTSType(binary.BigEndian.Uint32(sig))
+	if t != expected {
+		return kc, fmt.Errorf("LM-OTS type %d does not match public key type %d", t, expected)
+	}
+	p, err := t.params()
+	if err != nil {
+		return kc, err
+	}
+	if len(sig) != p.sigLen() {
+		return kc, fmt.Errorf("invalid LM-OTS signature length %d", len(sig))
+	}
+	c, y := sig[4:4+n], sig[4+n:]
+
+	digest := messageDigest(p, id, q, c, msg)
+	ch := newChainHasher(id, q)
+	h := sha256.New()
+	h.Write(id)
+	h.Write(u32str(q))
+	h.Write([]byte{dPBLC >> 8, dPBLC & 0xff})
+	for i := 0; i < p.p; i++ {
+		var tmp [n]byte
+		copy(tmp[:], y[i*n:(i+1)*n])
+		for j := int(coef(digest, i, p.w)); j < 1<<p.w-1; j++ {
+			tmp = ch.hash(uint16(i), uint8(j), tmp[:])
+		}
+		h.Write(tmp[:])
+	}
+	copy(kc[:], h.Sum(nil))
+	return kc, nil
+}
@@ -0,0 +1,122 @@
+// Package lms implements the Leighton-Micali hash-based signature scheme
+// (RFC 8554, NIST SP 800-208) with SHA-256/32 parameter sets.
+//
+// LMS is stateful: every signature consumes a one-time key. The index of the
+// next one-time key is reserved in a StateStore before the signature is
+// produced, so a crash can waste an index but never reuse one.
+package lms
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// OTSType is an LM-OTS parameter set (RFC 8554 section 4.1)
+type OTSType uint32
+
+const (
+	LMOTS_SHA256_N32_W1 OTSType = 1
+	LMOTS_SHA256_N32_W2 OTSType = 2
+	LMOTS_SHA256_N32_W4 OTSType = 3
+	LMOTS_SHA256_N32_W8 OTSType = 4
+)
+
+// Type is an LMS parameter set (RFC 8554 section 5.1)
+type Type uint32
+
+const (
+	LMS_SHA256_M32_H5  Type = 5
+	LMS_SHA256_M32_H10 Type = 6
+	LMS_SHA256_M32_H15 Type = 7
+	LMS_SHA256_M32_H20 Type = 8
+	LMS_SHA256_M32_H25 Type = 9
+)
+
+// n is the hash output length of every supported parameter set
+const n = 32
+
+// Domain separation values (RFC 8554 section 3.1.2 / 7.1)
+const (
+	dPBLC = 0x8080
+	dMESG = 0x8181
+	dLEAF = 0x8282
+	dINTR = 0x8383
+)
+
+type otsParams struct {
+	w  uint // Winternitz parameter
+	p  int  // number of hash chains
+	ls uint // checksum left shift
+}
+
+var otsTable = map[OTSType]otsParams{
+	LMOTS_SHA256_N32_W1: {w: 1, p: 265, ls: 7},
+	LMOTS_SHA256_N32_W2: {w: 2, p: 133, ls: 6},
+	LMOTS_SHA256_N32_W4: {w: 4, p: 67, ls: 4},
+	LMOTS_SHA256_N32_W8: {w: 8, p: 34, ls: 0},
+}
+
+var heights = map[Type]int{
+	LMS_SHA256_M32_H5:  5,
+	LMS_SHA256_M32_H10: 10,
+	LMS_SHA256_M32_H15: 15,
+	LMS_SHA256_M32_H20: 20,
+	LMS_SHA256_M32_H25: 25,
+}
+
+// maxKeyGenHeight bounds the trees built in memory by GenerateKey/ParsePrivateKey
+const maxKeyGenHeight = 15
+
+func (t OTSType) params() (otsParams, error) {
+	p, ok := otsTable[t]
+	if !ok {
+		return otsParams{}, fmt.Errorf("unsupported LM-OTS type %d", t)
+	}
+	return p, nil
+}
+
+// Height returns the height of the Merkle tree
+func (t Type) Height() (int, error) {
+	h, ok := heights[t]
+	if !ok {
+		return 0, fmt.Errorf("unsupported LMS type %d", t)
+	}
+	return h, nil
+}
+
+// TypeForHeight returns the SHA-256/32 LMS type with the given tree height
+func TypeForHeight(h int) (Type, error) {
+	for t, th := range heights {
+		if th == h {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported LMS tree height %d", h)
+}
+
+// sigLen returns the length of an LM-OTS signature
+func (p otsParams) sigLen() int {
+	return 4 + n + p.p*n
+}
+
+// coef returns the i-th w-bit digit of s (RFC 8554 section 3.1.3)
+func coef(s []byte, i int, w uint) uint {
+	perByte := 8 / int(w)
+	shift := 8 - (w*uint(i%perByte) + w)
+	return (uint(s[i/perByte]) >> shift) & (1<<w - 1)
+}
+
+// checksum computes Cksm(Q) shifted into position (RFC 8554 section 4.4)
+func (p otsParams) checksum(q []byte) []byte {
+	var sum uint
+	for i := 0; i < n*8/int(p.w); i++ {
+		sum += (1<<p.w - 1) - coef(q, i, p.w)
+	}
+	out := make([]byte, 2)
+	binary.BigEndian.PutUint16(out, uint16(sum<<p.ls))
+	return out
+}
+
+func u32str(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
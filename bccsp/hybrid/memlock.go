@@ -0,0 +1,79 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"reflect"
+	"runtime"
+	"unsafe"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// WithMemoryLocking locks the secret keys the provider generates, imports
+// and loads in memory with mlock, so that they are not swapped to disk:
+// the ML-DSA key of liboqs and the scalar of SW ECDSA keys. Locking is
+// process-wide, see core.SetMemoryLocking, and degrades gracefully: keys
+// that cannot be locked, e.g. over RLIMIT_MEMLOCK (ulimit -l) or on
+// platforms without mlock, are used unlocked and
+// bccsp_hybrid_secret_memory_locked drops to 0. Keys of HSMs and of the
+// purego backend are not locked.
+func WithMemoryLocking(enabled bool) Option {
+	return func(h *HybridBCCSP) {
+		h.memoryLocking = enabled
+	}
+}
+
+// enableMemoryLocking applies WithMemoryLocking when New completes
+func (h *HybridBCCSP) enableMemoryLocking() {
+	if h.memoryLocking {
+		// A failure is reported by the gauge; keys are used unlocked
+		_ = core.SetMemoryLocking(true)
+	}
+	h.reportMemoryLocking()
+}
+
+// lockKey locks the ECDSA scalar of a new key, the PQC signer locking its
+// own, and reports the outcome of both
+func (h *HybridBCCSP) lockKey(key *hybridKey) {
+	if !h.memoryLocking {
+		return
+	}
+	if priv := swECDSAPrivateKey(key.ecdsaKey); priv != nil {
+		if key.locked = core.LockSecret(core.ECDSASecret(priv)); key.locked != nil {
+			runtime.SetFinalizer(key, func(k *hybridKey) { core.UnlockSecret(k.locked) })
+		}
+	}
+	h.reportMemoryLocking()
+}
+
+// unlockKey releases the memory locked by lockKey, e.g. of purged keys
+func unlockKey(key *hybridKey) {
+	if key.locked != nil {
+		core.UnlockSecret(key.locked)
+		key.locked = nil
+		runtime.SetFinalizer(key, nil)
+	}
+}
+
+func (h *HybridBCCSP) reportMemoryLocking() {
+	locked := 0.0
+	if h.memoryLocking && core.MemoryLocking().Locked {
+		locked = 1
+	}
+	h.metrics.SecretMemoryLocked.Set(locked)
+}
+
+// swECDSAPrivateKey returns the private key held by a Fabric SW ECDSA key,
+// which does not export it, or nil for other keys
+func swECDSAPrivateKey(k bccsp.Key) *ecdsa.PrivateKey {
+	v := reflect.ValueOf(k)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.Elem().FieldByName("privKey")
+	if !f.IsValid() || f.Type() != reflect.TypeOf((*ecdsa.PrivateKey)(nil)) || f.IsNil() {
+		return nil
+	}
+	return (*ecdsa.PrivateKey)(unsafe.Pointer(f.Pointer()))
+}
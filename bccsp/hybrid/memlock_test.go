@@ -0,0 +1,64 @@
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestMemoryLocking(t *testing.T) {
+	defer core.SetMemoryLocking(false)
+	gauge := &metricsfakes.Gauge{}
+	provider := &metricsfakes.Provider{}
+	provider.NewGaugeStub = func(o metrics.GaugeOpts) metrics.Gauge {
+		if o.Name == secretMemoryLockedOpts.Name {
+			return gauge
+		}
+		return &metricsfakes.Gauge{}
+	}
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider.NewCounterReturns(counter)
+
+	csp, err := New(WithMetricsProvider(provider), WithMemoryLocking(true))
+	require.NoError(t, err)
+	require.True(t, core.MemoryLocking().Enabled)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	key := k.(*hybridKey)
+	require.NotNil(t, swECDSAPrivateKey(key.ecdsaKey), "the SW key layout changed")
+
+	// Locking fails without CAP_IPC_LOCK over RLIMIT_MEMLOCK: keys still work
+	status := core.MemoryLocking()
+	want := 0.0
+	if status.Locked {
+		want = 1
+		assert.Equal(t, core.ECDSASecret(swECDSAPrivateKey(key.ecdsaKey)), key.locked)
+	}
+	require.Equal(t, 2, gauge.SetCallCount())
+	assert.Equal(t, want, gauge.SetArgsForCall(1))
+	sig, err := csp.Sign(k, make([]byte, 32), nil)
+	require.NoError(t, err)
+	valid, err := csp.Verify(k, sig, make([]byte, 32), nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	unlockKey(key)
+	assert.Nil(t, key.locked)
+}
+
+func TestSWECDSAPrivateKey(t *testing.T) {
+	csp, err := New()
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	assert.Nil(t, swECDSAPrivateKey(pub.(*hybridKey).ecdsaKey))
+	assert.Nil(t, swECDSAPrivateKey(nil))
+}
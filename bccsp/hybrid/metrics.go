@@ -0,0 +1,194 @@
+package hybrid
+
+import (
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+)
+
+var (
+	signRateLimitedOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "sign_rate_limited",
+		Help:         "The number of Sign calls rejected by the rate limiter.",
+		LabelNames:   []string{"namespace", "ski"},
+		StatsdFormat: "%{#fqname}.%{namespace}.%{ski}",
+	}
+	keySignaturesOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "key_signatures",
+		Help:         "The number of signatures produced by a key.",
+		LabelNames:   []string{"namespace", "ski"},
+		StatsdFormat: "%{#fqname}.%{namespace}.%{ski}",
+	}
+	signKeyExhaustedOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "sign_key_exhausted",
+		Help:         "The number of Sign calls rejected because the key reached its maximum number of signatures.",
+		LabelNames:   []string{"namespace", "ski"},
+		StatsdFormat: "%{#fqname}.%{namespace}.%{ski}",
+	}
+	auditEmitFailuresOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "audit_emit_failures",
+		Help:         "The number of audit events the audit sink failed to accept.",
+		LabelNames:   []string{"operation"},
+		StatsdFormat: "%{#fqname}.%{operation}",
+	}
+	auditEventsDroppedOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "audit_events_dropped",
+		Help:         "The number of audit events dropped because the audit queue was full.",
+		LabelNames:   []string{"operation"},
+		StatsdFormat: "%{#fqname}.%{operation}",
+	}
+	pqcBackendDegradedOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "pqc_backend_degraded",
+		Help:         "Whether the PQC backend circuit breaker is open (1) or closed (0).",
+		StatsdFormat: "%{#fqname}",
+	}
+	pqcBreakerTripsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "pqc_breaker_trips",
+		Help:         "The number of times repeated PQC backend errors opened the circuit breaker.",
+		StatsdFormat: "%{#fqname}",
+	}
+	ephemeralKeysOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "ephemeral_keys",
+		Help:         "The number of temporary keys held by the provider.",
+		StatsdFormat: "%{#fqname}",
+	}
+	ephemeralKeysPurgedOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "ephemeral_keys_purged",
+		Help:         "The number of temporary keys purged.",
+		StatsdFormat: "%{#fqname}",
+	}
+	verifyTimeoutsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "verify_timeouts",
+		Help:         "The number of PQC verifications abandoned after the verify timeout.",
+		StatsdFormat: "%{#fqname}",
+	}
+	verificationsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "verifications",
+		Help:         "The number of Verify calls by key kind (hybrid, classical or lms) and result.",
+		LabelNames:   []string{"kind", "valid"},
+		StatsdFormat: "%{#fqname}.%{kind}.%{valid}",
+	}
+	pqcCanaryVerificationsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "pqc_canary_verifications",
+		Help:         "The number of PQC verifications repeated with the canary backend by result (agree or discrepancy).",
+		LabelNames:   []string{"result"},
+		StatsdFormat: "%{#fqname}.%{result}",
+	}
+	secretMemoryLockedOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "secret_memory_locked",
+		Help:         "Whether the last secret key buffer was locked in memory (1) or, memory locking being disabled or failing, not (0).",
+		StatsdFormat: "%{#fqname}",
+	}
+	selfBenchmarkSecondsOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "self_benchmark_seconds",
+		Help:         "The latency of the last self-benchmark by operation (sign or verify) and statistic (mean, p50 or p99).",
+		LabelNames:   []string{"operation", "statistic"},
+		StatsdFormat: "%{#fqname}.%{operation}.%{statistic}",
+	}
+	selfBenchmarkRunsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "self_benchmark_runs",
+		Help:         "The number of self-benchmark runs by result (ok or failed).",
+		LabelNames:   []string{"result"},
+		StatsdFormat: "%{#fqname}.%{result}",
+	}
+	selfBenchmarkTimestampOpts = metrics.GaugeOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "self_benchmark_timestamp_seconds",
+		Help:         "The Unix time of the last successful self-benchmark.",
+		StatsdFormat: "%{#fqname}",
+	}
+)
+
+// Metrics holds the instruments of a HybridBCCSP
+type Metrics struct {
+	SignRateLimited    metrics.Counter
+	AuditEmitFailures  metrics.Counter
+	AuditEventsDropped metrics.Counter
+	KeySignatures      metrics.Gauge
+	SignKeyExhausted   metrics.Counter
+
+	PQCBackendDegraded metrics.Gauge
+	PQCBreakerTrips    metrics.Counter
+
+	EphemeralKeys       metrics.Gauge
+	EphemeralKeysPurged metrics.Counter
+
+	VerifyTimeouts metrics.Counter
+	Verifications  metrics.Counter
+
+	PQCCanaryVerifications metrics.Counter
+
+	SecretMemoryLocked metrics.Gauge
+}
+
+// NewMetrics creates the hybrid provider metrics
+func NewMetrics(p metrics.Provider) *Metrics {
+	return &Metrics{
+		SignRateLimited:    p.NewCounter(signRateLimitedOpts),
+		AuditEmitFailures:  p.NewCounter(auditEmitFailuresOpts),
+		AuditEventsDropped: p.NewCounter(auditEventsDroppedOpts),
+		KeySignatures:      p.NewGauge(keySignaturesOpts),
+		SignKeyExhausted:   p.NewCounter(signKeyExhaustedOpts),
+
+		PQCBackendDegraded: p.NewGauge(pqcBackendDegradedOpts),
+		PQCBreakerTrips:    p.NewCounter(pqcBreakerTripsOpts),
+
+		EphemeralKeys:       p.NewGauge(ephemeralKeysOpts),
+		EphemeralKeysPurged: p.NewCounter(ephemeralKeysPurgedOpts),
+
+		VerifyTimeouts: p.NewCounter(verifyTimeoutsOpts),
+		Verifications:  p.NewCounter(verificationsOpts),
+
+		PQCCanaryVerifications: p.NewCounter(pqcCanaryVerificationsOpts),
+
+		SecretMemoryLocked: p.NewGauge(secretMemoryLockedOpts),
+	}
+}
+
+// keyLabels are the label values of per-key metrics
+func (h *HybridBCCSP) keyLabels(ski []byte) []string {
+	ns := h.namespace
+	if ns == "" {
+		ns = "default"
+	}
+	return []string{"namespace", ns, "ski", skiLabel(ski)}
+}
+
+// skiLabel shortens a SKI to keep label values readable
+func skiLabel(ski []byte) string {
+	if len(ski) > 8 {
+		ski = ski[:8]
+	}
+	return hex.EncodeToString(ski)
+}
@@ -0,0 +1,317 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+var (
+	// ErrParameterSet is returned when an imported ML-DSA key is not of the
+	// expected parameter set
+	ErrParameterSet = errors.New("ML-DSA parameter set mismatch")
+	// ErrMLDSAKey is returned for malformed ML-DSA key material
+	ErrMLDSAKey = errors.New("invalid ML-DSA key")
+	// ErrKeyPairMismatch is returned when an imported public key does not
+	// belong to the private key
+	ErrKeyPairMismatch = core.ErrPQCKeyPairMismatch
+)
+
+// mldsaSeedSize is the size of the ML-DSA key generation seed
+const mldsaSeedSize = 32
+
+// mldsaSizes are the FIPS 204 public and expanded private key sizes
+var mldsaSizes = map[string]struct{ public, private int }{
+	"ML-DSA-44": {1312, 2560},
+	"ML-DSA-65": {1952, 4032},
+	"ML-DSA-87": {2592, 4896},
+}
+
+// ExternalPrivateKey is the raw material accepted by
+// ExternalKeyImportOpts: an ECDSA key and an ML-DSA key pair generated
+// outside this package, e.g. by an HSM or openssl with the oqs-provider
+type ExternalPrivateKey struct {
+	ECDSA *ecdsa.PrivateKey
+	// MLDSA is the ML-DSA private key as PKCS#8, DER or PEM, with an ML-DSA
+	// algorithm OID, or the raw FIPS 204 private key
+	MLDSA []byte
+	// MLDSAPublicKey is the raw ML-DSA public key or its SubjectPublicKeyInfo,
+	// DER or PEM. It is required when MLDSA does not embed it and the
+	// backend cannot derive it from the private key.
+	MLDSAPublicKey []byte
+}
+
+// ExternalKeyImportOpts contains options for importing an *ExternalPrivateKey
+type ExternalKeyImportOpts struct {
+	// ParameterSet is the ML-DSA parameter set the key must have, PQCAlgorithm
+	// if empty
+	ParameterSet string
+	Temporary    bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *ExternalKeyImportOpts) Algorithm() string {
+	return Hybrid
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *ExternalKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// MLDSAKey is an ML-DSA key pair parsed by ParseMLDSAPrivateKey. Private
+// keys carry the seed, the expanded key or both.
+type MLDSAKey struct {
+	ParameterSet string
+	Seed         []byte
+	Expanded     []byte
+	PublicKey    []byte
+}
+
+// oneAsymmetricKey is the PKCS#8 / RFC 5958 private key structure
+type oneAsymmetricKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue  `asn1:"optional,tag:0"`
+	PublicKey  asn1.BitString `asn1:"optional,tag:1"`
+}
+
+// mldsaBoth is the both alternative of ML-DSA-PrivateKey
+type mldsaBoth struct {
+	Seed     []byte
+	Expanded []byte
+}
+
+// subjectPublicKeyInfo is the X.509 public key structure
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// mldsaParameterSet returns the parameter set of an ML-DSA OID
+func mldsaParameterSet(oid asn1.ObjectIdentifier) (string, bool) {
+	for name := range mldsaSizes {
+		if o, ok := core.PQCAlgorithmOID(name); ok && o.Equal(oid) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// pemBytes returns the DER of a PEM block of type typ, or data unchanged if
+// it is not PEM
+func pemBytes(data []byte, typ string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return data, nil
+	}
+	if block.Type != typ {
+		return nil, fmt.Errorf("%w: unexpected PEM block %q, expected %q", ErrMLDSAKey, block.Type, typ)
+	}
+	return block.Bytes, nil
+}
+
+// ParseMLDSAPrivateKey parses an ML-DSA private key of parameterSet, or of
+// PQCAlgorithm if empty. It accepts PKCS#8 in DER or PEM with the seed,
+// expandedKey or both private key of the IETF LAMPS profile, the expanded key
+// followed by the public key written by older oqs-provider releases, and raw
+// seeds or expanded keys. The public key is only set when the encoding
+// includes it.
+func ParseMLDSAPrivateKey(data []byte, parameterSet string) (*MLDSAKey, error) {
+	if parameterSet == "" {
+		parameterSet = PQCAlgorithm
+	}
+	sizes, ok := mldsaSizes[parameterSet]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown parameter set %q", ErrParameterSet, parameterSet)
+	}
+	der, err := pemBytes(data, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	var p8 oneAsymmetricKey
+	if rest, err := asn1.Unmarshal(der, &p8); err != nil || len(rest) != 0 {
+		// Raw key
+		return parseMLDSAPrivateKey(der, parameterSet)
+	}
+	set, ok := mldsaParameterSet(p8.Algorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: algorithm %s is not ML-DSA", ErrMLDSAKey, p8.Algorithm.Algorithm)
+	}
+	if set != parameterSet {
+		return nil, fmt.Errorf("%w: key is %s, expected %s", ErrParameterSet, set, parameterSet)
+	}
+	key, err := parseMLDSAPrivateKey(p8.PrivateKey, parameterSet)
+	if err != nil {
+		return nil, err
+	}
+	if pub := p8.PublicKey.RightAlign(); len(pub) > 0 {
+		if key.PublicKey != nil && !bytes.Equal(key.PublicKey, pub) {
+			return nil, fmt.Errorf("%w: PKCS#8 holds two public keys", ErrKeyPairMismatch)
+		}
+		if len(pub) != sizes.public {
+			return nil, fmt.Errorf("%w: %d-byte %s public key", ErrMLDSAKey, len(pub), parameterSet)
+		}
+		key.PublicKey = pub
+	}
+	return key, nil
+}
+
+// parseMLDSAPrivateKey parses the privateKey of PKCS#8, or a raw key
+func parseMLDSAPrivateKey(raw []byte, parameterSet string) (*MLDSAKey, error) {
+	sizes := mldsaSizes[parameterSet]
+	key := &MLDSAKey{ParameterSet: parameterSet}
+
+	var choice asn1.RawValue
+	if rest, err := asn1.Unmarshal(raw, &choice); err == nil && len(rest) == 0 {
+		switch {
+		case choice.Class == asn1.ClassContextSpecific && choice.Tag == 0 && !choice.IsCompound:
+			key.Seed = choice.Bytes
+		case choice.Class == asn1.ClassUniversal && choice.Tag == asn1.TagOctetString:
+			key.Expanded = choice.Bytes
+		case choice.Class == asn1.ClassUniversal && choice.Tag == asn1.TagSequence:
+			var both mldsaBoth
+			if _, err := asn1.Unmarshal(raw, &both); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrMLDSAKey, err)
+			}
+			key.Seed, key.Expanded = both.Seed, both.Expanded
+		}
+	}
+	if key.Seed == nil && key.Expanded == nil {
+		switch len(raw) {
+		case mldsaSeedSize:
+			key.Seed = raw
+		case sizes.private:
+			key.Expanded = raw
+		case sizes.private + sizes.public:
+			key.Expanded, key.PublicKey = raw[:sizes.private], raw[sizes.private:]
+		default:
+			return nil, fmt.Errorf("%w: %d bytes is not a %s private key", ErrParameterSet, len(raw), parameterSet)
+		}
+	}
+	if key.Seed != nil && len(key.Seed) != mldsaSeedSize {
+		return nil, fmt.Errorf("%w: %d-byte seed", ErrMLDSAKey, len(key.Seed))
+	}
+	if key.Expanded != nil && len(key.Expanded) != sizes.private {
+		return nil, fmt.Errorf("%w: %d bytes is not a %s private key", ErrParameterSet, len(key.Expanded), parameterSet)
+	}
+	key.Seed = bytes.Clone(key.Seed)
+	key.Expanded = bytes.Clone(key.Expanded)
+	key.PublicKey = bytes.Clone(key.PublicKey)
+	return key, nil
+}
+
+// ParseMLDSAPublicKey parses an ML-DSA public key of parameterSet, or of
+// PQCAlgorithm if empty: a SubjectPublicKeyInfo in DER or PEM, or the raw key
+func ParseMLDSAPublicKey(data []byte, parameterSet string) ([]byte, error) {
+	if parameterSet == "" {
+		parameterSet = PQCAlgorithm
+	}
+	sizes, ok := mldsaSizes[parameterSet]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown parameter set %q", ErrParameterSet, parameterSet)
+	}
+	der, err := pemBytes(data, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	pub := der
+	var spki subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 {
+		set, ok := mldsaParameterSet(spki.Algorithm.Algorithm)
+		if !ok {
+			return nil, fmt.Errorf("%w: algorithm %s is not ML-DSA", ErrMLDSAKey, spki.Algorithm.Algorithm)
+		}
+		if set != parameterSet {
+			return nil, fmt.Errorf("%w: key is %s, expected %s", ErrParameterSet, set, parameterSet)
+		}
+		pub = spki.PublicKey.RightAlign()
+	}
+	if len(pub) != sizes.public {
+		return nil, fmt.Errorf("%w: %d bytes is not a %s public key", ErrParameterSet, len(pub), parameterSet)
+	}
+	return bytes.Clone(pub), nil
+}
+
+// keyPair returns the private and public key to load on the compiled
+// backend, which takes expanded keys with liboqs and seeds with
+// crypto/mldsa, after checking that they are a pair
+func (k *MLDSAKey) keyPair() (priv, pub []byte, err error) {
+	if k.ParameterSet != PQCAlgorithm {
+		return nil, nil, fmt.Errorf("%w: key is %s, the provider signs with %s", ErrParameterSet, k.ParameterSet, PQCAlgorithm)
+	}
+	priv, format := k.Expanded, "expanded key"
+	if core.PQCPrivateKeySize() == mldsaSeedSize {
+		priv, format = k.Seed, "seed"
+	}
+	if priv == nil {
+		return nil, nil, fmt.Errorf("%w: the %s backend needs the %s of the key", ErrMLDSAKey, core.PQCBackend, format)
+	}
+	// Signers wipe their private key on Clean, so they get copies
+	s, err := NewPQCSignerFromPrivate(bytes.Clone(priv))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrMLDSAKey, err)
+	}
+	defer s.Clean()
+	pub = k.PublicKey
+	if derived := s.PublicKey(); derived != nil {
+		if pub != nil && !bytes.Equal(pub, derived) {
+			return nil, nil, ErrKeyPairMismatch
+		}
+		pub = derived
+	}
+	if pub == nil {
+		return nil, nil, fmt.Errorf("%w: the public key is required", ErrMLDSAKey)
+	}
+
+	// Pairwise consistency test, the only check of an expanded key
+	msg := []byte("ML-DSA pairwise consistency test")
+	sig, err := s.Sign(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrMLDSAKey, err)
+	}
+	if valid, err := VerifyPQC(pub, msg, sig); err != nil || !valid {
+		return nil, nil, ErrKeyPairMismatch
+	}
+	return bytes.Clone(priv), bytes.Clone(pub), nil
+}
+
+// importExternalKey builds a signing hybridKey from an *ExternalPrivateKey
+func (h *HybridBCCSP) importExternalKey(raw interface{}, opts *ExternalKeyImportOpts) (bccsp.Key, error) {
+	ext, ok := raw.(*ExternalPrivateKey)
+	if !ok || ext == nil {
+		return nil, fmt.Errorf("invalid raw material, expected *ExternalPrivateKey")
+	}
+	if ext.ECDSA == nil || len(ext.MLDSA) == 0 {
+		return nil, errors.New("external private key is incomplete")
+	}
+	key, err := ParseMLDSAPrivateKey(ext.MLDSA, opts.ParameterSet)
+	if err != nil {
+		return nil, err
+	}
+	if len(ext.MLDSAPublicKey) > 0 {
+		pub, err := ParseMLDSAPublicKey(ext.MLDSAPublicKey, key.ParameterSet)
+		if err != nil {
+			return nil, err
+		}
+		if key.PublicKey != nil && !bytes.Equal(key.PublicKey, pub) {
+			return nil, ErrKeyPairMismatch
+		}
+		key.PublicKey = pub
+	}
+	priv, pub, err := key.keyPair()
+	if err != nil {
+		return nil, err
+	}
+	return h.importPrivateKey(&HybridPrivateKey{ECDSA: ext.ECDSA, PQCPrivateKey: priv, PQCPublicKey: pub}, opts.Temporary)
+}
@@ -0,0 +1,162 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// externalMLDSAKey returns a key pair of the backend, wrapped in PKCS#8 the
+// way external tools export it: seed or expanded key, depending on the
+// backend
+func externalMLDSAKey(t *testing.T) (p8 []byte, priv, pub []byte) {
+	s, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer s.Clean()
+	priv, pub = append([]byte(nil), s.PrivateKey()...), s.PublicKey()
+	choice := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagOctetString, Bytes: priv}
+	if len(priv) == mldsaSeedSize {
+		choice = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: priv}
+	}
+	der, err := asn1.Marshal(choice)
+	require.NoError(t, err)
+	return mldsaPKCS8(t, PQCAlgorithm, der, nil), priv, pub
+}
+
+func mldsaPKCS8(t *testing.T, parameterSet string, privateKey, publicKey []byte) []byte {
+	oid, ok := core.PQCAlgorithmOID(parameterSet)
+	require.True(t, ok)
+	k := oneAsymmetricKey{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oid}, PrivateKey: privateKey}
+	if publicKey != nil {
+		k.Version = 1
+		k.PublicKey = asn1.BitString{Bytes: publicKey, BitLength: 8 * len(publicKey)}
+	}
+	der, err := asn1.Marshal(k)
+	require.NoError(t, err)
+	return der
+}
+
+func TestParseMLDSAPrivateKey(t *testing.T) {
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rand.Read(b)
+		return b
+	}
+	sizes := mldsaSizes[PQCAlgorithm]
+	seed, priv, pub := random(mldsaSeedSize), random(sizes.private), random(sizes.public)
+	expanded, err := asn1.Marshal(priv)
+	require.NoError(t, err)
+	both, err := asn1.Marshal(mldsaBoth{Seed: seed, Expanded: priv})
+	require.NoError(t, err)
+	seedOnly, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: seed})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		data           []byte
+		seed, expanded bool
+		public         bool
+	}{
+		"expanded":         {data: mldsaPKCS8(t, PQCAlgorithm, expanded, nil), expanded: true},
+		"expanded, public": {data: mldsaPKCS8(t, PQCAlgorithm, expanded, pub), expanded: true, public: true},
+		"both":             {data: mldsaPKCS8(t, PQCAlgorithm, both, nil), seed: true, expanded: true},
+		"seed":             {data: mldsaPKCS8(t, PQCAlgorithm, seedOnly, nil), seed: true},
+		"oqs-provider":     {data: mldsaPKCS8(t, PQCAlgorithm, append(append([]byte{}, priv...), pub...), nil), expanded: true, public: true},
+		"PEM": {data: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mldsaPKCS8(t, PQCAlgorithm, expanded, pub)}),
+			expanded: true, public: true},
+		"raw expanded": {data: priv, expanded: true},
+		"raw seed":     {data: seed, seed: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			k, err := ParseMLDSAPrivateKey(tc.data, "")
+			require.NoError(t, err)
+			assert.Equal(t, PQCAlgorithm, k.ParameterSet)
+			assert.Equal(t, tc.seed, k.Seed != nil)
+			assert.Equal(t, tc.expanded, k.Expanded != nil)
+			if tc.expanded {
+				assert.Equal(t, priv, k.Expanded)
+			}
+			if tc.public {
+				assert.Equal(t, pub, k.PublicKey)
+			} else {
+				assert.Nil(t, k.PublicKey)
+			}
+		})
+	}
+
+	_, err = ParseMLDSAPrivateKey(mldsaPKCS8(t, "ML-DSA-87", expanded, nil), "")
+	assert.ErrorIs(t, err, ErrParameterSet, "OID of another parameter set")
+	_, err = ParseMLDSAPrivateKey(mldsaPKCS8(t, PQCAlgorithm, expanded, nil), "ML-DSA-44")
+	assert.ErrorIs(t, err, ErrParameterSet, "not the expected parameter set")
+	_, err = ParseMLDSAPrivateKey(priv[:2560], "")
+	assert.ErrorIs(t, err, ErrParameterSet, "ML-DSA-44 sized key")
+	_, err = ParseMLDSAPrivateKey(mldsaPKCS8(t, PQCAlgorithm, expanded, pub[1:]), "")
+	assert.ErrorIs(t, err, ErrMLDSAKey)
+
+	_, err = ParseMLDSAPublicKey(pub[:1312], "")
+	assert.ErrorIs(t, err, ErrParameterSet)
+	oid, _ := core.PQCAlgorithmOID(PQCAlgorithm)
+	spki, err := asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oid},
+		PublicKey: asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	})
+	require.NoError(t, err)
+	parsed, err := ParseMLDSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki}), "")
+	require.NoError(t, err)
+	assert.Equal(t, pub, parsed)
+}
+
+func TestImportExternalKey(t *testing.T) {
+	p8, priv, pub := externalMLDSAKey(t)
+	_, _, otherPub := externalMLDSAKey(t)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csp, err := New()
+	require.NoError(t, err)
+
+	k, err := csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: p8, MLDSAPublicKey: pub},
+		&ExternalKeyImportOpts{ParameterSet: PQCAlgorithm, Temporary: true})
+	require.NoError(t, err)
+	assert.Equal(t, KindHybrid, KindOf(k))
+
+	digest := sha256.Sum256([]byte("imported"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	pk, err := k.PublicKey()
+	require.NoError(t, err)
+	valid, err := csp.Verify(pk, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: p8, MLDSAPublicKey: otherPub},
+		&ExternalKeyImportOpts{Temporary: true})
+	assert.ErrorIs(t, err, ErrKeyPairMismatch)
+	_, err = csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: p8, MLDSAPublicKey: pub},
+		&ExternalKeyImportOpts{ParameterSet: "ML-DSA-87", Temporary: true})
+	assert.ErrorIs(t, err, ErrParameterSet)
+
+	// The backend loads one of the two private key formats only
+	other := make([]byte, mldsaSizes[PQCAlgorithm].private)
+	if len(priv) != mldsaSeedSize {
+		other = make([]byte, mldsaSeedSize)
+	}
+	_, err = csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: other, MLDSAPublicKey: pub},
+		&ExternalKeyImportOpts{Temporary: true})
+	assert.ErrorIs(t, err, ErrMLDSAKey)
+
+	if s, err := NewPQCSignerFromPrivate(append([]byte(nil), priv...)); err == nil && s.PublicKey() == nil {
+		_, err = csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: p8}, &ExternalKeyImportOpts{Temporary: true})
+		assert.ErrorIs(t, err, ErrMLDSAKey, "the public key cannot be derived")
+	}
+	_, err = csp.KeyImport(&ExternalPrivateKey{ECDSA: ecdsaKey, MLDSA: p8}, &bccsp.ECDSAPrivateKeyImportOpts{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package hybrid
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy defines which signature components Verify requires
+type Policy = core.Policy
+
+const (
+	PolicyHybridAND = core.PolicyHybridAND
+	PolicyHybridOR  = core.PolicyHybridOR
+	PolicyClassical = core.PolicyClassical
+	PolicyPQC       = core.PolicyPQC
+)
+
+// ParsePolicy parses AND, OR, CLASSICAL or PQC (case insensitive)
+func ParsePolicy(s string) (Policy, error) {
+	return core.ParsePolicy(s)
+}
+
+// HybridSignerOpts carries the channel and MSP a signature is verified for
+type HybridSignerOpts struct {
+	Channel string
+	MSPID   string
+	// Policy overrides the resolved policy when not nil
+	Policy *Policy
+	// Message is the message the digest was computed from, required to sign
+	// and verify in pure ML-DSA mode and by framers other than RawDigest
+	Message []byte
+	// Framer selects the bytes the ML-DSA component signs, overriding the
+	// digest policy; verifiers must select the same framer
+	Framer MessageFramer
+	// Priority schedules SignAsync and VerifyAsync on the worker pool
+	Priority Priority
+	// Context carries the caller's pprof labels, kept when profiling labels
+	// are enabled
+	Context context.Context
+	// Block is the block being validated, nil outside block validation. A
+	// BlockPolicyResolver resolves the policy for it, e.g. a CutoverResolver.
+	Block *BlockRef
+}
+
+// BlockRef identifies the block a signature is validated in
+type BlockRef struct {
+	Number uint64
+	// Timestamp is the channel header timestamp of the block's first
+	// transaction, zero if unknown
+	Timestamp time.Time
+}
+
+// HashFunc returns 0, the digest is computed by the caller
+func (o *HybridSignerOpts) HashFunc() crypto.Hash {
+	return 0
+}
+
+// PolicyResolver resolves the verification policy for a channel and MSP
+type PolicyResolver interface {
+	ResolvePolicy(channel, mspID string) Policy
+}
+
+// BlockPolicyResolver is a PolicyResolver whose policies depend on the block
+// being validated. Verify calls ResolveBlockPolicy for options carrying a
+// Block, so concurrent validation of different blocks shares no state.
+type BlockPolicyResolver interface {
+	PolicyResolver
+	ResolveBlockPolicy(channel, mspID string, block BlockRef) Policy
+}
+
+// ChannelPolicy is the policy of a single channel with optional per-MSP overrides
+type ChannelPolicy struct {
+	Default Policy            `yaml:"default"`
+	MSPs    map[string]Policy `yaml:"msps,omitempty"`
+}
+
+// ChannelPolicies is a static PolicyResolver, usually loaded from channel configuration
+type ChannelPolicies struct {
+	Default  Policy                   `yaml:"default"`
+	Channels map[string]ChannelPolicy `yaml:"channels,omitempty"`
+}
+
+// ResolvePolicy returns the MSP override, then the channel default, then the global default
+func (c *ChannelPolicies) ResolvePolicy(channel, mspID string) Policy {
+	ch, ok := c.Channels[channel]
+	if !ok {
+		return c.Default
+	}
+	if p, ok := ch.MSPs[mspID]; ok {
+		return p
+	}
+	return ch.Default
+}
+
+// LoadChannelPolicies reads a ChannelPolicies YAML file
+func LoadChannelPolicies(path string) (*ChannelPolicies, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read channel policies: %w", err)
+	}
+	policies := &ChannelPolicies{}
+	if err := yaml.Unmarshal(raw, policies); err != nil {
+		return nil, fmt.Errorf("failed to parse channel policies %s: %w", path, err)
+	}
+	return policies, nil
+}
+
+// staticPolicy always resolves to the same policy
+type staticPolicy Policy
+
+func (s staticPolicy) ResolvePolicy(string, string) Policy {
+	return Policy(s)
+}
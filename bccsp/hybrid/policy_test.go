@@ -0,0 +1,82 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelPoliciesResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+default: AND
+channels:
+  channel-a:
+    default: AND
+  channel-b:
+    default: classical
+    msps:
+      Org3MSP: OR
+`), 0o600))
+
+	policies, err := LoadChannelPolicies(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, PolicyHybridAND, policies.ResolvePolicy("channel-a", "Org1MSP"))
+	assert.Equal(t, PolicyClassical, policies.ResolvePolicy("channel-b", "Org1MSP"))
+	assert.Equal(t, PolicyHybridOR, policies.ResolvePolicy("channel-b", "Org3MSP"))
+	assert.Equal(t, PolicyHybridAND, policies.ResolvePolicy("unknown", "Org1MSP"))
+
+	require.NoError(t, os.WriteFile(path, []byte("default: XOR\n"), 0o600))
+	_, err = LoadChannelPolicies(path)
+	assert.Error(t, err)
+}
+
+func TestVerifyPolicies(t *testing.T) {
+	policies := &ChannelPolicies{
+		Default: PolicyHybridAND,
+		Channels: map[string]ChannelPolicy{
+			"classical": {Default: PolicyClassical},
+			"staged":    {Default: PolicyHybridAND, MSPs: map[string]Policy{"Org2MSP": PolicyHybridOR}},
+		},
+	}
+	h, err := New(WithPolicyResolver(policies))
+	require.NoError(t, err)
+
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("staged rollout"))
+	signature, err := h.Sign(key, digest[:], nil)
+	require.NoError(t, err)
+
+	env, err := ParseEnvelope(signature)
+	require.NoError(t, err)
+	badPQC := append([]byte(nil), env.PQCSignature...)
+	badPQC[0] ^= 0xFF
+	brokenPQC := (&Envelope{Version: env.Version, Modes: env.Modes, ECDSASignature: env.ECDSASignature, PQCSignature: badPQC}).Marshal()
+
+	verify := func(sig []byte, channel, msp string) bool {
+		valid, _ := h.Verify(pub, sig, digest[:], &HybridSignerOpts{Channel: channel, MSPID: msp})
+		return valid
+	}
+
+	assert.True(t, verify(signature, "any", "Org1MSP"))
+	assert.False(t, verify(brokenPQC, "any", "Org1MSP"), "AND requires the PQC component")
+	assert.True(t, verify(brokenPQC, "classical", "Org1MSP"), "classical channel ignores the PQC component")
+	assert.False(t, verify(brokenPQC, "staged", "Org1MSP"))
+	assert.True(t, verify(brokenPQC, "staged", "Org2MSP"), "OR accepts a valid ECDSA component")
+
+	pqcOnly := PolicyPQC
+	valid, err := h.Verify(pub, brokenPQC, digest[:], &HybridSignerOpts{Policy: &pqcOnly})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
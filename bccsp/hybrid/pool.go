@@ -0,0 +1,224 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// ErrPoolClosed is returned for jobs submitted after WorkerPool.Close
+var ErrPoolClosed = errors.New("worker pool closed")
+
+// Priority orders the jobs waiting in a WorkerPool. Workers always take the
+// highest priority job queued, so block validation is not delayed by
+// background re-signing or notarization. Running jobs are not interrupted.
+type Priority int8
+
+const (
+	// PriorityBackground is for migration, re-signing and notarization jobs
+	PriorityBackground Priority = iota - 1
+	// PriorityNormal is the default, e.g. endorsements
+	PriorityNormal
+	// PriorityCritical is for consensus work such as block validation
+	PriorityCritical
+
+	numPriorities = 3
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PriorityNormal:
+		return "normal"
+	case PriorityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Priority(%d)", int8(p))
+}
+
+// WorkerPool runs crypto operations on a fixed set of goroutines
+type WorkerPool struct {
+	mutex  sync.Mutex
+	ready  *sync.Cond // a job was queued or the pool closed
+	space  *sync.Cond // a job left its queue
+	queues [numPriorities][]func()
+	limit  int
+	closed bool
+
+	wg sync.WaitGroup
+}
+
+// NewWorkerPool starts workers goroutines; at most queue jobs of each
+// priority wait for one. workers <= 0 means GOMAXPROCS, queue < 1 means 1.
+func NewWorkerPool(workers, queue int) *WorkerPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if queue < 1 {
+		queue = 1
+	}
+	p := &WorkerPool{limit: queue}
+	p.ready = sync.NewCond(&p.mutex)
+	p.space = sync.NewCond(&p.mutex)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for {
+		job, ok := p.next()
+		if !ok {
+			return
+		}
+		job()
+	}
+}
+
+// next waits for the highest priority job; false once closed and drained
+func (p *WorkerPool) next() (func(), bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for {
+		for i := numPriorities - 1; i >= 0; i-- {
+			if q := p.queues[i]; len(q) > 0 {
+				job := q[0]
+				q[0] = nil
+				p.queues[i] = q[1:]
+				p.space.Broadcast()
+				return job, true
+			}
+		}
+		if p.closed {
+			return nil, false
+		}
+		p.ready.Wait()
+	}
+}
+
+// Submit queues job with PriorityNormal
+func (p *WorkerPool) Submit(job func()) error {
+	return p.SubmitPriority(PriorityNormal, job)
+}
+
+// SubmitPriority queues job, blocking while the queue of its priority is full
+func (p *WorkerPool) SubmitPriority(prio Priority, job func()) error {
+	if prio < PriorityBackground || prio > PriorityCritical {
+		return fmt.Errorf("invalid priority %s", prio)
+	}
+	i := int(prio - PriorityBackground)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for !p.closed && len(p.queues[i]) >= p.limit {
+		p.space.Wait()
+	}
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.queues[i] = append(p.queues[i], job)
+	p.ready.Signal()
+	return nil
+}
+
+// Close runs the queued jobs and stops the workers
+func (p *WorkerPool) Close() {
+	p.mutex.Lock()
+	p.closed = true
+	p.ready.Broadcast()
+	p.space.Broadcast()
+	p.mutex.Unlock()
+	p.wg.Wait()
+}
+
+var (
+	defaultPoolOnce sync.Once
+	defaultPool     *WorkerPool
+)
+
+// sharedPool is used by providers without WithWorkerPool, so that providers
+// created per channel do not each start their own workers
+func sharedPool() *WorkerPool {
+	defaultPoolOnce.Do(func() {
+		defaultPool = NewWorkerPool(0, 64)
+	})
+	return defaultPool
+}
+
+// WithWorkerPool runs SignAsync on p instead of the process-wide pool
+func WithWorkerPool(p *WorkerPool) Option {
+	return func(h *HybridBCCSP) {
+		h.pool = p
+	}
+}
+
+// SignResult is the outcome of SignAsync
+type SignResult struct {
+	Signature []byte
+	Err       error
+}
+
+// VerifyResult is the outcome of VerifyAsync
+type VerifyResult struct {
+	Valid bool
+	Err   error
+}
+
+// AsyncSigner is implemented by the provider returned by New. Jobs run with
+// the Priority of HybridSignerOpts, PriorityNormal for other opts.
+type AsyncSigner interface {
+	SignAsync(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) <-chan SignResult
+	VerifyAsync(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) <-chan VerifyResult
+}
+
+// workerPool returns the pool of h
+func (h *HybridBCCSP) workerPool() *WorkerPool {
+	if h.pool != nil {
+		return h.pool
+	}
+	return sharedPool()
+}
+
+// priorityOf returns the priority requested by opts
+func priorityOf(opts bccsp.SignerOpts) Priority {
+	if o, ok := opts.(*HybridSignerOpts); ok && o != nil {
+		return o.Priority
+	}
+	return PriorityNormal
+}
+
+// SignAsync signs on the worker pool and delivers the result on the returned
+// channel, so callers can overlap signing with other work, e.g. chaincode
+// simulation. The channel is buffered and receives exactly one result.
+// SignAsync blocks while the pool queue is full.
+func (h *HybridBCCSP) SignAsync(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) <-chan SignResult {
+	result := make(chan SignResult, 1)
+	err := h.workerPool().SubmitPriority(priorityOf(opts), func() {
+		sig, err := h.Sign(k, digest, opts)
+		result <- SignResult{Signature: sig, Err: err}
+	})
+	if err != nil {
+		result <- SignResult{Err: err}
+	}
+	return result
+}
+
+// VerifyAsync is Verify on the worker pool, delivering one result like SignAsync
+func (h *HybridBCCSP) VerifyAsync(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) <-chan VerifyResult {
+	result := make(chan VerifyResult, 1)
+	err := h.workerPool().SubmitPriority(priorityOf(opts), func() {
+		valid, err := h.Verify(k, signature, digest, opts)
+		result <- VerifyResult{Valid: valid, Err: err}
+	})
+	if err != nil {
+		result <- VerifyResult{Err: err}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(2, 4)
+	var n atomic.Int32
+	for i := 0; i < 10; i++ {
+		require.NoError(t, p.Submit(func() { n.Add(1) }))
+	}
+	p.Close()
+	assert.Equal(t, int32(10), n.Load(), "Close runs the queued jobs")
+	assert.ErrorIs(t, p.Submit(func() {}), ErrPoolClosed)
+}
+
+func TestSignAsync(t *testing.T) {
+	pool := NewWorkerPool(2, 0)
+	h, err := New(WithWorkerPool(pool))
+	require.NoError(t, err)
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	signer := h.(AsyncSigner)
+
+	digests := make([][32]byte, 8)
+	results := make([]<-chan SignResult, len(digests))
+	for i := range digests {
+		digests[i] = sha256.Sum256([]byte{byte(i)})
+		results[i] = signer.SignAsync(key, digests[i][:], nil)
+	}
+	for i, ch := range results {
+		r := <-ch
+		require.NoError(t, r.Err)
+		valid, err := h.Verify(key, r.Signature, digests[i][:], nil)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	r := <-signer.SignAsync(key, []byte("short"), nil)
+	assert.ErrorIs(t, r.Err, ErrInvalidDigest)
+
+	pool.Close()
+	r = <-signer.SignAsync(key, digests[0][:], nil)
+	assert.ErrorIs(t, r.Err, ErrPoolClosed)
+}
+
+func TestWorkerPoolPriority(t *testing.T) {
+	p := NewWorkerPool(1, 8)
+	defer p.Close()
+
+	// Hold the only worker while the queues fill up
+	release := make(chan struct{})
+	require.NoError(t, p.Submit(func() { <-release }))
+
+	order := make(chan Priority, 6)
+	for _, prio := range []Priority{PriorityBackground, PriorityNormal, PriorityBackground, PriorityCritical, PriorityNormal, PriorityCritical} {
+		prio := prio
+		require.NoError(t, p.SubmitPriority(prio, func() { order <- prio }))
+	}
+	close(release)
+
+	var got []Priority
+	for i := 0; i < 6; i++ {
+		got = append(got, <-order)
+	}
+	assert.Equal(t, []Priority{
+		PriorityCritical, PriorityCritical,
+		PriorityNormal, PriorityNormal,
+		PriorityBackground, PriorityBackground,
+	}, got)
+
+	assert.Error(t, p.SubmitPriority(Priority(5), func() {}))
+}
@@ -0,0 +1,95 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// PreloadConfig configures the key preloading done by New
+type PreloadConfig struct {
+	// Workers loads keys in parallel, GOMAXPROCS when not positive
+	Workers int
+	// Progress, if set, is called after each key with the number of keys
+	// loaded so far and the total
+	Progress func(loaded, total int)
+}
+
+// WithPreload makes New load every key of the provider's keystore namespace
+// and initialize its ML-DSA signer, and the cached verifier if a verifier
+// cache is configured, so the first transactions after a restart do not
+// pay for it. GetKey then serves preloaded keys from memory.
+func WithPreload(c PreloadConfig) Option {
+	return func(h *HybridBCCSP) {
+		h.preload = &c
+	}
+}
+
+// preloadKeys loads the namespace keys into h.preloaded
+func (h *HybridBCCSP) preloadKeys() error {
+	if h.keystore == nil {
+		return errors.New("key preloading requires a keystore")
+	}
+	skis, err := h.keystore.ListKeys(h.namespace)
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+	workers := h.preload.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	h.preloaded = make(map[string]bccsp.Key, len(skis))
+	jobs := make(chan []byte)
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		errs   []error
+		loaded int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ski := range jobs {
+				k, err := h.loadKey(ski)
+
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("key %x: %w", ski, err))
+				} else {
+					h.preloaded[hex.EncodeToString(ski)] = k
+					loaded++
+					if h.preload.Progress != nil {
+						h.preload.Progress(loaded, len(skis))
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, ski := range skis {
+		jobs <- ski
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// loadKey reads a key and warms its verifier
+func (h *HybridBCCSP) loadKey(ski []byte) (bccsp.Key, error) {
+	k, err := h.keystore.GetKey(h.namespace, ski)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := k.(*hybridKey); ok && h.verifiers != nil {
+		if _, err := h.verifiers.Verifier(key.pqcPub); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
@@ -0,0 +1,47 @@
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/disabled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreload(t *testing.T) {
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithKeyStore(ks, "Org1MSP"))
+	require.NoError(t, err)
+	var skis [][]byte
+	for i := 0; i < 3; i++ {
+		k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+		require.NoError(t, err)
+		skis = append(skis, k.SKI())
+	}
+
+	var progress [][2]int
+	cache := NewVerifierCache(10, &disabled.Provider{})
+	preloaded, err := New(
+		WithKeyStore(ks, "Org1MSP"),
+		WithVerifierCache(cache),
+		WithPreload(PreloadConfig{Workers: 2, Progress: func(loaded, total int) {
+			progress = append(progress, [2]int{loaded, total})
+		}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, progress)
+	assert.Equal(t, 3, cache.Len())
+
+	for _, ski := range skis {
+		k1, err := preloaded.GetKey(ski)
+		require.NoError(t, err)
+		k2, err := preloaded.GetKey(ski)
+		require.NoError(t, err)
+		assert.Same(t, k1, k2)
+	}
+
+	_, err = New(WithPreload(PreloadConfig{}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// HybridPrivateKey is the raw material accepted by HybridPrivateKeyImportOpts,
+// e.g. the two halves of an MSP keystore written by qlca
+type HybridPrivateKey struct {
+	ECDSA         *ecdsa.PrivateKey
+	PQCPrivateKey []byte
+	PQCPublicKey  []byte
+}
+
+// HybridPrivateKeyImportOpts contains options for importing a *HybridPrivateKey
+type HybridPrivateKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *HybridPrivateKeyImportOpts) Algorithm() string {
+	return Hybrid
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *HybridPrivateKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// importPrivateKey builds a signing hybridKey from its raw halves
+func (h *HybridBCCSP) importPrivateKey(raw interface{}, temporary bool) (bccsp.Key, error) {
+	priv, ok := raw.(*HybridPrivateKey)
+	if !ok || priv == nil {
+		return nil, fmt.Errorf("invalid raw material, expected *HybridPrivateKey")
+	}
+	if priv.ECDSA == nil || len(priv.PQCPrivateKey) == 0 || len(priv.PQCPublicKey) == 0 {
+		return nil, errors.New("hybrid private key is incomplete")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ECDSA private key: %w", err)
+	}
+	// With a namespace keystore the combined key is stored below, the SW
+	// keystore is a dummy that refuses to store
+	ecdsaKey, err := h.sw.KeyImport(der, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: temporary || h.keystore != nil})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import ECDSA private key: %w", err)
+	}
+	pqc, err := NewPQCSignerFromKeyPair(priv.PQCPrivateKey, priv.PQCPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &hybridKey{
+		ecdsaKey: ecdsaKey,
+		pqcPriv:  pqc,
+		pqcPub:   append([]byte(nil), priv.PQCPublicKey...),
+	}
+	h.lockKey(key)
+	if h.keystore != nil && !temporary {
+		if err := h.keystore.StoreKey(h.namespace, key); err != nil {
+			return nil, fmt.Errorf("failed to store hybrid key: %w", err)
+		}
+	}
+	h.trackEphemeral(key, temporary)
+	return key, nil
+}
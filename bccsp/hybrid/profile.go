@@ -0,0 +1,62 @@
+package hybrid
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// pprof label keys attached to crypto operations
+const (
+	LabelOperation = "crypto_operation"
+	LabelAlgorithm = "crypto_algorithm"
+)
+
+// Algorithm label values
+const (
+	AlgorithmECDSA  = core.ClassicalAlgorithm
+	AlgorithmMLDSA  = core.PQCAlgorithm
+	AlgorithmLMS    = "LMS"
+	AlgorithmHybrid = AlgorithmECDSA + "+" + AlgorithmMLDSA
+)
+
+// WithProfilingLabels attaches pprof labels (LabelOperation, LabelAlgorithm)
+// around the ECDSA, ML-DSA and LMS parts of KeyGen, Sign and Verify, so CPU
+// profiles of loaded peers can be sliced per algorithm instead of showing
+// one cgo blob. Labels cost a few allocations per operation, so they are
+// off by default. The caller's labels are kept when the opts are
+// HybridSignerOpts with a Context; otherwise they are replaced for the
+// duration of the operation.
+func WithProfilingLabels(enabled bool) Option {
+	return func(h *HybridBCCSP) {
+		h.profiling = enabled
+	}
+}
+
+// profiler runs functions with pprof labels; a nil profiler runs them as is
+type profiler struct {
+	ctx context.Context
+}
+
+// profiler returns nil unless profiling labels are enabled
+func (h *HybridBCCSP) profiler(opts bccsp.SignerOpts) *profiler {
+	if !h.profiling {
+		return nil
+	}
+	ctx := context.Background()
+	if o, ok := opts.(*HybridSignerOpts); ok && o != nil && o.Context != nil {
+		ctx = o.Context
+	}
+	return &profiler{ctx: ctx}
+}
+
+// do runs fn labeled with the operation and algorithm
+func (p *profiler) do(op, alg string, fn func()) {
+	if p == nil {
+		fn()
+		return
+	}
+	pprof.Do(p.ctx, pprof.Labels(LabelOperation, op, LabelAlgorithm, alg), func(context.Context) { fn() })
+}
@@ -0,0 +1,71 @@
+package hybrid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelRecorder captures the goroutine labels seen by ECDSA calls
+type labelRecorder struct {
+	bccsp.BCCSP
+	profiles []string
+}
+
+func (r *labelRecorder) record() {
+	var buf bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	r.profiles = append(r.profiles, buf.String())
+}
+
+func (r *labelRecorder) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	r.record()
+	return r.BCCSP.Sign(k, digest, opts)
+}
+
+func (r *labelRecorder) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (bool, error) {
+	r.record()
+	return r.BCCSP.Verify(k, signature, digest, opts)
+}
+
+func TestProfilingLabels(t *testing.T) {
+	csp, err := New(WithProfilingLabels(true))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+	rec := &labelRecorder{BCCSP: h.sw}
+	h.sw = rec
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("profile"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	// The caller's labels are kept when passed through the opts
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("peer", "peer0"))
+	valid, err := csp.Verify(k, sig, digest[:], &HybridSignerOpts{Context: ctx})
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	require.Len(t, rec.profiles, 2)
+	assert.Contains(t, rec.profiles[0], `"crypto_operation":"sign"`)
+	assert.Contains(t, rec.profiles[0], `"crypto_algorithm":"ECDSA-P256"`)
+	assert.Contains(t, rec.profiles[1], `"crypto_operation":"verify"`)
+	assert.Contains(t, rec.profiles[1], `"peer":"peer0"`)
+
+	// Labels are not attached by default
+	plain, err := New()
+	require.NoError(t, err)
+	rec = &labelRecorder{BCCSP: plain.(*HybridBCCSP).sw}
+	plain.(*HybridBCCSP).sw = rec
+	_, err = plain.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	require.Len(t, rec.profiles, 1)
+	assert.NotContains(t, rec.profiles[0], "crypto_operation")
+}
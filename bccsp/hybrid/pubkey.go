@@ -0,0 +1,97 @@
+package hybrid
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Hybrid identifies composite ECDSA + PQC signing keys and opts
+const Hybrid = "HYBRID"
+
+// PQCAlgorithmOID returns the object identifier of a PQC signature algorithm
+func PQCAlgorithmOID(name string) (asn1.ObjectIdentifier, bool) {
+	return core.PQCAlgorithmOID(name)
+}
+
+// HybridPublicKeyImportOpts contains options for importing a composite public key
+// encoded by MarshalPublicKey
+type HybridPublicKeyImportOpts struct {
+	Temporary bool
+}
+
+// Algorithm returns the key importation algorithm identifier (to be used).
+func (opts *HybridPublicKeyImportOpts) Algorithm() string {
+	return Hybrid
+}
+
+// Ephemeral returns true if the key generated has to be ephemeral,
+// false otherwise.
+func (opts *HybridPublicKeyImportOpts) Ephemeral() bool {
+	return opts.Temporary
+}
+
+// MarshalPublicKey encodes the public half of a hybrid key as a DER composite public key
+func MarshalPublicKey(k bccsp.Key) ([]byte, error) {
+	key, ok := k.(*hybridKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid key type, expected *hybridKey")
+	}
+	if len(key.pqcPub) == 0 {
+		return nil, errors.New("PQC public key is empty")
+	}
+
+	ecdsaPub, err := key.ecdsaKey.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ECDSA public key: %w", err)
+	}
+	ecdsaDER, err := ecdsaPub.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ECDSA public key: %w", err)
+	}
+
+	return MarshalCompositePublicKey(ecdsaDER, key.pqcPub)
+}
+
+// PublicKeyJSON is the canonical JSON encoding of a composite public key
+type PublicKeyJSON = core.PublicKeyJSON
+
+// NewPublicKeyJSON describes the public half of a hybrid key in canonical
+// JSON; a zero createdAt is omitted
+func NewPublicKeyJSON(k bccsp.Key, createdAt time.Time) (*PublicKeyJSON, error) {
+	composite, err := MarshalPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewPublicKeyJSON(composite, createdAt)
+}
+
+// MarshalCompositePublicKey encodes an ECDSA SubjectPublicKeyInfo and an
+// ML-DSA public key as a DER composite public key, e.g. from a composite certificate
+func MarshalCompositePublicKey(ecdsaSPKI, pqcPub []byte) ([]byte, error) {
+	return core.MarshalCompositePublicKey(ecdsaSPKI, pqcPub)
+}
+
+// importPublicKey builds a public hybridKey from a composite public key
+func (h *HybridBCCSP) importPublicKey(raw interface{}) (bccsp.Key, error) {
+	der, ok := raw.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid raw material, expected []byte")
+	}
+	ecdsaDER, pqcPub, err := core.ParseCompositePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey, err := h.sw.KeyImport(ecdsaDER, &bccsp.ECDSAPKIXPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import ECDSA public key: %w", err)
+	}
+	return &hybridKey{
+		ecdsaKey: ecdsaKey,
+		pqcPub:   append([]byte(nil), pqcPub...),
+	}, nil
+}
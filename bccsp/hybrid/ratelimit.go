@@ -0,0 +1,105 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited matches every *RateLimitError via errors.Is
+var ErrRateLimited = errors.New("signing rate limit exceeded")
+
+// RateLimitError is returned by Sign when the key's quota is exhausted
+type RateLimitError struct {
+	SKI        []byte
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("signing rate limit exceeded for key %x, retry after %s", e.SKI, e.RetryAfter)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) succeed
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Quota is a token bucket: Rate signatures per second with bursts up to Burst
+type Quota struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter applies a token bucket per signing identity, keyed by SKI
+type RateLimiter struct {
+	mutex   sync.Mutex
+	quota   Quota
+	quotas  map[string]Quota
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a limiter applying quota to every key without an override
+func NewRateLimiter(quota Quota) *RateLimiter {
+	return &RateLimiter{
+		quota:   quota,
+		quotas:  map[string]Quota{},
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// SetQuota overrides the quota of the key with the given SKI
+func (r *RateLimiter) SetQuota(ski []byte, quota Quota) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id := hex.EncodeToString(ski)
+	r.quotas[id] = quota
+	delete(r.buckets, id)
+}
+
+// Allow consumes a token for ski, or returns a *RateLimitError
+func (r *RateLimiter) Allow(ski []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := hex.EncodeToString(ski)
+	quota, ok := r.quotas[id]
+	if !ok {
+		quota = r.quota
+	}
+	// A non-positive rate disables the limit for this key
+	if quota.Rate <= 0 {
+		return nil
+	}
+	burst := float64(quota.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := r.now()
+	b, ok := r.buckets[id]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		r.buckets[id] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * quota.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / quota.Rate * float64(time.Second))
+		return &RateLimitError{SKI: ski, RetryAfter: wait}
+	}
+	b.tokens--
+	return nil
+}
@@ -0,0 +1,65 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(Quota{Rate: 2, Burst: 2})
+	l.now = func() time.Time { return now }
+
+	ski := []byte{0x01}
+	require.NoError(t, l.Allow(ski))
+	require.NoError(t, l.Allow(ski))
+
+	err := l.Allow(ski)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	var rlErr *RateLimitError
+	require.True(t, errors.As(err, &rlErr))
+	assert.Equal(t, 500*time.Millisecond, rlErr.RetryAfter)
+
+	// Other identities have their own bucket
+	require.NoError(t, l.Allow([]byte{0x02}))
+
+	now = now.Add(500 * time.Millisecond)
+	require.NoError(t, l.Allow(ski))
+
+	// Per-identity override, a zero rate lifts the limit
+	l.SetQuota(ski, Quota{})
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.Allow(ski))
+	}
+}
+
+func TestSignRateLimited(t *testing.T) {
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider := &metricsfakes.Provider{}
+	provider.NewCounterReturns(counter)
+	provider.NewGaugeReturns(&metricsfakes.Gauge{})
+
+	csp, err := New(WithRateLimiter(NewRateLimiter(Quota{Rate: 0.001, Burst: 1})), WithMetricsProvider(provider))
+	require.NoError(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("rate limited"))
+
+	_, err = csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	_, err = csp.Sign(k, digest[:], nil)
+	require.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 1, counter.AddCallCount())
+	assert.Equal(t, []string{"namespace", "default", "ski", skiLabel(k.SKI())}, counter.WithArgsForCall(0))
+}
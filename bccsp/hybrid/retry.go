@@ -0,0 +1,135 @@
+package hybrid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// ErrRetryable matches errors worth retrying via errors.Is, see IsRetryable
+var ErrRetryable = errors.New("transient failure")
+
+// RetryableError marks Err as transient, e.g. a KMS answering 503
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrRetryable) succeed
+func (e *RetryableError) Is(target error) bool {
+	return target == ErrRetryable
+}
+
+// Retryable marks err as transient; nil stays nil
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsRetryable reports whether err is transient: marked with Retryable, a
+// timeout, or an I/O or connection error from the OS. Everything else, such
+// as a missing or corrupt key, is fatal.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrRetryable) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.EAGAIN, syscall.EINTR, syscall.EIO, syscall.ETIMEDOUT, syscall.ECONNREFUSED, syscall.ECONNRESET} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy retries transient failures with capped exponential backoff
+// and full jitter. The zero value makes a single attempt.
+type RetryPolicy struct {
+	// Attempts is the maximum number of calls, including the first
+	Attempts int
+	// BaseDelay is the backoff cap of the first retry, doubled at each retry
+	BaseDelay time.Duration
+	// MaxDelay bounds the backoff cap
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy rides out a KMS or Vault restart of a few seconds
+var DefaultRetryPolicy = RetryPolicy{Attempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+// Do calls fn until it succeeds, fails with an error that is not
+// retryable, the attempts are exhausted or ctx is done
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt >= p.Attempts {
+			if attempt > 1 {
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff is a random delay up to min(MaxDelay, BaseDelay*2^(attempt-1))
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || limit < p.MaxDelay); i++ {
+		limit *= 2
+	}
+	if p.MaxDelay > 0 && limit > p.MaxDelay {
+		limit = p.MaxDelay
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+// KeyUnwrapper unwraps data keys, e.g. HybridBCCSP.UnwrapKey or a KMS client
+type KeyUnwrapper interface {
+	UnwrapKey(k bccsp.Key, wrapped []byte) ([]byte, error)
+}
+
+// RetryingUnwrapper retries the transient failures of a KeyUnwrapper. KMS
+// clients should mark throttling and unavailability with Retryable.
+type RetryingUnwrapper struct {
+	Unwrapper KeyUnwrapper
+	Policy    RetryPolicy
+}
+
+// UnwrapKey calls the wrapped unwrapper according to the policy
+func (u *RetryingUnwrapper) UnwrapKey(k bccsp.Key, wrapped []byte) (key []byte, err error) {
+	err = u.Policy.Do(context.Background(), func() (err error) {
+		key, err = u.Unwrapper.UnwrapKey(k, wrapped)
+		return err
+	})
+	return key, err
+}
@@ -0,0 +1,91 @@
+package hybrid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(Retryable(errors.New("kms: 503"))))
+	assert.True(t, IsRetryable(fmt.Errorf("read: %w", syscall.EIO)))
+	assert.True(t, IsRetryable(&fs.PathError{Op: "open", Path: "k", Err: syscall.ETIMEDOUT}))
+	assert.False(t, IsRetryable(fs.ErrNotExist))
+	assert.False(t, IsRetryable(ErrKeyNotFound))
+	assert.Nil(t, Retryable(nil))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return Retryable(errors.New("vault sealed"))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		return Retryable(errors.New("vault sealed"))
+	})
+	require.ErrorIs(t, err, ErrRetryable)
+	assert.Contains(t, err.Error(), "giving up after 3 attempts")
+
+	// Fatal errors are not retried
+	calls = 0
+	err = p.Do(context.Background(), func() error {
+		calls++
+		return ErrKeyNotFound
+	})
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = RetryPolicy{Attempts: 10, BaseDelay: time.Hour}.Do(ctx, func() error {
+		return Retryable(errors.New("vault sealed"))
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, ErrRetryable)
+
+	for attempt := 1; attempt < 10; attempt++ {
+		assert.LessOrEqual(t, p.backoff(attempt), p.MaxDelay)
+	}
+}
+
+type flakyUnwrapper struct {
+	failures int
+}
+
+func (u *flakyUnwrapper) UnwrapKey(bccsp.Key, []byte) ([]byte, error) {
+	if u.failures > 0 {
+		u.failures--
+		return nil, Retryable(errors.New("kms: throttled"))
+	}
+	return []byte("data key"), nil
+}
+
+func TestRetryingUnwrapper(t *testing.T) {
+	u := &RetryingUnwrapper{Unwrapper: &flakyUnwrapper{failures: 2}, Policy: RetryPolicy{Attempts: 3}}
+	key, err := u.UnwrapKey(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data key"), key)
+
+	u = &RetryingUnwrapper{Unwrapper: &flakyUnwrapper{failures: 2}, Policy: RetryPolicy{Attempts: 2}}
+	_, err = u.UnwrapKey(nil, nil)
+	require.ErrorIs(t, err, ErrRetryable)
+}
@@ -0,0 +1,201 @@
+package hybrid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/disabled"
+)
+
+// Defaults of SelfBenchmarkConfig
+const (
+	DefaultSelfBenchmarkInterval = 10 * time.Minute
+	DefaultSelfBenchmarkOps      = 100
+)
+
+// SelfBenchmarkConfig configures a SelfBenchmark
+type SelfBenchmarkConfig struct {
+	// Interval between runs, DefaultSelfBenchmarkInterval if zero
+	Interval time.Duration
+	// Ops is the number of signatures and of verifications of each run,
+	// DefaultSelfBenchmarkOps if zero
+	Ops int
+	// Options configure the throwaway provider, e.g. WithPolicy, so that
+	// the benchmark measures what the node runs
+	Options []Option
+}
+
+// LatencyStats summarizes the latency of one operation in a run
+type LatencyStats struct {
+	Mean time.Duration `json:"mean_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P99  time.Duration `json:"p99_ns"`
+}
+
+// SelfBenchmarkResult is the outcome of one run
+type SelfBenchmarkResult struct {
+	Time   time.Time    `json:"time"`
+	Ops    int          `json:"ops"`
+	Sign   LatencyStats `json:"sign"`
+	Verify LatencyStats `json:"verify"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// SelfBenchmark periodically signs and verifies with a throwaway key and
+// reports the latencies as metrics, so dashboards can track the rate of
+// change of crypto performance, e.g. after a node or liboqs update. It runs
+// its own provider: the node's keys, statistics and metrics are untouched.
+type SelfBenchmark struct {
+	cfg       SelfBenchmarkConfig
+	seconds   metrics.Gauge
+	runs      metrics.Counter
+	timestamp metrics.Gauge
+	now       func() time.Time
+
+	mutex sync.RWMutex
+	last  *SelfBenchmarkResult
+}
+
+// NewSelfBenchmark returns a benchmark reporting through p, which may be nil
+func NewSelfBenchmark(cfg SelfBenchmarkConfig, p metrics.Provider) *SelfBenchmark {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultSelfBenchmarkInterval
+	}
+	if cfg.Ops <= 0 {
+		cfg.Ops = DefaultSelfBenchmarkOps
+	}
+	if p == nil {
+		p = &disabled.Provider{}
+	}
+	return &SelfBenchmark{
+		cfg:       cfg,
+		seconds:   p.NewGauge(selfBenchmarkSecondsOpts),
+		runs:      p.NewCounter(selfBenchmarkRunsOpts),
+		timestamp: p.NewGauge(selfBenchmarkTimestampOpts),
+		now:       time.Now,
+	}
+}
+
+// RunOnce runs the benchmark and records its result
+func (b *SelfBenchmark) RunOnce() (SelfBenchmarkResult, error) {
+	res := SelfBenchmarkResult{Time: b.now(), Ops: b.cfg.Ops}
+	sign, verify, err := b.measure()
+	if err != nil {
+		res.Error = err.Error()
+		b.runs.With("result", "failed").Add(1)
+	} else {
+		res.Sign, res.Verify = summarize(sign), summarize(verify)
+		b.runs.With("result", "ok").Add(1)
+		b.timestamp.Set(float64(res.Time.Unix()))
+		for op, stats := range map[string]LatencyStats{"sign": res.Sign, "verify": res.Verify} {
+			b.seconds.With("operation", op, "statistic", "mean").Set(stats.Mean.Seconds())
+			b.seconds.With("operation", op, "statistic", "p50").Set(stats.P50.Seconds())
+			b.seconds.With("operation", op, "statistic", "p99").Set(stats.P99.Seconds())
+		}
+	}
+	b.mutex.Lock()
+	b.last = &res
+	b.mutex.Unlock()
+	return res, err
+}
+
+// measure returns the latency of every signature and verification
+func (b *SelfBenchmark) measure() ([]time.Duration, []time.Duration, error) {
+	csp, err := New(b.cfg.Options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Close purges the throwaway key
+	defer csp.(*HybridBCCSP).Close()
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("key generation: %w", err)
+	}
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sign := make([]time.Duration, b.cfg.Ops)
+	verify := make([]time.Duration, b.cfg.Ops)
+	for i := range sign {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("self-benchmark %d", i)))
+		start := time.Now()
+		sig, err := csp.Sign(k, digest[:], nil)
+		sign[i] = time.Since(start)
+		if err != nil {
+			return nil, nil, fmt.Errorf("signing: %w", err)
+		}
+		start = time.Now()
+		valid, err := csp.Verify(pub, sig, digest[:], nil)
+		verify[i] = time.Since(start)
+		if err != nil || !valid {
+			return nil, nil, fmt.Errorf("signature does not verify: %v", err)
+		}
+	}
+	return sign, verify, nil
+}
+
+func summarize(latencies []time.Duration) LatencyStats {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	return LatencyStats{
+		Mean: total / time.Duration(len(sorted)),
+		P50:  sorted[len(sorted)/2],
+		P99:  sorted[(len(sorted)*99-1)/100],
+	}
+}
+
+// Run runs the benchmark every interval until ctx is done. Failed runs are
+// counted and kept as the last result; they do not stop the loop.
+func (b *SelfBenchmark) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		b.RunOnce()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Last returns the result of the last run, if any
+func (b *SelfBenchmark) Last() (SelfBenchmarkResult, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if b.last == nil {
+		return SelfBenchmarkResult{}, false
+	}
+	return *b.last, true
+}
+
+// Handler serves the last result as JSON, 503 before the first run or after
+// a failed one
+func (b *SelfBenchmark) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := b.Last()
+		if !ok {
+			http.Error(w, "no self-benchmark run yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if res.Error != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(res)
+	})
+}
@@ -0,0 +1,75 @@
+package hybrid
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfBenchmark(t *testing.T) {
+	seconds := &metricsfakes.Gauge{}
+	seconds.WithReturns(seconds)
+	runs := &metricsfakes.Counter{}
+	runs.WithReturns(runs)
+	provider := &metricsfakes.Provider{}
+	provider.NewGaugeStub = func(o metrics.GaugeOpts) metrics.Gauge {
+		if o.Name == selfBenchmarkSecondsOpts.Name {
+			return seconds
+		}
+		return &metricsfakes.Gauge{}
+	}
+	provider.NewCounterStub = func(o metrics.CounterOpts) metrics.Counter { return runs }
+
+	b := NewSelfBenchmark(SelfBenchmarkConfig{Ops: 10}, provider)
+	handler := b.Handler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/selfbench", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	res, err := b.RunOnce()
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.Ops)
+	for _, stats := range []LatencyStats{res.Sign, res.Verify} {
+		assert.Positive(t, stats.Mean)
+		assert.LessOrEqual(t, stats.P50, stats.P99)
+	}
+	require.Equal(t, 6, seconds.SetCallCount(), "mean, p50 and p99 of sign and verify")
+	require.Equal(t, 1, runs.AddCallCount())
+	assert.Equal(t, []string{"result", "ok"}, runs.WithArgsForCall(0))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/selfbench", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var served SelfBenchmarkResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, res.Sign, served.Sign)
+
+	// A provider that cannot be created fails the run, not the loop
+	ks, err := NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	broken := NewSelfBenchmark(SelfBenchmarkConfig{Ops: 1, Options: []Option{WithKeyStore(ks, "../Org1MSP")}}, nil)
+	_, err = broken.RunOnce()
+	require.Error(t, err)
+	last, ok := broken.Last()
+	require.True(t, ok)
+	assert.NotEmpty(t, last.Error)
+}
+
+func TestSummarize(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(100-i) * time.Millisecond
+	}
+	stats := summarize(latencies)
+	assert.Equal(t, 50500*time.Microsecond, stats.Mean)
+	assert.Equal(t, 51*time.Millisecond, stats.P50)
+	assert.Equal(t, 99*time.Millisecond, stats.P99)
+	assert.Equal(t, LatencyStats{Mean: time.Second, P50: time.Second, P99: time.Second}, summarize([]time.Duration{time.Second}))
+}
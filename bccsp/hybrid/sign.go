@@ -2,22 +2,132 @@
 package hybrid
 
 import (
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/core"
 )
 
+// MaxSignatureSize è la dimensione massima di una firma ibrida ECDSA P-256 + ML-DSA-65
+const MaxSignatureSize = core.MaxSignatureSize
+
+// ErrShortBuffer è restituito da SignInto quando dst non contiene la firma
+var ErrShortBuffer = errors.New("signature buffer too small")
+
+// AppendSigner è implementato dal provider restituito da New. Permette di
+// firmare in buffer del chiamante, senza allocare l'envelope (hot path dell'orderer).
+type AppendSigner interface {
+	AppendSignature(dst []byte, k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error)
+	SignInto(k bccsp.Key, digest, dst []byte, opts bccsp.SignerOpts) (int, error)
+}
+
 // Sign firma un messaggio con la chiave ibrida
 func (h *HybridBCCSP) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	return h.AppendSignature(nil, k, digest, opts)
+}
+
+// SignInto scrive la firma in dst e restituisce i byte scritti. dst di
+// MaxSignatureSize byte basta sempre per le chiavi ibride.
+func (h *HybridBCCSP) SignInto(k bccsp.Key, digest, dst []byte, opts bccsp.SignerOpts) (int, error) {
+	sig, err := h.AppendSignature(dst[:0], k, digest, opts)
+	if err != nil {
+		return 0, err
+	}
+	if len(sig) > len(dst) || (len(sig) > 0 && &sig[0] != &dst[0]) {
+		return 0, fmt.Errorf("%w: need %d bytes, have %d", ErrShortBuffer, len(sig), len(dst))
+	}
+	return len(sig), nil
+}
+
+// AppendSignature aggiunge la firma a dst come Sign. L'envelope viene scritto
+// direttamente in dst; le allocazioni residue sono dentro ECDSA e liboqs.
+func (h *HybridBCCSP) AppendSignature(dst []byte, k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	defer func(start time.Time) {
+		h.stats.sign.observe(start, true, err)
+		h.emitAudit(audit.OpSign, k, "", true, err)
+	}(time.Now())
+	defer func() { err = wrapKeyError(OpSign, k, err) }()
+
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	if err := h.checkDigest(digest, opts); err != nil {
+		return nil, err
+	}
+
+	prof := h.profiler(opts)
+	if lk, ok := k.(*lmsKey); ok {
+		var sig []byte
+		prof.do("sign", AlgorithmLMS, func() { sig, err = h.lmsSign(lk, digest) })
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, sig...), nil
+	}
+
 	key, ok := k.(*hybridKey)
 	if !ok {
 		return nil, fmt.Errorf("invalid key type, expected *hybridKey")
 	}
+	if key.ecdsaKey == nil || key.pqcPriv == nil || !key.Private() {
+		return nil, ErrPublicKeyOnly
+	}
 
-	// PQC signature con gestione errore
-	pqcSig, err := key.pqcPriv.Sign(digest)
+	// Con un framer (o in modalità pura) ML-DSA firma il messaggio incorniciato
+	env := &Envelope{Version: byte(h.envelopeFormat), Modes: ModeClassical | ModePQC}
+	message, err := h.signFrame(digest, opts)
 	if err != nil {
-		return nil, fmt.Errorf("PQC signature failed: %w", err)
+		return nil, err
+	}
+	if message != nil {
+		env.Modes |= ModePure
+	}
+
+	if h.limiter != nil {
+		if err := h.limiter.Allow(key.SKI()); err != nil {
+			h.metrics.SignRateLimited.With(h.keyLabels(key.SKI())...).Add(1)
+			return nil, err
+		}
+	}
+
+	// Il contatore viene persistito prima di firmare
+	if h.usage != nil {
+		n, err := h.usage.Reserve(key.SKI(), h.maxSignatures)
+		if errors.Is(err, ErrKeyExhausted) {
+			h.metrics.SignKeyExhausted.With(h.keyLabels(key.SKI())...).Add(1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		h.metrics.KeySignatures.With(h.keyLabels(key.SKI())...).Set(float64(n))
+	}
+
+	// Entrambe le componenti firmano anche le modalità offerte (anti-downgrade)
+	ecdsaMsg, pqcMsg := env.SignedMessages(digest, message)
+
+	// Firma classica ECDSA
+	var ecdsaSig []byte
+	prof.do("sign", AlgorithmECDSA, func() { ecdsaSig, err = h.ecdsaProvider(key).Sign(key.ecdsaKey, ecdsaMsg, nil) })
+	if err != nil {
+		return nil, wrapError(OpSign, AlgorithmECDSA, skiOf(key), fmt.Errorf("ECDSA signature failed: %w", err))
+	}
+
+	// PQC signature con gestione errore, attraverso il circuit breaker
+	var pqcSig []byte
+	err = h.pqcCall(func() (err error) {
+		prof.do("sign", AlgorithmMLDSA, func() { pqcSig, err = key.pqcPriv.Sign(pqcMsg) })
+		return err
+	})
+	if err != nil {
+		return nil, wrapError(OpSign, AlgorithmMLDSA, skiOf(key), fmt.Errorf("PQC signature failed: %w", err))
+	}
+
+	env.ECDSASignature, env.PQCSignature = ecdsaSig, pqcSig
+	if err := h.envelopeLimits.Check(env); err != nil {
+		return nil, err
 	}
-	
-	return pqcSig, nil
-}
\ No newline at end of file
+	return h.appendEnvelope(dst, env)
+}
@@ -1,40 +1,83 @@
 package hybrid
 
-import (
-	"encoding/binary"
-	"errors"
+import "github.com/yourusername/quantum-ledger/core"
+
+// Envelope versions, see core
+const (
+	EnvelopeV1   = core.EnvelopeV1
+	EnvelopeV2   = core.EnvelopeV2
+	EnvelopeV2LE = core.EnvelopeV2LE
 )
 
-// combineSignatures creates: [4 bytes ECDSA len][ECDSA sig][PQC sig]
-func combineSignatures(ecdsaSig, pqcSig []byte) []byte {
-	lenBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lenBuf, uint32(len(ecdsaSig)))
+// EnvelopeFormat selects the envelope Sign emits
+type EnvelopeFormat = core.EnvelopeFormat
 
-	combined := make([]byte, 0, 4+len(ecdsaSig)+len(pqcSig))
-	combined = append(combined, lenBuf...)
-	combined = append(combined, ecdsaSig...)
-	combined = append(combined, pqcSig...)
+const (
+	FormatStandard     = core.FormatStandard
+	FormatLittleEndian = core.FormatLittleEndian
+)
 
-	return combined
+// ParseEnvelopeFormat parses standard or little-endian (case insensitive)
+func ParseEnvelopeFormat(s string) (EnvelopeFormat, error) {
+	return core.ParseEnvelopeFormat(s)
 }
 
-// parseHybridSignature splits combined signature
-func parseHybridSignature(signature []byte) (ecdsaSig, pqcSig []byte, err error) {
-	if len(signature) < 4 {
-		return nil, nil, errors.New("signature too short")
+// WithEnvelopeFormat selects the envelope layout of new signatures. Verify
+// accepts every layout regardless, the version byte tells them apart.
+func WithEnvelopeFormat(f EnvelopeFormat) Option {
+	return func(h *HybridBCCSP) {
+		h.envelopeFormat = f
 	}
+}
 
-	ecdsaLen := binary.BigEndian.Uint32(signature[:4])
-	if ecdsaLen > uint32(len(signature)-4) {
-		return nil, nil, errors.New("invalid signature format: ECDSA length exceeds signature size")
-	}
+// Modes is the set of signature components a signer offered
+type Modes = core.Modes
 
-	if len(signature) < int(4+ecdsaLen) {
-		return nil, nil, errors.New("invalid signature format")
-	}
+const (
+	ModeClassical = core.ModeClassical
+	ModePQC       = core.ModePQC
+	ModePure      = core.ModePure
+)
+
+// ErrDowngrade is returned when an envelope lacks a component its signer offered
+var ErrDowngrade = core.ErrDowngrade
+
+// Envelope is a parsed hybrid signature
+type Envelope = core.Envelope
+
+// ParseEnvelope accepts v2 envelopes in either byte order and legacy v1 ones,
+// with DefaultEnvelopeLimits
+func ParseEnvelope(signature []byte) (*Envelope, error) {
+	return core.ParseEnvelope(signature)
+}
+
+// ParseEnvelopeLimits is ParseEnvelope with the given limits
+func ParseEnvelopeLimits(signature []byte, l EnvelopeLimits) (*Envelope, error) {
+	return core.ParseEnvelopeLimits(signature, l)
+}
+
+// Errors returned for envelopes that cannot be parsed or built, see core
+var (
+	ErrMalformedEnvelope = core.ErrMalformedEnvelope
+	ErrComponentTooLarge = core.ErrComponentTooLarge
+)
 
-	ecdsaSig = signature[4 : 4+ecdsaLen]
-	pqcSig = signature[4+ecdsaLen:]
+// ComponentSizeError reports a component over its EnvelopeLimits maximum
+type ComponentSizeError = core.ComponentSizeError
 
-	return ecdsaSig, pqcSig, nil
-}
\ No newline at end of file
+// EnvelopeLimits bounds the size of envelope components; zero disables a
+// bound
+type EnvelopeLimits = core.EnvelopeLimits
+
+// DefaultEnvelopeLimits are the limits of providers without
+// WithEnvelopeLimits
+var DefaultEnvelopeLimits = core.DefaultEnvelopeLimits
+
+// WithEnvelopeLimits replaces DefaultEnvelopeLimits. Sign fails rather than
+// emit an envelope over the limits, and Verify rejects one before verifying
+// any component.
+func WithEnvelopeLimits(l EnvelopeLimits) Option {
+	return func(h *HybridBCCSP) {
+		h.envelopeLimits = l
+	}
+}
@@ -0,0 +1,92 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// componentLen draws empty, typical and multi-MB component lengths
+func componentLen() *rapid.Generator[int] {
+	return rapid.OneOf(
+		rapid.Just(0),
+		rapid.IntRange(1, 8<<10),
+		rapid.IntRange(1<<20, 4<<20),
+	)
+}
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func TestPropertyEnvelopeRoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		seed := rapid.Int64().Draw(t, "seed")
+		ecdsaSig := randomBytes(seed, componentLen().Draw(t, "ecdsaLen"))
+		pqcSig := randomBytes(seed+1, componentLen().Draw(t, "pqcLen"))
+		modes := rapid.SampledFrom([]Modes{ModeClassical, ModePQC, ModeClassical | ModePQC}).Draw(t, "modes")
+
+		env := &Envelope{Version: EnvelopeV2, Modes: modes, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}
+		_, err := ParseEnvelope(env.Marshal())
+		tooLarge := len(ecdsaSig) > DefaultEnvelopeLimits.MaxECDSA || len(pqcSig) > DefaultEnvelopeLimits.MaxPQC
+		if tooLarge != errors.Is(err, ErrComponentTooLarge) {
+			t.Fatalf("default limits: components of %d and %d bytes: %v", len(ecdsaSig), len(pqcSig), err)
+		}
+
+		// The layout itself has no limits
+		parsed, err := ParseEnvelopeLimits(env.Marshal(), EnvelopeLimits{})
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		if parsed.Version != EnvelopeV2 || parsed.Modes != modes {
+			t.Fatalf("header mismatch: got version %#x modes %s", parsed.Version, parsed.Modes)
+		}
+		if !bytes.Equal(parsed.ECDSASignature, ecdsaSig) || !bytes.Equal(parsed.PQCSignature, pqcSig) {
+			t.Fatal("component mismatch after round trip")
+		}
+
+		// Legacy v1 layout: the ECDSA length prefix doubles as version byte 0x00
+		if len(ecdsaSig) < 1<<24 {
+			legacy := &Envelope{Version: EnvelopeV1, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}
+			v1, err := ParseEnvelopeLimits(legacy.Marshal(), EnvelopeLimits{})
+			if err != nil {
+				t.Fatalf("legacy parse failed: %v", err)
+			}
+			if !bytes.Equal(v1.ECDSASignature, ecdsaSig) || !bytes.Equal(v1.PQCSignature, pqcSig) {
+				t.Fatal("legacy component mismatch after round trip")
+			}
+		}
+	})
+}
+
+func TestPropertyBitFlipFailsUnderAND(t *testing.T) {
+	h, err := New(WithPolicy(PolicyHybridAND))
+	require.NoError(t, err)
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("bit flips"))
+	signature, err := h.Sign(key, digest[:], nil)
+	require.NoError(t, err)
+
+	rapid.Check(t, func(t *rapid.T) {
+		bit := rapid.IntRange(0, len(signature)*8-1).Draw(t, "bit")
+		flipped := append([]byte(nil), signature...)
+		flipped[bit/8] ^= 1 << (bit % 8)
+
+		valid, _ := h.Verify(pub, flipped, digest[:], nil)
+		if valid {
+			t.Fatalf("flipping bit %d still verifies", bit)
+		}
+	})
+}
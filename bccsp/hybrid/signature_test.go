@@ -0,0 +1,186 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	env := &Envelope{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{1, 2, 3}, PQCSignature: []byte{4, 5}}
+	parsed, err := ParseEnvelope(env.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, env, parsed)
+
+	legacy := (&Envelope{Version: EnvelopeV1, ECDSASignature: []byte{1, 2, 3}, PQCSignature: []byte{4, 5}}).Marshal()
+	parsed, err = ParseEnvelope(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, EnvelopeV1, parsed.Version)
+	assert.Equal(t, []byte{1, 2, 3}, parsed.ECDSASignature)
+
+	_, err = ParseEnvelope([]byte{0x7F, 0, 0, 0, 0})
+	assert.Error(t, err, "unknown version")
+	_, err = ParseEnvelope([]byte{EnvelopeV2, 0x80, 0, 0, 0, 0})
+	assert.Error(t, err, "unknown modes")
+}
+
+func TestDowngradeProtection(t *testing.T) {
+	h, err := New(WithPolicy(PolicyHybridOR))
+	require.NoError(t, err)
+
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("downgrade"))
+	signature, err := h.Sign(key, digest[:], nil)
+	require.NoError(t, err)
+
+	env, err := ParseEnvelope(signature)
+	require.NoError(t, err)
+
+	// Attacker strips the PQC component
+	stripped := (&Envelope{Version: env.Version, Modes: env.Modes, ECDSASignature: env.ECDSASignature}).Marshal()
+	valid, err := h.Verify(key, stripped, digest[:], nil)
+	assert.ErrorIs(t, err, ErrDowngrade)
+	assert.False(t, valid)
+
+	// ... and also rewrites the offered Modes: the ECDSA component no longer matches
+	rewritten := (&Envelope{Version: env.Version, Modes: ModeClassical, ECDSASignature: env.ECDSASignature}).Marshal()
+	valid, err = h.Verify(key, rewritten, digest[:], nil)
+	assert.ErrorIs(t, err, ErrDowngrade)
+	assert.False(t, valid)
+
+	unprotected, err := New(WithPolicy(PolicyHybridOR), WithDowngradeProtection(false))
+	require.NoError(t, err)
+	valid, err = unprotected.Verify(key, stripped, digest[:], nil)
+	assert.NoError(t, err)
+	assert.True(t, valid, "without protection OR accepts the remaining ECDSA component")
+
+	valid, _ = unprotected.Verify(key, rewritten, digest[:], nil)
+	assert.False(t, valid, "modes are covered by the ECDSA signature")
+}
+
+func TestDowngradeToLegacyEnvelope(t *testing.T) {
+	h, err := New(WithPolicy(PolicyHybridOR))
+	require.NoError(t, err)
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("legacy downgrade"))
+
+	// Legacy v1 components sign the digest itself: an attacker holding an
+	// ECDSA-only signature of the PQC-capable key re-encodes it as v1
+	// without the PQC component
+	hk := key.(*hybridKey)
+	ecdsaSig, err := h.(*HybridBCCSP).ecdsaProvider(hk).Sign(hk.ecdsaKey, digest[:], nil)
+	require.NoError(t, err)
+	stripped := (&Envelope{Version: EnvelopeV1, ECDSASignature: ecdsaSig}).Marshal()
+	valid, err := h.Verify(key, stripped, digest[:], nil)
+	assert.ErrorIs(t, err, ErrDowngrade)
+	assert.False(t, valid)
+
+	pqcSig, err := hk.pqcPriv.Sign(digest[:])
+	require.NoError(t, err)
+	legacy := (&Envelope{Version: EnvelopeV1, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}).Marshal()
+	valid, err = h.Verify(key, legacy, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid, "complete legacy envelopes still verify")
+
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+	composite, err := MarshalPublicKey(pub)
+	require.NoError(t, err)
+	corePub, err := core.ParsePublicKey(composite)
+	require.NoError(t, err)
+	valid, err = corePub.Verify(digest[:], stripped, core.PolicyHybridOR)
+	assert.ErrorIs(t, err, core.ErrDowngrade)
+	assert.False(t, valid)
+}
+
+func TestLittleEndianEnvelope(t *testing.T) {
+	env := &Envelope{Version: EnvelopeV2LE, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{1, 2, 3}, PQCSignature: []byte{4, 5}}
+	raw := env.Marshal()
+	assert.Equal(t, []byte{EnvelopeV2LE, byte(ModeClassical | ModePQC), 3, 0, 0, 0}, raw[:6])
+	parsed, err := ParseEnvelope(raw)
+	require.NoError(t, err)
+	assert.Equal(t, env, parsed)
+
+	legacy, err := New(WithEnvelopeFormat(FormatLittleEndian))
+	require.NoError(t, err)
+	standard, err := New()
+	require.NoError(t, err)
+	key, err := legacy.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("little-endian"))
+	signature, err := legacy.Sign(key, digest[:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, EnvelopeV2LE, signature[0])
+
+	valid, err := standard.Verify(key, signature, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// The version byte is signed, relabelling the layout breaks the signature
+	relabelled, err := ParseEnvelope(signature)
+	require.NoError(t, err)
+	relabelled.Version = EnvelopeV2
+	valid, _ = standard.Verify(key, relabelled.Marshal(), digest[:], nil)
+	assert.False(t, valid)
+
+	f, err := ParseEnvelopeFormat("Little-Endian")
+	require.NoError(t, err)
+	assert.Equal(t, FormatLittleEndian, f)
+	_, err = New(WithEnvelopeFormat(EnvelopeFormat(EnvelopeV1)))
+	assert.Error(t, err)
+}
+
+func TestCoreSignatureVerifies(t *testing.T) {
+	priv, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	der, err := priv.Public().Marshal()
+	require.NoError(t, err)
+
+	h, err := New()
+	require.NoError(t, err)
+	pub, err := h.KeyImport(der, &HybridPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("core"))
+	signature, err := priv.Sign(digest[:])
+	require.NoError(t, err)
+	valid, err := h.Verify(pub, signature, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestEnvelopeLimitsEnforced(t *testing.T) {
+	tight, err := New(WithEnvelopeLimits(EnvelopeLimits{MaxPQC: 1024}))
+	require.NoError(t, err)
+	k, err := tight.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("limits"))
+	_, err = tight.Sign(k, digest[:], nil)
+	var sizeErr *ComponentSizeError
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, "PQC", sizeErr.Component)
+
+	h, err := New()
+	require.NoError(t, err)
+	sig, err := h.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	valid, err := h.Verify(k, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	_, err = tight.Verify(k, sig, digest[:], nil)
+	assert.ErrorIs(t, err, ErrComponentTooLarge)
+
+	// A giant PQC segment is rejected before verification
+	env, err := ParseEnvelope(sig)
+	require.NoError(t, err)
+	env.PQCSignature = make([]byte, 1<<20)
+	_, err = h.Verify(k, env.Marshal(), digest[:], nil)
+	assert.ErrorIs(t, err, ErrComponentTooLarge)
+}
@@ -0,0 +1,116 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Signer and Verifier are the core interfaces, for code that signs with
+// either a BCCSP key or a core key. Services without Fabric import core
+// directly; core.PrivateKey and core.PublicKey implement them.
+type (
+	Signer   = core.Signer
+	Verifier = core.Verifier
+)
+
+// keySigner is a Signer over a private key of a provider
+type keySigner struct {
+	csp  bccsp.BCCSP
+	key  bccsp.Key
+	pub  *core.PublicKey
+	opts bccsp.SignerOpts
+}
+
+// NewSigner adapts k, a hybrid private key of csp, to Signer. opts are
+// passed to every Sign, e.g. a HybridSignerOpts with the channel, and may
+// be nil.
+func NewSigner(csp bccsp.BCCSP, k bccsp.Key, opts bccsp.SignerOpts) (Signer, error) {
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	if !k.Private() {
+		return nil, errors.New("signer requires a private key")
+	}
+	pub, err := publicKeyOf(k)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{csp: csp, key: k, pub: pub, opts: opts}, nil
+}
+
+func (s *keySigner) Sign(digest []byte) ([]byte, error) {
+	return s.csp.Sign(s.key, digest, s.opts)
+}
+
+func (s *keySigner) Public() *core.PublicKey {
+	return s.pub
+}
+
+// keyVerifier is a Verifier over a key of a provider
+type keyVerifier struct {
+	csp  bccsp.BCCSP
+	key  bccsp.Key
+	opts HybridSignerOpts
+}
+
+// NewVerifier adapts k, a hybrid key of csp, to Verifier. The policy given
+// to Verify overrides the provider's channel policies; opts, which may be
+// nil, supply the channel, MSP and framer of every verification.
+func NewVerifier(csp bccsp.BCCSP, k bccsp.Key, opts *HybridSignerOpts) (Verifier, error) {
+	if isNilKey(k) {
+		return nil, ErrNilKey
+	}
+	v := &keyVerifier{csp: csp, key: k}
+	if opts != nil {
+		v.opts = *opts
+	}
+	return v, nil
+}
+
+func (v *keyVerifier) Verify(digest, signature []byte, policy Policy) (bool, error) {
+	opts := v.opts
+	opts.Policy = &policy
+	return v.csp.Verify(v.key, signature, digest, &opts)
+}
+
+// ImportPrivateKey imports k into csp, e.g. to hand a key generated by a
+// service without Fabric to an MSP. Temporary keys are not stored in the
+// keystore.
+func ImportPrivateKey(csp bccsp.BCCSP, k *core.PrivateKey, temporary bool) (bccsp.Key, error) {
+	if k == nil || k.ECDSA == nil || k.PQC == nil {
+		return nil, errors.New("incomplete hybrid private key")
+	}
+	return csp.KeyImport(&HybridPrivateKey{
+		ECDSA:         k.ECDSA,
+		PQCPrivateKey: k.PQC.PrivateKey(),
+		PQCPublicKey:  k.PQC.PublicKey(),
+	}, &HybridPrivateKeyImportOpts{Temporary: temporary})
+}
+
+// ImportPublicKey imports pub into csp as a temporary key
+func ImportPublicKey(csp bccsp.BCCSP, pub *core.PublicKey) (bccsp.Key, error) {
+	if pub == nil || pub.ECDSA == nil {
+		return nil, errors.New("incomplete hybrid public key")
+	}
+	der, err := pub.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return csp.KeyImport(der, &HybridPublicKeyImportOpts{Temporary: true})
+}
+
+// publicKeyOf returns the public half of a hybrid key as a core key
+func publicKeyOf(k bccsp.Key) (*core.PublicKey, error) {
+	der, err := MarshalPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := core.ParsePublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hybrid public key: %w", err)
+	}
+	return pub, nil
+}
@@ -0,0 +1,65 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestSignerVerifier(t *testing.T) {
+	h, err := New()
+	require.NoError(t, err)
+	key, err := h.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("order #42"))
+
+	// A BCCSP key signs for a service that only knows core
+	signer, err := NewSigner(h, key, nil)
+	require.NoError(t, err)
+	sig, err := signer.Sign(digest[:])
+	require.NoError(t, err)
+	var verifier Verifier = signer.Public()
+	valid, err := verifier.Verify(digest[:], sig, PolicyHybridAND)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	pub, err := key.PublicKey()
+	require.NoError(t, err)
+	_, err = NewSigner(h, pub, nil)
+	assert.Error(t, err)
+	_, err = NewSigner(h, nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	// and a core key signs for a provider
+	coreKey, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer coreKey.Clean()
+	sig, err = coreKey.Sign(digest[:])
+	require.NoError(t, err)
+	imported, err := ImportPublicKey(h, coreKey.Public())
+	require.NoError(t, err)
+	verifier, err = NewVerifier(h, imported, &HybridSignerOpts{Channel: "mychannel"})
+	require.NoError(t, err)
+	for _, policy := range []Policy{PolicyHybridAND, PolicyClassical, PolicyPQC} {
+		valid, err := verifier.Verify(digest[:], sig, policy)
+		require.NoError(t, err)
+		assert.True(t, valid, policy.String())
+	}
+	valid, _ = verifier.Verify(digest[:], sig[:len(sig)-1], PolicyHybridAND)
+	assert.False(t, valid)
+
+	importedPriv, err := ImportPrivateKey(h, coreKey, true)
+	require.NoError(t, err)
+	signer, err = NewSigner(h, importedPriv, nil)
+	require.NoError(t, err)
+	assert.Equal(t, coreKey.Public().PQC, signer.Public().PQC)
+	sig, err = signer.Sign(digest[:])
+	require.NoError(t, err)
+	valid, err = coreKey.Public().Verify(digest[:], sig, PolicyHybridAND)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
@@ -0,0 +1,113 @@
+package hybrid
+
+import (
+	"sync"
+	"time"
+)
+
+// StatsProvider is implemented by the provider returned by New
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// Stats summarizes the activity of a provider since it was created, for
+// applications that do not scrape metrics
+type Stats struct {
+	Since  time.Time
+	KeyGen OperationStats
+	Sign   OperationStats
+	Verify OperationStats
+	// VerifierCache is zero without a verifier cache
+	VerifierCache CacheStats
+	// Canary is zero without WithCanary
+	Canary CanaryStats
+}
+
+// OperationStats counts the calls of one operation and their latency
+type OperationStats struct {
+	Count uint64
+	// Failures are calls that returned an error
+	Failures uint64
+	// Invalid are verifications that rejected the signature without error
+	Invalid     uint64
+	MeanLatency time.Duration
+	MaxLatency  time.Duration
+}
+
+// CacheStats counts the lookups of a verifier cache
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// HitRatio is Hits / (Hits + Misses), 0 before the first lookup
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// operationStats accumulates OperationStats
+type operationStats struct {
+	mutex    sync.Mutex
+	count    uint64
+	failures uint64
+	invalid  uint64
+	total    time.Duration
+	max      time.Duration
+}
+
+// observe records a call that started at start
+func (s *operationStats) observe(start time.Time, valid bool, err error) {
+	d := time.Since(start)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.count++
+	switch {
+	case err != nil:
+		s.failures++
+	case !valid:
+		s.invalid++
+	}
+	s.total += d
+	if d > s.max {
+		s.max = d
+	}
+}
+
+func (s *operationStats) snapshot() OperationStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	out := OperationStats{Count: s.count, Failures: s.failures, Invalid: s.invalid, MaxLatency: s.max}
+	if s.count > 0 {
+		out.MeanLatency = s.total / time.Duration(s.count)
+	}
+	return out
+}
+
+// providerStats are the accumulators of a provider
+type providerStats struct {
+	since  time.Time
+	keyGen operationStats
+	sign   operationStats
+	verify operationStats
+}
+
+// Stats returns the counters and latencies since the provider was created
+func (h *HybridBCCSP) Stats() Stats {
+	s := Stats{
+		Since:  h.stats.since,
+		KeyGen: h.stats.keyGen.snapshot(),
+		Sign:   h.stats.sign.snapshot(),
+		Verify: h.stats.verify.snapshot(),
+	}
+	if h.verifiers != nil {
+		s.VerifierCache = h.verifiers.Stats()
+	}
+	if h.canary != nil {
+		s.Canary = CanaryStats{Checks: h.canary.checkCount.Load(), Discrepancies: h.canary.discrepancyCount.Load()}
+	}
+	return s
+}
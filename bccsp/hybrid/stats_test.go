@@ -0,0 +1,48 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	csp, err := New(WithVerifierCache(NewVerifierCache(10, nil)))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("stats"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	_, err = csp.Sign(k, digest[:10], nil)
+	require.Error(t, err)
+	for i := 0; i < 3; i++ {
+		valid, err := csp.Verify(pub, sig, digest[:], nil)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+	other := sha256.Sum256([]byte("other"))
+	valid, err := csp.Verify(pub, sig, other[:], nil)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	stats := csp.(StatsProvider).Stats()
+	assert.False(t, stats.Since.IsZero())
+	assert.Equal(t, uint64(1), stats.KeyGen.Count)
+	assert.Equal(t, uint64(2), stats.Sign.Count)
+	assert.Equal(t, uint64(1), stats.Sign.Failures)
+	assert.Equal(t, uint64(4), stats.Verify.Count)
+	assert.Equal(t, uint64(1), stats.Verify.Invalid)
+	assert.Positive(t, stats.Verify.MeanLatency)
+	assert.GreaterOrEqual(t, stats.Verify.MaxLatency, stats.Verify.MeanLatency)
+
+	// The invalid signature fails on ECDSA before the PQC verifier is looked up
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, stats.VerifierCache)
+	assert.InDelta(t, 2.0/3, stats.VerifierCache.HitRatio(), 1e-9)
+}
@@ -0,0 +1,181 @@
+// Package testvectors publishes canonical composite keys and hybrid signatures
+// for every supported algorithm pair, so verifiers written in other languages
+// can be checked against this implementation.
+package testvectors
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+//go:embed testdata/vectors/*.json
+var files embed.FS
+
+// Vector holds a composite public key and the signature cases checked against it
+type Vector struct {
+	Name               string `json:"name"`
+	ClassicalAlgorithm string `json:"classical_algorithm"`
+	PQCAlgorithm       string `json:"pqc_algorithm"`
+	// PublicKey is the hex DER composite public key (see hybrid.MarshalPublicKey)
+	PublicKey string `json:"public_key"`
+	Cases     []Case `json:"cases"`
+}
+
+// Case is a single verification with its expected outcome. All byte fields are hex.
+type Case struct {
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+	Digest    string `json:"digest"`
+	Signature string `json:"signature"`
+	Policy    string `json:"policy"`
+	Valid     bool   `json:"valid"`
+	// Error names the expected verification error class, if any
+	Error string `json:"error,omitempty"`
+}
+
+// ErrorDowngrade marks cases that must fail with hybrid.ErrDowngrade
+const ErrorDowngrade = "downgrade"
+
+// suite is a supported ECDSA curve paired with the PQC algorithm
+type suite struct {
+	name      string
+	classical string
+	opts      bccsp.KeyGenOpts
+}
+
+var suites = []suite{
+	{name: "ecdsa-p256_ml-dsa-65", classical: "ECDSA-P256", opts: &bccsp.ECDSAP256KeyGenOpts{Temporary: true}},
+	{name: "ecdsa-p384_ml-dsa-65", classical: "ECDSA-P384", opts: &bccsp.ECDSAP384KeyGenOpts{Temporary: true}},
+}
+
+// All returns the embedded vectors sorted by name
+func All() ([]Vector, error) {
+	entries, err := files.ReadDir("testdata/vectors")
+	if err != nil {
+		return nil, err
+	}
+	var vectors []Vector
+	for _, entry := range entries {
+		raw, err := files.ReadFile(path.Join("testdata/vectors", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var v Vector
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("invalid vector file %s: %w", entry.Name(), err)
+		}
+		vectors = append(vectors, v)
+	}
+	sort.Slice(vectors, func(i, j int) bool { return vectors[i].Name < vectors[j].Name })
+	return vectors, nil
+}
+
+// Generate creates fresh keys and signatures for every supported algorithm pair
+func Generate() ([]Vector, error) {
+	csp, err := hybrid.New()
+	if err != nil {
+		return nil, err
+	}
+	var vectors []Vector
+	for _, s := range suites {
+		v, err := generate(csp, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", s.name, err)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+func generate(csp bccsp.BCCSP, s suite) (Vector, error) {
+	key, err := csp.KeyGen(s.opts)
+	if err != nil {
+		return Vector{}, err
+	}
+	pub, err := hybrid.MarshalPublicKey(key)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	message := []byte("quantum-ledger canonical test vector: " + s.name)
+	digest := sha256.Sum256(message)
+	signature, err := csp.Sign(key, digest[:], nil)
+	if err != nil {
+		return Vector{}, err
+	}
+	env, err := hybrid.ParseEnvelope(signature)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	tamperedDigest := append([]byte(nil), digest[:]...)
+	tamperedDigest[0] ^= 0x01
+	tamperedECDSA := withComponents(env, flipLast(env.ECDSASignature), env.PQCSignature)
+	tamperedPQC := withComponents(env, env.ECDSASignature, flipLast(env.PQCSignature))
+	strippedPQC := withComponents(env, env.ECDSASignature, nil)
+
+	newCase := func(name string, d, sig []byte, policy hybrid.Policy, valid bool, errClass string) Case {
+		return Case{
+			Name:      name,
+			Message:   hex.EncodeToString(message),
+			Digest:    hex.EncodeToString(d),
+			Signature: hex.EncodeToString(sig),
+			Policy:    policy.String(),
+			Valid:     valid,
+			Error:     errClass,
+		}
+	}
+
+	return Vector{
+		Name:               s.name,
+		ClassicalAlgorithm: s.classical,
+		PQCAlgorithm:       hybrid.PQCAlgorithm,
+		PublicKey:          hex.EncodeToString(pub),
+		Cases: []Case{
+			newCase("valid", digest[:], signature, hybrid.PolicyHybridAND, true, ""),
+			newCase("tampered_digest", tamperedDigest, signature, hybrid.PolicyHybridAND, false, ""),
+			newCase("tampered_ecdsa_and", digest[:], tamperedECDSA, hybrid.PolicyHybridAND, false, ""),
+			newCase("tampered_ecdsa_pqc_only", digest[:], tamperedECDSA, hybrid.PolicyPQC, true, ""),
+			newCase("tampered_pqc_and", digest[:], tamperedPQC, hybrid.PolicyHybridAND, false, ""),
+			newCase("tampered_pqc_or", digest[:], tamperedPQC, hybrid.PolicyHybridOR, true, ""),
+			newCase("stripped_pqc_or", digest[:], strippedPQC, hybrid.PolicyHybridOR, false, ErrorDowngrade),
+		},
+	}, nil
+}
+
+func withComponents(env *hybrid.Envelope, ecdsaSig, pqcSig []byte) []byte {
+	return (&hybrid.Envelope{Version: env.Version, Modes: env.Modes, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}).Marshal()
+}
+
+func flipLast(b []byte) []byte {
+	out := append([]byte(nil), b...)
+	out[len(out)-1] ^= 0x01
+	return out
+}
+
+// Write stores one indented JSON file per vector in dir
+func Write(dir string, vectors []Vector) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, v := range vectors {
+		raw, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, v.Name+".json"), append(raw, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
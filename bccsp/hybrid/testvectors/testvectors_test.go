@@ -0,0 +1,44 @@
+package testvectors
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func TestEmbeddedVectors(t *testing.T) {
+	vectors, err := All()
+	require.NoError(t, err)
+	require.Len(t, vectors, len(suites), "one vector file per algorithm pair")
+
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+
+	for _, v := range vectors {
+		pub, err := hex.DecodeString(v.PublicKey)
+		require.NoError(t, err)
+		key, err := csp.KeyImport(pub, &hybrid.HybridPublicKeyImportOpts{Temporary: true})
+		require.NoError(t, err, v.Name)
+
+		for _, c := range v.Cases {
+			t.Run(v.Name+"/"+c.Name, func(t *testing.T) {
+				digest, err := hex.DecodeString(c.Digest)
+				require.NoError(t, err)
+				signature, err := hex.DecodeString(c.Signature)
+				require.NoError(t, err)
+				policy, err := hybrid.ParsePolicy(c.Policy)
+				require.NoError(t, err)
+
+				valid, err := csp.Verify(key, signature, digest, &hybrid.HybridSignerOpts{Policy: &policy})
+				assert.Equal(t, c.Valid, valid)
+				if c.Error == ErrorDowngrade {
+					assert.True(t, errors.Is(err, hybrid.ErrDowngrade), "expected downgrade error, got %v", err)
+				}
+			})
+		}
+	}
+}
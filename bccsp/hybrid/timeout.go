@@ -0,0 +1,47 @@
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrVerifyTimeout is returned by Verify when the PQC verification of a
+// signature takes longer than the timeout set with WithVerifyTimeout
+var ErrVerifyTimeout = errors.New("PQC verification timed out")
+
+// WithVerifyTimeout bounds the wall time of the PQC verification of each
+// Verify call, so that a pathological signature cannot stall block
+// validation. Zero, the default, disables the bound.
+func WithVerifyTimeout(d time.Duration) Option {
+	return func(h *HybridBCCSP) {
+		h.verifyTimeout = d
+	}
+}
+
+// timeBoxed runs verify, giving up after the verify timeout. The backend
+// cannot be interrupted: an abandoned verification completes in the
+// background and its result is discarded.
+func (h *HybridBCCSP) timeBoxed(verify func() (bool, error)) (bool, error) {
+	if h.verifyTimeout <= 0 {
+		return verify()
+	}
+	type result struct {
+		valid bool
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		valid, err := verify()
+		done <- result{valid, err}
+	}()
+	timer := time.NewTimer(h.verifyTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.valid, r.err
+	case <-timer.C:
+		h.metrics.VerifyTimeouts.Add(1)
+		return false, fmt.Errorf("%w after %s", ErrVerifyTimeout, h.verifyTimeout)
+	}
+}
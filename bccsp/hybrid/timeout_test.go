@@ -0,0 +1,56 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyTimeout(t *testing.T) {
+	counter := &metricsfakes.Counter{}
+	counter.WithReturns(counter)
+	provider := &metricsfakes.Provider{}
+	provider.NewCounterReturns(counter)
+	provider.NewGaugeReturns(&metricsfakes.Gauge{})
+
+	csp, err := New(WithVerifyTimeout(20*time.Millisecond), WithMetricsProvider(provider))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err = h.timeBoxed(func() (bool, error) {
+		<-release
+		return true, nil
+	})
+	require.ErrorIs(t, err, ErrVerifyTimeout)
+	assert.Equal(t, 1, counter.AddCallCount())
+
+	valid, err := h.timeBoxed(func() (bool, error) { return true, nil })
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, 1, counter.AddCallCount())
+}
+
+func TestVerifyWithinTimeout(t *testing.T) {
+	csp, err := New(WithVerifyTimeout(time.Minute))
+	require.NoError(t, err)
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("time-boxed"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+
+	valid, err := csp.Verify(k, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	digest[0] ^= 1
+	valid, err = csp.Verify(k, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
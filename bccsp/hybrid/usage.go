@@ -0,0 +1,190 @@
+package hybrid
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// usageSuffix names usage counter files next to the keystore's key files
+const usageSuffix = "_usage"
+
+// ErrKeyExhausted matches every *KeyExhaustedError via errors.Is
+var ErrKeyExhausted = errors.New("key reached its maximum number of signatures")
+
+// KeyExhaustedError is returned by Sign once a key has produced Max signatures
+type KeyExhaustedError struct {
+	SKI []byte
+	Max uint64
+}
+
+func (e *KeyExhaustedError) Error() string {
+	return fmt.Sprintf("key %x reached its maximum of %d signatures", e.SKI, e.Max)
+}
+
+// Is makes errors.Is(err, ErrKeyExhausted) succeed
+func (e *KeyExhaustedError) Is(target error) bool {
+	return target == ErrKeyExhausted
+}
+
+// UsageStore tracks how many signatures each key produced
+type UsageStore interface {
+	// Reserve increments the counter of ski and returns the new value. The
+	// increment is durable before Reserve returns, so a crash can only lose
+	// a reserved signature, never reuse one. With max > 0 it fails with a
+	// *KeyExhaustedError instead of going beyond max.
+	Reserve(ski []byte, max uint64) (uint64, error)
+	// Count returns the number of signatures produced by ski
+	Count(ski []byte) (uint64, error)
+}
+
+// MemoryUsageStore keeps counters in memory. They are lost on restart, so
+// LMS keys refuse it.
+type MemoryUsageStore struct {
+	mutex  sync.Mutex
+	counts map[string]uint64
+}
+
+// NewMemoryUsageStore returns an empty in-memory store
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{counts: map[string]uint64{}}
+}
+
+// Reserve implements UsageStore
+func (s *MemoryUsageStore) Reserve(ski []byte, max uint64) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := hex.EncodeToString(ski)
+	if max > 0 && s.counts[id] >= max {
+		return s.counts[id], &KeyExhaustedError{SKI: ski, Max: max}
+	}
+	s.counts[id]++
+	return s.counts[id], nil
+}
+
+// Count implements UsageStore
+func (s *MemoryUsageStore) Count(ski []byte) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.counts[hex.EncodeToString(ski)], nil
+}
+
+// FileUsageStore persists one counter file per key, named <hex ski>_usage,
+// in the keystore directory
+type FileUsageStore struct {
+	mutex sync.Mutex
+	dir   string
+}
+
+// NewFileUsageStore stores counters in dir, creating it if needed
+func NewFileUsageStore(dir string) (*FileUsageStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory %s: %w", dir, err)
+	}
+	return &FileUsageStore{dir: dir}, nil
+}
+
+func (s *FileUsageStore) path(ski []byte) string {
+	return filepath.Join(s.dir, hex.EncodeToString(ski)+usageSuffix)
+}
+
+func (s *FileUsageStore) read(ski []byte) (uint64, error) {
+	raw, err := os.ReadFile(s.path(ski))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("corrupted usage counter %s: %w", s.path(ski), err)
+	}
+	return n, nil
+}
+
+// Reserve implements UsageStore. The counter is written to a temporary file,
+// synced and renamed over the previous one.
+func (s *FileUsageStore) Reserve(ski []byte, max uint64) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n, err := s.read(ski)
+	if err != nil {
+		return 0, err
+	}
+	if max > 0 && n >= max {
+		return n, &KeyExhaustedError{SKI: ski, Max: max}
+	}
+	n++
+	if err := writeFileSync(s.path(ski), []byte(strconv.FormatUint(n, 10)+"\n")); err != nil {
+		return 0, fmt.Errorf("failed to persist usage counter: %w", err)
+	}
+	return n, nil
+}
+
+// Count implements UsageStore
+func (s *FileUsageStore) Count(ski []byte) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.read(ski)
+}
+
+// Counts returns the counters of every key in the keystore, keyed by hex SKI
+func (s *FileUsageStore) Counts() (map[string]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]uint64{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), usageSuffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		ski, err := hex.DecodeString(id)
+		if err != nil {
+			continue
+		}
+		n, err := s.Count(ski)
+		if err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, nil
+}
+
+// writeFileSync atomically replaces path with data
+func writeFileSync(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,52 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileUsageStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileUsageStore(dir)
+	require.NoError(t, err)
+
+	ski := []byte{0xca, 0xfe}
+	for i := uint64(1); i <= 3; i++ {
+		n, err := s.Reserve(ski, 3)
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	_, err = s.Reserve(ski, 3)
+	require.ErrorIs(t, err, ErrKeyExhausted)
+
+	// Counters survive a restart
+	s, err = NewFileUsageStore(dir)
+	require.NoError(t, err)
+	n, err := s.Count(ski)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), n)
+
+	counts, err := s.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"cafe": 3}, counts)
+}
+
+func TestSignMaxSignatures(t *testing.T) {
+	csp, err := New(WithMaxSignatures(2))
+	require.NoError(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("counted"))
+
+	for i := 0; i < 2; i++ {
+		_, err = csp.Sign(k, digest[:], nil)
+		require.NoError(t, err)
+	}
+	_, err = csp.Sign(k, digest[:], nil)
+	require.ErrorIs(t, err, ErrKeyExhausted)
+}
@@ -0,0 +1,127 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/lms"
+)
+
+func TestInvalidArguments(t *testing.T) {
+	store, err := NewFileUsageStore(t.TempDir())
+	require.NoError(t, err)
+	csp, err := New(WithUsageStore(store))
+	require.NoError(t, err)
+	h := csp.(*HybridBCCSP)
+
+	priv, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+	kemPriv, err := csp.KeyGen(&HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	kemPub, err := kemPriv.PublicKey()
+	require.NoError(t, err)
+	lmsPriv, err := csp.KeyGen(&LMSKeyGenOpts{Type: lms.LMS_SHA256_M32_H5, Temporary: true})
+	require.NoError(t, err)
+	lmsPub, err := lmsPriv.PublicKey()
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := csp.Sign(priv, digest[:], nil)
+	require.NoError(t, err)
+	var nilHybrid *hybridKey
+	var nilLMS *lmsKey
+	var nilKEM *hybridKEMKey
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"KeyGen nil opts", func() error { _, err := csp.KeyGen(nil); return err }, ErrNilOpts},
+		{"KeyGen typed nil opts", func() error { _, err := csp.KeyGen((*LMSKeyGenOpts)(nil)); return err }, ErrNilOpts},
+		{"KeyDeriv nil key", func() error { _, err := csp.KeyDeriv(nil, &bccsp.ECDSAReRandKeyOpts{}); return err }, ErrNilKey},
+		{"KeyDeriv nil opts", func() error { _, err := csp.KeyDeriv(priv, nil); return err }, ErrNilOpts},
+		{"KeyImport nil opts", func() error { _, err := csp.KeyImport([]byte{1}, nil); return err }, ErrNilOpts},
+		{"KeyImport nil raw", func() error { _, err := csp.KeyImport(nil, &HybridPublicKeyImportOpts{}); return err }, ErrNilKeyMaterial},
+		{"KeyImport typed nil raw", func() error {
+			_, err := csp.KeyImport((*HybridPrivateKey)(nil), &HybridPrivateKeyImportOpts{})
+			return err
+		}, ErrNilKeyMaterial},
+		{"GetKey empty SKI", func() error { _, err := csp.GetKey(nil); return err }, ErrEmptySKI},
+		{"Hash nil opts", func() error { _, err := csp.Hash([]byte("m"), nil); return err }, ErrNilOpts},
+		{"GetHash nil opts", func() error { _, err := csp.GetHash(nil); return err }, ErrNilOpts},
+		{"Sign nil key", func() error { _, err := csp.Sign(nil, digest[:], nil); return err }, ErrNilKey},
+		{"Sign typed nil key", func() error { _, err := csp.Sign(nilHybrid, digest[:], nil); return err }, ErrNilKey},
+		{"Sign typed nil LMS key", func() error { _, err := csp.Sign(nilLMS, digest[:], nil); return err }, ErrNilKey},
+		{"Sign nil digest", func() error { _, err := csp.Sign(priv, nil, nil); return err }, ErrInvalidDigest},
+		{"Sign public key", func() error { _, err := csp.Sign(pub, digest[:], nil); return err }, ErrPublicKeyOnly},
+		{"Sign key without parts", func() error { _, err := csp.Sign(&hybridKey{}, digest[:], nil); return err }, ErrPublicKeyOnly},
+		{"Sign LMS public key", func() error { _, err := csp.Sign(lmsPub, digest[:], nil); return err }, ErrPublicKeyOnly},
+		{"Verify nil key", func() error { _, err := csp.Verify(nil, sig, digest[:], nil); return err }, ErrNilKey},
+		{"Verify typed nil key", func() error { _, err := csp.Verify(nilHybrid, sig, digest[:], nil); return err }, ErrNilKey},
+		{"Verify empty signature", func() error { _, err := csp.Verify(pub, nil, digest[:], nil); return err }, ErrEmptySignature},
+		{"Verify nil digest", func() error { _, err := csp.Verify(pub, sig, nil, nil); return err }, ErrInvalidDigest},
+		{"Encrypt nil opts", func() error { _, err := csp.Encrypt(kemPub, []byte("m"), nil); return err }, ErrNilOpts},
+		{"Encrypt nil key", func() error { _, err := csp.Encrypt(nil, []byte("m"), &HybridKEMEncrypterOpts{}); return err }, ErrNilKey},
+		{"Encrypt typed nil key", func() error { _, err := csp.Encrypt(nilKEM, []byte("m"), &HybridKEMEncrypterOpts{}); return err }, ErrNilKey},
+		{"Encrypt nil key without KEM", func() error { _, err := csp.Encrypt(nil, []byte("m"), &bccsp.AESCBCPKCS7ModeOpts{}); return err }, ErrNilKey},
+		{"Decrypt nil key", func() error { _, err := csp.Decrypt(nil, []byte("c"), &HybridKEMDecrypterOpts{}); return err }, ErrNilKey},
+		{"Decrypt nil opts", func() error { _, err := csp.Decrypt(kemPriv, []byte("c"), nil); return err }, ErrNilOpts},
+		{"Decrypt public key", func() error {
+			_, err := csp.Decrypt(kemPub, []byte{kemCiphertextVersion}, &HybridKEMDecrypterOpts{})
+			return err
+		}, ErrPublicKeyOnly},
+		{"WrapKey nil key", func() error { _, err := h.WrapKey(nil, make([]byte, 32)); return err }, ErrNilKey},
+		{"UnwrapKey nil key", func() error { _, err := h.UnwrapKey(nilKEM, []byte("w")); return err }, ErrNilKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { err = tt.call() })
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.want), "got %v, want %v", err, tt.want)
+			var ce *CryptoError
+			require.True(t, errors.As(err, &ce), "%v is not a *CryptoError", err)
+		})
+	}
+}
+
+func TestCryptoError(t *testing.T) {
+	csp, err := New()
+	require.NoError(t, err)
+	priv, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	pub, err := priv.PublicKey()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := csp.Sign(priv, digest[:], nil)
+	require.NoError(t, err)
+
+	_, err = csp.Verify(pub, []byte("garbage"), digest[:], nil)
+	var ce *CryptoError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, OpVerify, ce.Op)
+	assert.Equal(t, AlgorithmHybrid, ce.Algorithm)
+	assert.Equal(t, pub.SKI(), ce.SKI)
+	assert.Contains(t, err.Error(), "hybrid verify "+AlgorithmHybrid+" key "+hex.EncodeToString(pub.SKI()[:skiLogBytes])+"...: ")
+
+	// The failing component is named, not the hybrid algorithm
+	noPQC := &hybridKey{ecdsaKey: pub.(*hybridKey).ecdsaKey}
+	pqcOnly := PolicyPQC
+	_, err = csp.Verify(noPQC, sig, digest[:], &HybridSignerOpts{Policy: &pqcOnly})
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, AlgorithmMLDSA, ce.Algorithm)
+	assert.Equal(t, pub.SKI(), ce.SKI)
+
+	_, err = csp.GetKey([]byte{1, 2, 3})
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, OpGetKey, ce.Op)
+	assert.Equal(t, "hybrid getkey key 010203: ", err.Error()[:len("hybrid getkey key 010203: ")])
+}
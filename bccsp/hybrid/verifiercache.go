@@ -0,0 +1,166 @@
+package hybrid
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/disabled"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+var (
+	verifierCacheHitsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "verifier_cache_hits",
+		Help:         "The number of PQC verifications that reused a cached verifier.",
+		StatsdFormat: "%{#fqname}",
+	}
+	verifierCacheMissesOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "verifier_cache_misses",
+		Help:         "The number of PQC verifications that initialized a new verifier.",
+		StatsdFormat: "%{#fqname}",
+	}
+	verifierCacheEvictionsOpts = metrics.CounterOpts{
+		Namespace:    "bccsp",
+		Subsystem:    "hybrid",
+		Name:         "verifier_cache_evictions",
+		Help:         "The number of cached verifiers dropped for size or invalidated on revocation.",
+		StatsdFormat: "%{#fqname}",
+	}
+)
+
+type cachedVerifier struct {
+	id       [sha256.Size]byte
+	verifier *core.PQCVerifier
+}
+
+// VerifierCache is an LRU of initialized ML-DSA verifiers keyed by the
+// SHA-256 of the public key. It is safe for concurrent use and can be shared
+// between providers.
+type VerifierCache struct {
+	mutex   sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[[sha256.Size]byte]*list.Element
+
+	hits      metrics.Counter
+	misses    metrics.Counter
+	evictions metrics.Counter
+
+	hitCount, missCount, evictionCount atomic.Uint64
+}
+
+// NewVerifierCache keeps up to size verifiers and reports hits, misses and
+// evictions through p (nil disables metrics)
+func NewVerifierCache(size int, p metrics.Provider) *VerifierCache {
+	if size < 1 {
+		size = 1
+	}
+	if p == nil {
+		p = &disabled.Provider{}
+	}
+	return &VerifierCache{
+		size:      size,
+		lru:       list.New(),
+		entries:   map[[sha256.Size]byte]*list.Element{},
+		hits:      p.NewCounter(verifierCacheHitsOpts),
+		misses:    p.NewCounter(verifierCacheMissesOpts),
+		evictions: p.NewCounter(verifierCacheEvictionsOpts),
+	}
+}
+
+// WithVerifierCache reuses initialized PQC verifiers from c in Verify
+func WithVerifierCache(c *VerifierCache) Option {
+	return func(h *HybridBCCSP) {
+		h.verifiers = c
+	}
+}
+
+// Verifier returns the cached verifier of publicKey, initializing it on a miss
+func (c *VerifierCache) Verifier(publicKey []byte) (*core.PQCVerifier, error) {
+	id := sha256.Sum256(publicKey)
+
+	c.mutex.Lock()
+	if e, ok := c.entries[id]; ok {
+		c.lru.MoveToFront(e)
+		c.mutex.Unlock()
+		c.hits.Add(1)
+		c.hitCount.Add(1)
+		return e.Value.(*cachedVerifier).verifier, nil
+	}
+	c.mutex.Unlock()
+	c.misses.Add(1)
+	c.missCount.Add(1)
+
+	v, err := core.NewPQCVerifier(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[id]; ok {
+		// Initialized concurrently, keep the first one
+		v.Clean()
+		c.lru.MoveToFront(e)
+		return e.Value.(*cachedVerifier).verifier, nil
+	}
+	c.entries[id] = c.lru.PushFront(&cachedVerifier{id: id, verifier: v})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+	return v, nil
+}
+
+// Invalidate drops the verifier of publicKey, e.g. when its certificate is revoked
+func (c *VerifierCache) Invalidate(publicKey []byte) {
+	id := sha256.Sum256(publicKey)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[id]; ok {
+		c.remove(e)
+	}
+}
+
+// InvalidateKey drops the verifier of the PQC public key of a hybrid key
+func (c *VerifierCache) InvalidateKey(k bccsp.Key) {
+	if key, ok := k.(*hybridKey); ok && len(key.pqcPub) > 0 {
+		c.Invalidate(key.pqcPub)
+	}
+}
+
+// Purge drops every verifier
+func (c *VerifierCache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Len returns the number of cached verifiers
+func (c *VerifierCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+func (c *VerifierCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*cachedVerifier)
+	delete(c.entries, entry.id)
+	entry.verifier.Clean()
+	c.evictions.Add(1)
+	c.evictionCount.Add(1)
+}
+
+// Stats returns the lookups since the cache was created
+func (c *VerifierCache) Stats() CacheStats {
+	return CacheStats{Hits: c.hitCount.Load(), Misses: c.missCount.Load(), Evictions: c.evictionCount.Load()}
+}
@@ -0,0 +1,61 @@
+package hybrid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/hyperledger/fabric-lib-go/common/metrics"
+	"github.com/hyperledger/fabric-lib-go/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifierCache(t *testing.T) {
+	counters := map[string]*metricsfakes.Counter{}
+	provider := &metricsfakes.Provider{}
+	provider.NewCounterStub = func(o metrics.CounterOpts) metrics.Counter {
+		c := &metricsfakes.Counter{}
+		counters[o.Name] = c
+		return c
+	}
+	cache := NewVerifierCache(2, provider)
+	csp, err := New(WithVerifierCache(cache))
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("cached verifier"))
+	var keys []bccsp.Key
+	var sigs [][]byte
+	for i := 0; i < 3; i++ {
+		k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+		require.NoError(t, err)
+		sig, err := csp.Sign(k, digest[:], nil)
+		require.NoError(t, err)
+		keys, sigs = append(keys, k), append(sigs, sig)
+	}
+
+	verify := func(i int) {
+		valid, err := csp.Verify(keys[i], sigs[i], digest[:], nil)
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+	verify(0)
+	verify(0)
+	assert.Equal(t, 1, counters["verifier_cache_misses"].AddCallCount())
+	assert.Equal(t, 1, counters["verifier_cache_hits"].AddCallCount())
+
+	verify(1)
+	verify(2) // evicts key 0
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, 1, counters["verifier_cache_evictions"].AddCallCount())
+	verify(0)
+	assert.Equal(t, 4, counters["verifier_cache_misses"].AddCallCount())
+
+	cache.InvalidateKey(keys[0])
+	assert.Equal(t, 1, cache.Len())
+	verify(0)
+	assert.Equal(t, 5, counters["verifier_cache_misses"].AddCallCount())
+
+	cache.Purge()
+	assert.Zero(t, cache.Len())
+}
@@ -2,36 +2,148 @@
 package hybrid
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/hyperledger/fabric-lib-go/bccsp"
-	"github.com/open-quantum-safe/liboqs-go/oqs"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/core"
 )
 
-// Verify verifica la firma ibrida
-func (h *HybridBCCSP) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (bool, error) {
+// Verify verifica la firma ibrida secondo la policy del canale/MSP
+func (h *HybridBCCSP) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	kind := KindOf(k)
+	defer func(start time.Time) {
+		h.stats.verify.observe(start, valid, err)
+		h.metrics.Verifications.With("kind", kind.String(), "valid", strconv.FormatBool(valid)).Add(1)
+		if h.auditSink != nil {
+			h.emitAudit(audit.OpVerify, k, h.resolvePolicy(opts).String(), valid, err)
+		}
+	}(time.Now())
+	defer func() { err = wrapKeyError(OpVerify, k, err) }()
+
+	if isNilKey(k) {
+		return false, ErrNilKey
+	}
+	if len(signature) == 0 {
+		return false, ErrEmptySignature
+	}
+	if len(digest) == 0 {
+		return false, fmt.Errorf("%w: empty digest", ErrInvalidDigest)
+	}
+
+	prof := h.profiler(opts)
+	if lk, ok := k.(*lmsKey); ok {
+		prof.do("verify", AlgorithmLMS, func() { valid = lk.pub.Verify(digest, signature) })
+		return valid, nil
+	}
+
+	if kind == KindClassical {
+		return h.verifyClassical(prof, k, signature, digest, opts)
+	}
+
 	key, ok := k.(*hybridKey)
 	if !ok {
 		return false, fmt.Errorf("invalid key type, expected *hybridKey")
 	}
-	
+
+	env, err := DecodeEnvelope(signature, h.envelopeLimits)
+	if err != nil {
+		return false, fmt.Errorf("invalid hybrid signature: %w", err)
+	}
+	if h.downgradeProtection {
+		if err := env.CheckDowngrade(len(key.pqcPub) > 0); err != nil {
+			return false, err
+		}
+	}
+	message, err := h.verifyFrame(env, digest, opts)
+	if err != nil {
+		return false, err
+	}
+	ecdsaSig, pqcSig := env.ECDSASignature, env.PQCSignature
+	ecdsaMsg, pqcMsg := env.SignedMessages(digest, message)
+
+	switch policy := h.resolvePolicy(opts); policy {
+	case PolicyHybridAND:
+		valid, err := h.verifyECDSA(prof, key, ecdsaSig, ecdsaMsg)
+		if err != nil || !valid {
+			return false, err
+		}
+		return h.verifyPQC(prof, key, pqcSig, pqcMsg)
+	case PolicyHybridOR:
+		ecdsaValid, ecdsaErr := h.verifyECDSA(prof, key, ecdsaSig, ecdsaMsg)
+		if ecdsaValid {
+			return true, nil
+		}
+		pqcValid, pqcErr := h.verifyPQC(prof, key, pqcSig, pqcMsg)
+		if pqcValid {
+			return true, nil
+		}
+		if pqcErr != nil {
+			return false, pqcErr
+		}
+		return false, ecdsaErr
+	case PolicyClassical:
+		return h.verifyECDSA(prof, key, ecdsaSig, ecdsaMsg)
+	case PolicyPQC:
+		return h.verifyPQC(prof, key, pqcSig, pqcMsg)
+	default:
+		return false, fmt.Errorf("unsupported signature policy %s", policy)
+	}
+}
+
+// verifyECDSA verifica la componente classica con il provider della chiave
+func (h *HybridBCCSP) verifyECDSA(prof *profiler, key *hybridKey, signature, digest []byte) (valid bool, err error) {
+	defer func() { err = wrapError(OpVerify, AlgorithmECDSA, skiOf(key), err) }()
+	if len(signature) == 0 {
+		return false, fmt.Errorf("ECDSA signature is empty")
+	}
+	prof.do("verify", AlgorithmECDSA, func() { valid, err = h.ecdsaProvider(key).Verify(key.ecdsaKey, signature, digest, nil) })
+	if err != nil {
+		return false, fmt.Errorf("ECDSA verification failed: %w", err)
+	}
+	return valid, nil
+}
+
+// verifyPQC verifica la componente post-quantum
+func (h *HybridBCCSP) verifyPQC(prof *profiler, key *hybridKey, signature, digest []byte) (_ bool, err error) {
+	defer func() { err = wrapError(OpVerify, AlgorithmMLDSA, skiOf(key), err) }()
 	// Verifica che abbiamo la chiave pubblica PQC
 	if len(key.pqcPub) == 0 {
 		return false, fmt.Errorf("PQC public key is empty")
 	}
-	
-	// Crea un verifier PQC temporaneo per la verifica
-	// (la verifica richiede solo la chiave pubblica)
-	signer := oqs.Signature{}
-	if err := signer.Init(PQCAlgorithm, nil); err != nil {
-		return false, fmt.Errorf("failed to init PQC verifier: %w", err)
-	}
-	defer signer.Clean()
-	
-	// PQC verification usando la chiave pubblica
-	valid, err := signer.Verify(digest, signature, key.pqcPub)
+	if len(signature) == 0 {
+		return false, fmt.Errorf("PQC signature is empty")
+	}
+
+	// PQC verification usando la chiave pubblica, con il verifier in cache se presente
+	valid, err := h.timeBoxed(func() (valid bool, err error) {
+		err = h.pqcCall(func() (err error) {
+			prof.do("verify", AlgorithmMLDSA, func() {
+				if h.verifiers != nil {
+					var v *core.PQCVerifier
+					if v, err = h.verifiers.Verifier(key.pqcPub); err == nil {
+						valid, err = v.Verify(digest, signature)
+					}
+					return
+				}
+				valid, err = VerifyPQC(key.pqcPub, digest, signature)
+			})
+			if h.canary != nil {
+				h.compareCanary(key, digest, signature, valid, err)
+			}
+			return err
+		})
+		return valid, err
+	})
+	if errors.Is(err, ErrVerifyTimeout) {
+		return false, err
+	}
 	if err != nil {
 		return false, fmt.Errorf("PQC verification failed: %w", err)
 	}
-	
+
 	return valid, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,369 @@
+// Package hybridmock is a fake bccsp.BCCSP for unit tests of code built on
+// the hybrid provider. It needs neither liboqs nor cgo: keys are opaque
+// handles and signatures are deterministic digests of the key and the
+// message, so expected values can be computed with Signature. Failures can be
+// programmed per method and every call is recorded.
+//
+// Signatures are not hybrid envelopes and verify only with this provider;
+// tests of the envelope format belong with bccsp/hybrid.
+package hybridmock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+)
+
+// Method names a bccsp.BCCSP method
+type Method string
+
+// Methods of bccsp.BCCSP
+const (
+	MethodKeyGen    Method = "KeyGen"
+	MethodKeyDeriv  Method = "KeyDeriv"
+	MethodKeyImport Method = "KeyImport"
+	MethodGetKey    Method = "GetKey"
+	MethodHash      Method = "Hash"
+	MethodGetHash   Method = "GetHash"
+	MethodSign      Method = "Sign"
+	MethodVerify    Method = "Verify"
+	MethodEncrypt   Method = "Encrypt"
+	MethodDecrypt   Method = "Decrypt"
+)
+
+// ErrKeyNotFound is returned by GetKey for unknown SKIs
+var ErrKeyNotFound = errors.New("hybridmock: key not found")
+
+// ErrInvalidKey is returned for nil keys and keys of another provider
+var ErrInvalidKey = errors.New("hybridmock: invalid key")
+
+// publicKeyPrefix starts the Bytes of public keys, which KeyImport accepts
+const publicKeyPrefix = "hybridmock-public:"
+
+// Call is a recorded call. Fields not taken by the method are empty.
+type Call struct {
+	Method Method
+	// SKI is the key the call used or returned
+	SKI    []byte
+	Digest []byte
+	Opts   interface{}
+	Err    error
+}
+
+// Provider is the fake BCCSP. The zero value is not usable, call New.
+type Provider struct {
+	mutex   sync.Mutex
+	keys    map[string]*Key
+	counter uint64
+	calls   []Call
+	next    map[Method][]error
+	always  map[Method]error
+}
+
+var _ bccsp.BCCSP = (*Provider)(nil)
+
+// New returns an empty provider
+func New() *Provider {
+	return &Provider{keys: map[string]*Key{}, next: map[Method][]error{}, always: map[Method]error{}}
+}
+
+// FailNext makes the next call of m return err; successive calls queue
+// errors for successive calls
+func (p *Provider) FailNext(m Method, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.next[m] = append(p.next[m], err)
+}
+
+// FailAlways makes every call of m return err, until called with a nil err
+func (p *Provider) FailAlways(m Method, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err == nil {
+		delete(p.always, m)
+		return
+	}
+	p.always[m] = err
+}
+
+// Calls returns the recorded calls, oldest first
+func (p *Provider) Calls() []Call {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// CallCount returns the number of recorded calls of m
+func (p *Provider) CallCount(m Method) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	n := 0
+	for _, c := range p.calls {
+		if c.Method == m {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets recorded calls and programmed failures, keeping the keys
+func (p *Provider) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.calls = nil
+	p.next = map[Method][]error{}
+	p.always = map[Method]error{}
+}
+
+// begin returns the programmed failure of m, if any
+func (p *Provider) begin(m Method) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if queue := p.next[m]; len(queue) > 0 {
+		p.next[m] = queue[1:]
+		return queue[0]
+	}
+	return p.always[m]
+}
+
+func (p *Provider) record(c Call) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.calls = append(p.calls, c)
+}
+
+// Key is a key of the provider. Private keys have a public counterpart with
+// the same SKI.
+type Key struct {
+	ski     []byte
+	private bool
+}
+
+func (k *Key) Bytes() ([]byte, error) {
+	if k.private {
+		return nil, errors.New("hybridmock: private keys are not exportable")
+	}
+	return []byte(publicKeyPrefix + hex.EncodeToString(k.ski)), nil
+}
+
+func (k *Key) SKI() []byte     { return append([]byte(nil), k.ski...) }
+func (k *Key) Symmetric() bool { return false }
+func (k *Key) Private() bool   { return k.private }
+
+func (k *Key) PublicKey() (bccsp.Key, error) {
+	return &Key{ski: k.ski}, nil
+}
+
+// Signature returns the signature the provider makes of digest with the key
+// of ski
+func Signature(ski, digest []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("hybridmock-signature"))
+	binary.Write(h, binary.BigEndian, uint32(len(ski)))
+	h.Write(ski)
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+// newKey returns a private key with the next deterministic SKI, so a test
+// sees the same SKIs on every run
+func (p *Provider) newKey(seed []byte) *Key {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.counter++
+	h := sha256.New()
+	h.Write([]byte("hybridmock-key"))
+	binary.Write(h, binary.BigEndian, p.counter)
+	h.Write(seed)
+	k := &Key{ski: h.Sum(nil), private: true}
+	p.keys[string(k.ski)] = k
+	return k
+}
+
+func asKey(k bccsp.Key) (*Key, error) {
+	mk, ok := k.(*Key)
+	if !ok || mk == nil {
+		return nil, fmt.Errorf("%w: %T", ErrInvalidKey, k)
+	}
+	return mk, nil
+}
+
+// KeyGen returns a new private key whatever opts asks for
+func (p *Provider) KeyGen(opts bccsp.KeyGenOpts) (bccsp.Key, error) {
+	c := Call{Method: MethodKeyGen, Opts: opts}
+	if c.Err = p.begin(MethodKeyGen); c.Err != nil {
+		p.record(c)
+		return nil, c.Err
+	}
+	k := p.newKey(nil)
+	c.SKI = k.SKI()
+	p.record(c)
+	return k, nil
+}
+
+// KeyDeriv returns a new private key derived from k
+func (p *Provider) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (bccsp.Key, error) {
+	c := Call{Method: MethodKeyDeriv, Opts: opts}
+	mk, err := asKey(k)
+	if err == nil {
+		c.SKI = mk.SKI()
+		err = p.begin(MethodKeyDeriv)
+	}
+	if c.Err = err; err != nil {
+		p.record(c)
+		return nil, err
+	}
+	dk := p.newKey(mk.ski)
+	p.record(c)
+	return dk, nil
+}
+
+// KeyImport imports the Bytes of a public key of the provider
+func (p *Provider) KeyImport(raw interface{}, opts bccsp.KeyImportOpts) (bccsp.Key, error) {
+	c := Call{Method: MethodKeyImport, Opts: opts}
+	if c.Err = p.begin(MethodKeyImport); c.Err != nil {
+		p.record(c)
+		return nil, c.Err
+	}
+	var k *Key
+	b, ok := raw.([]byte)
+	if ok && bytes.HasPrefix(b, []byte(publicKeyPrefix)) {
+		if ski, err := hex.DecodeString(string(b[len(publicKeyPrefix):])); err == nil && len(ski) > 0 {
+			k = &Key{ski: ski}
+		}
+	}
+	if k == nil {
+		c.Err = fmt.Errorf("%w: not a hybridmock public key", ErrInvalidKey)
+		p.record(c)
+		return nil, c.Err
+	}
+	c.SKI = k.SKI()
+	p.record(c)
+	return k, nil
+}
+
+// GetKey returns a key generated or derived by the provider
+func (p *Provider) GetKey(ski []byte) (bccsp.Key, error) {
+	c := Call{Method: MethodGetKey, SKI: append([]byte(nil), ski...)}
+	if c.Err = p.begin(MethodGetKey); c.Err != nil {
+		p.record(c)
+		return nil, c.Err
+	}
+	p.mutex.Lock()
+	k, ok := p.keys[string(ski)]
+	p.mutex.Unlock()
+	if !ok {
+		c.Err = fmt.Errorf("%w: %x", ErrKeyNotFound, ski)
+		p.record(c)
+		return nil, c.Err
+	}
+	p.record(c)
+	return k, nil
+}
+
+// Hash returns the SHA-256 of msg
+func (p *Provider) Hash(msg []byte, opts bccsp.HashOpts) ([]byte, error) {
+	c := Call{Method: MethodHash, Opts: opts}
+	if c.Err = p.begin(MethodHash); c.Err != nil {
+		p.record(c)
+		return nil, c.Err
+	}
+	sum := sha256.Sum256(msg)
+	c.Digest = sum[:]
+	p.record(c)
+	return sum[:], nil
+}
+
+// GetHash returns SHA-256
+func (p *Provider) GetHash(opts bccsp.HashOpts) (hash.Hash, error) {
+	c := Call{Method: MethodGetHash, Opts: opts}
+	c.Err = p.begin(MethodGetHash)
+	p.record(c)
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	return sha256.New(), nil
+}
+
+// Sign returns Signature(k.SKI(), digest); k must be private
+func (p *Provider) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) ([]byte, error) {
+	c := Call{Method: MethodSign, Digest: append([]byte(nil), digest...), Opts: opts}
+	mk, err := asKey(k)
+	if err == nil {
+		c.SKI = mk.SKI()
+		if !mk.private {
+			err = fmt.Errorf("%w: signing requires a private key", ErrInvalidKey)
+		} else {
+			err = p.begin(MethodSign)
+		}
+	}
+	if c.Err = err; err != nil {
+		p.record(c)
+		return nil, err
+	}
+	p.record(c)
+	return Signature(mk.ski, digest), nil
+}
+
+// Verify reports whether signature is Signature(k.SKI(), digest)
+func (p *Provider) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (bool, error) {
+	c := Call{Method: MethodVerify, Digest: append([]byte(nil), digest...), Opts: opts}
+	mk, err := asKey(k)
+	if err == nil {
+		c.SKI = mk.SKI()
+		err = p.begin(MethodVerify)
+	}
+	if c.Err = err; err != nil {
+		p.record(c)
+		return false, err
+	}
+	p.record(c)
+	return bytes.Equal(signature, Signature(mk.ski, digest)), nil
+}
+
+// Encrypt returns plaintext prefixed with the SKI of k. It is reversible by
+// design: the mock protects nothing.
+func (p *Provider) Encrypt(k bccsp.Key, plaintext []byte, opts bccsp.EncrypterOpts) ([]byte, error) {
+	c := Call{Method: MethodEncrypt, Opts: opts}
+	mk, err := asKey(k)
+	if err == nil {
+		c.SKI = mk.SKI()
+		err = p.begin(MethodEncrypt)
+	}
+	if c.Err = err; err != nil {
+		p.record(c)
+		return nil, err
+	}
+	p.record(c)
+	return append(mk.SKI(), plaintext...), nil
+}
+
+// Decrypt reverses Encrypt; k must be private
+func (p *Provider) Decrypt(k bccsp.Key, ciphertext []byte, opts bccsp.DecrypterOpts) ([]byte, error) {
+	c := Call{Method: MethodDecrypt, Opts: opts}
+	mk, err := asKey(k)
+	if err == nil {
+		c.SKI = mk.SKI()
+		switch {
+		case !mk.private:
+			err = fmt.Errorf("%w: decryption requires a private key", ErrInvalidKey)
+		case !bytes.HasPrefix(ciphertext, mk.ski):
+			err = errors.New("hybridmock: ciphertext is not for this key")
+		default:
+			err = p.begin(MethodDecrypt)
+		}
+	}
+	if c.Err = err; err != nil {
+		p.record(c)
+		return nil, err
+	}
+	p.record(c)
+	return append([]byte(nil), ciphertext[len(mk.ski):]...), nil
+}
@@ -0,0 +1,117 @@
+package hybridmock
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	p := New()
+	k, err := p.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("tx"))
+	sig, err := p.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, Signature(k.SKI(), digest[:]), sig)
+
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	raw, err := pub.Bytes()
+	require.NoError(t, err)
+	imported, err := p.KeyImport(raw, nil)
+	require.NoError(t, err)
+	valid, err := p.Verify(imported, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	digest[0] ^= 1
+	valid, err = p.Verify(imported, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = p.Sign(pub, digest[:], nil)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = p.Sign(nil, digest[:], nil)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = k.Bytes()
+	assert.Error(t, err)
+
+	got, err := p.GetKey(k.SKI())
+	require.NoError(t, err)
+	assert.Equal(t, k, got)
+	_, err = p.GetKey([]byte{1})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestDeterministic(t *testing.T) {
+	a, b := New(), New()
+	for i := 0; i < 3; i++ {
+		ka, err := a.KeyGen(nil)
+		require.NoError(t, err)
+		kb, err := b.KeyGen(nil)
+		require.NoError(t, err)
+		assert.Equal(t, ka.SKI(), kb.SKI())
+	}
+	k, err := a.KeyGen(nil)
+	require.NoError(t, err)
+	dk, err := a.KeyDeriv(k, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, k.SKI(), dk.SKI())
+}
+
+func TestFailuresAndCalls(t *testing.T) {
+	p := New()
+	k, err := p.KeyGen(nil)
+	require.NoError(t, err)
+
+	errHSM := errors.New("hsm unavailable")
+	p.FailNext(MethodSign, errHSM)
+	_, err = p.Sign(k, []byte("d1"), nil)
+	assert.ErrorIs(t, err, errHSM)
+	_, err = p.Sign(k, []byte("d2"), nil)
+	require.NoError(t, err)
+
+	p.FailAlways(MethodVerify, errHSM)
+	for i := 0; i < 2; i++ {
+		_, err = p.Verify(k, nil, []byte("d"), nil)
+		assert.ErrorIs(t, err, errHSM)
+	}
+	p.FailAlways(MethodVerify, nil)
+	_, err = p.Verify(k, nil, []byte("d"), nil)
+	require.NoError(t, err)
+
+	calls := p.Calls()
+	require.Len(t, calls, 6)
+	assert.Equal(t, MethodKeyGen, calls[0].Method)
+	assert.Equal(t, Call{Method: MethodSign, SKI: k.SKI(), Digest: []byte("d1"), Err: errHSM}, calls[1])
+	assert.Equal(t, 2, p.CallCount(MethodSign))
+	assert.Equal(t, 3, p.CallCount(MethodVerify))
+
+	p.Reset()
+	assert.Empty(t, p.Calls())
+	_, err = p.GetKey(k.SKI())
+	assert.NoError(t, err, "keys survive Reset")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	p := New()
+	k, err := p.KeyGen(nil)
+	require.NoError(t, err)
+	other, err := p.KeyGen(nil)
+	require.NoError(t, err)
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	ct, err := p.Encrypt(pub, []byte("secret"), nil)
+	require.NoError(t, err)
+	pt, err := p.Decrypt(k, ct, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), pt)
+	_, err = p.Decrypt(other, ct, nil)
+	assert.Error(t, err)
+	_, err = p.Decrypt(pub, ct, nil)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,88 @@
+package bench
+
+import (
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// KeyCache is the state of the committer's per-signer caches when an
+// operation starts. Warm numbers are what a long-running peer sees for
+// known endorsers; cold numbers add parsing the identity's public key and
+// initializing its ML-DSA verifier, as after a restart or for a new signer.
+type KeyCache string
+
+const (
+	CacheCold KeyCache = "cold"
+	CacheWarm KeyCache = "warm"
+)
+
+// KeyCaches lists the cache scenarios in report order
+var KeyCaches = []KeyCache{CacheCold, CacheWarm}
+
+// ParseKeyCache parses "cold" or "warm"
+func ParseKeyCache(s string) (KeyCache, error) {
+	for _, c := range KeyCaches {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("invalid key cache scenario %q, expected cold or warm", s)
+}
+
+// verifierCache keeps the parsed public key and an initialized verifier per
+// identity. In the cold scenario it is flushed before every verification.
+type verifierCache struct {
+	scenario KeyCache
+	entries  map[string]*cachedIdentity
+}
+
+type cachedIdentity struct {
+	key      *core.PublicKey
+	verifier *core.PQCVerifier
+}
+
+func newVerifierCache(scenario KeyCache) (*verifierCache, error) {
+	if _, err := ParseKeyCache(string(scenario)); err != nil {
+		return nil, err
+	}
+	return &verifierCache{scenario: scenario, entries: map[string]*cachedIdentity{}}, nil
+}
+
+// verify checks a hybrid signature by the serialized identity
+func (c *verifierCache) verify(identity, digest, signature []byte) (bool, error) {
+	if c.scenario == CacheCold {
+		c.flush()
+	}
+	e, err := c.entry(identity)
+	if err != nil {
+		return false, err
+	}
+	return e.key.VerifyWith(e.verifier, digest, signature, core.PolicyHybridAND)
+}
+
+// entry returns the cached identity, parsing it on a miss
+func (c *verifierCache) entry(identity []byte) (*cachedIdentity, error) {
+	if e, ok := c.entries[string(identity)]; ok {
+		return e, nil
+	}
+	key, err := core.ParsePublicKey(identity)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := core.NewPQCVerifier(key.PQC)
+	if err != nil {
+		return nil, err
+	}
+	e := &cachedIdentity{key: key, verifier: verifier}
+	c.entries[string(identity)] = e
+	return e, nil
+}
+
+// flush drops every cached identity
+func (c *verifierCache) flush() {
+	for id, e := range c.entries {
+		e.verifier.Clean()
+		delete(c.entries, id)
+	}
+}
@@ -0,0 +1,47 @@
+package bench
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestVerifierCache(t *testing.T) {
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer key.Clean()
+	identity, err := key.Public().Marshal()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("cache"))
+	sig, err := key.Sign(digest[:])
+	require.NoError(t, err)
+
+	for _, scenario := range KeyCaches {
+		c, err := newVerifierCache(scenario)
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			ok, err := c.verify(identity, digest[:], sig)
+			require.NoError(t, err)
+			assert.True(t, ok, scenario)
+		}
+		first := c.entries[string(identity)]
+		_, err = c.verify(identity, digest[:], sig)
+		require.NoError(t, err)
+		if scenario == CacheWarm {
+			assert.Same(t, first, c.entries[string(identity)], "warm caches keep verifiers")
+		} else {
+			assert.NotSame(t, first, c.entries[string(identity)], "cold caches are flushed")
+		}
+		c.flush()
+		assert.Empty(t, c.entries)
+	}
+
+	c, err := ParseKeyCache("warm")
+	require.NoError(t, err)
+	assert.Equal(t, CacheWarm, c)
+	_, err = ParseKeyCache("hot")
+	assert.Error(t, err)
+}
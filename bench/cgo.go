@@ -0,0 +1,95 @@
+package bench
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// CGOCallsPerSign is the number of liboqs calls a hybrid signature makes:
+// ML-DSA signing is a single OQS_SIG_sign, ECDSA stays in Go
+const CGOCallsPerSign = 1
+
+// CGOOverhead splits hybrid signing latency into FFI and algorithmic cost
+type CGOOverhead struct {
+	// Call is one liboqs call doing no crypto
+	Call       time.Duration
+	PQCSign    time.Duration
+	ECDSASign  time.Duration
+	HybridSign time.Duration
+}
+
+// FFI is the boundary cost included in one hybrid signature
+func (o *CGOOverhead) FFI() time.Duration {
+	return CGOCallsPerSign * o.Call
+}
+
+// FFIShare is FFI as a fraction of HybridSign
+func (o *CGOOverhead) FFIShare() float64 {
+	if o.HybridSign == 0 {
+		return 0
+	}
+	return float64(o.FFI()) / float64(o.HybridSign)
+}
+
+// Algorithmic is the ML-DSA cost once the boundary crossing is removed
+func (o *CGOOverhead) Algorithmic() time.Duration {
+	return o.PQCSign - o.FFI()
+}
+
+func (o *CGOOverhead) String() string {
+	return fmt.Sprintf("cgo call %v, ecdsa %v, ml-dsa %v (algorithmic %v), hybrid %v, ffi share %.4f%%",
+		o.Call, o.ECDSASign, o.PQCSign, o.Algorithmic(), o.HybridSign, 100*o.FFIShare())
+}
+
+// MeasureCGOOverhead benchmarks a no-op liboqs call against full signatures
+func MeasureCGOOverhead() (*CGOOverhead, error) {
+	key, err := core.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer key.Clean()
+	digest := sha256.Sum256([]byte("cgo overhead"))
+
+	var signErr error
+	perOp := func(f func()) time.Duration {
+		r := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				f()
+			}
+		})
+		return time.Duration(r.NsPerOp())
+	}
+
+	o := &CGOOverhead{}
+	o.Call = perOp(func() { _ = core.PQCAvailable() })
+	o.PQCSign = perOp(func() {
+		if _, err := key.PQC.Sign(digest[:]); err != nil {
+			signErr = err
+		}
+	})
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	o.ECDSASign = perOp(func() {
+		if _, _, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:]); err != nil {
+			signErr = err
+		}
+	})
+	o.HybridSign = perOp(func() {
+		if _, err := key.Sign(digest[:]); err != nil {
+			signErr = err
+		}
+	})
+	if signErr != nil {
+		return nil, signErr
+	}
+	return o, nil
+}
@@ -0,0 +1,19 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureCGOOverhead(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs benchmarks")
+	}
+	o, err := MeasureCGOOverhead()
+	require.NoError(t, err)
+	assert.Positive(t, o.Call)
+	assert.Greater(t, o.HybridSign, o.FFI())
+	assert.Less(t, o.FFIShare(), 1.0)
+}
@@ -0,0 +1,148 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Statistic summarizes the samples of a metric
+type Statistic string
+
+const (
+	Mean Statistic = "mean"
+	P50  Statistic = "p50"
+	P95  Statistic = "p95"
+	P99  Statistic = "p99"
+)
+
+// Compute returns the statistic of samples; percentiles use linear interpolation
+func (s Statistic) Compute(samples []float64) (float64, error) {
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("no samples")
+	}
+	switch s {
+	case Mean:
+		var sum float64
+		for _, v := range samples {
+			sum += v
+		}
+		return sum / float64(len(samples)), nil
+	case P50:
+		return percentile(samples, 50), nil
+	case P95:
+		return percentile(samples, 95), nil
+	case P99:
+		return percentile(samples, 99), nil
+	}
+	return 0, fmt.Errorf("unknown statistic %q", s)
+}
+
+func percentile(samples []float64, p float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// Threshold is the largest tolerated relative change of a metric statistic
+type Threshold struct {
+	Metric    string
+	Statistic Statistic
+	// MaxChange is the tolerated relative change, e.g. 0.15 for +15%
+	MaxChange float64
+	// HigherIsBetter flips the direction, e.g. for throughput
+	HigherIsBetter bool
+}
+
+// DefaultThresholds gates the crypto timings, latency and throughput
+func DefaultThresholds() []Threshold {
+	return []Threshold{
+		{Metric: "sig_gen_time", Statistic: P95, MaxChange: 0.15},
+		{Metric: "sig_verify_time", Statistic: P95, MaxChange: 0.15},
+		{Metric: "latency_p95", Statistic: Mean, MaxChange: 0.10},
+		{Metric: "block_commit_time", Statistic: P95, MaxChange: 0.15},
+		{Metric: "tx_rate", Statistic: Mean, MaxChange: 0.10, HigherIsBetter: true},
+	}
+}
+
+// Regression is a statistic that moved beyond its threshold
+type Regression struct {
+	Group     Group
+	Metric    string
+	Statistic Statistic
+	Baseline  float64
+	Current   float64
+	// Change is the relative change (current - baseline) / baseline
+	Change float64
+	Limit  float64
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s %s: %.3f -> %.3f (%+.1f%%, limit %.1f%%)",
+		r.Group, r.Metric, r.Statistic, r.Baseline, r.Current, 100*r.Change, 100*r.Limit)
+}
+
+// Report is the outcome of Compare
+type Report struct {
+	Regressions []Regression
+	// Compared counts the (group, threshold) pairs present in both datasets
+	Compared int
+	// Skipped lists pairs missing from either dataset
+	Skipped []string
+}
+
+// Failed reports whether any regression was found
+func (r *Report) Failed() bool {
+	return len(r.Regressions) > 0
+}
+
+// Compare checks every threshold for every group of the current dataset
+func Compare(baseline, current *Dataset, thresholds []Threshold) (*Report, error) {
+	if baseline == nil || current == nil {
+		return nil, fmt.Errorf("baseline and current datasets are required")
+	}
+	report := &Report{}
+	for _, g := range current.Groups() {
+		for _, th := range thresholds {
+			base := baseline.Samples(g, th.Metric)
+			cur := current.Samples(g, th.Metric)
+			if len(base) == 0 || len(cur) == 0 {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s %s", g, th.Metric))
+				continue
+			}
+			b, err := th.Statistic.Compute(base)
+			if err != nil {
+				return nil, err
+			}
+			c, err := th.Statistic.Compute(cur)
+			if err != nil {
+				return nil, err
+			}
+			report.Compared++
+
+			if b == 0 {
+				continue
+			}
+			change := (c - b) / math.Abs(b)
+			worse := change > th.MaxChange
+			if th.HigherIsBetter {
+				worse = -change > th.MaxChange
+			}
+			if worse {
+				report.Regressions = append(report.Regressions, Regression{
+					Group:     g,
+					Metric:    th.Metric,
+					Statistic: th.Statistic,
+					Baseline:  b,
+					Current:   c,
+					Change:    change,
+					Limit:     th.MaxChange,
+				})
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatistics(t *testing.T) {
+	samples := []float64{5, 1, 4, 2, 3}
+	mean, err := Mean.Compute(samples)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, mean)
+
+	p50, _ := P50.Compute(samples)
+	assert.Equal(t, 3.0, p50)
+	p95, _ := P95.Compute(samples)
+	assert.InDelta(t, 4.8, p95, 1e-9)
+
+	_, err = Mean.Compute(nil)
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	base := write("HYBRID_LOWLOAD_RUN1.csv", "timestamp,tx_rate,sig_verify_time\n1,100,1000\n2,100,1000\n3,100,1000\n")
+	cur := write("HYBRID_LOWLOAD_RUN2.csv", "timestamp,tx_rate,sig_verify_time\n1,85,1200\n2,85,1200\n3,85,1200\n")
+
+	baseline, err := LoadCSV(base)
+	require.NoError(t, err)
+	current, err := LoadCSV(cur)
+	require.NoError(t, err)
+
+	report, err := Compare(baseline, current, []Threshold{
+		{Metric: "sig_verify_time", Statistic: P95, MaxChange: 0.15},
+		{Metric: "tx_rate", Statistic: Mean, MaxChange: 0.10, HigherIsBetter: true},
+		{Metric: "latency_p95", Statistic: Mean, MaxChange: 0.10},
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Failed())
+	assert.Equal(t, 2, report.Compared)
+	assert.Len(t, report.Skipped, 1)
+	require.Len(t, report.Regressions, 2)
+
+	r := report.Regressions[0]
+	assert.Equal(t, Group{CryptoMode: "HYBRID", LoadProfile: "LOWLOAD"}, r.Group)
+	assert.Equal(t, "sig_verify_time", r.Metric)
+	assert.InDelta(t, 0.20, r.Change, 1e-9)
+
+	report, err = Compare(baseline, baseline, DefaultThresholds())
+	require.NoError(t, err)
+	assert.False(t, report.Failed())
+}
@@ -0,0 +1,201 @@
+// Package bench loads benchmark result datasets and compares them to detect
+// performance regressions in the crypto path.
+package bench
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Categorical dataset columns; every other column is parsed as a numeric metric
+var labelColumns = map[string]bool{
+	"timestamp":          true,
+	"timestamp_epoch_ms": true,
+	"run_id":             true,
+	"crypto_mode":        true,
+	"cryptosystem":       true,
+	"load_profile":       true,
+	"operation_phase":    true,
+	"endorsement_policy": true,
+	"key_cache":          true,
+	"keystore_backend":   true,
+}
+
+// fileNamePattern matches <CRYPTO_MODE>_<LOAD_PROFILE>_RUN<N>.csv
+var fileNamePattern = regexp.MustCompile(`^([A-Z0-9-]+)_([A-Z]+)_RUN(\d+)\.csv$`)
+
+// Group identifies the rows compared against each other
+type Group struct {
+	CryptoMode  string
+	LoadProfile string
+	// EndorsementPolicy is set for rows of endorsement policy scenarios
+	EndorsementPolicy string
+	// KeyCache is set for rows measured with warm or cold key caches
+	KeyCache string
+	// KeystoreBackend is set for rows of keystore backend scenarios
+	KeystoreBackend string
+}
+
+func (g Group) String() string {
+	s := g.CryptoMode + "/" + g.LoadProfile
+	if g.EndorsementPolicy != "" {
+		s += "/" + g.EndorsementPolicy
+	}
+	if g.KeyCache != "" {
+		s += "/" + g.KeyCache
+	}
+	if g.KeystoreBackend != "" {
+		s += "/" + g.KeystoreBackend
+	}
+	return s
+}
+
+// Dataset holds metric samples grouped by crypto mode, load profile,
+// endorsement policy, key cache and keystore backend scenario
+type Dataset struct {
+	samples map[Group]map[string][]float64
+}
+
+// NewDataset returns an empty dataset
+func NewDataset() *Dataset {
+	return &Dataset{samples: map[Group]map[string][]float64{}}
+}
+
+// Add appends a sample of metric for group
+func (d *Dataset) Add(g Group, metric string, value float64) {
+	metrics, ok := d.samples[g]
+	if !ok {
+		metrics = map[string][]float64{}
+		d.samples[g] = metrics
+	}
+	metrics[metric] = append(metrics[metric], value)
+}
+
+// Samples returns the samples of metric for group
+func (d *Dataset) Samples(g Group, metric string) []float64 {
+	return d.samples[g][metric]
+}
+
+// Groups returns the groups in the dataset in sorted order
+func (d *Dataset) Groups() []Group {
+	groups := make([]Group, 0, len(d.samples))
+	for g := range d.samples {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].CryptoMode != groups[j].CryptoMode {
+			return groups[i].CryptoMode < groups[j].CryptoMode
+		}
+		if groups[i].LoadProfile != groups[j].LoadProfile {
+			return groups[i].LoadProfile < groups[j].LoadProfile
+		}
+		if groups[i].EndorsementPolicy != groups[j].EndorsementPolicy {
+			return groups[i].EndorsementPolicy < groups[j].EndorsementPolicy
+		}
+		if groups[i].KeyCache != groups[j].KeyCache {
+			return groups[i].KeyCache < groups[j].KeyCache
+		}
+		return groups[i].KeystoreBackend < groups[j].KeystoreBackend
+	})
+	return groups
+}
+
+// LoadDir loads every CSV file in dir
+func LoadDir(dir string) (*Dataset, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no CSV files in %s", dir)
+	}
+	return LoadCSV(paths...)
+}
+
+// LoadCSV loads one or more result files into a single dataset. Files whose
+// rows lack crypto_mode/load_profile take them from the file name.
+func LoadCSV(paths ...string) (*Dataset, error) {
+	d := NewDataset()
+	for _, path := range paths {
+		if err := d.loadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+	}
+	return d, nil
+}
+
+func (d *Dataset) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var fileGroup Group
+	if m := fileNamePattern.FindStringSubmatch(filepath.Base(path)); m != nil {
+		fileGroup = Group{CryptoMode: m[1], LoadProfile: m[2]}
+	}
+	return d.read(f, fileGroup)
+}
+
+func (d *Dataset) read(r io.Reader, fallback Group) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty file")
+		}
+		return err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		g := fallback
+		for i, col := range header {
+			switch col {
+			case "crypto_mode", "cryptosystem":
+				g.CryptoMode = record[i]
+			case "load_profile":
+				g.LoadProfile = record[i]
+			case "endorsement_policy":
+				g.EndorsementPolicy = record[i]
+			case "key_cache":
+				g.KeyCache = record[i]
+			case "keystore_backend":
+				g.KeystoreBackend = record[i]
+			}
+		}
+		if g.CryptoMode == "" {
+			return fmt.Errorf("line %d: crypto mode unknown", line)
+		}
+
+		for i, col := range header {
+			if labelColumns[col] || record[i] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(record[i], 64)
+			if err != nil {
+				return fmt.Errorf("line %d: column %s: %w", line, col, err)
+			}
+			d.Add(g, col, v)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package bench
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// EndorsementPolicy is a k-of-n endorsement policy: a transaction fans out
+// to Endorsers peers and commits once Required of their signatures verify
+type EndorsementPolicy struct {
+	Required  int
+	Endorsers int
+}
+
+// DefaultEndorsementPolicies spans single-org channels to 7-org consortia
+var DefaultEndorsementPolicies = []EndorsementPolicy{
+	{1, 1}, {1, 2}, {2, 3}, {3, 5}, {4, 7}, {5, 7},
+}
+
+// ParseEndorsementPolicy parses "k-of-n"
+func ParseEndorsementPolicy(s string) (EndorsementPolicy, error) {
+	var p EndorsementPolicy
+	if _, err := fmt.Sscanf(s, "%d-of-%d", &p.Required, &p.Endorsers); err != nil || p.String() != s {
+		return p, fmt.Errorf("invalid endorsement policy %q, expected k-of-n", s)
+	}
+	return p, p.validate()
+}
+
+func (p EndorsementPolicy) String() string {
+	return fmt.Sprintf("%d-of-%d", p.Required, p.Endorsers)
+}
+
+func (p EndorsementPolicy) validate() error {
+	if p.Required < 1 || p.Required > p.Endorsers {
+		return fmt.Errorf("invalid endorsement policy %s", p)
+	}
+	return nil
+}
+
+// EndorsementSample is the crypto cost of one transaction under a policy
+type EndorsementSample struct {
+	Policy EndorsementPolicy
+	// Cache is the state of the committer's key caches
+	Cache KeyCache
+	Time  time.Time
+	// Signatures is the number of endorsements attached to the transaction
+	Signatures int
+	// Endorse is the time every endorser took to sign, one after the other
+	Endorse time.Duration
+	// Commit is the time the committer took to verify the endorsements
+	Commit time.Duration
+	// Size is the size of the endorsements: composite keys and signatures
+	Size int
+}
+
+// endorsementColumns is the CSV header written by WriteEndorsementCSV
+var endorsementColumns = []string{
+	"timestamp_epoch_ms", "run_id", "cryptosystem", "load_profile", "operation_phase",
+	"endorsement_policy", "key_cache", "endorsement_count", "time_sign_us", "time_verify_us", "payload_size_bytes",
+}
+
+// EndorsementLoadProfile is the load profile of endorsement scenario rows
+const EndorsementLoadProfile = "ENDORSEMENT"
+
+// MeasureEndorsement endorses txs transactions with hybrid keys under
+// policy. Every endorser signs, as a client fanning out to all of them
+// does, and the committer verifies every attached endorsement, as Fabric's
+// policy evaluation does, before checking that enough are valid. With a
+// warm cache the committer already knows the endorsers; with a cold one it
+// parses their identities and initializes verifiers for every signature.
+func MeasureEndorsement(policy EndorsementPolicy, cache KeyCache, txs int) ([]EndorsementSample, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	verifiers, err := newVerifierCache(cache)
+	if err != nil {
+		return nil, err
+	}
+	defer verifiers.flush()
+	keys := make([]*core.PrivateKey, policy.Endorsers)
+	identities := make([][]byte, policy.Endorsers)
+	for i := range keys {
+		key, err := core.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		defer key.Clean()
+		if identities[i], err = key.Public().Marshal(); err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		if cache == CacheWarm {
+			if _, err := verifiers.entry(identities[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	samples := make([]EndorsementSample, 0, txs)
+	for tx := 0; tx < txs; tx++ {
+		digest := proposalResponseDigest(policy, tx)
+		start := time.Now()
+		s := EndorsementSample{Policy: policy, Cache: cache, Time: start, Signatures: policy.Endorsers}
+		signatures := make([][]byte, len(keys))
+		for i, key := range keys {
+			sig, err := key.Sign(digest)
+			if err != nil {
+				return nil, err
+			}
+			signatures[i] = sig
+		}
+		s.Endorse = time.Since(start)
+
+		start = time.Now()
+		valid := 0
+		for i, sig := range signatures {
+			ok, err := verifiers.verify(identities[i], digest, sig)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				valid++
+			}
+		}
+		s.Commit = time.Since(start)
+		if valid < policy.Required {
+			return nil, fmt.Errorf("transaction %d: %d of %d endorsements valid", tx, valid, policy.Required)
+		}
+
+		for i := range signatures {
+			s.Size += len(identities[i]) + len(signatures[i])
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// proposalResponseDigest stands in for the hash of a proposal response
+func proposalResponseDigest(policy EndorsementPolicy, tx int) []byte {
+	b := binary.BigEndian.AppendUint32([]byte(policy.String()), uint32(tx))
+	d := sha256.Sum256(b)
+	return d[:]
+}
+
+// WriteEndorsementCSV writes samples as dataset rows of the validation
+// phase, labelled with their endorsement policy and key cache scenario
+func WriteEndorsementCSV(w io.Writer, runID int, samples []EndorsementSample) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(endorsementColumns); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		err := cw.Write([]string{
+			strconv.FormatInt(s.Time.UnixMilli(), 10),
+			strconv.Itoa(runID),
+			"HYBRID",
+			EndorsementLoadProfile,
+			"validation",
+			s.Policy.String(),
+			string(s.Cache),
+			strconv.Itoa(s.Signatures),
+			formatMicros(s.Endorse),
+			formatMicros(s.Commit),
+			strconv.Itoa(s.Size),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatMicros(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Microsecond), 'f', 3, 64)
+}
@@ -0,0 +1,53 @@
+package bench
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndorsementPolicy(t *testing.T) {
+	p, err := ParseEndorsementPolicy("3-of-5")
+	require.NoError(t, err)
+	assert.Equal(t, EndorsementPolicy{Required: 3, Endorsers: 5}, p)
+
+	for _, s := range []string{"0-of-3", "4-of-3", "2of3", "2-of-3x", ""} {
+		_, err := ParseEndorsementPolicy(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMeasureEndorsement(t *testing.T) {
+	small, err := MeasureEndorsement(EndorsementPolicy{Required: 1, Endorsers: 1}, CacheCold, 3)
+	require.NoError(t, err)
+	large, err := MeasureEndorsement(EndorsementPolicy{Required: 2, Endorsers: 3}, CacheWarm, 3)
+	require.NoError(t, err)
+	require.Len(t, large, 3)
+	assert.Equal(t, 3, large[0].Signatures)
+	// DER ECDSA signatures vary by a few bytes
+	assert.InDelta(t, 3*small[0].Size, large[0].Size, 6)
+	assert.Positive(t, large[0].Commit)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEndorsementCSV(&buf, 1, append(small, large...)))
+	path := filepath.Join(t.TempDir(), "HYBRID_ENDORSEMENT_RUN1.csv")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	d, err := LoadCSV(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Group{
+		{CryptoMode: "HYBRID", LoadProfile: EndorsementLoadProfile, EndorsementPolicy: "1-of-1", KeyCache: "cold"},
+		{CryptoMode: "HYBRID", LoadProfile: EndorsementLoadProfile, EndorsementPolicy: "2-of-3", KeyCache: "warm"},
+	}, d.Groups())
+	assert.Equal(t, []float64{3, 3, 3}, d.Samples(d.Groups()[1], "endorsement_count"))
+	assert.Len(t, d.Samples(d.Groups()[0], "time_verify_us"), 3)
+
+	_, err = MeasureEndorsement(EndorsementPolicy{Required: 2, Endorsers: 1}, CacheWarm, 1)
+	assert.Error(t, err)
+	_, err = MeasureEndorsement(EndorsementPolicy{Required: 1, Endorsers: 1}, "lukewarm", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/telemetry"
+)
+
+// EnergyAlgorithms are the signature algorithms measured by
+// MeasureSigningEnergy, named as the dataset's cryptosystem column
+var EnergyAlgorithms = []string{"ECDSA", "DILITHIUM3", "HYBRID"}
+
+// EnergyResult is the energy attributed to signing with an algorithm
+type EnergyResult struct {
+	Algorithm  string
+	Signatures int
+	Duration   time.Duration
+	// Joules is the energy consumed while signing, minus idle consumption
+	// over the same duration
+	Joules float64
+	// IdleWatts is the host's power draw measured before signing
+	IdleWatts float64
+}
+
+// JoulesPer1k is the estimated energy of 1000 signatures
+func (r *EnergyResult) JoulesPer1k() float64 {
+	if r.Signatures == 0 {
+		return 0
+	}
+	return r.Joules / float64(r.Signatures) * 1000
+}
+
+func (r *EnergyResult) String() string {
+	return fmt.Sprintf("%-10s %8d signatures in %v, %.3f J above idle (%.2f W), %.4f J per 1k signatures",
+		r.Algorithm, r.Signatures, r.Duration.Round(time.Millisecond), r.Joules, r.IdleWatts, r.JoulesPer1k())
+}
+
+// MeasureSigningEnergy signs with algorithm on one core for duration and
+// reads meter around it. The idle power, measured over idle first, is
+// subtracted so the result estimates the marginal cost of signing. Meters
+// cover the whole host, so runs should be made on an otherwise quiet one.
+func MeasureSigningEnergy(meter telemetry.EnergyMeter, algorithm string, duration, idle time.Duration) (*EnergyResult, error) {
+	sign, clean, err := energySigner(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	defer clean()
+
+	r := &EnergyResult{Algorithm: algorithm}
+	if idle > 0 {
+		before, err := meter.Energy()
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		time.Sleep(idle)
+		after, err := meter.Energy()
+		if err != nil {
+			return nil, err
+		}
+		r.IdleWatts = (after - before) / time.Since(start).Seconds()
+	}
+
+	digest := sha256.Sum256([]byte("energy"))
+	before, err := meter.Energy()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	for time.Since(start) < duration {
+		if err := sign(digest[:]); err != nil {
+			return nil, err
+		}
+		r.Signatures++
+	}
+	after, err := meter.Energy()
+	if err != nil {
+		return nil, err
+	}
+	r.Duration = time.Since(start)
+	r.Joules = after - before - r.IdleWatts*r.Duration.Seconds()
+	return r, nil
+}
+
+// energySigner returns a signing function for algorithm and its cleanup
+func energySigner(algorithm string) (func([]byte) error, func(), error) {
+	switch algorithm {
+	case "ECDSA":
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(d []byte) error {
+			_, err := ecdsa.SignASN1(rand.Reader, key, d)
+			return err
+		}, func() {}, nil
+	case "DILITHIUM3":
+		signer, err := core.NewPQCSigner()
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(d []byte) error {
+			_, err := signer.Sign(d)
+			return err
+		}, signer.Clean, nil
+	case "HYBRID":
+		key, err := core.GenerateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(d []byte) error {
+			_, err := key.Sign(d)
+			return err
+		}, key.Clean, nil
+	}
+	return nil, nil, fmt.Errorf("unknown algorithm %q, expected one of %v", algorithm, EnergyAlgorithms)
+}
+
+// signingEnergyColumns is the CSV header written by WriteSigningEnergyCSV
+var signingEnergyColumns = []string{
+	"timestamp_epoch_ms", "run_id", "cryptosystem", "signatures", "duration_ms", "energy_j", "idle_power_w", "energy_per_1k_sign_j",
+}
+
+// WriteSigningEnergyCSV writes one dataset row per algorithm measured in run
+func WriteSigningEnergyCSV(w io.Writer, runID int, at time.Time, results []*EnergyResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(signingEnergyColumns); err != nil {
+		return err
+	}
+	for _, r := range results {
+		err := cw.Write([]string{
+			strconv.FormatInt(at.UnixMilli(), 10),
+			strconv.Itoa(runID),
+			r.Algorithm,
+			strconv.Itoa(r.Signatures),
+			strconv.FormatFloat(float64(r.Duration)/float64(time.Millisecond), 'f', 3, 64),
+			strconv.FormatFloat(r.Joules, 'f', 6, 64),
+			strconv.FormatFloat(r.IdleWatts, 'f', 3, 64),
+			strconv.FormatFloat(r.JoulesPer1k(), 'f', 6, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
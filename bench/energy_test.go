@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wallMeter draws a constant power on the wall clock
+type wallMeter struct {
+	start time.Time
+	watts float64
+}
+
+func (m *wallMeter) Energy() (float64, error) {
+	return time.Since(m.start).Seconds() * m.watts, nil
+}
+
+func TestMeasureSigningEnergy(t *testing.T) {
+	var results []*EnergyResult
+	for _, alg := range EnergyAlgorithms {
+		r, err := MeasureSigningEnergy(&wallMeter{start: time.Now(), watts: 50}, alg, 20*time.Millisecond, 10*time.Millisecond)
+		require.NoError(t, err, alg)
+		assert.Positive(t, r.Signatures, alg)
+		assert.InDelta(t, 50, r.IdleWatts, 1, alg)
+		// A constant draw is all idle: signing costs nothing above it
+		assert.InDelta(t, 0, r.JoulesPer1k(), 0.1, alg)
+		results = append(results, r)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSigningEnergyCSV(&buf, 1, time.UnixMilli(1700000000000), results))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "energy_per_1k_sign_j", rows[0][7])
+	assert.Equal(t, []string{"1700000000000", "1", "ECDSA"}, rows[1][:3])
+
+	_, err = MeasureSigningEnergy(&wallMeter{}, "RSA", time.Millisecond, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,326 @@
+package bench
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// KeystoreBackend is where a signer's hybrid key lives between signatures
+type KeystoreBackend string
+
+const (
+	// BackendMemory keeps the key in the provider, as the in-memory
+	// benchmarks do
+	BackendMemory KeystoreBackend = "memory"
+	// BackendFile reads and parses the key from a namespaced file keystore
+	// for every signature
+	BackendFile KeystoreBackend = "file"
+	// BackendKMS stores the key encrypted under a data key wrapped by a KMS:
+	// every signature pays the KMS round trip, the unwrap and the import
+	BackendKMS KeystoreBackend = "kms"
+	// BackendHSM keeps the key in an HSM: every signature pays the round
+	// trip to the device
+	BackendHSM KeystoreBackend = "hsm"
+)
+
+// KeystoreBackends are the backends qlbench keystore measures by default
+var KeystoreBackends = []KeystoreBackend{BackendMemory, BackendFile, BackendKMS, BackendHSM}
+
+// ParseKeystoreBackend parses memory, file, kms or hsm
+func ParseKeystoreBackend(s string) (KeystoreBackend, error) {
+	for _, b := range KeystoreBackends {
+		if string(b) == s {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unknown keystore backend %q, expected memory, file, kms or hsm", s)
+}
+
+// KeystoreConfig sets the remote backends up. There is no KMS client or
+// PKCS#11 module in this tree, so their network cost is a fixed round trip
+// to be set from the deployment's measured latency; the crypto is real.
+type KeystoreConfig struct {
+	// Dir holds the file keystore, a temporary directory if empty
+	Dir string
+	// KMSRoundTrip is added to every data key unwrap
+	KMSRoundTrip time.Duration
+	// HSMRoundTrip is added to every signature
+	HSMRoundTrip time.Duration
+}
+
+// KeystoreSample is one signature with its key retrieval
+type KeystoreSample struct {
+	Backend KeystoreBackend
+	Time    time.Time
+	// Retrieve is the time to get a key able to sign: nothing in memory, a
+	// file read and parse, or a KMS unwrap, decryption and import
+	Retrieve time.Duration
+	Sign     time.Duration
+}
+
+// Total is the latency a caller of Sign sees
+func (s KeystoreSample) Total() time.Duration {
+	return s.Retrieve + s.Sign
+}
+
+// keystoreColumns is the CSV header written by WriteKeystoreCSV
+var keystoreColumns = []string{
+	"timestamp_epoch_ms", "run_id", "cryptosystem", "load_profile", "operation_phase",
+	"keystore_backend", "time_retrieve_us", "time_sign_us", "time_total_us",
+}
+
+// KeystoreLoadProfile is the load profile of keystore scenario rows
+const KeystoreLoadProfile = "KEYSTORE"
+
+// keySource yields the key of one signature
+type keySource interface {
+	key() (bccsp.Key, error)
+	// release drops what key retrieved, outside the measurement
+	release()
+}
+
+// MeasureKeystore signs signs digests with a hybrid key held by backend,
+// retrieving the key for every signature as a signer that does not cache
+// it does
+func MeasureKeystore(backend KeystoreBackend, cfg KeystoreConfig, signs int) ([]KeystoreSample, error) {
+	csp, err := hybrid.New()
+	if err != nil {
+		return nil, err
+	}
+	h := csp.(*hybrid.HybridBCCSP)
+	defer h.Close()
+
+	var src keySource
+	switch backend {
+	case BackendMemory, BackendHSM:
+		k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+		if err != nil {
+			return nil, err
+		}
+		src = memorySource{k}
+	case BackendFile:
+		dir := cfg.Dir
+		if dir == "" {
+			if dir, err = os.MkdirTemp("", "qlbench-keystore-*"); err != nil {
+				return nil, err
+			}
+			defer os.RemoveAll(dir)
+		}
+		if src, err = newFileSource(csp, dir); err != nil {
+			return nil, err
+		}
+	case BackendKMS:
+		if src, err = newKMSSource(h, cfg.KMSRoundTrip); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown keystore backend %q", backend)
+	}
+
+	samples := make([]KeystoreSample, 0, signs)
+	for i := 0; i < signs; i++ {
+		digest := proposalResponseDigest(EndorsementPolicy{1, 1}, i)
+		start := time.Now()
+		s := KeystoreSample{Backend: backend, Time: start}
+		key, err := src.key()
+		if err != nil {
+			return nil, err
+		}
+		s.Retrieve = time.Since(start)
+
+		start = time.Now()
+		if backend == BackendHSM {
+			time.Sleep(cfg.HSMRoundTrip)
+		}
+		if _, err := csp.Sign(key, digest, nil); err != nil {
+			return nil, err
+		}
+		s.Sign = time.Since(start)
+		src.release()
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+type memorySource struct {
+	k bccsp.Key
+}
+
+func (s memorySource) key() (bccsp.Key, error) { return s.k, nil }
+func (s memorySource) release()                {}
+
+// fileSource loads the key from a namespaced keystore, as a peer whose
+// provider has a keystore does on GetKey
+type fileSource struct {
+	ks  *hybrid.KeyStore
+	ski []byte
+}
+
+const benchNamespace = "qlbench"
+
+func newFileSource(csp bccsp.BCCSP, dir string) (*fileSource, error) {
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		return nil, err
+	}
+	ks, err := hybrid.NewKeyStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.StoreKey(benchNamespace, k); err != nil {
+		return nil, err
+	}
+	return &fileSource{ks: ks, ski: k.SKI()}, nil
+}
+
+func (s *fileSource) key() (bccsp.Key, error) { return s.ks.GetKey(benchNamespace, s.ski) }
+func (s *fileSource) release()                {}
+
+// kmsSource holds the key encrypted under a data key, itself wrapped under
+// the KMS key: envelope encryption as AWS KMS or Vault transit do it
+type kmsSource struct {
+	h         *hybrid.HybridBCCSP
+	kek       bccsp.Key
+	wrapped   []byte
+	nonce     []byte
+	sealed    []byte
+	roundTrip time.Duration
+}
+
+// sealedKey is the plaintext of a kmsSource key
+type sealedKey struct {
+	ECDSA      []byte
+	PQCPrivate []byte
+	PQCPublic  []byte
+}
+
+func newKMSSource(h *hybrid.HybridBCCSP, roundTrip time.Duration) (*kmsSource, error) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ecdsaKey)
+	if err != nil {
+		return nil, err
+	}
+	pqc, err := hybrid.NewPQCSigner()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := asn1.Marshal(sealedKey{ECDSA: der, PQCPrivate: pqc.PrivateKey(), PQCPublic: pqc.PublicKey()})
+	pqc.Clean()
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := h.KeyGen(&hybrid.HybridKEMKeyGenOpts{Temporary: true})
+	if err != nil {
+		return nil, err
+	}
+	kekPub, err := kek.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	s := &kmsSource{h: h, kek: kek, roundTrip: roundTrip, nonce: make([]byte, 12)}
+	if s.wrapped, err = h.WrapKey(kekPub, dataKey); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(s.nonce); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	s.sealed = aead.Seal(nil, s.nonce, plaintext, nil)
+	return s, nil
+}
+
+func (s *kmsSource) key() (bccsp.Key, error) {
+	time.Sleep(s.roundTrip)
+	dataKey, err := s.h.UnwrapKey(s.kek, s.wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, s.nonce, s.sealed, nil)
+	if err != nil {
+		return nil, err
+	}
+	var sk sealedKey
+	if _, err := asn1.Unmarshal(plaintext, &sk); err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(sk.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("sealed key is not an ECDSA key")
+	}
+	return s.h.KeyImport(&hybrid.HybridPrivateKey{ECDSA: ecdsaKey, PQCPrivateKey: sk.PQCPrivate, PQCPublicKey: sk.PQCPublic},
+		&hybrid.HybridPrivateKeyImportOpts{Temporary: true})
+}
+
+// release drops the imported key, as a signer would after use
+func (s *kmsSource) release() {
+	s.h.PurgeEphemeral(0)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteKeystoreCSV writes samples as dataset rows of the endorsement phase,
+// labelled with their keystore backend
+func WriteKeystoreCSV(w io.Writer, runID int, samples []KeystoreSample) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(keystoreColumns); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		err := cw.Write([]string{
+			strconv.FormatInt(s.Time.UnixMilli(), 10),
+			strconv.Itoa(runID),
+			"HYBRID",
+			KeystoreLoadProfile,
+			"endorsement",
+			string(s.Backend),
+			formatMicros(s.Retrieve),
+			formatMicros(s.Sign),
+			formatMicros(s.Total()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureKeystore(t *testing.T) {
+	cfg := KeystoreConfig{Dir: t.TempDir(), KMSRoundTrip: time.Millisecond, HSMRoundTrip: time.Millisecond}
+	var all []KeystoreSample
+	for _, b := range KeystoreBackends {
+		samples, err := MeasureKeystore(b, cfg, 3)
+		require.NoError(t, err, b)
+		require.Len(t, samples, 3)
+		assert.Equal(t, b, samples[0].Backend)
+		assert.Positive(t, samples[0].Sign)
+		all = append(all, samples...)
+	}
+	// The round trips are paid where the backend pays them
+	assert.GreaterOrEqual(t, all[6].Retrieve, time.Millisecond, "kms")
+	assert.GreaterOrEqual(t, all[9].Sign, time.Millisecond, "hsm")
+	assert.Less(t, all[0].Retrieve, time.Millisecond, "memory")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteKeystoreCSV(&buf, 1, all))
+	path := filepath.Join(t.TempDir(), "HYBRID_KEYSTORE_RUN1.csv")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	d, err := LoadCSV(path)
+	require.NoError(t, err)
+	groups := d.Groups()
+	require.Len(t, groups, 4)
+	assert.Equal(t, Group{CryptoMode: "HYBRID", LoadProfile: KeystoreLoadProfile, KeystoreBackend: "file"}, groups[0])
+	assert.Len(t, d.Samples(groups[0], "time_total_us"), 3)
+
+	_, err = ParseKeystoreBackend("tpm")
+	assert.Error(t, err)
+	_, err = MeasureKeystore("tpm", cfg, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Platform describes the host a benchmark ran on. Results from different
+// architectures are only comparable together with it.
+type Platform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	// CPU is the processor model, or the board model on ARM boards that
+	// do not report one
+	CPU   string `json:"cpu"`
+	Cores int    `json:"cores"`
+	// Features are the CPU extensions liboqs dispatches on
+	Features []string `json:"cpu_features"`
+	// PQCImplementation is the ML-DSA implementation liboqs selects on
+	// this CPU when built with OQS_DIST_BUILD, the default: avx2, aarch64
+	// or ref. It is go in binaries built with the purego tag.
+	PQCImplementation string `json:"pqc_implementation"`
+	GoVersion         string `json:"go_version"`
+}
+
+// liboqsFeatures are the CPU extensions liboqs uses per architecture, as
+// named in /proc/cpuinfo
+var liboqsFeatures = map[string][]string{
+	"amd64": {"aes", "avx2", "avx512f", "bmi1", "bmi2", "popcnt", "sse2"},
+	"arm64": {"aes", "asimd", "sha3", "sve"},
+}
+
+// DetectPlatform describes the running host. CPU details come from
+// /proc/cpuinfo and are empty on other systems.
+func DetectPlatform() Platform {
+	p := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH, Cores: runtime.NumCPU(), GoVersion: runtime.Version()}
+	if f, err := os.Open("/proc/cpuinfo"); err == nil {
+		defer f.Close()
+		p.readCPUInfo(f)
+	}
+	p.PQCImplementation = pqcImplementation(p.Arch, p.Features)
+	if core.PQCBackend != "liboqs" {
+		p.PQCImplementation = core.PQCBackend
+	}
+	return p
+}
+
+// readCPUInfo fills the CPU model and features from the first processor
+// of a /proc/cpuinfo listing
+func (p *Platform) readCPUInfo(r io.Reader) {
+	wanted := map[string]bool{}
+	for _, f := range liboqsFeatures[p.Arch] {
+		wanted[f] = true
+	}
+	var board string
+	features := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "model name":
+			if p.CPU == "" {
+				p.CPU = value
+			}
+		case "Model", "Hardware":
+			board = value
+		case "flags", "Features":
+			for _, f := range strings.Fields(value) {
+				if wanted[f] {
+					features[f] = true
+				}
+			}
+		}
+	}
+	if p.CPU == "" {
+		p.CPU = board
+	}
+	p.Features = nil
+	for f := range features {
+		p.Features = append(p.Features, f)
+	}
+	sort.Strings(p.Features)
+}
+
+// pqcImplementation is the ML-DSA-65 code path liboqs picks at runtime
+func pqcImplementation(arch string, features []string) string {
+	has := map[string]bool{}
+	for _, f := range features {
+		has[f] = true
+	}
+	switch {
+	case arch == "amd64" && has["avx2"] && has["popcnt"]:
+		return "avx2"
+	case arch == "arm64" && has["asimd"]:
+		return "aarch64"
+	}
+	return "ref"
+}
+
+// WritePlatform writes p as indented JSON
+func WritePlatform(w io.Writer, p Platform) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// ReadPlatform decodes a description written by WritePlatform
+func ReadPlatform(r io.Reader) (Platform, error) {
+	var p Platform
+	err := json.NewDecoder(r).Decode(&p)
+	return p, err
+}
+
+// Comparable reports whether results measured on p and other can be
+// compared: same architecture and ML-DSA implementation
+func (p Platform) Comparable(other Platform) bool {
+	return p.Arch == other.Arch && p.PQCImplementation == other.PQCImplementation
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Arch
+	if p.CPU != "" {
+		s += " " + p.CPU
+	}
+	return s + ", ML-DSA " + p.PQCImplementation
+}
@@ -0,0 +1,77 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const x86CPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+flags		: fpu sse2 popcnt aes avx avx2 bmi1 bmi2 avx512f
+
+processor	: 1
+model name	: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+`
+
+const graviton3CPUInfo = `processor	: 0
+BogoMIPS	: 2100.00
+Features	: fp asimd evtstrm aes pmull sha1 sha2 crc32 sha3 sve
+CPU implementer	: 0x41
+CPU part	: 0xd40
+`
+
+const pi4CPUInfo = `processor	: 0
+Features	: fp asimd evtstrm crc32 cpuid
+CPU part	: 0xd08
+
+Hardware	: BCM2835
+Model		: Raspberry Pi 4 Model B Rev 1.4
+`
+
+func TestReadCPUInfo(t *testing.T) {
+	for _, c := range []struct {
+		arch, info, cpu, impl string
+		features              []string
+	}{
+		{"amd64", x86CPUInfo, "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz", "avx2",
+			[]string{"aes", "avx2", "avx512f", "bmi1", "bmi2", "popcnt", "sse2"}},
+		{"amd64", "model name : QEMU Virtual CPU\nflags : fpu sse2\n", "QEMU Virtual CPU", "ref", []string{"sse2"}},
+		{"arm64", graviton3CPUInfo, "", "aarch64", []string{"aes", "asimd", "sha3", "sve"}},
+		{"arm64", pi4CPUInfo, "Raspberry Pi 4 Model B Rev 1.4", "aarch64", []string{"asimd"}},
+		{"riscv64", "isa : rv64imafdc\n", "", "ref", nil},
+	} {
+		p := Platform{Arch: c.arch}
+		p.readCPUInfo(strings.NewReader(c.info))
+		assert.Equal(t, c.cpu, p.CPU, c.arch)
+		assert.Equal(t, c.features, p.Features, c.arch)
+		assert.Equal(t, c.impl, pqcImplementation(p.Arch, p.Features), c.arch)
+	}
+}
+
+func TestDetectPlatform(t *testing.T) {
+	p := DetectPlatform()
+	assert.Equal(t, runtime.GOARCH, p.Arch)
+	assert.Positive(t, p.Cores)
+	assert.NotEmpty(t, p.PQCImplementation)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlatform(&buf, p))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, runtime.GOARCH, decoded["arch"])
+	assert.Contains(t, decoded, "pqc_implementation")
+
+	read, err := ReadPlatform(&buf)
+	require.NoError(t, err)
+	assert.True(t, read.Comparable(p))
+	pi := Platform{OS: "linux", Arch: "arm64", CPU: "Raspberry Pi 4 Model B Rev 1.4", PQCImplementation: "aarch64"}
+	assert.False(t, pi.Comparable(p))
+	assert.Equal(t, "linux/arm64 Raspberry Pi 4 Model B Rev 1.4, ML-DSA aarch64", pi.String())
+}
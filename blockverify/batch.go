@@ -0,0 +1,246 @@
+package blockverify
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// Signature roles
+const (
+	// RoleCreator is the client signature over the transaction envelope
+	RoleCreator = "creator"
+	// RoleEndorser is an endorsement of the transaction
+	RoleEndorser = "endorser"
+)
+
+// ErrInvalidTxSignature is returned for transaction signatures that do not
+// verify
+var ErrInvalidTxSignature = errors.New("invalid transaction signature")
+
+// Signature is a signature carried by a transaction: Creator, a serialized
+// identity, signed the SHA-256 of Payload
+type Signature struct {
+	// Tx is the index of the transaction in the block data
+	Tx        int
+	Role      string
+	Creator   []byte
+	Payload   []byte
+	Signature []byte
+}
+
+// TransactionSignatures returns the channel header of an envelope and the
+// creator and endorser signatures it carries
+func TransactionSignatures(raw []byte) (*fabproto.ChannelHeader, []Signature, error) {
+	env, err := fabproto.UnmarshalEnvelope(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := fabproto.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	if payload.Header == nil {
+		return nil, nil, errors.New("payload has no header")
+	}
+	chdr, err := fabproto.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	shdr, err := fabproto.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	sigs := []Signature{{Role: RoleCreator, Creator: shdr.Creator, Payload: env.Payload, Signature: env.Signature}}
+	if chdr.Type != fabproto.HeaderTypeEndorserTransaction {
+		return chdr, sigs, nil
+	}
+
+	tx, err := fabproto.UnmarshalTransaction(payload.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, action := range tx.Actions {
+		ccPayload, err := fabproto.UnmarshalChaincodeActionPayload(action.Payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ccPayload.Action == nil {
+			continue
+		}
+		for _, e := range ccPayload.Action.Endorsements {
+			sigs = append(sigs, Signature{Role: RoleEndorser, Creator: e.Endorser, Payload: e.SignedBytes(ccPayload.Action.ProposalResponsePayload), Signature: e.Signature})
+		}
+	}
+	return chdr, sigs, nil
+}
+
+// BatchResult is the outcome of one signature of a batch
+type BatchResult struct {
+	// Identity is the deserialized creator, nil if it is invalid
+	Identity *msp.Identity
+	Err      error
+}
+
+// BlockResult is the outcome of BatchVerifier.VerifyBlock
+type BlockResult struct {
+	Number     uint64
+	Signatures []Signature
+	// Results holds the outcome of each of Signatures
+	Results []BatchResult
+	// Malformed maps the index of envelopes that do not decode to the
+	// decoding error; they carry no signatures
+	Malformed map[int]error
+	// Creators is the number of distinct identities deserialized
+	Creators int
+}
+
+// Valid reports whether every signature of transaction tx verified
+func (r *BlockResult) Valid(tx int) bool {
+	if _, ok := r.Malformed[tx]; ok {
+		return false
+	}
+	for i, s := range r.Signatures {
+		if s.Tx == tx && r.Results[i].Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchVerifier verifies the transaction signatures of a whole block in one
+// parallel pass instead of one transaction at a time: each distinct creator
+// is deserialized once and its imported key is shared by all the signatures
+// it made, then every signature is verified on the hybrid worker pool with
+// PriorityCritical. Verification runs under the provider's channel
+// policies, resolved for the block, e.g. by a hybrid.CutoverResolver; give
+// it a hybrid.VerifierCache so that the ML-DSA verifiers of recurring
+// endorsers also survive across blocks.
+type BatchVerifier struct {
+	csp          bccsp.BCCSP
+	deserializer *msp.Deserializer
+}
+
+// NewBatchVerifier verifies with csp the identities returned by
+// deserializer, which usually shares csp
+func NewBatchVerifier(csp bccsp.BCCSP, deserializer *msp.Deserializer) *BatchVerifier {
+	return &BatchVerifier{csp: csp, deserializer: deserializer}
+}
+
+// VerifyBlock extracts the creator and endorser signatures of every
+// transaction of block and verifies them as a batch. Policies are resolved
+// for the block number and the channel header timestamp of its first
+// transaction, so every peer, and every replay, resolves them alike.
+func (b *BatchVerifier) VerifyBlock(block *fabproto.Block) (*BlockResult, error) {
+	if block.Header == nil {
+		return nil, errors.New("block has no header")
+	}
+	r := &BlockResult{Number: block.Header.Number, Malformed: map[int]error{}}
+	ref := &hybrid.BlockRef{Number: block.Header.Number}
+	channels := make([]string, 0, len(block.Data))
+	for tx, raw := range block.Data {
+		chdr, sigs, err := TransactionSignatures(raw)
+		if err != nil {
+			r.Malformed[tx] = err
+			continue
+		}
+		if ref.Timestamp.IsZero() {
+			ref.Timestamp = chdr.Timestamp
+		}
+		for _, s := range sigs {
+			s.Tx = tx
+			r.Signatures = append(r.Signatures, s)
+			channels = append(channels, chdr.ChannelId)
+		}
+	}
+	r.Results, r.Creators = b.verify(r.Signatures, channels, ref)
+	return r, nil
+}
+
+// Verify verifies sigs of channel as a batch, outside of a block; results
+// are in the order of sigs
+func (b *BatchVerifier) Verify(channel string, sigs []Signature) []BatchResult {
+	channels := make([]string, len(sigs))
+	for i := range channels {
+		channels[i] = channel
+	}
+	results, _ := b.verify(sigs, channels, nil)
+	return results
+}
+
+// creator is a deserialized identity shared by the signatures of a batch
+type creator struct {
+	id  *msp.Identity
+	err error
+}
+
+// verify returns the results of sigs, each one verified under the policies
+// of its channel for block, if not nil, and the number of distinct creators
+func (b *BatchVerifier) verify(sigs []Signature, channels []string, block *hybrid.BlockRef) ([]BatchResult, int) {
+	results := make([]BatchResult, len(sigs))
+	creators := map[string]*creator{}
+	for i, s := range sigs {
+		c, ok := creators[string(s.Creator)]
+		if !ok {
+			c = &creator{}
+			c.id, c.err = b.deserializer.DeserializeIdentity(s.Creator)
+			if c.err != nil {
+				c.err = fmt.Errorf("invalid %s: %w", s.Role, c.err)
+			}
+			creators[string(s.Creator)] = c
+		}
+		results[i] = BatchResult{Identity: c.id, Err: c.err}
+	}
+
+	async, _ := b.csp.(hybrid.AsyncSigner)
+	pending := make([]<-chan hybrid.VerifyResult, len(sigs))
+	var wg sync.WaitGroup
+	// Without a worker pool, bound the goroutines to the CPUs
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i := range sigs {
+		if results[i].Err != nil {
+			continue
+		}
+		id, s := results[i].Identity, sigs[i]
+		digest := sha256.Sum256(s.Payload)
+		opts := &hybrid.HybridSignerOpts{Channel: channels[i], MSPID: id.MSPID, Priority: hybrid.PriorityCritical, Block: block}
+		if async != nil {
+			// Queue the whole batch before waiting for any result
+			pending[i] = async.VerifyAsync(id.Key, s.Signature, digest[:], opts)
+			continue
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			valid, err := b.csp.Verify(id.Key, s.Signature, digest[:], opts)
+			results[i].Err = verifyError(id, s.Role, valid, err)
+		}()
+	}
+	wg.Wait()
+	for i, done := range pending {
+		if done != nil {
+			r := <-done
+			results[i].Err = verifyError(results[i].Identity, sigs[i].Role, r.Valid, r.Err)
+		}
+	}
+	return results, len(creators)
+}
+
+func verifyError(id *msp.Identity, role string, valid bool, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w: %s %s/%s: %w", ErrInvalidTxSignature, role, id.MSPID, id.Certificate.Subject.CommonName, err)
+	}
+	if !valid {
+		return fmt.Errorf("%w: %s %s/%s", ErrInvalidTxSignature, role, id.MSPID, id.Certificate.Subject.CommonName)
+	}
+	return nil
+}
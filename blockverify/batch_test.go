@@ -0,0 +1,271 @@
+package blockverify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+func signingIdentity(tb testing.TB, csp bccsp.BCCSP, mspID string) *msp.SigningIdentity {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca." + mspID}, 0)
+	require.NoError(tb, err)
+	issued, err := root.Issue(ca.Request{CommonName: "peer0." + mspID, OrganizationalUnit: "peer"})
+	require.NoError(tb, err)
+	dir := filepath.Join(tb.TempDir(), "msp")
+	require.NoError(tb, ca.WriteMSP(dir, root, issued, ca.MSPOptions{}))
+	id, err := msp.LoadSigningIdentity(csp, dir, mspID)
+	require.NoError(tb, err)
+	return id
+}
+
+func sign(tb testing.TB, id *msp.SigningIdentity, msg []byte) []byte {
+	sig, err := id.Sign(msg)
+	require.NoError(tb, err)
+	return sig
+}
+
+// transaction returns an endorser transaction envelope
+func transaction(tb testing.TB, txID string, creator *msp.SigningIdentity, endorsers ...*msp.SigningIdentity) []byte {
+	prp := []byte("proposal response payload " + txID)
+	action := &fabproto.ChaincodeEndorsedAction{ProposalResponsePayload: prp}
+	for _, e := range endorsers {
+		endorsement := &fabproto.Endorsement{Endorser: e.Serialize()}
+		endorsement.Signature = sign(tb, e, endorsement.SignedBytes(prp))
+		action.Endorsements = append(action.Endorsements, endorsement)
+	}
+	tx := &fabproto.Transaction{Actions: []*fabproto.TransactionAction{{
+		Header:  (&fabproto.SignatureHeader{Creator: creator.Serialize()}).Marshal(),
+		Payload: (&fabproto.ChaincodeActionPayload{Action: action}).Marshal(),
+	}}}
+	payload := (&fabproto.Payload{
+		Header: &fabproto.Header{
+			ChannelHeader:   (&fabproto.ChannelHeader{Type: fabproto.HeaderTypeEndorserTransaction, ChannelId: "mychannel", TxId: txID}).Marshal(),
+			SignatureHeader: (&fabproto.SignatureHeader{Creator: creator.Serialize(), Nonce: []byte("nonce")}).Marshal(),
+		},
+		Data: tx.Marshal(),
+	}).Marshal()
+	return (&fabproto.Envelope{Payload: payload, Signature: sign(tb, creator, payload)}).Marshal()
+}
+
+func TestBatchVerifier(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	org1 := signingIdentity(t, csp, "Org1MSP")
+	org2 := signingIdentity(t, csp, "Org2MSP")
+
+	tampered, err := fabproto.UnmarshalEnvelope(transaction(t, "tx3", org2, org1))
+	require.NoError(t, err)
+	tampered.Signature = sign(t, org2, []byte("something else"))
+	block := &fabproto.Block{
+		Header: &fabproto.BlockHeader{Number: 4},
+		Data: [][]byte{
+			transaction(t, "tx1", org1, org1, org2),
+			transaction(t, "tx2", org2, org1, org2),
+			tampered.Marshal(),
+			[]byte("not an envelope"),
+		},
+	}
+
+	v := NewBatchVerifier(csp, &msp.Deserializer{CSP: csp})
+	r, err := v.VerifyBlock(block)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), r.Number)
+	require.Len(t, r.Signatures, 8)
+	require.Len(t, r.Results, 8)
+	assert.Equal(t, 2, r.Creators)
+	assert.Contains(t, r.Malformed, 3)
+
+	assert.True(t, r.Valid(0))
+	assert.True(t, r.Valid(1))
+	assert.False(t, r.Valid(2))
+	assert.False(t, r.Valid(3))
+	for i, s := range r.Signatures {
+		if s.Tx == 2 && s.Role == RoleCreator {
+			assert.ErrorIs(t, r.Results[i].Err, ErrInvalidTxSignature)
+			continue
+		}
+		assert.NoError(t, r.Results[i].Err, "signature %d", i)
+	}
+
+	// Signatures of the same creator share its deserialized identity
+	assert.Equal(t, "Org1MSP", r.Results[0].Identity.MSPID)
+	assert.Same(t, r.Results[0].Identity, r.Results[1].Identity)
+	assert.Same(t, r.Results[0].Identity, r.Results[4].Identity)
+
+	// Creators that do not deserialize fail their signatures only
+	msg := []byte("message")
+	results := v.Verify("mychannel", []Signature{
+		{Role: RoleEndorser, Creator: []byte("garbage"), Payload: msg, Signature: sign(t, org1, msg)},
+		{Role: RoleEndorser, Creator: org1.Serialize(), Payload: msg, Signature: sign(t, org1, msg)},
+	})
+	require.Len(t, results, 2)
+	assert.Error(t, results[0].Err)
+	assert.Nil(t, results[0].Identity)
+	assert.NoError(t, results[1].Err)
+}
+
+// legacyIdentity returns a classical ECDSA identity of LegacyMSP and its CA
+func legacyIdentity(tb testing.TB) (*ecdsa.PrivateKey, []byte, *hybridx509.Certificate) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "ca.legacy.com"},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(tb, err)
+	caCert, err := hybridx509.ParseCertificate(caDER)
+	require.NoError(tb, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "peer0.legacy.com"},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert.Certificate, &key.PublicKey, caKey)
+	require.NoError(tb, err)
+	return key, (&fabproto.SerializedIdentity{Mspid: "LegacyMSP", IdBytes: hybridx509.EncodeCertificatePEM(der)}).Marshal(), caCert
+}
+
+// legacyBlock returns a block of one config transaction created at ts and
+// signed by a classical key
+func legacyBlock(tb testing.TB, number uint64, ts time.Time, key *ecdsa.PrivateKey, creator []byte) *fabproto.Block {
+	payload := (&fabproto.Payload{
+		Header: &fabproto.Header{
+			ChannelHeader:   (&fabproto.ChannelHeader{Type: 1, ChannelId: "mychannel", Timestamp: ts}).Marshal(),
+			SignatureHeader: (&fabproto.SignatureHeader{Creator: creator, Nonce: []byte("nonce")}).Marshal(),
+		},
+		Data: []byte("config update"),
+	}).Marshal()
+	digest := sha256.Sum256(payload)
+	return &fabproto.Block{
+		Header: &fabproto.BlockHeader{Number: number},
+		Data:   [][]byte{(&fabproto.Envelope{Payload: payload, Signature: legacySignature(tb, key, digest[:])}).Marshal()},
+	}
+}
+
+func legacySignature(tb testing.TB, key *ecdsa.PrivateKey, digest []byte) []byte {
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	require.NoError(tb, err)
+	// Fabric signatures are low-S
+	if half := new(big.Int).Rsh(elliptic.P256().Params().N, 1); s.Cmp(half) > 0 {
+		s.Sub(elliptic.P256().Params().N, s)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(tb, err)
+	return sig
+}
+
+func TestBatchVerifierCutover(t *testing.T) {
+	cutover := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	resolver, err := hybrid.NewCutoverResolver(hybrid.CutoverConfig{Time: cutover, Height: 10},
+		&hybrid.ChannelPolicies{Default: hybrid.PolicyHybridOR}, nil)
+	require.NoError(t, err)
+	csp, err := hybrid.New(hybrid.WithLegacyECDSA(true), hybrid.WithPolicyResolver(resolver))
+	require.NoError(t, err)
+	key, creator, caCert := legacyIdentity(t)
+	d := &msp.Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"LegacyMSP": {caCert}}, AllowClassical: true}
+	v := NewBatchVerifier(csp, d)
+
+	before := cutover.Add(-time.Hour)
+	blocks := []struct {
+		block *fabproto.Block
+		valid bool
+	}{
+		{legacyBlock(t, 9, before, key, creator), true},
+		// The height is reached
+		{legacyBlock(t, 10, before, key, creator), false},
+		// The date is reached
+		{legacyBlock(t, 3, cutover, key, creator), false},
+		// An older block replayed after the cutover
+		{legacyBlock(t, 2, before, key, creator), true},
+	}
+	for _, b := range blocks {
+		r, err := v.VerifyBlock(b.block)
+		require.NoError(t, err)
+		require.Len(t, r.Results, 1)
+		assert.Equal(t, b.valid, r.Valid(0), "block %d", r.Number)
+		if !b.valid {
+			assert.ErrorIs(t, r.Results[0].Err, hybrid.ErrClassicalKey)
+		}
+	}
+
+	// Outside of a block the channel policy applies
+	payload := []byte("proposal")
+	digest := sha256.Sum256(payload)
+	results := v.Verify("mychannel", []Signature{{Role: RoleCreator, Creator: creator, Payload: payload, Signature: legacySignature(t, key, digest[:])}})
+	assert.NoError(t, results[0].Err)
+
+	// Blocks on either side of the cutover verified concurrently resolve
+	// their own policy
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		b := blocks[i%len(blocks)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := v.VerifyBlock(b.block)
+			if assert.NoError(t, err) {
+				assert.Equal(t, b.valid, r.Valid(0), "block %d", r.Number)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkBlockSignatures compares verifying the signatures of a 100
+// transaction block one by one with a batch
+func BenchmarkBlockSignatures(b *testing.B) {
+	csp, err := hybrid.New(hybrid.WithVerifierCache(hybrid.NewVerifierCache(16, nil)))
+	require.NoError(b, err)
+	orgs := []*msp.SigningIdentity{signingIdentity(b, csp, "Org1MSP"), signingIdentity(b, csp, "Org2MSP")}
+	block := &fabproto.Block{Header: &fabproto.BlockHeader{Number: 1}}
+	for i := 0; i < 100; i++ {
+		block.Data = append(block.Data, transaction(b, "tx", orgs[i%2], orgs...))
+	}
+	d := &msp.Deserializer{CSP: csp}
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, raw := range block.Data {
+				_, sigs, err := TransactionSignatures(raw)
+				require.NoError(b, err)
+				for _, s := range sigs {
+					id, err := d.DeserializeIdentity(s.Creator)
+					require.NoError(b, err)
+					digest := sha256.Sum256(s.Payload)
+					valid, err := csp.Verify(id.Key, s.Signature, digest[:], &hybrid.HybridSignerOpts{Channel: "mychannel", MSPID: id.MSPID})
+					require.NoError(b, err)
+					require.True(b, valid)
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		v := NewBatchVerifier(csp, d)
+		for i := 0; i < b.N; i++ {
+			r, err := v.VerifyBlock(block)
+			require.NoError(b, err)
+			require.Equal(b, 2, r.Creators)
+		}
+	})
+}
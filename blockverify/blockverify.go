@@ -0,0 +1,121 @@
+// Package blockverify lets light clients and auditors check blocks obtained
+// from any source (deliver service, ledger export, peer channel fetch)
+// against the orderer organizations they trust, without running a peer.
+package blockverify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+var (
+	// ErrDataHashMismatch is returned when the header does not commit to
+	// the block data
+	ErrDataHashMismatch = errors.New("block data hash mismatch")
+	// ErrBrokenChain is returned when a block does not follow the previous one
+	ErrBrokenChain = errors.New("block does not extend the chain")
+)
+
+// Result describes a verified block
+type Result struct {
+	Number       uint64
+	Hash         []byte
+	PreviousHash []byte
+	Signers      []*msp.Identity
+}
+
+// Verifier checks single blocks
+type Verifier struct {
+	signatures *orderer.BlockVerifier
+}
+
+// NewVerifier checks signatures with signatures, e.g. built by
+// orderer.BlockValidationConfig.NewVerifier from the orderer MSP folders
+func NewVerifier(signatures *orderer.BlockVerifier) *Verifier {
+	return &Verifier{signatures: signatures}
+}
+
+// Verify decodes a marshaled common.Block and verifies it
+func (v *Verifier) Verify(raw []byte) (*Result, error) {
+	block, err := fabproto.UnmarshalBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	return v.VerifyBlock(block)
+}
+
+// VerifyBlock checks that the header commits to the block data and carries
+// enough valid orderer signatures
+func (v *Verifier) VerifyBlock(block *fabproto.Block) (*Result, error) {
+	if block.Header == nil {
+		return nil, errors.New("block has no header")
+	}
+	if !bytes.Equal(block.Header.DataHash, DataHash(block.Data)) {
+		return nil, fmt.Errorf("block %d: %w", block.Header.Number, ErrDataHashMismatch)
+	}
+	signers, err := v.signatures.Signers(block.Header, block.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Number:       block.Header.Number,
+		Hash:         HeaderHash(block.Header),
+		PreviousHash: block.Header.PreviousHash,
+		Signers:      signers,
+	}, nil
+}
+
+// Chain verifies a sequence of blocks: each one is checked by the Verifier
+// and must directly follow the previous one, which rejects gaps and forks
+type Chain struct {
+	verifier *Verifier
+	last     *Result
+}
+
+// NewChain starts a chain at the first block passed to Append. last, when
+// not nil, is a previously verified block to continue from.
+func NewChain(verifier *Verifier, last *Result) *Chain {
+	return &Chain{verifier: verifier, last: last}
+}
+
+// Append verifies raw and checks that it directly follows the last block
+func (c *Chain) Append(raw []byte) (*Result, error) {
+	res, err := c.verifier.Verify(raw)
+	if err != nil {
+		return nil, err
+	}
+	if c.last != nil {
+		if res.Number != c.last.Number+1 {
+			return nil, fmt.Errorf("block %d after block %d: %w", res.Number, c.last.Number, ErrBrokenChain)
+		}
+		if !bytes.Equal(res.PreviousHash, c.last.Hash) {
+			return nil, fmt.Errorf("block %d: previous hash mismatch: %w", res.Number, ErrBrokenChain)
+		}
+	}
+	c.last = res
+	return res, nil
+}
+
+// Last returns the last verified block, nil if none
+func (c *Chain) Last() *Result {
+	return c.last
+}
+
+// HeaderHash is the block hash referenced by the next block's PreviousHash
+func HeaderHash(h *fabproto.BlockHeader) []byte {
+	sum := sha256.Sum256(fabproto.BlockHeaderBytes(h))
+	return sum[:]
+}
+
+// DataHash is the hash a header stores for the block data
+// (protoutil.BlockDataHash)
+func DataHash(data [][]byte) []byte {
+	sum := sha256.Sum256(bytes.Join(data, nil))
+	return sum[:]
+}
@@ -0,0 +1,68 @@
+package blockverify
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+func TestChain(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	id, err := root.Issue(ca.Request{CommonName: "orderer0.example.com", OrganizationalUnit: "orderer"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(mspDir, root, id, ca.MSPOptions{}))
+
+	signer, err := orderer.BlockSigningConfig{LocalMSPDir: mspDir, LocalMSPID: "OrdererMSP"}.NewSigner(csp)
+	require.NoError(t, err)
+	sigs, err := orderer.BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": mspDir}}.NewVerifier(csp)
+	require.NoError(t, err)
+	v := NewVerifier(sigs)
+
+	var prev []byte
+	var blocks [][]byte
+	for n := uint64(0); n < 3; n++ {
+		data := [][]byte{[]byte("tx")}
+		b := &fabproto.Block{
+			Header: &fabproto.BlockHeader{Number: n, PreviousHash: prev, DataHash: DataHash(data)},
+			Data:   data,
+		}
+		require.NoError(t, signer.SignBlock(b, nil))
+		prev = HeaderHash(b.Header)
+		blocks = append(blocks, b.Marshal())
+	}
+
+	chain := NewChain(v, nil)
+	for _, raw := range blocks {
+		res, err := chain.Append(raw)
+		require.NoError(t, err)
+		require.Len(t, res.Signers, 1)
+		assert.Equal(t, "OrdererMSP", res.Signers[0].MSPID)
+	}
+	assert.Equal(t, uint64(2), chain.Last().Number)
+
+	// Skipping a block breaks the chain
+	gap := NewChain(v, nil)
+	_, err = gap.Append(blocks[0])
+	require.NoError(t, err)
+	_, err = gap.Append(blocks[2])
+	assert.True(t, errors.Is(err, ErrBrokenChain), err)
+
+	// Data not matching the signed header
+	b, err := fabproto.UnmarshalBlock(blocks[1])
+	require.NoError(t, err)
+	b.Data = [][]byte{[]byte("forged")}
+	_, err = v.VerifyBlock(b)
+	assert.True(t, errors.Is(err, ErrDataHashMismatch), err)
+}
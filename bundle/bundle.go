@@ -0,0 +1,416 @@
+// Package bundle packages a hybrid signature with everything needed to
+// verify it offline years later: the digest recipe, the composite
+// certificate chain of the signer, CRLs and RFC 3161 time-stamps of the
+// signature. Bundles are zip archives with a manifest listing the SHA-256
+// of every entry.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+const (
+	// ManifestFile is the bundle entry describing the others
+	ManifestFile = "manifest.json"
+	// Version is the manifest version written by Write
+	Version = 1
+
+	signatureFile = "signature.bin"
+	messageFile   = "message"
+)
+
+var (
+	// ErrHashMismatch is returned when an entry does not match the manifest
+	ErrHashMismatch = errors.New("bundle entry hash mismatch")
+	// ErrInvalidSignature is returned when the signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrUntrustedChain is returned when the certificate chain is broken or
+	// does not end at a trusted root
+	ErrUntrustedChain = errors.New("untrusted certificate chain")
+	// ErrRevoked is returned when a certificate of the chain was revoked
+	// before the time the signature is proven to exist
+	ErrRevoked = errors.New("certificate revoked")
+	// ErrExpired is returned when a certificate of the chain was not valid
+	// at the time the signature is proven to exist
+	ErrExpired = errors.New("certificate not valid at signing time")
+)
+
+// Recipe describes how the signed digest was computed from the message
+type Recipe struct {
+	// Hash is SHA-256, SHA-384, SHA-512 or SHA3-256
+	Hash string `json:"hash"`
+	// Digest is the signed value
+	Digest []byte `json:"digest"`
+}
+
+// Manifest is the content of ManifestFile
+type Manifest struct {
+	Version      int               `json:"version"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Recipe       Recipe            `json:"recipe"`
+	Policy       core.Policy       `json:"policy"`
+	Signature    string            `json:"signature"`
+	Message      string            `json:"message,omitempty"`
+	Certificates []string          `json:"certificates"`
+	CRLs         []string          `json:"crls,omitempty"`
+	Timestamps   []string          `json:"timestamps,omitempty"`
+	Files        map[string]string `json:"files"`
+}
+
+// Bundle is a signature with its verification material
+type Bundle struct {
+	Recipe    Recipe
+	Policy    core.Policy
+	Signature []byte
+	// Message, when set, is included so the digest can be recomputed
+	Message []byte
+	// Certificates is the signer certificate followed by its issuers, up
+	// to the root
+	Certificates []*hybridx509.Certificate
+	// CRLs are DER certificate revocation lists of the chain's CAs
+	CRLs [][]byte
+	// Timestamps are RFC 3161 responses or tokens over the signature
+	Timestamps [][]byte
+}
+
+// Digest computes a recipe for msg
+func Digest(hash string, msg []byte) (Recipe, error) {
+	h, ok := HashByName(hash)
+	if !ok {
+		return Recipe{}, fmt.Errorf("unsupported hash %q", hash)
+	}
+	return Recipe{Hash: hash, Digest: Sum(h, msg)}, nil
+}
+
+// Write writes b as a zip archive
+func (b *Bundle) Write(w io.Writer, createdAt time.Time) error {
+	if len(b.Certificates) == 0 {
+		return errors.New("bundle has no signer certificate")
+	}
+	m := &Manifest{
+		Version:   Version,
+		CreatedAt: createdAt.UTC().Truncate(time.Second),
+		Recipe:    b.Recipe,
+		Policy:    b.Policy,
+		Signature: signatureFile,
+		Files:     map[string]string{},
+	}
+	entries := map[string][]byte{signatureFile: b.Signature}
+	if b.Message != nil {
+		m.Message = messageFile
+		entries[messageFile] = b.Message
+	}
+	add := func(list *[]string, format string, i int, data []byte) {
+		name := fmt.Sprintf(format, i)
+		*list = append(*list, name)
+		entries[name] = data
+	}
+	for i, c := range b.Certificates {
+		add(&m.Certificates, "certs/%d.pem", i, hybridx509.EncodeCertificatePEM(c.Raw))
+	}
+	for i, crl := range b.CRLs {
+		add(&m.CRLs, "crls/%d.der", i, crl)
+	}
+	for i, ts := range b.Timestamps {
+		add(&m.Timestamps, "timestamps/%d.tsr", i, ts)
+	}
+	for name, data := range entries {
+		sum := sha256.Sum256(data)
+		m.Files[name] = hex.EncodeToString(sum[:])
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zw := zip.NewWriter(w)
+	for _, name := range append([]string{ManifestFile}, names...) {
+		data := manifest
+		if name != ManifestFile {
+			data = entries[name]
+		}
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: m.CreatedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// WriteFile writes b to path
+func (b *Bundle) WriteFile(path string, createdAt time.Time) error {
+	var buf bytes.Buffer
+	if err := b.Write(&buf, createdAt); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Read decodes a bundle, checking every entry against the manifest
+func Read(r io.ReaderAt, size int64) (*Bundle, *Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	entries := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		entries[f.Name] = data
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(entries[ManifestFile], m); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if m.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported bundle version %d", m.Version)
+	}
+	for name, data := range entries {
+		if name == ManifestFile {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if want, ok := m.Files[name]; !ok || !strings.EqualFold(want, hex.EncodeToString(sum[:])) {
+			return nil, nil, fmt.Errorf("%s: %w", name, ErrHashMismatch)
+		}
+	}
+	entry := func(name string) ([]byte, error) {
+		data, ok := entries[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing bundle entry", name)
+		}
+		return data, nil
+	}
+
+	b := &Bundle{Recipe: m.Recipe, Policy: m.Policy}
+	if b.Signature, err = entry(m.Signature); err != nil {
+		return nil, nil, err
+	}
+	if m.Message != "" {
+		if b.Message, err = entry(m.Message); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, name := range m.Certificates {
+		data, err := entry(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		cert, err := hybridx509.ParseCertificatePEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		b.Certificates = append(b.Certificates, cert)
+	}
+	for _, name := range m.CRLs {
+		data, err := entry(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		b.CRLs = append(b.CRLs, data)
+	}
+	for _, name := range m.Timestamps {
+		data, err := entry(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		b.Timestamps = append(b.Timestamps, data)
+	}
+	return b, m, nil
+}
+
+// ReadFile reads the bundle at path
+func ReadFile(path string) (*Bundle, *Manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Read(bytes.NewReader(raw), int64(len(raw)))
+}
+
+// VerifyOptions configures Bundle.Verify
+type VerifyOptions struct {
+	// Roots are the trusted root certificates. Without roots, the chain
+	// only has to be internally consistent and Result.TrustedRoot is false.
+	Roots []*hybridx509.Certificate
+	// TSARoots, when set, must issue the certificates of the time-stamp
+	// authorities
+	TSARoots *x509.CertPool
+	// Message, when set, is checked against the recipe instead of the
+	// message included in the bundle
+	Message []byte
+	// Time is when certificates are checked if no time-stamp proves the
+	// signature older. Zero means now.
+	Time time.Time
+}
+
+// Result describes a verified bundle
+type Result struct {
+	Signer *hybridx509.Certificate
+	// SigningTime is the earliest time-stamp, zero without time-stamps
+	SigningTime time.Time
+	Timestamps  []*Timestamp
+	TrustedRoot bool
+	// Revocation lists, per certificate of the chain, whether a CRL of its
+	// issuer was checked
+	Revocation []bool
+}
+
+// Verify checks the digest, the hybrid signature, the certificate chain,
+// the time-stamps and the CRLs. Certificates must be valid, and not
+// revoked, at the earliest time-stamp, so signatures stay verifiable after
+// the certificates expire.
+func (b *Bundle) Verify(opts VerifyOptions) (*Result, error) {
+	if len(b.Certificates) == 0 {
+		return nil, fmt.Errorf("%w: no signer certificate", ErrUntrustedChain)
+	}
+	msg := opts.Message
+	if msg == nil {
+		msg = b.Message
+	}
+	if msg != nil {
+		r, err := Digest(b.Recipe.Hash, msg)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(r.Digest, b.Recipe.Digest) {
+			return nil, fmt.Errorf("%w: message does not match the digest", ErrInvalidSignature)
+		}
+	}
+
+	signer := b.Certificates[0]
+	composite, err := signer.CompositePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := core.ParsePublicKey(composite)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := pub.Verify(b.Recipe.Digest, b.Signature, b.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	res := &Result{Signer: signer, Revocation: make([]bool, len(b.Certificates))}
+	if res.TrustedRoot, err = b.checkChain(opts.Roots); err != nil {
+		return nil, err
+	}
+
+	at := opts.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	for i, raw := range b.Timestamps {
+		ts, err := ParseTimestamp(raw, b.Signature, opts.TSARoots)
+		if err != nil {
+			return nil, fmt.Errorf("time-stamp %d: %w", i, err)
+		}
+		res.Timestamps = append(res.Timestamps, ts)
+		if res.SigningTime.IsZero() || ts.Time.Before(res.SigningTime) {
+			res.SigningTime = ts.Time
+		}
+	}
+	if !res.SigningTime.IsZero() {
+		at = res.SigningTime
+	}
+	for _, c := range b.Certificates {
+		if at.Before(c.NotBefore) || at.After(c.NotAfter) {
+			return nil, fmt.Errorf("%s: %w", c.Subject.CommonName, ErrExpired)
+		}
+	}
+	if err := b.checkCRLs(at, res.Revocation); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkChain checks that every certificate is issued by the next one and
+// that the last one is self-signed and, with roots, one of them
+func (b *Bundle) checkChain(roots []*hybridx509.Certificate) (bool, error) {
+	certs := b.Certificates
+	for i := 0; i+1 < len(certs); i++ {
+		if err := certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+			return false, fmt.Errorf("%w: %s: %w", ErrUntrustedChain, certs[i].Subject.CommonName, err)
+		}
+	}
+	last := certs[len(certs)-1]
+	if err := last.CheckSignatureFrom(last); err != nil {
+		return false, fmt.Errorf("%w: chain does not end at a root: %w", ErrUntrustedChain, err)
+	}
+	if len(roots) == 0 {
+		return false, nil
+	}
+	for _, root := range roots {
+		if bytes.Equal(root.Raw, last.Raw) {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("%w: root %s is not trusted", ErrUntrustedChain, last.Subject.CommonName)
+}
+
+// checkCRLs checks each CRL with the chain certificate that issued it and
+// marks in checked the certificates it covers
+func (b *Bundle) checkCRLs(at time.Time, checked []bool) error {
+	certs := b.Certificates
+	for n, der := range b.CRLs {
+		crl, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return fmt.Errorf("CRL %d: %w", n, err)
+		}
+		issuer := -1
+		for i := 1; i < len(certs); i++ {
+			if bytes.Equal(certs[i].RawSubject, crl.RawIssuer) {
+				issuer = i
+				break
+			}
+		}
+		if issuer < 0 {
+			return fmt.Errorf("CRL %d: %w: issuer is not in the chain", n, ErrUntrustedChain)
+		}
+		if err := crl.CheckSignatureFrom(certs[issuer].Certificate); err != nil {
+			return fmt.Errorf("CRL %d: %w", n, err)
+		}
+		subject := certs[issuer-1]
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(subject.SerialNumber) == 0 && !entry.RevocationTime.After(at) {
+				return fmt.Errorf("%s: %w on %s", subject.Subject.CommonName, ErrRevoked, entry.RevocationTime.Format(time.RFC3339))
+			}
+		}
+		checked[issuer-1] = true
+	}
+	return nil
+}
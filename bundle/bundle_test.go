@@ -0,0 +1,219 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// tsa is a time-stamp authority issuing RFC 3161 tokens
+type tsa struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTSA(t *testing.T) *tsa {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tsa.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &tsa{key: key, cert: cert}
+}
+
+// stamp returns a TimeStampResp over data
+func (a *tsa) stamp(t *testing.T, data []byte, genTime time.Time) []byte {
+	imprint := sha256.Sum256(data)
+	sha256ID := pkix.AlgorithmIdentifier{Algorithm: hashes[0].oid}
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: messageImprint{HashAlgorithm: sha256ID, HashedMessage: imprint[:]},
+		SerialNumber:   big.NewInt(42),
+		GenTime:        genTime.UTC().Truncate(time.Second),
+	})
+	require.NoError(t, err)
+
+	contentType, err := asn1.Marshal(oidTSTInfo)
+	require.NoError(t, err)
+	infoDigest := sha256.Sum256(info)
+	md, err := asn1.Marshal(infoDigest[:])
+	require.NoError(t, err)
+	attrs, err := asn1.MarshalWithParams([]attribute{
+		{Type: oidContentType, Values: []asn1.RawValue{{FullBytes: contentType}}},
+		{Type: oidMessageDigest, Values: []asn1.RawValue{{FullBytes: md}}},
+	}, "set")
+	require.NoError(t, err)
+	attrsDigest := sha256.Sum256(attrs)
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, attrsDigest[:])
+	require.NoError(t, err)
+
+	sid, err := asn1.Marshal(struct {
+		Issuer asn1.RawValue
+		Serial *big.Int
+	}{asn1.RawValue{FullBytes: a.cert.RawIssuer}, a.cert.SerialNumber})
+	require.NoError(t, err)
+	digestAlgs, err := asn1.MarshalWithParams([]pkix.AlgorithmIdentifier{sha256ID}, "set")
+	require.NoError(t, err)
+	sd, err := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: asn1.RawValue{FullBytes: digestAlgs},
+		EncapContentInfo: encapContentInfo{EContentType: oidTSTInfo, EContent: info},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    sha256ID,
+			SignedAttrs:        asn1.RawValue{FullBytes: append([]byte{0xa0}, attrs[1:]...)},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          sig,
+		}},
+	})
+	require.NoError(t, err)
+	resp, err := asn1.Marshal(struct {
+		Status struct{ Status int }
+		Token  contentInfo
+	}{Token: contentInfo{
+		ContentType: oidSignedData,
+		// Marshal ignores the explicit tag of raw values
+		Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	}})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestBundle(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	id, err := root.Issue(ca.Request{CommonName: "signer.example.com", OrganizationalUnit: "client"})
+	require.NoError(t, err)
+
+	msg := []byte("record to retain")
+	recipe, err := Digest("SHA3-256", msg)
+	require.NoError(t, err)
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	key, err := csp.KeyImport(id.Key.Raw(), &hybrid.HybridPrivateKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	sig, err := csp.Sign(key, recipe.Digest, nil)
+	require.NoError(t, err)
+
+	authority := newTSA(t)
+	crl, err := root.CRL(time.Now().Add(24 * time.Hour))
+	require.NoError(t, err)
+	b := &Bundle{
+		Recipe:       recipe,
+		Policy:       core.PolicyHybridAND,
+		Signature:    sig,
+		Message:      msg,
+		Certificates: []*hybridx509.Certificate{id.Cert, root.Cert},
+		CRLs:         [][]byte{crl},
+		Timestamps:   [][]byte{authority.stamp(t, sig, time.Now())},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf, time.Now()))
+
+	read, m, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, "SHA3-256", m.Recipe.Hash)
+	tsaRoots := x509.NewCertPool()
+	tsaRoots.AddCert(authority.cert)
+	opts := VerifyOptions{Roots: []*hybridx509.Certificate{root.Cert}, TSARoots: tsaRoots}
+	res, err := read.Verify(opts)
+	require.NoError(t, err)
+	assert.True(t, res.TrustedRoot)
+	assert.Equal(t, []bool{true, false}, res.Revocation)
+	require.Len(t, res.Timestamps, 1)
+	assert.True(t, res.Timestamps[0].Trusted)
+	assert.False(t, res.SigningTime.IsZero())
+
+	// Years later, the time-stamp still proves the certificate was valid
+	later := opts
+	later.Time = id.Cert.NotAfter.Add(365 * 24 * time.Hour)
+	_, err = read.Verify(later)
+	require.NoError(t, err)
+	read.Timestamps = nil
+	_, err = read.Verify(later)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// Revocation before the time-stamp
+	root.Revoke(id.Cert.SerialNumber, time.Now().Add(-time.Minute))
+	crl, err = root.CRL(time.Now().Add(24 * time.Hour))
+	require.NoError(t, err)
+	revoked := *b
+	revoked.CRLs = [][]byte{crl}
+	_, err = revoked.Verify(opts)
+	assert.ErrorIs(t, err, ErrRevoked)
+
+	_, err = b.Verify(VerifyOptions{Message: []byte("another record")})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	other, err := ca.NewRoot(pkix.Name{CommonName: "other.example.com"}, 0)
+	require.NoError(t, err)
+	_, err = b.Verify(VerifyOptions{Roots: []*hybridx509.Certificate{other.Cert}})
+	assert.ErrorIs(t, err, ErrUntrustedChain)
+
+	// A time-stamp over another signature
+	stale := *b
+	stale.Timestamps = [][]byte{authority.stamp(t, []byte("other"), time.Now())}
+	_, err = stale.Verify(opts)
+	assert.ErrorIs(t, err, ErrInvalidTimestamp)
+}
+
+func TestReadRejectsModifiedEntries(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	b := &Bundle{Recipe: Recipe{Hash: "SHA-256", Digest: []byte("digest")}, Signature: []byte("sig"), Certificates: []*hybridx509.Certificate{root.Cert}}
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf, time.Now()))
+
+	// Rewrite the archive with another signature
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var tampered bytes.Buffer
+	zw := zip.NewWriter(&tampered)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		if f.Name == "signature.bin" {
+			data = []byte("forged")
+		}
+		w, err := zw.Create(f.Name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	_, _, err = Read(bytes.NewReader(tampered.Bytes()), int64(tampered.Len()))
+	assert.ErrorIs(t, err, ErrHashMismatch)
+}
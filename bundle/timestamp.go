@@ -0,0 +1,289 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// RFC 3161 time-stamp tokens are CMS SignedData over a TSTInfo. Only what
+// is needed to check a token offline is decoded here.
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+)
+
+// hashes are the digest algorithms accepted in tokens and digest recipes
+var hashes = []struct {
+	name string
+	oid  asn1.ObjectIdentifier
+	hash crypto.Hash
+}{
+	{"SHA-256", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, crypto.SHA256},
+	{"SHA-384", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}, crypto.SHA384},
+	{"SHA-512", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}, crypto.SHA512},
+	{"SHA3-256", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 8}, crypto.SHA3_256},
+}
+
+// ErrInvalidTimestamp is returned for time-stamp tokens that do not verify
+var ErrInvalidTimestamp = errors.New("invalid time-stamp token")
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type timeStampResp struct {
+	Status asn1.RawValue
+	Token  contentInfo `asn1:"optional"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       asn1.RawValue `asn1:"optional"`
+	Ordering       bool          `asn1:"optional"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// Timestamp is a verified time-stamp token
+type Timestamp struct {
+	// Time is the time the TSA asserts the imprint existed
+	Time   time.Time
+	Serial *big.Int
+	// Hash and Imprint are the message imprint of the token
+	Hash    crypto.Hash
+	Imprint []byte
+	// TSA is the certificate that signed the token
+	TSA *x509.Certificate
+	// Trusted is true when the TSA certificate chains to a trusted root
+	Trusted bool
+}
+
+// ParseTimestamp checks that raw, a TimeStampResp or a bare
+// TimeStampToken, is signed by its TSA certificate and time-stamps
+// imprintOf. With roots, the TSA certificate must also chain to one of
+// them at the asserted time.
+func ParseTimestamp(raw, imprintOf []byte, roots *x509.CertPool) (*Timestamp, error) {
+	ts, err := ParseTimestampToken(raw, roots)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(ts.Imprint, Sum(ts.Hash, imprintOf)) {
+		return nil, fmt.Errorf("%w: imprint does not match", ErrInvalidTimestamp)
+	}
+	return ts, nil
+}
+
+// ParseTimestampToken is ParseTimestamp for callers that check the
+// imprint themselves, e.g. when it is the root of a hash tree
+func ParseTimestampToken(raw []byte, roots *x509.CertPool) (*Timestamp, error) {
+	var token contentInfo
+	if _, err := asn1.Unmarshal(raw, &token); err != nil || !token.ContentType.Equal(oidSignedData) {
+		var resp timeStampResp
+		if _, err := asn1.Unmarshal(raw, &resp); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+		}
+		token = resp.Token
+	}
+	if !token.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("%w: not a SignedData token", ErrInvalidTimestamp)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(token.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) || len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("%w: unexpected content", ErrInvalidTimestamp)
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+	}
+	h, ok := hashByOID(info.MessageImprint.HashAlgorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported imprint algorithm %s", ErrInvalidTimestamp, info.MessageImprint.HashAlgorithm.Algorithm)
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil || len(certs) == 0 {
+		return nil, fmt.Errorf("%w: token carries no TSA certificate", ErrInvalidTimestamp)
+	}
+	tsa, err := checkSignerInfo(&sd.SignerInfos[0], sd.EncapContentInfo.EContent, certs)
+	if err != nil {
+		return nil, err
+	}
+	ts := &Timestamp{Time: info.GenTime, Serial: info.SerialNumber, Hash: h, Imprint: info.MessageImprint.HashedMessage, TSA: tsa}
+	if roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, c := range certs {
+			intermediates.AddCert(c)
+		}
+		_, err := tsa.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   info.GenTime,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: untrusted TSA: %w", ErrInvalidTimestamp, err)
+		}
+		ts.Trusted = true
+	}
+	return ts, nil
+}
+
+// checkSignerInfo verifies the signed attributes of si and returns the
+// certificate that signed them
+func checkSignerInfo(si *signerInfo, content []byte, certs []*x509.Certificate) (*x509.Certificate, error) {
+	h, ok := hashByOID(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported digest algorithm %s", ErrInvalidTimestamp, si.DigestAlgorithm.Algorithm)
+	}
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return nil, fmt.Errorf("%w: missing signed attributes", ErrInvalidTimestamp)
+	}
+	// The signature covers the attributes with their SET tag
+	signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+	}
+	var md []byte
+	var isTSTInfo bool
+	for _, a := range attrs {
+		if len(a.Values) != 1 {
+			continue
+		}
+		switch {
+		case a.Type.Equal(oidMessageDigest):
+			asn1.Unmarshal(a.Values[0].FullBytes, &md)
+		case a.Type.Equal(oidContentType):
+			var ct asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(a.Values[0].FullBytes, &ct); err == nil {
+				isTSTInfo = ct.Equal(oidTSTInfo)
+			}
+		}
+	}
+	if !isTSTInfo || !bytes.Equal(md, Sum(h, content)) {
+		return nil, fmt.Errorf("%w: signed attributes do not match the content", ErrInvalidTimestamp)
+	}
+
+	for _, c := range certs {
+		if algo, ok := signatureAlgorithm(h, c.PublicKey); ok && c.CheckSignature(algo, signed, si.Signature) == nil {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: signature does not verify", ErrInvalidTimestamp)
+}
+
+func signatureAlgorithm(h crypto.Hash, pub any) (x509.SignatureAlgorithm, bool) {
+	switch pub.(type) {
+	case *ecdsa.PublicKey:
+		switch h {
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, true
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, true
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, true
+		}
+	case *rsa.PublicKey:
+		switch h {
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, true
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, true
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, true
+		}
+	case ed25519.PublicKey:
+		return x509.PureEd25519, true
+	}
+	return 0, false
+}
+
+func hashByOID(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	for _, h := range hashes {
+		if h.oid.Equal(oid) {
+			return h.hash, true
+		}
+	}
+	return 0, false
+}
+
+// HashByName returns the hash named SHA-256, SHA-384, SHA-512 or SHA3-256
+func HashByName(name string) (crypto.Hash, bool) {
+	for _, h := range hashes {
+		if h.name == name {
+			return h.hash, true
+		}
+	}
+	return 0, false
+}
+
+// Sum hashes msg with h, which may be SHA3-256
+func Sum(h crypto.Hash, msg []byte) []byte {
+	var hh hash.Hash
+	if h == crypto.SHA3_256 {
+		hh = sha3.New256()
+	} else {
+		hh = h.New()
+	}
+	hh.Write(msg)
+	return hh.Sum(nil)
+}
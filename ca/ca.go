@@ -0,0 +1,245 @@
+// Package ca is a minimal hybrid certificate authority for lab networks. It
+// issues composite certificates (see hybridx509), keeps a revocation list and
+// lays out Fabric MSP folders for the identities it issues.
+package ca
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// DefaultValidity is used when a request does not set one
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Identity is a composite certificate and its private key
+type Identity struct {
+	Cert *hybridx509.Certificate
+	Key  *hybridx509.PrivateKey
+}
+
+// Revocation is an entry of the CA revocation list
+type Revocation struct {
+	Serial    *big.Int
+	RevokedAt time.Time
+}
+
+// CA is a root or intermediate hybrid certificate authority
+type CA struct {
+	Identity
+
+	// Chain holds the issuers of Cert, ending with the root. It is empty for a root CA.
+	Chain []*hybridx509.Certificate
+
+	revoked   []Revocation
+	crlNumber int64
+}
+
+// Request describes a certificate to issue
+type Request struct {
+	CommonName string
+	// Organization defaults to the CA's organization
+	Organization string
+	// OrganizationalUnit carries the Fabric node OU: peer, orderer, client or admin
+	OrganizationalUnit string
+	DNSNames           []string
+	// Validity defaults to DefaultValidity
+	Validity time.Duration
+	// TLS adds the server and client authentication extended key usages
+	TLS bool
+	// CRLDistributionPoints are the URLs where relying parties fetch the
+	// issuer's CRL, see msp.CRLCache
+	CRLDistributionPoints []string
+	// OCSPServer are the URLs of the issuer's OCSP responder, see
+	// OCSPResponder
+	OCSPServer []string
+	// Key is certified instead of a new key, e.g. one of a batch from
+	// hybridx509.GenerateKeys
+	Key *hybridx509.PrivateKey
+}
+
+// NewRoot creates a self-signed hybrid root CA
+func NewRoot(subject pkix.Name, validity time.Duration) (*CA, error) {
+	key, err := hybridx509.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := caTemplate(subject, validity, key)
+	if err != nil {
+		return nil, err
+	}
+	der, err := hybridx509.CreateCertificate(tmpl, nil, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root certificate: %w", err)
+	}
+	cert, err := hybridx509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Identity: Identity{Cert: cert, Key: key}}, nil
+}
+
+// NewIntermediate creates an intermediate CA signed by c
+func (c *CA) NewIntermediate(subject pkix.Name, validity time.Duration) (*CA, error) {
+	key, err := hybridx509.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := caTemplate(subject, validity, key)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.MaxPathLenZero = true
+	der, err := hybridx509.CreateCertificate(tmpl, c.Cert.Certificate, key.Public(), c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create intermediate certificate: %w", err)
+	}
+	cert, err := hybridx509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	chain := append([]*hybridx509.Certificate{c.Cert}, c.Chain...)
+	return &CA{Identity: Identity{Cert: cert, Key: key}, Chain: chain}, nil
+}
+
+// Issue creates a key pair, unless req has one, and a leaf certificate for req
+func (c *CA) Issue(req Request) (*Identity, error) {
+	if req.CommonName == "" {
+		return nil, errors.New("common name is required")
+	}
+	key := req.Key
+	if key == nil {
+		var err error
+		if key, err = hybridx509.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	subject := pkix.Name{
+		Country:      c.Cert.Subject.Country,
+		Province:     c.Cert.Subject.Province,
+		Locality:     c.Cert.Subject.Locality,
+		Organization: c.Cert.Subject.Organization,
+		CommonName:   req.CommonName,
+	}
+	if req.Organization != "" {
+		subject.Organization = []string{req.Organization}
+	}
+	if req.OrganizationalUnit != "" {
+		subject.OrganizationalUnit = []string{req.OrganizationalUnit}
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Add(-5 * time.Minute)
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		NotBefore:             now,
+		NotAfter:              now.Add(validityOrDefault(req.Validity)),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		DNSNames:              req.DNSNames,
+		CRLDistributionPoints: req.CRLDistributionPoints,
+		OCSPServer:            req.OCSPServer,
+		SubjectKeyId:          subjectKeyID(key),
+	}
+	if req.TLS {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := hybridx509.CreateCertificate(tmpl, c.Cert.Certificate, key.Public(), c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate for %s: %w", req.CommonName, err)
+	}
+	cert, err := hybridx509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{Cert: cert, Key: key}, nil
+}
+
+// Revoke adds serial to the revocation list
+func (c *CA) Revoke(serial *big.Int, at time.Time) {
+	for _, r := range c.revoked {
+		if r.Serial.Cmp(serial) == 0 {
+			return
+		}
+	}
+	c.revoked = append(c.revoked, Revocation{Serial: serial, RevokedAt: at.UTC()})
+}
+
+// Revoked returns the revocation list
+func (c *CA) Revoked() []Revocation {
+	return append([]Revocation(nil), c.revoked...)
+}
+
+// CRL issues a new DER composite CRL valid until nextUpdate, signed with both
+// CA keys
+func (c *CA) CRL(nextUpdate time.Time) ([]byte, error) {
+	entries := make([]x509.RevocationListEntry, 0, len(c.revoked))
+	for _, r := range c.revoked {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: r.Serial, RevocationTime: r.RevokedAt})
+	}
+	c.crlNumber++
+	return hybridx509.CreateRevocationList(&x509.RevocationList{
+		Number:                    big.NewInt(c.crlNumber),
+		ThisUpdate:                time.Now().UTC(),
+		NextUpdate:                nextUpdate.UTC(),
+		RevokedCertificateEntries: entries,
+	}, c.Cert, c.Key)
+}
+
+// Root returns the root certificate of the chain
+func (c *CA) Root() *hybridx509.Certificate {
+	if len(c.Chain) == 0 {
+		return c.Cert
+	}
+	return c.Chain[len(c.Chain)-1]
+}
+
+func caTemplate(subject pkix.Name, validity time.Duration, key *hybridx509.PrivateKey) (*x509.Certificate, error) {
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Add(-5 * time.Minute)
+	return &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		NotBefore:             now,
+		NotAfter:              now.Add(validityOrDefault(validity)),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          subjectKeyID(key),
+	}, nil
+}
+
+// subjectKeyID follows Fabric's convention of hashing the ECDSA public point
+func subjectKeyID(key *hybridx509.PrivateKey) []byte {
+	pub, _ := key.ECDSA.PublicKey.ECDH()
+	sum := sha256.Sum256(pub.Bytes())
+	return sum[:]
+}
+
+func newSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func validityOrDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultValidity
+	}
+	return d
+}
@@ -0,0 +1,164 @@
+package ca
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestIssueRevokeAndMSP(t *testing.T) {
+	root, err := NewRoot(pkix.Name{Organization: []string{"org1.example.com"}, CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	inter, err := root.NewIntermediate(pkix.Name{Organization: []string{"org1.example.com"}, CommonName: "ica.org1.example.com"}, 0)
+	require.NoError(t, err)
+	require.NoError(t, inter.Cert.CheckSignatureFrom(root.Cert))
+
+	peer, err := inter.Issue(Request{CommonName: "peer0.org1.example.com", OrganizationalUnit: "peer", DNSNames: []string{"peer0.org1.example.com"}})
+	require.NoError(t, err)
+	require.NoError(t, peer.Cert.CheckSignatureFrom(inter.Cert))
+	assert.Equal(t, []string{"org1.example.com"}, peer.Cert.Subject.Organization)
+	assert.Equal(t, []string{"peer"}, peer.Cert.Subject.OrganizationalUnit)
+
+	inter.Revoke(peer.Cert.SerialNumber, time.Now())
+	der, err := inter.CRL(time.Now().Add(24 * time.Hour))
+	require.NoError(t, err)
+	crl, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NoError(t, crl.CheckSignatureFrom(inter.Cert.Certificate))
+	require.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, peer.Cert.SerialNumber, crl.RevokedCertificateEntries[0].SerialNumber)
+	composite, err := hybridx509.ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NoError(t, composite.CheckSignatureFrom(inter.Cert), "the CRL carries the ML-DSA signature too")
+
+	caDir := filepath.Join(t.TempDir(), "ica")
+	require.NoError(t, inter.Save(caDir))
+	loaded, err := Load(caDir)
+	require.NoError(t, err)
+	assert.Equal(t, inter.Cert.Raw, loaded.Cert.Raw)
+	assert.Equal(t, root.Cert.Raw, loaded.Root().Raw)
+	assert.Equal(t, inter.Revoked(), loaded.Revoked())
+
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, WriteMSP(mspDir, loaded, peer, MSPOptions{CRL: der, NodeOUs: true}))
+	for _, f := range []string{
+		"cacerts/ca.org1.example.com-cert.pem",
+		"tlscacerts/ca.org1.example.com-cert.pem",
+		"intermediatecerts/ica.org1.example.com-cert.pem",
+		"signcerts/peer0.org1.example.com-cert.pem",
+		"keystore/priv_sk",
+		"keystore/pqc_sk",
+		"crls/crl.pem",
+		"config.yaml",
+	} {
+		_, err := os.Stat(filepath.Join(mspDir, f))
+		assert.NoError(t, err, f)
+	}
+}
+
+func TestExportImportIdentity(t *testing.T) {
+	root, err := NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	inter, err := root.NewIntermediate(pkix.Name{CommonName: "ica.org1.example.com"}, 0)
+	require.NoError(t, err)
+	peer, err := inter.Issue(Request{CommonName: "peer0.org1.example.com", OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, WriteMSP(mspDir, inter, peer, MSPOptions{}))
+
+	id, chain, err := ReadMSP(mspDir)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, inter.Cert.Raw, chain[0].Raw)
+	assert.Equal(t, root.Cert.Raw, chain[1].Raw)
+
+	password := []byte("lab password")
+	bundle, err := ExportIdentity(id, chain, password)
+	require.NoError(t, err)
+	imported, importedChain, err := ImportIdentity(bundle, password)
+	require.NoError(t, err)
+	assert.Equal(t, peer.Cert.Raw, imported.Cert.Raw)
+	assert.True(t, imported.Key.ECDSA.Equal(peer.Key.ECDSA))
+	assert.Equal(t, peer.Key.PQC.PublicKey(), imported.Key.PQC.PublicKey())
+	require.Len(t, importedChain, 2)
+	assert.Equal(t, root.Cert.Raw, importedChain[1].Raw)
+
+	_, _, err = ImportIdentity(bundle, []byte("wrong"))
+	assert.ErrorIs(t, err, ErrBundlePassword)
+
+	// The certificates are authenticated with the keys
+	other, err := inter.Issue(Request{CommonName: "peer1.org1.example.com", OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	block, _ := pem.Decode(bundle)
+	var b identityBundle
+	_, err = asn1.Unmarshal(block.Bytes, &b)
+	require.NoError(t, err)
+	b.Certificates[0] = asn1.RawValue{FullBytes: other.Cert.Raw}
+	der, err := asn1.Marshal(b)
+	require.NoError(t, err)
+	_, _, err = ImportIdentity(pem.EncodeToMemory(&pem.Block{Type: IdentityBundlePEMType, Bytes: der}), password)
+	assert.ErrorIs(t, err, ErrBundlePassword)
+
+	mismatched, err := ExportIdentity(&Identity{Cert: other.Cert, Key: peer.Key}, chain, password)
+	require.NoError(t, err)
+	_, _, err = ImportIdentity(mismatched, password)
+	assert.ErrorIs(t, err, ErrInvalidBundle)
+}
+
+func TestOCSPResponder(t *testing.T) {
+	root, err := NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	responder := NewOCSPResponder(root, time.Hour)
+	server := httptest.NewServer(responder.Handler())
+	defer server.Close()
+	peer, err := root.Issue(Request{CommonName: "peer0.org1.example.com", OCSPServer: []string{server.URL}})
+	require.NoError(t, err)
+
+	der, resp, err := FetchOCSP(nil, server.URL, peer.Cert, root.Cert)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.Good, resp.Status)
+	assert.NotEmpty(t, resp.AltSignature)
+	_, err = CheckOCSP(der, peer.Cert, root.Cert, time.Now().Add(2*time.Hour))
+	assert.ErrorContains(t, err, "not current")
+
+	// GET requests carry the request in the path
+	req, err := ocsp.CreateRequest(peer.Cert.Certificate, root.Cert.Certificate, nil)
+	require.NoError(t, err)
+	httpResp, err := http.Get(server.URL + "/" + url.PathEscape(base64.StdEncoding.EncodeToString(req)))
+	require.NoError(t, err)
+	body, err := io.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	require.NoError(t, err)
+	_, err = CheckOCSP(body, peer.Cert, root.Cert, time.Now())
+	require.NoError(t, err)
+
+	root.Revoke(peer.Cert.SerialNumber, time.Now())
+	responder.Update(root)
+	_, _, err = FetchOCSP(nil, server.URL, peer.Cert, root.Cert)
+	assert.ErrorIs(t, err, ErrOCSPStatus)
+
+	// Requests about another CA are refused, responses of another CA rejected
+	other, err := NewRoot(pkix.Name{CommonName: "ca.org2.example.com"}, 0)
+	require.NoError(t, err)
+	stranger, err := other.Issue(Request{CommonName: "peer0.org2.example.com"})
+	require.NoError(t, err)
+	_, _, err = FetchOCSP(nil, server.URL, stranger.Cert, other.Cert)
+	assert.Error(t, err)
+	_, err = CheckOCSP(der, peer.Cert, other.Cert, time.Now())
+	assert.Error(t, err)
+}
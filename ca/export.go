@@ -0,0 +1,199 @@
+package ca
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"golang.org/x/crypto/scrypt"
+)
+
+// IdentityBundlePEMType is the PEM type of ExportIdentity bundles
+const IdentityBundlePEMType = "QL IDENTITY BUNDLE"
+
+// identityBundleVersion is the only bundle layout so far
+const identityBundleVersion = 1
+
+// Scrypt parameters of new bundles, the interactive login cost of the scrypt
+// paper scaled to 2^15; bundles record theirs
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrBundlePassword is returned by ImportIdentity for a wrong password
+	// or a modified bundle, which cannot be told apart
+	ErrBundlePassword = errors.New("wrong password or corrupted identity bundle")
+	// ErrInvalidBundle is returned for data that is not an identity bundle
+	ErrInvalidBundle = errors.New("invalid identity bundle")
+)
+
+// identityBundle is
+//
+//	SEQUENCE {
+//	  version       INTEGER
+//	  certificates  SEQUENCE OF Certificate  -- the identity's, then its chain
+//	  kdf           SEQUENCE { salt OCTET STRING, n, r, p INTEGER }  -- scrypt
+//	  nonce         OCTET STRING
+//	  encryptedKeys OCTET STRING  -- AES-256-GCM of bundleKeys
+//	}
+//
+// The certificates are the additional data of the encryption, so they cannot
+// be swapped for others without the password.
+type identityBundle struct {
+	Version       int
+	Certificates  []asn1.RawValue
+	KDF           bundleKDF
+	Nonce         []byte
+	EncryptedKeys []byte
+}
+
+type bundleKDF struct {
+	Salt    []byte
+	N, R, P int
+}
+
+// bundleKeys holds the two PEM private key halves, as in an MSP keystore
+type bundleKeys struct {
+	ECDSA []byte
+	PQC   []byte
+}
+
+// ExportIdentity encrypts id and its chain, the issuers of its certificate up
+// to the root, into a single PEM bundle protected by password. It is meant
+// for moving lab identities between machines, like a PKCS#12 file.
+func ExportIdentity(id *Identity, chain []*hybridx509.Certificate, password []byte) ([]byte, error) {
+	if id == nil || id.Cert == nil || id.Key == nil {
+		return nil, errors.New("identity is incomplete")
+	}
+	if len(password) == 0 {
+		return nil, errors.New("password is empty")
+	}
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(id.Key)
+	if err != nil {
+		return nil, err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(id.Key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := asn1.Marshal(bundleKeys{ECDSA: ecdsaPEM, PQC: pqcPEM})
+	if err != nil {
+		return nil, err
+	}
+
+	b := identityBundle{
+		Version: identityBundleVersion,
+		KDF:     bundleKDF{Salt: make([]byte, 16), N: scryptN, R: scryptR, P: scryptP},
+	}
+	for _, cert := range append([]*hybridx509.Certificate{id.Cert}, chain...) {
+		b.Certificates = append(b.Certificates, asn1.RawValue{FullBytes: cert.Raw})
+	}
+	if _, err := rand.Read(b.KDF.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := b.KDF.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	b.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(b.Nonce); err != nil {
+		return nil, err
+	}
+	aad, err := asn1.Marshal(b.Certificates)
+	if err != nil {
+		return nil, err
+	}
+	b.EncryptedKeys = aead.Seal(nil, b.Nonce, plaintext, aad)
+
+	der, err := asn1.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: IdentityBundlePEMType, Bytes: der}), nil
+}
+
+// ImportIdentity decrypts a bundle written by ExportIdentity. It returns the
+// identity and its chain, after checking that the key belongs to the
+// certificate.
+func ImportIdentity(data, password []byte) (*Identity, []*hybridx509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != IdentityBundlePEMType {
+		return nil, nil, fmt.Errorf("%w: no %s PEM block", ErrInvalidBundle, IdentityBundlePEMType)
+	}
+	var b identityBundle
+	if rest, err := asn1.Unmarshal(block.Bytes, &b); err != nil || len(rest) != 0 {
+		return nil, nil, fmt.Errorf("%w: malformed DER", ErrInvalidBundle)
+	}
+	if b.Version != identityBundleVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, b.Version)
+	}
+	if len(b.Certificates) == 0 {
+		return nil, nil, fmt.Errorf("%w: no certificate", ErrInvalidBundle)
+	}
+	// Bound the cost an untrusted bundle can ask for
+	if b.KDF.N < 2 || b.KDF.N > 1<<20 || b.KDF.R < 1 || b.KDF.R > 32 || b.KDF.P < 1 || b.KDF.P > 16 {
+		return nil, nil, fmt.Errorf("%w: scrypt parameters out of range", ErrInvalidBundle)
+	}
+
+	certs := make([]*hybridx509.Certificate, len(b.Certificates))
+	for i, raw := range b.Certificates {
+		cert, err := hybridx509.ParseCertificate(raw.FullBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: certificate %d: %w", ErrInvalidBundle, i, err)
+		}
+		certs[i] = cert
+	}
+	aead, err := b.KDF.aead(password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(b.Nonce) != aead.NonceSize() {
+		return nil, nil, fmt.Errorf("%w: nonce size", ErrInvalidBundle)
+	}
+	aad, err := asn1.Marshal(b.Certificates)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := aead.Open(nil, b.Nonce, b.EncryptedKeys, aad)
+	if err != nil {
+		return nil, nil, ErrBundlePassword
+	}
+	var keys bundleKeys
+	if _, err := asn1.Unmarshal(plaintext, &keys); err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed keys", ErrInvalidBundle)
+	}
+	key, err := hybridx509.ParsePrivateKeyPEM(keys.ECDSA, keys.PQC)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	cert := certs[0]
+	certECDSA, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !certECDSA.Equal(&key.ECDSA.PublicKey) || !bytes.Equal(cert.PQCPublicKey, key.PQC.PublicKey()) {
+		return nil, nil, fmt.Errorf("%w: the key does not match the certificate", ErrInvalidBundle)
+	}
+	return &Identity{Cert: cert, Key: key}, certs[1:], nil
+}
+
+// aead derives the AES-256-GCM key of a bundle from password
+func (k bundleKDF) aead(password []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, k.Salt, k.N, k.R, k.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
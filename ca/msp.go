@@ -0,0 +1,210 @@
+package ca
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// Keystore file names of an MSP written by WriteMSP. The ECDSA half uses the
+// name cryptogen uses, so unmodified Fabric tooling still finds it.
+const (
+	ECDSAKeyFile = "priv_sk"
+	PQCKeyFile   = "pqc_sk"
+)
+
+// nodeOUConfig enables Fabric NodeOUs, as cryptogen does with EnableNodeOUs
+const nodeOUConfig = `NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    Certificate: cacerts/%[1]s
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    Certificate: cacerts/%[1]s
+    OrganizationalUnitIdentifier: peer
+  AdminOUIdentifier:
+    Certificate: cacerts/%[1]s
+    OrganizationalUnitIdentifier: admin
+  OrdererOUIdentifier:
+    Certificate: cacerts/%[1]s
+    OrganizationalUnitIdentifier: orderer
+`
+
+// MSPOptions tunes WriteMSP
+type MSPOptions struct {
+	// Admins are copied into admincerts, for MSPs without NodeOUs
+	Admins []*hybridx509.Certificate
+	// CRL, if set, is written to crls/crl.pem
+	CRL []byte
+	// NodeOUs writes config.yaml enabling node OUs
+	NodeOUs bool
+}
+
+// WriteMSP lays out a Fabric MSP folder for id, issued by ca. A nil id writes
+// a verifying MSP (no signcerts/keystore), as used for channel configuration.
+func WriteMSP(dir string, ca *CA, id *Identity, opts MSPOptions) error {
+	root := ca.Root()
+	rootName := certFileName(root)
+
+	if err := writePEM(filepath.Join(dir, "cacerts"), rootName, root.Raw); err != nil {
+		return err
+	}
+	if err := writePEM(filepath.Join(dir, "tlscacerts"), rootName, root.Raw); err != nil {
+		return err
+	}
+	// Intermediates: the issuing CA itself and every issuer below the root
+	if len(ca.Chain) > 0 {
+		intermediates := append([]*hybridx509.Certificate{ca.Cert}, ca.Chain[:len(ca.Chain)-1]...)
+		for _, cert := range intermediates {
+			if err := writePEM(filepath.Join(dir, "intermediatecerts"), certFileName(cert), cert.Raw); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "admincerts"), 0o755); err != nil {
+		return err
+	}
+	for _, admin := range opts.Admins {
+		if err := writePEM(filepath.Join(dir, "admincerts"), certFileName(admin), admin.Raw); err != nil {
+			return err
+		}
+	}
+	if opts.CRL != nil {
+		if err := os.MkdirAll(filepath.Join(dir, "crls"), 0o755); err != nil {
+			return err
+		}
+		crl := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: opts.CRL})
+		if err := os.WriteFile(filepath.Join(dir, "crls", "crl.pem"), crl, 0o644); err != nil {
+			return err
+		}
+	}
+	if opts.NodeOUs {
+		config := fmt.Sprintf(nodeOUConfig, rootName)
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if id == nil {
+		return nil
+	}
+	if err := writePEM(filepath.Join(dir, "signcerts"), certFileName(id.Cert), id.Cert.Raw); err != nil {
+		return err
+	}
+	return WriteKeystore(filepath.Join(dir, "keystore"), id)
+}
+
+// WriteKeystore writes both halves of id's private key to dir
+func WriteKeystore(dir string, id *Identity) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(id.Key)
+	if err != nil {
+		return err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(id.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ECDSAKeyFile), ecdsaPEM, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, PQCKeyFile), pqcPEM, 0o600)
+}
+
+// ReadMSP reads the signing identity of an MSP folder written by WriteMSP,
+// and its chain from intermediatecerts and cacerts
+func ReadMSP(dir string) (*Identity, []*hybridx509.Certificate, error) {
+	signcerts, err := readCertificates(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(signcerts) != 1 {
+		return nil, nil, fmt.Errorf("%s: expected one signing certificate, found %d", dir, len(signcerts))
+	}
+	ecdsaPEM, err := os.ReadFile(filepath.Join(dir, "keystore", ECDSAKeyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	pqcPEM, err := os.ReadFile(filepath.Join(dir, "keystore", PQCKeyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := hybridx509.ParsePrivateKeyPEM(ecdsaPEM, pqcPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	intermediates, err := readCertificates(filepath.Join(dir, "intermediatecerts"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+	roots, err := readCertificates(filepath.Join(dir, "cacerts"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(roots) != 1 {
+		return nil, nil, fmt.Errorf("%s: expected one CA certificate, found %d", dir, len(roots))
+	}
+	// WriteMSP names intermediates by common name; order them from the
+	// issuer of the signing certificate up
+	chain := []*hybridx509.Certificate{}
+	for issuer := signcerts[0]; ; {
+		next := -1
+		for i, c := range intermediates {
+			if bytes.Equal(c.RawSubject, issuer.RawIssuer) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		issuer = intermediates[next]
+		chain = append(chain, issuer)
+		intermediates = append(intermediates[:next], intermediates[next+1:]...)
+	}
+	return &Identity{Cert: signcerts[0], Key: key}, append(chain, roots[0]), nil
+}
+
+// readCertificates parses every PEM file in dir, in name order
+func readCertificates(dir string) ([]*hybridx509.Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	var certs []*hybridx509.Certificate
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cert, err := hybridx509.ParseCertificatePEM(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func writePEM(dir, name string, der []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), hybridx509.EncodeCertificatePEM(der), 0o644)
+}
+
+func certFileName(cert *hybridx509.Certificate) string {
+	return cert.Subject.CommonName + "-cert.pem"
+}
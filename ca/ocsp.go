@@ -0,0 +1,203 @@
+package ca
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"golang.org/x/crypto/ocsp"
+)
+
+// DefaultOCSPValidity is how long OCSP responses are valid when the
+// responder does not set it
+const DefaultOCSPValidity = time.Hour
+
+// maxOCSPSize bounds OCSP requests and responses read over HTTP
+const maxOCSPSize = 64 << 10
+
+// ErrOCSPStatus is returned by FetchOCSP for responses that are valid but do
+// not report the certificate as good
+var ErrOCSPStatus = errors.New("certificate is not good")
+
+// OCSPResponder answers OCSP requests (RFC 6960) about the certificates of a
+// CA with composite responses signed by the CA itself, see
+// hybridx509.CreateOCSPResponse. The CA keeps no list of the serials it
+// issued, so every serial not revoked is reported good.
+type OCSPResponder struct {
+	validity time.Duration
+	now      func() time.Time
+
+	mutex sync.RWMutex
+	ca    *CA
+}
+
+// NewOCSPResponder returns a responder for c whose responses are valid for
+// validity, DefaultOCSPValidity if zero
+func NewOCSPResponder(c *CA, validity time.Duration) *OCSPResponder {
+	if validity <= 0 {
+		validity = DefaultOCSPValidity
+	}
+	return &OCSPResponder{validity: validity, now: time.Now, ca: c}
+}
+
+// Update replaces the CA, e.g. after Load picked up new revocations
+func (r *OCSPResponder) Update(c *CA) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ca = c
+}
+
+// Respond returns the DER response to a DER request. Malformed requests and
+// requests about another CA get the matching OCSP error response.
+func (r *OCSPResponder) Respond(der []byte) ([]byte, error) {
+	req, err := ocsp.ParseRequest(der)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+	r.mutex.RLock()
+	c := r.ca
+	r.mutex.RUnlock()
+	nameHash, keyHash, err := issuerHashes(c.Cert, req.HashAlgorithm)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+	if !bytes.Equal(nameHash, req.IssuerNameHash) || !bytes.Equal(keyHash, req.IssuerKeyHash) {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	now := r.now().UTC().Truncate(time.Second)
+	template := ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: req.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(r.validity),
+		IssuerHash:   req.HashAlgorithm,
+	}
+	for _, revoked := range c.Revoked() {
+		if revoked.Serial.Cmp(req.SerialNumber) == 0 {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = revoked.RevokedAt
+			break
+		}
+	}
+	return hybridx509.CreateOCSPResponse(c.Cert, template, c.Key)
+}
+
+// Handler serves OCSP over HTTP as in RFC 6960 appendix A: POST with the
+// DER request as body, or GET with the base64 request as last path segment
+func (r *OCSPResponder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var der []byte
+		var err error
+		switch req.Method {
+		case http.MethodPost:
+			der, err = io.ReadAll(io.LimitReader(req.Body, maxOCSPSize))
+		case http.MethodGet:
+			var segment string
+			segment, err = url.PathUnescape(req.URL.EscapedPath()[strings.LastIndex(req.URL.EscapedPath(), "/")+1:])
+			if err == nil {
+				der, err = base64.StdEncoding.DecodeString(segment)
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := r.Respond(der)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(resp)
+	})
+}
+
+// FetchOCSP asks the responder at server about cert, with client,
+// http.DefaultClient if nil. It returns the DER response, for stapling, once
+// both signatures of issuer are checked and the response reports cert as
+// good and current; otherwise the error wraps ErrOCSPStatus.
+func FetchOCSP(client *http.Client, server string, cert, issuer *hybridx509.Certificate) ([]byte, *hybridx509.OCSPResponse, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := ocsp.CreateRequest(cert.Certificate, issuer.Certificate, &ocsp.RequestOptions{Hash: crypto.SHA256})
+	if err != nil {
+		return nil, nil, err
+	}
+	httpResp, err := client.Post(server, "application/ocsp-request", bytes.NewReader(req))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("OCSP responder: unexpected status %s", httpResp.Status)
+	}
+	der, err := io.ReadAll(io.LimitReader(httpResp.Body, maxOCSPSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := CheckOCSP(der, cert, issuer, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return der, resp, nil
+}
+
+// CheckOCSP parses a DER OCSP response about cert, e.g. a stapled one, and
+// checks both signatures of issuer, the serial, the validity at now and the
+// status
+func CheckOCSP(der []byte, cert, issuer *hybridx509.Certificate, now time.Time) (*hybridx509.OCSPResponse, error) {
+	resp, err := hybridx509.ParseOCSPResponse(der, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.CheckSignatureFrom(issuer); err != nil {
+		return nil, err
+	}
+	if resp.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		return nil, errors.New("OCSP response is about another certificate")
+	}
+	if now.Before(resp.ThisUpdate) || resp.NextUpdate.IsZero() || !now.Before(resp.NextUpdate) {
+		return nil, errors.New("OCSP response is not current")
+	}
+	switch resp.Status {
+	case ocsp.Good:
+		return resp, nil
+	case ocsp.Revoked:
+		return nil, fmt.Errorf("%w: revoked on %s", ErrOCSPStatus, resp.RevokedAt.Format(time.RFC3339))
+	}
+	return nil, fmt.Errorf("%w: status unknown", ErrOCSPStatus)
+}
+
+// issuerHashes returns the hashes identifying issuer in an OCSP CertID
+func issuerHashes(issuer *hybridx509.Certificate, h crypto.Hash) ([]byte, []byte, error) {
+	if !h.Available() {
+		return nil, nil, fmt.Errorf("hash %v unavailable", h)
+	}
+	var spki struct {
+		Algorithm asn1.RawValue
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, nil, err
+	}
+	name := h.New()
+	name.Write(issuer.RawSubject)
+	key := h.New()
+	key.Write(spki.PublicKey.RightAlign())
+	return name.Sum(nil), key.Sum(nil), nil
+}
@@ -0,0 +1,155 @@
+package ca
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// Files of a CA directory
+const (
+	certFile      = "ca-cert.pem"
+	keyFile       = "ca-key.pem"
+	pqcKeyFile    = "ca-pqc-key.pem"
+	chainFile     = "chain.pem"
+	revokedFile   = "revoked.json"
+	crlNumberFile = "crlnumber"
+)
+
+type revocationJSON struct {
+	Serial    string    `json:"serial"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Save writes the CA to dir. Private keys are written with mode 0600.
+func (c *CA) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(c.Key)
+	if err != nil {
+		return err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(c.Key)
+	if err != nil {
+		return err
+	}
+	var chain bytes.Buffer
+	for _, cert := range c.Chain {
+		chain.Write(hybridx509.EncodeCertificatePEM(cert.Raw))
+	}
+	revoked := make([]revocationJSON, 0, len(c.revoked))
+	for _, r := range c.revoked {
+		revoked = append(revoked, revocationJSON{Serial: r.Serial.Text(16), RevokedAt: r.RevokedAt})
+	}
+	revokedJSON, err := json.MarshalIndent(revoked, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{
+		{certFile, hybridx509.EncodeCertificatePEM(c.Cert.Raw), 0o644},
+		{keyFile, ecdsaPEM, 0o600},
+		{pqcKeyFile, pqcPEM, 0o600},
+		{chainFile, chain.Bytes(), 0o644},
+		{revokedFile, revokedJSON, 0o644},
+		{crlNumberFile, []byte(strconv.FormatInt(c.crlNumber, 10) + "\n"), 0o644},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, f.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// Exists reports whether dir holds a CA, whose keys Save would overwrite
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, keyFile))
+	return err == nil
+}
+
+// Load reads a CA written by Save
+func Load(dir string) (*CA, error) {
+	read := func(name string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA %s: %w", name, err)
+		}
+		return data, nil
+	}
+
+	certPEM, err := read(certFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := hybridx509.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaPEM, err := read(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	pqcPEM, err := read(pqcKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hybridx509.ParsePrivateKeyPEM(ecdsaPEM, pqcPEM)
+	if err != nil {
+		return nil, err
+	}
+	c := &CA{Identity: Identity{Cert: cert, Key: key}}
+
+	chainPEM, err := read(chainFile)
+	if err != nil {
+		return nil, err
+	}
+	for block, rest := pem.Decode(chainPEM); block != nil; block, rest = pem.Decode(rest) {
+		issuer, err := hybridx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		c.Chain = append(c.Chain, issuer)
+	}
+
+	revokedJSON, err := read(revokedFile)
+	if err != nil {
+		return nil, err
+	}
+	var revoked []revocationJSON
+	if err := json.Unmarshal(revokedJSON, &revoked); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", revokedFile, err)
+	}
+	for _, r := range revoked {
+		serial, ok := new(big.Int).SetString(r.Serial, 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid serial %q in %s", r.Serial, revokedFile)
+		}
+		c.revoked = append(c.revoked, Revocation{Serial: serial, RevokedAt: r.RevokedAt})
+	}
+
+	number, err := read(crlNumberFile)
+	if err != nil {
+		return nil, err
+	}
+	c.crlNumber, err = strconv.ParseInt(strings.TrimSpace(string(number)), 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid CRL number")
+	}
+	return c, nil
+}
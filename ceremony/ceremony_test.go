@@ -0,0 +1,178 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+)
+
+func TestShamir(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))), "inverse of %d", a)
+	}
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83), "FIPS 197 section 4.2")
+
+	secret := []byte("a secret of thirty-two bytes....")
+	shares, err := splitSecret(secret, 5, 3)
+	require.NoError(t, err)
+	for _, xs := range [][]byte{{1, 2, 3}, {5, 3, 1}, {2, 4, 5}, {1, 2, 3, 4, 5}} {
+		ys := make([][]byte, len(xs))
+		for i, x := range xs {
+			ys[i] = shares[x-1]
+		}
+		assert.Equal(t, secret, combineSecret(xs, ys), "shares %v", xs)
+	}
+	assert.NotEqual(t, secret, combineSecret([]byte{1, 2}, shares[:2]), "below the threshold")
+	_, err = splitSecret(secret, 256, 3)
+	assert.Error(t, err)
+}
+
+func newShares(t *testing.T, params Params) (*ecdsa.PrivateKey, []*KeyShare) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	shares, err := Split(key, params)
+	require.NoError(t, err)
+	return key, shares
+}
+
+func TestSplitAndCombine(t *testing.T) {
+	key, shares := newShares(t, Params{Members: 5, Threshold: 3})
+	require.Len(t, shares, 5)
+	for _, s := range shares[1:] {
+		assert.Equal(t, shares[0].ECDSAKey, s.ECDSAKey)
+		assert.Equal(t, shares[0].CoSigners, s.CoSigners)
+		assert.NotEqual(t, shares[0].CoSignerKey, s.CoSignerKey)
+	}
+
+	digest := sha256.Sum256([]byte("root certificate"))
+	for _, members := range [][]int{{1, 2, 3}, {1, 3, 5}, {2, 4, 5}, {1, 2, 3, 4}} {
+		var present []*KeyShare
+		for _, i := range members {
+			present = append(present, shares[i-1])
+		}
+		signer, err := Combine(present...)
+		require.NoError(t, err, "members %v", members)
+		assert.Equal(t, members, signer.Members())
+		assert.True(t, key.PublicKey.Equal(signer.Public()))
+		sig, err := signer.Sign(rand.Reader, digest[:], nil)
+		require.NoError(t, err)
+		assert.True(t, ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig))
+		signer.Clean()
+		_, err = signer.Sign(rand.Reader, digest[:], nil)
+		assert.Error(t, err)
+	}
+
+	_, err := Combine(shares[0], shares[1])
+	assert.ErrorContains(t, err, "needs 3 shares")
+	_, err = Combine(shares[0], shares[1], shares[1])
+	assert.ErrorIs(t, err, ErrInvalidShare)
+
+	// A corrupted share is detected by the public key
+	corrupted := *shares[2]
+	corrupted.Secret = bytes.Clone(corrupted.Secret)
+	corrupted.Secret[0] ^= 1
+	_, err = Combine(shares[0], shares[1], &corrupted)
+	assert.ErrorIs(t, err, ErrInvalidShare)
+	assert.ErrorContains(t, err, "do not rebuild")
+
+	_, others := newShares(t, Params{Members: 5, Threshold: 3})
+	_, err = Combine(shares[0], shares[1], others[2])
+	assert.ErrorIs(t, err, ErrInvalidShare)
+
+	assert.Error(t, Params{Members: 3, Threshold: 1}.Validate(), "a single member holds the key")
+	assert.Error(t, Params{Members: 3, Threshold: 4}.Validate())
+	_, err = Split(key, Params{Members: 3, Threshold: 1})
+	assert.Error(t, err)
+}
+
+func TestLowS(t *testing.T) {
+	_, shares := newShares(t, Params{Members: 2, Threshold: 2})
+	signer, err := Combine(shares...)
+	require.NoError(t, err)
+	defer signer.Clean()
+	half := new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+	digest := sha256.Sum256([]byte("low s"))
+	for i := 0; i < 16; i++ {
+		sig, err := signer.Sign(nil, digest[:], nil)
+		require.NoError(t, err)
+		var parsed struct{ R, S *big.Int }
+		_, err = asn1.Unmarshal(sig, &parsed)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, parsed.S.Cmp(half), 0)
+	}
+}
+
+func TestCeremonyCA(t *testing.T) {
+	_, shares := newShares(t, Params{Members: 4, Threshold: 2})
+	signer, err := Combine(shares[0], shares[3])
+	require.NoError(t, err)
+	defer signer.Clean()
+
+	subject := pkix.Name{Organization: []string{"Consortium"}, CommonName: "root.consortium.example.com"}
+	req, err := NewRequest(subject, time.Hour, nil)
+	require.NoError(t, err)
+	root, err := signer.Issue(req, nil)
+	require.NoError(t, err)
+	require.True(t, root.IsComposite())
+	require.NotNil(t, root.CoSigners)
+	assert.Equal(t, shares[0].CoSigners, root.CoSigners.Keys)
+	require.NoError(t, root.CheckSignatureFrom(root))
+	_, err = root.CompositePublicKey()
+	assert.ErrorIs(t, err, hybridx509.ErrNotComposite)
+
+	key, err := hybridx509.GenerateKey()
+	require.NoError(t, err)
+	req, err = NewRequest(pkix.Name{Organization: []string{"Org1"}, CommonName: "ca.org1.example.com"}, time.Hour, key.Public())
+	require.NoError(t, err)
+	name, err := req.SubjectName()
+	require.NoError(t, err)
+	assert.Equal(t, "ca.org1.example.com", name.CommonName)
+
+	// Any Threshold members sign later certificates
+	later, err := Combine(shares[1], shares[2])
+	require.NoError(t, err)
+	defer later.Clean()
+	cert, err := later.Issue(req, root)
+	require.NoError(t, err)
+	require.NoError(t, cert.CheckSignatureFrom(root))
+	assert.True(t, cert.MaxPathLenZero)
+
+	// The intermediate is an ordinary CA
+	inter := &ca.CA{Identity: ca.Identity{Cert: cert, Key: key}, Chain: []*hybridx509.Certificate{root}}
+	peer, err := inter.Issue(ca.Request{CommonName: "peer0.org1.example.com", OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	require.NoError(t, peer.Cert.CheckSignatureFrom(inter.Cert))
+
+	// The alternative signature needs Threshold co-signers
+	msg := []byte("tbs certificate")
+	var sigs []hybridx509.CoSignature
+	for _, share := range shares[:2] {
+		coSigner, err := share.coSigner()
+		require.NoError(t, err)
+		sig, err := coSigner.Sign(msg)
+		require.NoError(t, err)
+		sigs = append(sigs, hybridx509.CoSignature{Signer: share.Index, Signature: sig})
+	}
+	one, err := hybridx509.MarshalCoSignatures(sigs[:1])
+	require.NoError(t, err)
+	assert.ErrorIs(t, root.CoSigners.Verify(msg, one), hybridx509.ErrCoSignature)
+	two, err := hybridx509.MarshalCoSignatures(sigs)
+	require.NoError(t, err)
+	assert.NoError(t, root.CoSigners.Verify(msg, two))
+	twice, err := hybridx509.MarshalCoSignatures([]hybridx509.CoSignature{sigs[0], sigs[0]})
+	require.NoError(t, err)
+	assert.ErrorIs(t, root.CoSigners.Verify(msg, twice), hybridx509.ErrCoSignature)
+}
@@ -0,0 +1,151 @@
+package ceremony
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Request is a CA certificate the members agree to issue with the ceremony
+// key: one member writes it, the members present check it before it is
+// signed
+type Request struct {
+	Serial *big.Int `json:"serial"`
+	// Subject is the DER of the subject name
+	Subject   []byte    `json:"subject"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	// PublicKey is the composite public key (DER) of an intermediate CA,
+	// empty for the root
+	PublicKey []byte `json:"public_key,omitempty"`
+}
+
+// NewRequest returns the request of a CA certificate for subject. A nil pub
+// requests the self-signed root of the ceremony key.
+func NewRequest(subject pkix.Name, validity time.Duration, pub *hybridx509.PublicKey) (*Request, error) {
+	raw, err := asn1.Marshal(subject.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Add(-5 * time.Minute).Truncate(time.Second)
+	req := &Request{Serial: serial, Subject: raw, NotBefore: now, NotAfter: now.Add(validity)}
+	if pub != nil {
+		spki, err := x509.MarshalPKIXPublicKey(pub.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+		if req.PublicKey, err = hybrid.MarshalCompositePublicKey(spki, pub.PQC); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// SubjectName returns the parsed subject, for members to check the request
+func (r *Request) SubjectName() (pkix.Name, error) {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(r.Subject, &rdns); err != nil || len(rest) != 0 {
+		return pkix.Name{}, errors.New("invalid request subject")
+	}
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdns)
+	return name, nil
+}
+
+// compositeKey returns the intermediate key of the request
+func (r *Request) compositeKey() (*hybridx509.PublicKey, error) {
+	ecdsaDER, pqc, err := core.ParseCompositePublicKey(r.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(ecdsaDER)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("request key is not an ECDSA key")
+	}
+	return &hybridx509.PublicKey{ECDSA: pub, PQC: pqc}, nil
+}
+
+// template is the CA certificate template of a request for pub, as
+// ca.NewRoot and ca.NewIntermediate build them
+func (r *Request) template(pub *ecdsa.PublicKey, intermediate bool) (*x509.Certificate, error) {
+	if r.Serial == nil || !r.NotAfter.After(r.NotBefore) {
+		return nil, errors.New("invalid request")
+	}
+	point, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber:          r.Serial,
+		RawSubject:            r.Subject,
+		NotBefore:             r.NotBefore,
+		NotAfter:              r.NotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        intermediate,
+		SubjectKeyId:          subjectKeyID(point),
+	}, nil
+}
+
+// subjectKeyID follows Fabric's convention of hashing the ECDSA public point
+func subjectKeyID(pub *ecdh.PublicKey) []byte {
+	sum := sha256.Sum256(pub.Bytes())
+	return sum[:]
+}
+
+// Issue signs the certificate of req with the ceremony key: the self-signed
+// root when root is nil, else an intermediate signed by root, the
+// certificate of the ceremony key
+func (s *Signer) Issue(req *Request, root *hybridx509.Certificate) (*hybridx509.Certificate, error) {
+	var (
+		pub    *hybridx509.PublicKey
+		parent *x509.Certificate
+		err    error
+	)
+	if root == nil {
+		if len(req.PublicKey) != 0 {
+			return nil, errors.New("a root request has no public key")
+		}
+		pub, err = s.PublicKey()
+	} else {
+		if root.CoSigners == nil {
+			return nil, fmt.Errorf("%s is not a ceremony certificate", root.Subject)
+		}
+		parent = root.Certificate
+		pub, err = req.compositeKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := req.template(pub.ECDSA, root != nil)
+	if err != nil {
+		return nil, err
+	}
+	der, err := hybridx509.CreateCertificateWith(tmpl, parent, pub, s, s.CoSign)
+	if err != nil {
+		return nil, err
+	}
+	return hybridx509.ParseCertificate(der)
+}
@@ -0,0 +1,87 @@
+package ceremony
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// Shamir secret sharing over GF(2^8), byte by byte, as in HashiCorp Vault:
+// share x of a secret holds, for every byte of the secret, the value at x of
+// a random polynomial of degree threshold-1 whose constant term is the byte.
+// The field arithmetic on secret bytes has no branches or table lookups;
+// only the member indexes, which are public, drive control flow.
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x + 1
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = a<<1 ^ 0x1b&-(a>>7)
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns a^254, the inverse of a non-zero a
+func gfInv(a byte) byte {
+	b := gfMul(a, a) // a^2
+	c := gfMul(a, b) // a^3
+	b = gfMul(c, c)  // a^6
+	b = gfMul(b, b)  // a^12
+	c = gfMul(b, c)  // a^15
+	b = gfMul(b, b)  // a^24
+	b = gfMul(b, b)  // a^48
+	b = gfMul(b, c)  // a^63
+	b = gfMul(b, b)  // a^126
+	b = gfMul(a, b)  // a^127
+	return gfMul(b, b)
+}
+
+// splitSecret returns the shares of secret at x = 1..n, any threshold of
+// which rebuild it
+func splitSecret(secret []byte, n, threshold int) ([][]byte, error) {
+	if threshold < 1 || threshold > n || n > 255 {
+		return nil, errors.New("invalid secret sharing parameters")
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret))
+	}
+	coefficients := make([]byte, threshold-1)
+	defer clear(coefficients)
+	for b, s := range secret {
+		if _, err := rand.Read(coefficients); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			x := byte(i + 1)
+			// Horner's rule, highest coefficient first
+			var y byte
+			for k := len(coefficients) - 1; k >= 0; k-- {
+				y = gfMul(y, x) ^ coefficients[k]
+			}
+			shares[i][b] = gfMul(y, x) ^ s
+		}
+	}
+	return shares, nil
+}
+
+// combineSecret interpolates at 0 the shares ys taken at the distinct,
+// non-zero xs
+func combineSecret(xs []byte, ys [][]byte) []byte {
+	secret := make([]byte, len(ys[0]))
+	for i, xi := range xs {
+		// Lagrange basis polynomial i at 0: the product of xj / (xj - xi),
+		// subtraction being xor
+		l := byte(1)
+		for j, xj := range xs {
+			if i != j {
+				l = gfMul(l, gfMul(xj, gfInv(xj^xi)))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= gfMul(l, ys[i][b])
+		}
+	}
+	return secret
+}
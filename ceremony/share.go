@@ -0,0 +1,151 @@
+// Package ceremony backs up the root CA key of a consortium with Shamir
+// secret sharing, so that no single organization holds the root signing
+// capability at rest between ceremonies.
+//
+// Threat model: the ECDSA key is generated on an offline ceremony machine,
+// split into one share per member and wiped. Any Threshold members bring
+// their shares back to an offline machine to rebuild the key and sign CA
+// certificates; fewer shares reveal nothing about the key. The machine sees
+// the whole key while generating it and while signing, so it must be
+// trusted: air-gapped, booted from known media and attended by the
+// members. The package does not protect against a compromised ceremony
+// machine, nor against Threshold colluding members. It does not implement
+// threshold ECDSA: the key is whole whenever it is used.
+//
+// Generating the key by multi-party computation among the members, so
+// that no organization or machine ever holds it whole, is out of scope:
+// it needs a vetted threshold ECDSA and DKG implementation, which the
+// module does not depend on.
+//
+// Instead of one ML-DSA key, the ceremony key has one co-signing key per
+// member, kept in the member's share, and certificates carry the signatures
+// of the members present, see hybridx509.CoSignerSet.
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// ErrInvalidShare is returned when key shares are inconsistent or do not
+// rebuild the ceremony key
+var ErrInvalidShare = errors.New("invalid key share")
+
+// Params are the consortium size and the threshold of a ceremony key
+type Params struct {
+	Members int `json:"members"`
+	// Threshold members rebuild the key and co-sign certificates
+	Threshold int `json:"threshold"`
+}
+
+// Validate checks that no single member holds the key and that the members
+// can rebuild it
+func (p Params) Validate() error {
+	if p.Threshold < 2 || p.Threshold > p.Members || p.Members > 255 {
+		return fmt.Errorf("threshold %d out of range for %d members", p.Threshold, p.Members)
+	}
+	return nil
+}
+
+// KeyShare is what a member keeps between ceremonies: its share of the
+// ECDSA key and its ML-DSA co-signing key. It must be stored as a private
+// key.
+type KeyShare struct {
+	Params Params `json:"params"`
+	// Index is the member's index, from 1
+	Index int `json:"index"`
+	// Secret is the member's share of the ECDSA private key
+	Secret []byte `json:"secret"`
+	// ECDSAKey is the ECDSA public key (PKIX DER)
+	ECDSAKey []byte `json:"ecdsa_key"`
+	// CoSigners are the ML-DSA public keys of the members, by index
+	CoSigners [][]byte `json:"co_signers"`
+	// CoSignerKey is the member's ML-DSA private key
+	CoSignerKey []byte `json:"co_signer_key"`
+}
+
+// Split shares key, a P-256 key generated on the ceremony machine, among
+// params.Members and generates their co-signing keys. The caller wipes key
+// once the shares are stored.
+func Split(key *ecdsa.PrivateKey, params Params) ([]*KeyShare, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if key == nil || key.Curve != elliptic.P256() {
+		return nil, errors.New("the ceremony key must be a P-256 key")
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	secret := key.D.FillBytes(make([]byte, 32))
+	defer clear(secret)
+	secrets, err := splitSecret(secret, params.Members, params.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]*KeyShare, params.Members)
+	coSigners := make([][]byte, params.Members)
+	for i := range shares {
+		coSigner, err := hybrid.NewPQCSigner()
+		if err != nil {
+			return nil, err
+		}
+		coSigners[i] = coSigner.PublicKey()
+		shares[i] = &KeyShare{
+			Params:      params,
+			Index:       i + 1,
+			Secret:      secrets[i],
+			ECDSAKey:    pub,
+			CoSigners:   coSigners,
+			CoSignerKey: bytes.Clone(coSigner.PrivateKey()),
+		}
+		coSigner.Clean()
+	}
+	return shares, nil
+}
+
+// ECDSAPublicKey returns the ECDSA public key of the ceremony key
+func (s *KeyShare) ECDSAPublicKey() (*ecdsa.PublicKey, error) {
+	parsed, err := x509.ParsePKIXPublicKey(s.ECDSAKey)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := parsed.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() {
+		return nil, errors.New("the ceremony key is not a P-256 key")
+	}
+	return pub, nil
+}
+
+// CoSignerSet returns the alternative public key of the ceremony key
+func (s *KeyShare) CoSignerSet() *hybridx509.CoSignerSet {
+	return &hybridx509.CoSignerSet{Threshold: s.Params.Threshold, Keys: s.CoSigners}
+}
+
+// PublicKey returns the composite public key of certificates issued for the
+// ceremony key
+func (s *KeyShare) PublicKey() (*hybridx509.PublicKey, error) {
+	pub, err := s.ECDSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &hybridx509.PublicKey{ECDSA: pub, CoSigners: s.CoSignerSet()}, nil
+}
+
+// coSigner loads the member's ML-DSA key
+func (s *KeyShare) coSigner() (*hybrid.PQCSigner, error) {
+	if s.Index < 1 || s.Index > len(s.CoSigners) {
+		return nil, fmt.Errorf("member %d has no co-signing key", s.Index)
+	}
+	// The signer is cleaned after use, which may wipe the key it was given
+	return hybrid.NewPQCSignerFromKeyPair(bytes.Clone(s.CoSignerKey), s.CoSigners[s.Index-1])
+}
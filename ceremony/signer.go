@@ -0,0 +1,167 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Signer is the ceremony key rebuilt from the shares of the members present
+// at a ceremony, together with their co-signing keys. Clean it as soon as
+// the ceremony is over.
+type Signer struct {
+	share     *KeyShare
+	pub       *ecdsa.PublicKey
+	key       *ecdsa.PrivateKey
+	indexes   []int
+	coSigners []*hybrid.PQCSigner
+}
+
+// Combine rebuilds the ceremony key from the shares of at least Threshold
+// distinct members. The rebuilt key is checked against the public key the
+// shares record, so a corrupted or foreign share is detected, though not
+// attributed to its member.
+func Combine(shares ...*KeyShare) (*Signer, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("%w: no shares", ErrInvalidShare)
+	}
+	first := shares[0]
+	if err := first.Params.Validate(); err != nil {
+		return nil, err
+	}
+	if len(shares) < first.Params.Threshold {
+		return nil, fmt.Errorf("the ceremony key needs %d shares, got %d", first.Params.Threshold, len(shares))
+	}
+	pub, err := first.ECDSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+	indexes := make([]int, len(shares))
+	for i, s := range shares {
+		switch {
+		case s.Params != first.Params || !bytes.Equal(s.ECDSAKey, first.ECDSAKey) ||
+			!slices.EqualFunc(s.CoSigners, first.CoSigners, bytes.Equal):
+			return nil, fmt.Errorf("%w: member %d has a share of another key", ErrInvalidShare, s.Index)
+		case s.Index < 1 || s.Index > s.Params.Members || len(s.CoSigners) != s.Params.Members:
+			return nil, fmt.Errorf("%w: invalid member %d", ErrInvalidShare, s.Index)
+		case slices.Contains(indexes, s.Index):
+			return nil, fmt.Errorf("%w: member %d is given twice", ErrInvalidShare, s.Index)
+		case len(s.Secret) != 32:
+			return nil, fmt.Errorf("%w: member %d share is malformed", ErrInvalidShare, s.Index)
+		}
+		xs[i], ys[i], indexes[i] = byte(s.Index), s.Secret, s.Index
+	}
+
+	secret := combineSecret(xs, ys)
+	defer clear(secret)
+	key, err := ecdsaKey(secret)
+	if err != nil || !key.PublicKey.Equal(pub) {
+		return nil, fmt.Errorf("%w: the shares do not rebuild the ceremony key", ErrInvalidShare)
+	}
+	signer := &Signer{share: first, pub: pub, key: key, indexes: indexes}
+	for _, s := range shares {
+		coSigner, err := s.coSigner()
+		if err != nil {
+			signer.Clean()
+			return nil, fmt.Errorf("%w: member %d: %w", ErrInvalidShare, s.Index, err)
+		}
+		signer.coSigners = append(signer.coSigners, coSigner)
+	}
+	return signer, nil
+}
+
+// ecdsaKey returns the P-256 key of a big-endian scalar, deriving its public
+// key in constant time
+func ecdsaKey(secret []byte) (*ecdsa.PrivateKey, error) {
+	priv, err := ecdh.P256().NewPrivateKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	// Uncompressed point: 0x04 || X || Y
+	point := priv.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(secret),
+	}, nil
+}
+
+// Members returns the indexes of the members whose shares were combined
+func (s *Signer) Members() []int {
+	return slices.Clone(s.indexes)
+}
+
+// PublicKey returns the composite public key of the ceremony key
+func (s *Signer) PublicKey() (*hybridx509.PublicKey, error) {
+	return s.share.PublicKey()
+}
+
+// Public implements crypto.Signer: the ECDSA public key
+func (s *Signer) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign implements crypto.Signer with a low-S ECDSA signature of digest, the
+// form Fabric accepts
+func (s *Signer) Sign(random io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	if s.key == nil {
+		return nil, errors.New("the ceremony key was cleaned")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	r, sig, err := ecdsa.Sign(random, s.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	if sig.Cmp(new(big.Int).Rsh(s.key.Params().N, 1)) > 0 {
+		sig.Sub(s.key.Params().N, sig)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, sig})
+}
+
+// CoSign returns the co-signatures of msg by the members present, encoded
+// by hybridx509.MarshalCoSignatures
+func (s *Signer) CoSign(msg []byte) ([]byte, error) {
+	sigs := make([]hybridx509.CoSignature, 0, len(s.coSigners))
+	for i, coSigner := range s.coSigners {
+		sig, err := coSigner.Sign(msg)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: %w", s.indexes[i], err)
+		}
+		sigs = append(sigs, hybridx509.CoSignature{Signer: s.indexes[i], Signature: sig})
+	}
+	return hybridx509.MarshalCoSignatures(sigs)
+}
+
+// Clean wipes the ECDSA scalar and the co-signing keys. Copies made by the
+// Go runtime cannot be wiped, which is one more reason to power the
+// ceremony machine off afterwards.
+func (s *Signer) Clean() {
+	for _, coSigner := range s.coSigners {
+		coSigner.Clean()
+	}
+	s.coSigners = nil
+	if s.key != nil {
+		clear(core.ECDSASecret(s.key))
+		s.key = nil
+	}
+}
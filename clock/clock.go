@@ -0,0 +1,82 @@
+// Package clock abstracts time for the benchmark harness, so that load
+// generation and collection can run on virtual time in tests and replays.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and creates timers
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer of a Clock
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing; it returns false if it already
+	// fired or was stopped
+	Stop() bool
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+// Or returns c, or Real when c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+// SleepUntil waits until c reaches t or ctx is done
+func SleepUntil(ctx context.Context, c Clock, t time.Time) error {
+	timer := c.NewTimer(t.Sub(c.Now()))
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithTimeout is context.WithTimeout on c. Contexts of other clocks than
+// Real carry no deadline; when the timeout expires they are cancelled
+// with context.DeadlineExceeded as their cause.
+func WithTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if c == Real {
+		return context.WithTimeout(ctx, d)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := c.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// TimedOut reports whether ctx was cancelled by its timeout, on any clock
+func TimedOut(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded || context.Cause(ctx) == context.DeadlineExceeded
+}
@@ -0,0 +1,76 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtual(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	v := NewVirtual(start)
+	assert.Equal(t, start, v.Now())
+
+	late := v.NewTimer(2 * time.Second)
+	early := v.NewTimer(time.Second)
+	stopped := v.NewTimer(time.Second)
+	assert.Equal(t, 3, v.Pending())
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	v.Advance(500 * time.Millisecond)
+	select {
+	case <-early.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	require.True(t, v.AdvanceToNext())
+	assert.Equal(t, start.Add(time.Second), <-early.C())
+	assert.Equal(t, start.Add(time.Second), v.Now())
+	v.Advance(time.Hour)
+	assert.Equal(t, start.Add(2*time.Second), <-late.C())
+	assert.False(t, v.AdvanceToNext())
+	assert.False(t, early.Stop())
+
+	immediate := v.NewTimer(0)
+	assert.Equal(t, start.Add(time.Hour+time.Second), <-immediate.C())
+}
+
+func TestSleepAndTimeout(t *testing.T) {
+	v := NewVirtual(time.Unix(0, 0))
+	done := make(chan error)
+	go func() { done <- SleepUntil(context.Background(), v, time.Unix(10, 0)) }()
+	require.NoError(t, v.BlockUntil(context.Background(), 1))
+	v.Advance(10 * time.Second)
+	require.NoError(t, <-done)
+
+	ctx, cancel := WithTimeout(context.Background(), v, time.Minute)
+	defer cancel()
+	require.NoError(t, v.BlockUntil(context.Background(), 1))
+	assert.NoError(t, ctx.Err())
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	v.Advance(time.Minute)
+	<-ctx.Done()
+	assert.True(t, TimedOut(ctx))
+
+	ctx, cancel = WithTimeout(context.Background(), v, time.Minute)
+	cancel()
+	assert.False(t, TimedOut(ctx))
+	require.Eventually(t, func() bool { return v.Pending() == 0 }, time.Second, time.Millisecond)
+
+	ctx, cancel = WithTimeout(context.Background(), Real, time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.True(t, TimedOut(ctx))
+
+	short, stop := context.WithCancel(context.Background())
+	stop()
+	assert.ErrorIs(t, v.BlockUntil(short, 5), context.Canceled)
+	assert.ErrorIs(t, SleepUntil(short, v, time.Unix(100, 0)), context.Canceled)
+	assert.Equal(t, Real, Or(nil))
+	assert.Equal(t, Clock(v), Or(v))
+}
@@ -0,0 +1,134 @@
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Virtual is a Clock that only moves when told to. Timers fire, in order,
+// as Advance passes their deadline.
+type Virtual struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+	// changed is closed and replaced whenever timers change
+	changed chan struct{}
+}
+
+// NewVirtual returns a virtual clock reading start
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the virtual time
+func (v *Virtual) Now() time.Time {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.now
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d; timers
+// with d <= 0 fire immediately
+func (v *Virtual) NewTimer(d time.Duration) Timer {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	t := &virtualTimer{v: v, at: v.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- v.now
+		return t
+	}
+	v.timers = append(v.timers, t)
+	sort.SliceStable(v.timers, func(i, j int) bool { return v.timers[i].at.Before(v.timers[j].at) })
+	v.notify()
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers it passes
+func (v *Virtual) Advance(d time.Duration) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.advanceTo(v.now.Add(d))
+}
+
+// AdvanceToNext moves the clock to the earliest pending timer and fires
+// it. It returns false, leaving the clock unchanged, without timers.
+func (v *Virtual) AdvanceToNext() bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if len(v.timers) == 0 {
+		return false
+	}
+	v.advanceTo(v.timers[0].at)
+	return true
+}
+
+// Pending returns the number of timers that have not fired or been stopped
+func (v *Virtual) Pending() int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return len(v.timers)
+}
+
+// BlockUntil waits until at least n timers are pending, e.g. until the
+// code under test sleeps, or until ctx is done
+func (v *Virtual) BlockUntil(ctx context.Context, n int) error {
+	for {
+		v.mutex.Lock()
+		pending, changed := len(v.timers), v.changed
+		v.mutex.Unlock()
+		if pending >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (v *Virtual) advanceTo(t time.Time) {
+	if t.After(v.now) {
+		v.now = t
+	}
+	fired := 0
+	for _, timer := range v.timers {
+		if timer.at.After(v.now) {
+			break
+		}
+		timer.c <- timer.at
+		fired++
+	}
+	if fired > 0 {
+		v.timers = v.timers[fired:]
+		v.notify()
+	}
+}
+
+func (v *Virtual) notify() {
+	close(v.changed)
+	v.changed = make(chan struct{})
+}
+
+type virtualTimer struct {
+	v  *Virtual
+	at time.Time
+	c  chan time.Time
+}
+
+func (t *virtualTimer) C() <-chan time.Time { return t.c }
+
+func (t *virtualTimer) Stop() bool {
+	t.v.mutex.Lock()
+	defer t.v.mutex.Unlock()
+	for i, other := range t.v.timers {
+		if other == t {
+			t.v.timers = append(t.v.timers[:i], t.v.timers[i+1:]...)
+			t.v.notify()
+			return true
+		}
+	}
+	return false
+}
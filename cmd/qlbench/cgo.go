@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// cgoJSON is the result of qlbench cgo -output json, durations in
+// nanoseconds
+type cgoJSON struct {
+	Call        int64   `json:"call_ns"`
+	ECDSASign   int64   `json:"ecdsa_sign_ns"`
+	PQCSign     int64   `json:"pqc_sign_ns"`
+	Algorithmic int64   `json:"pqc_algorithmic_ns"`
+	HybridSign  int64   `json:"hybrid_sign_ns"`
+	FFIShare    float64 `json:"ffi_share"`
+}
+
+// runCGO measures how much of hybrid signing latency is the CGO boundary
+func runCGO(args []string) error {
+	fs := cli.NewFlagSet("cgo")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	o, err := bench.MeasureCGOOverhead()
+	if err != nil {
+		return err
+	}
+	res := cgoJSON{
+		Call: o.Call.Nanoseconds(), ECDSASign: o.ECDSASign.Nanoseconds(), PQCSign: o.PQCSign.Nanoseconds(),
+		Algorithmic: o.Algorithmic().Nanoseconds(), HybridSign: o.HybridSign.Nanoseconds(), FFIShare: o.FFIShare(),
+	}
+	cli.Print(res, func() {
+		fmt.Printf("cgo call:     %v\n", o.Call)
+		fmt.Printf("ecdsa sign:   %v\n", o.ECDSASign)
+		fmt.Printf("ml-dsa sign:  %v (algorithmic %v)\n", o.PQCSign, o.Algorithmic())
+		fmt.Printf("hybrid sign:  %v\n", o.HybridSign)
+		fmt.Printf("ffi share:    %.4f%% (%d call per signature)\n", 100*o.FFIShare(), bench.CGOCallsPerSign)
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+var errRegressions = cli.Rejected(errors.New("performance regressions found"))
+
+// compareJSON is the result of qlbench compare -output json
+type compareJSON struct {
+	Compared    int              `json:"compared"`
+	Skipped     []string         `json:"skipped"`
+	Regressions []regressionJSON `json:"regressions"`
+}
+
+type regressionJSON struct {
+	Group     string  `json:"group"`
+	Metric    string  `json:"metric"`
+	Statistic string  `json:"statistic"`
+	Baseline  float64 `json:"baseline"`
+	Current   float64 `json:"current"`
+	Change    float64 `json:"change"`
+	Limit     float64 `json:"limit"`
+}
+
+// runCompare loads two result directories and prints regressions
+func runCompare(args []string) error {
+	fs := cli.NewFlagSet("compare")
+	baselineDir := fs.String("baseline", "", "directory with the baseline CSV files")
+	currentDir := fs.String("current", "", "directory with the current CSV files")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *baselineDir == "" || *currentDir == "" {
+		return cli.Usagef("--baseline and --current are required")
+	}
+
+	baseline, err := bench.LoadDir(*baselineDir)
+	if err != nil {
+		return err
+	}
+	current, err := bench.LoadDir(*currentDir)
+	if err != nil {
+		return err
+	}
+
+	warnPlatforms(*baselineDir, *currentDir)
+
+	report, err := bench.Compare(baseline, current, bench.DefaultThresholds())
+	if err != nil {
+		return err
+	}
+	res := compareJSON{Compared: report.Compared, Skipped: append([]string{}, report.Skipped...), Regressions: []regressionJSON{}}
+	for _, r := range report.Regressions {
+		res.Regressions = append(res.Regressions, regressionJSON{
+			Group: r.Group.String(), Metric: r.Metric, Statistic: string(r.Statistic),
+			Baseline: r.Baseline, Current: r.Current, Change: r.Change, Limit: r.Limit,
+		})
+	}
+	cli.Print(res, func() {
+		fmt.Printf("compared %d statistics, skipped %d\n", report.Compared, len(report.Skipped))
+		for _, r := range report.Regressions {
+			fmt.Println("REGRESSION", r)
+		}
+	})
+	if report.Failed() {
+		return errRegressions
+	}
+	return nil
+}
+
+// warnPlatforms warns when the two directories were measured on platforms
+// whose results are not comparable, e.g. amd64 against arm64
+func warnPlatforms(baselineDir, currentDir string) {
+	baseline, err1 := readPlatform(filepath.Join(baselineDir, PlatformFile))
+	current, err2 := readPlatform(filepath.Join(currentDir, PlatformFile))
+	if err1 != nil || err2 != nil {
+		return
+	}
+	if !baseline.Comparable(current) {
+		fmt.Fprintf(os.Stderr, "warning: baseline measured on %s, current on %s\n", baseline, current)
+	}
+}
+
+func readPlatform(path string) (bench.Platform, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return bench.Platform{}, err
+	}
+	defer f.Close()
+	return bench.ReadPlatform(f)
+}
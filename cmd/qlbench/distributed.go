@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/loadgen"
+)
+
+// agentsFlag collects repeated agent URLs in order
+type agentsFlag []string
+
+func (a *agentsFlag) String() string { return strings.Join(*a, ",") }
+
+func (a *agentsFlag) Set(v string) error {
+	if !strings.HasPrefix(v, "http://") && !strings.HasPrefix(v, "https://") {
+		return fmt.Errorf("expected an http(s) URL, got %q", v)
+	}
+	*a = append(*a, strings.TrimSuffix(v, "/"))
+	return nil
+}
+
+// runAgent serves shares of coordinated runs against the in-process
+// simulator until interrupted
+func runAgent(args []string) error {
+	fs := cli.NewFlagSet("agent")
+	listen := fs.String("listen", ":7070", "address to serve the coordinator on")
+	endorsers := fs.Int("endorsers", 2, "endorsing peers")
+	keys := fs.Int("keys", 1000, "key space size, smaller means more MVCC conflicts")
+	mismatch := fs.Float64("mismatch-rate", 0, "fraction of transactions with mismatching endorsements")
+	corrupt := fs.Float64("corrupt-rate", 0, "fraction of transactions with a corrupted endorsement signature")
+	mode := fs.String("mode", loadgen.ModeHybrid, "crypto mode of the endorsers: ECDSA, DILITHIUM3 or HYBRID")
+	seed := fs.Int64("seed", 1, "random seed of the simulator")
+	events := fs.Bool("events", true, "confirm transactions from block events and measure confirmation time")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	sim, err := loadgen.NewModeSimulator(*mode, *endorsers, *keys, *seed)
+	if err != nil {
+		return err
+	}
+	defer sim.Close()
+	sim.MismatchRate, sim.CorruptRate = *mismatch, *corrupt
+	agent := &loadgen.Agent{Submitter: sim, CryptoMode: sim.Mode()}
+	if *events {
+		agent.Events = sim
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	srv := &http.Server{Addr: *listen, Handler: agent.Handler()}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	cli.Infof("agent listening on %s\n", *listen)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// runCoordinate spreads runs over agents and reports the merged results,
+// corrected for the agents' clock skew
+func runCoordinate(args []string) error {
+	fs := cli.NewFlagSet("coordinate")
+	var agents agentsFlag
+	fs.Var(&agents, "agent", "base URL of an agent, e.g. http://client1:7070 (repeatable)")
+	rate := fs.Float64("rate", 100, "mean arrival rate in transactions per second, over all agents")
+	txs := fs.Int("txs", 1000, "transactions per run, over all agents")
+	runs := fs.Int("runs", 1, "number of runs")
+	concurrency := fs.Int("concurrency", 16, "maximum transactions in flight per agent")
+	payload := fs.Int("payload", 256, "payload size in bytes")
+	timeout := fs.Duration("timeout", 5*time.Second, "per-transaction timeout")
+	profile := fs.String("profile", "HIGH", "load profile recorded in the report")
+	seed := fs.Int64("seed", 1, "random seed")
+	lead := fs.Duration("lead", time.Second, "delay between dispatching a run and its synchronized start")
+	out := fs.String("failures", "", "write failure rates per run and class to this CSV file")
+	confirmations := fs.String("confirmations", "", "write confirmation times as dataset rows to this CSV file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if len(agents) == 0 {
+		return cli.Usagef("at least one -agent is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	c := &loadgen.Coordinator{Agents: agents, Lead: *lead}
+	var reports []*loadgen.Report
+	for run := 1; run <= *runs; run++ {
+		report, agentReports, err := c.Run(ctx, loadgen.Config{
+			RunID:        run,
+			LoadProfile:  *profile,
+			Rate:         *rate,
+			Transactions: *txs,
+			Concurrency:  *concurrency,
+			PayloadSize:  *payload,
+			Timeout:      *timeout,
+			Seed:         *seed + int64(run),
+		})
+		for _, ar := range agentReports {
+			if ar.Report != nil {
+				cli.Infof("  %s (offset %v, rtt %v): %v\n", ar.Agent, ar.Skew.Offset.Round(time.Microsecond),
+					ar.Skew.RTT.Round(time.Microsecond), ar.Report)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		cli.Infof("%v\n", report)
+		reports = append(reports, report)
+	}
+
+	printReports(reports)
+
+	if *out != "" {
+		if err := writeCSV(*out, loadgen.WriteFailureCSV, reports); err != nil {
+			return err
+		}
+	}
+	if *confirmations != "" {
+		return writeCSV(*confirmations, loadgen.WriteConfirmationCSV, reports)
+	}
+	return nil
+}
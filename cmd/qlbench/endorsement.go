@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// endorsementJSON is the result of qlbench endorsement -output json, mean
+// durations in nanoseconds
+type endorsementJSON struct {
+	Results []endorsementResultJSON `json:"results"`
+	File    string                  `json:"file"`
+}
+
+type endorsementResultJSON struct {
+	Policy  string `json:"policy"`
+	Cache   string `json:"cache"`
+	Endorse int64  `json:"endorse_ns"`
+	Commit  int64  `json:"commit_ns"`
+	Size    int    `json:"endorsements_bytes"`
+	Samples int    `json:"samples"`
+}
+
+// runEndorsement measures validation cost across endorsement policies and
+// writes the samples as a dataset file
+func runEndorsement(args []string) error {
+	fs := cli.NewFlagSet("endorsement")
+	policies := fs.String("policies", "", "comma-separated k-of-n policies (default 1-of-1,1-of-2,2-of-3,3-of-5,4-of-7,5-of-7)")
+	txs := fs.Int("txs", 200, "transactions per policy")
+	caches := fs.String("cache", "cold,warm", "comma-separated key cache scenarios: cold flushes the committer's parsed keys and verifiers before every verification, warm keeps them")
+	run := fs.Int("run", 1, "run number")
+	out := fs.String("out", ".", "directory for the CSV file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	selected := bench.DefaultEndorsementPolicies
+	if *policies != "" {
+		selected = nil
+		for _, s := range strings.Split(*policies, ",") {
+			p, err := bench.ParseEndorsementPolicy(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			selected = append(selected, p)
+		}
+	}
+
+	var scenarios []bench.KeyCache
+	for _, s := range strings.Split(*caches, ",") {
+		c, err := bench.ParseKeyCache(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		scenarios = append(scenarios, c)
+	}
+
+	var all []bench.EndorsementSample
+	var res endorsementJSON
+	for _, p := range selected {
+		for _, c := range scenarios {
+			samples, err := bench.MeasureEndorsement(p, c, *txs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			var endorse, commit time.Duration
+			for _, s := range samples {
+				endorse += s.Endorse
+				commit += s.Commit
+			}
+			n := time.Duration(len(samples))
+			cli.Infof("%-7s %-4s endorse %10v  commit %10v  endorsements %6d bytes\n",
+				p, c, endorse/n, commit/n, samples[0].Size)
+			res.Results = append(res.Results, endorsementResultJSON{
+				Policy: fmt.Sprint(p), Cache: fmt.Sprint(c), Endorse: (endorse / n).Nanoseconds(),
+				Commit: (commit / n).Nanoseconds(), Size: samples[0].Size, Samples: len(samples),
+			})
+			all = append(all, samples...)
+		}
+	}
+
+	path := filepath.Join(*out, fmt.Sprintf("HYBRID_%s_RUN%d.csv", bench.EndorsementLoadProfile, *run))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteEndorsementCSV(f, *run, all); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	res.File = path
+	cli.Print(res, func() { fmt.Println("wrote", path) })
+	return recordPlatform(*out)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/telemetry"
+)
+
+// energyJSON is the result of qlbench energy -output json
+type energyJSON struct {
+	Results []energyResultJSON `json:"results"`
+	File    string             `json:"file"`
+}
+
+type energyResultJSON struct {
+	Algorithm   string  `json:"algorithm"`
+	Signatures  int     `json:"signatures"`
+	Duration    int64   `json:"duration_ns"`
+	Joules      float64 `json:"joules"`
+	IdleWatts   float64 `json:"idle_watts"`
+	JoulesPer1k float64 `json:"joules_per_1k"`
+}
+
+// runEnergy estimates the energy of signatures per algorithm from the RAPL
+// counters of the host
+func runEnergy(args []string) error {
+	fs := cli.NewFlagSet("energy")
+	algorithms := fs.String("algorithms", strings.Join(bench.EnergyAlgorithms, ","), "comma-separated algorithms to measure")
+	duration := fs.Duration("duration", 10*time.Second, "signing time per algorithm")
+	idle := fs.Duration("idle", 5*time.Second, "idle time measured before each algorithm, subtracted as baseline")
+	powercap := fs.String("powercap", telemetry.PowercapRoot, "powercap sysfs directory")
+	run := fs.Int("run", 1, "run number")
+	out := fs.String("out", ".", "directory for the CSV file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	meter, err := telemetry.OpenRAPL(*powercap)
+	if err != nil {
+		return err
+	}
+	cli.Infof("measuring %s\n", strings.Join(meter.Zones(), ", "))
+
+	start := time.Now()
+	var results []*bench.EnergyResult
+	for _, alg := range strings.Split(*algorithms, ",") {
+		r, err := bench.MeasureSigningEnergy(meter, strings.TrimSpace(alg), *duration, *idle)
+		if err != nil {
+			return err
+		}
+		cli.Infof("%s\n", r)
+		results = append(results, r)
+	}
+
+	path := filepath.Join(*out, fmt.Sprintf("SIGNING_ENERGY_RUN%d.csv", *run))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteSigningEnergyCSV(f, *run, start, results); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	res := energyJSON{File: path}
+	for _, r := range results {
+		res.Results = append(res.Results, energyResultJSON{
+			Algorithm: r.Algorithm, Signatures: r.Signatures, Duration: r.Duration.Nanoseconds(),
+			Joules: r.Joules, IdleWatts: r.IdleWatts, JoulesPer1k: r.JoulesPer1k(),
+		})
+	}
+	cli.Print(res, func() { fmt.Println("wrote", path) })
+	return recordPlatform(*out)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// keystoreJSON is the result of qlbench keystore -output json, durations in
+// nanoseconds
+type keystoreJSON struct {
+	Results []keystoreResultJSON `json:"results"`
+	File    string               `json:"file"`
+}
+
+type keystoreResultJSON struct {
+	Backend  string `json:"backend"`
+	Retrieve int64  `json:"retrieve_ns"`
+	Sign     int64  `json:"sign_ns"`
+	Total    int64  `json:"total_ns"`
+	P99      int64  `json:"total_p99_ns"`
+	Samples  int    `json:"samples"`
+}
+
+// runKeystore measures Sign latency including key retrieval across keystore
+// backends and writes the samples as a dataset file
+func runKeystore(args []string) error {
+	fs := cli.NewFlagSet("keystore")
+	backends := fs.String("backends", "memory,file,kms,hsm", "comma-separated keystore backends")
+	signs := fs.Int("signs", 200, "signatures per backend")
+	dir := fs.String("dir", "", "directory of the file keystore, e.g. on the volume peers use (default a temporary directory)")
+	kms := fs.Duration("kms-rtt", 5*time.Millisecond, "round trip of a KMS unwrap, as measured from the peer's network")
+	hsm := fs.Duration("hsm-rtt", time.Millisecond, "round trip of an HSM signature")
+	run := fs.Int("run", 1, "run number")
+	out := fs.String("out", ".", "directory for the CSV file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *signs < 1 {
+		return cli.Usagef("-signs must be positive")
+	}
+	var selected []bench.KeystoreBackend
+	for _, s := range strings.Split(*backends, ",") {
+		b, err := bench.ParseKeystoreBackend(strings.TrimSpace(s))
+		if err != nil {
+			return cli.Usagef("%v", err)
+		}
+		selected = append(selected, b)
+	}
+
+	cfg := bench.KeystoreConfig{Dir: *dir, KMSRoundTrip: *kms, HSMRoundTrip: *hsm}
+	var all []bench.KeystoreSample
+	var res keystoreJSON
+	for _, b := range selected {
+		samples, err := bench.MeasureKeystore(b, cfg, *signs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", b, err)
+		}
+		var retrieve, sign time.Duration
+		totals := make([]time.Duration, len(samples))
+		for i, s := range samples {
+			retrieve += s.Retrieve
+			sign += s.Sign
+			totals[i] = s.Total()
+		}
+		sort.Slice(totals, func(i, j int) bool { return totals[i] < totals[j] })
+		p99 := totals[(len(totals)*99-1)/100]
+		n := time.Duration(len(samples))
+		cli.Infof("%-6s retrieve %10v  sign %10v  total %10v  p99 %10v\n",
+			b, retrieve/n, sign/n, (retrieve+sign)/n, p99)
+		res.Results = append(res.Results, keystoreResultJSON{
+			Backend: string(b), Retrieve: (retrieve / n).Nanoseconds(), Sign: (sign / n).Nanoseconds(),
+			Total: ((retrieve + sign) / n).Nanoseconds(), P99: p99.Nanoseconds(), Samples: len(samples),
+		})
+		all = append(all, samples...)
+	}
+
+	path := filepath.Join(*out, fmt.Sprintf("HYBRID_%s_RUN%d.csv", bench.KeystoreLoadProfile, *run))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteKeystoreCSV(f, *run, all); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	res.File = path
+	cli.Print(res, func() { fmt.Println("wrote", path) })
+	return recordPlatform(*out)
+}
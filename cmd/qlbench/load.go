@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/loadgen"
+)
+
+// runLoad drives the in-process simulator and reports failure rates per
+// class for each run and confirmation times per crypto mode
+func runLoad(args []string) error {
+	fs := cli.NewFlagSet("load")
+	rate := fs.Float64("rate", 100, "mean arrival rate in transactions per second")
+	txs := fs.Int("txs", 1000, "transactions per run")
+	runs := fs.Int("runs", 1, "number of runs")
+	concurrency := fs.Int("concurrency", 16, "maximum transactions in flight")
+	payload := fs.Int("payload", 256, "payload size in bytes")
+	timeout := fs.Duration("timeout", 5*time.Second, "per-transaction timeout")
+	endorsers := fs.Int("endorsers", 2, "endorsing peers")
+	keys := fs.Int("keys", 1000, "key space size, smaller means more MVCC conflicts")
+	mismatch := fs.Float64("mismatch-rate", 0, "fraction of transactions with mismatching endorsements")
+	corrupt := fs.Float64("corrupt-rate", 0, "fraction of transactions with a corrupted endorsement signature")
+	profile := fs.String("profile", "LOWLOAD", "load profile recorded in the report")
+	seed := fs.Int64("seed", 1, "random seed")
+	out := fs.String("failures", "", "write failure rates per run and class to this CSV file")
+	events := fs.Bool("events", true, "confirm transactions from block events and measure confirmation time")
+	confirmations := fs.String("confirmations", "", "write confirmation times as dataset rows to this CSV file")
+	mode := fs.String("mode", loadgen.ModeHybrid, "crypto mode of the endorsers: ECDSA, DILITHIUM3 or HYBRID")
+	tracePath := fs.String("trace", "", "replay the arrivals and payload sizes of this trace file instead of Poisson arrivals")
+	record := fs.String("record", "", "write the arrivals of the first run to this trace file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	var trace loadgen.Trace
+	if *tracePath != "" {
+		f, err := os.Open(*tracePath)
+		if err != nil {
+			return err
+		}
+		trace, err = loadgen.ReadTrace(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", *tracePath, err)
+		}
+		// Replay the trace once unless -txs is given
+		txsSet := false
+		fs.Visit(func(f *flag.Flag) { txsSet = txsSet || f.Name == "txs" })
+		if !txsSet {
+			*txs = 0
+		}
+	}
+
+	sim, err := loadgen.NewModeSimulator(*mode, *endorsers, *keys, *seed)
+	if err != nil {
+		return err
+	}
+	defer sim.Close()
+	sim.MismatchRate, sim.CorruptRate = *mismatch, *corrupt
+
+	var blockEvents loadgen.BlockEvents
+	if *events {
+		blockEvents = sim
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var reports []*loadgen.Report
+	for run := 1; run <= *runs; run++ {
+		var submitter loadgen.Submitter = sim
+		recorder := &loadgen.Recorder{Submitter: sim}
+		if *record != "" && run == 1 {
+			submitter = recorder
+		}
+		report, err := loadgen.Run(ctx, submitter, loadgen.Config{
+			RunID:        run,
+			CryptoMode:   sim.Mode(),
+			LoadProfile:  *profile,
+			Rate:         *rate,
+			Transactions: *txs,
+			Concurrency:  *concurrency,
+			PayloadSize:  *payload,
+			Timeout:      *timeout,
+			Seed:         *seed + int64(run),
+			Events:       blockEvents,
+			Trace:        trace,
+		})
+		if report != nil {
+			cli.Infof("%v\n", report)
+			reports = append(reports, report)
+		}
+		if err != nil {
+			return err
+		}
+		if submitter == recorder {
+			if err := writeTrace(*record, recorder.Trace()); err != nil {
+				return err
+			}
+		}
+	}
+
+	printReports(reports)
+
+	if *out != "" {
+		if err := writeCSV(*out, loadgen.WriteFailureCSV, reports); err != nil {
+			return err
+		}
+	}
+	if *confirmations != "" {
+		return writeCSV(*confirmations, loadgen.WriteConfirmationCSV, reports)
+	}
+	return nil
+}
+
+// loadJSON is the result of qlbench load and coordinate -output json
+type loadJSON struct {
+	Reports       []*loadgen.Report  `json:"reports"`
+	Confirmations []confirmationJSON `json:"confirmations"`
+}
+
+// confirmationJSON holds the confirmation time percentiles of a crypto
+// mode, in milliseconds
+type confirmationJSON struct {
+	Mode         string  `json:"crypto_mode"`
+	P50          float64 `json:"p50_ms"`
+	P95          float64 `json:"p95_ms"`
+	P99          float64 `json:"p99_ms"`
+	Transactions int     `json:"transactions"`
+}
+
+// printReports reports the runs and their confirmation time percentiles
+// per crypto mode
+func printReports(reports []*loadgen.Report) {
+	res := loadJSON{Reports: reports, Confirmations: confirmationStats(reports)}
+	cli.Print(res, func() {
+		for _, c := range res.Confirmations {
+			fmt.Printf("%s confirmation time: p50 %.3f ms, p95 %.3f ms, p99 %.3f ms (%d transactions)\n",
+				c.Mode, c.P50, c.P95, c.P99, c.Transactions)
+		}
+	})
+}
+
+// confirmationStats returns confirmation time percentiles per crypto mode
+func confirmationStats(reports []*loadgen.Report) []confirmationJSON {
+	stats := []confirmationJSON{}
+	byMode := map[string][]float64{}
+	var modes []string
+	for _, r := range reports {
+		if _, ok := byMode[r.CryptoMode]; !ok {
+			modes = append(modes, r.CryptoMode)
+		}
+		byMode[r.CryptoMode] = append(byMode[r.CryptoMode], r.ConfirmationLatencies()...)
+	}
+	for _, mode := range modes {
+		samples := byMode[mode]
+		if len(samples) == 0 {
+			continue
+		}
+		p50, _ := bench.P50.Compute(samples)
+		p95, _ := bench.P95.Compute(samples)
+		p99, _ := bench.P99.Compute(samples)
+		stats = append(stats, confirmationJSON{Mode: mode, P50: p50, P95: p95, P99: p99, Transactions: len(samples)})
+	}
+	return stats
+}
+
+func writeTrace(path string, t loadgen.Trace) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeCSV(path string, write func(io.Writer, ...*loadgen.Report) error, reports []*loadgen.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, reports...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,44 @@
+// qlbench runs and analyses crypto benchmarks
+package main
+
+import "github.com/yourusername/quantum-ledger/internal/cli"
+
+const usage = `usage: qlbench <command> [flags]
+
+commands:
+  agent     serve shares of coordinated load runs
+  compare   compare two result datasets and report regressions
+  cgo       attribute hybrid signing latency to the CGO boundary vs the algorithms
+  coordinate
+            spread load runs over several agents and merge their reports
+  energy    estimate Joules per 1k signatures per algorithm from RAPL counters
+  endorsement
+            measure commit-time verification across k-of-n endorsement policies
+  keystore  measure Sign latency with key retrieval from file, KMS and HSM keystores
+  load      run a simulated transaction load and report failure rates per class
+  phases    scrape per-phase durations from operations endpoints into the dataset
+  platform  describe the host architecture, CPU and liboqs optimizations
+  run       run the workloads of an experiment file, in containers with pinned resources
+  trace     extract a replayable trace of arrivals and payload sizes from block files
+
+Every command accepts -output json. Exit codes: 0 success, 1 failure,
+2 usage error, 3 check failed (compare found regressions).
+Shell completion: source <(qlbench completion bash), or zsh.
+`
+
+func main() {
+	cli.Main("qlbench", usage, map[string]func([]string) error{
+		"agent":       runAgent,
+		"compare":     runCompare,
+		"cgo":         runCGO,
+		"coordinate":  runCoordinate,
+		"energy":      runEnergy,
+		"endorsement": runEndorsement,
+		"keystore":    runKeystore,
+		"load":        runLoad,
+		"phases":      runPhases,
+		"platform":    runPlatform,
+		"run":         runExperiment,
+		"trace":       runTrace,
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/telemetry"
+)
+
+// targetsFlag collects repeated name=url values in order
+type targetsFlag []telemetry.Target
+
+func (t *targetsFlag) String() string {
+	var parts []string
+	for _, target := range *t {
+		parts = append(parts, target.Name+"="+target.URL)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (t *targetsFlag) Set(v string) error {
+	name, url, ok := strings.Cut(v, "=")
+	if !ok || name == "" || url == "" {
+		return fmt.Errorf("expected name=url, got %q", v)
+	}
+	*t = append(*t, telemetry.Target{Name: name, URL: url})
+	return nil
+}
+
+// runPhases scrapes per-phase durations from operations endpoints during a
+// run and joins them into the run's dataset file
+func runPhases(args []string) error {
+	fs := cli.NewFlagSet("phases")
+	var targets targetsFlag
+	fs.Var(&targets, "target", "operations metrics endpoint as name=url, e.g. peer0=http://peer0:9443/metrics (repeatable)")
+	interval := fs.Duration("interval", 5*time.Second, "scrape interval")
+	duration := fs.Duration("duration", 0, "stop after this long, 0 to run until interrupted")
+	run := fs.Int("run", 1, "run number the samples belong to")
+	out := fs.String("out", "", "write the samples to this CSV file")
+	join := fs.String("join", "", "dataset CSV file of the run to add phase duration columns to")
+	joined := fs.String("joined", "", "output of -join, defaults to overwriting the dataset file")
+	metrics := fs.String("metrics", "", "phase=histogram overrides, e.g. validation=gossip_state_commit_duration")
+	energy := fs.String("energy", "", "also sample this host's RAPL energy counters and write them to this CSV file")
+	powercap := fs.String("powercap", telemetry.PowercapRoot, "powercap sysfs directory for -energy")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if len(targets) == 0 && *energy == "" {
+		return cli.Usagef("at least one -target or -energy is required")
+	}
+
+	c := &telemetry.Collector{Targets: targets, Metrics: map[telemetry.Phase]string{}}
+	for phase, name := range telemetry.DefaultPhaseMetrics {
+		c.Metrics[phase] = name
+	}
+	if *metrics != "" {
+		for _, kv := range strings.Split(*metrics, ",") {
+			phase, name, ok := strings.Cut(kv, "=")
+			if _, known := telemetry.DefaultPhaseMetrics[telemetry.Phase(phase)]; !ok || !known {
+				return cli.Usagef("invalid metric override %q", kv)
+			}
+			c.Metrics[telemetry.Phase(phase)] = name
+		}
+	}
+	if *energy != "" {
+		meter, err := telemetry.OpenRAPL(*powercap)
+		if err != nil {
+			return err
+		}
+		c.Energy = meter
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+	samples := c.Run(ctx, *interval, func(err error) {
+		fmt.Fprintln(os.Stderr, "qlbench phases:", err)
+	})
+	cli.Print(map[string]int{"samples": len(samples), "targets": len(targets)}, func() {
+		fmt.Printf("collected %d samples from %d targets\n", len(samples), len(targets))
+	})
+
+	if *energy != "" {
+		if err := writeEnergy(*energy, *run, c.EnergySamples()); err != nil {
+			return err
+		}
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := telemetry.WriteSamplesCSV(f, *run, samples); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if *join == "" {
+		return nil
+	}
+	return joinPhases(*join, *joined, map[int][]telemetry.Sample{*run: samples})
+}
+
+func writeEnergy(path string, run int, samples []telemetry.EnergySample) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := telemetry.WriteEnergyCSV(f, run, samples); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// joinPhases rewrites the dataset at path, or writes it to out, with the
+// phase duration columns
+func joinPhases(path, out string, runs map[int][]telemetry.Sample) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if out == "" {
+		out = path
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".phases-*.csv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := telemetry.Join(tmp, src, runs); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), out)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/quantum-ledger/bench"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// PlatformFile is written next to result files so that datasets from
+// different machines carry the architecture they were measured on
+const PlatformFile = "PLATFORM.json"
+
+// runPlatform prints the platform description, or writes it to -out
+func runPlatform(args []string) error {
+	fs := cli.NewFlagSet("platform")
+	out := fs.String("out", "", "write the description to this file instead of stdout")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		p := bench.DetectPlatform()
+		var err error
+		cli.Print(p, func() { err = bench.WritePlatform(os.Stdout, p) })
+		return err
+	}
+	if err := writePlatform(*out); err != nil {
+		return err
+	}
+	cli.Print(map[string]string{"file": *out}, nil)
+	return nil
+}
+
+// recordPlatform writes PlatformFile into the results directory dir
+func recordPlatform(dir string) error {
+	return writePlatform(filepath.Join(dir, PlatformFile))
+}
+
+func writePlatform(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bench.WritePlatform(f, bench.DetectPlatform()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
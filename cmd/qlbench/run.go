@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/runner"
+)
+
+// runExperiment runs the workloads of an experiment file, in containers
+// with its resource limits or directly on the host
+func runExperiment(args []string) error {
+	fs := cli.NewFlagSet("run")
+	experiment := fs.String("experiment", "", "experiment YAML file")
+	mode := fs.String("mode", string(runner.ModeContainer), "container, applying the experiment's CPU and memory limits, or local")
+	docker := fs.String("docker", "docker", "container CLI, e.g. podman")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *experiment == "" {
+		return cli.Usagef("-experiment is required")
+	}
+	e, err := runner.LoadExperiment(*experiment)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	r := &runner.Runner{Mode: runner.Mode(*mode), Docker: *docker}
+	return r.Run(ctx, e)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/loadgen"
+)
+
+// traceJSON is the result of qlbench trace -output json
+type traceJSON struct {
+	Arrivals int    `json:"arrivals"`
+	File     string `json:"file"`
+}
+
+// runTrace extracts the workload committed in block files into a trace
+// that `qlbench load -trace` replays
+func runTrace(args []string) error {
+	fs := cli.NewFlagSet("trace")
+	out := fs.String("out", "trace.csv", "trace file to write")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return cli.Usagef("usage: qlbench trace [-out trace.csv] <block file>...")
+	}
+
+	var blocks []*fabproto.Block
+	for _, path := range fs.Args() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b, err := fabproto.UnmarshalBlock(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		blocks = append(blocks, b)
+	}
+	trace, err := loadgen.TraceFromBlocks(blocks)
+	if err != nil {
+		return err
+	}
+	if err := writeTrace(*out, trace); err != nil {
+		return err
+	}
+	res := traceJSON{Arrivals: len(trace), File: *out}
+	cli.Print(res, func() { fmt.Printf("wrote %d arrivals to %s\n", res.Arrivals, res.File) })
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/anchor"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+// runAnchor publishes the hash of the latest block to an external chain,
+// once or every -interval
+func runAnchor(args []string) error {
+	fs := flag.NewFlagSet("anchor", flag.ContinueOnError)
+	channel := fs.String("channel", "", "channel name recorded in the anchors")
+	blockFile := fs.String("block", "", "file holding the latest block, refreshed by `peer channel fetch newest`")
+	mspDir := fs.String("msp-dir", "", "local MSP folder of the anchoring identity")
+	mspID := fs.String("msp-id", "", "MSP ID of the anchoring identity")
+	rpcURL := fs.String("rpc", "http://127.0.0.1:8545", "Ethereum JSON-RPC endpoint of the public chain")
+	from := fs.String("from", "", "account sending the anchor transactions")
+	to := fs.String("to", "", "recipient of the anchor transactions (default -from)")
+	journal := fs.String("journal", "anchors.jsonl", "file receipts are appended to")
+	interval := fs.Duration("interval", 0, "anchor every interval until interrupted (default once)")
+	msps := mspFlag{}
+	fs.Var(msps, "orderer-msp", "verify blocks against the orderer MSP MSPID=dir before anchoring (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *channel == "" || *blockFile == "" || *mspDir == "" || *mspID == "" || *from == "" {
+		return errors.New("-channel, -block, -msp-dir, -msp-id and -from are required")
+	}
+	if *to == "" {
+		*to = *from
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	id, err := msp.LoadSigningIdentity(csp, *mspDir, *mspID)
+	if err != nil {
+		return err
+	}
+	a := &anchor.Anchorer{
+		Channel: *channel,
+		Source:  anchor.FileSource(*blockFile),
+		Signer:  id,
+		Chain:   &anchor.EthereumRPC{URL: *rpcURL, From: *from, To: *to},
+		Journal: anchor.NewJournal(*journal),
+		OnError: func(err error) { fmt.Fprintf(os.Stderr, "qlblock anchor: %v\n", err) },
+	}
+	if len(msps) > 0 {
+		sigs, err := orderer.BlockValidationConfig{OrdererMSPs: msps}.NewVerifier(csp)
+		if err != nil {
+			return err
+		}
+		a.Verifier = blockverify.NewVerifier(sigs)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *interval <= 0 {
+		r, err := a.AnchorLatest(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("block %d: anchored in %s\n", r.Anchor.Number, r.TxID)
+		return nil
+	}
+	if err := a.Run(ctx, *interval); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// runAuditAnchors checks every receipt of a journal against the chain
+func runAuditAnchors(args []string) error {
+	fs := flag.NewFlagSet("audit-anchors", flag.ContinueOnError)
+	journal := fs.String("journal", "anchors.jsonl", "receipts written by qlblock anchor")
+	rpcURL := fs.String("rpc", "http://127.0.0.1:8545", "Ethereum JSON-RPC endpoint of the public chain")
+	msps := mspFlag{}
+	fs.Var(msps, "msp", "MSP trusted to sign anchors as MSPID=dir (repeatable)")
+	timeout := fs.Duration("timeout", time.Minute, "timeout of the whole audit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(msps) == 0 {
+		return errors.New("at least one -msp is required")
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	cas := map[string][]*hybridx509.Certificate{}
+	for mspID, dir := range msps {
+		if cas[mspID], err = msp.LoadCACertificates(dir); err != nil {
+			return fmt.Errorf("MSP %s: %w", mspID, err)
+		}
+	}
+	receipts, err := anchor.ReadJournal(*journal)
+	if err != nil {
+		return err
+	}
+	// Block files given as arguments are compared with the anchored hashes
+	hashes := map[uint64][]byte{}
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		b, err := fabproto.UnmarshalBlock(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if b.Header == nil {
+			return fmt.Errorf("%s: block has no header", file)
+		}
+		hashes[b.Header.Number] = blockverify.HeaderHash(b.Header)
+	}
+	auditor := &anchor.Auditor{
+		Chain:        &anchor.EthereumRPC{URL: *rpcURL},
+		Deserializer: &msp.Deserializer{CSP: csp, CAs: cas},
+		BlockHash:    func(n uint64) ([]byte, error) { return hashes[n], nil },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	var failed []string
+	for _, r := range receipts {
+		id, err := auditor.Audit(ctx, r)
+		if err != nil {
+			fmt.Printf("%s: FAILED: %v\n", r.TxID, err)
+			failed = append(failed, r.TxID)
+			continue
+		}
+		checked := ""
+		if hashes[r.Anchor.Number] != nil {
+			checked = ", matches local block"
+		}
+		fmt.Printf("%s: OK, block %d of %s, signed by %s/%s%s\n", r.TxID, r.Anchor.Number, r.Anchor.Channel,
+			id.MSPID, id.Certificate.Subject.CommonName, checked)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d anchors failed: %s", len(failed), len(receipts), strings.Join(failed, ", "))
+	}
+	return nil
+}
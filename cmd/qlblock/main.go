@@ -0,0 +1,57 @@
+// qlblock independently verifies hybrid orderer signatures on Fabric blocks
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: qlblock <command> [flags]
+
+commands:
+  verify          check data hash, orderer signatures and chaining of block files
+  anchor          publish the hybrid-signed hash of the latest block to a public chain
+  audit-anchors   check published anchors against their receipts and local blocks
+  prove           write the inclusion proof of a transaction
+  verify-proof    check an inclusion proof offline
+
+Typical flow:
+  peer channel fetch 5 block5.pb -c mychannel
+  qlblock verify -orderer-msp OrdererMSP=ordererOrg/msp block5.pb block6.pb
+  qlblock anchor -channel mychannel -block newest.pb -msp-dir orderer/msp -msp-id OrdererMSP -from 0x... -interval 1h
+  qlblock audit-anchors -msp OrdererMSP=ordererOrg/msp -journal anchors.jsonl block5.pb
+  qlblock prove -channel mychannel -tx <txid> -msp-dir orderer/msp -msp-id OrdererMSP -out proof.json block5.pb
+  qlblock verify-proof -trust orderer.json proof.json
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "anchor":
+		err = runAnchor(os.Args[2:])
+	case "audit-anchors":
+		err = runAuditAnchors(os.Args[2:])
+	case "prove":
+		err = runProve(os.Args[2:])
+	case "verify-proof":
+		err = runVerifyProof(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlblock: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlblock %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/inclusion"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+// runProve writes the inclusion proof of a transaction found in the block
+// files given as arguments
+func runProve(args []string) error {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	channel := fs.String("channel", "", "channel of the blocks")
+	txID := fs.String("tx", "", "transaction ID to prove")
+	mspDir := fs.String("msp-dir", "", "local MSP folder of the identity signing block headers")
+	mspID := fs.String("msp-id", "", "MSP ID of the signing identity")
+	out := fs.String("out", "", "proof file (default stdout)")
+	msps := mspFlag{}
+	fs.Var(msps, "orderer-msp", "verify blocks against the orderer MSP MSPID=dir before proving (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *channel == "" || *txID == "" || *mspDir == "" || *mspID == "" || fs.NArg() == 0 {
+		return errors.New("-channel, -tx, -msp-dir, -msp-id and at least one block file are required")
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	id, err := msp.LoadSigningIdentity(csp, *mspDir, *mspID)
+	if err != nil {
+		return err
+	}
+	signer, err := id.Signer()
+	if err != nil {
+		return err
+	}
+	var verifier *blockverify.Verifier
+	if len(msps) > 0 {
+		sigs, err := orderer.BlockValidationConfig{OrdererMSPs: msps}.NewVerifier(csp)
+		if err != nil {
+			return err
+		}
+		verifier = blockverify.NewVerifier(sigs)
+	}
+
+	prover, err := inclusion.NewProver(*channel, signer, verifier)
+	if err != nil {
+		return err
+	}
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		b, err := fabproto.UnmarshalBlock(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if _, err := prover.AddBlock(b); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if proof, err := prover.Prove(*txID); err == nil {
+			return writeProof(proof, *out)
+		}
+	}
+	return fmt.Errorf("%s: %w", *txID, inclusion.ErrTxNotFound)
+}
+
+func writeProof(proof *inclusion.Proof, out string) error {
+	raw, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	if out == "" {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	return os.WriteFile(out, raw, 0o644)
+}
+
+// trustFlag collects repeated key files
+type trustFlag []string
+
+func (t *trustFlag) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *trustFlag) Set(v string) error {
+	*t = append(*t, v)
+	return nil
+}
+
+// runVerifyProof checks an inclusion proof offline
+func runVerifyProof(args []string) error {
+	fs := flag.NewFlagSet("verify-proof", flag.ContinueOnError)
+	var trust trustFlag
+	fs.Var(&trust, "trust", "certificate (PEM) or public key JSON (qlsig inspect) of a trusted header signer (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected one proof file")
+	}
+
+	var trusted [][]byte
+	for _, file := range trust {
+		key, err := cliutil.ReadTrustedKey(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		trusted = append(trusted, key)
+	}
+	raw, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	proof, err := inclusion.ParseProof(raw)
+	if err != nil {
+		return err
+	}
+	if err := proof.Verify(trusted...); err != nil {
+		return err
+	}
+	fmt.Printf("transaction %s: OK, in block %d of %s, signed by key %x\n",
+		proof.TxID, proof.Header.Number, proof.Header.Channel, sha256.Sum256(proof.Header.PublicKey))
+	if len(trusted) == 0 {
+		fmt.Println("warning: no -trust given, the signer key was not checked")
+	}
+	return nil
+}
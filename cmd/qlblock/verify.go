@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+// mspFlag collects repeated MSPID=dir values
+type mspFlag map[string]string
+
+func (m mspFlag) String() string {
+	var parts []string
+	for id, dir := range m {
+		parts = append(parts, id+"="+dir)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m mspFlag) Set(v string) error {
+	id, dir, ok := strings.Cut(v, "=")
+	if !ok || id == "" || dir == "" {
+		return fmt.Errorf("expected MSPID=dir, got %q", v)
+	}
+	m[id] = dir
+	return nil
+}
+
+// runVerify verifies the block files given as arguments, in order. Each
+// file after the first must contain the next block.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	msps := mspFlag{}
+	fs.Var(msps, "orderer-msp", "trusted orderer MSP as MSPID=dir (repeatable)")
+	threshold := fs.Int("threshold", 1, "distinct orderer signatures required per block")
+	verifyTimeout := fs.Duration("verify-timeout", 0, "fail a signature whose PQC verification takes longer, 0 for no limit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(msps) == 0 || fs.NArg() == 0 {
+		return errors.New("-orderer-msp and at least one block file are required")
+	}
+
+	csp, err := hybrid.New(hybrid.WithVerifyTimeout(*verifyTimeout))
+	if err != nil {
+		return err
+	}
+	sigs, err := orderer.BlockValidationConfig{OrdererMSPs: msps, Threshold: *threshold}.NewVerifier(csp)
+	if err != nil {
+		return err
+	}
+	chain := blockverify.NewChain(blockverify.NewVerifier(sigs), nil)
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		res, err := chain.Append(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		var signers []string
+		for _, id := range res.Signers {
+			signers = append(signers, id.MSPID+"/"+id.Certificate.Subject.CommonName)
+		}
+		fmt.Printf("block %d: OK, hash %x, signed by %s\n", res.Number, res.Hash, strings.Join(signers, ", "))
+	}
+	return nil
+}
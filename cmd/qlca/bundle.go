@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// readPassword reads a bundle password from the first line of file
+func readPassword(file string) ([]byte, error) {
+	if file == "" {
+		return nil, errors.New("-password-file is required")
+	}
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	password, _, _ := bytes.Cut(raw, []byte("\n"))
+	password = bytes.TrimSuffix(password, []byte("\r"))
+	if len(password) == 0 {
+		return nil, fmt.Errorf("%s: empty password", file)
+	}
+	return password, nil
+}
+
+// runExport writes the signing identity of an MSP folder and its chain to a
+// password-protected bundle
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	mspDir := fs.String("msp", "", "MSP folder of the identity")
+	out := fs.String("out", "identity.pem", "output bundle")
+	passwordFile := fs.String("password-file", "", "file holding the bundle password")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *mspDir == "" {
+		return errors.New("-msp is required")
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	id, chain, err := ca.ReadMSP(*mspDir)
+	if err != nil {
+		return err
+	}
+	bundle, err := ca.ExportIdentity(id, chain, password)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, bundle, 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("exported %s and %d chain certificates to %s\n", id.Cert.Subject.CommonName, len(chain), *out)
+	return nil
+}
+
+// runImport writes the MSP folder of an identity bundle
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := fs.String("in", "identity.pem", "bundle written by qlca export")
+	mspDir := fs.String("msp", "", "MSP folder to write")
+	passwordFile := fs.String("password-file", "", "file holding the bundle password")
+	nodeOUs := fs.Bool("node-ous", true, "write config.yaml enabling node OUs")
+	safeguard := cli.NewSafeguard(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *mspDir == "" {
+		return errors.New("-msp is required")
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	id, chain, err := ca.ImportIdentity(raw, password)
+	if err != nil {
+		return cli.RejectedIf(err, ca.ErrBundlePassword)
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("%s: the bundle has no CA certificate", *in)
+	}
+
+	_, statErr := os.Stat(filepath.Join(*mspDir, "keystore", ca.ECDSAKeyFile))
+	exists := statErr == nil
+	format := "import %s into %s"
+	if exists {
+		format += ", replacing its keys"
+	}
+	if safeguard.DryRunf(format, id.Cert.Subject.CommonName, *mspDir) {
+		return nil
+	}
+	if exists {
+		if err := safeguard.Confirm("overwrite the keystore of %s", *mspDir); err != nil {
+			return err
+		}
+	}
+	// WriteMSP takes the issuer as a CA: only its certificates are used
+	issuer := &ca.CA{Identity: ca.Identity{Cert: chain[0]}, Chain: chain[1:]}
+	if err := ca.WriteMSP(*mspDir, issuer, id, ca.MSPOptions{NodeOUs: *nodeOUs}); err != nil {
+		return err
+	}
+	fmt.Printf("imported %s into %s\n", id.Cert.Subject.CommonName, *mspDir)
+	return nil
+}
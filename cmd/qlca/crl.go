@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// runRevoke adds a serial number to the CA revocation list
+func runRevoke(args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ContinueOnError)
+	caDir := fs.String("ca", "ca", "CA directory")
+	serial := fs.String("serial", "", "hex serial number of the certificate")
+	safeguard := cli.NewSafeguard(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	n, ok := new(big.Int).SetString(*serial, 16)
+	if !ok {
+		return errors.New("-serial must be a hex serial number")
+	}
+
+	c, err := ca.Load(*caDir)
+	if err != nil {
+		return err
+	}
+	if safeguard.DryRunf("revoke %s in %s", *serial, *caDir) {
+		return nil
+	}
+	if err := safeguard.Confirm("revoke certificate %s, this cannot be undone", *serial); err != nil {
+		return err
+	}
+	c.Revoke(n, time.Now())
+	if err := c.Save(*caDir); err != nil {
+		return err
+	}
+	fmt.Printf("revoked %s (%d revoked certificates)\n", *serial, len(c.Revoked()))
+	return nil
+}
+
+// runCRL issues a PEM CRL
+func runCRL(args []string) error {
+	fs := flag.NewFlagSet("crl", flag.ContinueOnError)
+	caDir := fs.String("ca", "ca", "CA directory")
+	out := fs.String("out", "crl.pem", "output file")
+	days := fs.Int("days", 7, "days until the next update")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := ca.Load(*caDir)
+	if err != nil {
+		return err
+	}
+	der, err := c.CRL(time.Now().Add(time.Duration(*days) * 24 * time.Hour))
+	if err != nil {
+		return err
+	}
+	if err := c.Save(*caDir); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0o644)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// subjectFlags registers the subject fields shared by init and intermediate
+type subjectFlags struct {
+	cn, org, country, province, locality *string
+}
+
+func addSubjectFlags(fs *flag.FlagSet) subjectFlags {
+	return subjectFlags{
+		cn:       fs.String("cn", "", "common name"),
+		org:      fs.String("org", "", "organization"),
+		country:  fs.String("country", "US", "country"),
+		province: fs.String("province", "California", "province"),
+		locality: fs.String("locality", "San Francisco", "locality"),
+	}
+}
+
+func (s subjectFlags) name() (pkix.Name, error) {
+	if *s.cn == "" || *s.org == "" {
+		return pkix.Name{}, errors.New("-cn and -org are required")
+	}
+	return pkix.Name{
+		Country:      []string{*s.country},
+		Province:     []string{*s.province},
+		Locality:     []string{*s.locality},
+		Organization: []string{*s.org},
+		CommonName:   *s.cn,
+	}, nil
+}
+
+// confirmSave asks before a CA is written over the keys of another one
+func confirmSave(s *cli.Safeguard, dir, format string, args ...interface{}) error {
+	exists := ca.Exists(dir)
+	if exists {
+		format += ", replacing the existing CA"
+	}
+	if s.DryRunf(format, args...) || !exists {
+		return nil
+	}
+	return s.Confirm("overwrite the CA in %s, destroying its private keys", dir)
+}
+
+// runInit creates a root CA directory
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	dir := fs.String("dir", "ca", "CA directory to create")
+	days := fs.Int("days", 3650, "validity in days")
+	subject := addSubjectFlags(fs)
+	safeguard := cli.NewSafeguard(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name, err := subject.name()
+	if err != nil {
+		return err
+	}
+	if err := confirmSave(safeguard, *dir, "create root CA %s in %s", name.CommonName, *dir); err != nil || safeguard.DryRun {
+		return err
+	}
+
+	root, err := ca.NewRoot(name, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := root.Save(*dir); err != nil {
+		return err
+	}
+	fmt.Printf("created root CA %s in %s\n", name.CommonName, *dir)
+	return nil
+}
+
+// runIntermediate creates an intermediate CA directory
+func runIntermediate(args []string) error {
+	fs := flag.NewFlagSet("intermediate", flag.ContinueOnError)
+	caDir := fs.String("ca", "ca", "issuing CA directory")
+	dir := fs.String("dir", "", "intermediate CA directory to create")
+	days := fs.Int("days", 1825, "validity in days")
+	subject := addSubjectFlags(fs)
+	safeguard := cli.NewSafeguard(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("-dir is required")
+	}
+	name, err := subject.name()
+	if err != nil {
+		return err
+	}
+	if err := confirmSave(safeguard, *dir, "create intermediate CA %s in %s", name.CommonName, *dir); err != nil || safeguard.DryRun {
+		return err
+	}
+
+	issuer, err := ca.Load(*caDir)
+	if err != nil {
+		return err
+	}
+	inter, err := issuer.NewIntermediate(name, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := inter.Save(*dir); err != nil {
+		return err
+	}
+	fmt.Printf("created intermediate CA %s in %s\n", name.CommonName, *dir)
+	return nil
+}
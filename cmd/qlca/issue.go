@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/ctlog"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+)
+
+// runIssue issues a leaf certificate and writes its MSP folder
+func runIssue(args []string) error {
+	fs := flag.NewFlagSet("issue", flag.ContinueOnError)
+	caDir := fs.String("ca", "ca", "issuing CA directory")
+	cn := fs.String("cn", "", "common name")
+	ou := fs.String("ou", "", "node OU: peer, orderer, client or admin")
+	san := fs.String("san", "", "comma separated DNS subject alternative names")
+	days := fs.Int("days", 365, "validity in days")
+	tls := fs.Bool("tls", false, "issue a TLS certificate (server and client auth)")
+	out := fs.String("msp", "", "MSP directory to write")
+	nodeOUs := fs.Bool("node-ous", true, "write config.yaml enabling node OUs")
+	crlURL := fs.String("crl-url", "", "comma separated CRL distribution point URLs of the issuing CA")
+	ocspURL := fs.String("ocsp-url", "", "comma separated OCSP responder URLs of the issuing CA")
+	logURL := fs.String("ct-log", "", "submit the certificate to this transparency log before writing the MSP")
+	logKeyFile := fs.String("ct-log-key", "", "certificate (PEM) or public key JSON (qlsig inspect) of the transparency log")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *cn == "" || *out == "" {
+		return errors.New("-cn and -msp are required")
+	}
+	if (*logURL == "") != (*logKeyFile == "") {
+		return errors.New("-ct-log and -ct-log-key go together")
+	}
+
+	issuer, err := ca.Load(*caDir)
+	if err != nil {
+		return err
+	}
+	req := ca.Request{
+		CommonName:         *cn,
+		OrganizationalUnit: *ou,
+		Validity:           time.Duration(*days) * 24 * time.Hour,
+		TLS:                *tls,
+	}
+	if *san != "" {
+		req.DNSNames = strings.Split(*san, ",")
+	}
+	if *crlURL != "" {
+		req.CRLDistributionPoints = strings.Split(*crlURL, ",")
+	}
+	if *ocspURL != "" {
+		req.OCSPServer = strings.Split(*ocspURL, ",")
+	}
+	id, err := issuer.Issue(req)
+	if err != nil {
+		return err
+	}
+	if *logURL != "" {
+		if err := submit(*logURL, *logKeyFile, id.Cert.Raw); err != nil {
+			return fmt.Errorf("transparency log: %w", err)
+		}
+	}
+
+	opts := ca.MSPOptions{NodeOUs: *nodeOUs}
+	if len(issuer.Revoked()) > 0 {
+		if opts.CRL, err = issuer.CRL(time.Now().Add(7 * 24 * time.Hour)); err != nil {
+			return err
+		}
+		// CRL issuance bumps the CRL number
+		if err := issuer.Save(*caDir); err != nil {
+			return err
+		}
+	}
+	if err := ca.WriteMSP(*out, issuer, id, opts); err != nil {
+		return err
+	}
+	fmt.Printf("issued %s (serial %s) into %s\n", *cn, id.Cert.SerialNumber.Text(16), *out)
+	return nil
+}
+
+// submit logs der and checks the receipt against the log key
+func submit(logURL, keyFile string, der []byte) error {
+	key, err := cliutil.ReadTrustedKey(keyFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyFile, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	receipt, err := (&ctlog.Client{URL: logURL}).Add(ctx, der)
+	if err != nil {
+		return err
+	}
+	if err := receipt.Verify(key); err != nil {
+		return err
+	}
+	fmt.Printf("logged as entry %d of %s\n", receipt.Entry.Index, logURL)
+	return nil
+}
@@ -0,0 +1,70 @@
+// qlca is a hybrid certificate authority for lab networks
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+const usage = `usage: qlca <command> [flags]
+
+commands:
+  init           create a self-signed hybrid root CA
+  intermediate   create an intermediate CA signed by a CA
+  issue          issue a leaf certificate and write its MSP folder
+  revoke         add a certificate serial to the revocation list
+  crl            issue a CRL
+  ocsp           serve hybrid-signed OCSP responses
+  export         write an MSP identity and its chain to a password-protected bundle
+  import         write the MSP folder of an exported identity bundle
+  completion     print the bash or zsh completion script
+
+init, intermediate, revoke and import ask for confirmation before
+overwriting a CA or keys or revoking, unless given -yes, and accept -dry-run.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "init":
+		err = runInit(os.Args[2:])
+	case "intermediate":
+		err = runIntermediate(os.Args[2:])
+	case "issue":
+		err = runIssue(os.Args[2:])
+	case "revoke":
+		err = runRevoke(os.Args[2:])
+	case "crl":
+		err = runCRL(os.Args[2:])
+	case "ocsp":
+		err = runOCSP(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	case "completion":
+		if len(os.Args) != 3 {
+			err = cli.Usagef("expected one shell, bash or zsh")
+			break
+		}
+		err = cli.WriteCompletion(os.Stdout, os.Args[2], "qlca", []string{"init", "intermediate", "issue", "revoke", "crl", "ocsp", "export", "import"})
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlca: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlca %s: %v\n", os.Args[1], err)
+		os.Exit(cli.ExitCode(err))
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/ca"
+)
+
+// runOCSP serves hybrid-signed OCSP responses for the certificates of a CA
+func runOCSP(args []string) error {
+	fs := flag.NewFlagSet("ocsp", flag.ContinueOnError)
+	caDir := fs.String("ca", "ca", "CA directory")
+	listen := fs.String("listen", "127.0.0.1:8889", "listen address")
+	validity := fs.Duration("validity", ca.DefaultOCSPValidity, "validity of the responses")
+	reload := fs.Duration("reload", time.Minute, "interval between reloads of the CA revocation list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := ca.Load(*caDir)
+	if err != nil {
+		return err
+	}
+	responder := ca.NewOCSPResponder(c, *validity)
+	go func() {
+		for range time.Tick(*reload) {
+			c, err := ca.Load(*caDir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "qlca ocsp: reload: %v\n", err)
+				continue
+			}
+			responder.Update(c)
+		}
+	}()
+
+	srv := &http.Server{Addr: *listen, Handler: responder.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	fmt.Printf("serving OCSP for %s on %s\n", c.Cert.Subject.CommonName, *listen)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,56 @@
+// qlceremony runs a key ceremony on an offline machine: it generates the
+// consortium root CA key, splits it among the members and signs CA
+// certificates once enough of them bring their shares back
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: qlceremony <command> [flags]
+
+commands:
+  split     generate the root key and write one key share per member (offline machine)
+  request   write the request of the root certificate or of an intermediate CA
+  sign      rebuild the root key from the shares present and sign a request (offline machine)
+  install   complete an intermediate CA directory with its signed certificate
+
+Typical flow:
+  qlceremony split -members 3 -threshold 2 -out shares
+  qlceremony request -cn root.consortium.example.com -org Consortium -out root-request.json
+  qlceremony sign -share shares/member1.json -share shares/member2.json -request root-request.json -out root.pem
+  qlceremony request -cn ca.org1.example.com -org Org1 -ca org1-ica -out org1-request.json
+  qlceremony sign -share member2.json -share member3.json -request org1-request.json -root root.pem -out org1-ica.pem
+  qlceremony install -ca org1-ica -cert org1-ica.pem -root root.pem
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "split":
+		err = runSplit(os.Args[2:])
+	case "request":
+		err = runRequest(os.Args[2:])
+	case "sign":
+		err = runSign(os.Args[2:])
+	case "install":
+		err = runInstall(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlceremony: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlceremony %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/ceremony"
+)
+
+// Key files of a pending intermediate CA directory, as ca.Save names them
+const (
+	keyFile    = "ca-key.pem"
+	pqcKeyFile = "ca-pqc-key.pem"
+)
+
+// runRequest writes a certificate request; with -ca it also creates the key
+// of an intermediate CA
+func runRequest(args []string) error {
+	fs := flag.NewFlagSet("request", flag.ContinueOnError)
+	cn := fs.String("cn", "", "common name")
+	org := fs.String("org", "", "organization")
+	country := fs.String("country", "US", "country")
+	days := fs.Int("days", 0, "validity in days, 3650 for the root and 1825 for an intermediate if zero")
+	caDir := fs.String("ca", "", "intermediate CA directory to create, the root is requested if empty")
+	out := fs.String("out", "", "request file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *cn == "" || *org == "" || *out == "" {
+		return errors.New("-cn, -org and -out are required")
+	}
+	subject := pkix.Name{Country: []string{*country}, Organization: []string{*org}, CommonName: *cn}
+
+	validity, pub := 3650, (*hybridx509.PublicKey)(nil)
+	if *caDir != "" {
+		validity = 1825
+		key, err := hybridx509.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if err := writeKey(*caDir, key); err != nil {
+			return err
+		}
+		pub = key.Public()
+	}
+	if *days > 0 {
+		validity = *days
+	}
+	req, err := ceremony.NewRequest(subject, time.Duration(validity)*24*time.Hour, pub)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, raw, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote request for %s, serial %s, to %s\n", subject, req.Serial.Text(16), *out)
+	return nil
+}
+
+func writeKey(dir string, key *hybridx509.PrivateKey) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, keyFile), ecdsaPEM, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, pqcKeyFile), pqcPEM, 0o600)
+}
+
+// runSign rebuilds the ceremony key from the shares of the members present
+// and signs a request
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	var sharePaths sharesFlag
+	fs.Var(&sharePaths, "share", "key share file written by split, one per member present (repeatable)")
+	reqPath := fs.String("request", "", "request file")
+	rootPath := fs.String("root", "", "root certificate (PEM), for an intermediate request")
+	out := fs.String("out", "", "certificate file (PEM) to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(sharePaths) == 0 || *reqPath == "" || *out == "" {
+		return errors.New("-share, -request and -out are required")
+	}
+	var shares []*ceremony.KeyShare
+	for _, path := range sharePaths {
+		share, err := readShare(path)
+		if err != nil {
+			return err
+		}
+		shares = append(shares, share)
+	}
+	raw, err := os.ReadFile(*reqPath)
+	if err != nil {
+		return err
+	}
+	var req ceremony.Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return fmt.Errorf("%s: %w", *reqPath, err)
+	}
+	var root *hybridx509.Certificate
+	if *rootPath != "" {
+		if root, err = readCertificate(*rootPath); err != nil {
+			return err
+		}
+	}
+	subject, err := req.SubjectName()
+	if err != nil {
+		return err
+	}
+
+	signer, err := ceremony.Combine(shares...)
+	if err != nil {
+		return err
+	}
+	defer signer.Clean()
+	fmt.Printf("signing %s, serial %s, valid until %s, with members %v\n", subject, req.Serial.Text(16), req.NotAfter.Format(time.DateOnly), signer.Members())
+	cert, err := signer.Issue(&req, root)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, hybridx509.EncodeCertificatePEM(cert.Raw), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", *out)
+	return nil
+}
+
+func readCertificate(path string) (*hybridx509.Certificate, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := hybridx509.ParseCertificatePEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cert, nil
+}
+
+// runInstall writes the CA directory of an intermediate signed by the
+// ceremony root
+func runInstall(args []string) error {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	dir := fs.String("ca", "", "intermediate CA directory created by request")
+	certPath := fs.String("cert", "", "intermediate certificate (PEM) written by sign")
+	rootPath := fs.String("root", "", "root certificate (PEM)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" || *certPath == "" || *rootPath == "" {
+		return errors.New("-ca, -cert and -root are required")
+	}
+	ecdsaPEM, err := os.ReadFile(filepath.Join(*dir, keyFile))
+	if err != nil {
+		return err
+	}
+	pqcPEM, err := os.ReadFile(filepath.Join(*dir, pqcKeyFile))
+	if err != nil {
+		return err
+	}
+	key, err := hybridx509.ParsePrivateKeyPEM(ecdsaPEM, pqcPEM)
+	if err != nil {
+		return err
+	}
+	cert, err := readCertificate(*certPath)
+	if err != nil {
+		return err
+	}
+	root, err := readCertificate(*rootPath)
+	if err != nil {
+		return err
+	}
+	if err := cert.CheckSignatureFrom(root); err != nil {
+		return fmt.Errorf("%s is not signed by the root: %w", *certPath, err)
+	}
+	if !key.ECDSA.PublicKey.Equal(cert.PublicKey) {
+		return fmt.Errorf("%s is not the certificate of the key in %s", *certPath, *dir)
+	}
+
+	inter := &ca.CA{Identity: ca.Identity{Cert: cert, Key: key}, Chain: []*hybridx509.Certificate{root}}
+	if err := inter.Save(*dir); err != nil {
+		return err
+	}
+	fmt.Printf("installed intermediate CA %s in %s\n", cert.Subject.CommonName, *dir)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/ceremony"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// sharesFlag collects repeated key share files
+type sharesFlag []string
+
+func (f *sharesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *sharesFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// runSplit generates the ceremony key and writes one share per member
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	members := fs.Int("members", 0, "number of members")
+	threshold := fs.Int("threshold", 0, "members needed to rebuild the key and co-sign")
+	out := fs.String("out", "", "directory to write member<i>.json key shares to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("-out is required")
+	}
+	params := ceremony.Params{Members: *members, Threshold: *threshold}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0o700); err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	defer clear(core.ECDSASecret(key))
+	shares, err := ceremony.Split(key, params)
+	if err != nil {
+		return err
+	}
+	for _, share := range shares {
+		raw, err := json.MarshalIndent(share, "", "  ")
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(*out, fmt.Sprintf("member%d.json", share.Index))
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(raw)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		clear(raw)
+		if err != nil {
+			return err
+		}
+	}
+	// Members record the fingerprint to check their share later
+	sum := sha256.Sum256(shares[0].ECDSAKey)
+	fmt.Printf("wrote %d key shares, %d needed, to %s\nceremony key fingerprint %x\n", *members, *threshold, *out, sum[:16])
+	return nil
+}
+
+func readShare(path string) (*ceremony.KeyShare, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var share ceremony.KeyShare
+	if err := json.Unmarshal(raw, &share); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &share, nil
+}
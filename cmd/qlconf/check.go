@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/runner"
+	"github.com/yourusername/quantum-ledger/signd"
+	"gopkg.in/yaml.v3"
+)
+
+// errInvalidConfig is returned when a configuration has problems
+var errInvalidConfig = cli.Rejected(errors.New("invalid configuration"))
+
+// checkJSON is the result of qlconf check -output json
+type checkJSON struct {
+	Backend   string       `json:"backend"`
+	Algorithm string       `json:"algorithm"`
+	Configs   []configJSON `json:"configs"`
+}
+
+// configJSON is the check of one file. Effective uses the YAML keys.
+type configJSON struct {
+	Kind      string                 `json:"kind"`
+	File      string                 `json:"file"`
+	OK        bool                   `json:"ok"`
+	Problems  []string               `json:"problems,omitempty"`
+	Effective map[string]interface{} `json:"effective,omitempty"`
+}
+
+// loaders strictly decode and validate a configuration file, returning the
+// configuration as it is applied, with defaults
+var loaders = map[string]func(path string, raw []byte) (interface{}, error){
+	"provider": func(path string, raw []byte) (interface{}, error) {
+		c := &hybrid.ProviderConfig{}
+		if err := hybrid.DecodeYAMLStrict(raw, c); err != nil {
+			return nil, err
+		}
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		return c.Effective(), nil
+	},
+	"acl": func(path string, raw []byte) (interface{}, error) {
+		if err := hybrid.DecodeYAMLStrict(raw, &signd.ACL{}); err != nil {
+			return nil, err
+		}
+		return signd.LoadACL(path)
+	},
+	"experiment": func(path string, raw []byte) (interface{}, error) {
+		if err := hybrid.DecodeYAMLStrict(raw, &runner.Experiment{}); err != nil {
+			return nil, err
+		}
+		return runner.LoadExperiment(path)
+	},
+}
+
+// runCheck validates the given configuration files: unknown keys, values
+// and algorithm names, resolved against the backend compiled into qlconf
+func runCheck(args []string) error {
+	fs := cli.NewFlagSet("check")
+	files := map[string]*string{
+		"provider":   fs.String("provider", "", "provider YAML file, as given to qlsignd serve -provider-config"),
+		"acl":        fs.String("acl", "", "qlsignd ACL YAML file"),
+		"experiment": fs.String("experiment", "", "qlbench experiment YAML file"),
+	}
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	result := checkJSON{Backend: core.PQCBackend, Algorithm: hybrid.PQCAlgorithm, Configs: []configJSON{}}
+	for _, kind := range []string{"provider", "acl", "experiment"} {
+		path := *files[kind]
+		if path == "" {
+			continue
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c := configJSON{Kind: kind, File: path, OK: true}
+		effective, err := loaders[kind](path, raw)
+		if err != nil {
+			c.OK = false
+			c.Problems = problems(err)
+		} else if c.Effective, err = yamlMap(effective); err != nil {
+			return err
+		}
+		result.Configs = append(result.Configs, c)
+	}
+	if len(result.Configs) == 0 {
+		return cli.Usagef("expected at least one of -provider, -acl or -experiment")
+	}
+
+	cli.Print(result, func() {
+		fmt.Printf("backend: %s (%s)\n", result.Backend, result.Algorithm)
+		for _, c := range result.Configs {
+			if !c.OK {
+				fmt.Printf("\n%s %s: invalid\n", c.Kind, c.File)
+				for _, p := range c.Problems {
+					fmt.Printf("  %s\n", p)
+				}
+				continue
+			}
+			fmt.Printf("\n%s %s: ok\n", c.Kind, c.File)
+			var out strings.Builder
+			enc := yaml.NewEncoder(&out)
+			enc.SetIndent(2)
+			enc.Encode(c.Effective)
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	})
+	for _, c := range result.Configs {
+		if !c.OK {
+			return errInvalidConfig
+		}
+	}
+	return nil
+}
+
+// problems splits the errors joined by Validate and the unknown keys
+// reported by the YAML decoder
+func problems(err error) []string {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Errors
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var list []string
+		for _, e := range joined.Unwrap() {
+			list = append(list, e.Error())
+		}
+		return list
+	}
+	return strings.Split(err.Error(), "\n")
+}
+
+// yamlMap converts v to the map of its YAML document, so that the JSON
+// output has the keys of the files
+func yamlMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, yaml.Unmarshal(raw, &m)
+}
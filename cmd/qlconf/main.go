@@ -0,0 +1,20 @@
+// qlconf is the command line tool for the YAML configurations of the
+// provider, the signing daemon and the benchmark runner
+package main
+
+import "github.com/yourusername/quantum-ledger/internal/cli"
+
+const usage = `usage: qlconf <command> [flags]
+
+commands:
+  check   validate configuration files and print the effective configuration
+
+Every command accepts -output json. Exit codes: 0 success, 1 failure,
+2 usage error, 3 check failed (an invalid configuration).
+`
+
+func main() {
+	cli.Main("qlconf", usage, map[string]func([]string) error{
+		"check": runCheck,
+	})
+}
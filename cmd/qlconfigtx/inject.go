@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/configtx"
+)
+
+// runInject writes a copy of the config with the hybrid capabilities set
+func runInject(args []string) error {
+	fs := flag.NewFlagSet("inject", flag.ContinueOnError)
+	in := fs.String("in", "", "configtxlator JSON config")
+	out := fs.String("out", "", "output file")
+	group := fs.String("group", string(configtx.GroupApplication), "capabilities group: Channel, Application or Orderer")
+	policy := fs.String("policy", "AND", "enforced policy: AND, OR, CLASSICAL or PQC")
+	disable := fs.Bool("disable", false, "remove the hybrid capabilities instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		return errors.New("-in and -out are required")
+	}
+
+	settings := configtx.Settings{Enabled: !*disable}
+	if settings.Enabled {
+		p, err := hybrid.ParsePolicy(*policy)
+		if err != nil {
+			return err
+		}
+		settings.Policy = p
+	}
+
+	raw, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	modified, err := configtx.InjectJSON(raw, configtx.Group(*group), settings)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, modified, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("%s capabilities: %v\n", *group, settings.Capabilities())
+	return nil
+}
+
+// runShow prints the hybrid settings of a config
+func runShow(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	in := fs.String("in", "", "configtxlator JSON config")
+	group := fs.String("group", string(configtx.GroupApplication), "capabilities group: Channel, Application or Orderer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("-in is required")
+	}
+
+	raw, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	s, err := configtx.ReadJSON(raw, configtx.Group(*group))
+	if err != nil {
+		return err
+	}
+	if !s.Enabled {
+		fmt.Println("hybrid enforcement: disabled")
+		return nil
+	}
+	fmt.Printf("hybrid enforcement: enabled, policy %s\n", s.Policy)
+	return nil
+}
@@ -0,0 +1,45 @@
+// qlconfigtx edits the hybrid enforcement capabilities of Fabric channel configs
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: qlconfigtx <command> [flags]
+
+commands:
+  inject   set the hybrid enforcement level in a configtxlator JSON config
+  show     print the hybrid enforcement level of a configtxlator JSON config
+
+Typical flow:
+  configtxlator proto_decode --type common.Config --input config.pb --output config.json
+  qlconfigtx inject -in config.json -out modified.json -policy AND
+  configtxlator proto_encode / compute_update as for any config update
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "inject":
+		err = runInject(os.Args[2:])
+	case "show":
+		err = runShow(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlconfigtx: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlconfigtx %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
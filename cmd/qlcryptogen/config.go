@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The configuration mirrors cryptogen's crypto-config.yaml so existing files
+// can be reused unchanged.
+
+type config struct {
+	OrdererOrgs []orgSpec `yaml:"OrdererOrgs"`
+	PeerOrgs    []orgSpec `yaml:"PeerOrgs"`
+}
+
+type orgSpec struct {
+	Name          string       `yaml:"Name"`
+	Domain        string       `yaml:"Domain"`
+	EnableNodeOUs bool         `yaml:"EnableNodeOUs"`
+	CA            nodeSpec     `yaml:"CA"`
+	Template      nodeTemplate `yaml:"Template"`
+	Specs         []nodeSpec   `yaml:"Specs"`
+	Users         usersSpec    `yaml:"Users"`
+}
+
+type nodeTemplate struct {
+	Count    int      `yaml:"Count"`
+	Start    int      `yaml:"Start"`
+	Hostname string   `yaml:"Hostname"`
+	SANS     []string `yaml:"SANS"`
+}
+
+type nodeSpec struct {
+	Hostname           string   `yaml:"Hostname"`
+	CommonName         string   `yaml:"CommonName"`
+	Country            string   `yaml:"Country"`
+	Province           string   `yaml:"Province"`
+	Locality           string   `yaml:"Locality"`
+	OrganizationalUnit string   `yaml:"OrganizationalUnit"`
+	StreetAddress      string   `yaml:"StreetAddress"`
+	PostalCode         string   `yaml:"PostalCode"`
+	SANS               []string `yaml:"SANS"`
+
+	isAdmin bool
+}
+
+type usersSpec struct {
+	Count int `yaml:"Count"`
+}
+
+const (
+	defaultHostnameTemplate   = "{{.Prefix}}{{.Index}}"
+	defaultCommonNameTemplate = "{{.Hostname}}.{{.Domain}}"
+)
+
+type hostnameData struct {
+	Prefix string
+	Index  int
+	Domain string
+}
+
+type specData struct {
+	Hostname   string
+	Domain     string
+	CommonName string
+}
+
+func loadConfig(path string) (*config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func parseConfig(raw []byte) (*config, error) {
+	var cfg config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func render(text string, data interface{}) (string, error) {
+	t, err := template.New("").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// expandNodes applies the template and renders common names and SANs, the
+// way cryptogen does
+func (o *orgSpec) expandNodes(prefix string) ([]nodeSpec, error) {
+	nodes := append([]nodeSpec(nil), o.Specs...)
+	hostTmpl := o.Template.Hostname
+	if hostTmpl == "" {
+		hostTmpl = defaultHostnameTemplate
+	}
+	for i := o.Template.Start; i < o.Template.Start+o.Template.Count; i++ {
+		host, err := render(hostTmpl, hostnameData{Prefix: prefix, Index: i, Domain: o.Domain})
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, nodeSpec{Hostname: host, SANS: o.Template.SANS})
+	}
+
+	for i := range nodes {
+		n := &nodes[i]
+		cnTmpl := n.CommonName
+		if cnTmpl == "" {
+			cnTmpl = defaultCommonNameTemplate
+		}
+		cn, err := render(cnTmpl, specData{Hostname: n.Hostname, Domain: o.Domain})
+		if err != nil {
+			return nil, err
+		}
+		n.CommonName = cn
+
+		sans := []string{cn, n.Hostname}
+		for _, san := range n.SANS {
+			s, err := render(san, specData{Hostname: n.Hostname, Domain: o.Domain, CommonName: cn})
+			if err != nil {
+				return nil, err
+			}
+			sans = append(sans, s)
+		}
+		n.SANS = dedup(sans)
+	}
+	return nodes, nil
+}
+
+func dedup(values []string) []string {
+	seen := map[string]bool{}
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+const defaultConfig = `# ---------------------------------------------------------------------------
+# "OrdererOrgs" - Definition of organizations managing orderer nodes
+# ---------------------------------------------------------------------------
+OrdererOrgs:
+  - Name: Orderer
+    Domain: example.com
+    EnableNodeOUs: true
+    Specs:
+      - Hostname: orderer
+        SANS:
+          - localhost
+# ---------------------------------------------------------------------------
+# "PeerOrgs" - Definition of organizations managing peer nodes
+# ---------------------------------------------------------------------------
+PeerOrgs:
+  - Name: Org1
+    Domain: org1.example.com
+    EnableNodeOUs: true
+    Template:
+      Count: 1
+      SANS:
+        - localhost
+    Users:
+      Count: 1
+  - Name: Org2
+    Domain: org2.example.com
+    EnableNodeOUs: true
+    Template:
+      Count: 1
+      SANS:
+        - localhost
+    Users:
+      Count: 1
+`
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// Node OUs used in issued certificates
+const (
+	ouPeer    = "peer"
+	ouOrderer = "orderer"
+	ouClient  = "client"
+	ouAdmin   = "admin"
+)
+
+// runGenerate writes the crypto material described by the config
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	configPath := fs.String("config", "", "crypto-config.yaml; the default template when empty")
+	output := fs.String("output", "crypto-config", "output directory")
+	safeguard := cli.NewSafeguard(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *config
+	var err error
+	if *configPath != "" {
+		cfg, err = loadConfig(*configPath)
+	} else {
+		cfg, err = parseConfig([]byte(defaultConfig))
+	}
+	if err != nil {
+		return err
+	}
+	// Existing organizations get new CAs and keys
+	entries, err := os.ReadDir(*output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if safeguard.DryRunf("generate %d peer and %d orderer organizations in %s", len(cfg.PeerOrgs), len(cfg.OrdererOrgs), *output) {
+		return nil
+	}
+	if len(entries) > 0 {
+		if err := safeguard.Confirm("regenerate the crypto material in %s, replacing its keys", *output); err != nil {
+			return err
+		}
+	}
+	return generate(cfg, *output, reportProgress)
+}
+
+// reportProgress prints key generation progress about every 10%
+func reportProgress(done, total int) {
+	if done == total || done%max(total/10, 1) == 0 {
+		fmt.Fprintf(os.Stderr, "generated %d/%d keys\n", done, total)
+	}
+}
+
+// keyPool hands out the keys generated up front for the identities
+type keyPool struct {
+	keys []*hybridx509.PrivateKey
+}
+
+func (p *keyPool) take() *hybridx509.PrivateKey {
+	k := p.keys[0]
+	p.keys = p.keys[1:]
+	return k
+}
+
+// identityCount is the number of node and user identities of org, each
+// with an enrollment and a TLS key
+func identityCount(org *orgSpec, prefix string) (int, error) {
+	nodes, err := org.expandNodes(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("org %s: %w", org.Name, err)
+	}
+	return len(nodes) + 1 + org.Users.Count, nil
+}
+
+func generate(cfg *config, output string, progress hybrid.BatchProgress) error {
+	// The keys of every identity are generated in parallel first: serially,
+	// ML-DSA key generation takes minutes for large networks
+	n := 0
+	for i := range cfg.PeerOrgs {
+		count, err := identityCount(&cfg.PeerOrgs[i], "peer")
+		if err != nil {
+			return err
+		}
+		n += 2 * count
+	}
+	for i := range cfg.OrdererOrgs {
+		count, err := identityCount(&cfg.OrdererOrgs[i], "orderer")
+		if err != nil {
+			return err
+		}
+		n += 2 * count
+	}
+	keys, err := hybridx509.GenerateKeys(n, progress)
+	if err != nil {
+		return err
+	}
+	pool := &keyPool{keys: keys}
+
+	for i := range cfg.PeerOrgs {
+		org := &cfg.PeerOrgs[i]
+		if err := generateOrg(org, filepath.Join(output, "peerOrganizations", org.Domain), "peer", ouPeer, "peers", pool); err != nil {
+			return fmt.Errorf("org %s: %w", org.Name, err)
+		}
+	}
+	for i := range cfg.OrdererOrgs {
+		org := &cfg.OrdererOrgs[i]
+		if err := generateOrg(org, filepath.Join(output, "ordererOrganizations", org.Domain), "orderer", ouOrderer, "orderers", pool); err != nil {
+			return fmt.Errorf("org %s: %w", org.Name, err)
+		}
+	}
+	return nil
+}
+
+func generateOrg(org *orgSpec, dir, prefix, nodeOU, nodesDir string, keys *keyPool) error {
+	nodes, err := org.expandNodes(prefix)
+	if err != nil {
+		return err
+	}
+
+	subject := func(cn string) pkix.Name {
+		name := pkix.Name{
+			Country:      []string{valueOr(org.CA.Country, "US")},
+			Province:     []string{valueOr(org.CA.Province, "California")},
+			Locality:     []string{valueOr(org.CA.Locality, "San Francisco")},
+			Organization: []string{org.Domain},
+			CommonName:   cn,
+		}
+		if org.CA.OrganizationalUnit != "" {
+			name.OrganizationalUnit = []string{org.CA.OrganizationalUnit}
+		}
+		if org.CA.StreetAddress != "" {
+			name.StreetAddress = []string{org.CA.StreetAddress}
+		}
+		if org.CA.PostalCode != "" {
+			name.PostalCode = []string{org.CA.PostalCode}
+		}
+		return name
+	}
+	signCA, err := ca.NewRoot(subject(valueOr(org.CA.Hostname, "ca")+"."+org.Domain), 0)
+	if err != nil {
+		return err
+	}
+	tlsCA, err := ca.NewRoot(subject("tls"+valueOr(org.CA.Hostname, "ca")+"."+org.Domain), 0)
+	if err != nil {
+		return err
+	}
+	if err := writeCA(filepath.Join(dir, "ca"), signCA); err != nil {
+		return err
+	}
+	if err := writeCA(filepath.Join(dir, "tlsca"), tlsCA); err != nil {
+		return err
+	}
+
+	// Users: Admin plus Count regular users
+	users := []nodeSpec{{CommonName: "Admin@" + org.Domain, isAdmin: true}}
+	for i := 1; i <= org.Users.Count; i++ {
+		users = append(users, nodeSpec{CommonName: fmt.Sprintf("User%d@%s", i, org.Domain)})
+	}
+	var admin *ca.Identity
+	for _, u := range users {
+		ou := ouClient
+		if u.isAdmin {
+			ou = ouAdmin
+		}
+		id, err := generateNode(filepath.Join(dir, "users", u.CommonName), signCA, tlsCA, u, ou, org, "client", nil, keys)
+		if err != nil {
+			return err
+		}
+		if u.isAdmin {
+			admin = id
+		}
+	}
+
+	// Without node OUs the admin certificate goes into admincerts
+	var admins []*hybridx509.Certificate
+	if !org.EnableNodeOUs {
+		admins = append(admins, admin.Cert)
+	}
+	for _, n := range nodes {
+		if _, err := generateNode(filepath.Join(dir, nodesDir, n.CommonName), signCA, tlsCA, n, nodeOU, org, "server", admins, keys); err != nil {
+			return err
+		}
+	}
+
+	return ca.WriteMSP(filepath.Join(dir, "msp"), signCA, nil, ca.MSPOptions{
+		Admins:  admins,
+		NodeOUs: org.EnableNodeOUs,
+	})
+}
+
+// generateNode issues the enrollment and TLS identities of a node or user.
+// The TLS files are named <tlsName>.crt/.key as cryptogen does, with the PQC
+// half in <tlsName>.pqc.key.
+func generateNode(dir string, signCA, tlsCA *ca.CA, n nodeSpec, ou string, org *orgSpec, tlsName string, admins []*hybridx509.Certificate, keys *keyPool) (*ca.Identity, error) {
+	req := ca.Request{CommonName: n.CommonName, DNSNames: n.SANS, Key: keys.take()}
+	if org.EnableNodeOUs {
+		req.OrganizationalUnit = ou
+	}
+	id, err := signCA.Issue(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ca.WriteMSP(filepath.Join(dir, "msp"), signCA, id, ca.MSPOptions{Admins: admins, NodeOUs: org.EnableNodeOUs}); err != nil {
+		return nil, err
+	}
+	// cryptogen replaces tlscacerts with the TLS CA
+	if err := os.RemoveAll(filepath.Join(dir, "msp", "tlscacerts")); err != nil {
+		return nil, err
+	}
+	if err := writeCert(filepath.Join(dir, "msp", "tlscacerts"), tlsCA.Cert.Subject.CommonName+"-cert.pem", tlsCA.Cert); err != nil {
+		return nil, err
+	}
+
+	tlsID, err := tlsCA.Issue(ca.Request{CommonName: n.CommonName, DNSNames: n.SANS, TLS: true, Key: keys.take()})
+	if err != nil {
+		return nil, err
+	}
+	tlsDir := filepath.Join(dir, "tls")
+	if err := writeCert(tlsDir, "ca.crt", tlsCA.Cert); err != nil {
+		return nil, err
+	}
+	if err := writeCert(tlsDir, tlsName+".crt", tlsID.Cert); err != nil {
+		return nil, err
+	}
+	if err := writeKeys(tlsDir, tlsName+".key", tlsName+".pqc.key", tlsID.Key); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// writeCA writes a CA the way cryptogen does: <cn>-cert.pem and priv_sk
+func writeCA(dir string, c *ca.CA) error {
+	if err := writeCert(dir, c.Cert.Subject.CommonName+"-cert.pem", c.Cert); err != nil {
+		return err
+	}
+	return ca.WriteKeystore(dir, &c.Identity)
+}
+
+func writeCert(dir, name string, cert *hybridx509.Certificate) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), hybridx509.EncodeCertificatePEM(cert.Raw), 0o644)
+}
+
+func writeKeys(dir, ecdsaName, pqcName string, key *hybridx509.PrivateKey) error {
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ecdsaName), ecdsaPEM, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, pqcName), pqcPEM, 0o600)
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+func TestGenerateDefaultConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(defaultConfig))
+	require.NoError(t, err)
+	out := t.TempDir()
+	var done, total int
+	require.NoError(t, generate(cfg, out, func(d, n int) { done, total = d, n }))
+	// Admin, User1 and peer0 of two orgs, orderer and Admin of one, twice
+	assert.Equal(t, 16, total)
+	assert.Equal(t, total, done)
+
+	org := filepath.Join(out, "peerOrganizations", "org1.example.com")
+	for _, f := range []string{
+		"ca/ca.org1.example.com-cert.pem",
+		"ca/priv_sk",
+		"tlsca/tlsca.org1.example.com-cert.pem",
+		"msp/config.yaml",
+		"msp/cacerts/ca.org1.example.com-cert.pem",
+		"peers/peer0.org1.example.com/msp/signcerts/peer0.org1.example.com-cert.pem",
+		"peers/peer0.org1.example.com/msp/keystore/priv_sk",
+		"peers/peer0.org1.example.com/msp/keystore/pqc_sk",
+		"peers/peer0.org1.example.com/msp/tlscacerts/tlsca.org1.example.com-cert.pem",
+		"peers/peer0.org1.example.com/tls/ca.crt",
+		"peers/peer0.org1.example.com/tls/server.crt",
+		"peers/peer0.org1.example.com/tls/server.key",
+		"users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem",
+		"users/User1@org1.example.com/tls/client.crt",
+	} {
+		_, err := os.Stat(filepath.Join(org, f))
+		assert.NoError(t, err, f)
+	}
+	_, err = os.Stat(filepath.Join(out, "ordererOrganizations", "example.com", "orderers", "orderer.example.com", "tls", "server.crt"))
+	assert.NoError(t, err)
+
+	raw, err := os.ReadFile(filepath.Join(org, "peers/peer0.org1.example.com/msp/signcerts/peer0.org1.example.com-cert.pem"))
+	require.NoError(t, err)
+	peer, err := hybridx509.ParseCertificatePEM(raw)
+	require.NoError(t, err)
+	assert.True(t, peer.IsComposite())
+	assert.Equal(t, []string{"peer"}, peer.Subject.OrganizationalUnit)
+	assert.ElementsMatch(t, []string{"peer0.org1.example.com", "peer0", "localhost"}, peer.DNSNames)
+
+	raw, err = os.ReadFile(filepath.Join(org, "ca/ca.org1.example.com-cert.pem"))
+	require.NoError(t, err)
+	caCert, err := hybridx509.ParseCertificatePEM(raw)
+	require.NoError(t, err)
+	require.NoError(t, peer.CheckSignatureFrom(caCert))
+}
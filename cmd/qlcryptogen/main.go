@@ -0,0 +1,52 @@
+// qlcryptogen is a cryptogen replacement producing hybrid crypto material
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+const usage = `usage: qlcryptogen <command> [flags]
+
+commands:
+  generate       generate key material from a crypto-config.yaml
+  showtemplate   print the default configuration template
+  completion     print the bash or zsh completion script
+
+generate asks for confirmation before writing into a non-empty output
+directory, unless given -yes, and accepts -dry-run.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = runGenerate(os.Args[2:])
+	case "showtemplate":
+		fmt.Print(defaultConfig)
+	case "completion":
+		if len(os.Args) != 3 {
+			err = cli.Usagef("expected one shell, bash or zsh")
+			break
+		}
+		err = cli.WriteCompletion(os.Stdout, os.Args[2], "qlcryptogen", []string{"generate", "showtemplate"})
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlcryptogen: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlcryptogen %s: %v\n", os.Args[1], err)
+		os.Exit(cli.ExitCode(err))
+	}
+}
@@ -0,0 +1,45 @@
+// qlctlog runs and monitors the transparency log of hybrid certificates
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: qlctlog <command> [flags]
+
+commands:
+  serve     run the log, appending submitted composite certificates
+  monitor   follow a log and report certificates not issued by the given CAs
+
+Typical flow:
+  qlctlog serve -db ctlog.jsonl -msp-dir ctlog/msp -msp-id LogMSP -issuer org1-ca.pem -issuer org2-ca.pem
+  qlca issue -ca ica -cn peer0.org1.example.com -msp peer0/msp -ct-log http://ctlog:8090 -ct-log-key ctlog/msp/signcerts/*.pem
+  qlctlog monitor -log http://ctlog:8090 -log-key ctlog/msp/signcerts/*.pem -org org1.example.com -issuer org1-ca.pem -interval 5m
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "serve":
+		err = runServe(os.Args[2:])
+	case "monitor":
+		err = runMonitor(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlctlog: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlctlog %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ctlog"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+)
+
+// errFindings is returned by a single poll that reported findings
+var errFindings = errors.New("mis-issued certificates found")
+
+// runMonitor polls a log and reports the certificates of an organization
+// that none of its CAs issued
+func runMonitor(args []string) error {
+	fs := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	logURL := fs.String("log", "", "log URL")
+	logKey := fs.String("log-key", "", "certificate (PEM) or public key JSON (qlsig inspect) of the log")
+	org := fs.String("org", "", "organization whose certificates are checked")
+	interval := fs.Duration("interval", 0, "poll period, a single poll if 0")
+	var issuers fileFlag
+	fs.Var(&issuers, "issuer", "CA certificate (PEM) allowed to issue for -org (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *logURL == "" || *logKey == "" || *org == "" || len(issuers) == 0 {
+		return errors.New("-log, -log-key, -org and -issuer are required")
+	}
+
+	key, err := cliutil.ReadTrustedKey(*logKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *logKey, err)
+	}
+	var cas []*hybridx509.Certificate
+	for _, file := range issuers {
+		c, err := readCertificate(file)
+		if err != nil {
+			return err
+		}
+		cas = append(cas, c)
+	}
+	m := &ctlog.Monitor{
+		Client: &ctlog.Client{URL: *logURL},
+		LogKey: key,
+		Check:  ctlog.IssuedBy(*org, cas...),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		findings, err := m.Poll(ctx)
+		if err != nil {
+			// A log that is not append-only cannot be trusted any more
+			if errors.Is(err, ctlog.ErrNotAppendOnly) || errors.Is(err, ctlog.ErrInvalidTreeHead) || *interval == 0 {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "poll failed: %v\n", err)
+		} else {
+			for _, f := range findings {
+				fmt.Printf("entry %d: %v\n", f.Entry.Index, f.Err)
+			}
+			fmt.Printf("%s: %d entries checked, root %x\n", time.Now().Format(time.RFC3339), m.TreeHead().Size, m.TreeHead().RootHash)
+			if *interval == 0 {
+				if len(findings) > 0 {
+					return errFindings
+				}
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
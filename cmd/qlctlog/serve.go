@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ctlog"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// fileFlag collects repeated file names
+type fileFlag []string
+
+func (f *fileFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// runServe serves the log API
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8090", "listen address")
+	db := fs.String("db", "", "entries file, appended as JSON lines")
+	mspDir := fs.String("msp-dir", "", "local MSP folder of the identity signing tree heads")
+	mspID := fs.String("msp-id", "", "MSP ID of the signing identity")
+	cert := fs.String("tls-cert", "", "server TLS certificate, plain HTTP if empty")
+	key := fs.String("tls-key", "", "server TLS private key")
+	var issuers fileFlag
+	fs.Var(&issuers, "issuer", "CA certificate (PEM) whose certificates are accepted, any if none (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *db == "" || *mspDir == "" || *mspID == "" {
+		return errors.New("-db, -msp-dir and -msp-id are required")
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	id, err := msp.LoadSigningIdentity(csp, *mspDir, *mspID)
+	if err != nil {
+		return err
+	}
+	signer, err := id.Signer()
+	if err != nil {
+		return err
+	}
+	var accepted []*hybridx509.Certificate
+	for _, file := range issuers {
+		c, err := readCertificate(file)
+		if err != nil {
+			return err
+		}
+		accepted = append(accepted, c)
+	}
+	l, err := ctlog.Open(*db, signer, accepted...)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	head, err := l.TreeHead()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("log of %d entries, root %x\n", head.Size, head.RootHash)
+
+	srv := &http.Server{Addr: *listen, Handler: l.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	fmt.Printf("serving on %s\n", *listen)
+	if *cert != "" {
+		return srv.ListenAndServeTLS(*cert, *key)
+	}
+	return srv.ListenAndServe()
+}
+
+func readCertificate(file string) (*hybridx509.Certificate, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	c, err := hybridx509.ParseCertificatePEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+)
+
+var errAuditFailed = cli.Rejected(errors.New("keystore audit found problems"))
+
+// auditJSON is one key of qlkeygen audit -output json
+type auditJSON struct {
+	Namespace string   `json:"namespace"`
+	SKI       string   `json:"ski"`
+	OK        bool     `json:"ok"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+// runAudit checks the integrity of every stored key: both halves present,
+// SKIs matching the ECDSA keys and key pairs that sign and verify
+func runAudit(args []string) error {
+	fs := cli.NewFlagSet("audit")
+	dir := fs.String("keystore", "", "keystore directory")
+	namespace := fs.String("namespace", "", "audit a single namespace, e.g. Org1MSP")
+	passphrase := fs.String("passphrase", "", "keystore passphrase source, for encrypted keys: keyring:<key>, systemd:<credential> or file:<path>")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return cli.Usagef("-keystore is required")
+	}
+
+	ks, _, err := cliutil.OpenKeyStore(*dir)
+	if err != nil {
+		return err
+	}
+	if err := cliutil.SetPassphrase(ks, *passphrase); err != nil {
+		return err
+	}
+	audits, err := ks.Audit()
+	if err != nil {
+		return err
+	}
+	results := []auditJSON{}
+	failed := 0
+	for _, a := range audits {
+		if *namespace != "" && a.Namespace != *namespace {
+			continue
+		}
+		res := auditJSON{Namespace: a.Namespace, SKI: hex.EncodeToString(a.SKI), OK: a.OK()}
+		for _, p := range a.Problems {
+			res.Problems = append(res.Problems, p.Error())
+		}
+		if !res.OK {
+			failed++
+		}
+		results = append(results, res)
+	}
+	cli.Print(results, func() {
+		for _, r := range results {
+			if r.OK {
+				fmt.Printf("ok\t%s\t%s\n", r.Namespace, r.SKI)
+			}
+			for _, p := range r.Problems {
+				fmt.Printf("FAIL\t%s\t%s\t%s\n", r.Namespace, r.SKI, p)
+			}
+		}
+		fmt.Printf("audited %d keys, %d with problems\n", len(results), failed)
+	})
+	if failed > 0 {
+		return errAuditFailed
+	}
+	return nil
+}
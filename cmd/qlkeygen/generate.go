@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+)
+
+// generateJSON is the result of qlkeygen generate -output json
+type generateJSON struct {
+	Namespace string                `json:"namespace"`
+	SKI       string                `json:"ski"`
+	PublicKey *hybrid.PublicKeyJSON `json:"public_key"`
+}
+
+// runGenerate generates a hybrid key and stores both halves in a keystore
+// namespace
+func runGenerate(args []string) error {
+	fs := cli.NewFlagSet("generate")
+	dir := fs.String("keystore", "", "keystore directory, created if missing")
+	namespace := fs.String("namespace", "", "namespace to store the key in, e.g. Org1MSP")
+	passphrase := fs.String("passphrase", "", "encrypt the key with the keystore passphrase from keyring:<key>, systemd:<credential> or file:<path>")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" || *namespace == "" {
+		return cli.Usagef("-keystore and -namespace are required")
+	}
+
+	ks, err := hybrid.NewKeyStore(*dir)
+	if err != nil {
+		return err
+	}
+	if err := cliutil.SetPassphrase(ks, *passphrase); err != nil {
+		return err
+	}
+	csp, err := hybrid.New(hybrid.WithKeyStore(ks, *namespace))
+	if err != nil {
+		return err
+	}
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		return err
+	}
+	pub, err := hybrid.NewPublicKeyJSON(k, time.Now())
+	if err != nil {
+		return err
+	}
+	res := generateJSON{Namespace: *namespace, SKI: hex.EncodeToString(k.SKI()), PublicKey: pub}
+	cli.Print(res, func() { fmt.Printf("generated %s\t%s\n", res.Namespace, res.SKI) })
+	return nil
+}
@@ -0,0 +1,29 @@
+// qlkeygen generates the hybrid keys of a namespaced keystore and audits
+// their integrity
+package main
+
+import "github.com/yourusername/quantum-ledger/internal/cli"
+
+const usage = `usage: qlkeygen <command> [flags]
+
+commands:
+  generate   generate a hybrid ECDSA P-256 and ML-DSA key in a keystore namespace
+  audit      check that every stored key has both halves, matching SKIs and
+             key pairs that sign and verify
+
+Typical flow:
+  qlkeygen generate -keystore keys -namespace Org1MSP -passphrase keyring:org1
+  qlkeygen audit -keystore keys -passphrase keyring:org1
+
+qlsig keystore lists, imports and purges the keys of the same keystores.
+Every command accepts -output json. Exit codes: 0 success, 1 failure,
+2 usage error, 3 check failed (the audit found problems).
+Shell completion: source <(qlkeygen completion bash), or zsh.
+`
+
+func main() {
+	cli.Main("qlkeygen", usage, map[string]func([]string) error{
+		"generate": runGenerate,
+		"audit":    runAudit,
+	})
+}
@@ -0,0 +1,137 @@
+// qloperator reconciles HybridKey resources of a Kubernetes cluster with keys
+// generated by the signing daemon
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/operator"
+	"github.com/yourusername/quantum-ledger/signd"
+)
+
+const usage = `usage: qloperator [flags]
+
+Runs in the cluster with the service account of its pod. The daemon is
+reached over mutual TLS, with a client certificate or a SPIRE SVID:
+  qloperator -daemon https://qlsignd:7443 -tls-cert op.pem -tls-key op.key -ca ca.pem
+  qloperator -daemon https://qlsignd:7443 -spiffe-dir /run/spiffe \
+    -daemon-id spiffe://example.org/ns/crypto/sa/qlsignd
+
+The operator identity needs the admin role in the daemon ACL, and an admins
+grant for the namespaces of its resources.
+
+flags:
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "qloperator: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("qloperator", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	daemon := fs.String("daemon", "", "signing daemon URL")
+	cert := fs.String("tls-cert", "", "client TLS certificate")
+	key := fs.String("tls-key", "", "client TLS private key")
+	ca := fs.String("ca", "", "CA certificate of the daemon")
+	spiffeDir := fs.String("spiffe-dir", "", "use the SPIRE SVID written here by spiffe-helper")
+	daemonID := fs.String("daemon-id", "", "SPIFFE ID of the daemon, any in the trust bundle if empty")
+	namespace := fs.String("namespace", "", "watch HybridKeys of this namespace only, all if empty")
+	resync := fs.Duration("resync", time.Minute, "reconciliation period")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *daemon == "" {
+		return errors.New("-daemon is required")
+	}
+	if *spiffeDir == "" && (*cert == "" || *key == "" || *ca == "") {
+		return errors.New("-tls-cert, -tls-key and -ca are required without -spiffe-dir")
+	}
+	if *resync <= 0 {
+		return errors.New("-resync must be positive")
+	}
+
+	var tlsConfig *tls.Config
+	if *spiffeDir != "" {
+		svid, err := signd.LoadSVID(*spiffeDir)
+		if err != nil {
+			return err
+		}
+		tlsConfig = svid.ClientTLSConfig(*daemonID)
+		fmt.Printf("connecting as %s\n", svid.ID())
+	} else {
+		var err error
+		if tlsConfig, err = clientTLSConfig(*cert, *key, *ca); err != nil {
+			return err
+		}
+	}
+	kube, err := operator.InClusterClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := &signd.Client{URL: *daemon, HTTP: &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}}
+	hs, err := client.Handshake(ctx, signd.HandshakeRequest{Versions: []int{signd.ProtocolV1, signd.ProtocolV2}})
+	if err != nil {
+		return fmt.Errorf("handshake with %s: %w", *daemon, err)
+	}
+	fmt.Printf("connected to %s, protocol v%d\n", *daemon, hs.Version)
+
+	op := &operator.Operator{
+		Kube:      kube,
+		Issuer:    client,
+		Namespace: *namespace,
+		Resync:    *resync,
+		Log:       os.Stderr,
+	}
+	if err := op.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// clientTLSConfig presents certFile and trusts the daemon certificates of
+// caFile
+func clientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	raw, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, fmt.Errorf("%s: no certificates found", caFile)
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}, nil
+}
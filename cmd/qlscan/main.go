@@ -0,0 +1,25 @@
+// qlscan scans the committed blocks of a channel for the signatures a
+// policy change affects
+package main
+
+import "github.com/yourusername/quantum-ledger/internal/cli"
+
+const usage = `usage: qlscan <command> [flags]
+
+commands:
+  simulate   replay blocks under a proposed signature policy and report the
+             transactions it would reject
+
+Typical flow:
+  peer channel fetch newest newest.pb -c mychannel
+  qlscan simulate -current policies.yaml -proposed policies-and.yaml block*.pb
+
+Every command accepts -output json. Exit codes: 0 success, 1 failure,
+2 usage error, 3 check failed (the proposed policy rejects transactions).
+`
+
+func main() {
+	cli.Main("qlscan", usage, map[string]func([]string) error{
+		"simulate": runSimulate,
+	})
+}
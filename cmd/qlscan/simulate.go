@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/policysim"
+)
+
+// errRejected is returned when the proposed policy rejects transactions
+var errRejected = cli.Rejected(errors.New("the proposed policy rejects committed transactions"))
+
+// runSimulate replays the block files given as arguments under the
+// proposed policy
+func runSimulate(args []string) error {
+	fs := cli.NewFlagSet("simulate")
+	currentFile := fs.String("current", "", "channel policies YAML in force; without it every rejected signature is reported")
+	proposedFile := fs.String("proposed", "", "proposed channel policies YAML, with per-channel and per-MSP overrides")
+	policy := fs.String("policy", "", "proposed policy for every channel and MSP, instead of -proposed: AND, OR, CLASSICAL or PQC")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if (*proposedFile == "") == (*policy == "") {
+		return cli.Usagef("expected one of -proposed or -policy")
+	}
+	if fs.NArg() == 0 {
+		return cli.Usagef("expected one or more block files")
+	}
+
+	var current hybrid.PolicyResolver
+	if *currentFile != "" {
+		c, err := hybrid.LoadChannelPolicies(*currentFile)
+		if err != nil {
+			return err
+		}
+		current = c
+	}
+	var proposed *hybrid.ChannelPolicies
+	if *policy != "" {
+		p, err := hybrid.ParsePolicy(*policy)
+		if err != nil {
+			return cli.Usagef("-policy: %v", err)
+		}
+		proposed = &hybrid.ChannelPolicies{Default: p}
+	} else {
+		var err error
+		if proposed, err = hybrid.LoadChannelPolicies(*proposedFile); err != nil {
+			return err
+		}
+	}
+
+	sim, err := policysim.New(current, proposed)
+	if err != nil {
+		return err
+	}
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := sim.Replay(raw); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	report := sim.Report()
+	cli.Print(report, func() {
+		fmt.Printf("%d blocks, %d transactions, %d signatures, %d already invalid, %d skipped\n",
+			report.Blocks, report.Transactions, report.Signatures, report.AlreadyInvalid, report.Skipped)
+		msps := make([]string, 0, len(report.MSPs))
+		for id := range report.MSPs {
+			msps = append(msps, id)
+		}
+		sort.Strings(msps)
+		for _, id := range msps {
+			s := report.MSPs[id]
+			fmt.Printf("  %-20s %d signatures, %d rejected\n", id, s.Signatures, s.Failing)
+		}
+		if len(report.Failures) == 0 {
+			fmt.Println("the proposed policy accepts every signature")
+			return
+		}
+		fmt.Printf("%d transactions would fail:\n", report.FailingTransactions)
+		for _, f := range report.Failures {
+			fmt.Printf("  block %d %s %s: %s %s/%s (%s) under %s: %s\n",
+				f.Block, f.Channel, f.TxID, f.Role, f.MSPID, f.Signer, f.Kind, f.Policy, f.Error)
+		}
+	})
+	if len(report.Failures) > 0 {
+		return errRejected
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/bundle"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// filesFlag collects repeated file names
+type filesFlag []string
+
+func (f *filesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *filesFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// bundleCreateJSON is the result of qlsig bundle create -output json
+type bundleCreateJSON struct {
+	File         string `json:"file"`
+	Certificates int    `json:"certificates"`
+	CRLs         int    `json:"crls"`
+	Timestamps   int    `json:"timestamps"`
+}
+
+// bundleVerifyJSON is the result of qlsig bundle verify -output json
+type bundleVerifyJSON struct {
+	Policy     string          `json:"policy"`
+	Hash       string          `json:"hash"`
+	Signer     string          `json:"signer"`
+	Timestamps []timestampJSON `json:"timestamps"`
+	// TrustedRoot is false when no -root was given
+	TrustedRoot bool `json:"trusted_root"`
+	// Unchecked lists the certificates whose revocation no CRL covered
+	Unchecked []string  `json:"unchecked_revocation"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type timestampJSON struct {
+	Time    time.Time `json:"time"`
+	TSA     string    `json:"tsa"`
+	Trusted bool      `json:"trusted"`
+}
+
+// runBundle creates or verifies offline verification bundles
+func runBundle(args []string) error {
+	if len(args) == 0 {
+		return cli.Usagef("expected create or verify")
+	}
+	switch args[0] {
+	case "create":
+		return runBundleCreate(args[1:])
+	case "verify":
+		return runBundleVerify(args[1:])
+	default:
+		return cli.Usagef("unknown bundle command %q, expected create or verify", args[0])
+	}
+}
+
+func runBundleCreate(args []string) error {
+	fs := cli.NewFlagSet("bundle create")
+	sigFile := fs.String("sig", "", "hybrid signature file")
+	msgFile := fs.String("message", "", "signed message, included in the bundle")
+	digestHex := fs.String("digest", "", "hex digest that was signed, when the message is not included")
+	hash := fs.String("hash", "SHA-256", "hash of the digest recipe: SHA-256, SHA-384, SHA-512 or SHA3-256")
+	policy := fs.String("policy", "AND", "verification policy recorded in the bundle")
+	var certs, crls, timestamps filesFlag
+	fs.Var(&certs, "cert", "PEM certificates, signer first then its issuers up to the root (repeatable)")
+	fs.Var(&crls, "crl", "PEM or DER CRL of a CA of the chain (repeatable)")
+	fs.Var(&timestamps, "timestamp", "RFC 3161 response or token over the signature file (repeatable)")
+	out := fs.String("out", "bundle.zip", "output file")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *sigFile == "" || len(certs) == 0 || (*msgFile == "") == (*digestHex == "") {
+		return cli.Usagef("-sig, -cert and one of -message or -digest are required")
+	}
+
+	b := &bundle.Bundle{}
+	var err error
+	if b.Policy, err = core.ParsePolicy(*policy); err != nil {
+		return err
+	}
+	if b.Signature, err = os.ReadFile(*sigFile); err != nil {
+		return err
+	}
+	if *msgFile != "" {
+		if b.Message, err = os.ReadFile(*msgFile); err != nil {
+			return err
+		}
+		if b.Recipe, err = bundle.Digest(*hash, b.Message); err != nil {
+			return err
+		}
+	} else {
+		b.Recipe.Hash = *hash
+		if b.Recipe.Digest, err = hex.DecodeString(*digestHex); err != nil {
+			return fmt.Errorf("invalid -digest: %w", err)
+		}
+	}
+	for _, file := range certs {
+		parsed, err := readCertificates(file)
+		if err != nil {
+			return err
+		}
+		b.Certificates = append(b.Certificates, parsed...)
+	}
+	for _, file := range crls {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if block, _ := pem.Decode(raw); block != nil {
+			raw = block.Bytes
+		}
+		b.CRLs = append(b.CRLs, raw)
+	}
+	for _, file := range timestamps {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		b.Timestamps = append(b.Timestamps, raw)
+	}
+
+	// Catch unusable bundles now rather than years later
+	if _, err := b.Verify(bundle.VerifyOptions{}); err != nil {
+		return err
+	}
+	if err := b.WriteFile(*out, time.Now()); err != nil {
+		return err
+	}
+	res := bundleCreateJSON{File: *out, Certificates: len(b.Certificates), CRLs: len(b.CRLs), Timestamps: len(b.Timestamps)}
+	cli.Print(res, func() {
+		fmt.Printf("wrote %s: %d certificates, %d CRLs, %d time-stamps\n", res.File, res.Certificates, res.CRLs, res.Timestamps)
+	})
+	return nil
+}
+
+func runBundleVerify(args []string) error {
+	fs := cli.NewFlagSet("bundle verify")
+	var roots, tsaRoots filesFlag
+	fs.Var(&roots, "root", "PEM trusted root certificate (repeatable)")
+	fs.Var(&tsaRoots, "tsa-root", "PEM trusted root of the time-stamp authorities (repeatable)")
+	msgFile := fs.String("message", "", "check this message instead of the one in the bundle")
+	at := fs.String("at", "", "RFC 3339 time to check certificates at when there is no time-stamp (default now)")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return cli.Usagef("expected one bundle file")
+	}
+
+	opts := bundle.VerifyOptions{}
+	for _, file := range roots {
+		parsed, err := readCertificates(file)
+		if err != nil {
+			return err
+		}
+		opts.Roots = append(opts.Roots, parsed...)
+	}
+	if len(tsaRoots) > 0 {
+		opts.TSARoots = x509.NewCertPool()
+		for _, file := range tsaRoots {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if !opts.TSARoots.AppendCertsFromPEM(raw) {
+				return fmt.Errorf("%s: no PEM certificate", file)
+			}
+		}
+	}
+	if *msgFile != "" {
+		var err error
+		if opts.Message, err = os.ReadFile(*msgFile); err != nil {
+			return err
+		}
+	}
+	if *at != "" {
+		var err error
+		if opts.Time, err = time.Parse(time.RFC3339, *at); err != nil {
+			return fmt.Errorf("invalid -at: %w", err)
+		}
+	}
+
+	b, m, err := bundle.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	res, err := b.Verify(opts)
+	if err != nil {
+		return cli.RejectedIf(err, bundle.ErrHashMismatch, bundle.ErrInvalidSignature, bundle.ErrUntrustedChain,
+			bundle.ErrRevoked, bundle.ErrExpired, bundle.ErrInvalidTimestamp)
+	}
+	out := bundleVerifyJSON{
+		Policy:      b.Policy.String(),
+		Hash:        b.Recipe.Hash,
+		Signer:      res.Signer.Subject.CommonName,
+		Timestamps:  []timestampJSON{},
+		TrustedRoot: res.TrustedRoot,
+		Unchecked:   []string{},
+		CreatedAt:   m.CreatedAt,
+	}
+	for _, ts := range res.Timestamps {
+		out.Timestamps = append(out.Timestamps, timestampJSON{Time: ts.Time, TSA: ts.TSA.Subject.CommonName, Trusted: ts.Trusted})
+	}
+	for i, checked := range res.Revocation[:len(res.Revocation)-1] {
+		if !checked {
+			out.Unchecked = append(out.Unchecked, b.Certificates[i].Subject.CommonName)
+		}
+	}
+	cli.Print(out, func() {
+		fmt.Printf("signature: OK, %s policy, %s digest, signed by %s\n", out.Policy, out.Hash, out.Signer)
+		for _, ts := range out.Timestamps {
+			trust := "TSA not checked, no -tsa-root"
+			if ts.Trusted {
+				trust = "trusted TSA"
+			}
+			fmt.Printf("time-stamp: %s by %s (%s)\n", ts.Time.Format(time.RFC3339), ts.TSA, trust)
+		}
+		for _, name := range out.Unchecked {
+			fmt.Printf("warning: no CRL for %s\n", name)
+		}
+		if !out.TrustedRoot {
+			fmt.Println("warning: no -root given, the certificate chain is only self-consistent")
+		}
+		fmt.Printf("bundle created %s\n", out.CreatedAt.Format(time.RFC3339))
+	})
+	return nil
+}
+
+// readCertificates parses every composite certificate of a PEM file
+func readCertificates(file string) ([]*hybridx509.Certificate, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*hybridx509.Certificate
+	for {
+		var block *pem.Block
+		if block, raw = pem.Decode(raw); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := hybridx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s: no PEM certificate", file)
+	}
+	return certs, nil
+}
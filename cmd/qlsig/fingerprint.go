@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// fingerprintJSON is a result of qlsig fingerprint -output json
+type fingerprintJSON struct {
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+	Subject     string `json:"subject"`
+}
+
+// runFingerprint prints the composite key fingerprints to pin for certificates
+func runFingerprint(args []string) error {
+	fs := cli.NewFlagSet("fingerprint")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return cli.Usagef("expected one or more PEM certificate files")
+	}
+
+	results := []fingerprintJSON{}
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		cert, err := hybridx509.ParseCertificatePEM(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		composite, err := cert.CompositePublicKey()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		results = append(results, fingerprintJSON{File: file, Fingerprint: msp.KeyFingerprint(composite).String(), Subject: cert.Subject.CommonName})
+	}
+	cli.Print(results, func() {
+		for _, r := range results {
+			fmt.Printf("%s  %s\n", r.Fingerprint, r.Subject)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/testvectors"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// vectorsJSON is a result of qlsig genvectors -output json
+type vectorsJSON struct {
+	Name  string `json:"name"`
+	Cases int    `json:"cases"`
+}
+
+// runGenVectors regenerates the JSON files embedded by the testvectors package
+func runGenVectors(args []string) error {
+	fs := cli.NewFlagSet("genvectors")
+	out := fs.String("out", "bccsp/hybrid/testvectors/testdata/vectors", "output directory")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+
+	vectors, err := testvectors.Generate()
+	if err != nil {
+		return err
+	}
+	if err := testvectors.Write(*out, vectors); err != nil {
+		return err
+	}
+	results := make([]vectorsJSON, len(vectors))
+	for i, v := range vectors {
+		results[i] = vectorsJSON{Name: v.Name, Cases: len(v.Cases)}
+	}
+	cli.Print(results, func() {
+		for _, r := range results {
+			fmt.Printf("wrote %s (%d cases)\n", r.Name, r.Cases)
+		}
+	})
+	return nil
+}
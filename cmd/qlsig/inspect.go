@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// runInspect prints the composite public keys of certificates as canonical JSON
+func runInspect(args []string) error {
+	fs := cli.NewFlagSet("inspect")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return cli.Usagef("expected one or more PEM certificate files")
+	}
+
+	keys := []*core.PublicKeyJSON{}
+	for _, file := range fs.Args() {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		cert, err := hybridx509.ParseCertificatePEM(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		composite, err := cert.CompositePublicKey()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		// The key exists at least since the certificate became valid
+		pub, err := core.NewPublicKeyJSON(composite, cert.NotBefore)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, pub)
+	}
+	var err error
+	cli.Print(keys, func() {
+		// One key per line, as before -output json existed
+		enc := json.NewEncoder(os.Stdout)
+		for _, pub := range keys {
+			if err = enc.Encode(pub); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
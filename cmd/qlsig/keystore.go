@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/internal/cliutil"
+)
+
+// keystoreJSON is a result of qlsig keystore -output json: the keys of a
+// namespace, the key import stored or the keys purge deleted
+type keystoreJSON struct {
+	Namespace string   `json:"namespace"`
+	Keys      []string `json:"keys"`
+	DryRun    bool     `json:"dry_run,omitempty"`
+}
+
+// runKeystore lists, imports or deletes the keys of a namespaced keystore;
+// qlkeygen audit checks their integrity
+func runKeystore(args []string) error {
+	if len(args) == 0 {
+		return cli.Usagef("expected list, import or purge")
+	}
+	switch args[0] {
+	case "list":
+		return runKeystoreList(args[1:])
+	case "import":
+		return runKeystoreImport(args[1:])
+	case "purge":
+		return runKeystorePurge(args[1:])
+	default:
+		return cli.Usagef("unknown keystore command %q, expected list, import or purge", args[0])
+	}
+}
+
+func listKeys(ks *hybrid.KeyStore, ns string) (keystoreJSON, error) {
+	skis, err := ks.ListKeys(ns)
+	if err != nil {
+		return keystoreJSON{}, err
+	}
+	res := keystoreJSON{Namespace: ns, Keys: make([]string, len(skis))}
+	for i, ski := range skis {
+		res.Keys[i] = hex.EncodeToString(ski)
+	}
+	return res, nil
+}
+
+func runKeystoreList(args []string) error {
+	fs := cli.NewFlagSet("keystore list")
+	dir := fs.String("keystore", "", "keystore directory")
+	namespace := fs.String("namespace", "", "list a single namespace, e.g. Org1MSP")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return cli.Usagef("-keystore is required")
+	}
+
+	ks, namespaces, err := cliutil.OpenKeyStore(*dir)
+	if err != nil {
+		return err
+	}
+	if *namespace != "" {
+		namespaces = []string{*namespace}
+	}
+	results := []keystoreJSON{}
+	for _, ns := range namespaces {
+		res, err := listKeys(ks, ns)
+		if err != nil {
+			return err
+		}
+		results = append(results, res)
+	}
+	cli.Print(results, func() {
+		for _, r := range results {
+			for _, ski := range r.Keys {
+				fmt.Printf("%s\t%s\n", r.Namespace, ski)
+			}
+		}
+	})
+	return nil
+}
+
+// runKeystoreImport stores a hybrid key made of an ECDSA key and an ML-DSA
+// key generated elsewhere, e.g. by an HSM or openssl with the oqs-provider
+func runKeystoreImport(args []string) error {
+	fs := cli.NewFlagSet("keystore import")
+	dir := fs.String("keystore", "", "keystore directory")
+	namespace := fs.String("namespace", "", "namespace to store the key in, e.g. Org1MSP")
+	ecdsaFile := fs.String("ecdsa", "", "PEM ECDSA P-256 private key, PKCS#8 or SEC 1")
+	mldsaFile := fs.String("mldsa", "", "ML-DSA private key: PKCS#8 PEM or DER, or raw")
+	mldsaPubFile := fs.String("mldsa-pub", "", "ML-DSA public key, when the private key does not embed it: SPKI PEM or DER, or raw")
+	parameterSet := fs.String("parameter-set", hybrid.PQCAlgorithm, "expected ML-DSA parameter set")
+	passphrase := fs.String("passphrase", "", "encrypt the key with the keystore passphrase from keyring:<key>, systemd:<credential> or file:<path>")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" || *namespace == "" || *ecdsaFile == "" || *mldsaFile == "" {
+		return cli.Usagef("-keystore, -namespace, -ecdsa and -mldsa are required")
+	}
+
+	ext := &hybrid.ExternalPrivateKey{}
+	raw, err := os.ReadFile(*ecdsaFile)
+	if err != nil {
+		return err
+	}
+	if ext.ECDSA, err = parseECDSAPrivateKey(raw); err != nil {
+		return err
+	}
+	if ext.MLDSA, err = os.ReadFile(*mldsaFile); err != nil {
+		return err
+	}
+	if *mldsaPubFile != "" {
+		if ext.MLDSAPublicKey, err = os.ReadFile(*mldsaPubFile); err != nil {
+			return err
+		}
+	}
+
+	ks, err := hybrid.NewKeyStore(*dir)
+	if err != nil {
+		return err
+	}
+	if err := cliutil.SetPassphrase(ks, *passphrase); err != nil {
+		return err
+	}
+	csp, err := hybrid.New(hybrid.WithKeyStore(ks, *namespace))
+	if err != nil {
+		return err
+	}
+	k, err := csp.KeyImport(ext, &hybrid.ExternalKeyImportOpts{ParameterSet: *parameterSet})
+	if err != nil {
+		return cli.RejectedIf(err, hybrid.ErrParameterSet, hybrid.ErrKeyPairMismatch)
+	}
+	res := keystoreJSON{Namespace: *namespace, Keys: []string{hex.EncodeToString(k.SKI())}}
+	cli.Print(res, func() { fmt.Printf("imported %s\t%s\n", res.Namespace, res.Keys[0]) })
+	return nil
+}
+
+// parseECDSAPrivateKey parses a PEM ECDSA private key as written by
+// openssl, PKCS#8 or SEC 1
+func parseECDSAPrivateKey(raw []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block in ECDSA private key")
+	}
+	if block.Type == "EC PRIVATE KEY" {
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid key type, expected *ecdsa.PrivateKey")
+	}
+	return key, nil
+}
+
+// runKeystorePurge deletes keys of a namespace, after confirmation
+func runKeystorePurge(args []string) error {
+	fs := cli.NewFlagSet("keystore purge")
+	dir := fs.String("keystore", "", "keystore directory")
+	namespace := fs.String("namespace", "", "namespace of the keys, e.g. Org1MSP")
+	skis := fs.String("ski", "", "comma separated hex SKIs of the keys to delete")
+	all := fs.Bool("all", false, "delete every key of the namespace")
+	safeguard := cli.NewSafeguard(fs)
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" || *namespace == "" {
+		return cli.Usagef("-keystore and -namespace are required")
+	}
+	if (*skis == "") == !*all {
+		return cli.Usagef("expected one of -ski and -all")
+	}
+
+	ks, namespaces, err := cliutil.OpenKeyStore(*dir)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(namespaces, *namespace) {
+		return fmt.Errorf("%w: no keys in namespace %s", hybrid.ErrKeyNotFound, *namespace)
+	}
+	res, err := listKeys(ks, *namespace)
+	if err != nil {
+		return err
+	}
+	if !*all {
+		var selected []string
+		for _, s := range strings.Split(*skis, ",") {
+			s = strings.ToLower(strings.TrimSpace(s))
+			if _, err := hex.DecodeString(s); err != nil {
+				return cli.Usagef("invalid SKI %q: %v", s, err)
+			}
+			if !slices.Contains(res.Keys, s) {
+				return fmt.Errorf("%w: %s in %s", hybrid.ErrKeyNotFound, s, *namespace)
+			}
+			selected = append(selected, s)
+		}
+		res.Keys = selected
+	}
+
+	if safeguard.DryRunf("delete %d keys from %s: %s", len(res.Keys), res.Namespace, strings.Join(res.Keys, ", ")) {
+		res.DryRun = true
+		cli.Print(res, nil)
+		return nil
+	}
+	if err := safeguard.Confirm("permanently delete %d keys from %s", len(res.Keys), res.Namespace); err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range res.Keys {
+		ski, _ := hex.DecodeString(s)
+		if err := ks.DeleteKey(res.Namespace, ski); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	cli.Print(res, func() {
+		for _, ski := range res.Keys {
+			fmt.Printf("deleted %s\t%s\n", res.Namespace, ski)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,33 @@
+// qlsig is the command line tool for hybrid signatures
+package main
+
+import "github.com/yourusername/quantum-ledger/internal/cli"
+
+const usage = `usage: qlsig <command> [flags]
+
+commands:
+  genvectors   regenerate the canonical test vectors
+  usage        show per-key signature counters of a keystore
+  keystore     list, import or purge the keys of a keystore
+  snapshot     sign or verify the SHA3-256 manifest of a ledger snapshot
+  fingerprint  print the composite key fingerprints of certificates, for pinning
+  inspect      print the composite public keys of certificates as JSON
+  bundle       create or verify an offline verification bundle for a signature
+
+Every command accepts -output json. Exit codes: 0 success, 1 failure,
+2 usage error, 3 check failed (e.g. invalid bundle or snapshot signature).
+Commands deleting keys ask for confirmation unless given -yes, and accept
+-dry-run. Shell completion: source <(qlsig completion bash), or zsh.
+`
+
+func main() {
+	cli.Main("qlsig", usage, map[string]func([]string) error{
+		"genvectors":  runGenVectors,
+		"usage":       runUsage,
+		"keystore":    runKeystore,
+		"snapshot":    runSnapshot,
+		"fingerprint": runFingerprint,
+		"inspect":     runInspect,
+		"bundle":      runBundle,
+	})
+}
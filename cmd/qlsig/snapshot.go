@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/snapshot"
+)
+
+// snapshotJSON is the result of qlsig snapshot -output json; the signer is
+// only set by verify
+type snapshotJSON struct {
+	Channel string `json:"channel"`
+	Block   uint64 `json:"block"`
+	Files   int    `json:"files"`
+	MSPID   string `json:"msp_id,omitempty"`
+	Signer  string `json:"signer,omitempty"`
+}
+
+// runSnapshot signs or verifies the manifest of a ledger snapshot
+func runSnapshot(args []string) error {
+	if len(args) == 0 {
+		return cli.Usagef("expected sign or verify")
+	}
+	switch args[0] {
+	case "sign":
+		return runSnapshotSign(args[1:])
+	case "verify":
+		return runSnapshotVerify(args[1:])
+	default:
+		return cli.Usagef("unknown snapshot command %q, expected sign or verify", args[0])
+	}
+}
+
+func runSnapshotSign(args []string) error {
+	fs := cli.NewFlagSet("snapshot sign")
+	dir := fs.String("dir", "", "snapshot directory")
+	mspDir := fs.String("msp", "", "MSP folder of the signing peer")
+	mspID := fs.String("mspid", "", "MSP ID of the signing peer")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" || *mspDir == "" || *mspID == "" {
+		return cli.Usagef("-dir, -msp and -mspid are required")
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	id, err := msp.LoadSigningIdentity(csp, *mspDir, *mspID)
+	if err != nil {
+		return err
+	}
+	m, err := snapshot.WriteManifest(*dir, id)
+	if err != nil {
+		return err
+	}
+	res := snapshotJSON{Channel: m.ChannelName, Block: m.LastBlockNumber, Files: len(m.Files)}
+	cli.Print(res, func() {
+		fmt.Printf("signed %d files of %s block %d\n", res.Files, res.Channel, res.Block)
+	})
+	return nil
+}
+
+func runSnapshotVerify(args []string) error {
+	fs := cli.NewFlagSet("snapshot verify")
+	dir := fs.String("dir", "", "snapshot directory")
+	mspDir := fs.String("msp", "", "MSP folder with the CA certificates of the trusted organization")
+	mspID := fs.String("mspid", "", "MSP ID of the trusted organization")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *dir == "" || *mspDir == "" || *mspID == "" {
+		return cli.Usagef("-dir, -msp and -mspid are required")
+	}
+
+	csp, err := hybrid.New()
+	if err != nil {
+		return err
+	}
+	cas, err := msp.LoadCACertificates(*mspDir)
+	if err != nil {
+		return err
+	}
+	v := &snapshot.Verifier{
+		Deserializer: &msp.Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{*mspID: cas}},
+		CSP:          csp,
+	}
+	m, id, err := v.VerifyDir(*dir)
+	if err != nil {
+		return cli.RejectedIf(err, snapshot.ErrHashMismatch, snapshot.ErrUnlistedFile, snapshot.ErrInvalidSignature,
+			msp.ErrUnknownIssuer, msp.ErrPinMismatch)
+	}
+	res := snapshotJSON{Channel: m.ChannelName, Block: m.LastBlockNumber, Files: len(m.Files), MSPID: id.MSPID, Signer: id.Certificate.Subject.CommonName}
+	cli.Print(res, func() {
+		fmt.Printf("snapshot of %s block %d: OK, %d files, signed by %s/%s\n", res.Channel, res.Block, res.Files, res.MSPID, res.Signer)
+	})
+	return nil
+}
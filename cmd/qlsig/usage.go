@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/cli"
+)
+
+// usageJSON is a result of qlsig usage -output json
+type usageJSON struct {
+	SKI        string `json:"ski"`
+	Signatures uint64 `json:"signatures"`
+}
+
+// runUsage prints the signature counters kept in a keystore
+func runUsage(args []string) error {
+	fs := cli.NewFlagSet("usage")
+	keystore := fs.String("keystore", "", "keystore directory")
+	ski := fs.String("ski", "", "hex SKI of a single key")
+	if err := cli.Parse(fs, args); err != nil {
+		return err
+	}
+	if *keystore == "" {
+		return cli.Usagef("-keystore is required")
+	}
+
+	store, err := hybrid.NewFileUsageStore(*keystore)
+	if err != nil {
+		return err
+	}
+	if *ski != "" {
+		raw, err := hex.DecodeString(*ski)
+		if err != nil {
+			return fmt.Errorf("invalid SKI: %w", err)
+		}
+		n, err := store.Count(raw)
+		if err != nil {
+			return err
+		}
+		printUsage([]usageJSON{{SKI: *ski, Signatures: n}})
+		return nil
+	}
+
+	counts, err := store.Counts()
+	if err != nil {
+		return err
+	}
+	skis := make([]string, 0, len(counts))
+	for s := range counts {
+		skis = append(skis, s)
+	}
+	sort.Strings(skis)
+	results := make([]usageJSON, len(skis))
+	for i, s := range skis {
+		results[i] = usageJSON{SKI: s, Signatures: counts[s]}
+	}
+	printUsage(results)
+	return nil
+}
+
+func printUsage(results []usageJSON) {
+	cli.Print(results, func() {
+		for _, r := range results {
+			fmt.Printf("%s\t%d\n", r.SKI, r.Signatures)
+		}
+	})
+}
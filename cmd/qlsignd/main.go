@@ -0,0 +1,44 @@
+// qlsignd is a signing daemon serving the hybrid keys of several organizations
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: qlsignd <command> [flags]
+
+commands:
+  serve   serve the signing API over mutual TLS
+
+Typical flow:
+  qlsignd serve -keystore keys -acl acl.yaml -tls-cert server.pem \
+    -tls-key server.key -client-ca clients-ca.pem -audit-log audit.jsonl
+
+With SPIRE, credentials come from the SVID spiffe-helper keeps in a directory:
+  qlsignd serve -keystore keys -acl acl.yaml -spiffe-dir /run/spiffe
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "serve":
+		err = runServe(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "qlsignd: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qlsignd %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/secret"
+	"github.com/yourusername/quantum-ledger/signd"
+)
+
+// runServe serves the signing API until the listener fails
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:7443", "listen address")
+	keystore := fs.String("keystore", "", "keystore directory, one subdirectory per namespace")
+	keystorePassphrase := fs.String("keystore-passphrase", "", "keystore passphrase source: keyring:<key>, systemd:<credential> or file:<path>")
+	aclFile := fs.String("acl", "", "ACL YAML file mapping client certificates to roles and keys")
+	providerConfig := fs.String("provider-config", "", "provider YAML file: policy, hash, envelope encoding and timeouts, check it with qlconf check")
+	cert := fs.String("tls-cert", "", "server TLS certificate")
+	key := fs.String("tls-key", "", "server TLS private key")
+	clientCAs := fs.String("client-ca", "", "comma separated CA certificates of the clients")
+	auditLog := fs.String("audit-log", "", "append audit events as JSON lines to this file")
+	auditSize := fs.Int("audit-size", 10000, "audit events served to audit-read clients")
+	preload := fs.Bool("preload", false, "load every key at startup instead of on first use")
+	spiffeDir := fs.String("spiffe-dir", "", "serve the SPIRE SVID written here by spiffe-helper and identify clients by SPIFFE ID")
+	trustDomains := fs.String("spiffe-trust-domains", "", "comma separated trust domains of the clients, the SVID's if empty")
+	healthListen := fs.String("health-listen", "", "serve /healthz and /readyz over plain HTTP here, for kubelet probes")
+	kmsEndpoints := fs.String("kms-endpoints", "", "comma separated host:port that must be reachable for readiness")
+	checkTimeout := fs.Duration("check-timeout", 5*time.Second, "timeout of each readiness check")
+	selfBenchmark := fs.Duration("self-benchmark", 0, "sign and verify with a throwaway key this often and serve the latencies on /selfbench of -health-listen, e.g. 10m")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keystore == "" || *aclFile == "" {
+		return errors.New("-keystore and -acl are required")
+	}
+	if *spiffeDir == "" && (*cert == "" || *key == "" || *clientCAs == "") {
+		return errors.New("-tls-cert, -tls-key and -client-ca are required without -spiffe-dir")
+	}
+	if *spiffeDir != "" && (*cert != "" || *key != "" || *clientCAs != "") {
+		return errors.New("-spiffe-dir replaces -tls-cert, -tls-key and -client-ca")
+	}
+	if *selfBenchmark != 0 && *healthListen == "" {
+		return errors.New("-self-benchmark requires -health-listen")
+	}
+
+	acl, err := signd.LoadACL(*aclFile)
+	if err != nil {
+		return err
+	}
+	ks, err := hybrid.NewKeyStore(*keystore)
+	if err != nil {
+		return err
+	}
+	if *keystorePassphrase != "" {
+		passphrase, err := secret.Read(*keystorePassphrase)
+		if err != nil {
+			return err
+		}
+		err = ks.SetPassphrase(passphrase)
+		clear(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+	var opts []hybrid.Option
+	if *providerConfig != "" {
+		c, err := hybrid.LoadProviderConfig(*providerConfig)
+		if err != nil {
+			return err
+		}
+		opts = c.Options()
+		if c.Canary {
+			opts = append(opts, hybrid.WithCanary(func(d *hybrid.CanaryDiscrepancy) {
+				fmt.Fprintln(os.Stderr, d)
+			}))
+		}
+		if c.MemoryLocking {
+			if err := core.SetMemoryLocking(true); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: secret keys are not locked in memory: %v\n", err)
+			}
+		}
+	}
+	var sink audit.Sink
+	if *auditLog != "" {
+		f, err := os.OpenFile(*auditLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sink = audit.NewWriterSink(f)
+	}
+
+	// A broken keystore, KMS or PQC library fails the start instead of the
+	// first requests
+	health := &signd.Health{Timeout: *checkTimeout, Checks: []signd.Check{signd.KeystoreCheck(ks), signd.SelfTestCheck(opts...)}}
+	if *kmsEndpoints != "" {
+		for _, addr := range strings.Split(*kmsEndpoints, ",") {
+			health.Checks = append(health.Checks, signd.DialCheck("kms "+addr, addr))
+		}
+	}
+	if report, ok := health.Run(context.Background()); !ok {
+		for _, c := range report.Checks {
+			if !c.OK {
+				fmt.Fprintf(os.Stderr, "check %s failed: %s\n", c.Name, c.Error)
+			}
+		}
+		return errors.New("startup checks failed")
+	}
+
+	server := signd.NewServer(ks, acl, audit.NewMemorySink(*auditSize), sink, opts...)
+	var tlsConfig *tls.Config
+	if *spiffeDir != "" {
+		svid, err := signd.LoadSVID(*spiffeDir)
+		if err != nil {
+			return err
+		}
+		domains := []string{svid.TrustDomain()}
+		if *trustDomains != "" {
+			domains = strings.Split(*trustDomains, ",")
+		}
+		if err := acl.ValidateSPIFFE(domains...); err != nil {
+			return fmt.Errorf("%s: %w", *aclFile, err)
+		}
+		server.SetIdentifier(signd.SPIFFEIdentifier(domains...))
+		tlsConfig = svid.TLSConfig()
+		fmt.Printf("serving as %s, clients from %s\n", svid.ID(), strings.Join(domains, ","))
+	} else if tlsConfig, err = serverTLSConfig(*cert, *key, strings.Split(*clientCAs, ",")); err != nil {
+		return err
+	}
+	if *preload {
+		start := time.Now()
+		err := server.Preload(hybrid.PreloadConfig{Progress: func(loaded, total int) {
+			if loaded == total || loaded%100 == 0 {
+				fmt.Printf("preloaded %d/%d keys\n", loaded, total)
+			}
+		}})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("preloading done in %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	srv := &http.Server{
+		Addr:      *listen,
+		Handler:   server.Handler(),
+		TLSConfig: tlsConfig,
+	}
+	if *healthListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/", health.Handler())
+		if *selfBenchmark != 0 {
+			bench := hybrid.NewSelfBenchmark(hybrid.SelfBenchmarkConfig{Interval: *selfBenchmark, Options: opts}, nil)
+			go bench.Run(context.Background())
+			mux.Handle("GET /selfbench", bench.Handler())
+		}
+		probes := &http.Server{Addr: *healthListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := probes.ListenAndServe(); err != nil {
+				fmt.Fprintf(os.Stderr, "health listener: %v\n", err)
+				os.Exit(1)
+			}
+		}()
+		fmt.Printf("serving probes on %s\n", *healthListen)
+	}
+	fmt.Printf("serving on %s\n", *listen)
+	return srv.ListenAndServeTLS("", "")
+}
+
+// serverTLSConfig requires a client certificate issued by one of caFiles
+func serverTLSConfig(certFile, keyFile string, caFiles []string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	pool := x509.NewCertPool()
+	for _, f := range caFiles {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(raw) {
+			return nil, fmt.Errorf("%s: no certificates found", f)
+		}
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}, nil
+}
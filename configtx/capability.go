@@ -0,0 +1,111 @@
+// Package configtx carries the hybrid-signature enforcement level in Fabric
+// channel configuration, so a consortium can switch enforcement on with a
+// regular channel config update.
+//
+// The level is expressed as capabilities: HYBRID_V1 activates hybrid
+// verification and exactly one HYBRID_POLICY_<policy> capability selects the
+// policy. Nodes that do not know these capabilities stop processing the
+// channel, which is the intended safety net: every member must run a
+// hybrid-aware build before the update is submitted.
+package configtx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+const (
+	// CapabilityHybridV1 marks a channel whose members verify hybrid signatures
+	CapabilityHybridV1 = "HYBRID_V1"
+	// capabilityPolicyPrefix prefixes the capability carrying the policy
+	capabilityPolicyPrefix = "HYBRID_POLICY_"
+)
+
+// Settings is the hybrid enforcement state of a channel
+type Settings struct {
+	Enabled bool
+	Policy  hybrid.Policy
+}
+
+// Capabilities returns the capability names encoding s
+func (s Settings) Capabilities() []string {
+	if !s.Enabled {
+		return nil
+	}
+	return []string{CapabilityHybridV1, capabilityPolicyPrefix + s.Policy.String()}
+}
+
+// SettingsFromCapabilities decodes the hybrid settings from a channel's
+// capability names. Unrelated capabilities are ignored.
+func SettingsFromCapabilities(capabilities []string) (Settings, error) {
+	var s Settings
+	var policies []string
+	for _, c := range capabilities {
+		switch {
+		case c == CapabilityHybridV1:
+			s.Enabled = true
+		case strings.HasPrefix(c, capabilityPolicyPrefix):
+			policies = append(policies, strings.TrimPrefix(c, capabilityPolicyPrefix))
+		}
+	}
+	if !s.Enabled {
+		if len(policies) > 0 {
+			return Settings{}, fmt.Errorf("%s%s set without %s", capabilityPolicyPrefix, policies[0], CapabilityHybridV1)
+		}
+		return s, nil
+	}
+	switch len(policies) {
+	case 0:
+		s.Policy = hybrid.PolicyHybridAND
+	case 1:
+		p, err := hybrid.ParsePolicy(policies[0])
+		if err != nil {
+			return Settings{}, err
+		}
+		s.Policy = p
+	default:
+		sort.Strings(policies)
+		return Settings{}, fmt.Errorf("conflicting hybrid policies %v", policies)
+	}
+	return s, nil
+}
+
+// Resolver is a hybrid.PolicyResolver fed with channel configurations. Channels
+// without hybrid enforcement fall back to another resolver.
+type Resolver struct {
+	mutex    sync.RWMutex
+	channels map[string]Settings
+	fallback hybrid.PolicyResolver
+}
+
+// NewResolver returns a resolver deferring to fallback until a channel enables hybrid enforcement
+func NewResolver(fallback hybrid.PolicyResolver) *Resolver {
+	return &Resolver{channels: map[string]Settings{}, fallback: fallback}
+}
+
+// Update records the settings of channel, typically on every config block
+func (r *Resolver) Update(channel string, s Settings) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.channels[channel] = s
+}
+
+// Settings returns the last settings recorded for channel
+func (r *Resolver) Settings(channel string) (Settings, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	s, ok := r.channels[channel]
+	return s, ok
+}
+
+// ResolvePolicy implements hybrid.PolicyResolver
+func (r *Resolver) ResolvePolicy(channel, mspID string) hybrid.Policy {
+	if s, ok := r.Settings(channel); ok && s.Enabled {
+		return s.Policy
+	}
+	return r.fallback.ResolvePolicy(channel, mspID)
+}
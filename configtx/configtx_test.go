@@ -0,0 +1,83 @@
+package configtx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+const sampleConfig = `{
+  "channel_group": {
+    "groups": {
+      "Application": {
+        "values": {
+          "Capabilities": {
+            "mod_policy": "Admins",
+            "value": {"capabilities": {"V2_0": {}}},
+            "version": "0"
+          }
+        }
+      }
+    },
+    "values": {}
+  }
+}`
+
+func TestSettingsFromCapabilities(t *testing.T) {
+	s, err := SettingsFromCapabilities([]string{"V2_0"})
+	require.NoError(t, err)
+	assert.False(t, s.Enabled)
+
+	s, err = SettingsFromCapabilities([]string{"V2_0", "HYBRID_V1"})
+	require.NoError(t, err)
+	assert.Equal(t, Settings{Enabled: true, Policy: hybrid.PolicyHybridAND}, s)
+
+	s, err = SettingsFromCapabilities([]string{"HYBRID_V1", "HYBRID_POLICY_OR"})
+	require.NoError(t, err)
+	assert.Equal(t, hybrid.PolicyHybridOR, s.Policy)
+
+	_, err = SettingsFromCapabilities([]string{"HYBRID_V1", "HYBRID_POLICY_OR", "HYBRID_POLICY_AND"})
+	assert.Error(t, err)
+	_, err = SettingsFromCapabilities([]string{"HYBRID_POLICY_OR"})
+	assert.Error(t, err)
+}
+
+func TestInjectReadJSON(t *testing.T) {
+	want := Settings{Enabled: true, Policy: hybrid.PolicyPQC}
+	out, err := InjectJSON([]byte(sampleConfig), GroupApplication, want)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"V2_0"`)
+
+	got, err := ReadJSON(out, GroupApplication)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Re-injecting replaces the previous level
+	out, err = InjectJSON(out, GroupApplication, Settings{Enabled: true, Policy: hybrid.PolicyHybridOR})
+	require.NoError(t, err)
+	got, err = ReadJSON(out, GroupApplication)
+	require.NoError(t, err)
+	assert.Equal(t, hybrid.PolicyHybridOR, got.Policy)
+
+	// Channel group without Capabilities yet
+	out, err = InjectJSON([]byte(sampleConfig), GroupChannel, want)
+	require.NoError(t, err)
+	got, err = ReadJSON(out, GroupChannel)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+type constResolver hybrid.Policy
+
+func (c constResolver) ResolvePolicy(string, string) hybrid.Policy { return hybrid.Policy(c) }
+
+func TestResolver(t *testing.T) {
+	r := NewResolver(constResolver(hybrid.PolicyClassical))
+	assert.Equal(t, hybrid.PolicyClassical, r.ResolvePolicy("ch1", "Org1MSP"))
+
+	r.Update("ch1", Settings{Enabled: true, Policy: hybrid.PolicyHybridAND})
+	assert.Equal(t, hybrid.PolicyHybridAND, r.ResolvePolicy("ch1", "Org1MSP"))
+	assert.Equal(t, hybrid.PolicyClassical, r.ResolvePolicy("ch2", "Org1MSP"))
+}
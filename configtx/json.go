@@ -0,0 +1,110 @@
+package configtx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Group selects which capabilities map of the channel config carries the settings
+type Group string
+
+const (
+	// GroupChannel capabilities are checked by peers and orderers
+	GroupChannel Group = "Channel"
+	// GroupApplication capabilities are only checked by peers
+	GroupApplication Group = "Application"
+	// GroupOrderer capabilities are only checked by orderers
+	GroupOrderer Group = "Orderer"
+)
+
+// capabilitiesOf returns the capabilities map of group inside a Config as
+// decoded by configtxlator (common.Config JSON)
+func capabilitiesOf(config map[string]interface{}, group Group, create bool) (map[string]interface{}, error) {
+	channel, ok := config["channel_group"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("config has no channel_group")
+	}
+	g := channel
+	if group != GroupChannel {
+		groups, _ := channel["groups"].(map[string]interface{})
+		g, ok = groups[string(group)].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("config has no %s group", group)
+		}
+	}
+
+	path := []string{"values", "Capabilities", "value", "capabilities"}
+	node := g
+	for _, key := range path {
+		next, ok := node[key].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil, nil
+			}
+			next = map[string]interface{}{}
+			if key == "Capabilities" {
+				next["mod_policy"] = "Admins"
+			}
+			node[key] = next
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// configRoot accepts either a Config or a ConfigBlock-derived config
+// envelope wrapper ({"config": ...}) and returns the Config object
+func configRoot(doc map[string]interface{}) map[string]interface{} {
+	if inner, ok := doc["config"].(map[string]interface{}); ok {
+		return inner
+	}
+	return doc
+}
+
+// InjectJSON sets the hybrid capabilities in a configtxlator JSON config,
+// replacing previous hybrid capabilities. The result is meant for
+// configtxlator compute_update.
+func InjectJSON(configJSON []byte, group Group, s Settings) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(configJSON, &doc); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %w", err)
+	}
+	caps, err := capabilitiesOf(configRoot(doc), group, true)
+	if err != nil {
+		return nil, err
+	}
+	for name := range caps {
+		if isHybridCapability(name) {
+			delete(caps, name)
+		}
+	}
+	for _, name := range s.Capabilities() {
+		caps[name] = map[string]interface{}{}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ReadJSON decodes the hybrid settings of group from a configtxlator JSON config
+func ReadJSON(configJSON []byte, group Group) (Settings, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(configJSON, &doc); err != nil {
+		return Settings{}, fmt.Errorf("invalid config JSON: %w", err)
+	}
+	caps, err := capabilitiesOf(configRoot(doc), group, false)
+	if err != nil {
+		return Settings{}, err
+	}
+	names := make([]string, 0, len(caps))
+	for name := range caps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return SettingsFromCapabilities(names)
+}
+
+func isHybridCapability(name string) bool {
+	return name == CapabilityHybridV1 || strings.HasPrefix(name, capabilityPolicyPrefix)
+}
@@ -0,0 +1,23 @@
+package core
+
+import "errors"
+
+// Build profiles select the ML-DSA backend with one build tag:
+//
+//	oqs         liboqs through cgo, also the default without tags
+//	purego      crypto/mldsa and crypto/mlkem, no cgo (Go 1.27+)
+//	verifyonly  crypto/mldsa verification only, no cgo and no signing
+//
+// Tags of two profiles fail the build, see backend_conflict.go.
+
+// ErrVerifyOnly is returned by the PQC signing functions of a verifyonly
+// build
+var ErrVerifyOnly = errors.New("PQC signing is not compiled in: verifyonly build")
+
+// ErrPQCKeyPairMismatch is returned by NewPQCSignerFromKeyPair when the
+// public key does not belong to the private key
+var ErrPQCKeyPairMismatch = errors.New("ML-DSA public key does not match the private key")
+
+// ErrNoCanaryBackend is returned by VerifyPQCCanary in builds that compile a
+// single ML-DSA backend
+var ErrNoCanaryBackend = errors.New("no canary PQC backend compiled in: liboqs build with Go 1.27+ required")
@@ -0,0 +1,7 @@
+//go:build (oqs && purego) || (oqs && verifyonly) || (purego && verifyonly)
+
+package core
+
+// The oqs, purego and verifyonly tags select one PQC backend each; this fails
+// the build with a readable error instead of duplicate PQC symbols
+var _ = selectExactlyOneOfTheOqsPuregoVerifyonlyBuildTags
@@ -0,0 +1,25 @@
+//go:build verifyonly
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOnlyBackend(t *testing.T) {
+	assert.Equal(t, "verifyonly", PQCBackend)
+	_, err := GenerateKey()
+	assert.ErrorIs(t, err, ErrVerifyOnly)
+	_, err = NewPQCSignerFromPrivate(make([]byte, 32))
+	assert.ErrorIs(t, err, ErrVerifyOnly)
+	_, err = (&PQCSigner{}).Sign([]byte("message"))
+	assert.ErrorIs(t, err, ErrVerifyOnly)
+
+	// Verification works, and rejects malformed keys as the other backends
+	require.True(t, PQCAvailable())
+	_, err = VerifyPQC([]byte("short"), []byte("message"), []byte("signature"))
+	assert.Error(t, err)
+}
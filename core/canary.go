@@ -0,0 +1,30 @@
+//go:build !purego && !verifyonly && go1.27
+
+package core
+
+import (
+	"crypto/mldsa"
+	"errors"
+	"fmt"
+)
+
+// CanaryBackend is the second ML-DSA implementation of liboqs builds:
+// crypto/mldsa, the backend of purego builds. Comparing both on production
+// traffic with VerifyPQCCanary shows whether the default backend can be
+// switched.
+const CanaryBackend = "go"
+
+// VerifyPQCCanary is VerifyPQC with CanaryBackend. Like liboqs it fails
+// for malformed keys and oversized signatures, and returns false for
+// signatures that do not verify.
+func VerifyPQCCanary(publicKey, msg, sig []byte) (bool, error) {
+	params := mldsa.MLDSA65()
+	pk, err := mldsa.NewPublicKey(params, publicKey)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s public key: %w", PQCAlgorithm, err)
+	}
+	if len(sig) > params.SignatureSize() {
+		return false, errors.New("incorrect signature size")
+	}
+	return mldsa.Verify(pk, msg, sig, nil) == nil, nil
+}
@@ -0,0 +1,35 @@
+//go:build !purego && !verifyonly && go1.27
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPQCCanary(t *testing.T) {
+	signer, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer signer.Clean()
+	sig, err := signer.Sign([]byte("message"))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		pub, sig []byte
+	}{
+		{"valid", signer.PublicKey(), sig},
+		{"tampered", signer.PublicKey(), append([]byte{sig[0] ^ 1}, sig[1:]...)},
+		{"truncated key", signer.PublicKey()[:100], sig},
+		{"oversized signature", signer.PublicKey(), append(sig, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			valid, err := VerifyPQC(tc.pub, []byte("message"), tc.sig)
+			canaryValid, canaryErr := VerifyPQCCanary(tc.pub, []byte("message"), tc.sig)
+			assert.Equal(t, valid, canaryValid)
+			assert.Equal(t, err != nil, canaryErr != nil)
+		})
+	}
+}
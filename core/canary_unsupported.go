@@ -0,0 +1,12 @@
+//go:build purego || verifyonly || !go1.27
+
+package core
+
+// CanaryBackend is empty: purego and verifyonly builds link no liboqs to
+// compare crypto/mldsa with
+const CanaryBackend = ""
+
+// VerifyPQCCanary fails with ErrNoCanaryBackend
+func VerifyPQCCanary(publicKey, msg, sig []byte) (bool, error) {
+	return false, ErrNoCanaryBackend
+}
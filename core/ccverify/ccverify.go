@@ -0,0 +1,148 @@
+// Package ccverify verifies hybrid signatures inside chaincode, for
+// application-level approval workflows: approvers sign a document off-chain,
+// the signatures reach the contract in transient data or world state, and
+// the contract checks them against public keys recorded on the ledger.
+//
+// It depends on core and the standard library only, and takes the stub
+// through the two methods it reads, so it does not pull in the Fabric shim.
+// Chaincode built with -tags verifyonly links no cgo and no signing code.
+package ccverify
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+var (
+	// ErrInvalidSignature is returned for signatures that do not verify
+	ErrInvalidSignature = errors.New("invalid hybrid signature")
+	// ErrNotFound is returned for missing state or transient keys
+	ErrNotFound = errors.New("not found")
+	// ErrInsufficientApprovals is returned when fewer approvers than the
+	// threshold signed
+	ErrInsufficientApprovals = errors.New("insufficient approvals")
+)
+
+// StateReader reads world state, e.g. shim.ChaincodeStubInterface
+type StateReader interface {
+	GetState(key string) ([]byte, error)
+}
+
+// TransientReader reads the transient map of the proposal, e.g.
+// shim.ChaincodeStubInterface
+type TransientReader interface {
+	GetTransient() (map[string][]byte, error)
+}
+
+// ParsePublicKey accepts a core.PublicKeyJSON document, a PEM PUBLIC KEY
+// block holding a composite key, or the DER composite key
+func ParsePublicKey(data []byte) (*core.PublicKey, error) {
+	if j, err := core.ParsePublicKeyJSON(data); err == nil {
+		return j.PublicKey()
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block %q, expected PUBLIC KEY", block.Type)
+		}
+		data = block.Bytes
+	}
+	return core.ParsePublicKey(data)
+}
+
+// Verify checks signature over the SHA-256 digest of message under policy,
+// as signed by the hybrid provider and by core.PrivateKey.Sign
+func Verify(pub *core.PublicKey, message, signature []byte, policy core.Policy) error {
+	digest := sha256.Sum256(message)
+	return VerifyDigest(pub, digest[:], signature, policy)
+}
+
+// VerifyDigest is Verify for a SHA-256 digest computed by the caller, e.g.
+// over a payload too large to hold in memory
+func VerifyDigest(pub *core.PublicKey, digest, signature []byte, policy core.Policy) error {
+	valid, err := pub.Verify(digest, signature, policy)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// PublicKeyFromState parses the public key stored under key
+func PublicKeyFromState(state StateReader, key string) (*core.PublicKey, error) {
+	raw, err := state.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", key, err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("public key %s: %w", key, ErrNotFound)
+	}
+	pub, err := ParsePublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("public key %s: %w", key, err)
+	}
+	return pub, nil
+}
+
+// Transient returns the value of the transient map under key
+func Transient(stub TransientReader, key string) ([]byte, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %w", err)
+	}
+	v, ok := transient[key]
+	if !ok || len(v) == 0 {
+		return nil, fmt.Errorf("transient %s: %w", key, ErrNotFound)
+	}
+	return v, nil
+}
+
+// Approvals is an m-of-n approval rule over a document
+type Approvals struct {
+	// Approvers maps approver IDs to their public keys
+	Approvers map[string]*core.PublicKey
+	// Threshold is the number of distinct approvers required, all of them
+	// if zero
+	Threshold int
+	// Policy is the signature policy, PolicyHybridAND by default
+	Policy core.Policy
+}
+
+// Verify returns the sorted IDs of the approvers whose signature over
+// message, in signatures by approver ID, is valid. It fails with
+// ErrInsufficientApprovals below the threshold, wrapping the errors of the
+// rejected signatures. Signatures of unknown approvers are ignored.
+func (a *Approvals) Verify(message []byte, signatures map[string][]byte) ([]string, error) {
+	threshold := a.Threshold
+	if threshold <= 0 {
+		threshold = len(a.Approvers)
+	}
+	if threshold == 0 {
+		return nil, errors.New("no approvers")
+	}
+	var approved []string
+	var errs []error
+	for id, sig := range signatures {
+		pub, ok := a.Approvers[id]
+		if !ok {
+			continue
+		}
+		if err := Verify(pub, message, sig, a.Policy); err != nil {
+			errs = append(errs, fmt.Errorf("approver %s: %w", id, err))
+			continue
+		}
+		approved = append(approved, id)
+	}
+	sort.Strings(approved)
+	if len(approved) < threshold {
+		err := fmt.Errorf("%w: %d of %d required", ErrInsufficientApprovals, len(approved), threshold)
+		return approved, errors.Join(append([]error{err}, errs...)...)
+	}
+	return approved, nil
+}
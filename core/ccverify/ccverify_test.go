@@ -0,0 +1,104 @@
+package ccverify
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// stub is the part of a chaincode stub ccverify reads
+type stub struct {
+	state     map[string][]byte
+	transient map[string][]byte
+}
+
+func (s *stub) GetState(key string) ([]byte, error) { return s.state[key], nil }
+
+func (s *stub) GetTransient() (map[string][]byte, error) { return s.transient, nil }
+
+func newApprover(t *testing.T) (*core.PrivateKey, []byte) {
+	k, err := core.GenerateKey()
+	require.NoError(t, err)
+	t.Cleanup(k.Clean)
+	der, err := k.Public().Marshal()
+	require.NoError(t, err)
+	return k, der
+}
+
+func sign(t *testing.T, k *core.PrivateKey, message []byte) []byte {
+	digest := sha256.Sum256(message)
+	sig, err := k.Sign(digest[:])
+	require.NoError(t, err)
+	return sig
+}
+
+func TestVerifyFromStub(t *testing.T) {
+	k, der := newApprover(t)
+	keyJSON, err := core.MarshalPublicKeyJSON(der, time.Now())
+	require.NoError(t, err)
+	doc := []byte(`{"invoice":42,"amount":"1000.00"}`)
+	s := &stub{
+		state: map[string][]byte{
+			"key~json": keyJSON,
+			"key~pem":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+			"key~der":  der,
+		},
+		transient: map[string][]byte{"signature": sign(t, k, doc)},
+	}
+
+	sig, err := Transient(s, "signature")
+	require.NoError(t, err)
+	for _, key := range []string{"key~json", "key~pem", "key~der"} {
+		pub, err := PublicKeyFromState(s, key)
+		require.NoError(t, err, key)
+		assert.NoError(t, Verify(pub, doc, sig, core.PolicyHybridAND), key)
+		assert.ErrorIs(t, Verify(pub, []byte("tampered"), sig, core.PolicyHybridAND), ErrInvalidSignature)
+	}
+
+	_, err = PublicKeyFromState(s, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Transient(s, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	assert.Error(t, err)
+	pub, _ := ParsePublicKey(der)
+	assert.ErrorIs(t, Verify(pub, doc, []byte("garbage"), core.PolicyHybridAND), ErrInvalidSignature)
+}
+
+func TestApprovals(t *testing.T) {
+	doc := []byte("release v2 of the asset contract")
+	a := &Approvals{Approvers: map[string]*core.PublicKey{}, Threshold: 2}
+	signatures := map[string][]byte{}
+	for _, id := range []string{"alice", "bob", "carol"} {
+		k, _ := newApprover(t)
+		a.Approvers[id] = k.Public()
+		signatures[id] = sign(t, k, doc)
+	}
+	stranger, _ := newApprover(t)
+	signatures["mallory"] = sign(t, stranger, doc)
+
+	approved, err := a.Verify(doc, signatures)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, approved)
+
+	// Signatures by someone else's key do not count
+	signatures["bob"] = signatures["mallory"]
+	signatures["carol"] = sign(t, stranger, doc)
+	approved, err = a.Verify(doc, signatures)
+	assert.True(t, errors.Is(err, ErrInsufficientApprovals), err)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.Equal(t, []string{"alice"}, approved)
+
+	// Zero threshold requires every approver
+	a.Threshold = 0
+	_, err = a.Verify(doc, map[string][]byte{"alice": sign(t, stranger, doc)})
+	assert.ErrorIs(t, err, ErrInsufficientApprovals)
+	_, err = (&Approvals{}).Verify(doc, signatures)
+	assert.Error(t, err)
+}
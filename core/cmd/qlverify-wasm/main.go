@@ -0,0 +1,14 @@
+//go:build js && wasm
+
+// qlverify-wasm is the WebAssembly module of the Explorer: it installs the
+// qlverify object of wasmverify.Register and keeps running so JavaScript
+// can call it. Build it with tools/scripts/build_wasm.sh, which enforces
+// the size budget.
+package main
+
+import "github.com/yourusername/quantum-ledger/core/wasmverify"
+
+func main() {
+	wasmverify.Register()
+	select {}
+}
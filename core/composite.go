@@ -0,0 +1,66 @@
+package core
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// PQC algorithm identifiers used in SubjectPublicKeyInfo (NIST CSOR)
+var pqcOIDs = map[string]asn1.ObjectIdentifier{
+	"ML-DSA-44": {2, 16, 840, 1, 101, 3, 4, 3, 17},
+	"ML-DSA-65": {2, 16, 840, 1, 101, 3, 4, 3, 18},
+	"ML-DSA-87": {2, 16, 840, 1, 101, 3, 4, 3, 19},
+}
+
+// PQCAlgorithmOID returns the object identifier of a PQC signature algorithm
+func PQCAlgorithmOID(name string) (asn1.ObjectIdentifier, bool) {
+	oid, ok := pqcOIDs[name]
+	return oid, ok
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// compositePublicKey is SEQUENCE { ecdsa SubjectPublicKeyInfo, pqc SubjectPublicKeyInfo }
+type compositePublicKey struct {
+	ECDSA asn1.RawValue
+	PQC   subjectPublicKeyInfo
+}
+
+// MarshalCompositePublicKey encodes an ECDSA SubjectPublicKeyInfo and an
+// ML-DSA public key as a DER composite public key, e.g. from a composite certificate
+func MarshalCompositePublicKey(ecdsaSPKI, pqcPub []byte) ([]byte, error) {
+	if len(pqcPub) == 0 {
+		return nil, errors.New("PQC public key is empty")
+	}
+	return asn1.Marshal(compositePublicKey{
+		ECDSA: asn1.RawValue{FullBytes: ecdsaSPKI},
+		PQC: subjectPublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: pqcOIDs[PQCAlgorithm]},
+			PublicKey: asn1.BitString{Bytes: pqcPub, BitLength: 8 * len(pqcPub)},
+		},
+	})
+}
+
+// ParseCompositePublicKey splits a composite public key into its ECDSA SPKI and PQC public key
+func ParseCompositePublicKey(der []byte) (ecdsaDER, pqcPub []byte, err error) {
+	var composite compositePublicKey
+	rest, err := asn1.Unmarshal(der, &composite)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid composite public key: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.New("invalid composite public key: trailing data")
+	}
+	if !composite.PQC.Algorithm.Algorithm.Equal(pqcOIDs[PQCAlgorithm]) {
+		return nil, nil, fmt.Errorf("unsupported PQC algorithm %s, expected %s", composite.PQC.Algorithm.Algorithm, PQCAlgorithm)
+	}
+	if len(composite.PQC.PublicKey.Bytes) == 0 {
+		return nil, nil, errors.New("PQC public key is empty")
+	}
+	return composite.ECDSA.FullBytes, composite.PQC.PublicKey.Bytes, nil
+}
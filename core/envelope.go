@@ -0,0 +1,316 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Envelope versions. Legacy v1 envelopes have no version byte and start with
+// the big-endian ECDSA length, so their first byte is always 0x00.
+// EnvelopeV2LE is the v2 layout with a little-endian ECDSA length, for
+// analysis tools that cannot read the standard one.
+const (
+	EnvelopeV1   byte = 0x00
+	EnvelopeV2   byte = 0x02
+	EnvelopeV2LE byte = 0x03
+)
+
+// EnvelopeFormat selects the envelope Sign emits
+type EnvelopeFormat byte
+
+const (
+	// FormatStandard emits v2 envelopes with a big-endian length
+	FormatStandard = EnvelopeFormat(EnvelopeV2)
+	// FormatLittleEndian emits v2 envelopes with a little-endian length
+	FormatLittleEndian = EnvelopeFormat(EnvelopeV2LE)
+)
+
+var formatNames = map[EnvelopeFormat]string{
+	FormatStandard:     "standard",
+	FormatLittleEndian: "little-endian",
+}
+
+func (f EnvelopeFormat) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("EnvelopeFormat(%#x)", byte(f))
+}
+
+// ParseEnvelopeFormat parses standard or little-endian (case insensitive)
+func ParseEnvelopeFormat(s string) (EnvelopeFormat, error) {
+	for f, name := range formatNames {
+		if strings.EqualFold(s, name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown envelope format %q", s)
+}
+
+// Valid reports whether f is a known format
+func (f EnvelopeFormat) Valid() bool {
+	_, ok := formatNames[f]
+	return ok
+}
+
+// envelopeDomain separates hybrid component signatures from plain ones
+const envelopeDomain = "QLHYB"
+
+// Modes is the set of signature components a signer offered
+type Modes byte
+
+const (
+	// ModeClassical means the signer produced an ECDSA component
+	ModeClassical Modes = 1 << iota
+	// ModePQC means the signer produced a PQC component
+	ModePQC
+	// ModePure means the PQC component signed the message rather than the
+	// digest (pure ML-DSA)
+	ModePure
+)
+
+// Has reports whether all modes in m are offered
+func (m Modes) Has(mode Modes) bool {
+	return m&mode == mode
+}
+
+// Valid reports whether m offers at least one component and ModePure only
+// with ModePQC
+func (m Modes) Valid() bool {
+	return m&^ModePure != 0 && m&^(ModeClassical|ModePQC|ModePure) == 0 && (!m.Has(ModePure) || m.Has(ModePQC))
+}
+
+func (m Modes) String() string {
+	suffix := ""
+	if m.Has(ModePQC | ModePure) {
+		m, suffix = m&^ModePure, "+pure"
+	}
+	switch m {
+	case ModeClassical:
+		return "classical" + suffix
+	case ModePQC:
+		return "pqc" + suffix
+	case ModeClassical | ModePQC:
+		return "hybrid" + suffix
+	}
+	return fmt.Sprintf("Modes(%#x)", byte(m))
+}
+
+// ErrDowngrade is returned when an envelope lacks a component its signer offered
+var ErrDowngrade = errors.New("hybrid signature downgrade detected")
+
+// Errors returned for envelopes that cannot be parsed or built
+var (
+	// ErrMalformedEnvelope is returned for truncated envelopes and invalid
+	// headers or length prefixes
+	ErrMalformedEnvelope = errors.New("malformed signature envelope")
+	// ErrComponentTooLarge is returned for a component over its
+	// EnvelopeLimits maximum
+	ErrComponentTooLarge = errors.New("signature component too large")
+)
+
+// ComponentSizeError reports a component over its EnvelopeLimits maximum
+type ComponentSizeError struct {
+	// Component is "ECDSA" or "PQC"
+	Component string
+	Size      int
+	Max       int
+}
+
+func (e *ComponentSizeError) Error() string {
+	return fmt.Sprintf("%s signature component is %d bytes, the limit is %d", e.Component, e.Size, e.Max)
+}
+
+// Is makes errors.Is(err, ErrComponentTooLarge) true
+func (e *ComponentSizeError) Is(target error) bool {
+	return target == ErrComponentTooLarge
+}
+
+// EnvelopeLimits bounds the size of envelope components; zero disables a
+// bound
+type EnvelopeLimits struct {
+	MaxECDSA int
+	MaxPQC   int
+}
+
+// DefaultEnvelopeLimits leave room for DER ECDSA signatures up to P-521
+// (139 bytes) and the largest ML-DSA and SLH-DSA signatures
+var DefaultEnvelopeLimits = EnvelopeLimits{MaxECDSA: 150, MaxPQC: 64 << 10}
+
+// Check fails with a *ComponentSizeError if a component of e is over its
+// maximum
+func (l EnvelopeLimits) Check(e *Envelope) error {
+	return l.check(len(e.ECDSASignature), len(e.PQCSignature))
+}
+
+func (l EnvelopeLimits) check(ecdsaLen, pqcLen int) error {
+	if l.MaxECDSA > 0 && ecdsaLen > l.MaxECDSA {
+		return &ComponentSizeError{Component: "ECDSA", Size: ecdsaLen, Max: l.MaxECDSA}
+	}
+	if l.MaxPQC > 0 && pqcLen > l.MaxPQC {
+		return &ComponentSizeError{Component: "PQC", Size: pqcLen, Max: l.MaxPQC}
+	}
+	return nil
+}
+
+// Envelope is a parsed hybrid signature
+type Envelope struct {
+	Version        byte
+	Modes          Modes
+	ECDSASignature []byte
+	PQCSignature   []byte
+}
+
+// Marshal creates v2: [version][modes][4 bytes ECDSA len][ECDSA sig][PQC sig]
+func (e *Envelope) Marshal() []byte {
+	return e.AppendMarshal(make([]byte, 0, e.Size()))
+}
+
+// AppendMarshal appends the encoding of e to dst. It does not allocate when
+// dst has Size bytes of spare capacity.
+func (e *Envelope) AppendMarshal(dst []byte) []byte {
+	if e.Version != EnvelopeV1 {
+		dst = append(dst, e.Version, byte(e.Modes))
+	}
+	return appendComponents(dst, e.byteOrder(), e.ECDSASignature, e.PQCSignature)
+}
+
+// Size is the length of the encoding of e
+func (e *Envelope) Size() int {
+	n := 4 + len(e.ECDSASignature) + len(e.PQCSignature)
+	if e.Version != EnvelopeV1 {
+		n += 2
+	}
+	return n
+}
+
+// lengthOrder reads and appends the ECDSA length prefix
+type lengthOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// byteOrder returns the order of the ECDSA length prefix
+func (e *Envelope) byteOrder() lengthOrder {
+	if e.Version == EnvelopeV2LE {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// SignedMessages returns what the ECDSA and PQC components sign for digest.
+// v2 components sign [domain][version][modes][digest] so the header cannot be altered.
+// With ModePure the PQC component signs [domain][version][modes][message].
+func (e *Envelope) SignedMessages(digest, message []byte) (ecdsaMsg, pqcMsg []byte) {
+	if e.Version == EnvelopeV1 {
+		return digest, digest
+	}
+	bound := e.bind(digest)
+	h := sha256.Sum256(bound)
+	if e.Modes.Has(ModePure) {
+		return h[:], e.bind(message)
+	}
+	return h[:], bound
+}
+
+func (e *Envelope) bind(b []byte) []byte {
+	bound := make([]byte, 0, len(envelopeDomain)+2+len(b))
+	bound = append(bound, envelopeDomain...)
+	bound = append(bound, e.Version, byte(e.Modes))
+	return append(bound, b...)
+}
+
+// CheckDowngrade fails if a component the signer offered is missing, or if a
+// PQC-capable key produced an envelope without the PQC component. Legacy v1
+// envelopes carry no modes; their signers always produced both components,
+// so one missing from a v1 envelope is a downgrade too.
+func (e *Envelope) CheckDowngrade(pqcCapable bool) error {
+	if e.Version == EnvelopeV1 && (len(e.ECDSASignature) == 0 || len(e.PQCSignature) == 0) {
+		return fmt.Errorf("%w: legacy envelope without both components", ErrDowngrade)
+	}
+	if e.Modes.Has(ModePQC) && len(e.PQCSignature) == 0 {
+		return fmt.Errorf("%w: PQC component offered but missing", ErrDowngrade)
+	}
+	if e.Modes.Has(ModeClassical) && len(e.ECDSASignature) == 0 {
+		return fmt.Errorf("%w: ECDSA component offered but missing", ErrDowngrade)
+	}
+	if pqcCapable && !e.Modes.Has(ModePQC) {
+		return fmt.Errorf("%w: PQC-capable key signed %s only", ErrDowngrade, e.Modes)
+	}
+	return nil
+}
+
+// ParseEnvelope accepts v2 envelopes in either byte order and legacy v1 ones,
+// with DefaultEnvelopeLimits
+func ParseEnvelope(signature []byte) (*Envelope, error) {
+	return ParseEnvelopeLimits(signature, DefaultEnvelopeLimits)
+}
+
+// ParseEnvelopeLimits is ParseEnvelope with the given limits. Oversize
+// components are rejected from the length prefix, before any copy.
+func ParseEnvelopeLimits(signature []byte, limits EnvelopeLimits) (*Envelope, error) {
+	if len(signature) == 0 {
+		return nil, fmt.Errorf("%w: signature too short", ErrMalformedEnvelope)
+	}
+	switch signature[0] {
+	case EnvelopeV1:
+		ecdsaSig, pqcSig, err := parseHybridSignature(signature, limits)
+		if err != nil {
+			return nil, err
+		}
+		return &Envelope{Version: EnvelopeV1, Modes: ModeClassical | ModePQC, ECDSASignature: ecdsaSig, PQCSignature: pqcSig}, nil
+	case EnvelopeV2, EnvelopeV2LE:
+		if len(signature) < 2 {
+			return nil, fmt.Errorf("%w: signature too short", ErrMalformedEnvelope)
+		}
+		modes := Modes(signature[1])
+		if !modes.Valid() {
+			return nil, fmt.Errorf("%w: invalid signature modes %#x", ErrMalformedEnvelope, byte(modes))
+		}
+		env := &Envelope{Version: signature[0], Modes: modes}
+		ecdsaSig, pqcSig, err := parseHybridSignatureOrder(env.byteOrder(), signature[2:], limits)
+		if err != nil {
+			return nil, err
+		}
+		env.ECDSASignature, env.PQCSignature = ecdsaSig, pqcSig
+		return env, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported signature envelope version %#x", ErrMalformedEnvelope, signature[0])
+	}
+}
+
+// appendComponents appends [4 bytes ECDSA len][ECDSA sig][PQC sig]
+func appendComponents(dst []byte, order lengthOrder, ecdsaSig, pqcSig []byte) []byte {
+	dst = order.AppendUint32(dst, uint32(len(ecdsaSig)))
+	dst = append(dst, ecdsaSig...)
+	return append(dst, pqcSig...)
+}
+
+// parseHybridSignature splits combined signature
+func parseHybridSignature(signature []byte, limits EnvelopeLimits) (ecdsaSig, pqcSig []byte, err error) {
+	return parseHybridSignatureOrder(binary.BigEndian, signature, limits)
+}
+
+// parseHybridSignatureOrder is parseHybridSignature with the given length byte order
+func parseHybridSignatureOrder(order binary.ByteOrder, signature []byte, limits EnvelopeLimits) (ecdsaSig, pqcSig []byte, err error) {
+	if len(signature) < 4 {
+		return nil, nil, fmt.Errorf("%w: signature too short", ErrMalformedEnvelope)
+	}
+
+	ecdsaLen := order.Uint32(signature[:4])
+	if ecdsaLen > uint32(len(signature)-4) {
+		return nil, nil, fmt.Errorf("%w: ECDSA length %d exceeds signature size", ErrMalformedEnvelope, ecdsaLen)
+	}
+	pqcLen := len(signature) - 4 - int(ecdsaLen)
+	if err := limits.check(int(ecdsaLen), pqcLen); err != nil {
+		return nil, nil, err
+	}
+
+	ecdsaSig = signature[4 : 4+ecdsaLen]
+	pqcSig = signature[4+ecdsaLen:]
+
+	return ecdsaSig, pqcSig, nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeLimits(t *testing.T) {
+	env := &Envelope{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: make([]byte, 72), PQCSignature: make([]byte, 3309)}
+	require.NoError(t, DefaultEnvelopeLimits.Check(env))
+	_, err := ParseEnvelope(env.Marshal())
+	require.NoError(t, err)
+
+	limits := EnvelopeLimits{MaxECDSA: 64, MaxPQC: 4096}
+	err = limits.Check(env)
+	require.ErrorIs(t, err, ErrComponentTooLarge)
+	var sizeErr *ComponentSizeError
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, ComponentSizeError{Component: "ECDSA", Size: 72, Max: 64}, *sizeErr)
+	_, err = ParseEnvelopeLimits(env.Marshal(), limits)
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, "ECDSA", sizeErr.Component)
+
+	env.PQCSignature = make([]byte, DefaultEnvelopeLimits.MaxPQC+1)
+	_, err = ParseEnvelope(env.Marshal())
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, ComponentSizeError{Component: "PQC", Size: DefaultEnvelopeLimits.MaxPQC + 1, Max: DefaultEnvelopeLimits.MaxPQC}, *sizeErr)
+	_, err = ParseEnvelopeLimits(env.Marshal(), EnvelopeLimits{})
+	assert.NoError(t, err, "zero limits disable the bounds")
+}
+
+// TestEnvelopeEncodingGolden pins the envelope bytes: datasets and fixtures
+// store them, so they must not depend on the machine writing them
+func TestEnvelopeEncodingGolden(t *testing.T) {
+	for _, tc := range []struct {
+		env  Envelope
+		want []byte
+	}{
+		{Envelope{Version: EnvelopeV1, ECDSASignature: []byte{0xAA}, PQCSignature: []byte{0xBB, 0xCC}},
+			[]byte{0, 0, 0, 1, 0xAA, 0xBB, 0xCC}},
+		{Envelope{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{0xAA}, PQCSignature: []byte{0xBB}},
+			[]byte{EnvelopeV2, 3, 0, 0, 0, 1, 0xAA, 0xBB}},
+		{Envelope{Version: EnvelopeV2LE, Modes: ModeClassical | ModePQC, ECDSASignature: []byte{0xAA}, PQCSignature: []byte{0xBB}},
+			[]byte{EnvelopeV2LE, 3, 1, 0, 0, 0, 0xAA, 0xBB}},
+	} {
+		got := tc.env.Marshal()
+		assert.Equal(t, tc.want, got)
+		parsed, err := ParseEnvelope(got)
+		require.NoError(t, err)
+		assert.Equal(t, got, parsed.Marshal(), "re-encoding is byte-identical")
+	}
+}
+
+func TestParseEnvelopeMalformed(t *testing.T) {
+	for name, signature := range map[string][]byte{
+		"empty":              {},
+		"v2 without modes":   {EnvelopeV2},
+		"no modes":           {EnvelopeV2, 0, 0, 0, 0, 0},
+		"unknown modes":      {EnvelopeV2, 0x80, 0, 0, 0, 0},
+		"pure without PQC":   {EnvelopeV2, byte(ModeClassical | ModePure), 0, 0, 0, 0},
+		"unknown version":    {0x7F, 0, 0, 0, 0},
+		"truncated length":   {EnvelopeV2, byte(ModePQC), 0, 0},
+		"length past end":    {EnvelopeV2LE, byte(ModeClassical), 5, 0, 0, 0, 1},
+		"v1 maximum length":  {0, 0xFF, 0xFF, 0xFF, 1},
+		"v2 maximum length":  {EnvelopeV2, byte(ModeClassical), 0xFF, 0xFF, 0xFF, 0xFF},
+		"v1 truncated":       {0, 0, 0},
+		"v2le short by one":  {EnvelopeV2LE, byte(ModeClassical | ModePQC), 2, 0, 0, 0, 1},
+		"v1 ECDSA overflows": {0, 0, 0, 0xFB, 1, 2, 3},
+	} {
+		_, err := ParseEnvelope(signature)
+		assert.ErrorIs(t, err, ErrMalformedEnvelope, name)
+	}
+}
+
+// FuzzParseEnvelope checks that ParseEnvelope fails with a typed error or
+// returns an envelope within the limits that encodes back to its input.
+// Regression inputs found by fuzzing are kept in testdata/fuzz.
+func FuzzParseEnvelope(f *testing.F) {
+	for _, env := range []*Envelope{
+		{Version: EnvelopeV1, ECDSASignature: make([]byte, 72), PQCSignature: make([]byte, 3309)},
+		{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: make([]byte, 72), PQCSignature: make([]byte, 3309)},
+		{Version: EnvelopeV2LE, Modes: ModeClassical | ModePQC, ECDSASignature: make([]byte, 71), PQCSignature: make([]byte, 3309)},
+		{Version: EnvelopeV2, Modes: ModePQC | ModePure, PQCSignature: make([]byte, 3309)},
+	} {
+		f.Add(env.Marshal())
+	}
+	f.Fuzz(func(t *testing.T, signature []byte) {
+		env, err := ParseEnvelope(signature)
+		if err != nil {
+			if !errors.Is(err, ErrMalformedEnvelope) && !errors.Is(err, ErrComponentTooLarge) {
+				t.Fatalf("untyped error: %v", err)
+			}
+			return
+		}
+		if err := DefaultEnvelopeLimits.Check(env); err != nil {
+			t.Fatalf("parsed envelope over the limits: %v", err)
+		}
+		if !bytes.Equal(env.Marshal(), signature) {
+			t.Fatalf("envelope %x encodes back to %x", signature, env.Marshal())
+		}
+	})
+}
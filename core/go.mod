@@ -0,0 +1,14 @@
+module github.com/yourusername/quantum-ledger/core
+
+go 1.22.0
+
+require (
+	github.com/open-quantum-safe/liboqs-go v0.0.0-20250119172907-28b5301df438
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
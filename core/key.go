@@ -0,0 +1,216 @@
+// Package core implements the hybrid ECDSA P-256 + ML-DSA-65 signature scheme:
+// keys, signing, envelopes and composite public keys. It has no Fabric
+// dependency, the BCCSP and MSP adapters live in the root module.
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+)
+
+// PrivateKey is a hybrid signing key
+type PrivateKey struct {
+	ECDSA *ecdsa.PrivateKey
+	PQC   *PQCSigner
+
+	// locked is the ECDSA scalar, if locked by SetMemoryLocking; the
+	// garbage collector unlocks it for keys never cleaned
+	locked []byte
+}
+
+// PublicKey is the public half of a hybrid key
+type PublicKey struct {
+	ECDSA *ecdsa.PublicKey
+	PQC   []byte
+}
+
+// GenerateKey creates a P-256 + ML-DSA-65 key pair. Call Clean when done.
+func GenerateKey() (*PrivateKey, error) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	pqc, err := NewPQCSigner()
+	if err != nil {
+		return nil, err
+	}
+	k := &PrivateKey{ECDSA: ecdsaKey, PQC: pqc}
+	if k.locked = LockSecret(ECDSASecret(ecdsaKey)); k.locked != nil {
+		runtime.SetFinalizer(k, func(k *PrivateKey) { UnlockSecret(k.locked) })
+	}
+	return k, nil
+}
+
+// Public returns the public half of k
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{ECDSA: &k.ECDSA.PublicKey, PQC: k.PQC.PublicKey()}
+}
+
+// Clean releases the PQC signer and unlocks the memory of the key
+func (k *PrivateKey) Clean() {
+	if k.PQC != nil {
+		k.PQC.Clean()
+	}
+	if k.locked != nil {
+		UnlockSecret(k.locked)
+		k.locked = nil
+		runtime.SetFinalizer(k, nil)
+	}
+}
+
+// MaxSignatureSize bounds a P-256 + ML-DSA-65 v2 envelope: header, length,
+// a DER ECDSA signature of at most 72 bytes and a 3309-byte ML-DSA signature
+const MaxSignatureSize = 2 + 4 + 72 + 3309
+
+// Sign signs digest with both components and returns a standard v2 envelope,
+// byte-compatible with the signatures of the Fabric BCCSP provider
+func (k *PrivateKey) Sign(digest []byte) ([]byte, error) {
+	return k.AppendSignature(make([]byte, 0, MaxSignatureSize), digest)
+}
+
+// AppendSignature is Sign appending the envelope to dst. The envelope itself
+// is written in place; what remains allocated is inside ECDSA and liboqs.
+func (k *PrivateKey) AppendSignature(dst, digest []byte) ([]byte, error) {
+	if k == nil || k.ECDSA == nil || k.PQC == nil {
+		return nil, errors.New("incomplete hybrid private key")
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("empty digest")
+	}
+	env := &Envelope{Version: EnvelopeV2, Modes: ModeClassical | ModePQC}
+	ecdsaMsg, pqcMsg := env.SignedMessages(digest, nil)
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.ECDSA, ecdsaMsg)
+	if err != nil {
+		return nil, fmt.Errorf("ECDSA signature failed: %w", err)
+	}
+	if s.Cmp(halfOrder(k.ECDSA.Curve)) > 0 {
+		s.Sub(k.ECDSA.Params().N, s)
+	}
+	if env.ECDSASignature, err = asn1.Marshal(ecdsaSignature{r, s}); err != nil {
+		return nil, err
+	}
+	if env.PQCSignature, err = k.PQC.Sign(pqcMsg); err != nil {
+		return nil, err
+	}
+	return env.AppendMarshal(dst), nil
+}
+
+// Verify checks signature over digest under policy. Envelopes without a
+// component the key supports are rejected as downgrades. Pure ML-DSA
+// envelopes sign the message rather than the digest and are not accepted.
+func (k *PublicKey) Verify(digest, signature []byte, policy Policy) (bool, error) {
+	return k.VerifyWith(nil, digest, signature, policy)
+}
+
+// VerifyWith is Verify reusing v, an initialized verifier of k.PQC, for
+// the ML-DSA component; a nil v initializes one for this call
+func (k *PublicKey) VerifyWith(v *PQCVerifier, digest, signature []byte, policy Policy) (bool, error) {
+	if len(digest) == 0 {
+		return false, errors.New("empty digest")
+	}
+	env, err := ParseEnvelope(signature)
+	if err != nil {
+		return false, fmt.Errorf("invalid hybrid signature: %w", err)
+	}
+	if err := env.CheckDowngrade(len(k.PQC) > 0); err != nil {
+		return false, err
+	}
+	if env.Modes.Has(ModePure) {
+		return false, errors.New("pure ML-DSA signatures require the message")
+	}
+	ecdsaMsg, pqcMsg := env.SignedMessages(digest, nil)
+
+	switch policy {
+	case PolicyHybridAND:
+		if !k.verifyECDSA(env.ECDSASignature, ecdsaMsg) {
+			return false, nil
+		}
+		return k.verifyPQC(v, env.PQCSignature, pqcMsg)
+	case PolicyHybridOR:
+		if k.verifyECDSA(env.ECDSASignature, ecdsaMsg) {
+			return true, nil
+		}
+		return k.verifyPQC(v, env.PQCSignature, pqcMsg)
+	case PolicyClassical:
+		return k.verifyECDSA(env.ECDSASignature, ecdsaMsg), nil
+	case PolicyPQC:
+		return k.verifyPQC(v, env.PQCSignature, pqcMsg)
+	default:
+		return false, fmt.Errorf("unsupported signature policy %s", policy)
+	}
+}
+
+// verifyECDSA accepts low-S signatures only, as Fabric does
+func (k *PublicKey) verifyECDSA(signature, msg []byte) bool {
+	if k.ECDSA == nil || len(signature) == 0 {
+		return false
+	}
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil || len(rest) != 0 || sig.R == nil || sig.S == nil {
+		return false
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(halfOrder(k.ECDSA.Curve)) > 0 {
+		return false
+	}
+	return ecdsa.Verify(k.ECDSA, msg, sig.R, sig.S)
+}
+
+func (k *PublicKey) verifyPQC(v *PQCVerifier, signature, msg []byte) (bool, error) {
+	if len(k.PQC) == 0 || len(signature) == 0 {
+		return false, nil
+	}
+	var valid bool
+	var err error
+	if v != nil {
+		valid, err = v.Verify(msg, signature)
+	} else {
+		valid, err = VerifyPQC(k.PQC, msg, signature)
+	}
+	if err != nil {
+		return false, fmt.Errorf("PQC verification failed: %w", err)
+	}
+	return valid, nil
+}
+
+// Marshal encodes k as a DER composite public key
+func (k *PublicKey) Marshal() ([]byte, error) {
+	ecdsaDER, err := x509.MarshalPKIXPublicKey(k.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ECDSA public key: %w", err)
+	}
+	return MarshalCompositePublicKey(ecdsaDER, k.PQC)
+}
+
+// ParsePublicKey decodes a DER composite public key
+func ParsePublicKey(der []byte) (*PublicKey, error) {
+	ecdsaDER, pqcPub, err := ParseCompositePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := x509.ParsePKIXPublicKey(ecdsaDER)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA public key: %w", err)
+	}
+	ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unexpected classical key type %T", pub)
+	}
+	return &PublicKey{ECDSA: ecdsaPub, PQC: append([]byte(nil), pqcPub...)}, nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func halfOrder(c elliptic.Curve) *big.Int {
+	return new(big.Int).Rsh(c.Params().N, 1)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+
+	digest := sha256.Sum256([]byte("core"))
+	signature, err := priv.Sign(digest[:])
+	require.NoError(t, err)
+	assert.Equal(t, EnvelopeV2, signature[0])
+
+	der, err := priv.Public().Marshal()
+	require.NoError(t, err)
+	pub, err := ParsePublicKey(der)
+	require.NoError(t, err)
+
+	for _, policy := range []Policy{PolicyHybridAND, PolicyHybridOR, PolicyClassical, PolicyPQC} {
+		valid, err := pub.Verify(digest[:], signature, policy)
+		require.NoError(t, err, policy)
+		assert.True(t, valid, policy)
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	valid, err := pub.Verify(other[:], signature, PolicyHybridAND)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestVerifyRejectsDowngradeAndHighS(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	pub := priv.Public()
+
+	digest := sha256.Sum256([]byte("core"))
+	signature, err := priv.Sign(digest[:])
+	require.NoError(t, err)
+	env, err := ParseEnvelope(signature)
+	require.NoError(t, err)
+
+	stripped := &Envelope{Version: env.Version, Modes: env.Modes, ECDSASignature: env.ECDSASignature}
+	_, err = pub.Verify(digest[:], stripped.Marshal(), PolicyHybridOR)
+	assert.ErrorIs(t, err, ErrDowngrade)
+
+	var sig ecdsaSignature
+	_, err = asn1.Unmarshal(env.ECDSASignature, &sig)
+	require.NoError(t, err)
+	sig.S = new(big.Int).Sub(pub.ECDSA.Params().N, sig.S)
+	env.ECDSASignature, err = asn1.Marshal(sig)
+	require.NoError(t, err)
+	valid, err := pub.Verify(digest[:], env.Marshal(), PolicyClassical)
+	require.NoError(t, err)
+	assert.False(t, valid, "high-S signatures are malleable")
+}
+
+func TestAppendMarshalDoesNotAllocate(t *testing.T) {
+	env := &Envelope{Version: EnvelopeV2, Modes: ModeClassical | ModePQC, ECDSASignature: make([]byte, 72), PQCSignature: make([]byte, 3309)}
+	buf := make([]byte, 0, MaxSignatureSize)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = env.AppendMarshal(buf[:0])
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, env.Marshal(), buf)
+	assert.Equal(t, env.Size(), len(buf))
+}
+
+func TestPublicKeyMarshalDeterministic(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	der, err := priv.Public().Marshal()
+	require.NoError(t, err)
+	again, err := priv.Public().Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, der, again)
+
+	parsed, err := ParsePublicKey(der)
+	require.NoError(t, err)
+	reencoded, err := parsed.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, der, reencoded, "a parsed key encodes back to the same bytes")
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ClassicalAlgorithm names the ECDSA component in PublicKeyJSON
+const ClassicalAlgorithm = "ECDSA-P256"
+
+// publicKeyJSONType is the type of every PublicKeyJSON
+const publicKeyJSONType = "composite"
+
+// PublicKeyJSON is the canonical JSON encoding of a composite public key,
+// shared by the REST APIs, the key registry and CLI output:
+//
+//	{"type":"composite","algorithms":["ECDSA-P256","ML-DSA-65"],
+//	 "ecdsa":"<base64url SPKI>","pqc":"<base64url ML-DSA key>",
+//	 "fingerprint":"<hex SHA-256 of the composite DER>",
+//	 "created_at":"2024-05-01T12:00:00Z"}
+//
+// Key bytes are unpadded base64url, created_at is optional, in UTC with
+// second precision. The fingerprint is the one pinned by MSPs.
+type PublicKeyJSON struct {
+	Type        string     `json:"type"`
+	Algorithms  []string   `json:"algorithms"`
+	ECDSA       string     `json:"ecdsa"`
+	PQC         string     `json:"pqc"`
+	Fingerprint string     `json:"fingerprint"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// NewPublicKeyJSON describes a DER composite public key; a zero createdAt
+// is omitted
+func NewPublicKeyJSON(composite []byte, createdAt time.Time) (*PublicKeyJSON, error) {
+	ecdsaDER, pqcPub, err := ParseCompositePublicKey(composite)
+	if err != nil {
+		return nil, err
+	}
+	fp := sha256.Sum256(composite)
+	j := &PublicKeyJSON{
+		Type:        publicKeyJSONType,
+		Algorithms:  []string{ClassicalAlgorithm, PQCAlgorithm},
+		ECDSA:       base64.RawURLEncoding.EncodeToString(ecdsaDER),
+		PQC:         base64.RawURLEncoding.EncodeToString(pqcPub),
+		Fingerprint: hex.EncodeToString(fp[:]),
+	}
+	if !createdAt.IsZero() {
+		t := createdAt.UTC().Truncate(time.Second)
+		j.CreatedAt = &t
+	}
+	return j, nil
+}
+
+// MarshalPublicKeyJSON returns the canonical JSON of a DER composite public key
+func MarshalPublicKeyJSON(composite []byte, createdAt time.Time) ([]byte, error) {
+	j, err := NewPublicKeyJSON(composite, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// ParsePublicKeyJSON decodes and checks a PublicKeyJSON: unknown fields,
+// unexpected algorithms and a fingerprint not matching the keys are errors
+func ParsePublicKeyJSON(data []byte) (*PublicKeyJSON, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var j PublicKeyJSON
+	if err := dec.Decode(&j); err != nil {
+		return nil, fmt.Errorf("invalid public key JSON: %w", err)
+	}
+	if _, err := j.Composite(); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// Composite returns the DER composite public key after checking j
+func (j *PublicKeyJSON) Composite() ([]byte, error) {
+	if j.Type != publicKeyJSONType {
+		return nil, fmt.Errorf("unsupported public key type %q", j.Type)
+	}
+	if len(j.Algorithms) != 2 || j.Algorithms[0] != ClassicalAlgorithm || j.Algorithms[1] != PQCAlgorithm {
+		return nil, fmt.Errorf("unsupported algorithms %v", j.Algorithms)
+	}
+	ecdsaDER, err := base64.RawURLEncoding.DecodeString(j.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECDSA key encoding: %w", err)
+	}
+	pqcPub, err := base64.RawURLEncoding.DecodeString(j.PQC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PQC key encoding: %w", err)
+	}
+	composite, err := MarshalCompositePublicKey(ecdsaDER, pqcPub)
+	if err != nil {
+		return nil, err
+	}
+	fp := sha256.Sum256(composite)
+	if j.Fingerprint != hex.EncodeToString(fp[:]) {
+		return nil, errors.New("public key fingerprint does not match the keys")
+	}
+	return composite, nil
+}
+
+// PublicKey parses the keys of j
+func (j *PublicKeyJSON) PublicKey() (*PublicKey, error) {
+	composite, err := j.Composite()
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(composite)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKeyJSON(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	composite, err := priv.Public().Marshal()
+	require.NoError(t, err)
+
+	created := time.Date(2024, 5, 1, 14, 0, 0, 123, time.FixedZone("CEST", 2*3600))
+	raw, err := MarshalPublicKeyJSON(composite, created)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), `{"type":"composite","algorithms":["ECDSA-P256","ML-DSA-65"],"ecdsa":"`))
+	assert.True(t, strings.HasSuffix(string(raw), `,"created_at":"2024-05-01T12:00:00Z"}`))
+	assert.NotContains(t, string(raw), "=")
+
+	j, err := ParsePublicKeyJSON(raw)
+	require.NoError(t, err)
+	fp := sha256.Sum256(composite)
+	assert.Equal(t, hex.EncodeToString(fp[:]), j.Fingerprint)
+	got, err := j.Composite()
+	require.NoError(t, err)
+	assert.Equal(t, composite, got)
+	pub, err := j.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, pub.ECDSA.Equal(&priv.ECDSA.PublicKey))
+
+	// Re-encoding is byte-identical
+	again, err := json.Marshal(j)
+	require.NoError(t, err)
+	assert.Equal(t, raw, again)
+
+	untimed, err := MarshalPublicKeyJSON(composite, time.Time{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(untimed), "created_at")
+}
+
+func TestParsePublicKeyJSONRejects(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	composite, err := priv.Public().Marshal()
+	require.NoError(t, err)
+	j, err := NewPublicKeyJSON(composite, time.Time{})
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*PublicKeyJSON){
+		"type":        func(j *PublicKeyJSON) { j.Type = "jwk" },
+		"algorithms":  func(j *PublicKeyJSON) { j.Algorithms = []string{ClassicalAlgorithm, "ML-DSA-87"} },
+		"encoding":    func(j *PublicKeyJSON) { j.PQC += "==" },
+		"fingerprint": func(j *PublicKeyJSON) { j.Fingerprint = strings.Repeat("00", 32) },
+	} {
+		bad := *j
+		mutate(&bad)
+		raw, err := json.Marshal(&bad)
+		require.NoError(t, err)
+		_, err = ParsePublicKeyJSON(raw)
+		assert.Error(t, err, name)
+	}
+
+	_, err = ParsePublicKeyJSON([]byte(`{"type":"composite","extra":1}`))
+	assert.Error(t, err)
+}
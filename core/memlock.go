@@ -0,0 +1,174 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// ErrMemoryLockUnsupported is returned by LockMemory on platforms without
+// mlock
+var ErrMemoryLockUnsupported = errors.New("memory locking is not supported on " + runtime.GOOS)
+
+// MemoryLockStatus describes the locking of secret key buffers
+type MemoryLockStatus struct {
+	Enabled bool
+	// Locked reports whether the last secret buffer was locked
+	Locked bool
+	// LockedPages is the number of pages locked now
+	LockedPages int
+	// Failures counts the secret buffers left unlocked since locking was
+	// enabled, e.g. over RLIMIT_MEMLOCK
+	Failures uint64
+	// LastError is the cause of the last failure
+	LastError error
+}
+
+// memoryLocks counts the secret buffers locked on each page: mlock does not
+// nest, so a page is unlocked only when its last buffer is
+var memoryLocks = struct {
+	sync.Mutex
+	enabled  bool
+	pages    map[uintptr]int
+	locked   bool
+	failures uint64
+	lastErr  error
+}{pages: map[uintptr]int{}}
+
+// memoryLockProbe is locked by SetMemoryLocking to check that locking
+// works; it lives on the heap, unlike a local buffer the stack could move
+var memoryLockProbe = make([]byte, 1)
+
+// SetMemoryLocking enables or disables the locking of the secret key
+// buffers of keys created afterwards: the liboqs secret key of PQCSigner,
+// the scalar of PrivateKey.ECDSA and the buffers passed to LockSecret, so
+// that they are not swapped to disk. Clean, or the garbage collector,
+// unlocks them. Locking degrades gracefully: buffers that cannot be locked,
+// e.g. over RLIMIT_MEMLOCK or on platforms without mlock, are used unlocked
+// and counted in MemoryLocking().Failures. Enabling returns the error of a
+// probe lock, if any, for callers that report it. Copies made by the Go
+// runtime or the standard library, e.g. inside crypto/ecdsa or the
+// crypto/mldsa keys of purego builds, are out of reach.
+func SetMemoryLocking(enabled bool) error {
+	memoryLocks.Lock()
+	memoryLocks.enabled = enabled
+	memoryLocks.Unlock()
+	if !enabled {
+		return nil
+	}
+	if LockSecret(memoryLockProbe) == nil {
+		return MemoryLocking().LastError
+	}
+	UnlockSecret(memoryLockProbe)
+	return nil
+}
+
+// MemoryLocking returns the status of secret buffer locking
+func MemoryLocking() MemoryLockStatus {
+	memoryLocks.Lock()
+	defer memoryLocks.Unlock()
+	return MemoryLockStatus{
+		Enabled:     memoryLocks.enabled,
+		Locked:      memoryLocks.enabled && memoryLocks.locked,
+		LockedPages: len(memoryLocks.pages),
+		Failures:    memoryLocks.failures,
+		LastError:   memoryLocks.lastErr,
+	}
+}
+
+// LockMemory locks the pages of buf in RAM until UnlockMemory, regardless
+// of SetMemoryLocking. buf must be on the heap.
+func LockMemory(buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+	start, end, size := pageRange(buf)
+	memoryLocks.Lock()
+	defer memoryLocks.Unlock()
+	err := mlock(start, end-start)
+	runtime.KeepAlive(buf)
+	if err != nil {
+		return fmt.Errorf("failed to lock %d bytes: %w", end-start, err)
+	}
+	for page := start; page < end; page += size {
+		memoryLocks.pages[page]++
+	}
+	return nil
+}
+
+// UnlockMemory releases a buffer locked by LockMemory. Pages shared with
+// other locked buffers stay locked.
+func UnlockMemory(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	start, end, size := pageRange(buf)
+	memoryLocks.Lock()
+	defer memoryLocks.Unlock()
+	for page := start; page < end; page += size {
+		switch n := memoryLocks.pages[page]; n {
+		case 0:
+		case 1:
+			delete(memoryLocks.pages, page)
+			// Nothing to do if it fails: the page merely stays locked
+			_ = munlock(page, size)
+		default:
+			memoryLocks.pages[page] = n - 1
+		}
+	}
+	runtime.KeepAlive(buf)
+}
+
+// LockSecret locks buf if memory locking is enabled and returns it, to be
+// passed to UnlockSecret; it returns nil if buf was not locked
+func LockSecret(buf []byte) []byte {
+	memoryLocks.Lock()
+	enabled := memoryLocks.enabled
+	memoryLocks.Unlock()
+	if !enabled || len(buf) == 0 {
+		return nil
+	}
+	err := LockMemory(buf)
+	memoryLocks.Lock()
+	defer memoryLocks.Unlock()
+	memoryLocks.locked = err == nil
+	if err != nil {
+		memoryLocks.failures++
+		memoryLocks.lastErr = err
+		return nil
+	}
+	return buf
+}
+
+// UnlockSecret releases a buffer returned by LockSecret
+func UnlockSecret(buf []byte) {
+	UnlockMemory(buf)
+}
+
+// ECDSASecret returns the memory holding the scalar of k, for LockSecret
+func ECDSASecret(k *ecdsa.PrivateKey) []byte {
+	if k == nil || k.D == nil {
+		return nil
+	}
+	return wordBytes(k.D.Bits())
+}
+
+// pageRange returns the pages spanned by buf
+func pageRange(buf []byte) (start, end, size uintptr) {
+	size = uintptr(os.Getpagesize())
+	addr := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	return addr &^ (size - 1), (addr + uintptr(len(buf)) + size - 1) &^ (size - 1), size
+}
+
+// wordBytes is the memory of the words of a big.Int
+func wordBytes[W any](words []W) []byte {
+	if len(words) == 0 {
+		return nil
+	}
+	var w W
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(words))), len(words)*int(unsafe.Sizeof(w)))
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package core
+
+func mlock(addr, length uintptr) error {
+	return ErrMemoryLockUnsupported
+}
+
+func munlock(addr, length uintptr) error {
+	return ErrMemoryLockUnsupported
+}
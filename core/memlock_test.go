@@ -0,0 +1,61 @@
+package core
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockMemory(t *testing.T) {
+	// On the heap, where secrets live
+	buf := bytes.Repeat([]byte{1}, 64)
+	if err := LockMemory(buf); err != nil {
+		require.True(t, runtime.GOOS != "linux" && runtime.GOOS != "darwin", err)
+		assert.ErrorIs(t, err, ErrMemoryLockUnsupported)
+		return
+	}
+	pages := MemoryLocking().LockedPages
+	assert.GreaterOrEqual(t, pages, 1)
+
+	// Buffers sharing a page lock it once
+	require.NoError(t, LockMemory(buf[32:]))
+	assert.Equal(t, pages, MemoryLocking().LockedPages)
+	UnlockMemory(buf)
+	assert.Equal(t, pages, MemoryLocking().LockedPages)
+	UnlockMemory(buf[32:])
+	assert.Less(t, MemoryLocking().LockedPages, pages)
+}
+
+func TestSetMemoryLocking(t *testing.T) {
+	defer SetMemoryLocking(false)
+	before := MemoryLocking()
+	if err := SetMemoryLocking(true); err != nil {
+		// Degraded: keys are still created, unlocked
+		status := MemoryLocking()
+		assert.False(t, status.Locked)
+		assert.Greater(t, status.Failures, before.Failures)
+		assert.ErrorIs(t, status.LastError, err)
+	}
+
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	status := MemoryLocking()
+	require.True(t, status.Enabled)
+	if status.Locked {
+		assert.NotNil(t, priv.locked)
+		assert.Greater(t, status.LockedPages, before.LockedPages)
+	}
+	priv.Clean()
+	assert.Nil(t, priv.locked)
+	assert.Equal(t, before.LockedPages, MemoryLocking().LockedPages)
+
+	require.NoError(t, SetMemoryLocking(false))
+	priv, err = GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+	assert.Nil(t, priv.locked)
+	assert.False(t, MemoryLocking().Locked)
+}
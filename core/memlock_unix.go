@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package core
+
+import "syscall"
+
+// mlock and munlock take page ranges rather than slices: a range may cover
+// memory outside the buffer being locked
+
+func mlock(addr, length uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_MLOCK, addr, length, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func munlock(addr, length uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_MUNLOCK, addr, length, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy defines which signature components Verify requires
+type Policy int
+
+const (
+	// PolicyHybridAND requires both the ECDSA and the PQC signature to be valid
+	PolicyHybridAND Policy = iota
+	// PolicyHybridOR accepts a signature if either component is valid
+	PolicyHybridOR
+	// PolicyClassical checks the ECDSA component only
+	PolicyClassical
+	// PolicyPQC checks the PQC component only
+	PolicyPQC
+)
+
+var policyNames = map[Policy]string{
+	PolicyHybridAND: "AND",
+	PolicyHybridOR:  "OR",
+	PolicyClassical: "CLASSICAL",
+	PolicyPQC:       "PQC",
+}
+
+func (p Policy) String() string {
+	if name, ok := policyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// ParsePolicy parses AND, OR, CLASSICAL or PQC (case insensitive)
+func ParsePolicy(s string) (Policy, error) {
+	for p, name := range policyNames {
+		if strings.EqualFold(s, name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signature policy %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (p Policy) MarshalText() ([]byte, error) {
+	if _, ok := policyNames[p]; !ok {
+		return nil, fmt.Errorf("unknown signature policy %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (p *Policy) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,194 @@
+//go:build !purego && !verifyonly
+
+// ./core/pqc.go
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/open-quantum-safe/liboqs-go/oqs"
+)
+
+// PQCBackend è l'implementazione ML-DSA compilata: liboqs senza tag o con
+// il tag oqs, vedi backend.go
+const PQCBackend = "liboqs"
+
+// PQCSigner wrap del signer PQC
+type PQCSigner struct {
+	signer    oqs.Signature
+	publicKey []byte
+	// locked è la chiave segreta di liboqs, se bloccata in RAM (memlock.go)
+	locked []byte
+}
+
+// NewPQCSigner crea un signer con nuova coppia di chiavi
+func NewPQCSigner() (*PQCSigner, error) {
+	signer := oqs.Signature{}
+	if err := signer.Init(PQCAlgorithm, nil); err != nil {
+		return nil, fmt.Errorf("failed to init PQC signer: %w", err)
+	}
+	
+	// Genera la coppia di chiavi
+	pubKey, err := signer.GenerateKeyPair()
+	if err != nil {
+		signer.Clean()
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	
+	s := &PQCSigner{
+		signer:    signer,
+		publicKey: pubKey,
+	}
+	s.lock()
+	return s, nil
+}
+
+// NewPQCSignerFromPrivate crea un signer da chiave privata esistente
+func NewPQCSignerFromPrivate(privKey []byte) (*PQCSigner, error) {
+	signer := oqs.Signature{}
+	if err := signer.Init(PQCAlgorithm, privKey); err != nil {
+		return nil, fmt.Errorf("failed to init PQC signer with private key: %w", err)
+	}
+	
+	// Ricostruisci la chiave pubblica dalla privata (se possibile)
+	// Potrebbe servire passarla come parametro separato
+	s := &PQCSigner{
+		signer:    signer,
+		publicKey: nil, // TODO: passare come parametro
+	}
+	s.lock()
+	return s, nil
+}
+
+// NewPQCSignerFromKeyPair crea un signer da una coppia di chiavi esportata.
+// liboqs non ricava la chiave pubblica dalla privata: un test di coerenza
+// (firma e verifica) fallisce con ErrPQCKeyPairMismatch se pubKey non
+// appartiene a privKey.
+func NewPQCSignerFromKeyPair(privKey, pubKey []byte) (*PQCSigner, error) {
+	s, err := NewPQCSignerFromPrivate(privKey)
+	if err != nil {
+		return nil, err
+	}
+	s.publicKey = pubKey
+	msg := []byte("ML-DSA pairwise consistency test")
+	sig, err := s.Sign(msg)
+	if err != nil {
+		s.Clean()
+		return nil, err
+	}
+	if valid, err := VerifyPQC(pubKey, msg, sig); err != nil || !valid {
+		s.Clean()
+		return nil, ErrPQCKeyPairMismatch
+	}
+	return s, nil
+}
+
+// VerifyPQC verifica una firma ML-DSA con la sola chiave pubblica
+func VerifyPQC(publicKey, msg, sig []byte) (bool, error) {
+	verifier := oqs.Signature{}
+	if err := verifier.Init(PQCAlgorithm, nil); err != nil {
+		return false, fmt.Errorf("failed to init PQC verifier: %w", err)
+	}
+	defer verifier.Clean()
+	return verifier.Verify(msg, sig, publicKey)
+}
+
+// PQCVerifier è un verifier ML-DSA già inizializzato per una chiave pubblica,
+// da riusare tra più verifiche invece di inizializzare liboqs ogni volta
+type PQCVerifier struct {
+	mutex     sync.RWMutex
+	verifier  oqs.Signature
+	publicKey []byte
+	cleaned   bool
+}
+
+// NewPQCVerifier inizializza un verifier per publicKey
+func NewPQCVerifier(publicKey []byte) (*PQCVerifier, error) {
+	v := &PQCVerifier{publicKey: append([]byte(nil), publicKey...)}
+	if err := v.verifier.Init(PQCAlgorithm, nil); err != nil {
+		return nil, fmt.Errorf("failed to init PQC verifier: %w", err)
+	}
+	return v, nil
+}
+
+// Verify verifica la firma, anche in parallelo; dopo Clean ricade su VerifyPQC
+func (v *PQCVerifier) Verify(msg, sig []byte) (bool, error) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	if v.cleaned {
+		return VerifyPQC(v.publicKey, msg, sig)
+	}
+	return v.verifier.Verify(msg, sig, v.publicKey)
+}
+
+// Clean libera le risorse, anche se altre goroutine usano ancora il verifier
+func (v *PQCVerifier) Clean() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if !v.cleaned {
+		v.cleaned = true
+		v.verifier.Clean()
+	}
+}
+
+// Sign firma il messaggio
+func (p *PQCSigner) Sign(msg []byte) ([]byte, error) {
+	sig, err := p.signer.Sign(msg)
+	if err != nil {
+		return nil, fmt.Errorf("PQC signature failed: %w", err)
+	}
+	return sig, nil
+}
+
+// Verify verifica la firma
+func (p *PQCSigner) Verify(msg, sig []byte) (bool, error) {
+	return p.signer.Verify(msg, sig, p.publicKey)
+}
+
+// PublicKey restituisce la chiave pubblica
+func (p *PQCSigner) PublicKey() []byte {
+	return p.publicKey
+}
+
+// PrivateKey esporta la chiave privata
+func (p *PQCSigner) PrivateKey() []byte {
+	return p.signer.ExportSecretKey()
+}
+
+// Clean libera le risorse; la chiave è azzerata prima di sbloccarla
+func (p *PQCSigner) Clean() {
+	p.signer.Clean()
+	if p.locked != nil {
+		UnlockSecret(p.locked)
+		p.locked = nil
+		runtime.SetFinalizer(p, nil)
+	}
+}
+
+// lock blocca in RAM la chiave segreta se SetMemoryLocking è attivo; il
+// garbage collector sblocca quella dei signer mai chiusi con Clean
+func (p *PQCSigner) lock() {
+	if p.locked = LockSecret(p.signer.ExportSecretKey()); p.locked != nil {
+		runtime.SetFinalizer(p, func(p *PQCSigner) { UnlockSecret(p.locked) })
+	}
+}
+
+// PQCPrivateKeySize restituisce la dimensione delle chiavi private che
+// liboqs esporta e carica: la chiave espansa di FIPS 204, 0 se PQCAlgorithm
+// non è abilitato
+func PQCPrivateKeySize() int {
+	signer := oqs.Signature{}
+	if err := signer.Init(PQCAlgorithm, nil); err != nil {
+		return 0
+	}
+	defer signer.Clean()
+	return signer.Details().LengthSecretKey
+}
+
+// PQCAvailable riporta se liboqs abilita PQCAlgorithm. È una singola chiamata
+// CGO senza lavoro crittografico, usata per misurare il costo del confine FFI.
+func PQCAvailable() bool {
+	return oqs.IsSigEnabled(PQCAlgorithm)
+}
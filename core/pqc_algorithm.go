@@ -0,0 +1,4 @@
+package core
+
+// PQCAlgorithm da usare - ML-DSA-65 è il nome standard NIST per Dilithium3
+const PQCAlgorithm = "ML-DSA-65"
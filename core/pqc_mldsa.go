@@ -0,0 +1,72 @@
+//go:build (purego || verifyonly) && go1.27
+
+package core
+
+import (
+	"crypto/mldsa"
+	"errors"
+	"fmt"
+)
+
+// mldsaParams are those of PQCAlgorithm. Verification with crypto/mldsa is
+// shared by the purego and verifyonly builds.
+var mldsaParams = mldsa.MLDSA65()
+
+// verifyMLDSA has the results of liboqs: an error for malformed keys and
+// oversized signatures, false for signatures that do not verify
+func verifyMLDSA(pk *mldsa.PublicKey, msg, sig []byte) (bool, error) {
+	if len(sig) > mldsaParams.SignatureSize() {
+		return false, errors.New("incorrect signature size")
+	}
+	return mldsa.Verify(pk, msg, sig, nil) == nil, nil
+}
+
+func parsePQCPublicKey(publicKey []byte) (*mldsa.PublicKey, error) {
+	pk, err := mldsa.NewPublicKey(mldsaParams, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s public key: %w", PQCAlgorithm, err)
+	}
+	return pk, nil
+}
+
+// VerifyPQC verifies an ML-DSA signature with the public key alone
+func VerifyPQC(publicKey, msg, sig []byte) (bool, error) {
+	pk, err := parsePQCPublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	return verifyMLDSA(pk, msg, sig)
+}
+
+// PQCVerifier is an ML-DSA verifier with a decoded public key, reused across
+// verifications
+type PQCVerifier struct {
+	key *mldsa.PublicKey
+	// err is the decoding error of the public key, returned by Verify as
+	// liboqs does
+	err error
+}
+
+// NewPQCVerifier decodes publicKey for verification
+func NewPQCVerifier(publicKey []byte) (*PQCVerifier, error) {
+	v := &PQCVerifier{}
+	v.key, v.err = parsePQCPublicKey(publicKey)
+	return v, nil
+}
+
+// Verify verifies sig, also concurrently
+func (v *PQCVerifier) Verify(msg, sig []byte) (bool, error) {
+	if v.err != nil {
+		return false, v.err
+	}
+	return verifyMLDSA(v.key, msg, sig)
+}
+
+// Clean is a no-op kept for the liboqs API; the key is garbage collected
+func (v *PQCVerifier) Clean() {}
+
+// PQCAvailable reports whether PQCAlgorithm is available, always with this
+// backend. It is a plain Go call, the baseline of the FFI cost measurement.
+func PQCAvailable() bool {
+	return true
+}
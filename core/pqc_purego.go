@@ -0,0 +1,105 @@
+//go:build purego && go1.27
+
+package core
+
+import (
+	"bytes"
+	"crypto/mldsa"
+	"errors"
+	"fmt"
+)
+
+// PQCBackend is the ML-DSA implementation compiled in. The purego build tag
+// selects crypto/mldsa instead of liboqs, so binaries build with
+// CGO_ENABLED=0; it requires Go 1.27 or later.
+//
+// Public keys and signatures use the FIPS 204 encodings either way. Private
+// keys do not: this backend exports the 32-byte seed, liboqs the expanded
+// secret key, so keys must be generated by the backend that loads them.
+const PQCBackend = "go"
+
+// ErrPQCPrivateKey is returned for private keys not exported by this backend
+var ErrPQCPrivateKey = fmt.Errorf("invalid %s private key, expected a %d-byte seed", PQCAlgorithm, mldsa.PrivateKeySize)
+
+// PQCSigner wraps an ML-DSA private key
+type PQCSigner struct {
+	key       *mldsa.PrivateKey
+	publicKey []byte
+}
+
+// NewPQCSigner creates a signer with a new key pair
+func NewPQCSigner() (*PQCSigner, error) {
+	key, err := mldsa.GenerateKey(mldsaParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	return &PQCSigner{key: key, publicKey: key.PublicKey().Bytes()}, nil
+}
+
+// NewPQCSignerFromPrivate creates a signer from an exported seed; the public
+// key is derived from it
+func NewPQCSignerFromPrivate(privKey []byte) (*PQCSigner, error) {
+	if len(privKey) != mldsa.PrivateKeySize {
+		return nil, ErrPQCPrivateKey
+	}
+	key, err := mldsa.NewPrivateKey(mldsaParams, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init PQC signer with private key: %w", err)
+	}
+	return &PQCSigner{key: key, publicKey: key.PublicKey().Bytes()}, nil
+}
+
+// NewPQCSignerFromKeyPair creates a signer from an exported key pair,
+// failing with ErrPQCKeyPairMismatch unless pubKey is derived from the seed
+func NewPQCSignerFromKeyPair(privKey, pubKey []byte) (*PQCSigner, error) {
+	s, err := NewPQCSignerFromPrivate(privKey)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(s.publicKey, pubKey) {
+		s.Clean()
+		return nil, ErrPQCKeyPairMismatch
+	}
+	return s, nil
+}
+
+// Sign signs msg
+func (p *PQCSigner) Sign(msg []byte) ([]byte, error) {
+	if p.key == nil {
+		return nil, errors.New("PQC signature failed: signer cleaned")
+	}
+	sig, err := p.key.Sign(nil, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("PQC signature failed: %w", err)
+	}
+	return sig, nil
+}
+
+// Verify verifies sig against the signer's public key
+func (p *PQCSigner) Verify(msg, sig []byte) (bool, error) {
+	return VerifyPQC(p.publicKey, msg, sig)
+}
+
+// PublicKey returns the public key
+func (p *PQCSigner) PublicKey() []byte {
+	return p.publicKey
+}
+
+// PrivateKey exports the private key seed
+func (p *PQCSigner) PrivateKey() []byte {
+	if p.key == nil {
+		return nil
+	}
+	return p.key.Bytes()
+}
+
+// Clean drops the private key
+func (p *PQCSigner) Clean() {
+	p.key = nil
+}
+
+// PQCPrivateKeySize returns the size of the private keys this backend
+// exports and loads, the seed
+func PQCPrivateKeySize() int {
+	return mldsa.PrivateKeySize
+}
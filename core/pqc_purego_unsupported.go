@@ -0,0 +1,7 @@
+//go:build (purego || verifyonly) && !go1.27
+
+package core
+
+// The purego and verifyonly backends need crypto/mldsa, added in Go 1.27;
+// this fails the build with a readable error instead of undefined PQC symbols
+var _ = pureGoBackendRequiresGo127
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPQCSignerFromKeyPair(t *testing.T) {
+	a, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer a.Clean()
+	b, err := NewPQCSigner()
+	require.NoError(t, err)
+	defer b.Clean()
+
+	s, err := NewPQCSignerFromKeyPair(a.PrivateKey(), a.PublicKey())
+	require.NoError(t, err)
+	msg := []byte("message")
+	sig, err := s.Sign(msg)
+	require.NoError(t, err)
+	valid, err := VerifyPQC(a.PublicKey(), msg, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Another key pair's public key, or none, is rejected
+	_, err = NewPQCSignerFromKeyPair(a.PrivateKey(), b.PublicKey())
+	assert.ErrorIs(t, err, ErrPQCKeyPairMismatch)
+	_, err = NewPQCSignerFromKeyPair(a.PrivateKey(), nil)
+	assert.ErrorIs(t, err, ErrPQCKeyPairMismatch)
+}
+
+// BenchmarkCGOCall is one liboqs call doing no crypto: the FFI round trip
+func BenchmarkCGOCall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = PQCAvailable()
+	}
+}
+
+func BenchmarkPQCSign(b *testing.B) {
+	signer, err := NewPQCSigner()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer signer.Clean()
+	digest := sha256.Sum256([]byte("benchmark message"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := signer.Sign(digest[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkECDSASign(b *testing.B) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		b.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("benchmark message"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ecdsa.Sign(rand.Reader, key, digest[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHybridSign(b *testing.B) {
+	key, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer key.Clean()
+	digest := sha256.Sum256([]byte("benchmark message"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := key.Sign(digest[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHybridAppendSignature(b *testing.B) {
+	key, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer key.Clean()
+	digest := sha256.Sum256([]byte("benchmark message"))
+	buf := make([]byte, 0, MaxSignatureSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if buf, err = key.AppendSignature(buf[:0], digest[:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build verifyonly && !purego && go1.27
+
+package core
+
+import "fmt"
+
+// PQCBackend is the ML-DSA implementation compiled in. The verifyonly build
+// tag compiles crypto/mldsa verification and no signing code, for clients
+// and auditors that only check signatures: no cgo, no liboqs and no private
+// key handling. Every PQC signing function fails with ErrVerifyOnly.
+const PQCBackend = "verifyonly"
+
+// ErrPQCPrivateKey is returned for private keys, which this backend does not
+// load
+var ErrPQCPrivateKey = fmt.Errorf("invalid %s private key: %w", PQCAlgorithm, ErrVerifyOnly)
+
+// PQCSigner only holds a public key in a verifyonly build
+type PQCSigner struct {
+	publicKey []byte
+}
+
+// NewPQCSigner fails with ErrVerifyOnly
+func NewPQCSigner() (*PQCSigner, error) {
+	return nil, ErrVerifyOnly
+}
+
+// NewPQCSignerFromPrivate fails with ErrVerifyOnly
+func NewPQCSignerFromPrivate(privKey []byte) (*PQCSigner, error) {
+	return nil, ErrPQCPrivateKey
+}
+
+// NewPQCSignerFromKeyPair fails with ErrVerifyOnly
+func NewPQCSignerFromKeyPair(privKey, pubKey []byte) (*PQCSigner, error) {
+	return nil, ErrPQCPrivateKey
+}
+
+// Sign fails with ErrVerifyOnly
+func (p *PQCSigner) Sign(msg []byte) ([]byte, error) {
+	return nil, ErrVerifyOnly
+}
+
+// Verify verifies sig against the signer's public key
+func (p *PQCSigner) Verify(msg, sig []byte) (bool, error) {
+	return VerifyPQC(p.publicKey, msg, sig)
+}
+
+// PublicKey returns the public key
+func (p *PQCSigner) PublicKey() []byte {
+	return p.publicKey
+}
+
+// PrivateKey returns nil
+func (p *PQCSigner) PrivateKey() []byte {
+	return nil
+}
+
+// Clean is a no-op
+func (p *PQCSigner) Clean() {}
+
+// PQCPrivateKeySize returns 0: this backend loads no private keys
+func PQCPrivateKeySize() int {
+	return 0
+}
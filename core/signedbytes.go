@@ -0,0 +1,39 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// SignedBytes is the encoding a signature over a structure covers: a domain
+// separating it from the other uses of the key, then big-endian integers
+// and byte strings prefixed with their 32-bit length, so that two distinct
+// structures never encode alike
+type SignedBytes []byte
+
+// NewSignedBytes starts the encoding of a structure of domain
+func NewSignedBytes(domain string) SignedBytes {
+	return append(SignedBytes(nil), domain...)
+}
+
+// AppendBytes appends v with its length
+func (b SignedBytes) AppendBytes(v []byte) SignedBytes {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(v)))
+	return append(b, v...)
+}
+
+// AppendUint32 appends v
+func (b SignedBytes) AppendUint32(v uint32) SignedBytes {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+
+// AppendUint64 appends v
+func (b SignedBytes) AppendUint64(v uint64) SignedBytes {
+	return binary.BigEndian.AppendUint64(b, v)
+}
+
+// Digest returns the SHA-256 of b, what a Signer signs
+func (b SignedBytes) Digest() []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedBytes(t *testing.T) {
+	b := NewSignedBytes("QLTEST1").AppendBytes([]byte("ab")).AppendUint64(7).AppendBytes(nil)
+	want := []byte("QLTEST1\x00\x00\x00\x02ab\x00\x00\x00\x00\x00\x00\x00\x07\x00\x00\x00\x00")
+	assert.Equal(t, want, []byte(b))
+	sum := sha256.Sum256(want)
+	assert.Equal(t, sum[:], b.Digest())
+
+	// The length prefix keeps the boundary between fields
+	assert.NotEqual(t,
+		NewSignedBytes("QLTEST1").AppendBytes([]byte("a")).AppendBytes([]byte("b")),
+		NewSignedBytes("QLTEST1").AppendBytes([]byte("ab")).AppendBytes(nil))
+}
+
+func TestSignMessage(t *testing.T) {
+	priv, err := GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+
+	sig, err := SignMessage(priv, []byte("message"))
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("message"))
+	valid, err := priv.Public().Verify(digest[:], sig, PolicyHybridAND)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
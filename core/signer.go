@@ -0,0 +1,29 @@
+package core
+
+import "crypto/sha256"
+
+// Signer signs SHA-256 digests with a hybrid key and returns v2 envelopes.
+// *PrivateKey implements it and hybrid.NewSigner adapts BCCSP keys, so
+// services written against Signer need no Fabric package.
+type Signer interface {
+	Sign(digest []byte) ([]byte, error)
+	Public() *PublicKey
+}
+
+// Verifier checks hybrid signatures over SHA-256 digests under a policy.
+// *PublicKey implements it and hybrid.NewVerifier adapts BCCSP keys.
+type Verifier interface {
+	Verify(digest, signature []byte, policy Policy) (bool, error)
+}
+
+// SignMessage signs the SHA-256 of msg with s, as Fabric signing
+// identities sign messages
+func SignMessage(s Signer, msg []byte) ([]byte, error) {
+	digest := sha256.Sum256(msg)
+	return s.Sign(digest[:])
+}
+
+var (
+	_ Signer   = (*PrivateKey)(nil)
+	_ Verifier = (*PublicKey)(nil)
+)
@@ -0,0 +1,101 @@
+//go:build js && wasm
+
+package wasmverify
+
+import (
+	"syscall/js"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Register installs the global qlverify object:
+//
+//	qlverify.backend                                 // "verifyonly"
+//	qlverify.verify(publicKey, message, signature, policy?)
+//	const s = qlverify.stream(publicKey, signature, policy?)
+//	s.update(chunk) ...; s.finish()
+//
+// Keys, messages, signatures and chunks are Uint8Arrays; keys may also be
+// strings holding public key JSON or PEM. policy is AND (the default), OR,
+// CLASSICAL or PQC. verify and finish return {valid: true} or
+// {valid: false, error: "..."}; update throws on a finished stream.
+func Register() {
+	api := map[string]interface{}{
+		"backend": core.PQCBackend,
+		"verify": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) < 3 {
+				return result(errorString("verify(publicKey, message, signature, policy?)"))
+			}
+			policy, err := policyArg(args, 3)
+			if err != nil {
+				return result(err)
+			}
+			return result(Verify(bytesArg(args[0]), bytesArg(args[1]), bytesArg(args[2]), policy))
+		}),
+		"stream": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) < 2 {
+				panic(js.Global().Get("Error").New("stream(publicKey, signature, policy?)"))
+			}
+			policy, err := policyArg(args, 2)
+			if err != nil {
+				panic(js.Global().Get("Error").New(err.Error()))
+			}
+			s, err := NewStream(bytesArg(args[0]), bytesArg(args[1]), policy)
+			if err != nil {
+				panic(js.Global().Get("Error").New(err.Error()))
+			}
+			return streamObject(s)
+		}),
+	}
+	js.Global().Set("qlverify", js.ValueOf(api))
+}
+
+// streamObject wraps s for JavaScript. Its functions are released by finish.
+func streamObject(s *Stream) js.Value {
+	obj := js.Global().Get("Object").New()
+	var update, finish js.Func
+	update = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		for _, chunk := range args {
+			if _, err := s.Write(bytesArg(chunk)); err != nil {
+				panic(js.Global().Get("Error").New(err.Error()))
+			}
+		}
+		return nil
+	})
+	finish = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		defer update.Release()
+		defer finish.Release()
+		return result(s.Verify())
+	})
+	obj.Set("update", update)
+	obj.Set("finish", finish)
+	return obj
+}
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+func result(err error) interface{} {
+	if err != nil {
+		return map[string]interface{}{"valid": false, "error": err.Error()}
+	}
+	return map[string]interface{}{"valid": true}
+}
+
+// bytesArg copies a Uint8Array, or the UTF-8 of a string
+func bytesArg(v js.Value) []byte {
+	if v.Type() == js.TypeString {
+		return []byte(v.String())
+	}
+	b := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b
+}
+
+func policyArg(args []js.Value, i int) (core.Policy, error) {
+	if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+		return core.PolicyHybridAND, nil
+	}
+	return core.ParsePolicy(args[i].String())
+}
@@ -0,0 +1,66 @@
+// Package wasmverify is the verification API of the WebAssembly build, for
+// browsers such as the Explorer that verify hybrid signatures client-side.
+// The bindings in bindings_js.go expose it to JavaScript; the rest builds
+// on any platform so it is tested natively. Build it with -tags verifyonly,
+// see core/cmd/qlverify-wasm.
+package wasmverify
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/core/ccverify"
+)
+
+// ErrStreamFinished is returned when a finished Stream is written to
+var ErrStreamFinished = errors.New("stream already verified")
+
+// Verify checks signature over the SHA-256 digest of message under policy.
+// publicKey is a public key JSON document, a PEM PUBLIC KEY block or the
+// DER composite key.
+func Verify(publicKey, message, signature []byte, policy core.Policy) error {
+	s, err := NewStream(publicKey, signature, policy)
+	if err != nil {
+		return err
+	}
+	s.Write(message)
+	return s.Verify()
+}
+
+// Stream verifies a signature over a payload received in chunks, e.g. a
+// fetch response body, hashing it as it arrives so that large payloads are
+// never held in memory
+type Stream struct {
+	pub       *core.PublicKey
+	signature []byte
+	policy    core.Policy
+	digest    hash.Hash
+	done      bool
+}
+
+// NewStream parses publicKey, in any format Verify accepts, and returns a
+// stream to write the payload to
+func NewStream(publicKey, signature []byte, policy core.Policy) (*Stream, error) {
+	pub, err := ccverify.ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{pub: pub, signature: append([]byte(nil), signature...), policy: policy, digest: sha256.New()}, nil
+}
+
+// Write hashes the next chunk of the payload
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.done {
+		return 0, ErrStreamFinished
+	}
+	return s.digest.Write(p)
+}
+
+// Verify checks the signature over the payload written so far. The stream
+// cannot be written to afterwards.
+func (s *Stream) Verify() error {
+	s.done = true
+	return ccverify.VerifyDigest(s.pub, s.digest.Sum(nil), s.signature, s.policy)
+}
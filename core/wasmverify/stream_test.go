@@ -0,0 +1,41 @@
+package wasmverify
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/core/ccverify"
+)
+
+// TestStream uses a fixed key and signature over payload, so that it also
+// runs in the verifyonly profile the WebAssembly module is built with
+func TestStream(t *testing.T) {
+	pub, err := os.ReadFile("testdata/stream_public_key.der")
+	require.NoError(t, err)
+	sig, err := os.ReadFile("testdata/stream_signature.bin")
+	require.NoError(t, err)
+	payload := bytes.Repeat([]byte("large attachment "), 100000)
+
+	assert.NoError(t, Verify(pub, payload, sig, core.PolicyHybridAND))
+	assert.ErrorIs(t, Verify(pub, payload[1:], sig, core.PolicyHybridAND), ccverify.ErrInvalidSignature)
+
+	// Chunks of any size give the same digest
+	s, err := NewStream(pub, sig, core.PolicyHybridAND)
+	require.NoError(t, err)
+	for rest := payload; len(rest) > 0; {
+		n := min(len(rest), 64*1024)
+		_, err = s.Write(rest[:n])
+		require.NoError(t, err)
+		rest = rest[n:]
+	}
+	assert.NoError(t, s.Verify())
+	_, err = s.Write([]byte("late"))
+	assert.ErrorIs(t, err, ErrStreamFinished)
+
+	_, err = NewStream([]byte("not a key"), sig, core.PolicyHybridAND)
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package ctlog
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/inclusion"
+)
+
+func newLogKey(t *testing.T) (core.Signer, []byte) {
+	t.Helper()
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	t.Cleanup(key.Clean)
+	pub, err := key.Public().Marshal()
+	require.NoError(t, err)
+	return key, pub
+}
+
+func newLog(t *testing.T, signer core.Signer, issuers ...*hybridx509.Certificate) *Log {
+	t.Helper()
+	l, err := New(signer, issuers...)
+	require.NoError(t, err)
+	return l
+}
+
+func newCA(t *testing.T, cn string) *ca.CA {
+	t.Helper()
+	c, err := ca.NewRoot(pkix.Name{Organization: []string{"Org1"}, CommonName: cn}, 0)
+	require.NoError(t, err)
+	return c
+}
+
+func issue(t *testing.T, c *ca.CA, cn string) []byte {
+	t.Helper()
+	id, err := c.Issue(ca.Request{CommonName: cn, OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	return id.Cert.Raw
+}
+
+func TestLogAndMonitor(t *testing.T) {
+	ctx := context.Background()
+	signer, logKey := newLogKey(t)
+	org1, rogue := newCA(t, "ca.org1.example.com"), newCA(t, "ca.rogue.example.com")
+	l := newLog(t, signer, org1.Cert, rogue.Cert)
+	srv := httptest.NewServer(l.Handler())
+	defer srv.Close()
+	client := &Client{URL: srv.URL}
+	m := &Monitor{Client: client, LogKey: logKey, Check: IssuedBy("Org1", org1.Cert)}
+
+	findings, err := m.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+	assert.Equal(t, uint64(0), m.TreeHead().Size)
+
+	peer0 := issue(t, org1, "peer0.org1.example.com")
+	receipt, err := client.Add(ctx, peer0)
+	require.NoError(t, err)
+	require.NoError(t, receipt.Verify(logKey))
+	assert.Equal(t, uint64(0), receipt.Entry.Index)
+	again, err := client.Add(ctx, peer0)
+	require.NoError(t, err)
+	assert.Equal(t, receipt.Entry, again.Entry, "resubmission returns the existing entry")
+
+	_, otherKey := newLogKey(t)
+	assert.ErrorIs(t, receipt.Verify(otherKey), ErrInvalidTreeHead)
+	receipt.Entry.Index = 1
+	assert.ErrorIs(t, receipt.Verify(logKey), inclusion.ErrInvalidProof)
+
+	_, err = client.Add(ctx, issue(t, org1, "peer1.org1.example.com"))
+	require.NoError(t, err)
+	findings, err = m.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	forged := issue(t, rogue, "admin.org1.example.com")
+	_, err = client.Add(ctx, forged)
+	require.NoError(t, err)
+	findings, err = m.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.ErrorIs(t, findings[0].Err, ErrMisissued)
+	assert.Equal(t, forged, findings[0].Entry.Certificate)
+	assert.Equal(t, uint64(3), m.TreeHead().Size)
+
+	path, err := client.InclusionProof(ctx, 1, 3)
+	require.NoError(t, err)
+	entries, err := client.Entries(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, inclusion.VerifyPath(entries[0].LeafHash(), 1, 3, path, m.TreeHead().RootHash))
+}
+
+func TestLogRejects(t *testing.T) {
+	ctx := context.Background()
+	signer, _ := newLogKey(t)
+	org1, other := newCA(t, "ca.org1.example.com"), newCA(t, "ca.other.example.com")
+	srv := httptest.NewServer(newLog(t, signer, org1.Cert).Handler())
+	defer srv.Close()
+	client := &Client{URL: srv.URL}
+
+	_, err := client.Add(ctx, issue(t, other, "peer0.org1.example.com"))
+	var se *StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusForbidden, se.Code)
+	_, err = client.Add(ctx, []byte("not a certificate"))
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.Code)
+	_, err = client.Entries(ctx, 0, 1)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.Code)
+}
+
+func TestMonitorDetectsFork(t *testing.T) {
+	ctx := context.Background()
+	signer, logKey := newLogKey(t)
+	org1 := newCA(t, "ca.org1.example.com")
+	honest, fork := newLog(t, signer), newLog(t, signer)
+	for i, cn := range []string{"peer0", "peer1"} {
+		_, err := honest.Add(issue(t, org1, cn))
+		require.NoError(t, err)
+		// The fork rewrites the first entry
+		if i == 0 {
+			cn = "peer0-forged"
+		}
+		_, err = fork.Add(issue(t, org1, cn))
+		require.NoError(t, err)
+	}
+	_, err := fork.Add(issue(t, org1, "peer2"))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(honest.Handler())
+	defer srv.Close()
+	m := &Monitor{Client: &Client{URL: srv.URL}, LogKey: logKey}
+	_, err = m.Poll(ctx)
+	require.NoError(t, err)
+
+	forkSrv := httptest.NewServer(fork.Handler())
+	defer forkSrv.Close()
+	m.Client = &Client{URL: forkSrv.URL}
+	_, err = m.Poll(ctx)
+	assert.ErrorIs(t, err, ErrNotAppendOnly)
+	assert.Equal(t, uint64(2), m.TreeHead().Size, "state kept on error")
+}
+
+func TestOpen(t *testing.T) {
+	signer, logKey := newLogKey(t)
+	org1 := newCA(t, "ca.org1.example.com")
+	path := filepath.Join(t.TempDir(), "ctlog.jsonl")
+	l, err := Open(path, signer)
+	require.NoError(t, err)
+	for _, cn := range []string{"peer0", "peer1", "peer2"} {
+		_, err := l.Add(issue(t, org1, cn))
+		require.NoError(t, err)
+	}
+	head, err := l.TreeHead()
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	reopened, err := Open(path, signer)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, logKey, reopened.PublicKey())
+	again, err := reopened.TreeHead()
+	require.NoError(t, err)
+	assert.Equal(t, head.Size, again.Size)
+	assert.Equal(t, head.RootHash, again.RootHash)
+	proof, err := reopened.ConsistencyProof(1, 3)
+	require.NoError(t, err)
+	first, err := reopened.Entries(0, 1)
+	require.NoError(t, err)
+	assert.True(t, inclusion.VerifyConsistency(1, 3, first[0].LeafHash(), head.RootHash, proof))
+}
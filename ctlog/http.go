@@ -0,0 +1,201 @@
+package ctlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// MaxEntriesPerRequest bounds the entries returned by GET /v1/entries
+const MaxEntriesPerRequest = 1000
+
+// AddRequest is the body of POST /v1/entries
+type AddRequest struct {
+	// Certificate is the DER of the composite certificate
+	Certificate []byte `json:"certificate"`
+}
+
+// Proof is the body of the proof endpoints
+type Proof struct {
+	Path [][]byte `json:"path"`
+}
+
+// Handler serves the log API:
+//
+//	POST /v1/entries                            log a certificate, returns a Receipt
+//	GET  /v1/tree-head                          the signed head of the current tree
+//	GET  /v1/entries?start=&end=                entries [start, end)
+//	GET  /v1/proofs/inclusion?index=&size=      audit path of an entry
+//	GET  /v1/proofs/consistency?first=&second=  consistency of two tree sizes
+func (l *Log) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/entries", func(w http.ResponseWriter, r *http.Request) {
+		var req AddRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		receipt, err := l.Add(req.Certificate)
+		respond(w, receipt, err)
+	})
+	mux.HandleFunc("GET /v1/tree-head", func(w http.ResponseWriter, r *http.Request) {
+		head, err := l.TreeHead()
+		respond(w, head, err)
+	})
+	mux.HandleFunc("GET /v1/entries", func(w http.ResponseWriter, r *http.Request) {
+		start, end, err := rangeParams(r, "start", "end")
+		if err == nil && end-start > MaxEntriesPerRequest {
+			end = start + MaxEntriesPerRequest
+		}
+		var entries []Entry
+		if err == nil {
+			entries, err = l.Entries(start, end)
+		}
+		respond(w, entries, err)
+	})
+	mux.HandleFunc("GET /v1/proofs/inclusion", func(w http.ResponseWriter, r *http.Request) {
+		index, size, err := rangeParams(r, "index", "size")
+		var path [][]byte
+		if err == nil {
+			path, err = l.InclusionProof(index, size)
+		}
+		respond(w, &Proof{Path: path}, err)
+	})
+	mux.HandleFunc("GET /v1/proofs/consistency", func(w http.ResponseWriter, r *http.Request) {
+		first, second, err := rangeParams(r, "first", "second")
+		var path [][]byte
+		if err == nil {
+			path, err = l.ConsistencyProof(first, second)
+		}
+		respond(w, &Proof{Path: path}, err)
+	})
+	return mux
+}
+
+// errBadRequest marks invalid query parameters
+var errBadRequest = errors.New("bad request")
+
+func rangeParams(r *http.Request, a, b string) (uint64, uint64, error) {
+	var values [2]uint64
+	for i, name := range []string{a, b} {
+		v, err := strconv.ParseUint(r.URL.Query().Get(name), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: %s: %w", errBadRequest, name, err)
+		}
+		values[i] = v
+	}
+	return values[0], values[1], nil
+}
+
+func respond(w http.ResponseWriter, v interface{}, err error) {
+	switch {
+	case errors.Is(err, errBadRequest), errors.Is(err, ErrOutOfRange), errors.Is(err, ErrInvalidCertificate), errors.Is(err, hybridx509.ErrNotComposite):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnknownIssuer):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// StatusError is a request the log answered with an error status
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("transparency log: %d %s", e.Code, e.Message)
+}
+
+// Client calls a log
+type Client struct {
+	// URL is the log's base URL, e.g. https://ctlog.example.org
+	URL string
+	// HTTP is http.DefaultClient if nil
+	HTTP *http.Client
+}
+
+// Add logs the composite certificate der. The receipt is not verified, see
+// Receipt.Verify.
+func (c *Client) Add(ctx context.Context, der []byte) (*Receipt, error) {
+	resp := &Receipt{}
+	return resp, c.do(ctx, "POST", "/v1/entries", nil, AddRequest{Certificate: der}, resp)
+}
+
+// TreeHead returns the current signed tree head
+func (c *Client) TreeHead(ctx context.Context) (*TreeHead, error) {
+	resp := &TreeHead{}
+	return resp, c.do(ctx, "GET", "/v1/tree-head", nil, nil, resp)
+}
+
+// Entries returns entries from start up to, not including, end. The log may
+// return fewer entries than asked for.
+func (c *Client) Entries(ctx context.Context, start, end uint64) ([]Entry, error) {
+	var resp []Entry
+	err := c.do(ctx, "GET", "/v1/entries", url.Values{"start": {fmt.Sprint(start)}, "end": {fmt.Sprint(end)}}, nil, &resp)
+	return resp, err
+}
+
+// InclusionProof returns the audit path of entry index in the tree of size
+func (c *Client) InclusionProof(ctx context.Context, index, size uint64) ([][]byte, error) {
+	resp := &Proof{}
+	err := c.do(ctx, "GET", "/v1/proofs/inclusion", url.Values{"index": {fmt.Sprint(index)}, "size": {fmt.Sprint(size)}}, nil, resp)
+	return resp.Path, err
+}
+
+// ConsistencyProof proves that the tree of size first is a prefix of the
+// tree of size second
+func (c *Client) ConsistencyProof(ctx context.Context, first, second uint64) ([][]byte, error) {
+	resp := &Proof{}
+	err := c.do(ctx, "GET", "/v1/proofs/consistency", url.Values{"first": {fmt.Sprint(first)}, "second": {fmt.Sprint(second)}}, nil, resp)
+	return resp.Path, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, resp interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	u := strings.TrimSuffix(c.URL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(r.Body, 4096))
+		return &StatusError{Code: r.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
@@ -0,0 +1,319 @@
+// Package ctlog is an append-only transparency log of the composite
+// certificates issued in a consortium, after Certificate Transparency
+// (RFC 9162). CAs submit every certificate they issue; the log keeps them in
+// an RFC 6962 Merkle tree and signs its tree heads with a hybrid key.
+// Monitors poll the log, check that each new head extends the previous one
+// and inspect the new certificates, so that a certificate issued for an
+// organization by a CA it does not know about is detected.
+package ctlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/inclusion"
+)
+
+// Domains separate log signatures and leaves from other uses of the key
+const (
+	treeHeadDomain = "QLCTSTH1"
+	leafDomain     = "QLCTLEAF1"
+)
+
+var (
+	// ErrInvalidCertificate is returned when a submission is not a
+	// certificate
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	// ErrUnknownIssuer is returned when a submitted certificate is not
+	// signed by an issuer the log accepts
+	ErrUnknownIssuer = errors.New("certificate not signed by an accepted issuer")
+	// ErrOutOfRange is returned for entries and tree sizes the log does not have
+	ErrOutOfRange = errors.New("out of range")
+	// ErrInvalidTreeHead is returned when a tree head signature does not verify
+	ErrInvalidTreeHead = errors.New("invalid tree head")
+)
+
+// Entry is a logged certificate
+type Entry struct {
+	Index     uint64    `json:"index"`
+	Timestamp time.Time `json:"timestamp"`
+	// Certificate is the DER of the composite certificate
+	Certificate []byte `json:"certificate"`
+}
+
+// LeafHash is the hash of e in the Merkle tree
+func (e *Entry) LeafHash() []byte {
+	return inclusion.LeafHash(core.NewSignedBytes(leafDomain).
+		AppendUint64(uint64(e.Timestamp.UnixMilli())).
+		AppendBytes(e.Certificate))
+}
+
+// TreeHead is a signed tree head: the root of the first Size entries
+type TreeHead struct {
+	Size      uint64    `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+	RootHash  []byte    `json:"root_hash"`
+	// PublicKey is the composite public key (DER) of the log
+	PublicKey []byte `json:"public_key"`
+	Signature []byte `json:"signature"`
+}
+
+// signedBytes is the encoding covered by the signature
+func (h *TreeHead) signedBytes() core.SignedBytes {
+	return core.NewSignedBytes(treeHeadDomain).
+		AppendUint64(h.Size).
+		AppendUint64(uint64(h.Timestamp.UnixMilli())).
+		AppendBytes(h.RootHash).
+		AppendBytes(h.PublicKey)
+}
+
+// Verify checks the signature of h with logKey, the composite public key
+// (DER) of the log
+func (h *TreeHead) Verify(logKey []byte) error {
+	if string(h.PublicKey) != string(logKey) {
+		return fmt.Errorf("%w: signed by another key", ErrInvalidTreeHead)
+	}
+	pub, err := core.ParsePublicKey(logKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTreeHead, err)
+	}
+	valid, err := pub.Verify(h.signedBytes().Digest(), h.Signature, core.PolicyHybridAND)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTreeHead, err)
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidTreeHead)
+	}
+	return nil
+}
+
+// Receipt is returned for a submitted certificate: its entry, a tree head
+// including it and the inclusion proof against that head
+type Receipt struct {
+	Entry    Entry     `json:"entry"`
+	TreeHead *TreeHead `json:"tree_head"`
+	Path     [][]byte  `json:"path"`
+}
+
+// Verify checks the tree head with logKey and that the entry is in its tree
+func (r *Receipt) Verify(logKey []byte) error {
+	if r.TreeHead == nil {
+		return fmt.Errorf("%w: missing", ErrInvalidTreeHead)
+	}
+	if err := r.TreeHead.Verify(logKey); err != nil {
+		return err
+	}
+	if !inclusion.VerifyPath(r.Entry.LeafHash(), int(r.Entry.Index), int(r.TreeHead.Size), r.Path, r.TreeHead.RootHash) {
+		return fmt.Errorf("%w: entry %d is not in the tree of size %d", inclusion.ErrInvalidProof, r.Entry.Index, r.TreeHead.Size)
+	}
+	return nil
+}
+
+// Log is the log service. Its methods are safe for concurrent use.
+type Log struct {
+	signer    core.Signer
+	publicKey []byte
+	issuers   []*hybridx509.Certificate
+
+	mutex   sync.RWMutex
+	entries []Entry
+	leaves  [][]byte
+	byCert  map[[sha256.Size]byte]uint64
+	head    *TreeHead
+	file    *os.File
+}
+
+// New returns an empty in-memory log signing tree heads with signer. With
+// issuers, only certificates signed by one of them are accepted.
+func New(signer core.Signer, issuers ...*hybridx509.Certificate) (*Log, error) {
+	publicKey, err := signer.Public().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Log{
+		signer:    signer,
+		publicKey: publicKey,
+		issuers:   issuers,
+		byCert:    map[[sha256.Size]byte]uint64{},
+	}, nil
+}
+
+// Open is New with the entries kept as JSON lines in path, which is created
+// if needed. Entries are synced to disk before they are acknowledged.
+func Open(path string, signer core.Signer, issuers ...*hybridx509.Certificate) (*Log, error) {
+	l, err := New(signer, issuers...)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: entry %d: %w", path, len(l.entries), err)
+		}
+		if e.Index != uint64(len(l.entries)) {
+			f.Close()
+			return nil, fmt.Errorf("%s: entry %d has index %d", path, len(l.entries), e.Index)
+		}
+		l.append(e)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	l.file = f
+	return l, nil
+}
+
+// Close closes the file of a log opened with Open
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// PublicKey returns the composite public key (DER) of the log
+func (l *Log) PublicKey() []byte {
+	return l.publicKey
+}
+
+func (l *Log) append(e Entry) {
+	l.entries = append(l.entries, e)
+	l.leaves = append(l.leaves, e.LeafHash())
+	l.byCert[sha256.Sum256(e.Certificate)] = e.Index
+}
+
+// Add logs the composite certificate der and returns its receipt. A
+// certificate already logged gets a receipt for its existing entry.
+func (l *Log) Add(der []byte) (*Receipt, error) {
+	cert, err := hybridx509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCertificate, err)
+	}
+	if !cert.IsComposite() {
+		return nil, hybridx509.ErrNotComposite
+	}
+	if err := l.checkIssuer(cert); err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	index, ok := l.byCert[sha256.Sum256(der)]
+	if !ok {
+		e := Entry{Index: uint64(len(l.entries)), Timestamp: time.Now().UTC().Truncate(time.Millisecond), Certificate: der}
+		if l.file != nil {
+			line, err := json.Marshal(e)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := l.file.Write(append(line, '\n')); err != nil {
+				return nil, err
+			}
+			if err := l.file.Sync(); err != nil {
+				return nil, err
+			}
+		}
+		l.append(e)
+		index = e.Index
+	}
+	head, err := l.treeHead()
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		Entry:    l.entries[index],
+		TreeHead: head,
+		Path:     inclusion.MerklePath(l.leaves, int(index)),
+	}, nil
+}
+
+func (l *Log) checkIssuer(cert *hybridx509.Certificate) error {
+	if len(l.issuers) == 0 {
+		return nil
+	}
+	for _, issuer := range l.issuers {
+		if cert.CheckSignatureFrom(issuer) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownIssuer, cert.Issuer)
+}
+
+// TreeHead returns the signed head of the current tree
+func (l *Log) TreeHead() (*TreeHead, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.treeHead()
+}
+
+// treeHead signs a new head when the tree grew since the last one
+func (l *Log) treeHead() (*TreeHead, error) {
+	if l.head != nil && l.head.Size == uint64(len(l.leaves)) {
+		return l.head, nil
+	}
+	head := &TreeHead{
+		Size:      uint64(len(l.leaves)),
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+		RootHash:  inclusion.MerkleRoot(l.leaves),
+		PublicKey: l.publicKey,
+	}
+	sig, err := l.signer.Sign(head.signedBytes().Digest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tree head: %w", err)
+	}
+	head.Signature = sig
+	l.head = head
+	return head, nil
+}
+
+// Entries returns the entries from start up to, not including, end
+func (l *Log) Entries(start, end uint64) ([]Entry, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if start > end || end > uint64(len(l.entries)) {
+		return nil, fmt.Errorf("%w: entries [%d, %d) of %d", ErrOutOfRange, start, end, len(l.entries))
+	}
+	return append([]Entry(nil), l.entries[start:end]...), nil
+}
+
+// InclusionProof returns the audit path of entry index in the tree of the
+// first size entries
+func (l *Log) InclusionProof(index, size uint64) ([][]byte, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if index >= size || size > uint64(len(l.leaves)) {
+		return nil, fmt.Errorf("%w: entry %d in a tree of size %d, the log has %d", ErrOutOfRange, index, size, len(l.leaves))
+	}
+	return inclusion.MerklePath(l.leaves[:size], int(index)), nil
+}
+
+// ConsistencyProof proves that the tree of size first is a prefix of the
+// tree of size second
+func (l *Log) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if first > second || second > uint64(len(l.leaves)) {
+		return nil, fmt.Errorf("%w: trees of size %d and %d, the log has %d", ErrOutOfRange, first, second, len(l.leaves))
+	}
+	return inclusion.ConsistencyProof(l.leaves[:second], int(first)), nil
+}
@@ -0,0 +1,133 @@
+package ctlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/inclusion"
+)
+
+var (
+	// ErrNotAppendOnly is returned when a tree head does not extend the
+	// previous one, or the entries served do not match it
+	ErrNotAppendOnly = errors.New("log is not append-only")
+	// ErrMisissued is returned by IssuedBy for a certificate of the
+	// organization signed by another issuer
+	ErrMisissued = errors.New("mis-issued certificate")
+)
+
+// Finding is a logged certificate that failed the monitor check
+type Finding struct {
+	Entry Entry
+	// Certificate is nil when the entry does not parse
+	Certificate *hybridx509.Certificate
+	Err         error
+}
+
+// Monitor follows a log: every Poll verifies the new tree head, its
+// consistency with the previous one and the new entries, and runs Check on
+// the new certificates. A Monitor is not safe for concurrent use.
+type Monitor struct {
+	Client *Client
+	// LogKey is the composite public key (DER) of the log
+	LogKey []byte
+	// Check inspects every new certificate; its errors are reported as
+	// findings. Nil accepts every certificate.
+	Check func(*hybridx509.Certificate) error
+
+	head   *TreeHead
+	leaves [][]byte
+}
+
+// TreeHead returns the last verified tree head, nil before the first Poll
+func (m *Monitor) TreeHead() *TreeHead {
+	return m.head
+}
+
+// Poll verifies the current tree head and returns the findings among the
+// entries added since the last Poll. On error the monitor keeps its state,
+// so the next Poll checks the same entries again.
+func (m *Monitor) Poll(ctx context.Context) ([]Finding, error) {
+	head, err := m.Client.TreeHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := head.Verify(m.LogKey); err != nil {
+		return nil, err
+	}
+	var size uint64
+	if m.head != nil {
+		size = m.head.Size
+		if head.Size < size {
+			return nil, fmt.Errorf("%w: tree shrank from %d to %d entries", ErrNotAppendOnly, size, head.Size)
+		}
+		proof, err := m.Client.ConsistencyProof(ctx, size, head.Size)
+		if err != nil {
+			return nil, err
+		}
+		if !inclusion.VerifyConsistency(int(size), int(head.Size), m.head.RootHash, head.RootHash, proof) {
+			return nil, fmt.Errorf("%w: tree of size %d does not extend the tree of size %d", ErrNotAppendOnly, head.Size, size)
+		}
+	}
+
+	leaves := slices.Clip(m.leaves)
+	var findings []Finding
+	for size < head.Size {
+		entries, err := m.Client.Entries(ctx, size, head.Size)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("%w: entries from %d missing", ErrNotAppendOnly, size)
+		}
+		for _, e := range entries {
+			if e.Index != size {
+				return nil, fmt.Errorf("%w: got entry %d for %d", ErrNotAppendOnly, e.Index, size)
+			}
+			leaves = append(leaves, e.LeafHash())
+			if f := m.check(e); f != nil {
+				findings = append(findings, *f)
+			}
+			size++
+		}
+	}
+	if !bytes.Equal(inclusion.MerkleRoot(leaves), head.RootHash) {
+		return nil, fmt.Errorf("%w: entries do not match the tree head", ErrNotAppendOnly)
+	}
+	m.head, m.leaves = head, leaves
+	return findings, nil
+}
+
+func (m *Monitor) check(e Entry) *Finding {
+	cert, err := hybridx509.ParseCertificate(e.Certificate)
+	if err != nil {
+		return &Finding{Entry: e, Err: fmt.Errorf("%w: %w", ErrInvalidCertificate, err)}
+	}
+	if m.Check == nil {
+		return nil
+	}
+	if err := m.Check(cert); err != nil {
+		return &Finding{Entry: e, Certificate: cert, Err: err}
+	}
+	return nil
+}
+
+// IssuedBy returns a Check flagging the certificates whose subject
+// organization is org and that are not signed by one of issuers
+func IssuedBy(org string, issuers ...*hybridx509.Certificate) func(*hybridx509.Certificate) error {
+	return func(cert *hybridx509.Certificate) error {
+		if !slices.Contains(cert.Subject.Organization, org) {
+			return nil
+		}
+		for _, issuer := range issuers {
+			if cert.CheckSignatureFrom(issuer) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s, serial %s, issued by %s", ErrMisissued, cert.Subject, cert.SerialNumber, cert.Issuer)
+	}
+}
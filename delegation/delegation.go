@@ -0,0 +1,384 @@
+// Package delegation mints short-lived sub-keys for ephemeral workers. A
+// parent hybrid key signs a delegation statement naming a fresh sub-key, its
+// validity window, the scopes it may sign for and how many further
+// delegations it may make. Workers sign with the sub-key and hand out the
+// chain of statements with each signature, so relying parties that trust
+// the parent verify them without certificates and the long-term key never
+// leaves its keystore.
+package delegation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// StatementVersion is the version of statements created by Delegate
+const StatementVersion = 1
+
+// statementDomain separates statement signatures from message signatures
+const statementDomain = "QLDELEGATE1"
+
+const (
+	// DefaultLifetime is the lifetime of sub-keys when Options does not set one
+	DefaultLifetime = 15 * time.Minute
+	// MaxLifetime bounds the lifetime of sub-keys
+	MaxLifetime = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidChain is returned when a statement is malformed, not signed
+	// by the key it names as issuer, or does not follow its parent
+	ErrInvalidChain = errors.New("invalid delegation chain")
+	// ErrExpired is returned when a statement of the chain is not valid at
+	// the verification time
+	ErrExpired = errors.New("delegation not valid at this time")
+	// ErrDepthExceeded is returned when a key delegates more than its
+	// statement allows
+	ErrDepthExceeded = errors.New("delegation depth exceeded")
+	// ErrScope is returned when a key delegates or signs outside its scopes
+	ErrScope = errors.New("delegation scope not allowed")
+	// ErrInvalidSignature is returned when the sub-key signature does not verify
+	ErrInvalidSignature = errors.New("invalid delegated signature")
+)
+
+// Statement authorizes the Subject key to sign for the Issuer key
+type Statement struct {
+	Version int `json:"version"`
+	// Issuer is the composite public key (DER) of the delegating key
+	Issuer []byte `json:"issuer"`
+	// Subject is the composite public key (DER) of the sub-key
+	Subject   []byte    `json:"subject"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	// Scopes restrict what the sub-key signs; empty allows any scope the
+	// issuer has
+	Scopes []string `json:"scopes,omitempty"`
+	// MaxDepth is the number of delegations allowed below the sub-key
+	MaxDepth int `json:"max_depth"`
+}
+
+// allows reports whether scope is within s; an empty s allows any scope
+func (s *Statement) allows(scope string) bool {
+	return len(s.Scopes) == 0 || slices.Contains(s.Scopes, scope)
+}
+
+// Delegation is a statement signed by its issuer. Statement holds the exact
+// signed bytes.
+type Delegation struct {
+	Statement json.RawMessage `json:"statement"`
+	Signature []byte          `json:"signature"`
+}
+
+// Chain lists the delegations from the parent key to a sub-key
+type Chain []Delegation
+
+// Options configures Delegate
+type Options struct {
+	// Lifetime is DefaultLifetime if zero, and at most MaxLifetime. The
+	// sub-key never outlives the key delegating to it.
+	Lifetime time.Duration
+	// Scopes restrict the sub-key. A key with scopes only delegates a subset.
+	Scopes []string
+	// MaxDepth is the number of delegations allowed below the sub-key
+	MaxDepth int
+}
+
+// SubKey is a delegated key and the chain authorizing it
+type SubKey struct {
+	Key   *core.PrivateKey
+	Chain Chain
+
+	statement *Statement
+}
+
+// Delegate mints a sub-key authorized by parent
+func Delegate(parent core.Signer, opts Options) (*SubKey, error) {
+	parentKey, err := parent.Public().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return delegate(parent, parentKey, nil, nil, opts)
+}
+
+// Delegate mints a sub-key authorized by k, within k's own scopes, depth
+// and lifetime
+func (k *SubKey) Delegate(opts Options) (*SubKey, error) {
+	if k.statement.MaxDepth < 1 {
+		return nil, ErrDepthExceeded
+	}
+	if opts.MaxDepth > k.statement.MaxDepth-1 {
+		return nil, fmt.Errorf("%w: %d further delegations allowed, %d requested", ErrDepthExceeded, k.statement.MaxDepth-1, opts.MaxDepth)
+	}
+	if len(k.statement.Scopes) > 0 {
+		if len(opts.Scopes) == 0 {
+			opts.Scopes = k.statement.Scopes
+		}
+		for _, scope := range opts.Scopes {
+			if !k.statement.allows(scope) {
+				return nil, fmt.Errorf("%w: %q", ErrScope, scope)
+			}
+		}
+	}
+	return delegate(k, k.statement.Subject, k.Chain, k.statement, opts)
+}
+
+func delegate(parent core.Signer, parentKey []byte, chain Chain, parentStatement *Statement, opts Options) (*SubKey, error) {
+	lifetime := opts.Lifetime
+	if lifetime <= 0 {
+		lifetime = DefaultLifetime
+	}
+	if lifetime > MaxLifetime {
+		return nil, fmt.Errorf("sub-key lifetime %s exceeds %s", lifetime, MaxLifetime)
+	}
+	if opts.MaxDepth < 0 {
+		return nil, fmt.Errorf("negative delegation depth %d", opts.MaxDepth)
+	}
+	key, err := core.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	subject, err := key.Public().Marshal()
+	if err != nil {
+		key.Clean()
+		return nil, err
+	}
+	// A minute of slack for the clocks of relying parties
+	now := time.Now().UTC().Truncate(time.Second)
+	s := &Statement{
+		Version:   StatementVersion,
+		Issuer:    parentKey,
+		Subject:   subject,
+		NotBefore: now.Add(-time.Minute),
+		NotAfter:  now.Add(lifetime),
+		Scopes:    opts.Scopes,
+		MaxDepth:  opts.MaxDepth,
+	}
+	if parentStatement != nil && s.NotAfter.After(parentStatement.NotAfter) {
+		s.NotAfter = parentStatement.NotAfter
+	}
+	raw, err := json.Marshal(s)
+	if err != nil {
+		key.Clean()
+		return nil, err
+	}
+	sig, err := core.SignMessage(parent, append([]byte(statementDomain), raw...))
+	if err != nil {
+		key.Clean()
+		return nil, fmt.Errorf("failed to sign delegation: %w", err)
+	}
+	chain = append(slices.Clip(chain), Delegation{Statement: raw, Signature: sig})
+	return &SubKey{Key: key, Chain: chain, statement: s}, nil
+}
+
+// Statement returns the statement authorizing k
+func (k *SubKey) Statement() *Statement {
+	return k.statement
+}
+
+// Sign implements core.Signer, so that sub-keys delegate further. Sign
+// messages with SignMessage, which refuses delegation statements.
+func (k *SubKey) Sign(digest []byte) ([]byte, error) {
+	return k.Key.Sign(digest)
+}
+
+// Public implements core.Signer
+func (k *SubKey) Public() *core.PublicKey {
+	return k.Key.Public()
+}
+
+// Clean releases the sub-key
+func (k *SubKey) Clean() {
+	k.Key.Clean()
+}
+
+// Signature is a message signature by a sub-key with its chain
+type Signature struct {
+	Chain     Chain  `json:"chain"`
+	Signature []byte `json:"signature"`
+}
+
+// SignMessage signs msg with the sub-key
+func (k *SubKey) SignMessage(msg []byte) (*Signature, error) {
+	if bytes.HasPrefix(msg, []byte(statementDomain)) {
+		return nil, errors.New("message looks like a delegation statement")
+	}
+	sig, err := core.SignMessage(k, msg)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{Chain: k.Chain, Signature: sig}, nil
+}
+
+// VerifyOptions configures Verify
+type VerifyOptions struct {
+	// Time is the verification time, now if zero
+	Time time.Time
+	// Scope, when set, must be allowed by every statement of the chain
+	Scope string
+	// Policy applies to every signature, hybrid AND by default
+	Policy core.Policy
+}
+
+// Verify walks the chain of sig from root, the composite public key (DER)
+// of the parent key, and checks the signature of msg by the last sub-key.
+// It returns the statement of that sub-key.
+func Verify(root, msg []byte, sig *Signature, opts VerifyOptions) (*Statement, error) {
+	at := opts.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if len(sig.Chain) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidChain)
+	}
+	issuer := root
+	var parent *Statement
+	for i, d := range sig.Chain {
+		s, err := verifyDelegation(issuer, d, opts.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("delegation %d: %w", i, err)
+		}
+		if at.Before(s.NotBefore) || at.After(s.NotAfter) {
+			return nil, fmt.Errorf("%w: delegation %d is valid from %s to %s", ErrExpired, i, s.NotBefore, s.NotAfter)
+		}
+		if parent != nil {
+			if err := checkNested(parent, s); err != nil {
+				return nil, fmt.Errorf("delegation %d: %w", i, err)
+			}
+		}
+		if opts.Scope != "" && !s.allows(opts.Scope) {
+			return nil, fmt.Errorf("%w: delegation %d does not allow %q", ErrScope, i, opts.Scope)
+		}
+		issuer, parent = s.Subject, s
+	}
+	if err := verifySignature(parent.Subject, msg, sig.Signature, opts.Policy); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return parent, nil
+}
+
+func verifyDelegation(issuer []byte, d Delegation, policy core.Policy) (*Statement, error) {
+	var s Statement
+	if err := json.Unmarshal(d.Statement, &s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChain, err)
+	}
+	if s.Version != StatementVersion {
+		return nil, fmt.Errorf("%w: unsupported statement version %d", ErrInvalidChain, s.Version)
+	}
+	if !bytes.Equal(s.Issuer, issuer) {
+		return nil, fmt.Errorf("%w: issued by another key", ErrInvalidChain)
+	}
+	if err := verifySignature(issuer, append([]byte(statementDomain), d.Statement...), d.Signature, policy); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChain, err)
+	}
+	return &s, nil
+}
+
+// checkNested checks that s stays within the depth, scopes and lifetime of
+// its parent statement
+func checkNested(parent, s *Statement) error {
+	if parent.MaxDepth < 1 || s.MaxDepth > parent.MaxDepth-1 {
+		return ErrDepthExceeded
+	}
+	if len(parent.Scopes) > 0 {
+		if len(s.Scopes) == 0 {
+			return fmt.Errorf("%w: unrestricted below a restricted key", ErrScope)
+		}
+		for _, scope := range s.Scopes {
+			if !parent.allows(scope) {
+				return fmt.Errorf("%w: %q", ErrScope, scope)
+			}
+		}
+	}
+	if s.NotAfter.After(parent.NotAfter) {
+		return fmt.Errorf("%w: outlives its parent", ErrInvalidChain)
+	}
+	return nil
+}
+
+func verifySignature(key, msg, sig []byte, policy core.Policy) error {
+	pub, err := core.ParsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(msg)
+	valid, err := pub.Verify(digest[:], sig, policy)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("signature does not verify")
+	}
+	return nil
+}
+
+// subKeyJSON is the exported form of a sub-key
+type subKeyJSON struct {
+	// ECDSA is the PKCS#8 DER of the ECDSA key
+	ECDSA         []byte `json:"ecdsa"`
+	PQCPrivateKey []byte `json:"pqc_private_key"`
+	PQCPublicKey  []byte `json:"pqc_public_key"`
+	Chain         Chain  `json:"chain"`
+}
+
+// Export encodes k, private key included, to hand it to a worker. Protect it
+// as a private key for its lifetime.
+func (k *SubKey) Export() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Key.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(subKeyJSON{
+		ECDSA:         der,
+		PQCPrivateKey: k.Key.PQC.PrivateKey(),
+		PQCPublicKey:  k.Key.PQC.PublicKey(),
+		Chain:         k.Chain,
+	})
+}
+
+// ParseSubKey decodes a sub-key encoded by Export. The chain is not
+// verified, see Verify.
+func ParseSubKey(raw []byte) (*SubKey, error) {
+	var j subKeyJSON
+	if err := json.Unmarshal(raw, &j); err != nil {
+		return nil, err
+	}
+	if len(j.Chain) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidChain)
+	}
+	var s Statement
+	if err := json.Unmarshal(j.Chain[len(j.Chain)-1].Statement, &s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChain, err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(j.ECDSA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA private key: %w", err)
+	}
+	ecdsaKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unexpected classical key type %T", parsed)
+	}
+	pqc, err := core.NewPQCSignerFromKeyPair(j.PQCPrivateKey, j.PQCPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &core.PrivateKey{ECDSA: ecdsaKey, PQC: pqc}
+	subject, err := key.Public().Marshal()
+	if err != nil {
+		key.Clean()
+		return nil, err
+	}
+	if !bytes.Equal(subject, s.Subject) {
+		key.Clean()
+		return nil, fmt.Errorf("%w: the last statement names another key", ErrInvalidChain)
+	}
+	return &SubKey{Key: key, Chain: j.Chain, statement: &s}, nil
+}
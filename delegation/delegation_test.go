@@ -0,0 +1,106 @@
+package delegation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func newParent(t *testing.T) (core.Signer, []byte) {
+	t.Helper()
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	t.Cleanup(key.Clean)
+	pub, err := key.Public().Marshal()
+	require.NoError(t, err)
+	return key, pub
+}
+
+func TestDelegationChain(t *testing.T) {
+	parent, root := newParent(t)
+	worker, err := Delegate(parent, Options{Lifetime: time.Hour, Scopes: []string{"invoke", "query"}, MaxDepth: 1})
+	require.NoError(t, err)
+	defer worker.Clean()
+	task, err := worker.Delegate(Options{Lifetime: 2 * time.Hour, Scopes: []string{"query"}})
+	require.NoError(t, err)
+	defer task.Clean()
+	assert.Equal(t, worker.Statement().NotAfter, task.Statement().NotAfter, "capped to the parent lifetime")
+	require.Len(t, task.Chain, 2)
+
+	msg := []byte(`{"fcn":"ReadAsset","args":["asset1"]}`)
+	sig, err := task.SignMessage(msg)
+	require.NoError(t, err)
+	s, err := Verify(root, msg, sig, VerifyOptions{Scope: "query"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"query"}, s.Scopes)
+
+	_, err = Verify(root, msg, sig, VerifyOptions{Scope: "invoke"})
+	assert.ErrorIs(t, err, ErrScope)
+	_, err = Verify(root, msg, sig, VerifyOptions{Time: time.Now().Add(90 * time.Minute)})
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = Verify(root, []byte("other"), sig, VerifyOptions{})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, other := newParent(t)
+	_, err = Verify(other, msg, sig, VerifyOptions{})
+	assert.ErrorIs(t, err, ErrInvalidChain)
+
+	_, err = task.Delegate(Options{})
+	assert.ErrorIs(t, err, ErrDepthExceeded)
+	_, err = worker.Delegate(Options{Scopes: []string{"admin"}})
+	assert.ErrorIs(t, err, ErrScope)
+	_, err = Delegate(parent, Options{Lifetime: 48 * time.Hour})
+	assert.Error(t, err)
+}
+
+func TestVerifyRejectsRogueDelegation(t *testing.T) {
+	parent, root := newParent(t)
+	worker, err := Delegate(parent, Options{Scopes: []string{"query"}, MaxDepth: 1})
+	require.NoError(t, err)
+	defer worker.Clean()
+
+	// A worker holding its sub-key signs statements its own does not allow
+	unbounded := &Statement{MaxDepth: 10, NotAfter: time.Now().Add(MaxLifetime)}
+	for name, c := range map[string]struct {
+		opts Options
+		err  error
+	}{
+		"depth":        {Options{Scopes: []string{"query"}, MaxDepth: 1}, ErrDepthExceeded},
+		"scope":        {Options{Scopes: []string{"admin"}}, ErrScope},
+		"unrestricted": {Options{}, ErrScope},
+		"lifetime":     {Options{Scopes: []string{"query"}, Lifetime: time.Hour}, ErrInvalidChain},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rogue, err := delegate(worker, worker.Statement().Subject, worker.Chain, unbounded, c.opts)
+			require.NoError(t, err)
+			defer rogue.Clean()
+			sig, err := rogue.SignMessage([]byte("msg"))
+			require.NoError(t, err)
+			_, err = Verify(root, []byte("msg"), sig, VerifyOptions{})
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+}
+
+func TestExportSubKey(t *testing.T) {
+	parent, root := newParent(t)
+	worker, err := Delegate(parent, Options{})
+	require.NoError(t, err)
+	defer worker.Clean()
+	raw, err := worker.Export()
+	require.NoError(t, err)
+
+	parsed, err := ParseSubKey(raw)
+	require.NoError(t, err)
+	defer parsed.Clean()
+	assert.Equal(t, worker.Statement(), parsed.Statement())
+	sig, err := parsed.SignMessage([]byte("msg"))
+	require.NoError(t, err)
+	_, err = Verify(root, []byte("msg"), sig, VerifyOptions{})
+	require.NoError(t, err)
+
+	_, err = parsed.SignMessage([]byte(statementDomain + "{}"))
+	assert.Error(t, err)
+}
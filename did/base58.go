@@ -0,0 +1,54 @@
+package did
+
+import (
+	"errors"
+	"math/big"
+)
+
+// base58btc is the Bitcoin alphabet, multibase prefix 'z'
+const base58btc = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	var out []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, bigRadix, mod)
+		out = append(out, base58btc[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58btc[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		d := -1
+		for j := 0; j < len(base58btc); j++ {
+			if base58btc[j] == s[i] {
+				d = j
+				break
+			}
+		}
+		if d < 0 {
+			return nil, errors.New("invalid base58btc character")
+		}
+		n.Mul(n, bigRadix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58btc[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,220 @@
+// Package did describes hybrid identities as W3C DID documents with two
+// verification methods, the ECDSA P-256 key and the ML-DSA-65 key, both
+// encoded as Multikey. Two methods are supported:
+//
+//   - did:web, where the document is served at https://<domain>/<path>/did.json
+//     (or /.well-known/did.json) and names the key explicitly;
+//   - a did:key-style identifier, self-certifying, whose method-specific id
+//     is the multibase encoding of the whole composite public key.
+//
+// Verifiers must check both signatures; a document is only accepted when it
+// carries exactly one method of each kind.
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Multicodec codes of the keys. Composite keys have no registered code yet,
+// compositeCodec is in the private use range.
+const (
+	p256PubCodec   = 0x1200
+	mldsa65Codec   = 0x1211
+	compositeCodec = 0x300001
+)
+
+const (
+	// MultikeyType is the verification method type of both keys
+	MultikeyType = "Multikey"
+	// ECDSAFragment and MLDSAFragment name the verification methods
+	ECDSAFragment = "ecdsa"
+	MLDSAFragment = "mldsa"
+)
+
+// Contexts of every document
+var Contexts = []string{"https://www.w3.org/ns/did/v1", "https://w3id.org/security/multikey/v1"}
+
+var (
+	// ErrUnsupportedMethod is returned for DIDs other than did:web and did:key
+	ErrUnsupportedMethod = errors.New("unsupported DID method")
+	// ErrInvalidDocument is returned for documents not describing a hybrid key
+	ErrInvalidDocument = errors.New("invalid hybrid DID document")
+)
+
+// VerificationMethod is a public key of the subject
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+}
+
+// Document is a DID document of a hybrid identity
+type Document struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	Authentication     []string             `json:"authentication"`
+	AssertionMethod    []string             `json:"assertionMethod"`
+}
+
+// WebDID returns did:web:<domain>[:<path>...], percent-encoding a port
+func WebDID(domain string, path ...string) string {
+	parts := append([]string{"did", "web", strings.ReplaceAll(domain, ":", "%3A")}, path...)
+	return strings.Join(parts, ":")
+}
+
+// KeyDID returns the did:key-style identifier of pub
+func KeyDID(pub *core.PublicKey) (string, error) {
+	composite, err := pub.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return "did:key:" + multikey(compositeCodec, composite), nil
+}
+
+// NewDocument returns the document of id with the two keys of pub
+func NewDocument(id string, pub *core.PublicKey) (*Document, error) {
+	if pub.ECDSA == nil || pub.ECDSA.Curve != elliptic.P256() || len(pub.PQC) == 0 {
+		return nil, fmt.Errorf("%w: expected a P-256 + %s key", ErrInvalidDocument, core.PQCAlgorithm)
+	}
+	ecdsaID, mldsaID := id+"#"+ECDSAFragment, id+"#"+MLDSAFragment
+	compressed := elliptic.MarshalCompressed(pub.ECDSA.Curve, pub.ECDSA.X, pub.ECDSA.Y)
+	return &Document{
+		Context: Contexts,
+		ID:      id,
+		VerificationMethod: []VerificationMethod{
+			{ID: ecdsaID, Type: MultikeyType, Controller: id, PublicKeyMultibase: multikey(p256PubCodec, compressed)},
+			{ID: mldsaID, Type: MultikeyType, Controller: id, PublicKeyMultibase: multikey(mldsa65Codec, pub.PQC)},
+		},
+		Authentication:  []string{ecdsaID, mldsaID},
+		AssertionMethod: []string{ecdsaID, mldsaID},
+	}, nil
+}
+
+// PublicKey returns the hybrid key of d after checking that it has exactly
+// one ECDSA and one ML-DSA method controlled by d.ID
+func (d *Document) PublicKey() (*core.PublicKey, error) {
+	pub := &core.PublicKey{}
+	for _, vm := range d.VerificationMethod {
+		if vm.Type != MultikeyType || vm.Controller != d.ID || !strings.HasPrefix(vm.ID, d.ID+"#") {
+			return nil, fmt.Errorf("%w: unexpected verification method %s", ErrInvalidDocument, vm.ID)
+		}
+		codec, key, err := parseMultikey(vm.PublicKeyMultibase)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidDocument, vm.ID, err)
+		}
+		switch {
+		case codec == p256PubCodec && pub.ECDSA == nil:
+			x, y := elliptic.UnmarshalCompressed(elliptic.P256(), key)
+			if x == nil {
+				return nil, fmt.Errorf("%w: %s: invalid P-256 key", ErrInvalidDocument, vm.ID)
+			}
+			pub.ECDSA = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		case codec == mldsa65Codec && pub.PQC == nil:
+			pub.PQC = key
+		default:
+			return nil, fmt.Errorf("%w: %s: unexpected key type 0x%x", ErrInvalidDocument, vm.ID, codec)
+		}
+	}
+	if pub.ECDSA == nil || pub.PQC == nil {
+		return nil, fmt.Errorf("%w: both an ECDSA and an ML-DSA key are required", ErrInvalidDocument)
+	}
+	return pub, nil
+}
+
+// Verify checks a hybrid signature envelope over digest with the keys of d
+func (d *Document) Verify(digest, signature []byte) (bool, error) {
+	pub, err := d.PublicKey()
+	if err != nil {
+		return false, err
+	}
+	return pub.Verify(digest, signature, core.PolicyHybridAND)
+}
+
+// Fetcher retrieves a did:web document, e.g. with an HTTPS client
+type Fetcher func(url string) ([]byte, error)
+
+// Resolve returns the document of id. did:key identifiers are expanded
+// locally; did:web documents are fetched and must have id as their ID.
+func Resolve(id string, fetch Fetcher) (*Document, error) {
+	switch {
+	case strings.HasPrefix(id, "did:key:"):
+		codec, composite, err := parseMultikey(strings.TrimPrefix(id, "did:key:"))
+		if err != nil {
+			return nil, err
+		}
+		if codec != compositeCodec {
+			return nil, fmt.Errorf("%w: did:key of type 0x%x", ErrUnsupportedMethod, codec)
+		}
+		pub, err := core.ParsePublicKey(composite)
+		if err != nil {
+			return nil, err
+		}
+		return NewDocument(id, pub)
+	case strings.HasPrefix(id, "did:web:"):
+		u, err := WebURL(id)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := fetch(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+		}
+		var d Document
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+		}
+		if d.ID != id {
+			return nil, fmt.Errorf("%w: document of %s served for %s", ErrInvalidDocument, d.ID, id)
+		}
+		if _, err := d.PublicKey(); err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMethod, id)
+}
+
+// WebURL returns the HTTPS URL of a did:web document
+func WebURL(id string) (string, error) {
+	rest, ok := strings.CutPrefix(id, "did:web:")
+	parts := strings.Split(rest, ":")
+	domain, err := url.PathUnescape(parts[0])
+	if !ok || err != nil || domain == "" {
+		return "", fmt.Errorf("invalid did:web %q", id)
+	}
+	if len(parts) == 1 {
+		return "https://" + domain + "/.well-known/did.json", nil
+	}
+	return "https://" + domain + "/" + strings.Join(parts[1:], "/") + "/did.json", nil
+}
+
+// multikey is the base58btc multibase encoding of the multicodec-prefixed key
+func multikey(codec uint64, key []byte) string {
+	return "z" + base58Encode(append(binary.AppendUvarint(nil, codec), key...))
+}
+
+func parseMultikey(s string) (uint64, []byte, error) {
+	if !strings.HasPrefix(s, "z") {
+		return 0, nil, errors.New("expected a base58btc multibase value")
+	}
+	raw, err := base58Decode(s[1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	codec, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return 0, nil, errors.New("invalid multicodec prefix")
+	}
+	return codec, raw[n:], nil
+}
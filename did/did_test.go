@@ -0,0 +1,91 @@
+package did
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestBase58(t *testing.T) {
+	for _, b := range [][]byte{{}, {0}, {0, 0, 1}, []byte("hello world")} {
+		got, err := base58Decode(base58Encode(b))
+		require.NoError(t, err)
+		assert.Equal(t, b, got)
+	}
+	assert.Equal(t, "StV1DL6CwTryKyV", base58Encode([]byte("hello world")))
+}
+
+func TestKeyDID(t *testing.T) {
+	priv, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+
+	id, err := KeyDID(priv.Public())
+	require.NoError(t, err)
+	assert.Regexp(t, `^did:key:z[1-9A-HJ-NP-Za-km-z]+$`, id)
+
+	doc, err := Resolve(id, nil)
+	require.NoError(t, err)
+	assert.Equal(t, id, doc.ID)
+	require.Len(t, doc.VerificationMethod, 2)
+	assert.Equal(t, []string{id + "#ecdsa", id + "#mldsa"}, doc.AssertionMethod)
+
+	pub, err := doc.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, pub.ECDSA.Equal(&priv.ECDSA.PublicKey))
+	assert.Equal(t, priv.PQC.PublicKey(), pub.PQC)
+
+	digest := sha256.Sum256([]byte("credential"))
+	sig, err := priv.Sign(digest[:])
+	require.NoError(t, err)
+	valid, err := doc.Verify(digest[:], sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestWebDID(t *testing.T) {
+	priv, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer priv.Clean()
+
+	id := WebDID("example.com:8443", "orgs", "org1")
+	assert.Equal(t, "did:web:example.com%3A8443:orgs:org1", id)
+	u, err := WebURL(id)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:8443/orgs/org1/did.json", u)
+	u, err = WebURL(WebDID("example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/.well-known/did.json", u)
+
+	doc, err := NewDocument(id, priv.Public())
+	require.NoError(t, err)
+	served, err := json.Marshal(doc)
+	require.NoError(t, err)
+	fetch := func(url string) ([]byte, error) {
+		if url != "https://example.com:8443/orgs/org1/did.json" {
+			return nil, errors.New("not found")
+		}
+		return served, nil
+	}
+
+	resolved, err := Resolve(id, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, doc, resolved)
+
+	// A document served for another DID is rejected
+	_, err = Resolve(WebDID("example.com:8443", "orgs", "org2"), func(string) ([]byte, error) { return served, nil })
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	// Both methods are required
+	doc.VerificationMethod = doc.VerificationMethod[:1]
+	_, err = doc.PublicKey()
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	_, err = Resolve("did:ethr:0x1234", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedMethod)
+}
@@ -0,0 +1,74 @@
+package ers
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/bundle"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// SignatureAttestor attests digests with the hybrid signature of an
+// archive authority
+type SignatureAttestor struct {
+	Signer core.Signer
+	// Certificates is the authority certificate followed by its issuers
+	Certificates []*hybridx509.Certificate
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// Attest signs digest and the current time
+func (a *SignatureAttestor) Attest(_ crypto.Hash, digest []byte) (*Stamp, error) {
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	s := &Stamp{
+		Kind:      KindSignature,
+		Algorithm: "ML-DSA-65+ECDSA-P256",
+		Time:      now().UTC().Truncate(time.Second),
+		Digest:    digest,
+	}
+	for _, c := range a.Certificates {
+		s.Certificates = append(s.Certificates, c.Raw)
+	}
+	sig, err := a.Signer.Sign(signedDigest(s))
+	if err != nil {
+		return nil, fmt.Errorf("archive signature failed: %w", err)
+	}
+	s.Signature = sig
+	return s, nil
+}
+
+// TimestampAttestor attests digests with an RFC 3161 time-stamp authority
+type TimestampAttestor struct {
+	// Request returns the TimeStampResp for a request with the given
+	// message imprint, e.g. by posting it to the TSA
+	Request func(hash crypto.Hash, digest []byte) ([]byte, error)
+}
+
+// Attest requests a time-stamp of digest
+func (a *TimestampAttestor) Attest(hash crypto.Hash, digest []byte) (*Stamp, error) {
+	token, err := a.Request(hash, digest)
+	if err != nil {
+		return nil, fmt.Errorf("time-stamp request failed: %w", err)
+	}
+	ts, err := bundle.ParseTimestampToken(token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ts.Hash != hash || !bytes.Equal(ts.Imprint, digest) {
+		return nil, fmt.Errorf("%w: time-stamp is not for the requested imprint", bundle.ErrInvalidTimestamp)
+	}
+	return &Stamp{
+		Kind:      KindTimestamp,
+		Algorithm: ts.TSA.PublicKeyAlgorithm.String(),
+		Time:      ts.Time,
+		Digest:    digest,
+		Token:     token,
+	}, nil
+}
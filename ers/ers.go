@@ -0,0 +1,352 @@
+// Package ers keeps archived hybrid signatures verifiable as algorithms
+// age, with evidence records inspired by RFC 4998. A record is a sequence
+// of chains; every chain uses one hash algorithm and holds archive stamps,
+// each attesting that a hash existed at some time, either as an RFC 3161
+// time-stamp or as a hybrid signature of an archive authority.
+//
+// The first stamp covers the archived objects. Renew adds a stamp over the
+// previous one before its attestation weakens (timestamp renewal); Rehash
+// starts a chain with a new hash algorithm over the objects and the whole
+// previous record (hash-tree renewal). Verify walks the record from the
+// first stamp and returns the time the objects are proven to exist.
+package ers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/bundle"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Version is the record version written by this package
+const Version = 1
+
+// Stamp kinds
+const (
+	KindTimestamp = "rfc3161"
+	KindSignature = "hybrid-signature"
+)
+
+// Reasons recorded on chains
+const (
+	ReasonInitial = "initial"
+	// ReasonHashRenewal is the default reason of Rehash
+	ReasonHashRenewal = "hash-renewal"
+)
+
+// domain separates stamp encodings from other hashed data
+const domain = "QLERS1"
+
+var (
+	// ErrInvalidRecord is returned for records that do not verify
+	ErrInvalidRecord = errors.New("invalid evidence record")
+	// ErrUnsupportedHash is returned for unknown hash algorithm names
+	ErrUnsupportedHash = errors.New("unsupported hash algorithm")
+)
+
+// Stamp attests that Digest existed at Time
+type Stamp struct {
+	Kind string `json:"kind"`
+	// Algorithm is the attestation algorithm, kept as migration metadata,
+	// e.g. ML-DSA-65+ECDSA-P256 or the TSA signature algorithm
+	Algorithm string    `json:"algorithm"`
+	Time      time.Time `json:"time"`
+	Digest    []byte    `json:"digest"`
+	// Token is the RFC 3161 response of KindTimestamp stamps
+	Token []byte `json:"token,omitempty"`
+	// Signature and Certificates (DER, signer first) are the hybrid
+	// signature of Digest and the archive authority chain of
+	// KindSignature stamps
+	Signature    []byte   `json:"signature,omitempty"`
+	Certificates [][]byte `json:"certificates,omitempty"`
+}
+
+// bytes is the encoding hashed by the next stamp
+func (s *Stamp) bytes() []byte {
+	b := core.NewSignedBytes(domain).
+		AppendBytes([]byte(s.Kind)).
+		AppendBytes([]byte(s.Algorithm)).
+		AppendUint64(uint64(s.Time.Unix())).
+		AppendBytes(s.Digest).
+		AppendBytes(s.Token).
+		AppendBytes(s.Signature)
+	for _, c := range s.Certificates {
+		b = b.AppendBytes(c)
+	}
+	return b
+}
+
+// Chain is a sequence of stamps using one hash algorithm
+type Chain struct {
+	Hash      string    `json:"hash"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	Stamps    []*Stamp  `json:"stamps"`
+}
+
+// Record is an evidence record over a group of archived objects
+type Record struct {
+	Version int      `json:"version"`
+	Chains  []*Chain `json:"chains"`
+}
+
+// Attestor attests digests
+type Attestor interface {
+	Attest(hash crypto.Hash, digest []byte) (*Stamp, error)
+}
+
+// New starts a record over objects, e.g. a signature bundle and its
+// message, hashing with hash (SHA-256, SHA-384, SHA-512 or SHA3-256)
+func New(hash string, objects [][]byte, a Attestor) (*Record, error) {
+	h, ok := bundle.HashByName(hash)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, hash)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("no objects to archive")
+	}
+	s, err := a.Attest(h, objectsDigest(h, objects))
+	if err != nil {
+		return nil, err
+	}
+	return &Record{Version: Version, Chains: []*Chain{{Hash: hash, Reason: ReasonInitial, CreatedAt: s.Time, Stamps: []*Stamp{s}}}}, nil
+}
+
+// Renew adds a stamp over the last one with the same hash algorithm, e.g.
+// before the certificate of its TSA or archive authority expires or when
+// its signature algorithm weakens
+func (r *Record) Renew(a Attestor) (*Stamp, error) {
+	chain := r.Chains[len(r.Chains)-1]
+	h, ok := bundle.HashByName(chain.Hash)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, chain.Hash)
+	}
+	s, err := a.Attest(h, bundle.Sum(h, chain.Stamps[len(chain.Stamps)-1].bytes()))
+	if err != nil {
+		return nil, err
+	}
+	chain.Stamps = append(chain.Stamps, s)
+	return s, nil
+}
+
+// Rehash starts a chain with a new hash algorithm, covering the objects and
+// every previous chain, before the current hash algorithm weakens. An empty
+// reason records ReasonHashRenewal.
+func (r *Record) Rehash(hash string, objects [][]byte, reason string, a Attestor) (*Stamp, error) {
+	h, ok := bundle.HashByName(hash)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, hash)
+	}
+	if reason == "" {
+		reason = ReasonHashRenewal
+	}
+	s, err := a.Attest(h, renewalDigest(h, objects, r.Chains))
+	if err != nil {
+		return nil, err
+	}
+	r.Chains = append(r.Chains, &Chain{Hash: hash, Reason: reason, CreatedAt: s.Time, Stamps: []*Stamp{s}})
+	return s, nil
+}
+
+// Marshal encodes r as JSON
+func (r *Record) Marshal() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Parse decodes a JSON record
+func Parse(data []byte) (*Record, error) {
+	r := &Record{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+	}
+	if r.Version != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidRecord, r.Version)
+	}
+	if len(r.Chains) == 0 {
+		return nil, fmt.Errorf("%w: no chains", ErrInvalidRecord)
+	}
+	for i, c := range r.Chains {
+		if len(c.Stamps) == 0 {
+			return nil, fmt.Errorf("%w: chain %d has no stamps", ErrInvalidRecord, i)
+		}
+	}
+	return r, nil
+}
+
+// VerifyOptions configures Record.Verify
+type VerifyOptions struct {
+	// TSARoots, when set, must issue the TSA certificates of time-stamps
+	TSARoots *x509.CertPool
+	// Roots, when set, must include the root of every archive authority
+	Roots []*hybridx509.Certificate
+}
+
+// Result describes a verified record
+type Result struct {
+	// Existed is the time of the first stamp: the objects existed then
+	Existed time.Time
+	// Hashes are the hash algorithms of the chains, oldest first
+	Hashes []string
+	// Stamps counts the verified stamps
+	Stamps int
+}
+
+// Verify checks that the record covers objects. Every stamp must attest
+// the hash it is expected to cover and must have been added while the
+// previous one could still be verified, i.e. before the certificate that
+// attested it expired.
+func (r *Record) Verify(objects [][]byte, opts VerifyOptions) (*Result, error) {
+	res := &Result{}
+	var prev *Stamp
+	var prevExpiry time.Time
+	for i, chain := range r.Chains {
+		h, ok := bundle.HashByName(chain.Hash)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, chain.Hash)
+		}
+		for j, s := range chain.Stamps {
+			var want []byte
+			switch {
+			case i == 0 && j == 0:
+				want = objectsDigest(h, objects)
+			case j == 0:
+				want = renewalDigest(h, objects, r.Chains[:i])
+			default:
+				want = bundle.Sum(h, prev.bytes())
+			}
+			if !bytes.Equal(s.Digest, want) {
+				return nil, fmt.Errorf("%w: chain %d stamp %d does not cover the expected hash", ErrInvalidRecord, i, j)
+			}
+			expiry, err := s.verify(h, opts)
+			if err != nil {
+				return nil, fmt.Errorf("chain %d stamp %d: %w", i, j, err)
+			}
+			if prev != nil && (s.Time.Before(prev.Time) || s.Time.After(prevExpiry)) {
+				return nil, fmt.Errorf("%w: chain %d stamp %d was not added while the previous stamp was valid", ErrInvalidRecord, i, j)
+			}
+			if prev == nil {
+				res.Existed = s.Time
+			}
+			prev, prevExpiry = s, expiry
+			res.Stamps++
+		}
+		res.Hashes = append(res.Hashes, chain.Hash)
+	}
+	return res, nil
+}
+
+// verify checks the attestation of s and returns when its certificate expires
+func (s *Stamp) verify(h crypto.Hash, opts VerifyOptions) (time.Time, error) {
+	switch s.Kind {
+	case KindTimestamp:
+		ts, err := bundle.ParseTimestampToken(s.Token, opts.TSARoots)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if ts.Hash != h || !bytes.Equal(ts.Imprint, s.Digest) || !ts.Time.Equal(s.Time) {
+			return time.Time{}, fmt.Errorf("%w: time-stamp does not match the stamp", ErrInvalidRecord)
+		}
+		return ts.TSA.NotAfter, nil
+	case KindSignature:
+		return s.verifySignature(opts.Roots)
+	default:
+		return time.Time{}, fmt.Errorf("%w: unknown stamp kind %q", ErrInvalidRecord, s.Kind)
+	}
+}
+
+func (s *Stamp) verifySignature(roots []*hybridx509.Certificate) (time.Time, error) {
+	if len(s.Certificates) == 0 {
+		return time.Time{}, fmt.Errorf("%w: signature stamp without certificates", ErrInvalidRecord)
+	}
+	certs := make([]*hybridx509.Certificate, len(s.Certificates))
+	for i, der := range s.Certificates {
+		cert, err := hybridx509.ParseCertificate(der)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if s.Time.Before(cert.NotBefore) || s.Time.After(cert.NotAfter) {
+			return time.Time{}, fmt.Errorf("%w: %s not valid at %s", ErrInvalidRecord, cert.Subject.CommonName, s.Time.Format(time.RFC3339))
+		}
+		if i > 0 {
+			if err := certs[i-1].CheckSignatureFrom(cert); err != nil {
+				return time.Time{}, fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+			}
+		}
+		certs[i] = cert
+	}
+	if len(roots) > 0 && !trusted(roots, certs[len(certs)-1]) {
+		return time.Time{}, fmt.Errorf("%w: untrusted archive authority", ErrInvalidRecord)
+	}
+	composite, err := certs[0].CompositePublicKey()
+	if err != nil {
+		return time.Time{}, err
+	}
+	pub, err := core.ParsePublicKey(composite)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The signed digest binds the claimed time
+	valid, err := pub.Verify(signedDigest(s), s.Signature, core.PolicyHybridAND)
+	if err != nil || !valid {
+		return time.Time{}, fmt.Errorf("%w: invalid archive signature", ErrInvalidRecord)
+	}
+	return certs[0].NotAfter, nil
+}
+
+func trusted(roots []*hybridx509.Certificate, cert *hybridx509.Certificate) bool {
+	for _, root := range roots {
+		if bytes.Equal(root.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectsDigest is the root of the one-level reduced hash tree of RFC 4998:
+// the hash of the sorted object hashes, or the object hash alone
+func objectsDigest(h crypto.Hash, objects [][]byte) []byte {
+	hashes := make([][]byte, len(objects))
+	for i, o := range objects {
+		hashes[i] = bundle.Sum(h, o)
+	}
+	return reduce(h, hashes)
+}
+
+// renewalDigest covers the objects and the previous chains with a new hash
+// (RFC 4998, section 5.2)
+func renewalDigest(h crypto.Hash, objects [][]byte, chains []*Chain) []byte {
+	var prev core.SignedBytes
+	for _, c := range chains {
+		prev = prev.AppendBytes([]byte(c.Hash))
+		for _, s := range c.Stamps {
+			prev = prev.AppendBytes(s.bytes())
+		}
+	}
+	return reduce(h, [][]byte{objectsDigest(h, objects), bundle.Sum(h, prev)})
+}
+
+func reduce(h crypto.Hash, hashes [][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	sorted := append([][]byte(nil), hashes...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return bundle.Sum(h, bytes.Join(sorted, nil))
+}
+
+// signedDigest is what an archive authority signs: the SHA-256 of the
+// stamp digest and time
+func signedDigest(s *Stamp) []byte {
+	msg := append([]byte(domain), s.Digest...)
+	msg = binary.BigEndian.AppendUint64(msg, uint64(s.Time.Unix()))
+	return bundle.Sum(crypto.SHA256, msg)
+}
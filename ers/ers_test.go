@@ -0,0 +1,81 @@
+package ers
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// authority issues an archive authority valid for validity
+func authority(t *testing.T, root *ca.CA, name string, validity time.Duration, now time.Time) *SignatureAttestor {
+	id, err := root.Issue(ca.Request{CommonName: name, Validity: validity})
+	require.NoError(t, err)
+	return &SignatureAttestor{
+		Signer:       &core.PrivateKey{ECDSA: id.Key.ECDSA, PQC: id.Key.PQC},
+		Certificates: []*hybridx509.Certificate{id.Cert, root.Cert},
+		Now:          func() time.Time { return now },
+	}
+}
+
+func TestRecord(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "archive-ca.example.com"}, 0)
+	require.NoError(t, err)
+	now := time.Now()
+	first := authority(t, root, "archive1.example.com", 24*time.Hour, now)
+	second := authority(t, root, "archive2.example.com", 0, now.Add(time.Hour))
+
+	objects := [][]byte{[]byte("bundle.zip"), []byte("message")}
+	r, err := New("SHA-256", objects, first)
+	require.NoError(t, err)
+	_, err = r.Renew(second)
+	require.NoError(t, err)
+	_, err = r.Rehash("SHA3-256", objects, "SHA-256 deprecated", second)
+	require.NoError(t, err)
+	_, err = r.Renew(second)
+	require.NoError(t, err)
+
+	raw, err := r.Marshal()
+	require.NoError(t, err)
+	parsed, err := Parse(raw)
+	require.NoError(t, err)
+	res, err := parsed.Verify(objects, VerifyOptions{Roots: []*hybridx509.Certificate{root.Cert}})
+	require.NoError(t, err)
+	assert.Equal(t, now.UTC().Truncate(time.Second), res.Existed)
+	assert.Equal(t, []string{"SHA-256", "SHA3-256"}, res.Hashes)
+	assert.Equal(t, 4, res.Stamps)
+	assert.Equal(t, "SHA-256 deprecated", parsed.Chains[1].Reason)
+
+	_, err = parsed.Verify([][]byte{[]byte("bundle.zip"), []byte("forged")}, VerifyOptions{})
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	other, err := ca.NewRoot(pkix.Name{CommonName: "other.example.com"}, 0)
+	require.NoError(t, err)
+	_, err = parsed.Verify(objects, VerifyOptions{Roots: []*hybridx509.Certificate{other.Cert}})
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	// Stamps cannot be altered after the fact
+	parsed.Chains[0].Stamps[0].Time = now.Add(-time.Hour)
+	_, err = parsed.Verify(objects, VerifyOptions{})
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+}
+
+func TestRenewAfterExpiry(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "archive-ca.example.com"}, 0)
+	require.NoError(t, err)
+	now := time.Now()
+	objects := [][]byte{[]byte("bundle.zip")}
+	r, err := New("SHA-256", objects, authority(t, root, "archive1.example.com", time.Hour, now))
+	require.NoError(t, err)
+
+	// Renewing once the first authority expired is too late
+	_, err = r.Renew(authority(t, root, "archive2.example.com", 0, now.Add(2*time.Hour)))
+	require.NoError(t, err)
+	_, err = r.Verify(objects, VerifyOptions{})
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+}
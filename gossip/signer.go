@@ -0,0 +1,126 @@
+// Package gossip adapts the hybrid BCCSP to Fabric's gossip layer, so that
+// membership and state-transfer messages can be hybrid-signed like
+// transactions.
+package gossip
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// DefaultCacheSize bounds the number of peer identities kept parsed
+const DefaultCacheSize = 1024
+
+// IdentityDeserializer turns a peer identity into a verification key
+type IdentityDeserializer interface {
+	DeserializeIdentity(identity []byte) (bccsp.Key, error)
+}
+
+// CertificateDeserializer accepts serialized MSP identities (or bare PEM)
+// holding a composite certificate and imports its key into CSP
+type CertificateDeserializer struct {
+	CSP bccsp.BCCSP
+}
+
+// DeserializeIdentity implements IdentityDeserializer
+func (d *CertificateDeserializer) DeserializeIdentity(identity []byte) (bccsp.Key, error) {
+	id, err := (&msp.Deserializer{CSP: d.CSP}).DeserializeIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	return id.Key, nil
+}
+
+// MessageSigner signs and verifies gossip payloads with identity keys. It
+// has the shape of the Sign/Verify half of gossip's MessageCryptoService.
+type MessageSigner struct {
+	csp          bccsp.BCCSP
+	key          bccsp.Key
+	deserializer IdentityDeserializer
+
+	mutex     sync.Mutex
+	cache     map[[sha256.Size]byte]bccsp.Key
+	order     [][sha256.Size]byte
+	cacheSize int
+}
+
+// NewMessageSigner signs with key and verifies with keys obtained from
+// deserializer. cacheSize <= 0 selects DefaultCacheSize.
+func NewMessageSigner(csp bccsp.BCCSP, key bccsp.Key, deserializer IdentityDeserializer, cacheSize int) *MessageSigner {
+	if cacheSize <= 0 {
+		cacheSize = DefaultCacheSize
+	}
+	return &MessageSigner{
+		csp:          csp,
+		key:          key,
+		deserializer: deserializer,
+		cache:        map[[sha256.Size]byte]bccsp.Key{},
+		cacheSize:    cacheSize,
+	}
+}
+
+// Sign signs msg with the local identity key
+func (s *MessageSigner) Sign(msg []byte) ([]byte, error) {
+	if s.key == nil {
+		return nil, errors.New("no signing key configured")
+	}
+	digest, err := s.csp.Hash(msg, &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, err
+	}
+	return s.csp.Sign(s.key, digest, nil)
+}
+
+// Verify checks that signature is a valid signature of message by peerIdentity
+func (s *MessageSigner) Verify(peerIdentity []byte, signature, message []byte) error {
+	key, err := s.identityKey(peerIdentity)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize peer identity: %w", err)
+	}
+	digest, err := s.csp.Hash(message, &bccsp.SHA256Opts{})
+	if err != nil {
+		return err
+	}
+	valid, err := s.csp.Verify(key, signature, digest, nil)
+	if err != nil {
+		return fmt.Errorf("could not verify gossip message signature: %w", err)
+	}
+	if !valid {
+		return errors.New("invalid gossip message signature")
+	}
+	return nil
+}
+
+// identityKey returns the cached key of identity, deserializing it on a miss.
+// The oldest entry is evicted once the cache is full.
+func (s *MessageSigner) identityKey(identity []byte) (bccsp.Key, error) {
+	id := sha256.Sum256(identity)
+	s.mutex.Lock()
+	key, ok := s.cache[id]
+	s.mutex.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := s.deserializer.DeserializeIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.cache[id]; !ok {
+		if len(s.order) >= s.cacheSize {
+			delete(s.cache, s.order[0])
+			s.order = s.order[1:]
+		}
+		s.cache[id] = key
+		s.order = append(s.order, id)
+	}
+	return key, nil
+}
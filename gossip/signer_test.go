@@ -0,0 +1,72 @@
+package gossip
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+type countingDeserializer struct {
+	IdentityDeserializer
+	calls int
+}
+
+func (c *countingDeserializer) DeserializeIdentity(identity []byte) (bccsp.Key, error) {
+	c.calls++
+	return c.IdentityDeserializer.DeserializeIdentity(identity)
+}
+
+func TestMessageSignerWithCertificateIdentities(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+
+	// Self-signed composite identity of the remote peer
+	peerKey, err := hybridx509.GenerateKey()
+	require.NoError(t, err)
+	der, err := hybridx509.CreateCertificate(&x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "peer1.org1.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, nil, peerKey.Public(), peerKey)
+	require.NoError(t, err)
+	identity := (&fabproto.SerializedIdentity{Mspid: "Org1MSP", IdBytes: hybridx509.EncodeCertificatePEM(der)}).Marshal()
+
+	// The remote peer signs with the key of its certificate
+	remoteKey, err := csp.KeyImport(peerKey.Raw(), &hybrid.HybridPrivateKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	remote := NewMessageSigner(csp, remoteKey, nil, 0)
+	msg := []byte("alive message")
+	sig, err := remote.Sign(msg)
+	require.NoError(t, err)
+
+	deserializer := &countingDeserializer{IdentityDeserializer: &CertificateDeserializer{CSP: csp}}
+	local, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	signer := NewMessageSigner(csp, local, deserializer, 1)
+
+	require.NoError(t, signer.Verify(identity, sig, msg))
+	require.NoError(t, signer.Verify(identity, sig, msg))
+	assert.Equal(t, 1, deserializer.calls, "identity should be cached")
+	assert.Error(t, signer.Verify(identity, sig, []byte("tampered")))
+
+	// Local Sign round trip through a public key verification
+	ownSig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	pub, err := local.PublicKey()
+	require.NoError(t, err)
+	digest, err := csp.Hash(msg, &bccsp.SHA256Opts{})
+	require.NoError(t, err)
+	valid, err := csp.Verify(pub, ownSig, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
@@ -0,0 +1,145 @@
+package inclusion
+
+import (
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+	"github.com/yourusername/quantum-ledger/orderer"
+)
+
+func TestMerklePath(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		var leaves [][]byte
+		for i := 0; i < n; i++ {
+			leaves = append(leaves, LeafHash([]byte(fmt.Sprintf("tx%d", i))))
+		}
+		root := MerkleRoot(leaves)
+		for i := range leaves {
+			path := MerklePath(leaves, i)
+			assert.True(t, VerifyPath(leaves[i], i, n, path, root), "n=%d i=%d", n, i)
+			assert.False(t, VerifyPath(LeafHash([]byte("forged")), i, n, path, root), "n=%d i=%d", n, i)
+			assert.False(t, VerifyPath(leaves[i], n, n, path, root), "n=%d i=%d", n, i)
+		}
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	var leaves [][]byte
+	for i := 0; i < 17; i++ {
+		leaves = append(leaves, LeafHash([]byte(fmt.Sprintf("cert%d", i))))
+	}
+	for n := 1; n <= len(leaves); n++ {
+		root := MerkleRoot(leaves[:n])
+		for m := 0; m <= n; m++ {
+			proof := ConsistencyProof(leaves[:n], m)
+			old := MerkleRoot(leaves[:m])
+			assert.True(t, VerifyConsistency(m, n, old, root, proof), "m=%d n=%d", m, n)
+			if m > 0 && m < n {
+				forged := MerkleRoot(append(append([][]byte(nil), leaves[:m-1]...), LeafHash([]byte("forged"))))
+				assert.False(t, VerifyConsistency(m, n, forged, root, proof), "m=%d n=%d", m, n)
+				assert.False(t, VerifyConsistency(m, n, old, forged, proof), "m=%d n=%d", m, n)
+			}
+		}
+		assert.False(t, VerifyConsistency(n+1, n, root, root, nil))
+	}
+}
+
+func envelope(channel, txID string) []byte {
+	chdr := &fabproto.ChannelHeader{Type: 3, ChannelId: channel, TxId: txID}
+	payload := &fabproto.Payload{Header: &fabproto.Header{ChannelHeader: chdr.Marshal()}, Data: []byte("tx " + txID)}
+	return (&fabproto.Envelope{Payload: payload.Marshal(), Signature: []byte("sig")}).Marshal()
+}
+
+func TestProver(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	id, err := root.Issue(ca.Request{CommonName: "orderer0.example.com", OrganizationalUnit: "orderer"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(mspDir, root, id, ca.MSPOptions{}))
+	signer, err := msp.LoadSigningIdentity(csp, mspDir, "OrdererMSP")
+	require.NoError(t, err)
+	sigs, err := orderer.BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": mspDir}}.NewVerifier(csp)
+	require.NoError(t, err)
+	pub, err := signer.Certificate.CompositePublicKey()
+	require.NoError(t, err)
+
+	data := [][]byte{envelope("mychannel", "tx0"), envelope("mychannel", "tx1"), envelope("mychannel", "tx2")}
+	b := &fabproto.Block{Header: &fabproto.BlockHeader{Number: 7, DataHash: blockverify.DataHash(data)}, Data: data}
+	require.NoError(t, orderer.NewBlockSigner(signer).SignBlock(b, nil))
+
+	key, err := signer.Signer()
+	require.NoError(t, err)
+	p, err := NewProver("mychannel", key, blockverify.NewVerifier(sigs))
+	require.NoError(t, err)
+	_, err = p.AddBlock(b)
+	require.NoError(t, err)
+
+	proof, err := p.Prove("tx2")
+	require.NoError(t, err)
+	raw, err := json.Marshal(proof)
+	require.NoError(t, err)
+	parsed, err := ParseProof(raw)
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify(pub))
+	assert.Equal(t, uint64(7), parsed.Header.Number)
+	assert.Equal(t, blockverify.HeaderHash(b.Header), blockverify.HeaderHash(parsed.Header.Header()))
+
+	otherKey, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer otherKey.Clean()
+	other, err := otherKey.Public().Marshal()
+	require.NoError(t, err)
+	assert.ErrorIs(t, parsed.Verify(other), ErrUntrustedKey)
+
+	// Another transaction of the block cannot reuse the path
+	forged := *parsed
+	forged.TxID, forged.Envelope = "tx1", data[1]
+	assert.ErrorIs(t, forged.Verify(), ErrInvalidProof)
+
+	// Nor can the header be moved to another block
+	header := *parsed.Header
+	header.Number = 8
+	forged = *parsed
+	forged.Header = &header
+	assert.ErrorIs(t, forged.Verify(), ErrInvalidProof)
+
+	_, err = p.Prove("missing")
+	assert.True(t, errors.Is(err, ErrTxNotFound), err)
+
+	// Blocks whose data does not match the orderer-signed header are rejected
+	b.Data = append(b.Data, envelope("mychannel", "tx3"))
+	_, err = p.AddBlock(b)
+	assert.ErrorIs(t, err, blockverify.ErrDataHashMismatch)
+
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/v1/proofs/tx0")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	served := &Proof{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(served))
+	require.NoError(t, served.Verify(pub))
+
+	resp, err = http.Get(srv.URL + "/v1/proofs/tx3")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,154 @@
+package inclusion
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// The transaction tree of a block is the RFC 6962 Merkle tree of its data
+// entries, so that proofs carry log2(n) hashes instead of the whole block
+
+// LeafHash hashes a block data entry
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// split is the largest power of two smaller than n
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// MerkleRoot is the root of the tree over leaves (leaf hashes). The empty
+// tree has the hash of the empty string.
+func MerkleRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// MerklePath is the audit path of leaf index, from the leaf up
+func MerklePath(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(MerklePath(leaves[:k], index), MerkleRoot(leaves[k:]))
+	}
+	return append(MerklePath(leaves[k:], index-k), MerkleRoot(leaves[:k]))
+}
+
+// VerifyPath checks that leaf is entry index of a tree of size leaves with
+// the given root (RFC 9162, section 2.1.3.2)
+func VerifyPath(leaf []byte, index, size int, path [][]byte, root []byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// ConsistencyProof proves that the tree over leaves[:first] is a prefix of
+// the tree over leaves (RFC 9162, section 2.1.4.1)
+func ConsistencyProof(leaves [][]byte, first int) [][]byte {
+	if first <= 0 || first >= len(leaves) {
+		return nil
+	}
+	return subProof(leaves, first, true)
+}
+
+func subProof(leaves [][]byte, m int, complete bool) [][]byte {
+	if m == len(leaves) {
+		if complete {
+			return nil
+		}
+		return [][]byte{MerkleRoot(leaves)}
+	}
+	k := split(len(leaves))
+	if m <= k {
+		return append(subProof(leaves[:k], m, complete), MerkleRoot(leaves[k:]))
+	}
+	return append(subProof(leaves[k:], m-k, false), MerkleRoot(leaves[:k]))
+}
+
+// VerifyConsistency checks that the tree of size second and root secondRoot
+// extends the tree of size first and root firstRoot (RFC 9162, section
+// 2.1.4.2). The empty tree is a prefix of every tree.
+func VerifyConsistency(first, second int, firstRoot, secondRoot []byte, proof [][]byte) bool {
+	switch {
+	case first < 0 || first > second:
+		return false
+	case first == second:
+		return len(proof) == 0 && bytes.Equal(firstRoot, secondRoot)
+	case first == 0:
+		return len(proof) == 0
+	}
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(c, fr)
+			sr = nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(fr, firstRoot) && bytes.Equal(sr, secondRoot)
+}
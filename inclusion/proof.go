@@ -0,0 +1,132 @@
+// Package inclusion proves that a transaction is part of a channel's
+// ledger. A Prover indexes committed blocks and signs, for each one, a
+// header binding the Fabric block header to the Merkle root of its
+// transactions; a Proof for a transaction ID carries the transaction, its
+// Merkle path, the signed header and the composite public key of the
+// signer, and is verified offline with the stateless core helpers.
+package inclusion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// domain separates header signatures from other uses of the identity
+const domain = "QLTXROOT1"
+
+var (
+	// ErrTxNotFound is returned for transaction IDs the prover has not indexed
+	ErrTxNotFound = errors.New("transaction not found")
+	// ErrInvalidProof is returned when a proof does not verify
+	ErrInvalidProof = errors.New("invalid inclusion proof")
+	// ErrUntrustedKey is returned when a proof is signed by a key the
+	// verifier does not trust
+	ErrUntrustedKey = errors.New("proof signed by an untrusted key")
+)
+
+// SignedHeader is a block header extended with the root of the block's
+// transaction tree, with the hybrid signature of the prover
+type SignedHeader struct {
+	Channel      string `json:"channel"`
+	Number       uint64 `json:"number"`
+	PreviousHash []byte `json:"previous_hash"`
+	DataHash     []byte `json:"data_hash"`
+	TxRoot       []byte `json:"tx_root"`
+	TxCount      int    `json:"tx_count"`
+	// PublicKey is the composite public key (DER) of the signer
+	PublicKey []byte `json:"public_key"`
+	Signature []byte `json:"signature"`
+}
+
+// signedBytes is the encoding covered by the signature
+func (h *SignedHeader) signedBytes() core.SignedBytes {
+	return core.NewSignedBytes(domain).
+		AppendBytes([]byte(h.Channel)).
+		AppendUint64(h.Number).
+		AppendBytes(h.PreviousHash).
+		AppendBytes(h.DataHash).
+		AppendBytes(h.TxRoot).
+		AppendUint64(uint64(h.TxCount)).
+		AppendBytes(h.PublicKey)
+}
+
+// Header is the Fabric block header h extends
+func (h *SignedHeader) Header() *fabproto.BlockHeader {
+	return &fabproto.BlockHeader{Number: h.Number, PreviousHash: h.PreviousHash, DataHash: h.DataHash}
+}
+
+// Verify checks the hybrid signature of h with its public key
+func (h *SignedHeader) Verify() error {
+	pub, err := core.ParsePublicKey(h.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	valid, err := pub.Verify(h.signedBytes().Digest(), h.Signature, core.PolicyHybridAND)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid header signature", ErrInvalidProof)
+	}
+	return nil
+}
+
+// Proof shows that a transaction is in a signed block
+type Proof struct {
+	TxID string `json:"tx_id"`
+	// Envelope is the marshaled common.Envelope of the transaction
+	Envelope []byte        `json:"envelope"`
+	Index    int           `json:"index"`
+	Path     [][]byte      `json:"path"`
+	Header   *SignedHeader `json:"header"`
+}
+
+// ParseProof decodes a JSON proof
+func ParseProof(data []byte) (*Proof, error) {
+	p := &Proof{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	if p.Header == nil {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidProof)
+	}
+	return p, nil
+}
+
+// Verify checks that the envelope carries TxID, is in the transaction tree
+// of the header and that the header signature is valid. With trusted keys
+// (composite DER), the header must also be signed by one of them; without,
+// the caller is responsible for trusting Header.PublicKey.
+func (p *Proof) Verify(trusted ...[]byte) error {
+	if p.Header == nil {
+		return fmt.Errorf("%w: missing header", ErrInvalidProof)
+	}
+	chdr, err := fabproto.EnvelopeChannelHeader(p.Envelope)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	if chdr.TxId != p.TxID || chdr.ChannelId != p.Header.Channel {
+		return fmt.Errorf("%w: envelope is not transaction %s of channel %s", ErrInvalidProof, p.TxID, p.Header.Channel)
+	}
+	if !VerifyPath(LeafHash(p.Envelope), p.Index, p.Header.TxCount, p.Path, p.Header.TxRoot) {
+		return fmt.Errorf("%w: Merkle path does not lead to the transaction root", ErrInvalidProof)
+	}
+	if len(trusted) > 0 && !containsKey(trusted, p.Header.PublicKey) {
+		return ErrUntrustedKey
+	}
+	return p.Header.Verify()
+}
+
+func containsKey(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
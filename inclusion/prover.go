@@ -0,0 +1,132 @@
+package inclusion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// location of an indexed transaction
+type location struct {
+	number uint64
+	index  int
+}
+
+// block is an indexed block
+type block struct {
+	header *SignedHeader
+	data   [][]byte
+	leaves [][]byte
+}
+
+// Prover indexes the blocks of a channel and returns inclusion proofs for
+// their transactions
+type Prover struct {
+	channel   string
+	signer    core.Signer
+	publicKey []byte
+	verifier  *blockverify.Verifier
+
+	mutex  sync.RWMutex
+	txs    map[string]location
+	blocks map[uint64]*block
+}
+
+// NewProver signs headers with signer. verifier, when not nil, checks the
+// orderer signatures of every block before it is indexed.
+func NewProver(channel string, signer core.Signer, verifier *blockverify.Verifier) (*Prover, error) {
+	publicKey, err := signer.Public().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{
+		channel:   channel,
+		signer:    signer,
+		publicKey: publicKey,
+		verifier:  verifier,
+		txs:       map[string]location{},
+		blocks:    map[uint64]*block{},
+	}, nil
+}
+
+// AddBlock signs the header of b and indexes its transactions. Data entries
+// that are not transaction envelopes are part of the tree but not indexed.
+func (p *Prover) AddBlock(b *fabproto.Block) (*SignedHeader, error) {
+	if b.Header == nil {
+		return nil, errors.New("block has no header")
+	}
+	if p.verifier != nil {
+		if _, err := p.verifier.VerifyBlock(b); err != nil {
+			return nil, err
+		}
+	}
+	entry := &block{data: b.Data, leaves: make([][]byte, len(b.Data))}
+	for i, d := range b.Data {
+		entry.leaves[i] = LeafHash(d)
+	}
+	entry.header = &SignedHeader{
+		Channel:      p.channel,
+		Number:       b.Header.Number,
+		PreviousHash: b.Header.PreviousHash,
+		DataHash:     b.Header.DataHash,
+		TxRoot:       MerkleRoot(entry.leaves),
+		TxCount:      len(b.Data),
+		PublicKey:    p.publicKey,
+	}
+	sig, err := p.signer.Sign(entry.header.signedBytes().Digest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign header of block %d: %w", b.Header.Number, err)
+	}
+	entry.header.Signature = sig
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.blocks[b.Header.Number] = entry
+	for i, d := range b.Data {
+		chdr, err := fabproto.EnvelopeChannelHeader(d)
+		if err != nil || chdr.TxId == "" {
+			continue
+		}
+		p.txs[chdr.TxId] = location{number: b.Header.Number, index: i}
+	}
+	return entry.header, nil
+}
+
+// Prove returns the inclusion proof of txID
+func (p *Prover) Prove(txID string) (*Proof, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	loc, ok := p.txs[txID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", txID, ErrTxNotFound)
+	}
+	b := p.blocks[loc.number]
+	return &Proof{
+		TxID:     txID,
+		Envelope: b.data[loc.index],
+		Index:    loc.index,
+		Path:     MerklePath(b.leaves, loc.index),
+		Header:   b.header,
+	}, nil
+}
+
+// Handler serves GET /v1/proofs/{txid}
+func (p *Prover) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/proofs/{txid}", func(w http.ResponseWriter, r *http.Request) {
+		proof, err := p.Prove(r.PathValue("txid"))
+		if errors.Is(err, ErrTxNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(proof)
+	})
+	return mux
+}
@@ -0,0 +1,205 @@
+// Package cli holds the conventions shared by the command line tools: the
+// -output flag, the JSON result document and the exit codes. Scripts rely
+// on both, so they only change in a backwards compatible way.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes of every tool
+const (
+	// ExitOK: the command succeeded
+	ExitOK = 0
+	// ExitFailure: the command could not run to completion, e.g. a missing
+	// file, an unreachable service or a malformed input
+	ExitFailure = 1
+	// ExitUsage: unknown command, invalid flag or missing argument
+	ExitUsage = 2
+	// ExitRejected: the command ran but its check failed, e.g. an invalid
+	// signature or a performance regression
+	ExitRejected = 3
+)
+
+var (
+	// ErrUsage marks errors in the command line
+	ErrUsage = errors.New("usage error")
+	// ErrRejected marks failed checks
+	ErrRejected = errors.New("check failed")
+)
+
+// Usagef returns an error exiting with ExitUsage
+func Usagef(format string, args ...interface{}) error {
+	return &markedError{err: fmt.Errorf(format, args...), mark: ErrUsage}
+}
+
+// Rejected marks err as a failed check, exiting with ExitRejected
+func Rejected(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &markedError{err: err, mark: ErrRejected}
+}
+
+// RejectedIf marks err as a failed check when it matches one of checks,
+// the errors of the verification itself, as opposed to e.g. I/O errors
+func RejectedIf(err error, checks ...error) error {
+	for _, c := range checks {
+		if errors.Is(err, c) {
+			return Rejected(err)
+		}
+	}
+	return err
+}
+
+// markedError keeps the message of err while matching mark
+type markedError struct {
+	err  error
+	mark error
+}
+
+func (e *markedError) Error() string   { return e.err.Error() }
+func (e *markedError) Unwrap() []error { return []error{e.err, e.mark} }
+
+// ExitCode returns the exit code of err
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, ErrRejected):
+		return ExitRejected
+	}
+	return ExitFailure
+}
+
+// Output formats
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// output is the format selected by the -output flag of the running command
+var output = OutputText
+
+// result is the value the running command reported with Print
+var result interface{}
+
+// command is the name of the running command's flag set
+var command string
+
+// NewFlagSet returns the flag set of a command, with the -output flag
+func NewFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Func("output", "output format: text or json", func(v string) error {
+		if v != OutputText && v != OutputJSON {
+			return fmt.Errorf("expected text or json, got %q", v)
+		}
+		output = v
+		return nil
+	})
+	command = name
+	return fs
+}
+
+// Parse parses args, marking errors as usage errors
+func Parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &markedError{err: err, mark: ErrUsage}
+	}
+	return nil
+}
+
+// JSON reports whether the running command writes JSON
+func JSON() bool {
+	return output == OutputJSON
+}
+
+// Print reports the result of the command: it becomes the result of the
+// JSON document, or text prints it in text mode
+func Print(v interface{}, text func()) {
+	if JSON() {
+		result = v
+		return
+	}
+	if text != nil {
+		text()
+	}
+}
+
+// Infof prints progress messages: on stdout in text mode, on stderr in
+// JSON mode so stdout only holds the document
+func Infof(format string, args ...interface{}) {
+	w := io.Writer(os.Stdout)
+	if JSON() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+// Document is the JSON output of a command
+type Document struct {
+	Tool     string      `json:"tool"`
+	Command  string      `json:"command"`
+	OK       bool        `json:"ok"`
+	ExitCode int         `json:"exit_code"`
+	Error    string      `json:"error,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
+}
+
+// Main runs the command named by the first argument, prints usage for help
+// and unknown commands, and exits with the code of the command's error. In
+// JSON mode the Document is written to stdout. The completion command
+// prints the shell completion script of the tool.
+func Main(tool, usage string, commands map[string]func(args []string) error) {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(ExitUsage)
+	}
+	switch os.Args[1] {
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok && os.Args[1] == "completion" {
+		run, ok = completionCommand(tool, commands), true
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n%s", tool, os.Args[1], usage)
+		os.Exit(ExitUsage)
+	}
+
+	err := run(os.Args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	code := ExitCode(err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s: %v\n", tool, os.Args[1], err)
+	}
+	if JSON() {
+		if command == "" {
+			command = os.Args[1]
+		}
+		doc := Document{Tool: tool, Command: command, OK: err == nil, ExitCode: code, Result: result}
+		if err != nil {
+			doc.Error = err.Error()
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(doc); encErr != nil && code == ExitOK {
+			code = ExitFailure
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	errCheck := errors.New("invalid signature")
+
+	require.Equal(t, ExitOK, ExitCode(nil))
+	require.Equal(t, ExitFailure, ExitCode(errors.New("no such file")))
+	require.Equal(t, ExitUsage, ExitCode(Usagef("-dir is required")))
+	require.Equal(t, ExitRejected, ExitCode(Rejected(errCheck)))
+	require.Equal(t, ExitRejected, ExitCode(fmt.Errorf("block 3: %w", Rejected(errCheck))))
+
+	err := RejectedIf(fmt.Errorf("peer0: %w", errCheck), errCheck)
+	require.Equal(t, ExitRejected, ExitCode(err))
+	require.ErrorIs(t, err, errCheck)
+	require.Equal(t, "peer0: invalid signature", err.Error())
+	require.Equal(t, ExitFailure, ExitCode(RejectedIf(errors.New("timeout"), errCheck)))
+	require.Nil(t, Rejected(nil))
+}
+
+func TestParse(t *testing.T) {
+	defer func() { output, result = OutputText, nil }()
+
+	fs := NewFlagSet("test")
+	fs.SetOutput(io.Discard)
+	require.Equal(t, ExitUsage, ExitCode(Parse(fs, []string{"-output", "yaml"})))
+	require.False(t, JSON())
+
+	fs = NewFlagSet("test")
+	n := fs.Int("n", 1, "")
+	require.NoError(t, Parse(fs, []string{"-output", "json", "-n", "3"}))
+	require.True(t, JSON())
+	require.Equal(t, 3, *n)
+
+	printed := false
+	Print(42, func() { printed = true })
+	require.False(t, printed)
+	require.Equal(t, 42, result)
+}
+
+func TestSafeguard(t *testing.T) {
+	defer func() { stdin, stderr, interactive = os.Stdin, os.Stderr, isTerminal }()
+	var prompt bytes.Buffer
+	stderr = &prompt
+
+	fs := NewFlagSet("purge")
+	s := NewSafeguard(fs)
+	require.NoError(t, Parse(fs, nil))
+
+	// Scripts must pass -yes instead of blocking on a prompt
+	interactive = func() bool { return false }
+	err := s.Confirm("delete %d keys", 2)
+	require.Equal(t, ExitUsage, ExitCode(err))
+	require.Contains(t, err.Error(), "without -yes")
+
+	interactive = func() bool { return true }
+	stdin = strings.NewReader("y\n")
+	require.NoError(t, s.Confirm("delete %d keys", 2))
+	require.Equal(t, "Delete 2 keys? [y/N] ", prompt.String())
+	for _, answer := range []string{"\n", "n\n", "no\n", ""} {
+		stdin = strings.NewReader(answer)
+		require.ErrorIs(t, s.Confirm("delete 2 keys"), ErrAborted, answer)
+	}
+
+	require.NoError(t, Parse(fs, []string{"-yes", "-dry-run"}))
+	stdin = strings.NewReader("")
+	require.NoError(t, s.Confirm("delete 2 keys"))
+	require.True(t, s.DryRunf("delete 2 keys"))
+}
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh"} {
+		var b bytes.Buffer
+		require.NoError(t, WriteCompletion(&b, shell, "qlsig", []string{"usage", "bundle"}))
+		require.Contains(t, b.String(), "_qlsig()")
+		require.Contains(t, b.String(), "bundle completion help usage")
+		require.NotContains(t, b.String(), "%!")
+	}
+	require.Equal(t, ExitUsage, ExitCode(WriteCompletion(io.Discard, "fish", "qlsig", nil)))
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Completion scripts complete command names from the list they are
+// generated with, and flags by running the command with -h, so flags added
+// later complete without regenerating the script. %[1]s is the tool, %[2]s
+// the commands.
+const (
+	bashCompletion = `# bash completion for %[1]s: source <(%[1]s completion bash)
+_%[1]s() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	if [ "$COMP_CWORD" -eq 1 ]; then
+		COMPREPLY=($(compgen -W "%[2]s" -- "$cur"))
+		return
+	fi
+	if [[ $cur == -* ]]; then
+		local args=() w
+		for w in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+			[[ $w == -* ]] && break
+			args+=("$w")
+		done
+		COMPREPLY=($(compgen -W "$("${COMP_WORDS[0]}" "${args[@]}" -h 2>&1 | sed -n 's/^  \(-[^ ]*\).*/\1/p')" -- "$cur"))
+	fi
+}
+complete -o default -F _%[1]s %[1]s
+`
+	zshCompletion = `#compdef %[1]s
+# zsh completion for %[1]s: source <(%[1]s completion zsh)
+_%[1]s() {
+	if (( CURRENT == 2 )); then
+		compadd -- %[2]s
+		return
+	fi
+	if [[ $PREFIX == -* ]]; then
+		local -a args
+		local w
+		for w in "${words[@]:1:CURRENT-2}"; do
+			[[ $w == -* ]] && break
+			args+=("$w")
+		done
+		compadd -- ${(f)"$("$words[1]" "${args[@]}" -h 2>&1 | sed -n 's/^  \(-[^ ]*\).*/\1/p')"}
+		return
+	fi
+	_files
+}
+compdef _%[1]s %[1]s
+`
+)
+
+// WriteCompletion writes the bash or zsh completion script of tool
+func WriteCompletion(w io.Writer, shell, tool string, commands []string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	default:
+		return Usagef("unsupported shell %q, expected bash or zsh", shell)
+	}
+	names := append([]string{"completion", "help"}, commands...)
+	sort.Strings(names)
+	_, err := fmt.Fprintf(w, script, tool, strings.Join(names, " "))
+	return err
+}
+
+// completionCommand is the completion command of Main
+func completionCommand(tool string, commands map[string]func([]string) error) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return Usagef("expected one shell, bash or zsh")
+		}
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		return WriteCompletion(os.Stdout, args[0], tool, names)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrAborted is returned when the user declines a confirmation prompt
+var ErrAborted = errors.New("aborted")
+
+// Prompts read answers from stdin and write to stderr, so stdout only holds
+// the command's output. Tests replace them.
+var (
+	stdin       io.Reader = os.Stdin
+	stderr      io.Writer = os.Stderr
+	interactive           = isTerminal
+)
+
+// isTerminal reports whether stdin is a terminal
+func isTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Safeguard holds the -yes and -dry-run flags of a command that destroys or
+// overwrites keys
+type Safeguard struct {
+	// Yes skips the confirmation prompt
+	Yes bool
+	// DryRun reports what the command would do without doing it
+	DryRun bool
+}
+
+// NewSafeguard registers -yes and -dry-run on fs
+func NewSafeguard(fs *flag.FlagSet) *Safeguard {
+	s := &Safeguard{}
+	fs.BoolVar(&s.Yes, "yes", false, "do not ask for confirmation")
+	fs.BoolVar(&s.DryRun, "dry-run", false, "print what would be done and exit")
+	return s
+}
+
+// Confirm asks the user to confirm the action described by format. It
+// returns nil when confirmed or with -yes, ErrAborted when declined, and a
+// usage error when stdin is not a terminal and -yes is missing, so scripts
+// never block on a prompt.
+func (s *Safeguard) Confirm(format string, args ...interface{}) error {
+	if s.Yes {
+		return nil
+	}
+	action := fmt.Sprintf(format, args...)
+	if !interactive() {
+		return Usagef("refusing to %s without -yes: stdin is not a terminal", action)
+	}
+	fmt.Fprintf(stderr, "%s? [y/N] ", strings.ToUpper(action[:1])+action[1:])
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return ErrAborted
+}
+
+// DryRunf reports the action a dry run skipped. It returns true with
+// -dry-run, in which case the command must stop.
+func (s *Safeguard) DryRunf(format string, args ...interface{}) bool {
+	if s.DryRun {
+		Infof("dry run: would "+format+"\n", args...)
+	}
+	return s.DryRun
+}
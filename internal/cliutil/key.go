@@ -0,0 +1,28 @@
+// Package cliutil holds the file and keystore helpers shared by the command
+// line tools
+package cliutil
+
+import (
+	"errors"
+	"os"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// ReadTrustedKey returns the composite key of a certificate (PEM) or public
+// key JSON (qlsig inspect) file
+func ReadTrustedKey(file string) ([]byte, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if pub, err := core.ParsePublicKeyJSON(raw); err == nil {
+		return pub.Composite()
+	}
+	cert, err := hybridx509.ParseCertificatePEM(raw)
+	if err != nil {
+		return nil, errors.New("expected a PEM certificate or a public key JSON")
+	}
+	return cert.CompositePublicKey()
+}
@@ -0,0 +1,44 @@
+package cliutil
+
+import (
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func TestReadTrustedKey(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	composite, err := root.Cert.CompositePublicKey()
+	require.NoError(t, err)
+	keyJSON, err := core.MarshalPublicKeyJSON(composite, time.Time{})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"ca.pem":   hybridx509.EncodeCertificatePEM(root.Cert.Raw),
+		"key.json": keyJSON,
+		"bad.txt":  []byte("neither"),
+	}
+	for name, raw := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), raw, 0o644))
+	}
+
+	for _, name := range []string{"ca.pem", "key.json"} {
+		key, err := ReadTrustedKey(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, composite, key, name)
+	}
+	_, err = ReadTrustedKey(filepath.Join(dir, "bad.txt"))
+	assert.ErrorContains(t, err, "expected a PEM certificate")
+	_, err = ReadTrustedKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package cliutil
+
+import (
+	"os"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/secret"
+)
+
+// OpenKeyStore opens an existing keystore and returns its namespaces;
+// hybrid.NewKeyStore would create it
+func OpenKeyStore(dir string) (*hybrid.KeyStore, []string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil, err
+	}
+	ks, err := hybrid.NewKeyStore(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaces, err := ks.Namespaces()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ks, namespaces, nil
+}
+
+// SetPassphrase sets the passphrase of ks read from source (see
+// secret.Read), if any
+func SetPassphrase(ks *hybrid.KeyStore, source string) error {
+	if source == "" {
+		return nil
+	}
+	passphrase, err := secret.Read(source)
+	if err != nil {
+		return err
+	}
+	defer clear(passphrase)
+	return ks.SetPassphrase(passphrase)
+}
@@ -0,0 +1,272 @@
+package fabproto
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// BlockMetadataIndex values (common.BlockMetadataIndex)
+const (
+	BlockMetadataIndexSignatures = 0
+	BlockMetadataIndexLastConfig = 1
+	BlockMetadataIndexTxFilter   = 2
+	BlockMetadataIndexOrderer    = 3
+	BlockMetadataIndexCommitHash = 4
+	blockMetadataIndexCount      = 5
+)
+
+// Block is common.Block
+type Block struct {
+	Header   *BlockHeader   // field 1
+	Data     [][]byte       // field 2 (common.BlockData.data)
+	Metadata *BlockMetadata // field 3
+}
+
+// BlockHeader is common.BlockHeader
+type BlockHeader struct {
+	Number       uint64 // field 1
+	PreviousHash []byte // field 2
+	DataHash     []byte // field 3
+}
+
+// BlockMetadata is common.BlockMetadata
+type BlockMetadata struct {
+	Metadata [][]byte // field 1
+}
+
+// Metadata is common.Metadata
+type Metadata struct {
+	Value      []byte               // field 1
+	Signatures []*MetadataSignature // field 2
+}
+
+// MetadataSignature is common.MetadataSignature
+type MetadataSignature struct {
+	SignatureHeader  []byte // field 1
+	Signature        []byte // field 2
+	IdentifierHeader []byte // field 3
+}
+
+// SignatureHeader is common.SignatureHeader
+type SignatureHeader struct {
+	Creator []byte // field 1
+	Nonce   []byte // field 2
+}
+
+// Marshal encodes the block
+func (b *Block) Marshal() []byte {
+	var out []byte
+	if b.Header != nil {
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, b.Header.Marshal())
+	}
+	var data []byte
+	for _, d := range b.Data {
+		data = protowire.AppendTag(data, 1, protowire.BytesType)
+		data = protowire.AppendBytes(data, d)
+	}
+	out = protowire.AppendTag(out, 2, protowire.BytesType)
+	out = protowire.AppendBytes(out, data)
+	if b.Metadata != nil {
+		out = protowire.AppendTag(out, 3, protowire.BytesType)
+		out = protowire.AppendBytes(out, b.Metadata.Marshal())
+	}
+	return out
+}
+
+// UnmarshalBlock decodes a common.Block
+func UnmarshalBlock(raw []byte) (*Block, error) {
+	b := &Block{}
+	err := walk(raw, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			b.Header, err = UnmarshalBlockHeader(v)
+		case 2:
+			err = walk(v, func(num protowire.Number, _ protowire.Type, d []byte) error {
+				if num == 1 {
+					b.Data = append(b.Data, d)
+				}
+				return nil
+			})
+		case 3:
+			b.Metadata, err = UnmarshalBlockMetadata(v)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Block: %w", err)
+	}
+	return b, nil
+}
+
+// Marshal encodes the header
+func (h *BlockHeader) Marshal() []byte {
+	var out []byte
+	out = appendVarint(out, 1, h.Number)
+	out = appendBytes(out, 2, h.PreviousHash)
+	return appendBytes(out, 3, h.DataHash)
+}
+
+// UnmarshalBlockHeader decodes a common.BlockHeader
+func UnmarshalBlockHeader(raw []byte) (*BlockHeader, error) {
+	h := &BlockHeader{}
+	err := walk(raw, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			h.Number, err = varint(v)
+		case 2:
+			h.PreviousHash = v
+		case 3:
+			h.DataHash = v
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid BlockHeader: %w", err)
+	}
+	return h, nil
+}
+
+// Marshal encodes the block metadata
+func (m *BlockMetadata) Marshal() []byte {
+	var out []byte
+	for _, md := range m.Metadata {
+		// repeated bytes keeps empty entries to preserve indexes
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, md)
+	}
+	return out
+}
+
+// UnmarshalBlockMetadata decodes a common.BlockMetadata
+func UnmarshalBlockMetadata(raw []byte) (*BlockMetadata, error) {
+	m := &BlockMetadata{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		if num == 1 {
+			m.Metadata = append(m.Metadata, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid BlockMetadata: %w", err)
+	}
+	return m, nil
+}
+
+// NewBlockMetadata returns metadata with every index present and empty, as
+// Fabric's protoutil.NewBlock does
+func NewBlockMetadata() *BlockMetadata {
+	return &BlockMetadata{Metadata: make([][]byte, blockMetadataIndexCount)}
+}
+
+// Marshal encodes the metadata entry
+func (m *Metadata) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, m.Value)
+	for _, s := range m.Signatures {
+		out = protowire.AppendTag(out, 2, protowire.BytesType)
+		out = protowire.AppendBytes(out, s.Marshal())
+	}
+	return out
+}
+
+// UnmarshalMetadata decodes a common.Metadata
+func UnmarshalMetadata(raw []byte) (*Metadata, error) {
+	m := &Metadata{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			m.Value = v
+		case 2:
+			s, err := UnmarshalMetadataSignature(v)
+			if err != nil {
+				return err
+			}
+			m.Signatures = append(m.Signatures, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Metadata: %w", err)
+	}
+	return m, nil
+}
+
+// Marshal encodes the metadata signature
+func (s *MetadataSignature) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, s.SignatureHeader)
+	out = appendBytes(out, 2, s.Signature)
+	return appendBytes(out, 3, s.IdentifierHeader)
+}
+
+// UnmarshalMetadataSignature decodes a common.MetadataSignature
+func UnmarshalMetadataSignature(raw []byte) (*MetadataSignature, error) {
+	s := &MetadataSignature{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			s.SignatureHeader = v
+		case 2:
+			s.Signature = v
+		case 3:
+			s.IdentifierHeader = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid MetadataSignature: %w", err)
+	}
+	return s, nil
+}
+
+// Marshal encodes the signature header
+func (s *SignatureHeader) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, s.Creator)
+	return appendBytes(out, 2, s.Nonce)
+}
+
+// UnmarshalSignatureHeader decodes a common.SignatureHeader
+func UnmarshalSignatureHeader(raw []byte) (*SignatureHeader, error) {
+	s := &SignatureHeader{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			s.Creator = v
+		case 2:
+			s.Nonce = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SignatureHeader: %w", err)
+	}
+	return s, nil
+}
+
+type asn1Header struct {
+	Number       *big.Int
+	PreviousHash []byte
+	DataHash     []byte
+}
+
+// BlockHeaderBytes is the ASN.1 encoding Fabric hashes and signs for a
+// header (protoutil.BlockHeaderBytes)
+func BlockHeaderBytes(h *BlockHeader) []byte {
+	out, err := asn1.Marshal(asn1Header{
+		Number:       new(big.Int).SetUint64(h.Number),
+		PreviousHash: h.PreviousHash,
+		DataHash:     h.DataHash,
+	})
+	if err != nil {
+		// Only fails for types asn1 cannot encode, which these are not
+		panic(err)
+	}
+	return out
+}
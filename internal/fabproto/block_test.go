@@ -0,0 +1,59 @@
+package fabproto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRoundTrip(t *testing.T) {
+	md := NewBlockMetadata()
+	md.Metadata[BlockMetadataIndexSignatures] = (&Metadata{
+		Value: []byte("value"),
+		Signatures: []*MetadataSignature{{
+			SignatureHeader: (&SignatureHeader{Creator: []byte("creator"), Nonce: []byte("nonce")}).Marshal(),
+			Signature:       []byte("sig"),
+		}},
+	}).Marshal()
+	block := &Block{
+		Header:   &BlockHeader{Number: 42, PreviousHash: []byte("prev"), DataHash: []byte("data")},
+		Data:     [][]byte{[]byte("tx1"), []byte("tx2")},
+		Metadata: md,
+	}
+
+	parsed, err := UnmarshalBlock(block.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, block.Header, parsed.Header)
+	assert.Equal(t, block.Data, parsed.Data)
+	require.Len(t, parsed.Metadata.Metadata, 5)
+
+	m, err := UnmarshalMetadata(parsed.Metadata.Metadata[BlockMetadataIndexSignatures])
+	require.NoError(t, err)
+	require.Len(t, m.Signatures, 1)
+	shdr, err := UnmarshalSignatureHeader(m.Signatures[0].SignatureHeader)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("creator"), shdr.Creator)
+}
+
+func TestBlockHeaderBytes(t *testing.T) {
+	// SEQUENCE { INTEGER 1, OCTET STRING 0x01, OCTET STRING 0x02 }
+	assert.Equal(t,
+		[]byte{0x30, 0x09, 0x02, 0x01, 0x01, 0x04, 0x01, 0x01, 0x04, 0x01, 0x02},
+		BlockHeaderBytes(&BlockHeader{Number: 1, PreviousHash: []byte{1}, DataHash: []byte{2}}))
+}
+
+func TestEnvelopeChannelHeader(t *testing.T) {
+	chdr := &ChannelHeader{Type: 3, Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC), ChannelId: "mychannel", TxId: "abc123"}
+	env := &Envelope{
+		Payload:   (&Payload{Header: &Header{ChannelHeader: chdr.Marshal()}, Data: []byte("tx")}).Marshal(),
+		Signature: []byte("sig"),
+	}
+	parsed, err := EnvelopeChannelHeader(env.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, chdr, parsed)
+
+	_, err = EnvelopeChannelHeader((&Envelope{Payload: (&Payload{Data: []byte("tx")}).Marshal()}).Marshal())
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package fabproto
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Envelope is common.Envelope
+type Envelope struct {
+	Payload   []byte // field 1
+	Signature []byte // field 2
+}
+
+// Payload is common.Payload
+type Payload struct {
+	Header *Header // field 1
+	Data   []byte  // field 2
+}
+
+// Header is common.Header
+type Header struct {
+	ChannelHeader   []byte // field 1
+	SignatureHeader []byte // field 2
+}
+
+// ChannelHeader is common.ChannelHeader, without extension
+type ChannelHeader struct {
+	Type int32 // field 1
+	// Timestamp is set by the client when it creates the proposal
+	Timestamp time.Time // field 3 (google.protobuf.Timestamp)
+	ChannelId string    // field 4
+	TxId      string    // field 5
+}
+
+// Marshal encodes the envelope
+func (e *Envelope) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, e.Payload)
+	return appendBytes(out, 2, e.Signature)
+}
+
+// UnmarshalEnvelope decodes a common.Envelope
+func UnmarshalEnvelope(raw []byte) (*Envelope, error) {
+	e := &Envelope{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			e.Payload = v
+		case 2:
+			e.Signature = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Envelope: %w", err)
+	}
+	return e, nil
+}
+
+// Marshal encodes the payload
+func (p *Payload) Marshal() []byte {
+	var out []byte
+	if p.Header != nil {
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, p.Header.Marshal())
+	}
+	return appendBytes(out, 2, p.Data)
+}
+
+// UnmarshalPayload decodes a common.Payload
+func UnmarshalPayload(raw []byte) (*Payload, error) {
+	p := &Payload{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			p.Header, err = UnmarshalHeader(v)
+		case 2:
+			p.Data = v
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Payload: %w", err)
+	}
+	return p, nil
+}
+
+// Marshal encodes the header
+func (h *Header) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, h.ChannelHeader)
+	return appendBytes(out, 2, h.SignatureHeader)
+}
+
+// UnmarshalHeader decodes a common.Header
+func UnmarshalHeader(raw []byte) (*Header, error) {
+	h := &Header{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			h.ChannelHeader = v
+		case 2:
+			h.SignatureHeader = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Header: %w", err)
+	}
+	return h, nil
+}
+
+// Marshal encodes the channel header
+func (h *ChannelHeader) Marshal() []byte {
+	var out []byte
+	out = appendVarint(out, 1, uint64(h.Type))
+	if !h.Timestamp.IsZero() {
+		var ts []byte
+		ts = appendVarint(ts, 1, uint64(h.Timestamp.Unix()))
+		ts = appendVarint(ts, 2, uint64(h.Timestamp.Nanosecond()))
+		out = protowire.AppendTag(out, 3, protowire.BytesType)
+		out = protowire.AppendBytes(out, ts)
+	}
+	out = appendString(out, 4, h.ChannelId)
+	return appendString(out, 5, h.TxId)
+}
+
+// UnmarshalChannelHeader decodes a common.ChannelHeader
+func UnmarshalChannelHeader(raw []byte) (*ChannelHeader, error) {
+	h := &ChannelHeader{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			t, err := varint(v)
+			if err != nil {
+				return err
+			}
+			h.Type = int32(t)
+		case 3:
+			ts, err := unmarshalTimestamp(v)
+			if err != nil {
+				return err
+			}
+			h.Timestamp = ts
+		case 4:
+			h.ChannelId = string(v)
+		case 5:
+			h.TxId = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ChannelHeader: %w", err)
+	}
+	return h, nil
+}
+
+// unmarshalTimestamp decodes a google.protobuf.Timestamp
+func unmarshalTimestamp(raw []byte) (time.Time, error) {
+	var seconds, nanos uint64
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			seconds, err = varint(v)
+		case 2:
+			nanos, err = varint(v)
+		}
+		return err
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Timestamp: %w", err)
+	}
+	return time.Unix(int64(seconds), int64(int32(nanos))).UTC(), nil
+}
+
+// EnvelopeChannelHeader decodes the channel header of a marshaled envelope,
+// as stored in block data
+func EnvelopeChannelHeader(raw []byte) (*ChannelHeader, error) {
+	env, err := UnmarshalEnvelope(raw)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("invalid Payload: missing header")
+	}
+	return UnmarshalChannelHeader(payload.Header.ChannelHeader)
+}
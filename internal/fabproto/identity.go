@@ -0,0 +1,42 @@
+// Package fabproto decodes and encodes the few Fabric protobuf messages this
+// module needs, using the wire format directly so that light clients do not
+// depend on the Fabric protos. Field numbers follow fabric-protos.
+package fabproto
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SerializedIdentity is msp.SerializedIdentity
+type SerializedIdentity struct {
+	Mspid   string // field 1
+	IdBytes []byte // field 2
+}
+
+// Marshal encodes the identity
+func (s *SerializedIdentity) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, s.Mspid)
+	b = appendBytes(b, 2, s.IdBytes)
+	return b
+}
+
+// UnmarshalSerializedIdentity decodes an msp.SerializedIdentity
+func UnmarshalSerializedIdentity(raw []byte) (*SerializedIdentity, error) {
+	s := &SerializedIdentity{}
+	err := walk(raw, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			s.Mspid = string(v)
+		case 2:
+			s.IdBytes = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SerializedIdentity: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,59 @@
+package fabproto
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Proposal is peer.Proposal, the message clients sign to request an
+// endorsement
+type Proposal struct {
+	Header    []byte // field 1, a marshaled common.Header
+	Payload   []byte // field 2
+	Extension []byte // field 3
+}
+
+// Marshal encodes the proposal
+func (p *Proposal) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, p.Header)
+	out = appendBytes(out, 2, p.Payload)
+	return appendBytes(out, 3, p.Extension)
+}
+
+// UnmarshalProposal decodes a peer.Proposal
+func UnmarshalProposal(raw []byte) (*Proposal, error) {
+	p := &Proposal{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			p.Header = v
+		case 2:
+			p.Payload = v
+		case 3:
+			p.Extension = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Proposal: %w", err)
+	}
+	return p, nil
+}
+
+// ProposalChannelHeader decodes the channel header of a marshaled proposal
+func ProposalChannelHeader(raw []byte) (*ChannelHeader, error) {
+	p, err := UnmarshalProposal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Header) == 0 {
+		return nil, fmt.Errorf("invalid Proposal: missing header")
+	}
+	h, err := UnmarshalHeader(p.Header)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalChannelHeader(h.ChannelHeader)
+}
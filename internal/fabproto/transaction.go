@@ -0,0 +1,192 @@
+package fabproto
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// HeaderTypeEndorserTransaction is common.HeaderType ENDORSER_TRANSACTION
+const HeaderTypeEndorserTransaction = 3
+
+// Transaction is peer.Transaction, the data of an endorser transaction
+// payload
+type Transaction struct {
+	Actions []*TransactionAction // field 1
+}
+
+// TransactionAction is peer.TransactionAction
+type TransactionAction struct {
+	Header  []byte // field 1, a marshaled common.SignatureHeader
+	Payload []byte // field 2, a marshaled ChaincodeActionPayload
+}
+
+// ChaincodeActionPayload is peer.ChaincodeActionPayload
+type ChaincodeActionPayload struct {
+	ChaincodeProposalPayload []byte                   // field 1
+	Action                   *ChaincodeEndorsedAction // field 2
+}
+
+// ChaincodeEndorsedAction is peer.ChaincodeEndorsedAction
+type ChaincodeEndorsedAction struct {
+	ProposalResponsePayload []byte         // field 1
+	Endorsements            []*Endorsement // field 2
+}
+
+// Endorsement is peer.Endorsement. Endorsers sign the proposal response
+// payload followed by Endorser.
+type Endorsement struct {
+	Endorser  []byte // field 1, a marshaled msp.SerializedIdentity
+	Signature []byte // field 2
+}
+
+// Marshal encodes the transaction
+func (t *Transaction) Marshal() []byte {
+	var out []byte
+	for _, a := range t.Actions {
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, a.Marshal())
+	}
+	return out
+}
+
+// UnmarshalTransaction decodes a peer.Transaction
+func UnmarshalTransaction(raw []byte) (*Transaction, error) {
+	t := &Transaction{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		a, err := UnmarshalTransactionAction(v)
+		if err != nil {
+			return err
+		}
+		t.Actions = append(t.Actions, a)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Transaction: %w", err)
+	}
+	return t, nil
+}
+
+// Marshal encodes the action
+func (a *TransactionAction) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, a.Header)
+	return appendBytes(out, 2, a.Payload)
+}
+
+// UnmarshalTransactionAction decodes a peer.TransactionAction
+func UnmarshalTransactionAction(raw []byte) (*TransactionAction, error) {
+	a := &TransactionAction{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			a.Header = v
+		case 2:
+			a.Payload = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid TransactionAction: %w", err)
+	}
+	return a, nil
+}
+
+// Marshal encodes the chaincode action payload
+func (p *ChaincodeActionPayload) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, p.ChaincodeProposalPayload)
+	if p.Action != nil {
+		out = protowire.AppendTag(out, 2, protowire.BytesType)
+		out = protowire.AppendBytes(out, p.Action.Marshal())
+	}
+	return out
+}
+
+// UnmarshalChaincodeActionPayload decodes a peer.ChaincodeActionPayload
+func UnmarshalChaincodeActionPayload(raw []byte) (*ChaincodeActionPayload, error) {
+	p := &ChaincodeActionPayload{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		var err error
+		switch num {
+		case 1:
+			p.ChaincodeProposalPayload = v
+		case 2:
+			p.Action, err = UnmarshalChaincodeEndorsedAction(v)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ChaincodeActionPayload: %w", err)
+	}
+	return p, nil
+}
+
+// Marshal encodes the endorsed action
+func (a *ChaincodeEndorsedAction) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, a.ProposalResponsePayload)
+	for _, e := range a.Endorsements {
+		out = protowire.AppendTag(out, 2, protowire.BytesType)
+		out = protowire.AppendBytes(out, e.Marshal())
+	}
+	return out
+}
+
+// UnmarshalChaincodeEndorsedAction decodes a peer.ChaincodeEndorsedAction
+func UnmarshalChaincodeEndorsedAction(raw []byte) (*ChaincodeEndorsedAction, error) {
+	a := &ChaincodeEndorsedAction{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			a.ProposalResponsePayload = v
+		case 2:
+			e, err := UnmarshalEndorsement(v)
+			if err != nil {
+				return err
+			}
+			a.Endorsements = append(a.Endorsements, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ChaincodeEndorsedAction: %w", err)
+	}
+	return a, nil
+}
+
+// Marshal encodes the endorsement
+func (e *Endorsement) Marshal() []byte {
+	var out []byte
+	out = appendBytes(out, 1, e.Endorser)
+	return appendBytes(out, 2, e.Signature)
+}
+
+// UnmarshalEndorsement decodes a peer.Endorsement
+func UnmarshalEndorsement(raw []byte) (*Endorsement, error) {
+	e := &Endorsement{}
+	err := walk(raw, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1:
+			e.Endorser = v
+		case 2:
+			e.Signature = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Endorsement: %w", err)
+	}
+	return e, nil
+}
+
+// SignedBytes is what the endorser signed: the proposal response payload
+// followed by the serialized endorser
+func (e *Endorsement) SignedBytes(proposalResponsePayload []byte) []byte {
+	out := make([]byte, 0, len(proposalResponsePayload)+len(e.Endorser))
+	out = append(out, proposalResponsePayload...)
+	return append(out, e.Endorser...)
+}
@@ -0,0 +1,35 @@
+package fabproto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionRoundTrip(t *testing.T) {
+	action := &ChaincodeEndorsedAction{
+		ProposalResponsePayload: []byte("prp"),
+		Endorsements: []*Endorsement{
+			{Endorser: []byte("peer0"), Signature: []byte("sig0")},
+			{Endorser: []byte("peer1"), Signature: []byte("sig1")},
+		},
+	}
+	tx := &Transaction{Actions: []*TransactionAction{{
+		Header:  []byte("shdr"),
+		Payload: (&ChaincodeActionPayload{ChaincodeProposalPayload: []byte("cpp"), Action: action}).Marshal(),
+	}}}
+
+	parsed, err := UnmarshalTransaction(tx.Marshal())
+	require.NoError(t, err)
+	require.Len(t, parsed.Actions, 1)
+	assert.Equal(t, []byte("shdr"), parsed.Actions[0].Header)
+	payload, err := UnmarshalChaincodeActionPayload(parsed.Actions[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cpp"), payload.ChaincodeProposalPayload)
+	assert.Equal(t, action, payload.Action)
+	assert.Equal(t, []byte("prppeer1"), payload.Action.Endorsements[1].SignedBytes(payload.Action.ProposalResponsePayload))
+
+	_, err = UnmarshalTransaction([]byte{0x0a, 0x05})
+	assert.Error(t, err)
+}
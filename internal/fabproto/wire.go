@@ -0,0 +1,72 @@
+package fabproto
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// walk calls fn for every field of a message. Length-delimited values are
+// passed as-is; varints are passed re-encoded so fn can decode them with
+// protowire.ConsumeVarint. Groups are rejected.
+func walk(raw []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			b, m := protowire.ConsumeBytes(raw)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			v, n = b, m
+		case protowire.VarintType, protowire.Fixed32Type, protowire.Fixed64Type:
+			n = protowire.ConsumeFieldValue(num, typ, raw)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			v = raw[:n]
+		default:
+			return errors.New("unsupported wire type")
+		}
+		raw = raw[n:]
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	return appendBytes(b, num, []byte(v))
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// varint decodes a value passed to walk for a varint field
+func varint(v []byte) (uint64, error) {
+	x, n := protowire.ConsumeVarint(v)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return x, nil
+}
@@ -0,0 +1,70 @@
+// Package golden compares serialized test outputs with files committed under
+// testdata/golden, so a change of a wire or file format fails the tests
+// instead of reaching deployed peers. After an intended change, rewrite the
+// files and review the diff:
+//
+//	go test ./bccsp/hybrid/ ./vc/ -update-golden
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite golden files with the current outputs")
+
+// Update reports whether the tests run with -update-golden. Tests whose
+// outputs are not reproducible, e.g. signatures, generate new inputs then.
+func Update() bool {
+	return *update
+}
+
+// Path returns the path of golden file name
+func Path(name string) string {
+	return filepath.Join("testdata", "golden", name)
+}
+
+// Read returns golden file name, failing the test if it is missing
+func Read(t testing.TB, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(Path(name))
+	if err != nil {
+		t.Fatalf("golden file %s: %v (run with -update-golden to create it)", name, err)
+	}
+	return raw
+}
+
+// Write replaces golden file name, creating its directory
+func Write(t testing.TB, name string, data []byte) {
+	t.Helper()
+	path := Path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Assert compares got with golden file name, or writes it with
+// -update-golden
+func Assert(t testing.TB, name string, got []byte) {
+	t.Helper()
+	if *update {
+		Write(t, name, got)
+		return
+	}
+	want := Read(t, name)
+	if bytes.Equal(got, want) {
+		return
+	}
+	i := 0
+	for i < len(got) && i < len(want) && got[i] == want[i] {
+		i++
+	}
+	t.Errorf("%s: output differs from the golden file at byte %d (got %d bytes, want %d); "+
+		"if the format change is intended, run with -update-golden and review the diff", name, i, len(got), len(want))
+}
@@ -0,0 +1,366 @@
+package keyregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registry event types, the Type of chaincode events of the registry
+const (
+	// EventUpdate publishes a new or changed entry
+	EventUpdate = "update"
+	// EventRevoke withdraws the entry of an identity
+	EventRevoke = "revoke"
+	// EventCheckpoint carries no change; sources send it for blocks without
+	// registry events so that the cache knows it is current
+	EventCheckpoint = "checkpoint"
+)
+
+// DefaultMaxStaleness is the MaxStaleness of caches that set none
+const DefaultMaxStaleness = 5 * time.Minute
+
+var (
+	// ErrStale is returned by Cache.Lookup when the cache was last
+	// synchronized longer than MaxStaleness ago
+	ErrStale = errors.New("key registry cache is stale")
+	// ErrRevoked is returned by Cache.Lookup for revoked identities
+	ErrRevoked = errors.New("identity revoked from the key registry")
+)
+
+// Event is the payload of a chaincode event of the registry
+type Event struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	// Entry is the new entry of update events
+	Entry *Entry `json:"entry,omitempty"`
+	// Block is the number of the block that committed the event, set by
+	// the Source
+	Block uint64 `json:"-"`
+}
+
+// ParseEvent decodes the payload of a registry event committed in block
+func ParseEvent(payload []byte, block uint64) (*Event, error) {
+	e := &Event{}
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+	}
+	e.Block = block
+	switch e.Type {
+	case EventUpdate:
+		if e.Entry == nil {
+			return nil, fmt.Errorf("%w: update event without entry", ErrInvalidEntry)
+		}
+		if err := e.Entry.Validate(); err != nil {
+			return nil, err
+		}
+		e.ID = e.Entry.ID
+	case EventRevoke:
+		if e.ID == "" {
+			return nil, fmt.Errorf("%w: revoke event without ID", ErrInvalidEntry)
+		}
+	case EventCheckpoint:
+	default:
+		return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidEntry, e.Type)
+	}
+	return e, nil
+}
+
+// Source reads the registry chaincode of a channel
+type Source interface {
+	// Snapshot queries every entry of the registry and returns them with
+	// the number of the last block they reflect
+	Snapshot(ctx context.Context) (entries []*Entry, height uint64, err error)
+	// Events calls handle for the registry events of blocks from height
+	// on, in commit order, until ctx is done, handle fails or the stream
+	// breaks
+	Events(ctx context.Context, from uint64, handle func(*Event) error) error
+}
+
+// Cache is a Registry kept in sync with the registry chaincode of a
+// channel: Refresh loads a snapshot, Run follows the chaincode events and
+// falls back to snapshots when the channel is quiet. Lookups fail with
+// ErrStale rather than serve entries older than MaxStaleness, so a revoked
+// key is trusted for at most that long. Set the fields before calling
+// Load, Refresh or Run; the cache is safe for concurrent use after that.
+type Cache struct {
+	Source Source
+	// Path, when set, is the file the cache persists to, so that a
+	// restarted verifier serves lookups before reaching the channel
+	Path string
+	// MaxStaleness bounds the age of the entries served,
+	// DefaultMaxStaleness if zero
+	MaxStaleness time.Duration
+	// OnInvalidate is called with entries that were replaced, revoked or
+	// removed, e.g. to drop their keys from a hybrid.VerifierCache
+	OnInvalidate func(*Entry)
+	// OnError is called by Run for synchronization attempts that failed
+	OnError func(error)
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+
+	mutex    sync.RWMutex
+	entries  map[string]*Entry
+	revoked  map[string]uint64
+	height   uint64
+	syncedAt time.Time
+}
+
+// cacheFile is the persisted form of a Cache
+type cacheFile struct {
+	Height   uint64            `json:"height"`
+	SyncedAt time.Time         `json:"synced_at"`
+	Entries  []*Entry          `json:"entries"`
+	Revoked  map[string]uint64 `json:"revoked,omitempty"`
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) maxStaleness() time.Duration {
+	if c.MaxStaleness > 0 {
+		return c.MaxStaleness
+	}
+	return DefaultMaxStaleness
+}
+
+// Load restores the cache persisted at Path. A missing file leaves the
+// cache empty and stale.
+func (c *Cache) Load() error {
+	if c.Path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f cacheFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return fmt.Errorf("failed to parse key registry cache %s: %w", c.Path, err)
+	}
+	entries := make(map[string]*Entry, len(f.Entries))
+	for _, e := range f.Entries {
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("key registry cache %s: %w", c.Path, err)
+		}
+		entries[e.ID] = e
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries, c.revoked, c.height, c.syncedAt = entries, f.Revoked, f.Height, f.SyncedAt
+	return nil
+}
+
+// Lookup implements Registry for entries at most MaxStaleness old
+func (c *Cache) Lookup(id string) (*Entry, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if age := c.now().Sub(c.syncedAt); c.syncedAt.IsZero() || age > c.maxStaleness() {
+		if c.syncedAt.IsZero() {
+			return nil, fmt.Errorf("%w: never synchronized", ErrStale)
+		}
+		return nil, fmt.Errorf("%w: last synchronized %s ago", ErrStale, age.Round(time.Second))
+	}
+	if block, ok := c.revoked[id]; ok {
+		return nil, fmt.Errorf("%w: %s in block %d", ErrRevoked, id, block)
+	}
+	e, ok := c.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return e, nil
+}
+
+// Height returns the number of the last block applied to the cache
+func (c *Cache) Height() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.height
+}
+
+// SyncedAt returns when the cache was last known to be current, zero if
+// never
+func (c *Cache) SyncedAt() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.syncedAt
+}
+
+// Refresh replaces the entries with a snapshot of the registry
+func (c *Cache) Refresh(ctx context.Context) error {
+	snapshot, height, err := c.Source.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read key registry snapshot: %w", err)
+	}
+	entries := make(map[string]*Entry, len(snapshot))
+	for _, e := range snapshot {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		entries[e.ID] = e
+	}
+
+	c.mutex.Lock()
+	if height < c.height {
+		c.mutex.Unlock()
+		return fmt.Errorf("key registry snapshot of block %d is older than the cache at block %d", height, c.height)
+	}
+	var invalidated []*Entry
+	for id, old := range c.entries {
+		if e, ok := entries[id]; !ok || !sameEntry(old, e) {
+			invalidated = append(invalidated, old)
+		}
+	}
+	revoked := map[string]uint64{}
+	for id, block := range c.revoked {
+		if _, ok := entries[id]; !ok {
+			revoked[id] = block
+		}
+	}
+	c.entries, c.revoked, c.height, c.syncedAt = entries, revoked, height, c.now()
+	err = c.persist()
+	c.mutex.Unlock()
+
+	c.invalidate(invalidated)
+	return err
+}
+
+// Apply applies a registry event. Events of blocks before Height are
+// ignored; those of Height itself are applied again, which is harmless as
+// they come in commit order.
+func (c *Cache) Apply(e *Event) error {
+	c.mutex.Lock()
+	old, err := c.apply(e)
+	c.mutex.Unlock()
+	if old != nil {
+		c.invalidate([]*Entry{old})
+	}
+	return err
+}
+
+// apply applies e with the lock held and returns the entry it invalidated
+func (c *Cache) apply(e *Event) (*Entry, error) {
+	if e.Block < c.height {
+		return nil, nil
+	}
+	var old *Entry
+	switch e.Type {
+	case EventUpdate:
+		if e.Entry == nil {
+			return nil, fmt.Errorf("%w: update event without entry", ErrInvalidEntry)
+		}
+		if err := e.Entry.Validate(); err != nil {
+			return nil, err
+		}
+		if c.entries == nil {
+			c.entries = map[string]*Entry{}
+		}
+		id := e.Entry.ID
+		if prev, ok := c.entries[id]; ok && !sameEntry(prev, e.Entry) {
+			old = prev
+		}
+		c.entries[id] = e.Entry
+		delete(c.revoked, id)
+	case EventRevoke:
+		old = c.entries[e.ID]
+		delete(c.entries, e.ID)
+		if c.revoked == nil {
+			c.revoked = map[string]uint64{}
+		}
+		c.revoked[e.ID] = e.Block
+	case EventCheckpoint:
+	default:
+		return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidEntry, e.Type)
+	}
+	c.height, c.syncedAt = e.Block, c.now()
+	return old, c.persist()
+}
+
+// Run keeps the cache in sync until ctx is done. It follows the events of
+// the registry, restarting the stream every MaxStaleness/2 and loading a
+// snapshot whenever the cache gets older than that, so that a quiet
+// channel or a broken stream do not make lookups fail.
+func (c *Cache) Run(ctx context.Context) error {
+	window := c.maxStaleness() / 2
+	for {
+		if c.SyncedAt().IsZero() || c.now().Sub(c.SyncedAt()) >= window {
+			if err := c.Refresh(ctx); err != nil {
+				c.reportError(ctx, err)
+			}
+		}
+		streamCtx, cancel := context.WithTimeout(ctx, window)
+		err := c.Source.Events(streamCtx, c.Height(), c.Apply)
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			c.reportError(ctx, fmt.Errorf("key registry event stream failed: %w", err))
+			// Do not hammer a source that is down
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(window / 10):
+			}
+		}
+	}
+}
+
+func (c *Cache) reportError(ctx context.Context, err error) {
+	if c.OnError != nil && ctx.Err() == nil {
+		c.OnError(err)
+	}
+}
+
+func (c *Cache) invalidate(entries []*Entry) {
+	if c.OnInvalidate == nil {
+		return
+	}
+	for _, e := range entries {
+		c.OnInvalidate(e)
+	}
+}
+
+// persist writes the cache to Path, the caller holds the lock
+func (c *Cache) persist() error {
+	if c.Path == "" {
+		return nil
+	}
+	f := cacheFile{Height: c.height, SyncedAt: c.syncedAt, Entries: make([]*Entry, 0, len(c.entries)), Revoked: c.revoked}
+	for _, e := range c.entries {
+		f.Entries = append(f.Entries, e)
+	}
+	slices.SortFunc(f.Entries, func(a, b *Entry) int { return strings.Compare(a.ID, b.ID) })
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to persist key registry cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.Path); err != nil {
+		return fmt.Errorf("failed to persist key registry cache: %w", err)
+	}
+	return nil
+}
+
+// sameEntry reports whether a and b publish the same keys and preferences
+func sameEntry(a, b *Entry) bool {
+	return maps.EqualFunc(a.Keys, b.Keys, bytes.Equal) && slices.Equal(a.Preferences, b.Preferences)
+}
@@ -0,0 +1,163 @@
+package keyregistry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// source is a registry chaincode whose history is events
+type source struct {
+	mutex     sync.Mutex
+	events    []*Event
+	snapshots int
+	froms     []uint64
+}
+
+func (s *source) Snapshot(context.Context) ([]*Entry, uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.snapshots++
+	state := &Memory{}
+	var height uint64
+	for _, e := range s.events {
+		switch e.Type {
+		case EventUpdate:
+			if err := state.Put(e.Entry); err != nil {
+				return nil, 0, err
+			}
+		case EventRevoke:
+			state.Delete(e.ID)
+		}
+		height = e.Block
+	}
+	return state.Entries(), height, nil
+}
+
+func (s *source) Events(ctx context.Context, from uint64, handle func(*Event) error) error {
+	s.mutex.Lock()
+	s.froms = append(s.froms, from)
+	var events []*Event
+	for _, e := range s.events {
+		if e.Block >= from {
+			events = append(events, e)
+		}
+	}
+	s.mutex.Unlock()
+	for _, e := range events {
+		if err := handle(e); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestParseEvent(t *testing.T) {
+	e, err := ParseEvent([]byte(`{"type":"update","entry":{"id":"Org1MSP/peer0","keys":{"`+CompositeMLDSA65+`":"a2V5"}}}`), 9)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP/peer0", e.ID)
+	assert.Equal(t, uint64(9), e.Block)
+	_, err = ParseEvent([]byte(`{"type":"revoke"}`), 9)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+	_, err = ParseEvent([]byte(`{"type":"rename","id":"x"}`), 9)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &source{events: []*Event{
+		{Type: EventUpdate, Entry: entry("Org1MSP/peer0", []string{CompositeMLDSA65}), Block: 9},
+		{Type: EventUpdate, Entry: entry("Org2MSP/peer0", []string{CompositeMLDSA65}), Block: 10},
+	}}
+	var invalidated []string
+	path := filepath.Join(t.TempDir(), "registry.json")
+	c := &Cache{
+		Source: src, Path: path, MaxStaleness: time.Minute,
+		OnInvalidate: func(e *Entry) { invalidated = append(invalidated, e.ID) },
+		Now:          func() time.Time { return now },
+	}
+	_, err := c.Lookup("Org1MSP/peer0")
+	assert.ErrorIs(t, err, ErrStale)
+
+	require.NoError(t, c.Refresh(context.Background()))
+	_, err = c.Lookup("Org1MSP/peer0")
+	require.NoError(t, err)
+	_, err = c.Lookup("Org3MSP/peer0")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Org1 rotates its key, Org2 is revoked
+	rotated := entry("Org1MSP/peer0", []string{CompositeMLDSA65, CompositeMLDSA87}, CompositeMLDSA87)
+	require.NoError(t, c.Apply(&Event{Type: EventUpdate, Entry: rotated, Block: 11}))
+	require.NoError(t, c.Apply(&Event{Type: EventRevoke, ID: "Org2MSP/peer0", Block: 12}))
+	assert.Equal(t, []string{"Org1MSP/peer0", "Org2MSP/peer0"}, invalidated)
+	e, err := c.Lookup("Org1MSP/peer0")
+	require.NoError(t, err)
+	assert.Equal(t, rotated, e)
+	_, err = c.Lookup("Org2MSP/peer0")
+	assert.ErrorIs(t, err, ErrRevoked)
+
+	// Events of blocks already applied are ignored
+	require.NoError(t, c.Apply(&Event{Type: EventUpdate, Entry: entry("Org2MSP/peer0", []string{CompositeMLDSA65}), Block: 11}))
+	_, err = c.Lookup("Org2MSP/peer0")
+	assert.ErrorIs(t, err, ErrRevoked)
+	assert.Equal(t, uint64(12), c.Height())
+
+	// Lookups fail once the cache outlives MaxStaleness, until it hears
+	// from the channel again
+	now = now.Add(2 * time.Minute)
+	_, err = c.Lookup("Org1MSP/peer0")
+	assert.ErrorIs(t, err, ErrStale)
+	require.NoError(t, c.Apply(&Event{Type: EventCheckpoint, Block: 13}))
+	_, err = c.Lookup("Org1MSP/peer0")
+	require.NoError(t, err)
+
+	// A restarted verifier serves the persisted cache within the bound
+	restarted := &Cache{Source: src, Path: path, MaxStaleness: time.Minute, Now: func() time.Time { return now }}
+	require.NoError(t, restarted.Load())
+	assert.Equal(t, uint64(13), restarted.Height())
+	e, err = restarted.Lookup("Org1MSP/peer0")
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Keys, e.Keys)
+	_, err = restarted.Lookup("Org2MSP/peer0")
+	assert.ErrorIs(t, err, ErrRevoked)
+
+	// Snapshots older than the cache do not roll it back
+	src.events = src.events[:1]
+	assert.Error(t, c.Refresh(context.Background()))
+}
+
+func TestCacheRun(t *testing.T) {
+	org2 := entry("Org2MSP/peer0", []string{CompositeMLDSA65})
+	src := &source{
+		events: []*Event{
+			{Type: EventUpdate, Entry: entry("Org1MSP/peer0", []string{CompositeMLDSA65}), Block: 4},
+			{Type: EventRevoke, ID: "Org1MSP/peer0", Block: 5},
+			{Type: EventUpdate, Entry: org2, Block: 6},
+		},
+	}
+	var errs []error
+	c := &Cache{Source: src, MaxStaleness: 100 * time.Millisecond, OnError: func(err error) { errs = append(errs, err) }}
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(c.Run(ctx), context.DeadlineExceeded))
+	assert.Empty(t, errs)
+
+	// Events before the snapshot are not replayed
+	_, err := c.Lookup("Org1MSP/peer0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.Lookup("Org2MSP/peer0")
+	assert.NoError(t, err)
+	src.mutex.Lock()
+	defer src.mutex.Unlock()
+	// The stream resumes from the last block applied and, on a quiet
+	// channel, the snapshot is reloaded once the cache gets half stale
+	assert.Equal(t, []uint64{6, 6, 6}, src.froms[:3])
+	assert.GreaterOrEqual(t, src.snapshots, 1)
+}
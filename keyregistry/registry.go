@@ -0,0 +1,237 @@
+// Package keyregistry holds the public keys identities publish for others
+// to verify their signatures, together with their ordered algorithm
+// preferences. Signers negotiate the composite to sign with from the
+// entries of their verifiers, so a stronger composite such as
+// ECDSA-P256+ML-DSA-87 can be adopted organization by organization instead
+// of on a flag day. Cache keeps a local copy of the on-channel registry for
+// verifiers. It depends only on the core module.
+package keyregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Composites, named after the algorithms of core.PublicKeyJSON joined by +
+const (
+	CompositeMLDSA44    = core.ClassicalAlgorithm + "+ML-DSA-44"
+	CompositeMLDSA65    = core.ClassicalAlgorithm + "+ML-DSA-65"
+	CompositeMLDSA87    = core.ClassicalAlgorithm + "+ML-DSA-87"
+	CompositeFalcon512  = core.ClassicalAlgorithm + "+Falcon-512"
+	CompositeFalcon1024 = core.ClassicalAlgorithm + "+Falcon-1024"
+)
+
+// DefaultComposite is the composite of core keys, assumed for entries
+// without preferences
+const DefaultComposite = core.ClassicalAlgorithm + "+" + core.PQCAlgorithm
+
+// strength is the NIST security category of the PQC half of each composite
+var strength = map[string]int{
+	CompositeFalcon512:  1,
+	CompositeMLDSA44:    2,
+	CompositeMLDSA65:    3,
+	CompositeMLDSA87:    5,
+	CompositeFalcon1024: 5,
+}
+
+var (
+	// ErrNotFound is returned by Lookup for unknown identities
+	ErrNotFound = errors.New("identity not in the key registry")
+	// ErrNoCommonComposite is returned when the signer has no key for a
+	// composite every verifier accepts
+	ErrNoCommonComposite = errors.New("no mutually supported composite")
+	// ErrInvalidEntry is returned for malformed entries
+	ErrInvalidEntry = errors.New("invalid registry entry")
+)
+
+// Strength returns the NIST security category of a composite, 0 if unknown
+func Strength(composite string) int {
+	return strength[composite]
+}
+
+// Entry is what an identity publishes in the registry
+type Entry struct {
+	// ID identifies the identity, e.g. Org1MSP/peer0.org1.example.com
+	ID string `json:"id"`
+	// Keys are the DER composite public keys of the identity by composite
+	Keys map[string][]byte `json:"keys"`
+	// Preferences are the composites the identity accepts, most preferred
+	// first. Empty means DefaultComposite only.
+	Preferences []string  `json:"preferences,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate checks that the entry names known composites, once each, and
+// has a key
+func (e *Entry) Validate() error {
+	if e.ID == "" {
+		return fmt.Errorf("%w: empty ID", ErrInvalidEntry)
+	}
+	if len(e.Keys) == 0 {
+		return fmt.Errorf("%w: %s has no keys", ErrInvalidEntry, e.ID)
+	}
+	for c, key := range e.Keys {
+		if Strength(c) == 0 {
+			return fmt.Errorf("%w: %s: unknown composite %q", ErrInvalidEntry, e.ID, c)
+		}
+		if len(key) == 0 {
+			return fmt.Errorf("%w: %s: empty %s key", ErrInvalidEntry, e.ID, c)
+		}
+	}
+	for i, c := range e.Preferences {
+		if Strength(c) == 0 {
+			return fmt.Errorf("%w: %s: unknown composite %q", ErrInvalidEntry, e.ID, c)
+		}
+		if slices.Contains(e.Preferences[:i], c) {
+			return fmt.Errorf("%w: %s: %s preferred twice", ErrInvalidEntry, e.ID, c)
+		}
+	}
+	return nil
+}
+
+// Accepts returns the composites the identity accepts, most preferred first
+func (e *Entry) Accepts() []string {
+	if len(e.Preferences) == 0 {
+		return []string{DefaultComposite}
+	}
+	return e.Preferences
+}
+
+// Key returns the public key of the identity for composite
+func (e *Entry) Key(composite string) ([]byte, bool) {
+	key, ok := e.Keys[composite]
+	return key, ok
+}
+
+// Negotiate returns the composite signer signs with for verifiers: the
+// strongest one signer prefers and has a key for that every verifier
+// accepts. Composites of equal strength are ranked by signer's preferences.
+func Negotiate(signer *Entry, verifiers ...*Entry) (string, error) {
+	var best string
+	for _, c := range signer.Accepts() {
+		if _, ok := signer.Keys[c]; !ok || Strength(c) <= Strength(best) {
+			continue
+		}
+		accepted := true
+		for _, v := range verifiers {
+			if !slices.Contains(v.Accepts(), c) {
+				accepted = false
+				break
+			}
+		}
+		if accepted {
+			best = c
+		}
+	}
+	if best == "" {
+		ids := make([]string, len(verifiers))
+		for i, v := range verifiers {
+			ids[i] = v.ID
+		}
+		return "", fmt.Errorf("%w between %s and %s", ErrNoCommonComposite, signer.ID, strings.Join(ids, ", "))
+	}
+	return best, nil
+}
+
+// Registry looks identities up
+type Registry interface {
+	Lookup(id string) (*Entry, error)
+}
+
+// NegotiateIDs looks signer and verifiers up in r and negotiates their
+// composite
+func NegotiateIDs(r Registry, signer string, verifiers ...string) (string, error) {
+	s, err := r.Lookup(signer)
+	if err != nil {
+		return "", err
+	}
+	entries := make([]*Entry, len(verifiers))
+	for i, id := range verifiers {
+		if entries[i], err = r.Lookup(id); err != nil {
+			return "", err
+		}
+	}
+	return Negotiate(s, entries...)
+}
+
+// Memory is an in-memory Registry, safe for concurrent use. The zero value
+// is empty and ready to use.
+type Memory struct {
+	mutex   sync.RWMutex
+	entries map[string]*Entry
+}
+
+// Lookup implements Registry
+func (m *Memory) Lookup(id string) (*Entry, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return e, nil
+}
+
+// Put validates e and stores it, replacing the entry of the same ID
+func (m *Memory) Put(e *Entry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.entries == nil {
+		m.entries = map[string]*Entry{}
+	}
+	m.entries[e.ID] = e
+	return nil
+}
+
+// Delete removes the entry of id
+func (m *Memory) Delete(id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.entries, id)
+}
+
+// Entries returns the entries sorted by ID
+func (m *Memory) Entries() []*Entry {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	entries := make([]*Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// MarshalJSON encodes the registry as the list of its entries
+func (m *Memory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Entries())
+}
+
+// UnmarshalJSON replaces the entries with a list of entries
+func (m *Memory) UnmarshalJSON(data []byte) error {
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	loaded := &Memory{}
+	for _, e := range entries {
+		if err := loaded.Put(e); err != nil {
+			return err
+		}
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.entries = loaded.entries
+	return nil
+}
@@ -0,0 +1,72 @@
+package keyregistry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entry(id string, keys []string, prefs ...string) *Entry {
+	e := &Entry{ID: id, Keys: map[string][]byte{}, Preferences: prefs}
+	for _, c := range keys {
+		e.Keys[c] = []byte("key " + c)
+	}
+	return e
+}
+
+func TestNegotiate(t *testing.T) {
+	// Org1 has migrated to ML-DSA-87 and Falcon-1024, Org2 accepts
+	// ML-DSA-87 too, Org3 has not upgraded yet
+	org1 := entry("Org1MSP/peer0", []string{CompositeMLDSA65, CompositeMLDSA87, CompositeFalcon1024},
+		CompositeFalcon1024, CompositeMLDSA87, CompositeMLDSA65)
+	org2 := entry("Org2MSP/peer0", []string{CompositeMLDSA65, CompositeMLDSA87}, CompositeMLDSA87, CompositeMLDSA65)
+	org3 := entry("Org3MSP/peer0", []string{CompositeMLDSA65})
+
+	c, err := Negotiate(org1, org2)
+	require.NoError(t, err)
+	assert.Equal(t, CompositeMLDSA87, c)
+	c, err = Negotiate(org1)
+	require.NoError(t, err)
+	assert.Equal(t, CompositeFalcon1024, c, "equal strength follows the signer's order")
+	c, err = Negotiate(org1, org2, org3)
+	require.NoError(t, err)
+	assert.Equal(t, CompositeMLDSA65, c)
+
+	// Preferring a composite is not enough, the signer needs its key
+	org4 := entry("Org4MSP/peer0", []string{CompositeMLDSA65}, CompositeMLDSA87)
+	_, err = Negotiate(org4, org2)
+	assert.ErrorIs(t, err, ErrNoCommonComposite)
+	c, err = Negotiate(org2, org4)
+	require.NoError(t, err)
+	assert.Equal(t, CompositeMLDSA87, c)
+	falcon := entry("Org5MSP/peer0", []string{CompositeFalcon512}, CompositeFalcon512)
+	_, err = Negotiate(org2, falcon)
+	assert.ErrorIs(t, err, ErrNoCommonComposite)
+}
+
+func TestMemory(t *testing.T) {
+	m := &Memory{}
+	require.NoError(t, m.Put(entry("Org1MSP/peer0", []string{CompositeMLDSA65, CompositeMLDSA87}, CompositeMLDSA87, CompositeMLDSA65)))
+	require.NoError(t, m.Put(entry("Org2MSP/peer0", []string{CompositeMLDSA65})))
+	assert.ErrorIs(t, m.Put(entry("Org3MSP/peer0", []string{"ECDSA-P256+Rainbow"})), ErrInvalidEntry)
+	assert.ErrorIs(t, m.Put(entry("Org3MSP/peer0", []string{CompositeMLDSA65}, CompositeMLDSA65, CompositeMLDSA65)), ErrInvalidEntry)
+	assert.ErrorIs(t, m.Put(entry("Org3MSP/peer0", nil)), ErrInvalidEntry)
+
+	c, err := NegotiateIDs(m, "Org1MSP/peer0", "Org2MSP/peer0")
+	require.NoError(t, err)
+	assert.Equal(t, CompositeMLDSA65, c)
+	_, err = NegotiateIDs(m, "Org1MSP/peer0", "Org3MSP/peer0")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	raw, err := json.Marshal(m)
+	require.NoError(t, err)
+	loaded := &Memory{}
+	require.NoError(t, json.Unmarshal(raw, loaded))
+	assert.Equal(t, m.Entries(), loaded.Entries())
+
+	m.Delete("Org2MSP/peer0")
+	_, err = m.Lookup("Org2MSP/peer0")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
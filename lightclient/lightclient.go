@@ -0,0 +1,192 @@
+// Package lightclient verifies hybrid-signed state commitments exported from
+// a Fabric channel inside another ledger's client, in the style of a
+// Tendermint light client: the client trusts a validator set (the composite
+// keys of the channel's orderer or endorsing organizations and a signature
+// threshold), accepts a commitment once enough validators signed it, and
+// follows validator set changes announced by the previous trusted
+// commitment. It depends only on the core module so it can be embedded in
+// bridge relayers and other chains' clients.
+package lightclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Domains separating commitment and validator set encodings
+const (
+	commitmentDomain   = "QLCOMMIT1"
+	validatorSetDomain = "QLVALSET1"
+)
+
+var (
+	// ErrInsufficientSignatures is returned when fewer than the threshold of
+	// trusted validators signed a commitment
+	ErrInsufficientSignatures = errors.New("not enough valid validator signatures")
+	// ErrNonMonotonicHeight is returned for commitments not above the trusted height
+	ErrNonMonotonicHeight = errors.New("commitment height is not above the trusted height")
+	// ErrValidatorSetMismatch is returned when a new validator set was not
+	// announced by the trusted commitment
+	ErrValidatorSetMismatch = errors.New("validator set does not match the trusted commitment")
+	// ErrWrongChain is returned for commitments of another channel
+	ErrWrongChain = errors.New("commitment is for another chain")
+)
+
+// Commitment is the state of a channel at a block height
+type Commitment struct {
+	ChainID   string
+	Height    uint64
+	Time      time.Time
+	StateRoot []byte
+	// NextValidatorsHash is the hash of the validator set signing the next
+	// commitments
+	NextValidatorsHash []byte
+}
+
+// Bytes is the canonical encoding signed by validators
+func (c *Commitment) Bytes() []byte {
+	return core.NewSignedBytes(commitmentDomain).
+		AppendBytes([]byte(c.ChainID)).
+		AppendUint64(c.Height).
+		AppendUint64(uint64(c.Time.UnixNano())).
+		AppendBytes(c.StateRoot).
+		AppendBytes(c.NextValidatorsHash)
+}
+
+// Digest is the SHA-256 of Bytes
+func (c *Commitment) Digest() []byte {
+	d := sha256.Sum256(c.Bytes())
+	return d[:]
+}
+
+// Signature is a validator's hybrid signature of a commitment
+type Signature struct {
+	// Validator is the composite public key of the signer
+	Validator []byte
+	Signature []byte
+}
+
+// SignedCommitment is a commitment with its validator signatures
+type SignedCommitment struct {
+	Commitment Commitment
+	Signatures []Signature
+}
+
+// Sign adds the signature of key to sc
+func (sc *SignedCommitment) Sign(key *core.PrivateKey) error {
+	validator, err := key.Public().Marshal()
+	if err != nil {
+		return err
+	}
+	sig, err := key.Sign(sc.Commitment.Digest())
+	if err != nil {
+		return err
+	}
+	sc.Signatures = append(sc.Signatures, Signature{Validator: validator, Signature: sig})
+	return nil
+}
+
+// ValidatorSet is the composite keys of the validators and how many of them
+// must sign a commitment
+type ValidatorSet struct {
+	Validators [][]byte
+	Threshold  int
+}
+
+// Hash identifies the set independently of the order of the validators
+func (v *ValidatorSet) Hash() []byte {
+	sorted := append([][]byte(nil), v.Validators...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	b := core.NewSignedBytes(validatorSetDomain).
+		AppendUint32(uint32(v.Threshold)).
+		AppendUint32(uint32(len(sorted)))
+	for _, key := range sorted {
+		b = b.AppendBytes(key)
+	}
+	return b.Digest()
+}
+
+// Verify checks that at least Threshold distinct validators of v produced a
+// valid hybrid signature of c; signatures of unknown keys are ignored
+func (v *ValidatorSet) Verify(sc *SignedCommitment) error {
+	if v.Threshold < 1 || v.Threshold > len(v.Validators) {
+		return fmt.Errorf("invalid validator set threshold %d of %d", v.Threshold, len(v.Validators))
+	}
+	digest := sc.Commitment.Digest()
+	signed := map[string]bool{}
+	for _, s := range sc.Signatures {
+		if signed[string(s.Validator)] || !v.contains(s.Validator) {
+			continue
+		}
+		pub, err := core.ParsePublicKey(s.Validator)
+		if err != nil {
+			continue
+		}
+		if valid, err := pub.Verify(digest, s.Signature, core.PolicyHybridAND); err == nil && valid {
+			signed[string(s.Validator)] = true
+		}
+	}
+	if len(signed) < v.Threshold {
+		return fmt.Errorf("%w: %d of %d required", ErrInsufficientSignatures, len(signed), v.Threshold)
+	}
+	return nil
+}
+
+func (v *ValidatorSet) contains(key []byte) bool {
+	for _, k := range v.Validators {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client tracks the latest trusted commitment of a chain
+type Client struct {
+	chainID    string
+	validators ValidatorSet
+	trusted    *Commitment
+}
+
+// NewClient trusts validators for chainID, e.g. from the channel genesis
+// block or a social consensus checkpoint
+func NewClient(chainID string, validators ValidatorSet) *Client {
+	return &Client{chainID: chainID, validators: validators}
+}
+
+// Trusted returns the latest verified commitment, nil before the first Update
+func (c *Client) Trusted() *Commitment {
+	return c.trusted
+}
+
+// Update verifies sc against the trusted validator set and makes it the
+// trusted commitment. next is the validator set signing the following
+// commitments; it must hash to sc's NextValidatorsHash, and may be nil when
+// the set does not change.
+func (c *Client) Update(sc *SignedCommitment, next *ValidatorSet) error {
+	if sc.Commitment.ChainID != c.chainID {
+		return fmt.Errorf("%w: %s", ErrWrongChain, sc.Commitment.ChainID)
+	}
+	if c.trusted != nil && sc.Commitment.Height <= c.trusted.Height {
+		return fmt.Errorf("%w: %d <= %d", ErrNonMonotonicHeight, sc.Commitment.Height, c.trusted.Height)
+	}
+	if err := c.validators.Verify(sc); err != nil {
+		return err
+	}
+	if next == nil {
+		next = &c.validators
+	}
+	if !bytes.Equal(next.Hash(), sc.Commitment.NextValidatorsHash) {
+		return ErrValidatorSetMismatch
+	}
+	c.validators = *next
+	trusted := sc.Commitment
+	c.trusted = &trusted
+	return nil
+}
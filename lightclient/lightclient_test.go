@@ -0,0 +1,86 @@
+package lightclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+func validators(t *testing.T, n, threshold int) ([]*core.PrivateKey, ValidatorSet) {
+	var keys []*core.PrivateKey
+	set := ValidatorSet{Threshold: threshold}
+	for i := 0; i < n; i++ {
+		k, err := core.GenerateKey()
+		require.NoError(t, err)
+		t.Cleanup(k.Clean)
+		pub, err := k.Public().Marshal()
+		require.NoError(t, err)
+		keys = append(keys, k)
+		set.Validators = append(set.Validators, pub)
+	}
+	return keys, set
+}
+
+func commit(t *testing.T, height uint64, next *ValidatorSet, signers ...*core.PrivateKey) *SignedCommitment {
+	sc := &SignedCommitment{Commitment: Commitment{
+		ChainID:            "mychannel",
+		Height:             height,
+		Time:               time.Unix(1700000000+int64(height), 0),
+		StateRoot:          []byte{byte(height)},
+		NextValidatorsHash: next.Hash(),
+	}}
+	for _, k := range signers {
+		require.NoError(t, sc.Sign(k))
+	}
+	return sc
+}
+
+func TestClientUpdate(t *testing.T) {
+	keys, set := validators(t, 3, 2)
+	c := NewClient("mychannel", set)
+
+	// One signature, even repeated, is not enough
+	sc := commit(t, 1, &set, keys[0])
+	sc.Signatures = append(sc.Signatures, sc.Signatures[0])
+	assert.ErrorIs(t, c.Update(sc, nil), ErrInsufficientSignatures)
+
+	require.NoError(t, c.Update(commit(t, 1, &set, keys[0], keys[2]), nil))
+	assert.Equal(t, uint64(1), c.Trusted().Height)
+
+	assert.ErrorIs(t, c.Update(commit(t, 1, &set, keys[0], keys[1]), nil), ErrNonMonotonicHeight)
+
+	// A tampered state root invalidates the signatures
+	sc = commit(t, 2, &set, keys[0], keys[1])
+	sc.Commitment.StateRoot = []byte("forged")
+	assert.ErrorIs(t, c.Update(sc, nil), ErrInsufficientSignatures)
+
+	other := commit(t, 2, &set, keys[0], keys[1])
+	other.Commitment.ChainID = "other"
+	assert.ErrorIs(t, c.Update(other, nil), ErrWrongChain)
+}
+
+func TestClientValidatorRotation(t *testing.T) {
+	oldKeys, oldSet := validators(t, 2, 2)
+	newKeys, newSet := validators(t, 3, 2)
+	c := NewClient("mychannel", oldSet)
+
+	// The old set announces the new one; an unannounced set is rejected
+	sc := commit(t, 10, &newSet, oldKeys...)
+	assert.ErrorIs(t, c.Update(sc, nil), ErrValidatorSetMismatch)
+	require.NoError(t, c.Update(sc, &newSet))
+
+	assert.ErrorIs(t, c.Update(commit(t, 11, &newSet, oldKeys...), nil), ErrInsufficientSignatures)
+	require.NoError(t, c.Update(commit(t, 11, &newSet, newKeys[1], newKeys[2]), nil))
+	assert.Equal(t, []byte{11}, c.Trusted().StateRoot)
+}
+
+func TestValidatorSetHashIgnoresOrder(t *testing.T) {
+	_, set := validators(t, 3, 2)
+	reversed := ValidatorSet{Threshold: 2, Validators: [][]byte{set.Validators[2], set.Validators[1], set.Validators[0]}}
+	assert.Equal(t, set.Hash(), reversed.Hash())
+	reversed.Threshold = 3
+	assert.NotEqual(t, set.Hash(), reversed.Hash())
+}
@@ -0,0 +1,102 @@
+package loadgen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// NotValidated is the code of transactions missing from a block's filter
+const NotValidated ValidationCode = 254
+
+// BlockEvents delivers committed blocks, as a peer's deliver service does
+// for block event listeners. The channel is closed when ctx is done.
+type BlockEvents interface {
+	Blocks(ctx context.Context) (<-chan *fabproto.Block, error)
+}
+
+// Confirmation is the end-to-end confirmation time of a transaction: from
+// submission until the block committing it was received
+type Confirmation struct {
+	TxID      string        `json:"tx_id"`
+	Submitted time.Time     `json:"submitted"`
+	Latency   time.Duration `json:"latency"`
+}
+
+type commit struct {
+	at   time.Time
+	code ValidationCode
+}
+
+// tracker matches committed transactions with the submissions waiting for
+// them. Commits may arrive before the submitter returned the transaction
+// ID, so unmatched ones are kept until a submission claims them.
+type tracker struct {
+	clock     clock.Clock
+	mutex     sync.Mutex
+	committed map[string]commit
+	waiting   map[string]chan commit
+}
+
+func newTracker(c clock.Clock) *tracker {
+	return &tracker{clock: c, committed: map[string]commit{}, waiting: map[string]chan commit{}}
+}
+
+// follow observes blocks until the channel is closed
+func (t *tracker) follow(blocks <-chan *fabproto.Block) {
+	for b := range blocks {
+		t.observe(b, t.clock.Now())
+	}
+}
+
+// observe records the transactions of b, committed at
+func (t *tracker) observe(b *fabproto.Block, at time.Time) {
+	var filter []byte
+	if b.Metadata != nil && len(b.Metadata.Metadata) > fabproto.BlockMetadataIndexTxFilter {
+		filter = b.Metadata.Metadata[fabproto.BlockMetadataIndexTxFilter]
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i, raw := range b.Data {
+		ch, err := fabproto.EnvelopeChannelHeader(raw)
+		if err != nil || ch.TxId == "" {
+			continue
+		}
+		c := commit{at: at, code: NotValidated}
+		if i < len(filter) {
+			c.code = ValidationCode(filter[i])
+		}
+		if w, ok := t.waiting[ch.TxId]; ok {
+			delete(t.waiting, ch.TxId)
+			w <- c
+		} else {
+			t.committed[ch.TxId] = c
+		}
+	}
+}
+
+// wait returns the commit of txID once observed
+func (t *tracker) wait(ctx context.Context, txID string) (commit, error) {
+	t.mutex.Lock()
+	if c, ok := t.committed[txID]; ok {
+		delete(t.committed, txID)
+		t.mutex.Unlock()
+		return c, nil
+	}
+	w := make(chan commit, 1)
+	t.waiting[txID] = w
+	t.mutex.Unlock()
+
+	select {
+	case c := <-w:
+		return c, nil
+	case <-ctx.Done():
+		t.mutex.Lock()
+		delete(t.waiting, txID)
+		t.mutex.Unlock()
+		return commit{}, ctx.Err()
+	}
+}
@@ -0,0 +1,354 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+)
+
+// A single client box saturates before the HIGH and SUSTAINED profiles
+// stress the peers, so a run can be spread over several agents. The
+// coordinator splits the load, starts every agent at the same instant of
+// its own clock and merges the reports on its own clock. Agents and the
+// coordinator talk HTTP/JSON, like the other services of the repository.
+
+// ErrAgentBusy is returned by agents that are already running a share
+var ErrAgentBusy = errors.New("agent is already running")
+
+// TimeResponse carries an agent's clock reading
+type TimeResponse struct {
+	Time time.Time `json:"time"`
+}
+
+// RunRequest is the share of a run assigned to an agent
+type RunRequest struct {
+	RunID        int           `json:"run_id"`
+	CryptoMode   string        `json:"crypto_mode,omitempty"`
+	LoadProfile  string        `json:"load_profile"`
+	Rate         float64       `json:"rate"`
+	Transactions int           `json:"transactions"`
+	Concurrency  int           `json:"concurrency"`
+	PayloadSize  int           `json:"payload_size"`
+	Timeout      time.Duration `json:"timeout"`
+	Seed         int64         `json:"seed"`
+	// StartAt is when to start, on the agent's clock
+	StartAt time.Time `json:"start_at"`
+}
+
+// Agent runs the shares of a coordinated run against its submitter
+type Agent struct {
+	Submitter Submitter
+	// Events confirm transactions as in Config.Events, if set
+	Events BlockEvents
+	// CryptoMode is reported when requests leave it empty
+	CryptoMode string
+	// Clock is the agent's time source, clock.Real if nil
+	Clock clock.Clock
+
+	mutex   sync.Mutex
+	running bool
+}
+
+// Handler serves GET /v1/time and POST /v1/runs. A run request is answered
+// with the agent's Report once its share completes.
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/time", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, TimeResponse{Time: clock.Or(a.Clock).Now()})
+	})
+	mux.HandleFunc("POST /v1/runs", a.handleRun)
+	return mux
+}
+
+func (a *Agent) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.mutex.Lock()
+	if a.running {
+		a.mutex.Unlock()
+		http.Error(w, ErrAgentBusy.Error(), http.StatusConflict)
+		return
+	}
+	a.running = true
+	a.mutex.Unlock()
+	defer func() {
+		a.mutex.Lock()
+		a.running = false
+		a.mutex.Unlock()
+	}()
+
+	report, err := a.Run(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respond(w, report)
+}
+
+// Run waits until req.StartAt and runs the share
+func (a *Agent) Run(ctx context.Context, req RunRequest) (*Report, error) {
+	clk := clock.Or(a.Clock)
+	if err := clock.SleepUntil(ctx, clk, req.StartAt); err != nil {
+		return nil, err
+	}
+	mode := req.CryptoMode
+	if mode == "" {
+		mode = a.CryptoMode
+	}
+	return Run(ctx, a.Submitter, Config{
+		RunID:        req.RunID,
+		CryptoMode:   mode,
+		LoadProfile:  req.LoadProfile,
+		Rate:         req.Rate,
+		Transactions: req.Transactions,
+		Concurrency:  req.Concurrency,
+		PayloadSize:  req.PayloadSize,
+		Timeout:      req.Timeout,
+		Seed:         req.Seed,
+		Clock:        clk,
+		Events:       a.Events,
+	})
+}
+
+func respond(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Skew is the offset of an agent's clock from the coordinator's, measured
+// over a round trip of RTT. The offset is accurate to within RTT/2.
+type Skew struct {
+	Offset time.Duration
+	RTT    time.Duration
+}
+
+// AgentReport is an agent's report, with times already corrected to the
+// coordinator's clock
+type AgentReport struct {
+	Agent  string
+	Skew   Skew
+	Report *Report
+}
+
+// Coordinator spreads runs over agents given by their base URLs
+type Coordinator struct {
+	Agents []string
+	Client *http.Client
+	// Clock is the coordinator's time source, clock.Real if nil
+	Clock clock.Clock
+	// Lead is the time between dispatching the shares and starting them,
+	// one second if zero
+	Lead time.Duration
+	// Probes is the number of clock readings per agent; the one with the
+	// shortest round trip is kept. Eight if zero.
+	Probes int
+}
+
+// Skew estimates the clock offset of agent, NTP style: the agent read its
+// clock halfway through the round trip
+func (c *Coordinator) Skew(ctx context.Context, agent string) (Skew, error) {
+	clk := clock.Or(c.Clock)
+	probes := c.Probes
+	if probes <= 0 {
+		probes = 8
+	}
+	var best Skew
+	for i := 0; i < probes; i++ {
+		var resp TimeResponse
+		sent := clk.Now()
+		if err := c.call(ctx, http.MethodGet, agent+"/v1/time", nil, &resp); err != nil {
+			return Skew{}, err
+		}
+		received := clk.Now()
+		rtt := received.Sub(sent)
+		if i == 0 || rtt < best.RTT {
+			best = Skew{Offset: resp.Time.Sub(sent.Add(rtt / 2)), RTT: rtt}
+		}
+	}
+	return best, nil
+}
+
+// Run splits cfg over the agents, runs the shares simultaneously and
+// returns the merged report along with the report of each agent. Rate and
+// Transactions are divided among the agents; Concurrency applies to each
+// of them. Traces, Events and Clock are not supported: agents use their
+// own submitters, block events and clocks.
+func (c *Coordinator) Run(ctx context.Context, cfg Config) (*Report, []AgentReport, error) {
+	if len(c.Agents) == 0 {
+		return nil, nil, errors.New("no agents")
+	}
+	if len(cfg.Trace) > 0 {
+		return nil, nil, errors.New("traces cannot be replayed by several agents")
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+	shares := split(cfg, len(c.Agents))
+
+	results := make([]AgentReport, len(shares))
+	for i := range shares {
+		skew, err := c.Skew(ctx, c.Agents[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("agent %s: %w", c.Agents[i], err)
+		}
+		results[i] = AgentReport{Agent: c.Agents[i], Skew: skew}
+	}
+
+	lead := c.Lead
+	if lead <= 0 {
+		lead = time.Second
+	}
+	start := clock.Or(c.Clock).Now().Add(lead)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, len(shares))
+	var wg sync.WaitGroup
+	for i, share := range shares {
+		wg.Add(1)
+		go func(i int, req RunRequest) {
+			defer wg.Done()
+			req.StartAt = start.Add(results[i].Skew.Offset)
+			var r Report
+			if err := c.call(ctx, http.MethodPost, results[i].Agent+"/v1/runs", req, &r); err != nil {
+				errs[i] = fmt.Errorf("agent %s: %w", results[i].Agent, err)
+				// The run is incomplete without this share
+				cancel()
+				return
+			}
+			r.shift(-results[i].Skew.Offset)
+			results[i].Report = &r
+		}(i, share)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, results, err
+	}
+
+	reports := make([]*Report, len(results))
+	for i, r := range results {
+		reports[i] = r.Report
+	}
+	merged, err := MergeReports(reports...)
+	return merged, results, err
+}
+
+// split divides cfg into n shares, dropping empty ones. Each share has its
+// own seed so that agents generate distinct transactions.
+func split(cfg Config, n int) []RunRequest {
+	if n > cfg.Transactions {
+		n = cfg.Transactions
+	}
+	shares := make([]RunRequest, n)
+	for i := range shares {
+		txs := cfg.Transactions / n
+		if i < cfg.Transactions%n {
+			txs++
+		}
+		shares[i] = RunRequest{
+			RunID:        cfg.RunID,
+			CryptoMode:   cfg.CryptoMode,
+			LoadProfile:  cfg.LoadProfile,
+			Rate:         cfg.Rate * float64(txs) / float64(cfg.Transactions),
+			Transactions: txs,
+			Concurrency:  cfg.Concurrency,
+			PayloadSize:  cfg.PayloadSize,
+			Timeout:      cfg.Timeout,
+			Seed:         cfg.Seed ^ int64(i)<<32,
+		}
+	}
+	return shares
+}
+
+// shift moves the report's times by d
+func (r *Report) shift(d time.Duration) {
+	r.Start = r.Start.Add(d)
+	for i := range r.Confirmations {
+		r.Confirmations[i].Submitted = r.Confirmations[i].Submitted.Add(d)
+	}
+}
+
+// MergeReports combines the reports of the agents of a run, whose times
+// must be on the same clock. The merged run spans from the earliest start
+// to the latest end; confirmations are ordered by submission time.
+func MergeReports(reports ...*Report) (*Report, error) {
+	if len(reports) == 0 {
+		return nil, errors.New("no reports")
+	}
+	first := reports[0]
+	merged := &Report{
+		RunID:       first.RunID,
+		CryptoMode:  first.CryptoMode,
+		LoadProfile: first.LoadProfile,
+		Start:       first.Start,
+		Failures:    map[FailureClass]int{},
+	}
+	end := first.Start.Add(first.Duration)
+	for _, r := range reports {
+		if r.RunID != first.RunID || r.CryptoMode != first.CryptoMode || r.LoadProfile != first.LoadProfile {
+			return nil, fmt.Errorf("cannot merge run %d %s/%s with run %d %s/%s",
+				first.RunID, first.CryptoMode, first.LoadProfile, r.RunID, r.CryptoMode, r.LoadProfile)
+		}
+		if r.Start.Before(merged.Start) {
+			merged.Start = r.Start
+		}
+		if e := r.Start.Add(r.Duration); e.After(end) {
+			end = e
+		}
+		merged.Submitted += r.Submitted
+		merged.Committed += r.Committed
+		for class, n := range r.Failures {
+			merged.Failures[class] += n
+		}
+		merged.Confirmations = append(merged.Confirmations, r.Confirmations...)
+	}
+	merged.Duration = end.Sub(merged.Start)
+	sort.SliceStable(merged.Confirmations, func(i, j int) bool {
+		return merged.Confirmations[i].Submitted.Before(merged.Confirmations[j].Submitted)
+	})
+	return merged, nil
+}
+
+func (c *Coordinator) call(ctx context.Context, method, url string, in, out interface{}) error {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
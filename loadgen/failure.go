@@ -0,0 +1,144 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FailureClass is the cause of a failed transaction
+type FailureClass string
+
+// Failure classes reported per run
+const (
+	// FailureMVCC is a read set invalidated by a concurrent commit
+	FailureMVCC FailureClass = "mvcc_conflict"
+	// FailureEndorsementMismatch is endorsers returning different results
+	FailureEndorsementMismatch FailureClass = "endorsement_mismatch"
+	// FailureCryptoVerify is a creator or endorsement signature rejected at
+	// validation
+	FailureCryptoVerify FailureClass = "crypto_verify"
+	// FailureTimeout is a transaction not committed within the timeout
+	FailureTimeout FailureClass = "timeout"
+	// FailureOther is every other error
+	FailureOther FailureClass = "other"
+)
+
+// FailureClasses lists the classes in report order
+var FailureClasses = []FailureClass{
+	FailureMVCC, FailureEndorsementMismatch, FailureCryptoVerify, FailureTimeout, FailureOther,
+}
+
+var (
+	// ErrEndorsementMismatch is returned by submitters when proposal
+	// responses of different endorsers do not match
+	ErrEndorsementMismatch = errors.New("proposal responses do not match")
+	// ErrVerify is returned by submitters when a signature does not verify
+	ErrVerify = errors.New("signature verification failed")
+)
+
+// ValidationCode is Fabric's TxValidationCode, written by committing peers
+// in the transactions filter of each block
+type ValidationCode int32
+
+// Validation codes the failure classes are derived from
+const (
+	Valid                    ValidationCode = 0
+	BadCreatorSignature      ValidationCode = 4
+	EndorsementPolicyFailure ValidationCode = 10
+	MVCCReadConflict         ValidationCode = 11
+	PhantomReadConflict      ValidationCode = 12
+)
+
+var validationCodeNames = map[ValidationCode]string{
+	0: "VALID", 1: "NIL_ENVELOPE", 2: "BAD_PAYLOAD", 3: "BAD_COMMON_HEADER",
+	4: "BAD_CREATOR_SIGNATURE", 5: "INVALID_ENDORSER_TRANSACTION", 6: "INVALID_CONFIG_TRANSACTION",
+	7: "UNSUPPORTED_TX_PAYLOAD", 8: "BAD_PROPOSAL_TXID", 9: "DUPLICATE_TXID",
+	10: "ENDORSEMENT_POLICY_FAILURE", 11: "MVCC_READ_CONFLICT", 12: "PHANTOM_READ_CONFLICT",
+	13: "UNKNOWN_TX_TYPE", 14: "TARGET_CHAIN_NOT_FOUND", 15: "MARSHAL_TX_ERROR",
+	16: "NIL_TXACTION", 17: "EXPIRED_CHAINCODE", 18: "CHAINCODE_VERSION_CONFLICT",
+	19: "BAD_HEADER_EXTENSION", 20: "BAD_CHANNEL_HEADER", 21: "BAD_RESPONSE_PAYLOAD",
+	22: "BAD_RWSET", 23: "ILLEGAL_WRITESET", 24: "INVALID_WRITESET", 25: "INVALID_CHAINCODE",
+	254: "NOT_VALIDATED", 255: "INVALID_OTHER_REASON",
+}
+
+func (c ValidationCode) String() string {
+	if name, ok := validationCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("ValidationCode(%d)", int32(c))
+}
+
+// Class returns the failure class of a transaction committed with c, empty
+// for valid transactions. Endorsement policy failures count as crypto
+// failures: with a satisfiable policy they are endorsement signatures that
+// did not verify.
+func (c ValidationCode) Class() FailureClass {
+	switch c {
+	case Valid:
+		return ""
+	case MVCCReadConflict, PhantomReadConflict:
+		return FailureMVCC
+	case BadCreatorSignature, EndorsementPolicyFailure:
+		return FailureCryptoVerify
+	default:
+		return FailureOther
+	}
+}
+
+// ValidationError is a transaction committed as invalid
+type ValidationError struct {
+	TxID string
+	Code ValidationCode
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("transaction %s invalidated: %s", e.TxID, e.Code)
+}
+
+// messagePatterns classify errors of submitters that only return text, such
+// as the Fabric gateway and SDKs
+var messagePatterns = []struct {
+	substring string
+	class     FailureClass
+}{
+	{"MVCC_READ_CONFLICT", FailureMVCC},
+	{"PHANTOM_READ_CONFLICT", FailureMVCC},
+	{"ProposalResponsePayloads do not match", FailureEndorsementMismatch},
+	{"responses do not match", FailureEndorsementMismatch},
+	{"BAD_CREATOR_SIGNATURE", FailureCryptoVerify},
+	{"ENDORSEMENT_POLICY_FAILURE", FailureCryptoVerify},
+	{"signature verification failed", FailureCryptoVerify},
+	{"signature is invalid", FailureCryptoVerify},
+	{"deadline exceeded", FailureTimeout},
+	{"timeout", FailureTimeout},
+}
+
+// Classify returns the failure class of err, empty for nil
+func Classify(err error) FailureClass {
+	if err == nil {
+		return ""
+	}
+	var verr *ValidationError
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case errors.As(err, &timeout) && timeout.Timeout():
+		return FailureTimeout
+	case errors.As(err, &verr):
+		return verr.Code.Class()
+	case errors.Is(err, ErrEndorsementMismatch):
+		return FailureEndorsementMismatch
+	case errors.Is(err, ErrVerify):
+		return FailureCryptoVerify
+	}
+	msg := err.Error()
+	for _, p := range messagePatterns {
+		if strings.Contains(msg, p.substring) {
+			return p.class
+		}
+	}
+	return FailureOther
+}
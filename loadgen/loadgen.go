@@ -0,0 +1,184 @@
+// Package loadgen submits transaction workloads, with Poisson arrivals or
+// replayed from a recorded trace, classifies failed transactions and
+// reports per-class failure rates per benchmark run. Submitters adapt it to
+// a Fabric gateway or to the in-process Simulator.
+package loadgen
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+)
+
+// Transaction is one unit of load
+type Transaction struct {
+	ID      string
+	Payload []byte
+	// Arrival is when the workload scheduled the transaction
+	Arrival time.Time
+}
+
+// Submitter submits a transaction and returns once it is committed. Errors
+// are classified with Classify.
+type Submitter interface {
+	Submit(ctx context.Context, tx *Transaction) error
+}
+
+// Config describes a run
+type Config struct {
+	RunID       int
+	CryptoMode  string
+	LoadProfile string
+	// Rate is the mean arrival rate in transactions per second
+	Rate         float64
+	Transactions int
+	// Concurrency bounds in-flight transactions; arrivals wait for a free
+	// slot once it is reached
+	Concurrency int
+	PayloadSize int
+	// Timeout bounds each transaction, zero for none
+	Timeout time.Duration
+	// Seed makes arrivals, transaction IDs and payloads reproducible
+	Seed int64
+	// Trace, when set, replaces Poisson arrivals and PayloadSize: each
+	// transaction follows the previous one by the interarrival time of its
+	// trace entry and carries a payload of its size. The trace repeats if
+	// Transactions is larger; zero Transactions replays it once, and Rate
+	// is ignored.
+	Trace Trace
+	// Clock is the time source, clock.Real if nil. Arrivals, timeouts and
+	// confirmation times all follow it.
+	Clock clock.Clock
+	// Events, when set, are the committed blocks of the channel. Each
+	// transaction is then confirmed by the block carrying it, which gives
+	// its validation code and end-to-end confirmation time. Submitters
+	// must leave the transaction ID they submitted with in tx.ID.
+	Events BlockEvents
+}
+
+func (c *Config) validate() error {
+	if len(c.Trace) > 0 {
+		if c.Transactions == 0 {
+			c.Transactions = len(c.Trace)
+		}
+		if c.Transactions < 0 || c.Concurrency <= 0 {
+			return errors.New("transactions and concurrency must be positive")
+		}
+		return nil
+	}
+	if c.Rate <= 0 || c.Transactions <= 0 || c.Concurrency <= 0 {
+		return errors.New("rate, transactions and concurrency must be positive")
+	}
+	return nil
+}
+
+// arrival returns the interarrival time and payload size of transaction i
+func (c *Config) arrival(rng *rand.Rand, i int) (time.Duration, int) {
+	if len(c.Trace) > 0 {
+		e := c.Trace[i%len(c.Trace)]
+		return e.Interarrival, e.PayloadSize
+	}
+	return time.Duration(rng.ExpFloat64() / c.Rate * float64(time.Second)), c.PayloadSize
+}
+
+func (c *Config) txContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout > 0 {
+		return clock.WithTimeout(ctx, clock.Or(c.Clock), c.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Run submits cfg.Transactions transactions to s. When ctx is cancelled it
+// stops submitting, waits for the transactions in flight and returns the
+// partial report with the context error.
+func Run(ctx context.Context, s Submitter, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	clk := clock.Or(cfg.Clock)
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	var tr *tracker
+	if cfg.Events != nil {
+		blocks, err := cfg.Events.Blocks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tr = newTracker(clk)
+		go tr.follow(blocks)
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	report := newReport(cfg, clk.Now())
+	slots := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	var err error
+	next := report.Start
+	for i := 0; i < cfg.Transactions; i++ {
+		gap, size := cfg.arrival(rng, i)
+		next = next.Add(gap)
+		if err = clock.SleepUntil(ctx, clk, next); err != nil {
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+
+		tx := newTransaction(rng, size)
+		tx.Arrival = next
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			txCtx, cancel := cfg.txContext(ctx)
+			c, submitErr := submit(txCtx, clk, s, tx, tr)
+			cancel()
+			mutex.Lock()
+			report.record(submitErr, c)
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	report.Duration = clk.Now().Sub(report.Start)
+	return report, err
+}
+
+// submit submits tx and, with a tracker, waits for the block committing it.
+// The validation code in the block decides the outcome then.
+func submit(ctx context.Context, clk clock.Clock, s Submitter, tx *Transaction, tr *tracker) (*Confirmation, error) {
+	start := clk.Now()
+	err := s.Submit(ctx, tx)
+	var verr *ValidationError
+	if tr != nil && (err == nil || errors.As(err, &verr)) {
+		var c commit
+		if c, err = tr.wait(ctx, tx.ID); err == nil {
+			if c.code != Valid {
+				return nil, &ValidationError{TxID: tx.ID, Code: c.code}
+			}
+			return &Confirmation{TxID: tx.ID, Submitted: start, Latency: c.at.Sub(start)}, nil
+		}
+	}
+	if err != nil && clock.TimedOut(ctx) {
+		err = context.DeadlineExceeded
+	}
+	return nil, err
+}
+
+func newTransaction(rng *rand.Rand, payloadSize int) *Transaction {
+	id := make([]byte, 32)
+	rng.Read(id)
+	payload := make([]byte, payloadSize)
+	rng.Read(payload)
+	return &Transaction{ID: hex.EncodeToString(id), Payload: payload}
+}
@@ -0,0 +1,415 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/clock"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestClassify(t *testing.T) {
+	for _, c := range []struct {
+		err   error
+		class FailureClass
+	}{
+		{nil, ""},
+		{&ValidationError{Code: Valid}, ""},
+		{fmt.Errorf("commit: %w", &ValidationError{TxID: "tx", Code: MVCCReadConflict}), FailureMVCC},
+		{&ValidationError{Code: PhantomReadConflict}, FailureMVCC},
+		{&ValidationError{Code: BadCreatorSignature}, FailureCryptoVerify},
+		{&ValidationError{Code: EndorsementPolicyFailure}, FailureCryptoVerify},
+		{&ValidationError{Code: 9}, FailureOther},
+		{fmt.Errorf("endorse: %w", ErrEndorsementMismatch), FailureEndorsementMismatch},
+		{ErrVerify, FailureCryptoVerify},
+		{context.DeadlineExceeded, FailureTimeout},
+		{timeoutError{}, FailureTimeout},
+		{errors.New("transaction abc failed to commit with status code 11 (MVCC_READ_CONFLICT)"), FailureMVCC},
+		{errors.New("ProposalResponsePayloads do not match"), FailureEndorsementMismatch},
+		{errors.New("connection refused"), FailureOther},
+	} {
+		assert.Equal(t, c.class, Classify(c.err), "%v", c.err)
+	}
+	assert.Equal(t, "MVCC_READ_CONFLICT", MVCCReadConflict.String())
+	assert.Equal(t, "ValidationCode(100)", ValidationCode(100).String())
+}
+
+type scriptedSubmitter struct {
+	n    atomic.Int64
+	errs []error
+}
+
+func (s *scriptedSubmitter) Submit(ctx context.Context, tx *Transaction) error {
+	i := s.n.Add(1) - 1
+	err := s.errs[int(i)%len(s.errs)]
+	if err == context.DeadlineExceeded {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return err
+}
+
+func TestRunReport(t *testing.T) {
+	s := &scriptedSubmitter{errs: []error{
+		nil, nil, nil, nil,
+		&ValidationError{Code: MVCCReadConflict},
+		ErrEndorsementMismatch,
+		&ValidationError{Code: BadCreatorSignature},
+		context.DeadlineExceeded,
+	}}
+	cfg := Config{RunID: 3, CryptoMode: "HYBRID", LoadProfile: "LOWLOAD", Rate: 2000, Transactions: 16,
+		Concurrency: 1, PayloadSize: 64, Timeout: 10 * time.Millisecond}
+	r, err := Run(context.Background(), s, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 16, r.Submitted)
+	assert.Equal(t, 8, r.Committed)
+	assert.Equal(t, 0.5, r.SuccessRate())
+	for _, class := range []FailureClass{FailureMVCC, FailureEndorsementMismatch, FailureCryptoVerify, FailureTimeout} {
+		assert.Equal(t, 2, r.Failures[class], class)
+		assert.Equal(t, 0.125, r.Rate(class), class)
+	}
+	assert.Zero(t, r.Rate(FailureOther))
+	assert.Contains(t, r.String(), "mvcc_conflict 2 (12.50%)")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteFailureCSV(&buf, r))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1+len(FailureClasses))
+	assert.Equal(t, []string{"3", "HYBRID", "LOWLOAD", "timeout", "2", "0.125000"}, rows[4])
+	assert.Equal(t, []string{"3", "HYBRID", "LOWLOAD", "other", "0", "0.000000"}, rows[5])
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := Run(ctx, &scriptedSubmitter{errs: []error{nil}}, Config{Rate: 1, Transactions: 10, Concurrency: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, r.Submitted)
+
+	_, err = Run(context.Background(), &scriptedSubmitter{}, Config{Rate: 1})
+	assert.Error(t, err)
+}
+
+// interleavingContext calls during once, on its first Err check, which the
+// simulator makes after endorsing and before committing
+type interleavingContext struct {
+	context.Context
+	during func()
+}
+
+func (c *interleavingContext) Err() error {
+	if during := c.during; during != nil {
+		c.during = nil
+		during()
+	}
+	return c.Context.Err()
+}
+
+func TestSimulator(t *testing.T) {
+	s, err := NewSimulator(2, 1000, 1)
+	require.NoError(t, err)
+	defer s.Close()
+	cfg := Config{Rate: 5000, Transactions: 20, Concurrency: 1, PayloadSize: 32}
+	r, err := Run(context.Background(), s, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 20, r.Committed, r)
+
+	s.MismatchRate, s.CorruptRate = 0.3, 0.3
+	r, err = Run(context.Background(), s, cfg)
+	require.NoError(t, err)
+	assert.Positive(t, r.Failures[FailureEndorsementMismatch], r)
+	assert.Positive(t, r.Failures[FailureCryptoVerify], r)
+	assert.Equal(t, r.Submitted, r.Committed+r.Failures[FailureEndorsementMismatch]+r.Failures[FailureCryptoVerify])
+
+	// A transaction on the same key committed between the endorsement and
+	// the commit of another makes the latter conflict
+	hot, err := NewSimulator(1, 1, 1)
+	require.NoError(t, err)
+	defer hot.Close()
+	ctx := &interleavingContext{Context: context.Background(), during: func() {
+		assert.NoError(t, hot.Submit(context.Background(), &Transaction{ID: "winner"}))
+	}}
+	err = hot.Submit(ctx, &Transaction{ID: "loser"})
+	assert.Equal(t, FailureMVCC, Classify(err), err)
+	assert.NoError(t, hot.Submit(context.Background(), &Transaction{ID: "next"}))
+
+	_, err = NewSimulator(0, 1, 1)
+	assert.Error(t, err)
+}
+
+func TestConfirmations(t *testing.T) {
+	s, err := NewSimulator(1, 1000, 1)
+	require.NoError(t, err)
+	defer s.Close()
+	s.CorruptRate = 0.2
+	cfg := Config{RunID: 1, CryptoMode: "HYBRID", LoadProfile: "LOWLOAD", Rate: 5000, Transactions: 30,
+		Concurrency: 4, Timeout: 5 * time.Second, Events: s}
+	r, err := Run(context.Background(), s, cfg)
+	require.NoError(t, err)
+	assert.Positive(t, r.Failures[FailureCryptoVerify], r)
+	require.Len(t, r.Confirmations, r.Committed)
+	for _, c := range r.Confirmations {
+		assert.Positive(t, c.Latency)
+	}
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.subscribers) == 0
+	}, time.Second, time.Millisecond, "Run unsubscribes when done")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteConfirmationCSV(&buf, r))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1+r.Committed)
+	assert.Equal(t, []string{"1", "HYBRID", "LOWLOAD", "commit"}, rows[1][1:5])
+}
+
+func TestTracker(t *testing.T) {
+	s, err := NewSimulator(1, 1, 1)
+	require.NoError(t, err)
+	defer s.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, err := s.Blocks(ctx)
+	require.NoError(t, err)
+
+	// The block arrives before the submission is registered
+	tr := newTracker(clock.Real)
+	require.NoError(t, s.Submit(ctx, &Transaction{ID: "early"}))
+	b := <-blocks
+	b.Metadata.Metadata[2] = nil
+	tr.observe(b, time.Now())
+	c, err := tr.wait(ctx, "early")
+	require.NoError(t, err)
+	assert.Equal(t, NotValidated, c.code)
+
+	short, stop := context.WithTimeout(ctx, time.Millisecond)
+	defer stop()
+	_, err = tr.wait(short, "missing")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, tr.waiting)
+}
+
+// drive fires the timers of v as soon as the code under test waits on them
+func drive(ctx context.Context, v *clock.Virtual) {
+	for v.BlockUntil(ctx, 1) == nil {
+		v.AdvanceToNext()
+	}
+}
+
+func TestRunVirtualClock(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	v := clock.NewVirtual(start)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go drive(ctx, v)
+
+	// An hour of arrivals at one per minute runs instantly and ends exactly
+	// at the last arrival drawn from the seed
+	cfg := Config{Rate: 1.0 / 60, Transactions: 60, Concurrency: 1, Seed: 7, Clock: v}
+	r, err := Run(context.Background(), &scriptedSubmitter{errs: []error{nil}}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 60, r.Committed)
+	assert.Equal(t, start, r.Start)
+	var want time.Duration
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 60; i++ {
+		want += time.Duration(rng.ExpFloat64() * 60 * float64(time.Second))
+		rng.Read(make([]byte, 32))
+	}
+	assert.Equal(t, want, r.Duration)
+
+	// Timeouts expire on virtual time too
+	cfg = Config{Rate: 1, Transactions: 3, Concurrency: 3, Timeout: time.Hour, Clock: v}
+	r, err = Run(context.Background(), &scriptedSubmitter{errs: []error{context.DeadlineExceeded}}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Failures[FailureTimeout])
+	assert.GreaterOrEqual(t, r.Duration, time.Hour)
+}
+
+func TestTraceReplay(t *testing.T) {
+	v := clock.NewVirtual(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go drive(ctx, v)
+
+	// Record a Poisson run, then replay it with the same arrivals
+	rec := &Recorder{Submitter: &scriptedSubmitter{errs: []error{nil}}, Clock: v}
+	cfg := Config{Rate: 10, Transactions: 20, Concurrency: 1, PayloadSize: 100, Seed: 3, Clock: v}
+	recorded, err := Run(context.Background(), rec, cfg)
+	require.NoError(t, err)
+	trace := rec.Trace()
+	require.Len(t, trace, 20)
+	assert.Zero(t, trace[0].Interarrival)
+	assert.Equal(t, 100, trace[5].PayloadSize)
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.Write(&buf))
+	parsed, err := ReadTrace(&buf)
+	require.NoError(t, err)
+	// Microsecond resolution
+	assert.InDelta(t, float64(trace[7].Interarrival), float64(parsed[7].Interarrival), float64(time.Microsecond))
+
+	rec = &Recorder{Submitter: &scriptedSubmitter{errs: []error{nil}}, Clock: v}
+	replayed, err := Run(context.Background(), rec, Config{Concurrency: 1, Clock: v, Trace: trace})
+	require.NoError(t, err)
+	assert.Equal(t, 20, replayed.Submitted)
+	assert.Equal(t, trace[1:], rec.Trace()[1:])
+	var total time.Duration
+	for _, e := range trace {
+		total += e.Interarrival
+	}
+	assert.Equal(t, total, replayed.Duration)
+	assert.Greater(t, recorded.Duration, total, "the recorded run waited for its first arrival")
+
+	_, err = ReadTrace(strings.NewReader("a,b\n1,2\n"))
+	assert.Error(t, err)
+	_, err = ReadTrace(strings.NewReader("interarrival_us,payload_size_bytes\n-1,2\n"))
+	assert.Error(t, err)
+}
+
+func TestTraceFromBlocks(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	envelope := func(at time.Time, size int) []byte {
+		ch := &fabproto.ChannelHeader{Type: endorserTransaction, Timestamp: at, ChannelId: "ch", TxId: at.String()}
+		payload := &fabproto.Payload{Header: &fabproto.Header{ChannelHeader: ch.Marshal()}, Data: make([]byte, size)}
+		return (&fabproto.Envelope{Payload: payload.Marshal()}).Marshal()
+	}
+	config := (&fabproto.Envelope{Payload: (&fabproto.Payload{Header: &fabproto.Header{
+		ChannelHeader: (&fabproto.ChannelHeader{Type: 1, Timestamp: t0}).Marshal()}}).Marshal()}).Marshal()
+	blocks := []*fabproto.Block{
+		{Data: [][]byte{config, envelope(t0.Add(time.Second), 10), envelope(t0, 500)}},
+		{Data: [][]byte{envelope(t0.Add(1500*time.Millisecond), 20)}},
+	}
+	trace, err := TraceFromBlocks(blocks)
+	require.NoError(t, err)
+	require.Len(t, trace, 3)
+	assert.Equal(t, time.Duration(0), trace[0].Interarrival)
+	assert.Greater(t, trace[0].PayloadSize, 500)
+	assert.Equal(t, time.Second, trace[1].Interarrival)
+	assert.Equal(t, 500*time.Millisecond, trace[2].Interarrival)
+
+	_, err = TraceFromBlocks([]*fabproto.Block{{Data: [][]byte{config}}})
+	assert.Error(t, err)
+}
+
+func TestModeSimulator(t *testing.T) {
+	for _, mode := range []string{ModeECDSA, ModeDilithium3, ModeHybrid} {
+		s, err := NewModeSimulator(mode, 2, 1000, 1)
+		require.NoError(t, err, mode)
+		assert.Equal(t, mode, s.Mode())
+		require.NoError(t, s.Submit(context.Background(), &Transaction{ID: "a", Payload: []byte("x")}), mode)
+		s.CorruptRate = 1
+		assert.Equal(t, FailureCryptoVerify, Classify(s.Submit(context.Background(), &Transaction{ID: "b"})), mode)
+		s.Close()
+	}
+	_, err := NewModeSimulator("RSA", 1, 1, 1)
+	assert.Error(t, err)
+}
+
+// skewedClock is the real clock off by a fixed offset, like an agent box
+// without synchronized time
+type skewedClock struct{ offset time.Duration }
+
+func (c skewedClock) Now() time.Time                       { return time.Now().Add(c.offset) }
+func (c skewedClock) NewTimer(d time.Duration) clock.Timer { return clock.Real.NewTimer(d) }
+
+func TestSplit(t *testing.T) {
+	shares := split(Config{RunID: 2, Rate: 100, Transactions: 10, Concurrency: 4, Seed: 7}, 3)
+	require.Len(t, shares, 3)
+	txs, rate := 0, 0.0
+	for _, s := range shares {
+		txs += s.Transactions
+		rate += s.Rate
+		assert.Equal(t, 4, s.Concurrency)
+		assert.Equal(t, 2, s.RunID)
+	}
+	assert.Equal(t, 10, txs)
+	assert.InDelta(t, 100, rate, 1e-9)
+	assert.NotEqual(t, shares[0].Seed, shares[1].Seed)
+
+	assert.Len(t, split(Config{Rate: 100, Transactions: 2, Concurrency: 1}, 5), 2, "no empty shares")
+}
+
+func TestMergeReports(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	a := &Report{RunID: 1, CryptoMode: "HYBRID", Start: t0, Duration: time.Second, Submitted: 3, Committed: 2,
+		Failures:      map[FailureClass]int{FailureMVCC: 1},
+		Confirmations: []Confirmation{{TxID: "a", Submitted: t0.Add(500 * time.Millisecond)}}}
+	b := &Report{RunID: 1, CryptoMode: "HYBRID", Start: t0.Add(-time.Second), Duration: time.Second, Submitted: 2,
+		Committed: 1, Failures: map[FailureClass]int{FailureMVCC: 1},
+		Confirmations: []Confirmation{{TxID: "b", Submitted: t0.Add(-500 * time.Millisecond)}}}
+	m, err := MergeReports(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, t0.Add(-time.Second), m.Start)
+	assert.Equal(t, 2*time.Second, m.Duration)
+	assert.Equal(t, 5, m.Submitted)
+	assert.Equal(t, 3, m.Committed)
+	assert.Equal(t, 2, m.Failures[FailureMVCC])
+	require.Len(t, m.Confirmations, 2)
+	assert.Equal(t, "b", m.Confirmations[0].TxID)
+
+	_, err = MergeReports(a, &Report{RunID: 2, CryptoMode: "HYBRID"})
+	assert.Error(t, err)
+}
+
+func TestCoordinator(t *testing.T) {
+	offsets := []time.Duration{0, 3 * time.Second, -2 * time.Second}
+	var agents []string
+	for i, offset := range offsets {
+		s, err := NewSimulator(1, 1000, int64(i))
+		require.NoError(t, err)
+		defer s.Close()
+		srv := httptest.NewServer((&Agent{Submitter: s, Events: s, CryptoMode: "HYBRID", Clock: skewedClock{offset}}).Handler())
+		defer srv.Close()
+		agents = append(agents, srv.URL)
+	}
+	c := &Coordinator{Agents: agents, Lead: 100 * time.Millisecond}
+
+	for i, agent := range agents {
+		skew, err := c.Skew(context.Background(), agent)
+		require.NoError(t, err)
+		assert.InDelta(t, offsets[i], skew.Offset, float64(skew.RTT/2+time.Millisecond))
+	}
+
+	before := time.Now()
+	r, agentReports, err := c.Run(context.Background(), Config{RunID: 1, LoadProfile: "HIGH", Rate: 3000,
+		Transactions: 30, Concurrency: 4, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	after := time.Now()
+	require.Len(t, agentReports, 3)
+	assert.Equal(t, 30, r.Submitted)
+	assert.Equal(t, "HYBRID", r.CryptoMode)
+	assert.Equal(t, "HIGH", r.LoadProfile)
+	require.Len(t, r.Confirmations, r.Committed)
+	// Corrected to the coordinator's clock, all agents ran in its window
+	for _, ar := range agentReports {
+		assert.Equal(t, 10, ar.Report.Submitted)
+		assert.WithinRange(t, ar.Report.Start, before, after)
+	}
+	for _, conf := range r.Confirmations {
+		assert.WithinRange(t, conf.Submitted, before, after)
+	}
+
+	_, _, err = (&Coordinator{Agents: []string{agents[0]}}).Run(context.Background(),
+		Config{Trace: Trace{{PayloadSize: 1}}, Concurrency: 1})
+	assert.Error(t, err)
+}
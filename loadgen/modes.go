@@ -0,0 +1,93 @@
+package loadgen
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// Crypto modes of the simulator, named as in the dataset
+const (
+	ModeECDSA      = "ECDSA"
+	ModeDilithium3 = "DILITHIUM3"
+	ModeHybrid     = "HYBRID"
+)
+
+// endorser signs proposal response digests and verifies its signatures as
+// a committer holding its certificate would
+type endorser interface {
+	sign(digest []byte) ([]byte, error)
+	verify(digest, signature []byte) bool
+	close()
+}
+
+func newEndorser(mode string) (endorser, error) {
+	switch mode {
+	case ModeECDSA:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsaEndorser{key}, nil
+	case ModeDilithium3:
+		signer, err := core.NewPQCSigner()
+		if err != nil {
+			return nil, err
+		}
+		return pqcEndorser{signer, signer.PublicKey()}, nil
+	case ModeHybrid:
+		key, err := core.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		return hybridEndorser{key, key.Public()}, nil
+	}
+	return nil, fmt.Errorf("unsupported crypto mode %q", mode)
+}
+
+type ecdsaEndorser struct{ key *ecdsa.PrivateKey }
+
+func (e ecdsaEndorser) sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, e.key, digest)
+}
+
+func (e ecdsaEndorser) verify(digest, signature []byte) bool {
+	return ecdsa.VerifyASN1(&e.key.PublicKey, digest, signature)
+}
+
+func (ecdsaEndorser) close() {}
+
+type pqcEndorser struct {
+	signer    *core.PQCSigner
+	publicKey []byte
+}
+
+func (e pqcEndorser) sign(digest []byte) ([]byte, error) {
+	return e.signer.Sign(digest)
+}
+
+func (e pqcEndorser) verify(digest, signature []byte) bool {
+	valid, err := core.VerifyPQC(e.publicKey, digest, signature)
+	return err == nil && valid
+}
+
+func (e pqcEndorser) close() { e.signer.Clean() }
+
+type hybridEndorser struct {
+	key    *core.PrivateKey
+	public *core.PublicKey
+}
+
+func (e hybridEndorser) sign(digest []byte) ([]byte, error) {
+	return e.key.Sign(digest)
+}
+
+func (e hybridEndorser) verify(digest, signature []byte) bool {
+	valid, err := e.public.Verify(digest, signature, core.PolicyHybridAND)
+	return err == nil && valid
+}
+
+func (e hybridEndorser) close() { e.key.Clean() }
@@ -0,0 +1,144 @@
+package loadgen
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of a run
+type Report struct {
+	RunID       int                  `json:"run_id"`
+	CryptoMode  string               `json:"crypto_mode"`
+	LoadProfile string               `json:"load_profile"`
+	Start       time.Time            `json:"start"`
+	Duration    time.Duration        `json:"duration"`
+	Submitted   int                  `json:"submitted"`
+	Committed   int                  `json:"committed"`
+	Failures    map[FailureClass]int `json:"failures"`
+	// Confirmations of committed transactions, when the run followed block
+	// events
+	Confirmations []Confirmation `json:"confirmations,omitempty"`
+}
+
+func newReport(cfg Config, start time.Time) *Report {
+	return &Report{
+		RunID:       cfg.RunID,
+		CryptoMode:  cfg.CryptoMode,
+		LoadProfile: cfg.LoadProfile,
+		Start:       start,
+		Failures:    map[FailureClass]int{},
+	}
+}
+
+func (r *Report) record(err error, c *Confirmation) {
+	r.Submitted++
+	if class := Classify(err); class != "" {
+		r.Failures[class]++
+	} else {
+		r.Committed++
+	}
+	if c != nil {
+		r.Confirmations = append(r.Confirmations, *c)
+	}
+}
+
+// ConfirmationLatencies returns the confirmation times in milliseconds
+func (r *Report) ConfirmationLatencies() []float64 {
+	ms := make([]float64, len(r.Confirmations))
+	for i, c := range r.Confirmations {
+		ms[i] = float64(c.Latency) / float64(time.Millisecond)
+	}
+	return ms
+}
+
+// Rate is the fraction of submitted transactions that failed with class
+func (r *Report) Rate(class FailureClass) float64 {
+	if r.Submitted == 0 {
+		return 0
+	}
+	return float64(r.Failures[class]) / float64(r.Submitted)
+}
+
+// SuccessRate is the fraction of submitted transactions committed as valid
+func (r *Report) SuccessRate() float64 {
+	if r.Submitted == 0 {
+		return 0
+	}
+	return float64(r.Committed) / float64(r.Submitted)
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "run %d: %d submitted, %d committed (%.2f%%) in %v",
+		r.RunID, r.Submitted, r.Committed, 100*r.SuccessRate(), r.Duration.Round(time.Millisecond))
+	for _, class := range FailureClasses {
+		if n := r.Failures[class]; n > 0 {
+			fmt.Fprintf(&b, ", %s %d (%.2f%%)", class, n, 100*r.Rate(class))
+		}
+	}
+	return b.String()
+}
+
+// failureColumns is the header written by WriteFailureCSV
+var failureColumns = []string{"run_id", "crypto_mode", "load_profile", "failure_class", "count", "rate"}
+
+// WriteFailureCSV writes one row per run and failure class, including
+// classes with no failures so that runs can be averaged
+func WriteFailureCSV(w io.Writer, reports ...*Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(failureColumns); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		for _, class := range FailureClasses {
+			err := cw.Write([]string{
+				strconv.Itoa(r.RunID),
+				r.CryptoMode,
+				r.LoadProfile,
+				string(class),
+				strconv.Itoa(r.Failures[class]),
+				strconv.FormatFloat(r.Rate(class), 'f', 6, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// confirmationColumns is the dataset header written by WriteConfirmationCSV
+var confirmationColumns = []string{
+	"timestamp_epoch_ms", "run_id", "cryptosystem", "load_profile", "operation_phase", "latency_e2e_ms",
+}
+
+// WriteConfirmationCSV writes one dataset row per confirmed transaction,
+// with its confirmation time as latency_e2e_ms
+func WriteConfirmationCSV(w io.Writer, reports ...*Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(confirmationColumns); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		for _, c := range r.Confirmations {
+			err := cw.Write([]string{
+				strconv.FormatInt(c.Submitted.UnixMilli(), 10),
+				strconv.Itoa(r.RunID),
+				r.CryptoMode,
+				r.LoadProfile,
+				"commit",
+				strconv.FormatFloat(float64(c.Latency)/float64(time.Millisecond), 'f', 3, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
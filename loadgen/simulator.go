@@ -0,0 +1,201 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// Simulator is an in-process network for load tests without a Fabric
+// deployment: every transaction reads and writes one of Keys keys, all
+// endorsers sign the result with keys of the simulator's crypto mode, and
+// a committer verifies
+// the signatures and the read version, then delivers the block committing
+// the transaction to Blocks subscribers. Contention on few keys produces
+// MVCC conflicts; the fault rates inject the other failure classes.
+type Simulator struct {
+	// Keys is the size of the key space, fewer keys conflict more often
+	Keys int
+	// MismatchRate is the fraction of transactions one endorser reads a
+	// stale version for
+	MismatchRate float64
+	// CorruptRate is the fraction of transactions with a corrupted
+	// endorsement signature
+	CorruptRate float64
+
+	mode      string
+	endorsers []endorser
+
+	mutex    sync.Mutex
+	rng      *rand.Rand
+	versions map[uint64]uint64
+	// height is the number of the next block
+	height      uint64
+	subscribers []*subscriber
+}
+
+const (
+	simulatorChannel = "loadgen"
+	// endorserTransaction is common.HeaderType ENDORSER_TRANSACTION
+	endorserTransaction = 3
+)
+
+// NewSimulator returns a simulator with endorsers endorsing peers, each with
+// its own hybrid key. Call Close when done.
+func NewSimulator(endorsers, keys int, seed int64) (*Simulator, error) {
+	return NewModeSimulator(ModeHybrid, endorsers, keys, seed)
+}
+
+// NewModeSimulator is NewSimulator with ECDSA, DILITHIUM3 or HYBRID keys
+func NewModeSimulator(mode string, endorsers, keys int, seed int64) (*Simulator, error) {
+	if endorsers < 1 || keys < 1 {
+		return nil, errors.New("the simulator needs at least one endorser and one key")
+	}
+	s := &Simulator{Keys: keys, mode: mode, rng: rand.New(rand.NewSource(seed)), versions: map[uint64]uint64{}}
+	for i := 0; i < endorsers; i++ {
+		e, err := newEndorser(mode)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.endorsers = append(s.endorsers, e)
+	}
+	return s, nil
+}
+
+// Mode returns the crypto mode of the endorser keys
+func (s *Simulator) Mode() string {
+	return s.mode
+}
+
+// Close releases the endorser keys
+func (s *Simulator) Close() {
+	for _, e := range s.endorsers {
+		e.close()
+	}
+}
+
+// Submit endorses, validates and commits tx
+func (s *Simulator) Submit(ctx context.Context, tx *Transaction) error {
+	h := sha256.Sum256([]byte(tx.ID))
+	key := binary.BigEndian.Uint64(h[:8]) % uint64(s.Keys)
+	s.mutex.Lock()
+	version := s.versions[key]
+	mismatch := s.rng.Float64() < s.MismatchRate
+	corrupt := s.rng.Float64() < s.CorruptRate
+	s.mutex.Unlock()
+
+	responses := make([][]byte, len(s.endorsers))
+	signatures := make([][]byte, len(s.endorsers))
+	for i, e := range s.endorsers {
+		read := version
+		if mismatch && i == 0 {
+			read++
+		}
+		responses[i] = proposalResponse(tx, key, read)
+		digest := sha256.Sum256(responses[i])
+		sig, err := e.sign(digest[:])
+		if err != nil {
+			return err
+		}
+		signatures[i] = sig
+	}
+	for _, r := range responses[1:] {
+		if !bytes.Equal(r, responses[0]) {
+			return ErrEndorsementMismatch
+		}
+	}
+	if corrupt {
+		signatures[0][len(signatures[0])-1] ^= 0xff
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(responses[0])
+	code := Valid
+	for i, sig := range signatures {
+		if !s.endorsers[i].verify(digest[:], sig) {
+			code = EndorsementPolicyFailure
+			break
+		}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if code == Valid && s.versions[key] != version {
+		code = MVCCReadConflict
+	}
+	if code == Valid {
+		s.versions[key]++
+	}
+	s.publish(tx, code)
+	if code != Valid {
+		return &ValidationError{TxID: tx.ID, Code: code}
+	}
+	return nil
+}
+
+// Blocks implements BlockEvents: every transaction that reaches validation
+// is committed in a block of its own
+func (s *Simulator) Blocks(ctx context.Context) (<-chan *fabproto.Block, error) {
+	sub := &subscriber{ctx: ctx, blocks: make(chan *fabproto.Block, 64)}
+	s.mutex.Lock()
+	s.subscribers = append(s.subscribers, sub)
+	s.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i, other := range s.subscribers {
+			if other == sub {
+				s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+				break
+			}
+		}
+		close(sub.blocks)
+	}()
+	return sub.blocks, nil
+}
+
+type subscriber struct {
+	ctx    context.Context
+	blocks chan *fabproto.Block
+}
+
+// publish delivers the block committing tx; s.mutex must be held
+func (s *Simulator) publish(tx *Transaction, code ValidationCode) {
+	if len(s.subscribers) == 0 {
+		return
+	}
+	channelHeader := &fabproto.ChannelHeader{Type: endorserTransaction, ChannelId: simulatorChannel, TxId: tx.ID}
+	payload := &fabproto.Payload{
+		Header: &fabproto.Header{ChannelHeader: channelHeader.Marshal()},
+		Data:   tx.Payload,
+	}
+	b := &fabproto.Block{
+		Header:   &fabproto.BlockHeader{Number: s.height},
+		Data:     [][]byte{(&fabproto.Envelope{Payload: payload.Marshal()}).Marshal()},
+		Metadata: fabproto.NewBlockMetadata(),
+	}
+	b.Metadata.Metadata[fabproto.BlockMetadataIndexTxFilter] = []byte{byte(code)}
+	s.height++
+	for _, sub := range s.subscribers {
+		select {
+		case sub.blocks <- b:
+		case <-sub.ctx.Done():
+		}
+	}
+}
+
+// proposalResponse is the read-write set an endorser returns for tx
+func proposalResponse(tx *Transaction, key, version uint64) []byte {
+	b := append([]byte(tx.ID), tx.Payload...)
+	b = binary.BigEndian.AppendUint64(b, key)
+	return binary.BigEndian.AppendUint64(b, version)
+}
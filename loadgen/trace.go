@@ -0,0 +1,146 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// TraceEntry is one arrival of a recorded workload
+type TraceEntry struct {
+	// Interarrival is the time since the previous arrival
+	Interarrival time.Duration
+	PayloadSize  int
+}
+
+// Trace is a recorded workload, replayed by setting Config.Trace
+type Trace []TraceEntry
+
+// traceColumns is the header of trace files
+var traceColumns = []string{"interarrival_us", "payload_size_bytes"}
+
+// Write writes t as CSV
+func (t Trace) Write(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(traceColumns); err != nil {
+		return err
+	}
+	for _, e := range t {
+		err := cw.Write([]string{
+			strconv.FormatInt(e.Interarrival.Microseconds(), 10),
+			strconv.Itoa(e.PayloadSize),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadTrace reads a trace written by Trace.Write
+func ReadTrace(r io.Reader) (Trace, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) != 2 || records[0][0] != traceColumns[0] || records[0][1] != traceColumns[1] {
+		return nil, errors.New("not a trace file")
+	}
+	t := make(Trace, 0, len(records)-1)
+	for i, record := range records[1:] {
+		us, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil || us < 0 {
+			return nil, fmt.Errorf("line %d: invalid interarrival %q", i+2, record[0])
+		}
+		size, err := strconv.Atoi(record[1])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("line %d: invalid payload size %q", i+2, record[1])
+		}
+		t = append(t, TraceEntry{Interarrival: time.Duration(us) * time.Microsecond, PayloadSize: size})
+	}
+	if len(t) == 0 {
+		return nil, errors.New("empty trace")
+	}
+	return t, nil
+}
+
+// Recorder is a Submitter recording the workload passing through it
+type Recorder struct {
+	Submitter Submitter
+	// Clock stamps transactions without an arrival time, clock.Real if nil
+	Clock clock.Clock
+
+	mutex    sync.Mutex
+	arrivals []arrival
+}
+
+type arrival struct {
+	at   time.Time
+	size int
+}
+
+// Submit records the arrival of tx and submits it
+func (r *Recorder) Submit(ctx context.Context, tx *Transaction) error {
+	at := tx.Arrival
+	if at.IsZero() {
+		at = clock.Or(r.Clock).Now()
+	}
+	r.mutex.Lock()
+	r.arrivals = append(r.arrivals, arrival{at, len(tx.Payload)})
+	r.mutex.Unlock()
+	return r.Submitter.Submit(ctx, tx)
+}
+
+// Trace returns the arrivals recorded so far
+func (r *Recorder) Trace() Trace {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return traceOf(append([]arrival(nil), r.arrivals...))
+}
+
+// TraceFromBlocks rebuilds the workload committed in blocks, e.g. fetched
+// from a production channel: transactions are ordered by the timestamp
+// their client set in the channel header and their payload size is the
+// size of their envelope. Transactions without timestamp are skipped.
+func TraceFromBlocks(blocks []*fabproto.Block) (Trace, error) {
+	var arrivals []arrival
+	for _, b := range blocks {
+		for _, raw := range b.Data {
+			ch, err := fabproto.EnvelopeChannelHeader(raw)
+			if err != nil {
+				return nil, err
+			}
+			if ch.Type != endorserTransaction || ch.Timestamp.IsZero() {
+				continue
+			}
+			arrivals = append(arrivals, arrival{ch.Timestamp, len(raw)})
+		}
+	}
+	if len(arrivals) == 0 {
+		return nil, errors.New("no timestamped transactions in the blocks")
+	}
+	return traceOf(arrivals), nil
+}
+
+// traceOf sorts arrivals and returns their interarrival times
+func traceOf(arrivals []arrival) Trace {
+	sort.SliceStable(arrivals, func(i, j int) bool { return arrivals[i].at.Before(arrivals[j].at) })
+	t := make(Trace, len(arrivals))
+	for i, a := range arrivals {
+		t[i].PayloadSize = a.size
+		if i > 0 {
+			t[i].Interarrival = a.at.Sub(arrivals[i-1].at)
+		}
+	}
+	return t
+}
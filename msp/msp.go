@@ -0,0 +1,247 @@
+// Package msp deserializes and loads hybrid MSP identities: serialized
+// identities carrying composite certificates, and the local MSP folders
+// written by qlca and qlcryptogen.
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+// ErrUnknownIssuer is returned for certificates not issued by a CA of their MSP
+var ErrUnknownIssuer = errors.New("certificate not issued by a trusted CA")
+
+// Identity is a deserialized identity with its verification key
+type Identity struct {
+	MSPID       string
+	Certificate *hybridx509.Certificate
+	Key         bccsp.Key
+}
+
+// Deserializer accepts serialized MSP identities (or bare PEM) holding a
+// composite certificate, or a classical one with AllowClassical, and imports
+// its key into CSP
+type Deserializer struct {
+	CSP bccsp.BCCSP
+	// CAs, when set, restricts identities to the listed MSP IDs and requires
+	// the certificate to be issued (classical and PQC signature) by one of
+	// the certificates of its MSP. Bare PEM identities are then rejected.
+	CAs map[string][]*hybridx509.Certificate
+	// Pins, when set, restricts identities to pinned keys during the
+	// bootstrap window
+	Pins *PinStore
+	// Revocation, when set with CAs, rejects certificates revoked by their
+	// issuer, e.g. a CRLCache
+	Revocation RevocationChecker
+	// AllowClassical accepts identities with a plain ECDSA certificate, for
+	// organizations that have not migrated yet. Their key is imported by the
+	// SW provider of CSP, which must verify them (hybrid.WithLegacyECDSA);
+	// a pin covers the certificate's SubjectPublicKeyInfo.
+	AllowClassical bool
+}
+
+// Kind reports whether the identity is hybrid or classical, for policies
+// and metrics
+func (id *Identity) Kind() hybrid.KeyKind {
+	return hybrid.KindOf(id.Key)
+}
+
+// DeserializeIdentity parses identity and imports its public key
+func (d *Deserializer) DeserializeIdentity(identity []byte) (*Identity, error) {
+	id := &Identity{}
+	certPEM := identity
+	if block, _ := pem.Decode(identity); block == nil {
+		sid, err := fabproto.UnmarshalSerializedIdentity(identity)
+		if err != nil {
+			return nil, err
+		}
+		id.MSPID, certPEM = sid.Mspid, sid.IdBytes
+	}
+	cert, err := hybridx509.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity certificate: %w", err)
+	}
+	if d.CAs != nil {
+		if err := d.validate(id.MSPID, cert); err != nil {
+			return nil, err
+		}
+	}
+	if !cert.IsComposite() && d.AllowClassical {
+		if d.Pins != nil {
+			if err := d.Pins.Check(id.MSPID, cert.RawSubjectPublicKeyInfo); err != nil {
+				return nil, err
+			}
+		}
+		if id.Key, err = d.CSP.KeyImport(cert.Certificate, &bccsp.X509PublicKeyImportOpts{Temporary: true}); err != nil {
+			return nil, err
+		}
+		id.Certificate = cert
+		return id, nil
+	}
+	composite, err := cert.CompositePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if d.Pins != nil {
+		if err := d.Pins.Check(id.MSPID, composite); err != nil {
+			return nil, err
+		}
+	}
+	id.Key, err = d.CSP.KeyImport(composite, &hybrid.HybridPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, err
+	}
+	id.Certificate = cert
+	return id, nil
+}
+
+func (d *Deserializer) validate(mspID string, cert *hybridx509.Certificate) error {
+	cas, ok := d.CAs[mspID]
+	if !ok {
+		return fmt.Errorf("unknown MSP %q", mspID)
+	}
+	for _, parent := range cas {
+		err := cert.CheckSignatureFrom(parent)
+		// The classical signature of a classical certificate is all there is
+		if err == nil || (d.AllowClassical && !cert.IsComposite() && errors.Is(err, hybridx509.ErrNotComposite)) {
+			if d.Revocation != nil {
+				return d.Revocation.CheckRevocation(cert, parent)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", mspID, ErrUnknownIssuer)
+}
+
+// SigningIdentity is a local identity able to sign
+type SigningIdentity struct {
+	Identity
+	csp        bccsp.BCCSP
+	signer     bccsp.Key
+	serialized []byte
+}
+
+// LoadSigningIdentity reads signcerts and keystore of the MSP folder dir
+func LoadSigningIdentity(csp bccsp.BCCSP, dir, mspID string) (*SigningIdentity, error) {
+	certs, err := readCertificates(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) != 1 {
+		return nil, fmt.Errorf("%s: expected one signing certificate, found %d", dir, len(certs))
+	}
+	ecdsaPEM, err := os.ReadFile(filepath.Join(dir, "keystore", ca.ECDSAKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	pqcPEM, err := os.ReadFile(filepath.Join(dir, "keystore", ca.PQCKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	priv, err := hybridx509.ParsePrivateKeyPEM(ecdsaPEM, pqcPEM)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := csp.KeyImport(priv.Raw(), &hybrid.HybridPrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import signing key: %w", err)
+	}
+	pub, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cert := certs[0]
+	certECDSA, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !certECDSA.Equal(&priv.ECDSA.PublicKey) || !bytes.Equal(cert.PQCPublicKey, priv.PQC.PublicKey()) {
+		return nil, fmt.Errorf("%s: keystore does not match the signing certificate", dir)
+	}
+
+	return &SigningIdentity{
+		Identity:   Identity{MSPID: mspID, Certificate: cert, Key: pub},
+		csp:        csp,
+		signer:     signer,
+		serialized: (&fabproto.SerializedIdentity{Mspid: mspID, IdBytes: hybridx509.EncodeCertificatePEM(cert.Raw)}).Marshal(),
+	}, nil
+}
+
+// Serialize returns the SerializedIdentity bytes of the identity
+func (s *SigningIdentity) Serialize() []byte {
+	return s.serialized
+}
+
+// Sign hashes msg with SHA-256 and signs the digest, as Fabric signing
+// identities do
+func (s *SigningIdentity) Sign(msg []byte) ([]byte, error) {
+	return s.SignHashed(msg, &bccsp.SHA256Opts{})
+}
+
+// SignHashed hashes msg with opts and signs the digest
+func (s *SigningIdentity) SignHashed(msg []byte, opts bccsp.HashOpts) ([]byte, error) {
+	digest, err := s.csp.Hash(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.csp.Sign(s.signer, digest, nil)
+}
+
+// Signer returns the key of the identity as a core.Signer, which signs
+// digests; sign messages with core.SignMessage
+func (s *SigningIdentity) Signer() (core.Signer, error) {
+	return hybrid.NewSigner(s.csp, s.signer, nil)
+}
+
+// LoadCACertificates returns the cacerts and intermediatecerts of the MSP
+// folder dir
+func LoadCACertificates(dir string) ([]*hybridx509.Certificate, error) {
+	roots, err := readCertificates(filepath.Join(dir, "cacerts"))
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%s: no CA certificates", dir)
+	}
+	intermediates, err := readCertificates(filepath.Join(dir, "intermediatecerts"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return append(roots, intermediates...), nil
+}
+
+// readCertificates parses every PEM file in dir, in name order
+func readCertificates(dir string) ([]*hybridx509.Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	var certs []*hybridx509.Certificate
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cert, err := hybridx509.ParseCertificatePEM(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
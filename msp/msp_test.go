@@ -0,0 +1,115 @@
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func TestSigningIdentityAndDeserializer(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	orderer, err := root.Issue(ca.Request{CommonName: "orderer0.example.com", OrganizationalUnit: "orderer"})
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(dir, root, orderer, ca.MSPOptions{}))
+
+	id, err := LoadSigningIdentity(csp, dir, "OrdererMSP")
+	require.NoError(t, err)
+	msg := []byte("block bytes")
+	sig, err := id.Sign(msg)
+	require.NoError(t, err)
+
+	cas, err := LoadCACertificates(dir)
+	require.NoError(t, err)
+	d := &Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"OrdererMSP": cas}}
+	remote, err := d.DeserializeIdentity(id.Serialize())
+	require.NoError(t, err)
+	assert.Equal(t, "OrdererMSP", remote.MSPID)
+
+	digest, err := csp.Hash(msg, &bccsp.SHA256Opts{})
+	require.NoError(t, err)
+	valid, err := csp.Verify(remote.Key, sig, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Same certificate claimed by an MSP that did not issue it
+	other, err := ca.NewRoot(pkix.Name{CommonName: "ca.other.com"}, 0)
+	require.NoError(t, err)
+	d.CAs = map[string][]*hybridx509.Certificate{"OrdererMSP": {other.Cert}}
+	_, err = d.DeserializeIdentity(id.Serialize())
+	assert.True(t, errors.Is(err, ErrUnknownIssuer))
+}
+
+func TestClassicalIdentity(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "ca.legacy.com"},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := hybridx509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "peer0.legacy.com"},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert.Certificate, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	serialized := (&fabproto.SerializedIdentity{Mspid: "LegacyMSP", IdBytes: hybridx509.EncodeCertificatePEM(der)}).Marshal()
+
+	csp, err := hybrid.New(hybrid.WithLegacyECDSA(true), hybrid.WithPolicy(hybrid.PolicyHybridOR))
+	require.NoError(t, err)
+	_, err = (&Deserializer{CSP: csp}).DeserializeIdentity(serialized)
+	assert.ErrorIs(t, err, hybridx509.ErrNotComposite)
+
+	d := &Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"LegacyMSP": {caCert}}, AllowClassical: true}
+	id, err := d.DeserializeIdentity(serialized)
+	require.NoError(t, err)
+	assert.Equal(t, hybrid.KindClassical, id.Kind())
+
+	digest := sha256.Sum256([]byte("legacy proposal"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	// Fabric signatures are low-S
+	if half := new(big.Int).Rsh(elliptic.P256().Params().N, 1); s.Cmp(half) > 0 {
+		s.Sub(elliptic.P256().Params().N, s)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(t, err)
+	valid, err := csp.Verify(id.Key, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Another MSP's classical CA did not issue it
+	other, err := ca.NewRoot(pkix.Name{CommonName: "ca.other.com"}, 0)
+	require.NoError(t, err)
+	d.CAs = map[string][]*hybridx509.Certificate{"LegacyMSP": {other.Cert}}
+	_, err = d.DeserializeIdentity(serialized)
+	assert.ErrorIs(t, err, ErrUnknownIssuer)
+}
@@ -0,0 +1,142 @@
+package msp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrPinMismatch is returned when a key is not pinned for its MSP during the
+// bootstrap window
+var ErrPinMismatch = errors.New("key does not match pinned fingerprints")
+
+// Fingerprint is the SHA-256 of a composite public key
+type Fingerprint [sha256.Size]byte
+
+// KeyFingerprint returns the fingerprint of a composite public key, as
+// returned by hybridx509.Certificate.CompositePublicKey
+func KeyFingerprint(composite []byte) Fingerprint {
+	return sha256.Sum256(composite)
+}
+
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+// ParseFingerprint parses a hex fingerprint
+func ParseFingerprint(s string) (Fingerprint, error) {
+	var f Fingerprint
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != len(f) {
+		return f, fmt.Errorf("invalid key fingerprint %q", s)
+	}
+	copy(f[:], raw)
+	return f, nil
+}
+
+// PinError reports an identity whose key is not pinned
+type PinError struct {
+	MSPID       string
+	Fingerprint Fingerprint
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("%s: key %s does not match pinned fingerprints", e.MSPID, e.Fingerprint)
+}
+
+// Is makes errors.Is(err, ErrPinMismatch) true
+func (e *PinError) Is(target error) bool {
+	return target == ErrPinMismatch
+}
+
+// PinStore holds the expected key fingerprints of each MSP. Until the end
+// of the bootstrap window only pinned keys are accepted, so a compromised CA
+// cannot introduce new identities while trust is being established.
+type PinStore struct {
+	mutex sync.RWMutex
+	pins  map[string]map[Fingerprint]bool
+	until time.Time
+	now   func() time.Time
+}
+
+// NewPinStore enforces pins until the given time
+func NewPinStore(until time.Time) *PinStore {
+	return &PinStore{pins: map[string]map[Fingerprint]bool{}, until: until, now: time.Now}
+}
+
+// Pin registers fp as an expected key of mspID
+func (s *PinStore) Pin(mspID string, fp Fingerprint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.pins[mspID] == nil {
+		s.pins[mspID] = map[Fingerprint]bool{}
+	}
+	s.pins[mspID][fp] = true
+}
+
+// Unpin removes fp from the expected keys of mspID
+func (s *PinStore) Unpin(mspID string, fp Fingerprint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.pins[mspID], fp)
+}
+
+// Enforcing reports whether the bootstrap window is still open
+func (s *PinStore) Enforcing() bool {
+	return s.now().Before(s.until)
+}
+
+// Check accepts any key once the bootstrap window is over; before, the key
+// must be pinned for mspID. MSPs without pins are rejected too.
+func (s *PinStore) Check(mspID string, composite []byte) error {
+	if !s.Enforcing() {
+		return nil
+	}
+	fp := KeyFingerprint(composite)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if !s.pins[mspID][fp] {
+		return &PinError{MSPID: mspID, Fingerprint: fp}
+	}
+	return nil
+}
+
+// pinFile is the YAML layout read by LoadPinStore
+type pinFile struct {
+	BootstrapUntil time.Time           `yaml:"bootstrap_until"`
+	Pins           map[string][]string `yaml:"pins"`
+}
+
+// LoadPinStore reads a YAML file of the form
+//
+//	bootstrap_until: 2026-12-31T00:00:00Z
+//	pins:
+//	  Org1MSP:
+//	    - <hex fingerprint>
+func LoadPinStore(path string) (*PinStore, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pin store: %w", err)
+	}
+	var f pinFile
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse pin store %s: %w", path, err)
+	}
+	s := NewPinStore(f.BootstrapUntil)
+	for mspID, fps := range f.Pins {
+		for _, hexFP := range fps {
+			fp, err := ParseFingerprint(hexFP)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", mspID, err)
+			}
+			s.Pin(mspID, fp)
+		}
+	}
+	return s, nil
+}
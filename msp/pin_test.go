@@ -0,0 +1,54 @@
+package msp
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func TestPinnedDeserializer(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	serialize := func(cn string) ([]byte, Fingerprint) {
+		id, err := root.Issue(ca.Request{CommonName: cn})
+		require.NoError(t, err)
+		composite, err := id.Cert.CompositePublicKey()
+		require.NoError(t, err)
+		return (&fabproto.SerializedIdentity{Mspid: "Org1MSP", IdBytes: hybridx509.EncodeCertificatePEM(id.Cert.Raw)}).Marshal(), KeyFingerprint(composite)
+	}
+	pinned, pinnedFP := serialize("peer0.org1.example.com")
+	rogue, rogueFP := serialize("rogue.org1.example.com")
+
+	path := filepath.Join(t.TempDir(), "pins.yaml")
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("bootstrap_until: %s\npins:\n  Org1MSP:\n    - %s\n", until, pinnedFP)), 0o644))
+	pins, err := LoadPinStore(path)
+	require.NoError(t, err)
+
+	d := &Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"Org1MSP": {root.Cert}}, Pins: pins}
+	_, err = d.DeserializeIdentity(pinned)
+	require.NoError(t, err)
+	_, err = d.DeserializeIdentity(rogue)
+	var pinErr *PinError
+	require.True(t, errors.As(err, &pinErr))
+	assert.Equal(t, rogueFP, pinErr.Fingerprint)
+	assert.True(t, errors.Is(err, ErrPinMismatch))
+
+	// Once the window closes the CA alone decides
+	pins.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = d.DeserializeIdentity(rogue)
+	require.NoError(t, err)
+}
@@ -0,0 +1,192 @@
+package msp
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+var (
+	// ErrRevoked is returned for certificates listed in the CRL of their issuer
+	ErrRevoked = errors.New("certificate revoked")
+	// ErrCRLUnavailable is returned when none of the distribution points of a
+	// certificate served a valid CRL
+	ErrCRLUnavailable = errors.New("no valid CRL available")
+)
+
+// DefaultCRLRefresh is how often a cached CRL is fetched again, even when
+// its next update is later
+const DefaultCRLRefresh = time.Hour
+
+// maxCRLSize bounds the CRLs HTTPCRLFetcher reads
+const maxCRLSize = 16 << 20
+
+// RevocationChecker rejects revoked certificates, given the CA certificate
+// that issued them
+type RevocationChecker interface {
+	CheckRevocation(cert, issuer *hybridx509.Certificate) error
+}
+
+// CRLFetcher retrieves a DER or PEM CRL from a distribution point
+type CRLFetcher func(url string) ([]byte, error)
+
+// HTTPCRLFetcher fetches http and https distribution points with client,
+// http.DefaultClient if nil, and reads file URLs and bare paths from disk
+func HTTPCRLFetcher(client *http.Client) CRLFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(point string) ([]byte, error) {
+		u, err := url.Parse(point)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case "http", "https":
+		case "file":
+			return os.ReadFile(u.Path)
+		case "":
+			return os.ReadFile(point)
+		default:
+			return nil, fmt.Errorf("unsupported CRL distribution point scheme %q", u.Scheme)
+		}
+		resp, err := client.Get(point)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxCRLSize))
+	}
+}
+
+// cachedCRL is a verified CRL reduced to what CheckRevocation needs
+type cachedCRL struct {
+	issuer     []byte
+	number     int64
+	fetched    time.Time
+	nextUpdate time.Time
+	revoked    map[string]time.Time
+}
+
+// CRLCache is a RevocationChecker reading the CRLs at the distribution points
+// of the certificates, as written by qlca. Each CRL must be signed by the
+// issuer with both keys. It is cached until its next update and fetched again
+// every refresh period; when that fails, the cached CRL is used until its
+// next update. Certificates without distribution points are not checked.
+// CRLCache is safe for concurrent use.
+type CRLCache struct {
+	fetch   CRLFetcher
+	refresh time.Duration
+	now     func() time.Time
+
+	mutex sync.Mutex
+	crls  map[string]*cachedCRL
+}
+
+// NewCRLCache returns a cache fetching CRLs with fetch, HTTPCRLFetcher(nil)
+// if nil, every refresh, DefaultCRLRefresh if zero
+func NewCRLCache(fetch CRLFetcher, refresh time.Duration) *CRLCache {
+	if fetch == nil {
+		fetch = HTTPCRLFetcher(nil)
+	}
+	if refresh <= 0 {
+		refresh = DefaultCRLRefresh
+	}
+	return &CRLCache{fetch: fetch, refresh: refresh, now: time.Now, crls: map[string]*cachedCRL{}}
+}
+
+// CheckRevocation implements RevocationChecker. Distribution points are tried
+// in order; the first valid CRL decides.
+func (c *CRLCache) CheckRevocation(cert, issuer *hybridx509.Certificate) error {
+	if len(cert.CRLDistributionPoints) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, point := range cert.CRLDistributionPoints {
+		crl, err := c.get(point, issuer)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", point, err))
+			continue
+		}
+		if at, ok := crl.revoked[cert.SerialNumber.Text(16)]; ok {
+			return fmt.Errorf("%s: %w on %s", cert.Subject.CommonName, ErrRevoked, at.Format(time.RFC3339))
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: %w: %w", cert.Subject.CommonName, ErrCRLUnavailable, errors.Join(errs...))
+}
+
+// get returns the CRL of issuer at point, from the cache if fresh enough
+func (c *CRLCache) get(point string, issuer *hybridx509.Certificate) (*cachedCRL, error) {
+	now := c.now()
+	c.mutex.Lock()
+	cached := c.crls[point]
+	c.mutex.Unlock()
+	if cached != nil && !bytes.Equal(cached.issuer, issuer.RawSubject) {
+		return nil, errors.New("CRL of another issuer")
+	}
+	if cached != nil && now.Sub(cached.fetched) < c.refresh && now.Before(cached.nextUpdate) {
+		return cached, nil
+	}
+
+	crl, err := c.load(point, issuer, now)
+	if err == nil && cached != nil && crl.number < cached.number {
+		err = fmt.Errorf("CRL number %d is older than the cached %d", crl.number, cached.number)
+	}
+	if err != nil {
+		if cached != nil && now.Before(cached.nextUpdate) {
+			return cached, nil
+		}
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.crls[point] = crl
+	c.mutex.Unlock()
+	return crl, nil
+}
+
+// load fetches and verifies the CRL at point
+func (c *CRLCache) load(point string, issuer *hybridx509.Certificate, now time.Time) (*cachedCRL, error) {
+	raw, err := c.fetch(point)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(raw); block != nil && block.Type == "X509 CRL" {
+		raw = block.Bytes
+	}
+	crl, err := hybridx509.ParseRevocationList(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, err
+	}
+	if !now.Before(crl.NextUpdate) {
+		return nil, fmt.Errorf("CRL expired on %s", crl.NextUpdate.Format(time.RFC3339))
+	}
+	cached := &cachedCRL{
+		issuer:     issuer.RawSubject,
+		fetched:    now,
+		nextUpdate: crl.NextUpdate,
+		revoked:    make(map[string]time.Time, len(crl.RevokedCertificateEntries)),
+	}
+	if crl.Number != nil && crl.Number.IsInt64() {
+		cached.number = crl.Number.Int64()
+	}
+	for _, entry := range crl.RevokedCertificateEntries {
+		cached.revoked[entry.SerialNumber.Text(16)] = entry.RevocationTime
+	}
+	return cached, nil
+}
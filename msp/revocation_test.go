@@ -0,0 +1,139 @@
+package msp
+
+import (
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func TestCRLCache(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	var crl atomic.Pointer[[]byte]
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if crl.Load() == nil {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: *crl.Load()}))
+	}))
+	defer server.Close()
+	publish := func(nextUpdate time.Duration) {
+		der, err := root.CRL(time.Now().Add(nextUpdate))
+		require.NoError(t, err)
+		crl.Store(&der)
+	}
+
+	peer, err := root.Issue(ca.Request{CommonName: "peer0.org1.example.com", CRLDistributionPoints: []string{server.URL + "/crl.pem"}})
+	require.NoError(t, err)
+	client, err := root.Issue(ca.Request{CommonName: "user1.org1.example.com", CRLDistributionPoints: []string{server.URL + "/crl.pem"}})
+	require.NoError(t, err)
+
+	cache := NewCRLCache(nil, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	// Fail closed while no CRL was ever fetched
+	assert.ErrorIs(t, cache.CheckRevocation(peer.Cert, root.Cert), ErrCRLUnavailable)
+
+	publish(24 * time.Hour)
+	require.NoError(t, cache.CheckRevocation(peer.Cert, root.Cert))
+	require.NoError(t, cache.CheckRevocation(client.Cert, root.Cert))
+	fetched := fetches.Load()
+
+	// Revocations are seen after the refresh period, not before
+	root.Revoke(peer.Cert.SerialNumber, now)
+	publish(24 * time.Hour)
+	require.NoError(t, cache.CheckRevocation(peer.Cert, root.Cert))
+	assert.Equal(t, fetched, fetches.Load(), "served from the cache")
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, cache.CheckRevocation(peer.Cert, root.Cert), ErrRevoked)
+	require.NoError(t, cache.CheckRevocation(client.Cert, root.Cert))
+
+	// The cached CRL outlives an outage, until its next update
+	crl.Store(nil)
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, cache.CheckRevocation(peer.Cert, root.Cert), ErrRevoked)
+	now = now.Add(25 * time.Hour)
+	assert.ErrorIs(t, cache.CheckRevocation(client.Cert, root.Cert), ErrCRLUnavailable)
+
+	// CRLs of another CA are rejected
+	other, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	assert.ErrorIs(t, NewCRLCache(nil, 0).CheckRevocation(client.Cert, other.Cert), ErrCRLUnavailable)
+
+	// Certificates without distribution points are not checked
+	plain, err := root.Issue(ca.Request{CommonName: "peer1.org1.example.com"})
+	require.NoError(t, err)
+	assert.NoError(t, cache.CheckRevocation(plain.Cert, root.Cert))
+}
+
+func TestCRLCacheFileAndRollback(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "crl.der")
+	peer, err := root.Issue(ca.Request{CommonName: "peer0.org1.example.com", CRLDistributionPoints: []string{"file://" + path}})
+	require.NoError(t, err)
+
+	old, err := root.CRL(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	root.Revoke(peer.Cert.SerialNumber, time.Now())
+	revoked, err := root.CRL(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	cache := NewCRLCache(HTTPCRLFetcher(nil), time.Nanosecond)
+	require.NoError(t, os.WriteFile(path, revoked, 0o644))
+	assert.ErrorIs(t, cache.CheckRevocation(peer.Cert, root.Cert), ErrRevoked)
+
+	// Replaying an older CRL does not unrevoke the certificate
+	require.NoError(t, os.WriteFile(path, old, 0o644))
+	assert.ErrorIs(t, cache.CheckRevocation(peer.Cert, root.Cert), ErrRevoked)
+}
+
+func TestDeserializerRevocation(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	peer, err := root.Issue(ca.Request{CommonName: "peer0.org1.example.com", CRLDistributionPoints: []string{"crl.pem"}})
+	require.NoError(t, err)
+
+	revoked := errors.New("revoked")
+	d := &Deserializer{
+		CSP:        csp,
+		CAs:        map[string][]*hybridx509.Certificate{"Org1MSP": {root.Cert}},
+		Revocation: checkerFunc(func(cert, issuer *hybridx509.Certificate) error { return revoked }),
+	}
+	serialized := (&fabproto.SerializedIdentity{Mspid: "Org1MSP", IdBytes: hybridx509.EncodeCertificatePEM(peer.Cert.Raw)}).Marshal()
+	_, err = d.DeserializeIdentity(serialized)
+	assert.ErrorIs(t, err, revoked)
+
+	d.Revocation = checkerFunc(func(cert, issuer *hybridx509.Certificate) error {
+		assert.Equal(t, root.Cert.Raw, issuer.Raw)
+		return nil
+	})
+	_, err = d.DeserializeIdentity(serialized)
+	assert.NoError(t, err)
+}
+
+type checkerFunc func(cert, issuer *hybridx509.Certificate) error
+
+func (f checkerFunc) CheckRevocation(cert, issuer *hybridx509.Certificate) error {
+	return f(cert, issuer)
+}
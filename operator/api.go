@@ -0,0 +1,186 @@
+// Package operator implements qloperator, a Kubernetes operator managing
+// hybrid keys of cluster-run peers. It reconciles HybridKey resources: keys
+// are generated by the signing daemon, which keeps a copy, and their private
+// material is stored in a Secret encrypted to the hybrid KEM key of the
+// workload, so only that workload can read it. Keys are rotated on a
+// schedule and the outcome is reported in the resource status.
+//
+// The operator talks to the API server over plain REST with the service
+// account of its pod and polls instead of watching, so it needs no client
+// libraries.
+package operator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// HybridKey resource coordinates, see deploy/kubernetes/qloperator/crd.yaml
+const (
+	Group    = "quantumledger.io"
+	Version  = "v1alpha1"
+	Kind     = "HybridKey"
+	Resource = "hybridkeys"
+)
+
+// SecretType marks the Secrets written by the operator
+const SecretType = Group + "/hybrid-key"
+
+// Keys of the Secret data
+const (
+	SecretSKI        = "ski"
+	SecretPublicKey  = "public_key.json"
+	SecretWrappedKey = "wrapped_key"
+)
+
+// ObjectMeta is the subset of Kubernetes object metadata the operator uses
+type ObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	UID             string            `json:"uid,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Generation      int64             `json:"generation,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty"`
+}
+
+// OwnerReference makes Kubernetes delete a Secret with its HybridKey
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller"`
+}
+
+// HybridKey is a key managed by the operator
+type HybridKey struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   ObjectMeta      `json:"metadata"`
+	Spec       HybridKeySpec   `json:"spec"`
+	Status     HybridKeyStatus `json:"status,omitempty"`
+}
+
+// HybridKeySpec is the desired key
+type HybridKeySpec struct {
+	// KeystoreNamespace is the daemon keystore namespace of the key, e.g.
+	// the MSP ID
+	KeystoreNamespace string `json:"keystoreNamespace"`
+	// SecretName is the Secret receiving the key, in the resource's
+	// namespace
+	SecretName string `json:"secretName"`
+	// RecipientPublicKey is the base64 marshaled hybrid KEM public key of
+	// the workload; changing it issues a new key
+	RecipientPublicKey string `json:"recipientPublicKey"`
+	// RotationPeriod is a Go duration, e.g. 720h; empty never rotates
+	RotationPeriod string `json:"rotationPeriod,omitempty"`
+}
+
+// HybridKeyStatus is the observed state of the key
+type HybridKeyStatus struct {
+	SKI string `json:"ski,omitempty"`
+	// PreviousSKI is the key replaced by the last rotation, still held by
+	// the daemon so signatures made with it can be verified
+	PreviousSKI        string      `json:"previousSKI,omitempty"`
+	RotatedAt          *time.Time  `json:"rotatedAt,omitempty"`
+	NextRotation       *time.Time  `json:"nextRotation,omitempty"`
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+// ConditionReady reports whether the Secret holds the desired key
+const ConditionReady = "Ready"
+
+// Reasons of the Ready condition
+const (
+	ReasonKeyIssued   = "KeyIssued"
+	ReasonKeyRotated  = "KeyRotated"
+	ReasonInvalidSpec = "InvalidSpec"
+	ReasonDaemonError = "DaemonError"
+	ReasonSecretError = "SecretError"
+)
+
+// Condition follows the Kubernetes condition conventions
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// setCondition sets condition t, keeping its transition time if the status
+// did not change
+func (s *HybridKeyStatus) setCondition(t string, ok bool, reason, message string, now time.Time) {
+	status := "False"
+	if ok {
+		status = "True"
+	}
+	c := Condition{Type: t, Status: status, Reason: reason, Message: message, LastTransitionTime: now}
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			if s.Conditions[i].Status == status {
+				c.LastTransitionTime = s.Conditions[i].LastTransitionTime
+			}
+			s.Conditions[i] = c
+			return
+		}
+	}
+	s.Conditions = append(s.Conditions, c)
+}
+
+// Condition returns condition t, if set
+func (s *HybridKeyStatus) Condition(t string) (Condition, bool) {
+	for _, c := range s.Conditions {
+		if c.Type == t {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// errInvalidSpec marks spec errors, which retrying cannot fix
+var errInvalidSpec = errors.New("invalid spec")
+
+// parsedSpec is a validated spec
+type parsedSpec struct {
+	recipient []byte
+	period    time.Duration
+}
+
+// parse validates the spec
+func (s *HybridKeySpec) parse() (parsedSpec, error) {
+	var p parsedSpec
+	if err := hybrid.ValidateNamespace(s.KeystoreNamespace); err != nil {
+		return p, fmt.Errorf("%w: %v", errInvalidSpec, err)
+	}
+	if s.SecretName == "" {
+		return p, fmt.Errorf("%w: secretName is required", errInvalidSpec)
+	}
+	recipient, err := base64.StdEncoding.DecodeString(s.RecipientPublicKey)
+	if err != nil || len(recipient) == 0 {
+		return p, fmt.Errorf("%w: recipientPublicKey must be base64", errInvalidSpec)
+	}
+	p.recipient = recipient
+	if s.RotationPeriod != "" {
+		if p.period, err = time.ParseDuration(s.RotationPeriod); err != nil || p.period < time.Minute {
+			return p, fmt.Errorf("%w: rotationPeriod %q must be a duration of at least 1m", errInvalidSpec, s.RotationPeriod)
+		}
+	}
+	return p, nil
+}
+
+// Secret is the subset of a Kubernetes Secret the operator uses
+type Secret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+}
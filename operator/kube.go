@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned for missing objects
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when an object changed since it was read
+var ErrConflict = errors.New("conflict")
+
+// Kube is the part of the Kubernetes API the operator uses
+type Kube interface {
+	// ListHybridKeys lists the keys of namespace, of all namespaces if empty
+	ListHybridKeys(ctx context.Context, namespace string) ([]HybridKey, error)
+	UpdateHybridKeyStatus(ctx context.Context, hk *HybridKey) error
+	GetSecret(ctx context.Context, namespace, name string) (*Secret, error)
+	// PutSecret creates the Secret or, if it has a resource version,
+	// replaces it
+	PutSecret(ctx context.Context, s *Secret) error
+}
+
+// serviceAccountDir holds the credentials of the pod's service account
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// RESTClient is a Kube calling the API server over HTTPS
+type RESTClient struct {
+	// Host is the API server base URL
+	Host string
+	HTTP *http.Client
+	// TokenFile is read on each request, bound tokens are rotated by the
+	// kubelet
+	TokenFile string
+}
+
+// InClusterClient returns a client with the pod's service account
+func InClusterClient() (*RESTClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a cluster: KUBERNETES_SERVICE_HOST is not set")
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates in the service account CA")
+	}
+	return &RESTClient{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		HTTP:      &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}},
+		TokenFile: serviceAccountDir + "/token",
+	}, nil
+}
+
+// hybridKeyList is the list response of HybridKeys
+type hybridKeyList struct {
+	Items []HybridKey `json:"items"`
+}
+
+func hybridKeysPath(namespace string) string {
+	if namespace == "" {
+		return fmt.Sprintf("/apis/%s/%s/%s", Group, Version, Resource)
+	}
+	return fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", Group, Version, namespace, Resource)
+}
+
+func (c *RESTClient) ListHybridKeys(ctx context.Context, namespace string) ([]HybridKey, error) {
+	var list hybridKeyList
+	if err := c.do(ctx, "GET", hybridKeysPath(namespace), nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// UpdateHybridKeyStatus replaces the status subresource; it fails with
+// ErrConflict if the resource changed since it was listed
+func (c *RESTClient) UpdateHybridKeyStatus(ctx context.Context, hk *HybridKey) error {
+	path := hybridKeysPath(hk.Metadata.Namespace) + "/" + hk.Metadata.Name + "/status"
+	return c.do(ctx, "PUT", path, hk, hk)
+}
+
+func (c *RESTClient) GetSecret(ctx context.Context, namespace, name string) (*Secret, error) {
+	s := &Secret{}
+	if err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name), nil, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *RESTClient) PutSecret(ctx context.Context, s *Secret) error {
+	path := fmt.Sprintf("/api/v1/namespaces/%s/secrets", s.Metadata.Namespace)
+	if s.Metadata.ResourceVersion == "" {
+		return c.do(ctx, "POST", path, s, s)
+	}
+	return c.do(ctx, "PUT", path+"/"+s.Metadata.Name, s, s)
+}
+
+func (c *RESTClient) do(ctx context.Context, method, path string, body, resp interface{}) error {
+	var r io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.Host, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.TokenFile != "" {
+		token, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s %s: %w", method, path, ErrNotFound)
+	case res.StatusCode == http.StatusConflict:
+		return fmt.Errorf("%s %s: %w", method, path, ErrConflict)
+	case res.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, res.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
@@ -0,0 +1,234 @@
+package operator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/clock"
+	"github.com/yourusername/quantum-ledger/signd"
+)
+
+// fakeKube keeps objects in memory
+type fakeKube struct {
+	keys          []HybridKey
+	secrets       map[string]*Secret
+	statusUpdates int
+	version       int
+}
+
+func newFakeKube(keys ...HybridKey) *fakeKube {
+	return &fakeKube{keys: keys, secrets: map[string]*Secret{}}
+}
+
+func (k *fakeKube) ListHybridKeys(_ context.Context, _ string) ([]HybridKey, error) {
+	out := make([]HybridKey, len(k.keys))
+	copy(out, k.keys)
+	return out, nil
+}
+
+func (k *fakeKube) UpdateHybridKeyStatus(_ context.Context, hk *HybridKey) error {
+	k.statusUpdates++
+	for i := range k.keys {
+		if k.keys[i].Metadata.UID == hk.Metadata.UID {
+			k.keys[i].Status = hk.Status
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (k *fakeKube) GetSecret(_ context.Context, namespace, name string) (*Secret, error) {
+	s, ok := k.secrets[namespace+"/"+name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *s
+	return &c, nil
+}
+
+func (k *fakeKube) PutSecret(_ context.Context, s *Secret) error {
+	k.version++
+	s.Metadata.ResourceVersion = fmt.Sprint(k.version)
+	c := *s
+	k.secrets[s.Metadata.Namespace+"/"+s.Metadata.Name] = &c
+	return nil
+}
+
+// fakeIssuer returns sequential keys
+type fakeIssuer struct {
+	n   int
+	err error
+}
+
+func (f *fakeIssuer) GenerateWrappedKey(_ context.Context, _ string, recipient []byte) (*signd.WrappedKeyResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.n++
+	return &signd.WrappedKeyResponse{
+		SKI:        fmt.Sprintf("ski-%d", f.n),
+		WrappedKey: append([]byte("wrapped-for-"), recipient...),
+	}, nil
+}
+
+func testKey(period string) HybridKey {
+	return HybridKey{
+		APIVersion: Group + "/" + Version,
+		Kind:       Kind,
+		Metadata:   ObjectMeta{Name: "peer0", Namespace: "org1", UID: "uid-1", Generation: 1},
+		Spec: HybridKeySpec{
+			KeystoreNamespace:  "Org1MSP",
+			SecretName:         "peer0-key",
+			RecipientPublicKey: base64.StdEncoding.EncodeToString([]byte("kem")),
+			RotationPeriod:     period,
+		},
+	}
+}
+
+func ready(t *testing.T, hk HybridKey) Condition {
+	t.Helper()
+	c, ok := hk.Status.Condition(ConditionReady)
+	require.True(t, ok)
+	return c
+}
+
+func TestReconcileRotation(t *testing.T) {
+	kube := newFakeKube(testKey("1h"))
+	issuer := &fakeIssuer{}
+	vc := clock.NewVirtual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	op := &Operator{Kube: kube, Issuer: issuer, Clock: vc}
+	ctx := context.Background()
+
+	require.NoError(t, op.ReconcileAll(ctx))
+	hk := kube.keys[0]
+	assert.Equal(t, "ski-1", hk.Status.SKI)
+	assert.Equal(t, ReasonKeyIssued, ready(t, hk).Reason)
+	assert.Equal(t, vc.Now().Add(time.Hour), *hk.Status.NextRotation)
+	s := kube.secrets["org1/peer0-key"]
+	require.NotNil(t, s)
+	assert.Equal(t, "ski-1", string(s.Data[SecretSKI]))
+	assert.Equal(t, "wrapped-for-kem", string(s.Data[SecretWrappedKey]))
+	assert.True(t, ownedBy(s, &hk))
+
+	// Nothing to do before the period elapses, and nothing is written
+	vc.Advance(30 * time.Minute)
+	require.NoError(t, op.ReconcileAll(ctx))
+	assert.Equal(t, 1, issuer.n)
+	assert.Equal(t, 1, kube.statusUpdates)
+
+	vc.Advance(30 * time.Minute)
+	require.NoError(t, op.ReconcileAll(ctx))
+	hk = kube.keys[0]
+	assert.Equal(t, "ski-2", hk.Status.SKI)
+	assert.Equal(t, "ski-1", hk.Status.PreviousSKI)
+	assert.Equal(t, ReasonKeyRotated, ready(t, hk).Reason)
+	assert.Equal(t, "ski-2", string(kube.secrets["org1/peer0-key"].Data[SecretSKI]))
+	assert.Equal(t, "2", kube.secrets["org1/peer0-key"].Metadata.ResourceVersion)
+}
+
+func TestReconcileReissue(t *testing.T) {
+	kube := newFakeKube(testKey(""))
+	issuer := &fakeIssuer{}
+	op := &Operator{Kube: kube, Issuer: issuer, Clock: clock.NewVirtual(time.Unix(0, 0))}
+	ctx := context.Background()
+	require.NoError(t, op.ReconcileAll(ctx))
+	assert.Nil(t, kube.keys[0].Status.NextRotation)
+
+	// A deleted Secret is written again with a new key
+	delete(kube.secrets, "org1/peer0-key")
+	require.NoError(t, op.ReconcileAll(ctx))
+	assert.Equal(t, "ski-2", kube.keys[0].Status.SKI)
+
+	// So is a changed spec
+	kube.keys[0].Spec.RecipientPublicKey = base64.StdEncoding.EncodeToString([]byte("kem2"))
+	kube.keys[0].Metadata.Generation = 2
+	require.NoError(t, op.ReconcileAll(ctx))
+	hk := kube.keys[0]
+	assert.Equal(t, "ski-3", hk.Status.SKI)
+	assert.Equal(t, int64(2), hk.Status.ObservedGeneration)
+	assert.Equal(t, "wrapped-for-kem2", string(kube.secrets["org1/peer0-key"].Data[SecretWrappedKey]))
+}
+
+func TestReconcileErrors(t *testing.T) {
+	ctx := context.Background()
+	vc := clock.NewVirtual(time.Unix(0, 0))
+
+	bad := testKey("1s")
+	kube := newFakeKube(bad)
+	op := &Operator{Kube: kube, Issuer: &fakeIssuer{}, Clock: vc}
+	hk := &kube.keys[0]
+	err := op.Reconcile(ctx, hk)
+	assert.ErrorIs(t, err, errInvalidSpec)
+	c := ready(t, kube.keys[0])
+	assert.Equal(t, "False", c.Status)
+	assert.Equal(t, ReasonInvalidSpec, c.Reason)
+
+	// A Secret of someone else is never overwritten
+	kube = newFakeKube(testKey(""))
+	kube.secrets["org1/peer0-key"] = &Secret{Metadata: ObjectMeta{Name: "peer0-key", Namespace: "org1"}, Data: map[string][]byte{"tls.key": []byte("x")}}
+	issuer := &fakeIssuer{}
+	op = &Operator{Kube: kube, Issuer: issuer, Clock: vc}
+	require.Error(t, op.Reconcile(ctx, &kube.keys[0]))
+	assert.Equal(t, ReasonSecretError, ready(t, kube.keys[0]).Reason)
+	assert.Equal(t, 0, issuer.n)
+	assert.Equal(t, "x", string(kube.secrets["org1/peer0-key"].Data["tls.key"]))
+
+	kube = newFakeKube(testKey(""))
+	op = &Operator{Kube: kube, Issuer: &fakeIssuer{err: errors.New("daemon down")}, Clock: vc}
+	require.Error(t, op.Reconcile(ctx, &kube.keys[0]))
+	assert.Equal(t, ReasonDaemonError, ready(t, kube.keys[0]).Reason)
+	assert.Empty(t, kube.secrets)
+
+	// Recovery flips Ready back
+	op.Issuer = &fakeIssuer{}
+	require.NoError(t, op.Reconcile(ctx, &kube.keys[0]))
+	c = ready(t, kube.keys[0])
+	assert.Equal(t, "True", c.Status)
+	assert.Equal(t, ReasonKeyIssued, c.Reason)
+}
+
+func TestRESTClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis/quantumledger.io/v1alpha1/namespaces/org1/hybridkeys", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(hybridKeyList{Items: []HybridKey{testKey("")}})
+	})
+	mux.HandleFunc("POST /api/v1/namespaces/org1/secrets", func(w http.ResponseWriter, r *http.Request) {
+		var s Secret
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&s))
+		s.Metadata.ResourceVersion = "7"
+		json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("PUT /api/v1/namespaces/org1/secrets/peer0-key", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "stale", http.StatusConflict)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	tokenFile := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenFile, []byte("tok\n"), 0o600))
+	c := &RESTClient{Host: ts.URL, TokenFile: tokenFile}
+	ctx := context.Background()
+
+	keys, err := c.ListHybridKeys(ctx, "org1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "peer0-key", keys[0].Spec.SecretName)
+
+	_, err = c.GetSecret(ctx, "org1", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	s := newSecret(&keys[0])
+	require.NoError(t, c.PutSecret(ctx, s))
+	assert.Equal(t, "7", s.Metadata.ResourceVersion)
+	assert.ErrorIs(t, c.PutSecret(ctx, s), ErrConflict)
+}
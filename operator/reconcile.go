@@ -0,0 +1,217 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+	"github.com/yourusername/quantum-ledger/signd"
+)
+
+// KeyIssuer generates keys with wrapped private material, *signd.Client
+type KeyIssuer interface {
+	GenerateWrappedKey(ctx context.Context, ns string, recipient []byte) (*signd.WrappedKeyResponse, error)
+}
+
+// Operator reconciles the HybridKeys of a cluster
+type Operator struct {
+	Kube   Kube
+	Issuer KeyIssuer
+	// Namespace restricts the operator to one namespace, all if empty
+	Namespace string
+	// Resync is the polling period, and so the rotation granularity
+	Resync time.Duration
+	Clock  clock.Clock
+	// Log receives one line per reconciliation error, discarded if nil
+	Log io.Writer
+}
+
+// Run reconciles every Resync until ctx is done
+func (o *Operator) Run(ctx context.Context) error {
+	c := clock.Or(o.Clock)
+	for {
+		if err := o.ReconcileAll(ctx); err != nil && ctx.Err() == nil {
+			o.logf("list: %v", err)
+		}
+		t := c.NewTimer(o.Resync)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C():
+		}
+	}
+}
+
+// ReconcileAll reconciles every HybridKey once. Failures of single keys are
+// logged and recorded in their status; only listing errors are returned.
+func (o *Operator) ReconcileAll(ctx context.Context) error {
+	keys, err := o.Kube.ListHybridKeys(ctx, o.Namespace)
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		hk := &keys[i]
+		if err := o.Reconcile(ctx, hk); err != nil {
+			o.logf("%s/%s: %v", hk.Metadata.Namespace, hk.Metadata.Name, err)
+		}
+	}
+	return nil
+}
+
+func (o *Operator) logf(format string, args ...interface{}) {
+	if o.Log != nil {
+		fmt.Fprintf(o.Log, format+"\n", args...)
+	}
+}
+
+// Reconcile brings the Secret of hk to its spec and updates the status. A
+// key is issued when there is none, when the spec changed, when the Secret
+// is missing or holds another key, and when the rotation period elapsed.
+func (o *Operator) Reconcile(ctx context.Context, hk *HybridKey) error {
+	now := clock.Or(o.Clock).Now()
+	before, _ := json.Marshal(hk.Status)
+	err := o.reconcile(ctx, hk, now)
+	if err != nil {
+		reason := ReasonDaemonError
+		var re *reconcileError
+		if errors.As(err, &re) {
+			reason = re.reason
+		}
+		hk.Status.setCondition(ConditionReady, false, reason, err.Error(), now)
+	}
+	// Unchanged statuses are not written, most passes find nothing to do
+	if after, _ := json.Marshal(hk.Status); bytes.Equal(before, after) {
+		return err
+	}
+	if serr := o.Kube.UpdateHybridKeyStatus(ctx, hk); serr != nil {
+		return errors.Join(err, fmt.Errorf("failed to update status: %w", serr))
+	}
+	return err
+}
+
+// reconcileError carries the Ready reason of a failure
+type reconcileError struct {
+	reason string
+	err    error
+}
+
+func (e *reconcileError) Error() string { return e.err.Error() }
+func (e *reconcileError) Unwrap() error { return e.err }
+
+func (o *Operator) reconcile(ctx context.Context, hk *HybridKey, now time.Time) error {
+	spec, err := hk.Spec.parse()
+	if err != nil {
+		return &reconcileError{ReasonInvalidSpec, err}
+	}
+	secret, err := o.Kube.GetSecret(ctx, hk.Metadata.Namespace, hk.Spec.SecretName)
+	if errors.Is(err, ErrNotFound) {
+		secret, err = nil, nil
+	}
+	if err != nil {
+		return &reconcileError{ReasonSecretError, err}
+	}
+	if secret != nil && !ownedBy(secret, hk) {
+		return &reconcileError{ReasonSecretError, fmt.Errorf("secret %s exists and is not managed by this HybridKey", hk.Spec.SecretName)}
+	}
+
+	st := &hk.Status
+	due := st.SKI == "" || st.RotatedAt == nil || secret == nil || string(secret.Data[SecretSKI]) != st.SKI ||
+		st.ObservedGeneration != hk.Metadata.Generation ||
+		(spec.period > 0 && !now.Before(st.RotatedAt.Add(spec.period)))
+	if due {
+		key, err := o.Issuer.GenerateWrappedKey(ctx, hk.Spec.KeystoreNamespace, spec.recipient)
+		if err != nil {
+			return &reconcileError{ReasonDaemonError, err}
+		}
+		if secret == nil {
+			secret = newSecret(hk)
+		}
+		if err := fillSecret(secret, key); err != nil {
+			return &reconcileError{ReasonSecretError, err}
+		}
+		if err := o.Kube.PutSecret(ctx, secret); err != nil {
+			return &reconcileError{ReasonSecretError, err}
+		}
+		reason := ReasonKeyIssued
+		if st.SKI != "" && st.SKI != key.SKI {
+			st.PreviousSKI, reason = st.SKI, ReasonKeyRotated
+		}
+		st.SKI = key.SKI
+		rotated := now
+		st.RotatedAt = &rotated
+		st.setCondition(ConditionReady, true, reason, "key "+key.SKI+" in secret "+secret.Metadata.Name, now)
+	} else {
+		st.setCondition(ConditionReady, true, readyReason(st), "key "+st.SKI+" in secret "+secret.Metadata.Name, now)
+	}
+	st.NextRotation = nil
+	if spec.period > 0 {
+		next := st.RotatedAt.Add(spec.period)
+		st.NextRotation = &next
+	}
+	st.ObservedGeneration = hk.Metadata.Generation
+	return nil
+}
+
+// readyReason keeps the reason of the last successful issue
+func readyReason(st *HybridKeyStatus) string {
+	if c, ok := st.Condition(ConditionReady); ok && c.Status == "True" {
+		return c.Reason
+	}
+	if st.PreviousSKI != "" {
+		return ReasonKeyRotated
+	}
+	return ReasonKeyIssued
+}
+
+// newSecret returns the Secret of hk, owned by it so that deleting the
+// HybridKey deletes the Secret
+func newSecret(hk *HybridKey) *Secret {
+	return &Secret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Type:       SecretType,
+		Metadata: ObjectMeta{
+			Name:      hk.Spec.SecretName,
+			Namespace: hk.Metadata.Namespace,
+			Labels:    map[string]string{Group + "/hybridkey": hk.Metadata.Name},
+			OwnerReferences: []OwnerReference{{
+				APIVersion: Group + "/" + Version,
+				Kind:       Kind,
+				Name:       hk.Metadata.Name,
+				UID:        hk.Metadata.UID,
+				Controller: true,
+			}},
+		},
+	}
+}
+
+// ownedBy reports whether s was written for hk
+func ownedBy(s *Secret, hk *HybridKey) bool {
+	for _, ref := range s.Metadata.OwnerReferences {
+		if ref.Controller && ref.Kind == Kind && ref.UID == hk.Metadata.UID {
+			return true
+		}
+	}
+	return false
+}
+
+func fillSecret(s *Secret, key *signd.WrappedKeyResponse) error {
+	var pub bytes.Buffer
+	enc := json.NewEncoder(&pub)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(key.PublicKey); err != nil {
+		return err
+	}
+	s.Data = map[string][]byte{
+		SecretSKI:        []byte(key.SKI),
+		SecretPublicKey:  pub.Bytes(),
+		SecretWrappedKey: key.WrappedKey,
+	}
+	return nil
+}
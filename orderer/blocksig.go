@@ -0,0 +1,182 @@
+package orderer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// nonceSize matches crypto.NonceSize in Fabric
+const nonceSize = 24
+
+// ErrInsufficientSignatures is returned when a block carries fewer valid
+// orderer signatures than required
+var ErrInsufficientSignatures = errors.New("insufficient orderer signatures")
+
+// BlockSigner adds the orderer signature to the SIGNATURES metadata of a
+// block, as the block writer does before committing it to the ledger
+type BlockSigner struct {
+	id *msp.SigningIdentity
+}
+
+// NewBlockSigner signs with id
+func NewBlockSigner(id *msp.SigningIdentity) *BlockSigner {
+	return &BlockSigner{id: id}
+}
+
+// SignBlock signs header and value (the marshaled OrdererBlockMetadata)
+// and stores the signature in block's SIGNATURES metadata, keeping any
+// signatures already present
+func (s *BlockSigner) SignBlock(block *fabproto.Block, value []byte) error {
+	if block == nil || block.Header == nil {
+		return errors.New("block has no header")
+	}
+	if block.Metadata == nil {
+		block.Metadata = fabproto.NewBlockMetadata()
+	}
+	for len(block.Metadata.Metadata) <= fabproto.BlockMetadataIndexSignatures {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, nil)
+	}
+
+	md := &fabproto.Metadata{}
+	if raw := block.Metadata.Metadata[fabproto.BlockMetadataIndexSignatures]; len(raw) > 0 {
+		var err error
+		if md, err = fabproto.UnmarshalMetadata(raw); err != nil {
+			return err
+		}
+	}
+	md.Value = value
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	shdr := (&fabproto.SignatureHeader{Creator: s.id.Serialize(), Nonce: nonce}).Marshal()
+	sig, err := s.id.Sign(signedBytes(md.Value, shdr, block.Header))
+	if err != nil {
+		return fmt.Errorf("failed to sign block %d: %w", block.Header.Number, err)
+	}
+	md.Signatures = append(md.Signatures, &fabproto.MetadataSignature{SignatureHeader: shdr, Signature: sig})
+	block.Metadata.Metadata[fabproto.BlockMetadataIndexSignatures] = md.Marshal()
+	return nil
+}
+
+// BlockVerifier checks the orderer signatures of blocks. VerifyBlockSignature
+// has the shape of protoutil.BlockVerifierFunc so the fork can plug it into
+// the deliver client and the block puller.
+type BlockVerifier struct {
+	deserializer *msp.Deserializer
+	csp          bccsp.BCCSP
+	threshold    int
+}
+
+// NewBlockVerifier accepts signatures by identities deserializer validates.
+// threshold <= 0 requires a single signature.
+func NewBlockVerifier(deserializer *msp.Deserializer, csp bccsp.BCCSP, threshold int) *BlockVerifier {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &BlockVerifier{deserializer: deserializer, csp: csp, threshold: threshold}
+}
+
+// VerifyBlockSignature checks that metadata carries at least threshold valid
+// signatures over header by distinct orderer identities. Invalid signatures
+// are ignored as long as enough valid ones remain.
+func (v *BlockVerifier) VerifyBlockSignature(header *fabproto.BlockHeader, metadata *fabproto.BlockMetadata) error {
+	_, err := v.Signers(header, metadata)
+	return err
+}
+
+// Signers returns the distinct orderer identities with a valid signature
+// over header, or an error wrapping ErrInsufficientSignatures when there are
+// fewer than threshold
+func (v *BlockVerifier) Signers(header *fabproto.BlockHeader, metadata *fabproto.BlockMetadata) ([]*msp.Identity, error) {
+	if header == nil {
+		return nil, errors.New("block has no header")
+	}
+	if metadata == nil || len(metadata.Metadata) <= fabproto.BlockMetadataIndexSignatures {
+		return nil, fmt.Errorf("block %d has no signatures metadata", header.Number)
+	}
+	md, err := fabproto.UnmarshalMetadata(metadata.Metadata[fabproto.BlockMetadataIndexSignatures])
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", header.Number, err)
+	}
+
+	seen := map[[sha256.Size]byte]bool{}
+	var signers []*msp.Identity
+	var errs []error
+	for i, ms := range md.Signatures {
+		creator, id, err := v.verifySignature(md.Value, ms, header)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("signature %d: %w", i, err))
+			continue
+		}
+		if h := sha256.Sum256(creator); !seen[h] {
+			seen[h] = true
+			signers = append(signers, id)
+		}
+	}
+	if len(signers) < v.threshold {
+		return nil, fmt.Errorf("block %d: %w: %d of %d required: %w",
+			header.Number, ErrInsufficientSignatures, len(signers), v.threshold, errors.Join(errs...))
+	}
+	return signers, nil
+}
+
+// verifySignature returns the creator of a valid signature and its identity
+func (v *BlockVerifier) verifySignature(value []byte, ms *fabproto.MetadataSignature, header *fabproto.BlockHeader) ([]byte, *msp.Identity, error) {
+	if len(ms.SignatureHeader) == 0 {
+		// BFT identifier headers reference consenters by ID, which Raft
+		// channels do not define
+		return nil, nil, errors.New("signature has no signature header")
+	}
+	shdr, err := fabproto.UnmarshalSignatureHeader(ms.SignatureHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := v.deserializer.DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid creator: %w", err)
+	}
+	digest, err := v.csp.Hash(signedBytes(value, ms.SignatureHeader, header), &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, nil, err
+	}
+	valid, err := v.verify(id.Key, ms.Signature, digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !valid {
+		return nil, nil, fmt.Errorf("invalid signature by %s", id.Certificate.Subject.CommonName)
+	}
+	return shdr.Creator, id, nil
+}
+
+// criticalOpts queues block validation ahead of background jobs
+var criticalOpts = &hybrid.HybridSignerOpts{Priority: hybrid.PriorityCritical}
+
+// verify runs on the hybrid worker pool when the provider has one, so that
+// re-signing or notarization jobs queued there do not delay block validation
+func (v *BlockVerifier) verify(k bccsp.Key, signature, digest []byte) (bool, error) {
+	if async, ok := v.csp.(hybrid.AsyncSigner); ok {
+		r := <-async.VerifyAsync(k, signature, digest, criticalOpts)
+		return r.Valid, r.Err
+	}
+	return v.csp.Verify(k, signature, digest, nil)
+}
+
+// signedBytes is what orderers sign for a block: metadata value, signature
+// header and ASN.1 block header, concatenated
+func signedBytes(value, shdr []byte, header *fabproto.BlockHeader) []byte {
+	headerBytes := fabproto.BlockHeaderBytes(header)
+	out := make([]byte, 0, len(value)+len(shdr)+len(headerBytes))
+	out = append(out, value...)
+	out = append(out, shdr...)
+	return append(out, headerBytes...)
+}
@@ -0,0 +1,92 @@
+// Package orderer wires the hybrid crypto into a Raft ordering service: TLS
+// settings for client and cluster connections, block signing with the
+// orderer's hybrid identity and block signature verification for the
+// fork's block validation path.
+package orderer
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// The structs mirror the orderer.yaml sections they extend, so they can be
+// embedded in the fork's configuration with the same keys.
+
+// Config groups the hybrid settings of an orderer node
+type Config struct {
+	TLS             TLSConfig             `yaml:"TLS"`
+	Cluster         ClusterConfig         `yaml:"Cluster"`
+	BlockSigning    BlockSigningConfig    `yaml:"BlockSigning"`
+	BlockValidation BlockValidationConfig `yaml:"BlockValidation"`
+}
+
+// TLSConfig is General.TLS. The handshake uses the ECDSA half of the
+// composite certificate; RequireComposite additionally checks the ML-DSA
+// signatures of the peer's chain. OCSPStapling staples the hybrid-signed
+// OCSP response of the certificate, whose file must then include its issuer.
+type TLSConfig struct {
+	Enabled            bool     `yaml:"Enabled"`
+	PrivateKey         string   `yaml:"PrivateKey"`
+	Certificate        string   `yaml:"Certificate"`
+	RootCAs            []string `yaml:"RootCAs"`
+	ClientAuthRequired bool     `yaml:"ClientAuthRequired"`
+	ClientRootCAs      []string `yaml:"ClientRootCAs"`
+	RequireComposite   bool     `yaml:"RequireComposite"`
+	OCSPStapling       bool     `yaml:"OCSPStapling"`
+}
+
+// ClusterConfig is General.Cluster, used for Raft replication between
+// consenters. With OCSPStapling, consenters staple the OCSP response of
+// their server certificate and require one from the consenters they dial.
+type ClusterConfig struct {
+	ClientCertificate string   `yaml:"ClientCertificate"`
+	ClientPrivateKey  string   `yaml:"ClientPrivateKey"`
+	ServerCertificate string   `yaml:"ServerCertificate"`
+	ServerPrivateKey  string   `yaml:"ServerPrivateKey"`
+	RootCAs           []string `yaml:"RootCAs"`
+	RequireComposite  bool     `yaml:"RequireComposite"`
+	OCSPStapling      bool     `yaml:"OCSPStapling"`
+}
+
+// BlockSigningConfig selects the local MSP whose identity signs blocks
+// (General.LocalMSPDir and General.LocalMSPID)
+type BlockSigningConfig struct {
+	LocalMSPDir string `yaml:"LocalMSPDir"`
+	LocalMSPID  string `yaml:"LocalMSPID"`
+}
+
+// BlockValidationConfig lists the orderer organizations whose signatures
+// are accepted on blocks, by MSP ID and MSP folder, and how many distinct
+// orderer signatures a block needs (1 for Raft)
+type BlockValidationConfig struct {
+	OrdererMSPs map[string]string `yaml:"OrdererMSPs"`
+	Threshold   int               `yaml:"Threshold"`
+}
+
+// NewSigner loads the signing identity of c
+func (c BlockSigningConfig) NewSigner(csp bccsp.BCCSP) (*BlockSigner, error) {
+	id, err := msp.LoadSigningIdentity(csp, c.LocalMSPDir, c.LocalMSPID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load orderer signing identity: %w", err)
+	}
+	return NewBlockSigner(id), nil
+}
+
+// NewVerifier loads the CA certificates of the configured orderer MSPs
+func (c BlockValidationConfig) NewVerifier(csp bccsp.BCCSP) (*BlockVerifier, error) {
+	if len(c.OrdererMSPs) == 0 {
+		return nil, fmt.Errorf("no orderer MSPs configured")
+	}
+	cas := map[string][]*hybridx509.Certificate{}
+	for mspID, dir := range c.OrdererMSPs {
+		certs, err := msp.LoadCACertificates(dir)
+		if err != nil {
+			return nil, fmt.Errorf("orderer MSP %s: %w", mspID, err)
+		}
+		cas[mspID] = certs
+	}
+	return NewBlockVerifier(&msp.Deserializer{CSP: csp, CAs: cas}, csp, c.Threshold), nil
+}
@@ -0,0 +1,118 @@
+package orderer
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+)
+
+// OCSPStapler staples a hybrid-signed OCSP response of the certificate's
+// issuer to the server certificate, as long as it reports the certificate as
+// good. A response is fetched again once half of its validity has passed;
+// while the responder is unreachable the last response is stapled until it
+// expires.
+type OCSPStapler struct {
+	cert         tls.Certificate
+	leaf, issuer *hybridx509.Certificate
+	client       *http.Client
+	now          func() time.Time
+	refreshing   atomic.Bool
+
+	mutex          sync.RWMutex
+	stapled        *tls.Certificate
+	refreshAt, end time.Time
+}
+
+// NewOCSPStapler returns a stapler for cert, whose chain must include the
+// issuer of the leaf and whose leaf must name an OCSP responder. client is
+// http.DefaultClient if nil.
+func NewOCSPStapler(cert tls.Certificate, client *http.Client) (*OCSPStapler, error) {
+	if len(cert.Certificate) < 2 {
+		return nil, errors.New("OCSP stapling needs the issuer certificate in the certificate file")
+	}
+	leaf, err := hybridx509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := hybridx509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(leaf.OCSPServer) == 0 {
+		return nil, fmt.Errorf("%s: certificate names no OCSP responder", leaf.Subject.CommonName)
+	}
+	return &OCSPStapler{cert: cert, leaf: leaf, issuer: issuer, client: client, now: time.Now}, nil
+}
+
+// Refresh fetches a new response from the first responder that answers
+func (s *OCSPStapler) Refresh() error {
+	var errs []error
+	for _, server := range s.leaf.OCSPServer {
+		der, resp, err := ca.FetchOCSP(s.client, server, s.leaf, s.issuer)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		stapled := s.cert
+		stapled.OCSPStaple = der
+		s.mutex.Lock()
+		s.stapled = &stapled
+		s.refreshAt = resp.ThisUpdate.Add(resp.NextUpdate.Sub(resp.ThisUpdate) / 2)
+		s.end = resp.NextUpdate
+		s.mutex.Unlock()
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// GetCertificate is a tls.Config.GetCertificate callback returning the
+// certificate with the current response, and refreshing it in the
+// background when due
+func (s *OCSPStapler) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := s.now()
+	s.mutex.RLock()
+	stapled, refreshAt, end := s.stapled, s.refreshAt, s.end
+	s.mutex.RUnlock()
+	if (stapled == nil || !now.Before(refreshAt)) && s.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer s.refreshing.Store(false)
+			s.Refresh()
+		}()
+	}
+	if stapled == nil || !now.Before(end) {
+		return &s.cert, nil
+	}
+	return stapled, nil
+}
+
+// VerifyOCSPStaple is a tls.Config.VerifyConnection callback requiring the
+// server to staple a current response, signed by its issuer with both keys,
+// reporting its certificate as good
+func VerifyOCSPStaple(cs tls.ConnectionState) error {
+	if len(cs.OCSPResponse) == 0 {
+		return errors.New("no OCSP response stapled")
+	}
+	if len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) < 2 {
+		return errors.New("no verified certificate chain")
+	}
+	chain := cs.VerifiedChains[0]
+	leaf, err := hybridx509.FromX509(chain[0])
+	if err != nil {
+		return err
+	}
+	issuer, err := hybridx509.FromX509(chain[1])
+	if err != nil {
+		return err
+	}
+	if _, err := ca.CheckOCSP(cs.OCSPResponse, leaf, issuer, time.Now()); err != nil {
+		return fmt.Errorf("%s: stapled OCSP response: %w", leaf.Subject.CommonName, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package orderer
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func writeMSP(t *testing.T, root *ca.CA, cn string) string {
+	id, err := root.Issue(ca.Request{CommonName: cn, OrganizationalUnit: "orderer"})
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), cn, "msp")
+	require.NoError(t, ca.WriteMSP(dir, root, id, ca.MSPOptions{}))
+	return dir
+}
+
+func TestBlockSignatures(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.example.com"}, 0)
+	require.NoError(t, err)
+	mspDir := writeMSP(t, root, "orderer0.example.com")
+
+	signer, err := BlockSigningConfig{LocalMSPDir: mspDir, LocalMSPID: "OrdererMSP"}.NewSigner(csp)
+	require.NoError(t, err)
+	verifier, err := BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": mspDir}}.NewVerifier(csp)
+	require.NoError(t, err)
+
+	block := &fabproto.Block{Header: &fabproto.BlockHeader{Number: 7, PreviousHash: []byte("prev"), DataHash: []byte("data")}}
+	require.NoError(t, signer.SignBlock(block, []byte("orderer metadata")))
+
+	parsed, err := fabproto.UnmarshalBlock(block.Marshal())
+	require.NoError(t, err)
+	require.NoError(t, verifier.VerifyBlockSignature(parsed.Header, parsed.Metadata))
+
+	// Two signatures by the same orderer count once
+	require.NoError(t, signer.SignBlock(block, []byte("orderer metadata")))
+	strict, err := BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": mspDir}, Threshold: 2}.NewVerifier(csp)
+	require.NoError(t, err)
+	err = strict.VerifyBlockSignature(block.Header, block.Metadata)
+	assert.True(t, errors.Is(err, ErrInsufficientSignatures), err)
+
+	tampered := *block.Header
+	tampered.DataHash = []byte("other")
+	err = verifier.VerifyBlockSignature(&tampered, block.Metadata)
+	assert.True(t, errors.Is(err, ErrInsufficientSignatures), err)
+
+	// Orderers of another organization are not accepted
+	other, err := ca.NewRoot(pkix.Name{CommonName: "ca.other.com"}, 0)
+	require.NoError(t, err)
+	foreign, err := BlockValidationConfig{OrdererMSPs: map[string]string{"OrdererMSP": writeMSP(t, other, "orderer0.other.com")}}.NewVerifier(csp)
+	require.NoError(t, err)
+	assert.Error(t, foreign.VerifyBlockSignature(block.Header, block.Metadata))
+}
+
+func writeTLS(t *testing.T, root *ca.CA, cn string) (certFile, keyFile string) {
+	id, err := root.Issue(ca.Request{CommonName: cn, DNSNames: []string{cn}, TLS: true})
+	require.NoError(t, err)
+	keyPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(id.Key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	require.NoError(t, os.WriteFile(certFile, hybridx509.EncodeCertificatePEM(id.Cert.Raw), 0o644))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
+
+func TestClusterTLSRequiresComposite(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "tlsca.example.com"}, 0)
+	require.NoError(t, err)
+	rootFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(rootFile, hybridx509.EncodeCertificatePEM(root.Cert.Raw), 0o644))
+
+	serverCert, serverKey := writeTLS(t, root, "orderer0.example.com")
+	clientCert, clientKey := writeTLS(t, root, "orderer1.example.com")
+	cfg := ClusterConfig{
+		ServerCertificate: serverCert,
+		ServerPrivateKey:  serverKey,
+		ClientCertificate: clientCert,
+		ClientPrivateKey:  clientKey,
+		RootCAs:           []string{rootFile},
+		RequireComposite:  true,
+	}
+	serverCfg, err := cfg.ServerTLSConfig()
+	require.NoError(t, err)
+	clientCfg, err := cfg.ClientTLSConfig()
+	require.NoError(t, err)
+	clientCfg.ServerName = "orderer0.example.com"
+
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	errc := make(chan error, 1)
+	go func() { errc <- tls.Server(s, serverCfg).Handshake() }()
+	require.NoError(t, tls.Client(c, clientCfg).Handshake())
+	require.NoError(t, <-errc)
+}
+
+func TestClusterTLSOCSPStapling(t *testing.T) {
+	root, err := ca.NewRoot(pkix.Name{CommonName: "tlsca.example.com"}, 0)
+	require.NoError(t, err)
+	responder := ca.NewOCSPResponder(root, 0)
+	server := httptest.NewServer(responder.Handler())
+	defer server.Close()
+	dir := t.TempDir()
+	rootFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(rootFile, hybridx509.EncodeCertificatePEM(root.Cert.Raw), 0o644))
+
+	// The certificate file carries the issuer, for the stapler
+	writeChain := func(cn string) (certFile, keyFile string, serial *big.Int) {
+		id, err := root.Issue(ca.Request{CommonName: cn, DNSNames: []string{cn}, TLS: true, OCSPServer: []string{server.URL}})
+		require.NoError(t, err)
+		keyPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(id.Key)
+		require.NoError(t, err)
+		certFile, keyFile = filepath.Join(dir, cn+".crt"), filepath.Join(dir, cn+".key")
+		chain := append(hybridx509.EncodeCertificatePEM(id.Cert.Raw), hybridx509.EncodeCertificatePEM(root.Cert.Raw)...)
+		require.NoError(t, os.WriteFile(certFile, chain, 0o644))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+		return certFile, keyFile, id.Cert.SerialNumber
+	}
+	serverCert, serverKey, serverSerial := writeChain("orderer0.example.com")
+	clientCert, clientKey, _ := writeChain("orderer1.example.com")
+	cfg := ClusterConfig{
+		ServerCertificate: serverCert,
+		ServerPrivateKey:  serverKey,
+		ClientCertificate: clientCert,
+		ClientPrivateKey:  clientKey,
+		RootCAs:           []string{rootFile},
+		OCSPStapling:      true,
+	}
+	handshake := func() error {
+		serverCfg, err := cfg.ServerTLSConfig()
+		require.NoError(t, err)
+		clientCfg, err := cfg.ClientTLSConfig()
+		require.NoError(t, err)
+		clientCfg.ServerName = "orderer0.example.com"
+		c, s := net.Pipe()
+		defer c.Close()
+		defer s.Close()
+		go tls.Server(s, serverCfg).Handshake()
+		return tls.Client(c, clientCfg).Handshake()
+	}
+	require.NoError(t, handshake())
+
+	// Only good responses are stapled, and the client requires one
+	root.Revoke(serverSerial, time.Now())
+	responder.Update(root)
+	assert.ErrorContains(t, handshake(), "no OCSP response stapled")
+	server.Close()
+	assert.ErrorContains(t, handshake(), "no OCSP response stapled")
+}
@@ -0,0 +1,154 @@
+package orderer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// ServerTLSConfig returns the TLS configuration of the orderer's client
+// facing listener
+func (c TLSConfig) ServerTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.Certificate, c.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if c.OCSPStapling {
+		if err := staple(cfg, cert); err != nil {
+			return nil, err
+		}
+	}
+	if c.ClientAuthRequired {
+		pool, err := loadCertPool(c.ClientRootCAs)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = pool
+		if c.RequireComposite {
+			cfg.VerifyPeerCertificate = VerifyCompositeChain
+		}
+	}
+	return cfg, nil
+}
+
+// ServerTLSConfig returns the TLS configuration of the cluster listener.
+// Consenters always authenticate each other.
+func (c ClusterConfig) ServerTLSConfig() (*tls.Config, error) {
+	return c.tlsConfig(c.ServerCertificate, c.ServerPrivateKey, true)
+}
+
+// ClientTLSConfig returns the TLS configuration used to dial other
+// consenters
+func (c ClusterConfig) ClientTLSConfig() (*tls.Config, error) {
+	return c.tlsConfig(c.ClientCertificate, c.ClientPrivateKey, false)
+}
+
+func (c ClusterConfig) tlsConfig(certFile, keyFile string, server bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster key pair: %w", err)
+	}
+	pool, err := loadCertPool(c.RootCAs)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if server {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = pool
+	} else {
+		cfg.RootCAs = pool
+	}
+	if c.RequireComposite {
+		cfg.VerifyPeerCertificate = VerifyCompositeChain
+	}
+	if c.OCSPStapling {
+		if !server {
+			cfg.VerifyConnection = VerifyOCSPStaple
+		} else if err := staple(cfg, cert); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// staple serves cert with its OCSP response. A responder that is down at
+// startup does not prevent it: the response is stapled once fetched.
+func staple(cfg *tls.Config, cert tls.Certificate) error {
+	stapler, err := NewOCSPStapler(cert, &http.Client{Timeout: 10 * time.Second})
+	if err != nil {
+		return err
+	}
+	stapler.Refresh()
+	cfg.Certificates = nil
+	cfg.GetCertificate = stapler.GetCertificate
+	return nil
+}
+
+// VerifyCompositeChain is a tls.Config.VerifyPeerCertificate callback that
+// requires every certificate of the classically verified chain to be
+// composite and to carry a valid ML-DSA signature by its issuer
+func VerifyCompositeChain(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	if len(verifiedChains) == 0 {
+		return errors.New("no verified certificate chain")
+	}
+	var err error
+	for _, chain := range verifiedChains {
+		if err = checkCompositeChain(chain); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func checkCompositeChain(chain []*x509.Certificate) error {
+	certs := make([]*hybridx509.Certificate, len(chain))
+	for i, c := range chain {
+		cert, err := hybridx509.FromX509(c)
+		if err != nil {
+			return err
+		}
+		if !cert.IsComposite() {
+			return fmt.Errorf("%s: %w", c.Subject.CommonName, hybridx509.ErrNotComposite)
+		}
+		certs[i] = cert
+	}
+	// The root is trusted as configured, each other link needs both signatures
+	for i := 0; i+1 < len(certs); i++ {
+		if err := certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+			return fmt.Errorf("%s: %w", certs[i].Subject.CommonName, err)
+		}
+	}
+	return nil
+}
+
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no root CA certificates configured")
+	}
+	pool := x509.NewCertPool()
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(raw) {
+			return nil, fmt.Errorf("%s: no certificates found", f)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,193 @@
+// Package policysim replays committed blocks under a proposed signature
+// policy, so that operators see which historical transactions the policy
+// would reject before enabling it on a channel.
+package policysim
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/blockverify"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// Signature roles
+const (
+	// RoleCreator is the client signature over the transaction envelope
+	RoleCreator = blockverify.RoleCreator
+	// RoleEndorser is an endorsement of the transaction
+	RoleEndorser = blockverify.RoleEndorser
+)
+
+// Failure is a signature that verifies under the current policy but not
+// under the proposed one
+type Failure struct {
+	Block   uint64        `json:"block"`
+	Channel string        `json:"channel"`
+	TxID    string        `json:"tx_id"`
+	Role    string        `json:"role"`
+	MSPID   string        `json:"msp_id"`
+	Signer  string        `json:"signer"`
+	Kind    string        `json:"kind"`
+	Policy  hybrid.Policy `json:"policy"`
+	Error   string        `json:"error"`
+}
+
+// MSPSummary counts the signatures of one organization
+type MSPSummary struct {
+	Signatures int `json:"signatures"`
+	Failing    int `json:"failing"`
+}
+
+// Report is the outcome of a simulation
+type Report struct {
+	Blocks       int `json:"blocks"`
+	Transactions int `json:"transactions"`
+	Signatures   int `json:"signatures"`
+	// FailingTransactions have at least one Failure
+	FailingTransactions int `json:"failing_transactions"`
+	// AlreadyInvalid counts signatures rejected by the current policy too,
+	// or whose identity does not deserialize; the new policy changes
+	// nothing for them
+	AlreadyInvalid int `json:"already_invalid"`
+	// Skipped counts envelopes that do not decode
+	Skipped  int                    `json:"skipped"`
+	MSPs     map[string]*MSPSummary `json:"msps"`
+	Failures []Failure              `json:"failures"`
+}
+
+// Simulator verifies every signature of the replayed blocks twice, under
+// the current and the proposed policies. Identities are not validated
+// against their MSP, only the signature policy is simulated.
+type Simulator struct {
+	csp          bccsp.BCCSP
+	deserializer *msp.Deserializer
+	current      hybrid.PolicyResolver
+	proposed     hybrid.PolicyResolver
+	report       Report
+}
+
+// New returns a simulator of proposed. With a nil current, every signature
+// proposed rejects is reported. opts configure the verifying provider;
+// classical identities are always accepted, their fate is up to the policy.
+func New(current, proposed hybrid.PolicyResolver, opts ...hybrid.Option) (*Simulator, error) {
+	if proposed == nil {
+		return nil, errors.New("no proposed policy")
+	}
+	csp, err := hybrid.New(append(opts, hybrid.WithLegacyECDSA(true))...)
+	if err != nil {
+		return nil, err
+	}
+	return &Simulator{
+		csp:          csp,
+		deserializer: &msp.Deserializer{CSP: csp, AllowClassical: true},
+		current:      current,
+		proposed:     proposed,
+		report:       Report{MSPs: map[string]*MSPSummary{}, Failures: []Failure{}},
+	}, nil
+}
+
+// Replay decodes a marshaled common.Block and checks its transactions
+func (s *Simulator) Replay(raw []byte) error {
+	block, err := fabproto.UnmarshalBlock(raw)
+	if err != nil {
+		return err
+	}
+	if block.Header == nil {
+		return errors.New("block has no header")
+	}
+	s.report.Blocks++
+	for _, env := range block.Data {
+		s.replayEnvelope(block.Header.Number, env)
+	}
+	return nil
+}
+
+// Report returns the results of the blocks replayed so far, failures sorted
+// by block and transaction
+func (s *Simulator) Report() Report {
+	r := s.report
+	r.Failures = append([]Failure(nil), r.Failures...)
+	sort.SliceStable(r.Failures, func(i, j int) bool {
+		if r.Failures[i].Block != r.Failures[j].Block {
+			return r.Failures[i].Block < r.Failures[j].Block
+		}
+		return r.Failures[i].TxID < r.Failures[j].TxID
+	})
+	return r
+}
+
+func (s *Simulator) replayEnvelope(number uint64, raw []byte) {
+	chdr, sigs, err := blockverify.TransactionSignatures(raw)
+	if err != nil {
+		s.report.Skipped++
+		return
+	}
+	s.report.Transactions++
+	failing := false
+	for _, sig := range sigs {
+		if f := s.check(sig, chdr.ChannelId); f != nil {
+			f.Block, f.Channel, f.TxID = number, chdr.ChannelId, chdr.TxId
+			s.report.Failures = append(s.report.Failures, *f)
+			failing = true
+		}
+	}
+	if failing {
+		s.report.FailingTransactions++
+	}
+}
+
+// check verifies sig under both policies, returning a Failure when only the
+// proposed one rejects it
+func (s *Simulator) check(sig blockverify.Signature, channel string) *Failure {
+	s.report.Signatures++
+	id, err := s.deserializer.DeserializeIdentity(sig.Creator)
+	if err != nil {
+		s.report.AlreadyInvalid++
+		return nil
+	}
+	summary := s.report.MSPs[id.MSPID]
+	if summary == nil {
+		summary = &MSPSummary{}
+		s.report.MSPs[id.MSPID] = summary
+	}
+	summary.Signatures++
+
+	digest := sha256.Sum256(sig.Payload)
+	if s.current != nil {
+		if err := s.verify(id, sig.Signature, digest[:], s.current.ResolvePolicy(channel, id.MSPID), channel); err != nil {
+			s.report.AlreadyInvalid++
+			return nil
+		}
+	}
+	policy := s.proposed.ResolvePolicy(channel, id.MSPID)
+	err = s.verify(id, sig.Signature, digest[:], policy, channel)
+	if err == nil {
+		return nil
+	}
+	summary.Failing++
+	return &Failure{
+		Role:   sig.Role,
+		MSPID:  id.MSPID,
+		Signer: id.Certificate.Subject.CommonName,
+		Kind:   id.Kind().String(),
+		Policy: policy,
+		Error:  err.Error(),
+	}
+}
+
+func (s *Simulator) verify(id *msp.Identity, signature, digest []byte, policy hybrid.Policy, channel string) error {
+	valid, err := s.csp.Verify(id.Key, signature, digest, &hybrid.HybridSignerOpts{Channel: channel, MSPID: id.MSPID, Policy: &policy})
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("invalid signature under policy %s", policy)
+	}
+	return nil
+}
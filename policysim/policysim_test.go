@@ -0,0 +1,152 @@
+package policysim
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// signer signs like a Fabric identity: SHA-256 of the message
+type signer struct {
+	serialized []byte
+	sign       func(msg []byte) []byte
+}
+
+func hybridSigner(t *testing.T, mspID string) signer {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca." + mspID}, 0)
+	require.NoError(t, err)
+	issued, err := root.Issue(ca.Request{CommonName: "peer0." + mspID, OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(dir, root, issued, ca.MSPOptions{}))
+	id, err := msp.LoadSigningIdentity(csp, dir, mspID)
+	require.NoError(t, err)
+	return signer{serialized: id.Serialize(), sign: func(msg []byte) []byte {
+		sig, err := id.Sign(msg)
+		require.NoError(t, err)
+		return sig
+	}}
+}
+
+func classicalSigner(t *testing.T, mspID string) signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "peer0." + mspID},
+		NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	serialized := (&fabproto.SerializedIdentity{Mspid: mspID, IdBytes: hybridx509.EncodeCertificatePEM(der)}).Marshal()
+	return signer{serialized: serialized, sign: func(msg []byte) []byte {
+		digest := sha256.Sum256(msg)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		// Fabric signatures are low-S
+		if half := new(big.Int).Rsh(elliptic.P256().Params().N, 1); s.Cmp(half) > 0 {
+			s.Sub(elliptic.P256().Params().N, s)
+		}
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		require.NoError(t, err)
+		return sig
+	}}
+}
+
+// transaction returns an endorser transaction envelope
+func transaction(txID string, creator signer, endorsers ...signer) []byte {
+	prp := []byte("proposal response payload " + txID)
+	action := &fabproto.ChaincodeEndorsedAction{ProposalResponsePayload: prp}
+	for _, e := range endorsers {
+		endorsement := &fabproto.Endorsement{Endorser: e.serialized}
+		endorsement.Signature = e.sign(endorsement.SignedBytes(prp))
+		action.Endorsements = append(action.Endorsements, endorsement)
+	}
+	tx := &fabproto.Transaction{Actions: []*fabproto.TransactionAction{{
+		Header:  (&fabproto.SignatureHeader{Creator: creator.serialized}).Marshal(),
+		Payload: (&fabproto.ChaincodeActionPayload{Action: action}).Marshal(),
+	}}}
+	payload := (&fabproto.Payload{
+		Header: &fabproto.Header{
+			ChannelHeader:   (&fabproto.ChannelHeader{Type: fabproto.HeaderTypeEndorserTransaction, ChannelId: "mychannel", TxId: txID}).Marshal(),
+			SignatureHeader: (&fabproto.SignatureHeader{Creator: creator.serialized, Nonce: []byte("nonce")}).Marshal(),
+		},
+		Data: tx.Marshal(),
+	}).Marshal()
+	return (&fabproto.Envelope{Payload: payload, Signature: creator.sign(payload)}).Marshal()
+}
+
+func TestSimulator(t *testing.T) {
+	org1 := hybridSigner(t, "Org1MSP")
+	org2 := hybridSigner(t, "Org2MSP")
+	legacy := classicalSigner(t, "LegacyMSP")
+	block := (&fabproto.Block{
+		Header: &fabproto.BlockHeader{Number: 7},
+		Data: [][]byte{
+			transaction("tx1", org1, org1, org2),
+			transaction("tx2", org1, org1, legacy),
+			transaction("tx3", legacy, org1),
+			[]byte("not an envelope"),
+		},
+	}).Marshal()
+
+	current := &hybrid.ChannelPolicies{Default: hybrid.PolicyHybridOR}
+	proposed := &hybrid.ChannelPolicies{Default: hybrid.PolicyHybridAND}
+	sim, err := New(current, proposed)
+	require.NoError(t, err)
+	require.NoError(t, sim.Replay(block))
+	r := sim.Report()
+	assert.Equal(t, 1, r.Blocks)
+	assert.Equal(t, 3, r.Transactions)
+	assert.Equal(t, 1, r.Skipped)
+	assert.Equal(t, 8, r.Signatures)
+	assert.Equal(t, 0, r.AlreadyInvalid)
+	assert.Equal(t, 2, r.FailingTransactions)
+	assert.Equal(t, MSPSummary{Signatures: 2, Failing: 2}, *r.MSPs["LegacyMSP"])
+	assert.Equal(t, MSPSummary{Signatures: 5}, *r.MSPs["Org1MSP"])
+	require.Len(t, r.Failures, 2)
+	assert.Equal(t, Failure{
+		Block: 7, Channel: "mychannel", TxID: "tx2", Role: RoleEndorser, MSPID: "LegacyMSP",
+		Signer: "peer0.LegacyMSP", Kind: hybrid.KindClassical.String(), Policy: hybrid.PolicyHybridAND,
+		Error: r.Failures[0].Error,
+	}, r.Failures[0])
+	assert.Equal(t, RoleCreator, r.Failures[1].Role)
+
+	// An override for the organization still migrating keeps it working
+	proposed.Channels = map[string]hybrid.ChannelPolicy{"mychannel": {
+		Default: hybrid.PolicyHybridAND,
+		MSPs:    map[string]hybrid.Policy{"LegacyMSP": hybrid.PolicyHybridOR},
+	}}
+	sim, err = New(current, proposed)
+	require.NoError(t, err)
+	require.NoError(t, sim.Replay(block))
+	assert.Empty(t, sim.Report().Failures)
+
+	// Signatures the current policy rejects are not the new policy's doing
+	sim, err = New(&hybrid.ChannelPolicies{Default: hybrid.PolicyHybridAND}, &hybrid.ChannelPolicies{Default: hybrid.PolicyPQC})
+	require.NoError(t, err)
+	require.NoError(t, sim.Replay(block))
+	r = sim.Report()
+	assert.Equal(t, 2, r.AlreadyInvalid)
+	assert.Empty(t, r.Failures)
+
+	_, err = New(current, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+// Package qlmobile is the API of the Android and iOS bindings, for field
+// apps that validate ledger receipts and decrypt attachments offline. It
+// only uses types gomobile can bind: strings, byte slices, integers,
+// errors and pointers to the structs declared here. Bind it with the
+// purego tag (Go 1.27+), which needs no cgo, see
+// tools/scripts/build_mobile.sh.
+package qlmobile
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/core/ccverify"
+	"github.com/yourusername/quantum-ledger/inclusion"
+)
+
+// provider is the hybrid provider of the KEM operations, keyless
+var provider = sync.OnceValues(func() (bccsp.BCCSP, error) {
+	return hybrid.New()
+})
+
+// Backend returns the PQC backend the library was built with
+func Backend() string {
+	return core.PQCBackend
+}
+
+// Verify checks signature over the SHA-256 digest of message under policy:
+// AND (the default when empty), OR, CLASSICAL or PQC. publicKey is a public
+// key JSON document, a PEM PUBLIC KEY block or the DER composite key.
+func Verify(publicKey, message, signature []byte, policy string) error {
+	pub, err := ccverify.ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	p, err := parsePolicy(policy)
+	if err != nil {
+		return err
+	}
+	return ccverify.Verify(pub, message, signature, p)
+}
+
+func parsePolicy(s string) (core.Policy, error) {
+	if s == "" {
+		return core.PolicyHybridAND, nil
+	}
+	return core.ParsePolicy(s)
+}
+
+// Receipt is a transaction whose inclusion in the ledger was verified
+type Receipt struct {
+	TxID    string
+	Channel string
+	Block   int64
+	// Envelope is the marshaled common.Envelope of the transaction
+	Envelope []byte
+	// SignerKey is the DER composite public key of the prover
+	SignerKey []byte
+}
+
+// ReceiptVerifier checks inclusion proofs, the ledger receipts served by
+// the inclusion prover, against the prover keys the app trusts
+type ReceiptVerifier struct {
+	trusted [][]byte
+}
+
+// NewReceiptVerifier returns a verifier trusting no key; Verify fails until
+// Trust is called
+func NewReceiptVerifier() *ReceiptVerifier {
+	return &ReceiptVerifier{}
+}
+
+// Trust adds a prover public key, in any format Verify accepts
+func (v *ReceiptVerifier) Trust(publicKey []byte) error {
+	pub, err := ccverify.ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	der, err := pub.Marshal()
+	if err != nil {
+		return err
+	}
+	v.trusted = append(v.trusted, der)
+	return nil
+}
+
+// Verify checks a JSON inclusion proof and returns the receipt it proves
+func (v *ReceiptVerifier) Verify(proof []byte) (*Receipt, error) {
+	if len(v.trusted) == 0 {
+		return nil, fmt.Errorf("%w: no trusted prover key", inclusion.ErrUntrustedKey)
+	}
+	p, err := inclusion.ParseProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Verify(v.trusted...); err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		TxID:      p.TxID,
+		Channel:   p.Header.Channel,
+		Block:     int64(p.Header.Number),
+		Envelope:  p.Envelope,
+		SignerKey: p.Header.PublicKey,
+	}, nil
+}
+
+// KEMKey is a hybrid ECDH P-256 + ML-KEM-768 key pair of the device
+type KEMKey struct {
+	key bccsp.Key
+}
+
+// GenerateKEMKey generates a key pair. Store Marshal in the platform secure
+// storage (Keychain, Android Keystore) and register PublicKey with the
+// senders of attachments.
+func GenerateKEMKey() (*KEMKey, error) {
+	csp, err := provider()
+	if err != nil {
+		return nil, err
+	}
+	k, err := csp.KeyGen(&hybrid.HybridKEMKeyGenOpts{Temporary: true})
+	if err != nil {
+		return nil, err
+	}
+	return &KEMKey{key: k}, nil
+}
+
+// ImportKEMKey parses a key pair returned by Marshal
+func ImportKEMKey(data []byte) (*KEMKey, error) {
+	csp, err := provider()
+	if err != nil {
+		return nil, err
+	}
+	k, err := csp.KeyImport(data, &hybrid.HybridKEMPrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, err
+	}
+	return &KEMKey{key: k}, nil
+}
+
+// PublicKey returns the marshaled hybrid KEM public key, the
+// RecipientPublicKey of senders
+func (k *KEMKey) PublicKey() ([]byte, error) {
+	pub, err := k.key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return pub.Bytes()
+}
+
+// Marshal exports the key pair, private key included
+func (k *KEMKey) Marshal() ([]byte, error) {
+	return hybrid.MarshalKEMPrivateKey(k.key)
+}
+
+// Decrypt opens a hybrid KEM ciphertext. aad and kdfInfo must be those the
+// sender encrypted with, nil if none.
+func (k *KEMKey) Decrypt(ciphertext, aad, kdfInfo []byte) ([]byte, error) {
+	csp, err := provider()
+	if err != nil {
+		return nil, err
+	}
+	return csp.Decrypt(k.key, ciphertext, &hybrid.HybridKEMDecrypterOpts{AAD: aad, KDFInfo: kdfInfo})
+}
+
+// Encrypt encrypts plaintext to a marshaled hybrid KEM public key, e.g. for
+// attachments captured on the device
+func Encrypt(recipientPublicKey, plaintext, aad, kdfInfo []byte) ([]byte, error) {
+	csp, err := provider()
+	if err != nil {
+		return nil, err
+	}
+	return csp.Encrypt(nil, plaintext, &hybrid.HybridKEMEncrypterOpts{RecipientPublicKey: recipientPublicKey, AAD: aad, KDFInfo: kdfInfo})
+}
@@ -0,0 +1,93 @@
+package qlmobile
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/inclusion"
+	"github.com/yourusername/quantum-ledger/internal/fabproto"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer key.Clean()
+	pub, err := key.Public().Marshal()
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("receipt"))
+	sig, err := key.Sign(digest[:])
+	require.NoError(t, err)
+
+	assert.NoError(t, Verify(pub, []byte("receipt"), sig, ""))
+	assert.NoError(t, Verify(pub, []byte("receipt"), sig, "pqc"))
+	assert.Error(t, Verify(pub, []byte("forged"), sig, ""))
+	assert.Error(t, Verify(pub, []byte("receipt"), sig, "NONE"))
+}
+
+func TestReceiptVerifier(t *testing.T) {
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer key.Clean()
+	pub, err := key.Public().Marshal()
+	require.NoError(t, err)
+
+	chdr := &fabproto.ChannelHeader{Type: fabproto.HeaderTypeEndorserTransaction, ChannelId: "mychannel", TxId: "tx1"}
+	payload := &fabproto.Payload{Header: &fabproto.Header{ChannelHeader: chdr.Marshal()}, Data: []byte("delivery")}
+	env := (&fabproto.Envelope{Payload: payload.Marshal(), Signature: []byte("sig")}).Marshal()
+	prover, err := inclusion.NewProver("mychannel", key, nil)
+	require.NoError(t, err)
+	_, err = prover.AddBlock(&fabproto.Block{Header: &fabproto.BlockHeader{Number: 3}, Data: [][]byte{env}})
+	require.NoError(t, err)
+	proof, err := prover.Prove("tx1")
+	require.NoError(t, err)
+	raw, err := json.Marshal(proof)
+	require.NoError(t, err)
+
+	v := NewReceiptVerifier()
+	_, err = v.Verify(raw)
+	assert.ErrorIs(t, err, inclusion.ErrUntrustedKey)
+
+	other, err := core.GenerateKey()
+	require.NoError(t, err)
+	defer other.Clean()
+	otherPub, err := other.Public().Marshal()
+	require.NoError(t, err)
+	require.NoError(t, v.Trust(otherPub))
+	_, err = v.Verify(raw)
+	assert.ErrorIs(t, err, inclusion.ErrUntrustedKey)
+
+	require.NoError(t, v.Trust(pub))
+	receipt, err := v.Verify(raw)
+	require.NoError(t, err)
+	assert.Equal(t, &Receipt{TxID: "tx1", Channel: "mychannel", Block: 3, Envelope: env, SignerKey: pub}, receipt)
+
+	_, err = v.Verify([]byte("{}"))
+	assert.ErrorIs(t, err, inclusion.ErrInvalidProof)
+}
+
+func TestKEMKey(t *testing.T) {
+	k, err := GenerateKEMKey()
+	require.NoError(t, err)
+	pub, err := k.PublicKey()
+	require.NoError(t, err)
+	ct, err := Encrypt(pub, []byte("photo"), []byte("attachment-7"), nil)
+	require.NoError(t, err)
+
+	// The key survives a round trip through secure storage
+	stored, err := k.Marshal()
+	require.NoError(t, err)
+	restored, err := ImportKEMKey(stored)
+	require.NoError(t, err)
+	pt, err := restored.Decrypt(ct, []byte("attachment-7"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("photo"), pt)
+
+	_, err = restored.Decrypt(ct, []byte("attachment-8"), nil)
+	assert.Error(t, err)
+	_, err = ImportKEMKey([]byte("garbage"))
+	assert.Error(t, err)
+}
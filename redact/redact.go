@@ -0,0 +1,194 @@
+// Package redact implements hash-chunked redactable signatures: a payload is
+// split into chunks, each chunk is committed to with a salted hash and the
+// hybrid key signs the list of commitments. Disclosed chunks are checked
+// against their commitment, redacted ones carry the commitment only, so an
+// auditor can verify a partially erased payload without seeing the erased data.
+package redact
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+// saltSize keeps commitments of short chunks, e.g. a birth date, from being
+// brute-forced
+const saltSize = 32
+
+// rootDomain separates the signed commitment root from other signed data
+const rootDomain = "QLREDACT1"
+
+var (
+	// ErrInvalidSignature is returned when the signature over the commitments is invalid
+	ErrInvalidSignature = errors.New("invalid redactable signature")
+	// ErrInvalidChunk is returned for chunks that are neither disclosed nor redacted
+	ErrInvalidChunk = errors.New("invalid redactable chunk")
+)
+
+// Chunk is a disclosed chunk (Data and Salt) or a redacted one (Commitment)
+type Chunk struct {
+	Data       []byte `json:"data,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	Commitment []byte `json:"commitment,omitempty"`
+}
+
+// Redacted reports whether c only carries its commitment
+func (c *Chunk) Redacted() bool {
+	return len(c.Commitment) > 0
+}
+
+// commitment is SHA-256(salt || index || data)
+func (c *Chunk) commitment(index int) ([]byte, error) {
+	if c.Redacted() {
+		if len(c.Data) > 0 || len(c.Salt) > 0 || len(c.Commitment) != sha256.Size {
+			return nil, fmt.Errorf("%w %d", ErrInvalidChunk, index)
+		}
+		return c.Commitment, nil
+	}
+	if len(c.Salt) != saltSize {
+		return nil, fmt.Errorf("%w %d: salt is %d bytes", ErrInvalidChunk, index, len(c.Salt))
+	}
+	h := sha256.New()
+	h.Write(c.Salt)
+	binary.Write(h, binary.BigEndian, uint32(index))
+	h.Write(c.Data)
+	return h.Sum(nil), nil
+}
+
+// Payload is a signed, possibly redacted, chunked payload
+type Payload struct {
+	Chunks    []Chunk `json:"chunks"`
+	Signer    []byte  `json:"signer"`
+	Signature []byte  `json:"signature"`
+}
+
+// Root returns what the signature covers: the domain, the number of chunks
+// and every commitment, in order
+func (p *Payload) Root() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(rootDomain)
+	binary.Write(&buf, binary.BigEndian, uint32(len(p.Chunks)))
+	for i := range p.Chunks {
+		c, err := p.Chunks[i].commitment(i)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(c)
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign commits to every chunk with a fresh salt and signs the commitments with id
+func Sign(chunks [][]byte, id *msp.SigningIdentity) (*Payload, error) {
+	p := &Payload{Chunks: make([]Chunk, len(chunks)), Signer: id.Serialize()}
+	for i, data := range chunks {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		p.Chunks[i] = Chunk{Data: append([]byte(nil), data...), Salt: salt}
+	}
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	if p.Signature, err = id.Sign(root); err != nil {
+		return nil, fmt.Errorf("failed to sign commitments: %w", err)
+	}
+	return p, nil
+}
+
+// Redact returns a copy of p with the chunks at indexes replaced by their
+// commitment. The signature stays valid; the redacted data and salts are gone.
+func (p *Payload) Redact(indexes ...int) (*Payload, error) {
+	out := &Payload{Chunks: append([]Chunk(nil), p.Chunks...), Signer: p.Signer, Signature: p.Signature}
+	for _, i := range indexes {
+		if i < 0 || i >= len(out.Chunks) {
+			return nil, fmt.Errorf("chunk %d out of range", i)
+		}
+		if out.Chunks[i].Redacted() {
+			continue
+		}
+		c, err := out.Chunks[i].commitment(i)
+		if err != nil {
+			return nil, err
+		}
+		out.Chunks[i] = Chunk{Commitment: c}
+	}
+	return out, nil
+}
+
+// Disclosed returns the data of the chunks that are not redacted, by index
+func (p *Payload) Disclosed() map[int][]byte {
+	out := map[int][]byte{}
+	for i, c := range p.Chunks {
+		if !c.Redacted() {
+			out[i] = c.Data
+		}
+	}
+	return out
+}
+
+// Verifier checks redactable payloads against trusted identities
+type Verifier struct {
+	Deserializer *msp.Deserializer
+	CSP          bccsp.BCCSP
+}
+
+// Verify checks the signature of p, whatever chunks were redacted, and
+// returns the signer
+func (v *Verifier) Verify(p *Payload) (*msp.Identity, error) {
+	id, err := v.Deserializer.DeserializeIdentity(p.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer: %w", err)
+	}
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := v.CSP.Hash(root, &bccsp.SHA256Opts{})
+	if err != nil {
+		return nil, err
+	}
+	valid, err := v.CSP.Verify(id.Key, p.Signature, digest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+	return id, nil
+}
+
+// FieldChunks splits a JSON object into one chunk per top-level field, in
+// key order, each encoded as a single-field object. Redacting a chunk then
+// erases one field, e.g. a personal data attribute.
+func FieldChunks(object []byte) ([][]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(object, &fields); err != nil {
+		return nil, fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	chunks := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		chunk, err := json.Marshal(map[string]json.RawMessage{k: fields[k]})
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,76 @@
+package redact
+
+import (
+	"crypto/x509/pkix"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+func TestRedactVerify(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	client, err := root.Issue(ca.Request{CommonName: "User1@org1.example.com", OrganizationalUnit: "client"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(mspDir, root, client, ca.MSPOptions{}))
+	id, err := msp.LoadSigningIdentity(csp, mspDir, "Org1MSP")
+	require.NoError(t, err)
+	v := &Verifier{
+		Deserializer: &msp.Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"Org1MSP": {root.Cert}}},
+		CSP:          csp,
+	}
+
+	chunks, err := FieldChunks([]byte(`{"name":"Mario Rossi","amount":100,"iban":"IT60X0542811101000000123456"}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"amount":100}`, string(chunks[0]))
+
+	signed, err := Sign(chunks, id)
+	require.NoError(t, err)
+	signer, err := v.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", signer.MSPID)
+
+	// Erase the personal fields, the amount stays verifiable
+	redacted, err := signed.Redact(1, 2)
+	require.NoError(t, err)
+	raw, err := json.Marshal(redacted)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "Rossi")
+	var decoded Payload
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	_, err = v.Verify(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]byte{0: chunks[0]}, decoded.Disclosed())
+	assert.False(t, signed.Chunks[1].Redacted(), "Redact returns a copy")
+
+	tampered, err := signed.Redact()
+	require.NoError(t, err)
+	tampered.Chunks[0] = Chunk{Data: []byte(`{"amount":1000}`), Salt: signed.Chunks[0].Salt}
+	_, err = v.Verify(tampered)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Reordering chunks changes the commitments
+	swapped, err := signed.Redact()
+	require.NoError(t, err)
+	swapped.Chunks[0], swapped.Chunks[1] = swapped.Chunks[1], swapped.Chunks[0]
+	_, err = v.Verify(swapped)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	dropped := &Payload{Chunks: redacted.Chunks[:2], Signer: redacted.Signer, Signature: redacted.Signature}
+	_, err = v.Verify(dropped)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	bad := &Payload{Chunks: []Chunk{{Data: []byte("x")}}, Signer: signed.Signer, Signature: signed.Signature}
+	_, err = v.Verify(bad)
+	assert.ErrorIs(t, err, ErrInvalidChunk)
+}
@@ -0,0 +1,172 @@
+// Package runner executes the workloads of an experiment, optionally in
+// containers with pinned CPU and memory, so that runs on different lab
+// machines are isolated and comparable.
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resources are the cgroup limits of a workload's container
+type Resources struct {
+	// CPUs is the CPU quota in cores, e.g. 1.5; zero for no quota
+	CPUs float64 `yaml:"cpus,omitempty"`
+	// CPUSet pins the container to cores, e.g. "2-3" or "0,2"
+	CPUSet string `yaml:"cpuset,omitempty"`
+	// Memory is the memory limit, e.g. 4g; swap is disabled with it
+	Memory string `yaml:"memory,omitempty"`
+}
+
+var (
+	cpusetPattern = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+	memoryPattern = regexp.MustCompile(`^\d+[bkmgBKMG]?$`)
+	namePattern   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// Validate checks the limits are accepted by the container runtime
+func (r Resources) Validate() error {
+	if r.CPUs < 0 {
+		return fmt.Errorf("invalid cpus %v", r.CPUs)
+	}
+	if r.CPUSet != "" {
+		if !cpusetPattern.MatchString(r.CPUSet) {
+			return fmt.Errorf("invalid cpuset %q", r.CPUSet)
+		}
+		for _, part := range strings.Split(r.CPUSet, ",") {
+			lo, hi, isRange := strings.Cut(part, "-")
+			if isRange {
+				l, _ := strconv.Atoi(lo)
+				h, _ := strconv.Atoi(hi)
+				if l > h {
+					return fmt.Errorf("invalid cpuset range %q", part)
+				}
+			}
+		}
+	}
+	if r.Memory != "" && !memoryPattern.MatchString(r.Memory) {
+		return fmt.Errorf("invalid memory %q", r.Memory)
+	}
+	return nil
+}
+
+// merge returns r with the fields set in override replaced
+func (r Resources) merge(override *Resources) Resources {
+	if override == nil {
+		return r
+	}
+	if override.CPUs != 0 {
+		r.CPUs = override.CPUs
+	}
+	if override.CPUSet != "" {
+		r.CPUSet = override.CPUSet
+	}
+	if override.Memory != "" {
+		r.Memory = override.Memory
+	}
+	return r
+}
+
+// Workload is a qlbench invocation. Its arguments may use {run}, the run
+// number, and {out}, the output directory as seen by the workload.
+type Workload struct {
+	Name string   `yaml:"name"`
+	Args []string `yaml:"args"`
+	// Resources override the experiment's limits field by field
+	Resources *Resources `yaml:"resources,omitempty"`
+}
+
+// Experiment is a set of workloads repeated Runs times
+type Experiment struct {
+	Name string `yaml:"name"`
+	// Image runs the workloads in container mode; its entrypoint is qlbench
+	Image string `yaml:"image,omitempty"`
+	Runs  int    `yaml:"runs"`
+	// Output is the results directory on the host
+	Output    string     `yaml:"output"`
+	Resources Resources  `yaml:"resources,omitempty"`
+	Workloads []Workload `yaml:"workloads"`
+}
+
+// LoadExperiment reads a YAML file of the form
+//
+//	name: hybrid-endorsement
+//	image: quantum-ledger/qlbench:latest
+//	runs: 5
+//	output: /data/results/hybrid-endorsement
+//	resources:
+//	  cpus: 2
+//	  cpuset: "2-3"
+//	  memory: 4g
+//	workloads:
+//	  - name: endorsement
+//	    args: [endorsement, -run, "{run}", -out, "{out}"]
+//	  - name: energy
+//	    args: [energy, -run, "{run}", -out, "{out}"]
+//	    resources: {cpuset: "2"}
+func LoadExperiment(path string) (*Experiment, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read experiment: %w", err)
+	}
+	e := &Experiment{}
+	if err := yaml.Unmarshal(raw, e); err != nil {
+		return nil, fmt.Errorf("failed to parse experiment %s: %w", path, err)
+	}
+	if e.Runs == 0 {
+		e.Runs = 1
+	}
+	if err := e.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+// Validate checks names, run count and resource limits
+func (e *Experiment) Validate() error {
+	if !namePattern.MatchString(e.Name) {
+		return fmt.Errorf("invalid experiment name %q", e.Name)
+	}
+	if e.Runs < 1 {
+		return fmt.Errorf("invalid runs %d", e.Runs)
+	}
+	if e.Output == "" {
+		return errors.New("output is required")
+	}
+	if len(e.Workloads) == 0 {
+		return errors.New("no workloads")
+	}
+	if err := e.Resources.Validate(); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i, w := range e.Workloads {
+		if !namePattern.MatchString(w.Name) {
+			return fmt.Errorf("workload %d: invalid name %q", i, w.Name)
+		}
+		if seen[w.Name] {
+			return fmt.Errorf("duplicate workload %s", w.Name)
+		}
+		seen[w.Name] = true
+		if len(w.Args) == 0 {
+			return fmt.Errorf("workload %s: no args", w.Name)
+		}
+		if w.Resources != nil {
+			if err := w.Resources.Validate(); err != nil {
+				return fmt.Errorf("workload %s: %w", w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Limits returns the resources of w, the experiment's with w's overrides
+func (e *Experiment) Limits(w Workload) Resources {
+	return e.Resources.merge(w.Resources)
+}
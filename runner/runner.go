@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode selects where workloads run
+type Mode string
+
+const (
+	// ModeLocal runs qlbench directly; resource limits are not applied
+	ModeLocal Mode = "local"
+	// ModeContainer runs each workload in a fresh container of the
+	// experiment image with its limits
+	ModeContainer Mode = "container"
+)
+
+// ContainerOutput is where the output directory is mounted in containers
+const ContainerOutput = "/results"
+
+// ExperimentFile is the copy of the experiment written to the output
+// directory, so results carry the limits they were measured under
+const ExperimentFile = "EXPERIMENT.yaml"
+
+// Executor starts a process and waits for it
+type Executor interface {
+	Run(ctx context.Context, name string, args []string, stdout, stderr io.Writer) error
+}
+
+type execExecutor struct{}
+
+func (execExecutor) Run(ctx context.Context, name string, args []string, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	return cmd.Run()
+}
+
+// Runner executes experiments
+type Runner struct {
+	Mode Mode
+	// Executable is the qlbench binary of local mode, the running one if
+	// empty
+	Executable string
+	// Docker is the container CLI, docker if empty; podman is compatible
+	Docker string
+	// Exec starts processes, os/exec if nil
+	Exec           Executor
+	Stdout, Stderr io.Writer
+}
+
+// Run creates the output directory, records the experiment in it and runs
+// every workload once per run, in order. It stops at the first failure.
+func (r *Runner) Run(ctx context.Context, e *Experiment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if r.Mode == ModeContainer && e.Image == "" {
+		return errors.New("container mode requires an image")
+	}
+	if err := os.MkdirAll(e.Output, 0o755); err != nil {
+		return err
+	}
+	raw, err := yaml.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(e.Output, ExperimentFile), raw, 0o644); err != nil {
+		return err
+	}
+
+	executor := r.Exec
+	if executor == nil {
+		executor = execExecutor{}
+	}
+	stdout, stderr := r.Stdout, r.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	for run := 1; run <= e.Runs; run++ {
+		for _, w := range e.Workloads {
+			name, args, err := r.Command(e, w, run)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "run %d %s: %s %s\n", run, w.Name, name, strings.Join(args, " "))
+			if err := executor.Run(ctx, name, args, stdout, stderr); err != nil {
+				return fmt.Errorf("run %d %s: %w", run, w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Command returns the process that runs w for run
+func (r *Runner) Command(e *Experiment, w Workload, run int) (string, []string, error) {
+	switch r.Mode {
+	case ModeLocal, "":
+		name := r.Executable
+		if name == "" {
+			var err error
+			if name, err = os.Executable(); err != nil {
+				return "", nil, err
+			}
+		}
+		return name, expand(w.Args, run, e.Output), nil
+	case ModeContainer:
+		output, err := filepath.Abs(e.Output)
+		if err != nil {
+			return "", nil, err
+		}
+		docker := r.Docker
+		if docker == "" {
+			docker = "docker"
+		}
+		args := []string{"run", "--rm", "--name", fmt.Sprintf("%s-%s-run%d", e.Name, w.Name, run)}
+		args = append(args, limitArgs(e.Limits(w))...)
+		args = append(args, "-v", output+":"+ContainerOutput, e.Image)
+		return docker, append(args, expand(w.Args, run, ContainerOutput)...), nil
+	}
+	return "", nil, fmt.Errorf("unknown mode %q", r.Mode)
+}
+
+// limitArgs are the docker run flags applying res
+func limitArgs(res Resources) []string {
+	var args []string
+	if res.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(res.CPUs, 'f', -1, 64))
+	}
+	if res.CPUSet != "" {
+		args = append(args, "--cpuset-cpus", res.CPUSet)
+	}
+	if res.Memory != "" {
+		// Equal limits disable swap, which would blur latency results
+		args = append(args, "--memory", res.Memory, "--memory-swap", res.Memory)
+	}
+	return args
+}
+
+// expand substitutes {run} and {out} in args
+func expand(args []string, run int, out string) []string {
+	r := strings.NewReplacer("{run}", strconv.Itoa(run), "{out}", out)
+	expanded := make([]string, len(args))
+	for i, a := range args {
+		expanded[i] = r.Replace(a)
+	}
+	return expanded
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const experimentYAML = `
+name: hybrid
+image: quantum-ledger/qlbench:test
+runs: 2
+resources:
+  cpus: 2
+  cpuset: "2-3"
+  memory: 4g
+workloads:
+  - name: endorsement
+    args: [endorsement, -run, "{run}", -out, "{out}"]
+  - name: energy
+    args: [energy, -run, "{run}", -out, "{out}"]
+    resources: {cpus: 1, cpuset: "2"}
+`
+
+func loadExperiment(t *testing.T, yaml string) *Experiment {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "experiment.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yaml+"output: "+filepath.Join(dir, "results")+"\n"), 0o644))
+	e, err := LoadExperiment(path)
+	require.NoError(t, err)
+	return e
+}
+
+type call struct {
+	name string
+	args []string
+}
+
+type recorder struct {
+	calls []call
+	err   error
+}
+
+func (r *recorder) Run(ctx context.Context, name string, args []string, stdout, stderr io.Writer) error {
+	r.calls = append(r.calls, call{name, args})
+	return r.err
+}
+
+func TestContainerRun(t *testing.T) {
+	e := loadExperiment(t, experimentYAML)
+	rec := &recorder{}
+	r := &Runner{Mode: ModeContainer, Exec: rec, Stdout: io.Discard}
+	require.NoError(t, r.Run(context.Background(), e))
+
+	require.Len(t, rec.calls, 4)
+	assert.Equal(t, "docker", rec.calls[0].name)
+	assert.Equal(t, []string{
+		"run", "--rm", "--name", "hybrid-endorsement-run1",
+		"--cpus", "2", "--cpuset-cpus", "2-3", "--memory", "4g", "--memory-swap", "4g",
+		"-v", e.Output + ":/results", "quantum-ledger/qlbench:test",
+		"endorsement", "-run", "1", "-out", "/results",
+	}, rec.calls[0].args)
+	// Overrides apply field by field
+	assert.Equal(t, []string{
+		"run", "--rm", "--name", "hybrid-energy-run2",
+		"--cpus", "1", "--cpuset-cpus", "2", "--memory", "4g", "--memory-swap", "4g",
+		"-v", e.Output + ":/results", "quantum-ledger/qlbench:test",
+		"energy", "-run", "2", "-out", "/results",
+	}, rec.calls[3].args)
+
+	recorded, err := LoadExperiment(filepath.Join(e.Output, ExperimentFile))
+	require.NoError(t, err)
+	assert.Equal(t, e, recorded)
+}
+
+func TestLocalRun(t *testing.T) {
+	e := loadExperiment(t, experimentYAML)
+	rec := &recorder{err: errors.New("exit status 1")}
+	r := &Runner{Mode: ModeLocal, Executable: "/usr/local/bin/qlbench", Exec: rec, Stdout: io.Discard}
+	err := r.Run(context.Background(), e)
+	assert.ErrorContains(t, err, "run 1 endorsement: exit status 1")
+	require.Len(t, rec.calls, 1, "stops at the first failure")
+	assert.Equal(t, call{"/usr/local/bin/qlbench", []string{"endorsement", "-run", "1", "-out", e.Output}}, rec.calls[0])
+
+	e.Image = ""
+	err = (&Runner{Mode: ModeContainer, Exec: rec}).Run(context.Background(), e)
+	assert.ErrorContains(t, err, "requires an image")
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Experiment {
+		return &Experiment{Name: "x", Runs: 1, Output: "out", Workloads: []Workload{{Name: "w", Args: []string{"load"}}}}
+	}
+	require.NoError(t, valid().Validate())
+	for name, mutate := range map[string]func(*Experiment){
+		"name":      func(e *Experiment) { e.Name = "a b" },
+		"runs":      func(e *Experiment) { e.Runs = 0 },
+		"output":    func(e *Experiment) { e.Output = "" },
+		"workloads": func(e *Experiment) { e.Workloads = nil },
+		"args":      func(e *Experiment) { e.Workloads[0].Args = nil },
+		"duplicate": func(e *Experiment) { e.Workloads = append(e.Workloads, e.Workloads[0]) },
+		"cpus":      func(e *Experiment) { e.Resources.CPUs = -1 },
+		"cpuset":    func(e *Experiment) { e.Resources.CPUSet = "0-a" },
+		"range":     func(e *Experiment) { e.Resources.CPUSet = "3-1" },
+		"memory":    func(e *Experiment) { e.Resources.Memory = "4 GB" },
+		"override":  func(e *Experiment) { e.Workloads[0].Resources = &Resources{CPUSet: "x"} },
+	} {
+		e := valid()
+		mutate(e)
+		assert.Error(t, e.Validate(), name)
+	}
+}
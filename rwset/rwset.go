@@ -0,0 +1,127 @@
+// Package rwset canonicalizes transaction read-write sets and hashes them
+// with SHA3-256, so that every SDK signing a proposal response computes the
+// same digest regardless of map iteration or protobuf field order.
+//
+// Encoding, all integers big-endian, strings and byte strings prefixed with
+// their uint32 length:
+//
+//	"QLRWSET1" uint32(#namespaces)
+//	per namespace, sorted by name:
+//	  name uint32(#reads)
+//	  per read, sorted by key:  key uint8(hasVersion) uint64(block) uint64(tx)
+//	  uint32(#writes)
+//	  per write, sorted by key: key uint8(isDelete) value
+package rwset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// domain prefixes the canonical encoding and names its version
+const domain = "QLRWSET1"
+
+// Version is the height of the committed value a read observed
+type Version struct {
+	BlockNum uint64
+	TxNum    uint64
+}
+
+// KVRead is a key read by the chaincode; Version is nil for keys that did not exist
+type KVRead struct {
+	Key     string
+	Version *Version
+}
+
+// KVWrite is a key written or deleted by the chaincode
+type KVWrite struct {
+	Key      string
+	IsDelete bool
+	Value    []byte
+}
+
+// NsReadWriteSet is the read-write set of one chaincode namespace
+type NsReadWriteSet struct {
+	Namespace string
+	Reads     []KVRead
+	Writes    []KVWrite
+}
+
+// Canonical returns the canonical encoding of sets. Namespaces, reads and
+// writes are sorted; a namespace, or a key within a namespace's reads or
+// writes, appearing twice is an error because SDKs could not agree on it.
+func Canonical(sets []NsReadWriteSet) ([]byte, error) {
+	sorted := append([]NsReadWriteSet(nil), sets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Namespace < sorted[j].Namespace })
+
+	out := append([]byte(nil), domain...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(sorted)))
+	for i, ns := range sorted {
+		if i > 0 && ns.Namespace == sorted[i-1].Namespace {
+			return nil, fmt.Errorf("duplicate namespace %q", ns.Namespace)
+		}
+		out = appendString(out, ns.Namespace)
+
+		reads := append([]KVRead(nil), ns.Reads...)
+		sort.Slice(reads, func(i, j int) bool { return reads[i].Key < reads[j].Key })
+		out = binary.BigEndian.AppendUint32(out, uint32(len(reads)))
+		for j, r := range reads {
+			if j > 0 && r.Key == reads[j-1].Key {
+				return nil, fmt.Errorf("namespace %q: duplicate read of key %q", ns.Namespace, r.Key)
+			}
+			out = appendString(out, r.Key)
+			var v Version
+			if r.Version != nil {
+				out = append(out, 1)
+				v = *r.Version
+			} else {
+				out = append(out, 0)
+			}
+			out = binary.BigEndian.AppendUint64(out, v.BlockNum)
+			out = binary.BigEndian.AppendUint64(out, v.TxNum)
+		}
+
+		writes := append([]KVWrite(nil), ns.Writes...)
+		sort.Slice(writes, func(i, j int) bool { return writes[i].Key < writes[j].Key })
+		out = binary.BigEndian.AppendUint32(out, uint32(len(writes)))
+		for j, w := range writes {
+			if j > 0 && w.Key == writes[j-1].Key {
+				return nil, fmt.Errorf("namespace %q: duplicate write of key %q", ns.Namespace, w.Key)
+			}
+			out = appendString(out, w.Key)
+			value := w.Value
+			if w.IsDelete {
+				out = append(out, 1)
+				value = nil
+			} else {
+				out = append(out, 0)
+			}
+			out = appendBytes(out, value)
+		}
+	}
+	return out, nil
+}
+
+// Hash returns the SHA3-256 of the canonical encoding of sets, the digest to
+// sign in the proposal response
+func Hash(sets []NsReadWriteSet) ([]byte, error) {
+	canonical, err := Canonical(sets)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha3.Sum256(canonical)
+	return sum[:], nil
+}
+
+func appendString(out []byte, s string) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s)))
+	return append(out, s...)
+}
+
+func appendBytes(out, b []byte) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(b)))
+	return append(out, b...)
+}
@@ -0,0 +1,64 @@
+package rwset
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sample() []NsReadWriteSet {
+	return []NsReadWriteSet{
+		{
+			Namespace: "marbles",
+			Reads:     []KVRead{{Key: "m2", Version: &Version{BlockNum: 7, TxNum: 1}}, {Key: "m1"}},
+			Writes:    []KVWrite{{Key: "m2", Value: []byte("blue")}, {Key: "m1", IsDelete: true, Value: []byte("ignored")}},
+		},
+		{Namespace: "_lifecycle"},
+	}
+}
+
+func TestHashIsOrderIndependent(t *testing.T) {
+	digest, err := Hash(sample())
+	require.NoError(t, err)
+
+	shuffled := sample()
+	shuffled[0], shuffled[1] = shuffled[1], shuffled[0]
+	r := shuffled[1].Reads
+	r[0], r[1] = r[1], r[0]
+	w := shuffled[1].Writes
+	w[0], w[1] = w[1], w[0]
+	other, err := Hash(shuffled)
+	require.NoError(t, err)
+	assert.Equal(t, digest, other)
+
+	// The digest other SDKs must reproduce for sample()
+	canonical, err := Canonical(sample())
+	require.NoError(t, err)
+	assert.Equal(t, "514c52575345543100000002"+
+		"0000000a5f6c6966656379636c65"+"00000000"+"00000000"+
+		"000000076d6172626c6573"+"00000002"+
+		"000000026d31"+"00"+"0000000000000000"+"0000000000000000"+
+		"000000026d32"+"01"+"0000000000000007"+"0000000000000001"+
+		"00000002"+
+		"000000026d31"+"01"+"00000000"+
+		"000000026d32"+"00"+"00000004626c7565",
+		hex.EncodeToString(canonical))
+	assert.Equal(t, "7394fa93078a06ebeb5ca457af2d4763b21dfe88bb1bcc8f23f945647e6efea4", hex.EncodeToString(digest))
+
+	changed := sample()
+	changed[0].Writes[0].Value = []byte("red")
+	other, err = Hash(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, other)
+}
+
+func TestCanonicalRejectsDuplicates(t *testing.T) {
+	_, err := Canonical([]NsReadWriteSet{{Namespace: "cc"}, {Namespace: "cc"}})
+	assert.Error(t, err)
+	_, err = Canonical([]NsReadWriteSet{{Namespace: "cc", Writes: []KVWrite{{Key: "k"}, {Key: "k"}}}})
+	assert.Error(t, err)
+	_, err = Canonical([]NsReadWriteSet{{Namespace: "cc", Reads: []KVRead{{Key: "k"}, {Key: "k"}}}})
+	assert.Error(t, err)
+}
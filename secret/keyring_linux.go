@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// keyctlRead is the KEYCTL_READ operation of keyctl(2)
+const keyctlRead = 11
+
+// readKeyring reads the "user" key with the given description from the
+// thread, process, session or user keyring of the process, with
+// request_key(2) and keyctl(2)
+func readKeyring(description string) ([]byte, error) {
+	if description == "" {
+		return nil, errors.New("empty keyring key description")
+	}
+	typ, err := syscall.BytePtrFromString("user")
+	if err != nil {
+		return nil, err
+	}
+	desc, err := syscall.BytePtrFromString(description)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring key description %q: %w", description, err)
+	}
+	// Without callout information the kernel searches and never upcalls
+	id, _, errno := syscall.Syscall6(syscall.SYS_REQUEST_KEY, uintptr(unsafe.Pointer(typ)), uintptr(unsafe.Pointer(desc)), 0, 0, 0, 0)
+	if errno != 0 {
+		switch errno {
+		case syscall.ENOKEY, syscall.EKEYEXPIRED, syscall.EKEYREVOKED:
+			return nil, fmt.Errorf("%w: keyring key %s: %w", ErrNotFound, description, errno)
+		case syscall.ENOSYS:
+			return nil, fmt.Errorf("%w: keyring key %s: %w", ErrUnsupported, description, errno)
+		}
+		return nil, fmt.Errorf("failed to find keyring key %s: %w", description, errno)
+	}
+
+	// KEYCTL_READ returns the size of the payload, which may have grown
+	// since the buffer was sized
+	buf := make([]byte, 256)
+	for {
+		n, _, errno := syscall.Syscall6(syscall.SYS_KEYCTL, keyctlRead, id, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0, 0)
+		if errno != 0 {
+			clear(buf)
+			return nil, fmt.Errorf("failed to read keyring key %s: %w", description, errno)
+		}
+		if int(n) <= len(buf) {
+			return buf[:n], nil
+		}
+		clear(buf)
+		buf = make([]byte, n)
+	}
+}
@@ -0,0 +1,38 @@
+package secret
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keySpecProcessKeyring is KEY_SPEC_PROCESS_KEYRING
+const keySpecProcessKeyring = -2
+
+// addKey adds a "user" key to the process keyring with add_key(2)
+func addKey(t *testing.T, description, payload string) {
+	typ, _ := syscall.BytePtrFromString("user")
+	desc, _ := syscall.BytePtrFromString(description)
+	data := []byte(payload)
+	ring := keySpecProcessKeyring
+	_, _, errno := syscall.Syscall6(syscall.SYS_ADD_KEY, uintptr(unsafe.Pointer(typ)), uintptr(unsafe.Pointer(desc)),
+		uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), uintptr(ring), 0)
+	if errno != 0 {
+		// Container runtimes commonly filter the keyring syscalls
+		t.Skipf("add_key: %v", errno)
+	}
+}
+
+func TestReadKeyring(t *testing.T) {
+	addKey(t, "quantum-ledger-test-keystore", "from-keyring\n")
+	s, err := Read(Keyring + "quantum-ledger-test-keystore")
+	require.NoError(t, err)
+	assert.Equal(t, "from-keyring", string(s))
+
+	_, err = Read(Keyring + "quantum-ledger-test-missing")
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package secret
+
+import "fmt"
+
+func readKeyring(description string) ([]byte, error) {
+	return nil, fmt.Errorf("%w: the kernel keyring is Linux only", ErrUnsupported)
+}
@@ -0,0 +1,94 @@
+// Package secret reads runtime secrets, e.g. keystore passphrases, from
+// where a deployment can keep them out of process listings, environments
+// and shell history: the Linux kernel keyring, systemd credentials or a
+// file readable by the service only.
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source prefixes accepted by Read
+const (
+	// Keyring reads a "user" key of the kernel keyring, e.g. added with
+	// keyctl padd user qlsignd-keystore @u or systemd-ask-password --keyname
+	Keyring = "keyring:"
+	// Systemd reads a credential passed to the service with LoadCredential=,
+	// LoadCredentialEncrypted= or SetCredential=
+	Systemd = "systemd:"
+	// File reads the first line of a file
+	File = "file:"
+)
+
+var (
+	// ErrNotFound is returned for secrets missing from their source
+	ErrNotFound = errors.New("secret not found")
+	// ErrUnsupported is returned for sources the platform does not provide
+	ErrUnsupported = errors.New("secret source not supported")
+)
+
+// Read returns the secret named by source: keyring:<description>,
+// systemd:<credential> or file:<path>. Surrounding newlines are removed and
+// empty secrets are errors. Callers should clear the secret once used.
+func Read(source string) ([]byte, error) {
+	var (
+		raw []byte
+		err error
+	)
+	switch {
+	case strings.HasPrefix(source, Keyring):
+		raw, err = readKeyring(strings.TrimPrefix(source, Keyring))
+	case strings.HasPrefix(source, Systemd):
+		raw, err = readCredential(strings.TrimPrefix(source, Systemd))
+	case strings.HasPrefix(source, File):
+		raw, err = readFile(strings.TrimPrefix(source, File))
+	default:
+		return nil, fmt.Errorf("unknown secret source %q, expected %s<key>, %s<credential> or %s<path>", source, Keyring, Systemd, File)
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret := bytes.Trim(raw, "\r\n")
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret %s is empty", source)
+	}
+	return secret, nil
+}
+
+// readCredential reads a systemd credential from $CREDENTIALS_DIRECTORY,
+// which systemd sets for services with credentials
+func readCredential(name string) ([]byte, error) {
+	if name == "" || strings.ContainsRune(name, '/') {
+		return nil, fmt.Errorf("invalid systemd credential name %q", name)
+	}
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return nil, fmt.Errorf("%w: systemd credential %s, CREDENTIALS_DIRECTORY is not set", ErrNotFound, name)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: systemd credential %s", ErrNotFound, name)
+	}
+	return raw, err
+}
+
+// readFile reads the first line of a file
+func readFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("empty secret file path")
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	line, _, _ := bytes.Cut(raw, []byte("\n"))
+	return line, nil
+}
@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("correct horse\r\nsecond line\n"), 0o600))
+	s, err := Read(File + path)
+	require.NoError(t, err)
+	assert.Equal(t, "correct horse", string(s))
+
+	_, err = Read(File + path + ".missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0o600))
+	_, err = Read(File + path)
+	assert.ErrorContains(t, err, "empty")
+}
+
+func TestReadSystemdCredential(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore"), []byte("from-systemd"), 0o400))
+
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	_, err := Read(Systemd + "keystore")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	s, err := Read(Systemd + "keystore")
+	require.NoError(t, err)
+	assert.Equal(t, "from-systemd", string(s))
+	_, err = Read(Systemd + "other")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Read(Systemd + "../keystore")
+	assert.Error(t, err)
+}
+
+func TestReadUnknownSource(t *testing.T) {
+	_, err := Read("env:QL_PASSPHRASE")
+	assert.ErrorContains(t, err, "unknown secret source")
+	_, err = Read("hunter2")
+	assert.Error(t, err)
+}
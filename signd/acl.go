@@ -0,0 +1,158 @@
+package signd
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"gopkg.in/yaml.v3"
+)
+
+// Role is a set of daemon operations granted to a client
+type Role string
+
+const (
+	// RoleSign may sign with the keys its per-key grants allow
+	RoleSign Role = "sign"
+	// RoleAdmin may generate and list keys in the namespaces its admin
+	// grants allow
+	RoleAdmin Role = "admin"
+	// RoleAuditRead may read the audit log
+	RoleAuditRead Role = "audit-read"
+)
+
+// ErrDenied is returned for operations the client is not authorized to perform
+var ErrDenied = errors.New("permission denied")
+
+// KeyGrant lets identities sign with one key, or every key of a namespace
+// when SKI is "*"
+type KeyGrant struct {
+	Namespace  string   `yaml:"namespace"`
+	SKI        string   `yaml:"ski"`
+	Identities []string `yaml:"identities"`
+}
+
+// AdminGrant lets identities administer the keys of one namespace, or of
+// every namespace when Namespace is "*"
+type AdminGrant struct {
+	Namespace  string   `yaml:"namespace"`
+	Identities []string `yaml:"identities"`
+}
+
+// ACL maps client identities, the common name of their TLS certificate, to
+// roles, keys and administered namespaces
+type ACL struct {
+	Identities map[string][]Role `yaml:"identities"`
+	Keys       []KeyGrant        `yaml:"keys"`
+	Admins     []AdminGrant      `yaml:"admins"`
+}
+
+// LoadACL reads a YAML file of the form
+//
+//	identities:
+//	  peer0.org1.example.com: [sign]
+//	  admin.org1.example.com: [admin, audit-read]
+//	keys:
+//	  - namespace: Org1MSP
+//	    ski: "*"
+//	    identities: [peer0.org1.example.com]
+//	admins:
+//	  - namespace: Org1MSP
+//	    identities: [admin.org1.example.com]
+func LoadACL(path string) (*ACL, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL: %w", err)
+	}
+	acl := &ACL{}
+	if err := yaml.Unmarshal(raw, acl); err != nil {
+		return nil, fmt.Errorf("failed to parse ACL %s: %w", path, err)
+	}
+	if err := acl.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return acl, nil
+}
+
+// Validate rejects unknown roles, invalid namespaces and malformed SKIs
+func (a *ACL) Validate() error {
+	for id, roles := range a.Identities {
+		for _, r := range roles {
+			if r != RoleSign && r != RoleAdmin && r != RoleAuditRead {
+				return fmt.Errorf("identity %s: unknown role %q", id, r)
+			}
+		}
+	}
+	for i, g := range a.Keys {
+		if err := hybrid.ValidateNamespace(g.Namespace); err != nil {
+			return fmt.Errorf("key grant %d: %w", i, err)
+		}
+		if g.SKI != "*" {
+			if _, err := hex.DecodeString(g.SKI); err != nil || g.SKI == "" {
+				return fmt.Errorf("key grant %d: invalid SKI %q", i, g.SKI)
+			}
+		}
+	}
+	for i, g := range a.Admins {
+		if g.Namespace == "*" {
+			continue
+		}
+		if err := hybrid.ValidateNamespace(g.Namespace); err != nil {
+			return fmt.Errorf("admin grant %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// HasRole reports whether id was granted r
+func (a *ACL) HasRole(id string, r Role) bool {
+	for _, granted := range a.Identities[id] {
+		if granted == r {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSign reports whether id may sign with key ski of namespace ns
+func (a *ACL) CanSign(id, ns string, ski []byte) bool {
+	if !a.HasRole(id, RoleSign) {
+		return false
+	}
+	for _, g := range a.Keys {
+		if g.Namespace != ns || (g.SKI != "*" && !strings.EqualFold(g.SKI, hex.EncodeToString(ski))) {
+			continue
+		}
+		for _, granted := range g.Identities {
+			if granted == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CanAdmin reports whether id may generate and list the keys of namespace ns
+func (a *ACL) CanAdmin(id, ns string) bool {
+	if !a.HasRole(id, RoleAdmin) {
+		return false
+	}
+	for _, g := range a.Admins {
+		if g.Namespace == ns || g.Namespace == "*" {
+			if slices.Contains(g.Identities, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Identity returns the identity of a verified client certificate
+func Identity(cert *x509.Certificate) string {
+	return cert.Subject.CommonName
+}
@@ -0,0 +1,101 @@
+package signd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StatusError is a request the daemon answered with an error status
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("signing daemon: %d %s", e.Code, e.Message)
+}
+
+// Client calls a signing daemon
+type Client struct {
+	// URL is the daemon's base URL, e.g. https://qlsignd:7443
+	URL string
+	// HTTP presents the client certificate, http.DefaultClient if nil
+	HTTP *http.Client
+	// Version is sent in VersionHeader when not zero; Handshake sets it
+	Version int
+}
+
+// Handshake negotiates the protocol version and keeps it for the following
+// requests. Daemons older than the handshake are reported as version 1.
+func (c *Client) Handshake(ctx context.Context, req HandshakeRequest) (*HandshakeResponse, error) {
+	resp := &HandshakeResponse{}
+	err := c.do(ctx, "POST", "/v1/handshake", req, resp)
+	var se *StatusError
+	if errors.As(err, &se) && se.Code == http.StatusNotFound {
+		c.Version = ProtocolV1
+		return &HandshakeResponse{Version: ProtocolV1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Version = resp.Version
+	return resp, nil
+}
+
+// KeyGen generates a key in namespace ns
+func (c *Client) KeyGen(ctx context.Context, ns string) (*KeyGenResponse, error) {
+	resp := &KeyGenResponse{}
+	return resp, c.do(ctx, "POST", "/v1/keys", KeyGenRequest{Namespace: ns}, resp)
+}
+
+// GenerateWrappedKey generates a key in namespace ns and returns its private
+// material encrypted to recipient, a marshaled hybrid KEM public key
+func (c *Client) GenerateWrappedKey(ctx context.Context, ns string, recipient []byte) (*WrappedKeyResponse, error) {
+	resp := &WrappedKeyResponse{}
+	return resp, c.do(ctx, "POST", "/v1/keys/wrapped", WrappedKeyRequest{Namespace: ns, RecipientPublicKey: recipient}, resp)
+}
+
+// Sign returns the hybrid signature envelope of req
+func (c *Client) Sign(ctx context.Context, req SignRequest) ([]byte, error) {
+	resp := &SignResponse{}
+	if err := c.do(ctx, "POST", "/v1/sign", req, resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, resp interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+path, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Version != 0 {
+		req.Header.Set(VersionHeader, strconv.Itoa(c.Version))
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(r.Body, 4096))
+		return &StatusError{Code: r.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
@@ -0,0 +1,95 @@
+package signd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func TestClientWrappedKey(t *testing.T) {
+	ca := newTestCA(t)
+	admin := "spiffe://example.org/ns/crypto/sa/operator"
+	ks, err := hybrid.NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	acl := &ACL{
+		Identities: map[string][]Role{admin: {RoleAdmin}},
+		Admins:     []AdminGrant{{Namespace: "*", Identities: []string{admin}}},
+	}
+	server := NewServer(ks, acl, audit.NewMemorySink(10), nil)
+	server.SetIdentifier(SPIFFEIdentifier("example.org"))
+	ts := httptest.NewUnstartedServer(server.Handler())
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: x509.NewCertPool()}
+	ts.TLS.ClientCAs.AddCert(ca.cert)
+	ts.StartTLS()
+	defer ts.Close()
+
+	httpClient := ts.Client()
+	httpClient.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{ca.issue(t, admin)}
+	c := &Client{URL: ts.URL, HTTP: httpClient}
+	ctx := context.Background()
+	hs, err := c.Handshake(ctx, HandshakeRequest{Versions: []int{ProtocolV1, ProtocolV2}})
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolV2, c.Version)
+	assert.Equal(t, ProtocolV2, hs.Version)
+
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	kemKey, err := csp.KeyGen(&hybrid.HybridKEMKeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	kemPub, err := kemKey.PublicKey()
+	require.NoError(t, err)
+	recipient, err := kemPub.Bytes()
+	require.NoError(t, err)
+
+	_, err = c.GenerateWrappedKey(ctx, "Org1MSP", []byte("not a key"))
+	var se *StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.Code)
+	skis, err := ks.ListKeys("Org1MSP")
+	require.NoError(t, err)
+	assert.Empty(t, skis, "a failed wrap must not store the key")
+
+	wrapped, err := c.GenerateWrappedKey(ctx, "Org1MSP", recipient)
+	require.NoError(t, err)
+	skis, err = ks.ListKeys("Org1MSP")
+	require.NoError(t, err)
+	assert.Len(t, skis, 1)
+
+	// The recipient signs with the unwrapped key; the daemon's public key verifies
+	priv, err := OpenWrappedKey(csp, kemKey, wrapped.WrappedKey)
+	require.NoError(t, err)
+	k, err := csp.KeyImport(priv.Raw(), &hybrid.HybridPrivateKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("tx"))
+	sig, err := csp.Sign(k, digest[:], nil)
+	require.NoError(t, err)
+	composite, err := wrapped.PublicKey.Composite()
+	require.NoError(t, err)
+	pub, err := csp.KeyImport(composite, &hybrid.HybridPublicKeyImportOpts{})
+	require.NoError(t, err)
+	valid, err := csp.Verify(pub, sig, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = OpenWrappedKey(csp, kemKey, wrapped.WrappedKey[:len(wrapped.WrappedKey)-1])
+	assert.Error(t, err)
+}
+
+func TestClientHandshakeOldDaemon(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	c := &Client{URL: ts.URL}
+	hs, err := c.Handshake(context.Background(), HandshakeRequest{Versions: []int{ProtocolV2}})
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolV1, hs.Version)
+	assert.Equal(t, ProtocolV1, c.Version)
+}
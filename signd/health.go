@@ -0,0 +1,167 @@
+package signd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// Check is a dependency the daemon needs to serve requests
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// KeystoreCheck verifies that ks can be listed and written
+func KeystoreCheck(ks *hybrid.KeyStore) Check {
+	return Check{Name: "keystore", Run: func(context.Context) error { return ks.Check() }}
+}
+
+// SelfTestCheck runs SelfTest with the provider options of the daemon
+func SelfTestCheck(opts ...hybrid.Option) Check {
+	return Check{Name: "pqc-self-test", Run: func(context.Context) error { return SelfTest(opts...) }}
+}
+
+// DialCheck verifies that a TCP connection to address, e.g. the KMS holding
+// wrapping keys, can be opened
+func DialCheck(name, address string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}}
+}
+
+// selfTestMessage is signed by SelfTest
+var selfTestMessage = []byte("qlsignd self-test")
+
+// SelfTest signs and verifies with a temporary hybrid key, checks that a
+// modified digest is rejected, and round-trips a key through the hybrid
+// KEM. It fails on a broken or mismatched PQC library, which would otherwise
+// surface as signing errors under traffic.
+func SelfTest(opts ...hybrid.Option) error {
+	csp, err := hybrid.New(opts...)
+	if err != nil {
+		return err
+	}
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		return fmt.Errorf("key generation: %w", err)
+	}
+	pub, err := k.PublicKey()
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(selfTestMessage)
+	sig, err := csp.Sign(k, digest[:], nil)
+	if err != nil {
+		return fmt.Errorf("signing: %w", err)
+	}
+	if valid, err := csp.Verify(pub, sig, digest[:], nil); err != nil || !valid {
+		return fmt.Errorf("signature does not verify: %v", err)
+	}
+	digest[0] ^= 1
+	if valid, _ := csp.Verify(pub, sig, digest[:], nil); valid {
+		return errors.New("signature of another digest verifies")
+	}
+
+	kemKey, err := csp.KeyGen(&hybrid.HybridKEMKeyGenOpts{Temporary: true})
+	if err != nil {
+		return fmt.Errorf("KEM key generation: %w", err)
+	}
+	kemPub, err := kemKey.PublicKey()
+	if err != nil {
+		return err
+	}
+	h := csp.(*hybrid.HybridBCCSP)
+	dataKey := digest[:]
+	wrapped, err := h.WrapKey(kemPub, dataKey)
+	if err != nil {
+		return fmt.Errorf("key wrap: %w", err)
+	}
+	unwrapped, err := h.UnwrapKey(kemKey, wrapped)
+	if err != nil {
+		return fmt.Errorf("key unwrap: %w", err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		return errors.New("unwrapped key differs")
+	}
+	return nil
+}
+
+// CheckResult is the outcome of a Check
+type CheckResult struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// HealthReport is the body of the health endpoints
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Health serves liveness and readiness. Readiness runs every check, each
+// bounded by Timeout.
+type Health struct {
+	Checks  []Check
+	Timeout time.Duration
+}
+
+// Run runs the checks and reports whether all passed
+func (h *Health) Run(ctx context.Context) (HealthReport, bool) {
+	report := HealthReport{Status: "ok"}
+	ok := true
+	for _, c := range h.Checks {
+		cctx, cancel := ctx, context.CancelFunc(func() {})
+		if h.Timeout > 0 {
+			cctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		}
+		start := time.Now()
+		err := c.Run(cctx)
+		cancel()
+		r := CheckResult{Name: c.Name, OK: err == nil, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			r.Error, report.Status, ok = err.Error(), "fail", false
+		}
+		report.Checks = append(report.Checks, r)
+	}
+	return report, ok
+}
+
+// Handler serves GET /healthz, answering while the process runs, and
+// GET /readyz, 503 while a check fails. They are served without client
+// certificates on a separate listener, for the kubelet probes.
+func (h *Health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, HealthReport{Status: "ok"}, true)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		report, ok := h.Run(r.Context())
+		writeHealth(w, report, ok)
+	})
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, report HealthReport, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
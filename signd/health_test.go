@@ -0,0 +1,73 @@
+package signd
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func TestSelfTest(t *testing.T) {
+	require.NoError(t, SelfTest())
+	require.NoError(t, SelfTest(hybrid.WithEnvelopeFormat(hybrid.FormatLittleEndian)))
+}
+
+func TestHealth(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	ks, err := hybrid.NewKeyStore(dir)
+	require.NoError(t, err)
+	kms, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	kmsAddr := kms.Addr().String()
+
+	health := &Health{Checks: []Check{KeystoreCheck(ks), SelfTestCheck(), DialCheck("kms", kmsAddr)}}
+	ts := httptest.NewServer(health.Handler())
+	defer ts.Close()
+	get := func(path string) (int, HealthReport) {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var report HealthReport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	status, report := get("/readyz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", report.Status)
+	require.Len(t, report.Checks, 3)
+	for _, c := range report.Checks {
+		assert.True(t, c.OK, c.Name)
+	}
+
+	// Lost keystore volume and unreachable KMS: not ready, but alive
+	require.NoError(t, os.RemoveAll(dir))
+	kms.Close()
+	status, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "fail", report.Status)
+	assert.False(t, report.Checks[0].OK)
+	assert.NotEmpty(t, report.Checks[0].Error)
+	assert.True(t, report.Checks[1].OK)
+	assert.False(t, report.Checks[2].OK)
+	status, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestHealthTimeout(t *testing.T) {
+	hang := Check{Name: "hang", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	health := &Health{Checks: []Check{hang}, Timeout: 1}
+	report, ok := health.Run(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
@@ -0,0 +1,132 @@
+package signd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// Protocol versions. Version 1 is the API before the handshake and is
+// assumed for requests without VersionHeader, so old clients keep working.
+// Version 2 adds the handshake and the envelope version of sign requests.
+const (
+	ProtocolV1 = 1
+	ProtocolV2 = 2
+
+	MinProtocolVersion = ProtocolV1
+	MaxProtocolVersion = ProtocolV2
+)
+
+// VersionHeader carries the protocol version of requests and responses
+const VersionHeader = "QL-Protocol-Version"
+
+var (
+	// ErrUnsupportedVersion is returned when client and daemon share no
+	// protocol version
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
+	// ErrUnsupportedAlgorithm is returned when the daemon lacks an
+	// algorithm the client requires
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	// ErrUnsupportedEnvelope is returned when the daemon emits none of the
+	// envelope versions the client reads
+	ErrUnsupportedEnvelope = errors.New("unsupported envelope version")
+)
+
+// Capabilities describe what the daemon serves
+type Capabilities struct {
+	// Versions are the protocol versions, oldest first
+	Versions   []int    `json:"versions"`
+	Algorithms []string `json:"algorithms"`
+	// EnvelopeVersions are the signature envelopes the daemon emits, the
+	// preferred one first
+	EnvelopeVersions []int `json:"envelope_versions"`
+}
+
+// DefaultCapabilities are the capabilities of this daemon
+func DefaultCapabilities() Capabilities {
+	c := Capabilities{
+		Algorithms:       []string{hybrid.AlgorithmECDSA, hybrid.AlgorithmMLDSA},
+		EnvelopeVersions: []int{int(hybrid.EnvelopeV2), int(hybrid.EnvelopeV2LE)},
+	}
+	for v := MinProtocolVersion; v <= MaxProtocolVersion; v++ {
+		c.Versions = append(c.Versions, v)
+	}
+	return c
+}
+
+// HandshakeRequest lists what the client supports. A daemon answering
+// POST /v1/handshake with 404 predates the handshake and speaks version 1.
+type HandshakeRequest struct {
+	Versions []int `json:"versions"`
+	// Algorithms the client needs; the handshake fails if one is missing
+	Algorithms []string `json:"algorithms,omitempty"`
+	// EnvelopeVersions the client can parse, any of the daemon's if empty
+	EnvelopeVersions []int `json:"envelope_versions,omitempty"`
+}
+
+// HandshakeResponse carries the negotiated version and envelope, to be used
+// in the following requests, and the full capabilities
+type HandshakeResponse struct {
+	Version         int          `json:"version"`
+	EnvelopeVersion int          `json:"envelope_version"`
+	Capabilities    Capabilities `json:"capabilities"`
+}
+
+// Negotiate picks the highest protocol version and the preferred envelope
+// version shared by req and c
+func Negotiate(req HandshakeRequest, c Capabilities) (HandshakeResponse, error) {
+	resp := HandshakeResponse{Capabilities: c}
+	for _, v := range req.Versions {
+		if v > resp.Version && slices.Contains(c.Versions, v) {
+			resp.Version = v
+		}
+	}
+	if resp.Version == 0 {
+		return resp, fmt.Errorf("%w: client offers %v, daemon supports %v", ErrUnsupportedVersion, req.Versions, c.Versions)
+	}
+	for _, alg := range req.Algorithms {
+		if !slices.Contains(c.Algorithms, alg) {
+			return resp, fmt.Errorf("%w: %s, daemon supports %v", ErrUnsupportedAlgorithm, alg, c.Algorithms)
+		}
+	}
+	for _, v := range c.EnvelopeVersions {
+		if len(req.EnvelopeVersions) == 0 || slices.Contains(req.EnvelopeVersions, v) {
+			resp.EnvelopeVersion = v
+			break
+		}
+	}
+	if resp.EnvelopeVersion == 0 {
+		return resp, fmt.Errorf("%w: client reads %v, daemon emits %v", ErrUnsupportedEnvelope, req.EnvelopeVersions, c.EnvelopeVersions)
+	}
+	return resp, nil
+}
+
+// requestVersion returns the protocol version of r
+func requestVersion(r *http.Request) (int, error) {
+	h := r.Header.Get(VersionHeader)
+	if h == "" {
+		return ProtocolV1, nil
+	}
+	v, err := strconv.Atoi(h)
+	if err != nil || v < MinProtocolVersion || v > MaxProtocolVersion {
+		return 0, fmt.Errorf("%w %q, daemon supports %d to %d", ErrUnsupportedVersion, h, MinProtocolVersion, MaxProtocolVersion)
+	}
+	return v, nil
+}
+
+// envelopeFormat is the provider format emitting envelope version v, zero
+// for the configured default
+func envelopeFormat(v int) (hybrid.EnvelopeFormat, error) {
+	if v == 0 {
+		return 0, nil
+	}
+	f := hybrid.EnvelopeFormat(v)
+	if v > 0xff || !f.Valid() {
+		return 0, fmt.Errorf("%w %d", ErrUnsupportedEnvelope, v)
+	}
+	return f, nil
+}
@@ -0,0 +1,360 @@
+// Package signd implements qlsignd, a signing daemon holding the hybrid keys
+// of several organizations. Clients authenticate with TLS certificates; the
+// common name, or the SPIFFE ID of SPIRE-issued SVIDs, is looked up in an
+// ACL granting roles (sign, admin, audit-read), per-key signing rights and
+// per-namespace admin rights. Every request, denied ones included, is
+// recorded in the audit log.
+//
+// API, JSON over HTTPS:
+//
+//	POST /v1/handshake          {"versions","algorithms","envelope_versions"}    any client
+//	POST /v1/keys               {"namespace"}                                    admin + namespace grant
+//	POST /v1/keys/wrapped       {"namespace","recipient_public_key"}             admin + namespace grant
+//	GET  /v1/keys?namespace=...                                                  admin + namespace grant
+//	POST /v1/sign               {"namespace","ski","digest","envelope_version"}  sign + key grant
+//	GET  /v1/audit                                                               audit-read
+//
+// Clients negotiate the protocol version with the handshake and send it in
+// the QL-Protocol-Version header of later requests; requests without it are
+// served as version 1. Health serves the liveness and readiness probes on a
+// separate plain HTTP listener.
+package signd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+// maxRequestSize bounds request bodies; the largest, a wrapped key request,
+// is a few kilobytes
+const maxRequestSize = 64 << 10
+
+// Server serves the daemon API
+type Server struct {
+	keystore *hybrid.KeyStore
+	acl      *ACL
+	identify Identifier
+	sink     audit.Sink
+	log      *audit.MemorySink
+	opts     []hybrid.Option
+
+	mutex     sync.Mutex
+	providers map[providerKey]bccsp.BCCSP
+}
+
+// providerKey identifies the provider of a namespace emitting an envelope
+// format, zero for the configured one
+type providerKey struct {
+	namespace string
+	format    hybrid.EnvelopeFormat
+}
+
+// NewServer returns a server signing with the keys of ks. Events are kept in
+// log, served to auditors, and forwarded to sink if not nil. opts configure
+// the provider of each namespace.
+func NewServer(ks *hybrid.KeyStore, acl *ACL, log *audit.MemorySink, sink audit.Sink, opts ...hybrid.Option) *Server {
+	return &Server{keystore: ks, acl: acl, identify: CommonNameIdentifier, log: log, sink: sink, opts: opts, providers: map[providerKey]bccsp.BCCSP{}}
+}
+
+// SetIdentifier selects how client certificates map to ACL identities,
+// e.g. SPIFFEIdentifier; clients are identified by common name by default
+func (s *Server) SetIdentifier(identify Identifier) {
+	s.identify = identify
+}
+
+// Preload creates the provider of every namespace of the keystore, loading
+// all its keys, so the first requests after a restart are not slowed down
+func (s *Server) Preload(config hybrid.PreloadConfig) error {
+	namespaces, err := s.keystore.Namespaces()
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		csp, err := hybrid.New(append(s.providerOptions(ns), hybrid.WithPreload(config))...)
+		if err != nil {
+			return fmt.Errorf("namespace %s: %w", ns, err)
+		}
+		s.mutex.Lock()
+		s.providers[providerKey{namespace: ns}] = csp
+		s.mutex.Unlock()
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/handshake", s.handleHandshake)
+	mux.HandleFunc("POST /v1/keys", s.handleKeyGen)
+	mux.HandleFunc("POST /v1/keys/wrapped", s.handleWrappedKeyGen)
+	mux.HandleFunc("GET /v1/keys", s.handleListKeys)
+	mux.HandleFunc("POST /v1/sign", s.handleSign)
+	mux.HandleFunc("GET /v1/audit", s.handleAudit)
+	return mux
+}
+
+// KeyGenRequest asks for a new key in Namespace
+type KeyGenRequest struct {
+	Namespace string `json:"namespace"`
+}
+
+// KeyGenResponse carries the SKI and public key of a new key
+type KeyGenResponse struct {
+	SKI       string                `json:"ski"`
+	PublicKey *hybrid.PublicKeyJSON `json:"public_key"`
+}
+
+// ListKeysResponse lists the SKIs of a namespace
+type ListKeysResponse struct {
+	SKIs []string `json:"skis"`
+}
+
+// SignRequest asks for a hybrid signature of Digest with key SKI of Namespace
+type SignRequest struct {
+	Namespace string `json:"namespace"`
+	SKI       string `json:"ski"`
+	Digest    []byte `json:"digest"`
+	// EnvelopeVersion selects the envelope, the daemon's default if zero.
+	// It requires protocol version 2.
+	EnvelopeVersion int `json:"envelope_version,omitempty"`
+}
+
+// SignResponse carries the hybrid signature envelope
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// AuditResponse carries the retained audit events, oldest first
+type AuditResponse struct {
+	Events []audit.Event `json:"events"`
+}
+
+func (s *Server) handleHandshake(w http.ResponseWriter, r *http.Request) {
+	var req HandshakeRequest
+	id, ok := s.authorize(w, r, audit.OpHandshake, "", &req)
+	if !ok {
+		return
+	}
+	resp, err := Negotiate(req, DefaultCapabilities())
+	if err != nil {
+		s.fail(w, audit.Event{Operation: audit.OpHandshake, Identity: id}, http.StatusBadRequest, err)
+		return
+	}
+	s.respond(w, audit.Event{Operation: audit.OpHandshake, Identity: id}, resp, nil)
+}
+
+func (s *Server) handleKeyGen(w http.ResponseWriter, r *http.Request) {
+	var req KeyGenRequest
+	id, ok := s.authorize(w, r, audit.OpKeyGen, RoleAdmin, &req)
+	if !ok {
+		return
+	}
+	if !s.authorizeNamespace(w, audit.OpKeyGen, id, req.Namespace) {
+		return
+	}
+	resp, err := s.keyGen(req.Namespace)
+	s.respond(w, audit.Event{Operation: audit.OpKeyGen, Identity: id, SKI: resp.SKI}, resp, err)
+}
+
+func (s *Server) keyGen(ns string) (KeyGenResponse, error) {
+	csp, err := s.provider(ns, 0)
+	if err != nil {
+		return KeyGenResponse{}, err
+	}
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		return KeyGenResponse{}, err
+	}
+	pub, err := hybrid.NewPublicKeyJSON(k, time.Now())
+	if err != nil {
+		return KeyGenResponse{}, err
+	}
+	return KeyGenResponse{SKI: hex.EncodeToString(k.SKI()), PublicKey: pub}, nil
+}
+
+func (s *Server) handleListKeys(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.authorize(w, r, audit.OpListKeys, RoleAdmin, nil)
+	if !ok {
+		return
+	}
+	ns := r.URL.Query().Get("namespace")
+	if !s.authorizeNamespace(w, audit.OpListKeys, id, ns) {
+		return
+	}
+	var resp ListKeysResponse
+	skis, err := s.keystore.ListKeys(ns)
+	for _, ski := range skis {
+		resp.SKIs = append(resp.SKIs, hex.EncodeToString(ski))
+	}
+	s.respond(w, audit.Event{Operation: audit.OpListKeys, Identity: id}, resp, err)
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req SignRequest
+	id, ok := s.authorize(w, r, audit.OpSign, RoleSign, &req)
+	if !ok {
+		return
+	}
+	ski, err := hex.DecodeString(req.SKI)
+	if err != nil || len(ski) == 0 {
+		s.fail(w, audit.Event{Operation: audit.OpSign, Identity: id, SKI: req.SKI}, http.StatusBadRequest, errors.New("invalid SKI"))
+		return
+	}
+	if !s.acl.CanSign(id, req.Namespace, ski) {
+		s.fail(w, audit.Event{Operation: audit.OpSign, Identity: id, SKI: req.SKI, Outcome: audit.OutcomeDenied},
+			http.StatusForbidden, fmt.Errorf("%w: key %s in %s", ErrDenied, req.SKI, req.Namespace))
+		return
+	}
+	format, err := envelopeFormat(req.EnvelopeVersion)
+	if err == nil && req.EnvelopeVersion != 0 {
+		if v, _ := requestVersion(r); v < ProtocolV2 {
+			err = fmt.Errorf("%w: envelope_version requires protocol version %d", ErrUnsupportedVersion, ProtocolV2)
+		}
+	}
+	if err != nil {
+		s.fail(w, audit.Event{Operation: audit.OpSign, Identity: id, SKI: req.SKI}, http.StatusBadRequest, err)
+		return
+	}
+	var resp SignResponse
+	csp, err := s.provider(req.Namespace, format)
+	if err == nil {
+		var k bccsp.Key
+		if k, err = csp.GetKey(ski); err == nil {
+			resp.Signature, err = csp.Sign(k, req.Digest, nil)
+		}
+	}
+	s.respond(w, audit.Event{Operation: audit.OpSign, Identity: id, SKI: req.SKI}, resp, err)
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.authorize(w, r, audit.OpReadAudit, RoleAuditRead, nil)
+	if !ok {
+		return
+	}
+	resp := AuditResponse{Events: s.log.Events()}
+	s.respond(w, audit.Event{Operation: audit.OpReadAudit, Identity: id}, resp, nil)
+}
+
+// authorize authenticates the client, checks the protocol version and that
+// the client has role, if not empty, and decodes the request body into req,
+// if not nil. It writes the error response and audits the denial when the
+// request cannot proceed.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, op audit.Operation, role Role, req interface{}) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		s.fail(w, audit.Event{Operation: op, Outcome: audit.OutcomeDenied}, http.StatusUnauthorized, errors.New("client certificate required"))
+		return "", false
+	}
+	id, err := s.identify(r.TLS.VerifiedChains[0][0])
+	if err != nil {
+		s.fail(w, audit.Event{Operation: op, Outcome: audit.OutcomeDenied}, http.StatusUnauthorized, err)
+		return "", false
+	}
+	version, err := requestVersion(r)
+	if err != nil {
+		s.fail(w, audit.Event{Operation: op, Identity: id}, http.StatusBadRequest, err)
+		return id, false
+	}
+	w.Header().Set(VersionHeader, strconv.Itoa(version))
+	if role != "" && !s.acl.HasRole(id, role) {
+		s.fail(w, audit.Event{Operation: op, Identity: id, Outcome: audit.OutcomeDenied},
+			http.StatusForbidden, fmt.Errorf("%w: %s requires role %s", ErrDenied, op, role))
+		return id, false
+	}
+	if req != nil {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(req); err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			s.fail(w, audit.Event{Operation: op, Identity: id}, status, fmt.Errorf("invalid request: %w", err))
+			return id, false
+		}
+	}
+	return id, true
+}
+
+// authorizeNamespace checks that ns is a valid namespace the admin id
+// administers. It writes the error response and audits the denial when the
+// request cannot proceed.
+func (s *Server) authorizeNamespace(w http.ResponseWriter, op audit.Operation, id, ns string) bool {
+	if err := hybrid.ValidateNamespace(ns); err != nil {
+		s.fail(w, audit.Event{Operation: op, Identity: id}, http.StatusBadRequest, err)
+		return false
+	}
+	if !s.acl.CanAdmin(id, ns) {
+		s.fail(w, audit.Event{Operation: op, Identity: id, Outcome: audit.OutcomeDenied},
+			http.StatusForbidden, fmt.Errorf("%w: namespace %s", ErrDenied, ns))
+		return false
+	}
+	return true
+}
+
+// provider returns the hybrid provider of namespace ns emitting envelopes
+// of format, the configured one if zero
+func (s *Server) provider(ns string, format hybrid.EnvelopeFormat) (bccsp.BCCSP, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := providerKey{namespace: ns, format: format}
+	if csp, ok := s.providers[key]; ok {
+		return csp, nil
+	}
+	opts := s.providerOptions(ns)
+	if format != 0 {
+		opts = append(opts, hybrid.WithEnvelopeFormat(format))
+	}
+	csp, err := hybrid.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.providers[key] = csp
+	return csp, nil
+}
+
+func (s *Server) providerOptions(ns string) []hybrid.Option {
+	return append(append([]hybrid.Option(nil), s.opts...), hybrid.WithKeyStore(s.keystore, ns))
+}
+
+// respond audits e and writes resp, or the error
+func (s *Server) respond(w http.ResponseWriter, e audit.Event, resp interface{}, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, hybrid.ErrKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		s.fail(w, e, status, err)
+		return
+	}
+	e.Outcome = audit.OutcomeSuccess
+	s.emit(e)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fail audits e, as a failure unless it has an outcome, and writes err
+func (s *Server) fail(w http.ResponseWriter, e audit.Event, status int, err error) {
+	if e.Outcome == "" {
+		e.Outcome = audit.OutcomeFailure
+	}
+	e.Error = err.Error()
+	s.emit(e)
+	http.Error(w, err.Error(), status)
+}
+
+func (s *Server) emit(e audit.Event) {
+	e.Time = time.Now()
+	s.log.Emit(e)
+	if s.sink != nil {
+		s.sink.Emit(e)
+	}
+}
@@ -0,0 +1,193 @@
+package signd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func call(t *testing.T, h http.Handler, id, method, path string, body interface{}, resp interface{}) int {
+	t.Helper()
+	return callVersion(t, h, id, "", method, path, body, resp)
+}
+
+// callVersion is call sending version in VersionHeader, if not empty
+func callVersion(t *testing.T, h http.Handler, id, version, method, path string, body interface{}, resp interface{}) int {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if version != "" {
+		req.Header.Set(VersionHeader, version)
+	}
+	if id != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: id}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code == http.StatusOK && resp != nil {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(resp))
+	}
+	return rec.Code
+}
+
+func TestServerRBAC(t *testing.T) {
+	ks, err := hybrid.NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	acl := &ACL{
+		Identities: map[string][]Role{
+			"admin":   {RoleAdmin},
+			"admin2":  {RoleAdmin},
+			"peer0":   {RoleSign},
+			"peer1":   {RoleSign},
+			"auditor": {RoleAuditRead},
+		},
+		Keys: []KeyGrant{{Namespace: "Org1MSP", SKI: "*", Identities: []string{"peer0"}}},
+		Admins: []AdminGrant{
+			{Namespace: "Org1MSP", Identities: []string{"admin", "peer0"}},
+			{Namespace: "Org2MSP", Identities: []string{"admin2"}},
+		},
+	}
+	require.NoError(t, acl.Validate())
+	log := audit.NewMemorySink(100)
+	h := NewServer(ks, acl, log, nil).Handler()
+
+	var key KeyGenResponse
+	assert.Equal(t, http.StatusForbidden, call(t, h, "peer0", "POST", "/v1/keys", KeyGenRequest{Namespace: "Org1MSP"}, nil))
+	require.Equal(t, http.StatusOK, call(t, h, "admin", "POST", "/v1/keys", KeyGenRequest{Namespace: "Org1MSP"}, &key))
+
+	var list ListKeysResponse
+	require.Equal(t, http.StatusOK, call(t, h, "admin", "GET", "/v1/keys?namespace=Org1MSP", nil, &list))
+	assert.Equal(t, []string{key.SKI}, list.SKIs)
+
+	// Admins administer their namespaces only
+	assert.Equal(t, http.StatusForbidden, call(t, h, "admin2", "POST", "/v1/keys", KeyGenRequest{Namespace: "Org1MSP"}, nil))
+	assert.Equal(t, http.StatusForbidden, call(t, h, "admin2", "GET", "/v1/keys?namespace=Org1MSP", nil, nil))
+	assert.Equal(t, http.StatusForbidden, call(t, h, "admin", "POST", "/v1/keys/wrapped", WrappedKeyRequest{Namespace: "Org2MSP"}, nil))
+	require.Equal(t, http.StatusOK, call(t, h, "admin2", "GET", "/v1/keys?namespace=Org2MSP", nil, &list))
+	assert.Empty(t, list.SKIs)
+
+	digest := sha256.Sum256([]byte("tx"))
+	req := SignRequest{Namespace: "Org1MSP", SKI: key.SKI, Digest: digest[:]}
+	assert.Equal(t, http.StatusUnauthorized, call(t, h, "", "POST", "/v1/sign", req, nil))
+	assert.Equal(t, http.StatusForbidden, call(t, h, "peer1", "POST", "/v1/sign", req, nil))
+	assert.Equal(t, http.StatusForbidden, call(t, h, "admin", "POST", "/v1/sign", req, nil))
+
+	var sig SignResponse
+	require.Equal(t, http.StatusOK, call(t, h, "peer0", "POST", "/v1/sign", req, &sig))
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	composite, err := key.PublicKey.Composite()
+	require.NoError(t, err)
+	pub, err := csp.KeyImport(composite, &hybrid.HybridPublicKeyImportOpts{})
+	require.NoError(t, err)
+	valid, err := csp.Verify(pub, sig.Signature, digest[:], nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// The grant covers Org1MSP only
+	other := SignRequest{Namespace: "Org2MSP", SKI: key.SKI, Digest: digest[:]}
+	assert.Equal(t, http.StatusForbidden, call(t, h, "peer0", "POST", "/v1/sign", other, nil))
+
+	var events AuditResponse
+	assert.Equal(t, http.StatusForbidden, call(t, h, "admin", "GET", "/v1/audit", nil, nil))
+	require.Equal(t, http.StatusOK, call(t, h, "auditor", "GET", "/v1/audit", nil, &events))
+	var denied []string
+	for _, e := range events.Events {
+		if e.Outcome == audit.OutcomeDenied {
+			denied = append(denied, e.Identity+" "+string(e.Operation))
+		}
+	}
+	assert.Equal(t, []string{"peer0 keygen", "admin2 keygen", "admin2 list_keys", "admin keygen_wrapped",
+		" sign", "peer1 sign", "admin sign", "peer0 sign", "admin read_audit"}, denied)
+}
+
+func TestServerRequestSize(t *testing.T) {
+	ks, err := hybrid.NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	acl := &ACL{Identities: map[string][]Role{"peer0": {RoleSign}}}
+	h := NewServer(ks, acl, audit.NewMemorySink(10), nil).Handler()
+	req := SignRequest{Namespace: "Org1MSP", SKI: "0a", Digest: make([]byte, maxRequestSize)}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, call(t, h, "peer0", "POST", "/v1/sign", req, nil))
+}
+
+func TestACLValidate(t *testing.T) {
+	assert.Error(t, (&ACL{Identities: map[string][]Role{"x": {"root"}}}).Validate())
+	assert.Error(t, (&ACL{Keys: []KeyGrant{{Namespace: "../x", SKI: "*"}}}).Validate())
+	assert.Error(t, (&ACL{Keys: []KeyGrant{{Namespace: "Org1MSP", SKI: "zz"}}}).Validate())
+	assert.Error(t, (&ACL{Admins: []AdminGrant{{Namespace: "../x"}}}).Validate())
+	assert.NoError(t, (&ACL{Admins: []AdminGrant{{Namespace: "*"}}}).Validate())
+	assert.NoError(t, (&ACL{Keys: []KeyGrant{{Namespace: "Org1MSP", SKI: "0a1b"}}}).Validate())
+}
+
+func TestNegotiate(t *testing.T) {
+	c := DefaultCapabilities()
+	resp, err := Negotiate(HandshakeRequest{Versions: []int{1, 2, 3}}, c)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolV2, resp.Version)
+	assert.Equal(t, int(hybrid.EnvelopeV2), resp.EnvelopeVersion)
+
+	resp, err = Negotiate(HandshakeRequest{Versions: []int{1}, EnvelopeVersions: []int{int(hybrid.EnvelopeV2LE)}}, c)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolV1, resp.Version)
+	assert.Equal(t, int(hybrid.EnvelopeV2LE), resp.EnvelopeVersion)
+
+	_, err = Negotiate(HandshakeRequest{Versions: []int{3, 4}}, c)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	_, err = Negotiate(HandshakeRequest{Versions: []int{2}, Algorithms: []string{"SLH-DSA"}}, c)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	_, err = Negotiate(HandshakeRequest{Versions: []int{2}, EnvelopeVersions: []int{int(hybrid.EnvelopeV1)}}, c)
+	assert.ErrorIs(t, err, ErrUnsupportedEnvelope)
+}
+
+func TestServerHandshake(t *testing.T) {
+	ks, err := hybrid.NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	acl := &ACL{
+		Identities: map[string][]Role{"admin": {RoleAdmin}, "peer0": {RoleSign}},
+		Keys:       []KeyGrant{{Namespace: "Org1MSP", SKI: "*", Identities: []string{"peer0"}}},
+		Admins:     []AdminGrant{{Namespace: "Org1MSP", Identities: []string{"admin"}}},
+	}
+	h := NewServer(ks, acl, audit.NewMemorySink(100), nil).Handler()
+
+	// Any authenticated client may negotiate, even without roles
+	var hs HandshakeResponse
+	assert.Equal(t, http.StatusUnauthorized, call(t, h, "", "POST", "/v1/handshake", HandshakeRequest{Versions: []int{2}}, nil))
+	require.Equal(t, http.StatusOK, call(t, h, "unknown", "POST", "/v1/handshake", HandshakeRequest{Versions: []int{1, 2}}, &hs))
+	assert.Equal(t, ProtocolV2, hs.Version)
+	assert.Equal(t, DefaultCapabilities(), hs.Capabilities)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "peer0", "POST", "/v1/handshake", HandshakeRequest{Versions: []int{9}}, nil))
+
+	// Requests without the header are served as version 1
+	var key KeyGenResponse
+	require.Equal(t, http.StatusOK, call(t, h, "admin", "POST", "/v1/keys", KeyGenRequest{Namespace: "Org1MSP"}, &key))
+	assert.Equal(t, http.StatusBadRequest, callVersion(t, h, "admin", "3", "POST", "/v1/keys", KeyGenRequest{Namespace: "Org1MSP"}, nil))
+
+	digest := sha256.Sum256([]byte("tx"))
+	le := SignRequest{Namespace: "Org1MSP", SKI: key.SKI, Digest: digest[:], EnvelopeVersion: int(hybrid.EnvelopeV2LE)}
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "peer0", "POST", "/v1/sign", le, nil))
+	var sig SignResponse
+	v2 := strconv.Itoa(ProtocolV2)
+	require.Equal(t, http.StatusOK, callVersion(t, h, "peer0", v2, "POST", "/v1/sign", le, &sig))
+	assert.Equal(t, hybrid.EnvelopeV2LE, sig.Signature[0])
+	le.EnvelopeVersion = 7
+	assert.Equal(t, http.StatusBadRequest, callVersion(t, h, "peer0", v2, "POST", "/v1/sign", le, nil))
+
+	require.Equal(t, http.StatusOK, callVersion(t, h, "peer0", v2, "POST", "/v1/sign", SignRequest{Namespace: "Org1MSP", SKI: key.SKI, Digest: digest[:]}, &sig))
+	assert.Equal(t, hybrid.EnvelopeV2, sig.Signature[0])
+}
@@ -0,0 +1,269 @@
+package signd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoSPIFFEID is returned for certificates without exactly one SPIFFE ID
+var ErrNoSPIFFEID = errors.New("certificate has no single SPIFFE ID")
+
+var (
+	trustDomainPattern = regexp.MustCompile(`^[a-z0-9._-]+$`)
+	pathSegmentPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+)
+
+// ParseSPIFFEID checks id is a SPIFFE ID, spiffe://trust-domain/path, and
+// returns its trust domain
+func ParseSPIFFEID(id string) (string, error) {
+	u, err := url.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid SPIFFE ID %q: %w", id, err)
+	}
+	if u.Scheme != "spiffe" || u.User != nil || u.Port() != "" || u.RawQuery != "" || u.Fragment != "" || u.Opaque != "" {
+		return "", fmt.Errorf("invalid SPIFFE ID %q", id)
+	}
+	if !trustDomainPattern.MatchString(u.Host) {
+		return "", fmt.Errorf("invalid SPIFFE ID %q: bad trust domain", id)
+	}
+	if u.Path != "" {
+		for _, seg := range strings.Split(u.Path[1:], "/") {
+			if seg == "." || seg == ".." || !pathSegmentPattern.MatchString(seg) {
+				return "", fmt.Errorf("invalid SPIFFE ID %q: bad path segment %q", id, seg)
+			}
+		}
+	}
+	return u.Host, nil
+}
+
+// SPIFFEID returns the SPIFFE ID of an X.509 SVID, its only URI SAN
+func SPIFFEID(cert *x509.Certificate) (string, error) {
+	if len(cert.URIs) != 1 || cert.URIs[0].Scheme != "spiffe" {
+		return "", ErrNoSPIFFEID
+	}
+	id := cert.URIs[0].String()
+	if _, err := ParseSPIFFEID(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Identifier returns the ACL identity of a verified client certificate
+type Identifier func(cert *x509.Certificate) (string, error)
+
+// CommonNameIdentifier identifies clients by common name, the default
+func CommonNameIdentifier(cert *x509.Certificate) (string, error) {
+	return Identity(cert), nil
+}
+
+// SPIFFEIdentifier identifies clients by SPIFFE ID, accepting only the
+// given trust domains
+func SPIFFEIdentifier(trustDomains ...string) Identifier {
+	return func(cert *x509.Certificate) (string, error) {
+		id, err := SPIFFEID(cert)
+		if err != nil {
+			return "", err
+		}
+		if td, _ := ParseSPIFFEID(id); slices.Contains(trustDomains, td) {
+			return id, nil
+		}
+		return "", fmt.Errorf("SPIFFE ID %s is outside trust domains %v", id, trustDomains)
+	}
+}
+
+// ValidateSPIFFE checks that every identity of the ACL is a SPIFFE ID of one
+// of trustDomains, so that no grant is silently unreachable
+func (a *ACL) ValidateSPIFFE(trustDomains ...string) error {
+	ids := make([]string, 0, len(a.Identities))
+	for id := range a.Identities {
+		ids = append(ids, id)
+	}
+	for _, g := range a.Keys {
+		ids = append(ids, g.Identities...)
+	}
+	for _, g := range a.Admins {
+		ids = append(ids, g.Identities...)
+	}
+	for _, id := range ids {
+		td, err := ParseSPIFFEID(id)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(trustDomains, td) {
+			return fmt.Errorf("identity %s is outside trust domains %v", id, trustDomains)
+		}
+	}
+	return nil
+}
+
+// SVID file names written by spiffe-helper with its usual configuration
+const (
+	SVIDCertFile   = "svid.pem"
+	SVIDKeyFile    = "svid_key.pem"
+	SVIDBundleFile = "svid_bundle.pem"
+)
+
+// SVIDSource serves the X.509 SVID and trust bundle that spiffe-helper
+// fetches from the SPIRE agent into a directory. SVIDs are short lived and
+// rewritten before they expire, so the files are reloaded when they change.
+type SVIDSource struct {
+	dir string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	cert    *tls.Certificate
+	bundle  *x509.CertPool
+	id      string
+}
+
+// LoadSVID loads the SVID of dir
+func LoadSVID(dir string) (*SVIDSource, error) {
+	s := &SVIDSource{dir: dir}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ID returns the SPIFFE ID of the SVID
+func (s *SVIDSource) ID() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.id
+}
+
+// TrustDomain returns the trust domain of the SVID
+func (s *SVIDSource) TrustDomain() string {
+	td, _ := ParseSPIFFEID(s.ID())
+	return td
+}
+
+// lastModified is the latest modification time of the SVID files
+func (s *SVIDSource) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{SVIDCertFile, SVIDKeyFile, SVIDBundleFile} {
+		info, err := os.Stat(filepath.Join(s.dir, name))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// reload reads the files if they changed since the last load. A rotation
+// caught halfway, with a key not matching the certificate, fails and keeps
+// the previous SVID until the next attempt.
+func (s *SVIDSource) reload() error {
+	modTime, err := s.lastModified()
+	if err != nil {
+		return fmt.Errorf("failed to read SVID: %w", err)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cert != nil && modTime.Equal(s.modTime) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(s.dir, SVIDCertFile), filepath.Join(s.dir, SVIDKeyFile))
+	if err != nil {
+		return fmt.Errorf("failed to load SVID: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse SVID: %w", err)
+	}
+	id, err := SPIFFEID(leaf)
+	if err != nil {
+		return fmt.Errorf("SVID: %w", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(s.dir, SVIDBundleFile))
+	if err != nil {
+		return fmt.Errorf("failed to read trust bundle: %w", err)
+	}
+	bundle := x509.NewCertPool()
+	if !bundle.AppendCertsFromPEM(raw) {
+		return fmt.Errorf("%s: no certificates found", SVIDBundleFile)
+	}
+	cert.Leaf = leaf
+	s.cert, s.bundle, s.id, s.modTime = &cert, bundle, id, modTime
+	return nil
+}
+
+// TLSConfig returns a server configuration presenting the current SVID and
+// requiring client SVIDs issued by the current trust bundle. Each handshake
+// checks the files for a rotation.
+func (s *SVIDSource) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			// A failed reload keeps serving the previous SVID
+			_ = s.reload()
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*s.cert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    s.bundle,
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig returns a client configuration presenting the current SVID
+// and accepting servers with an SVID issued by the current trust bundle and,
+// if serverID is not empty, with that SPIFFE ID. SVIDs carry no DNS names,
+// so the SPIFFE ID replaces the host name check.
+func (s *SVIDSource) ClientTLSConfig(serverID string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Verified by VerifyConnection against the current bundle
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			_ = s.reload()
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return s.cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			s.mutex.Lock()
+			bundle := s.bundle
+			s.mutex.Unlock()
+			intermediates := x509.NewCertPool()
+			for _, c := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         bundle,
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			if err != nil {
+				return err
+			}
+			id, err := SPIFFEID(cs.PeerCertificates[0])
+			if err != nil {
+				return err
+			}
+			if serverID != "" && id != serverID {
+				return fmt.Errorf("server is %s, expected %s", id, serverID)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package signd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+)
+
+func TestParseSPIFFEID(t *testing.T) {
+	for id, td := range map[string]string{
+		"spiffe://example.org/ns/fabric/sa/peer0": "example.org",
+		"spiffe://prod.example-org_1":             "prod.example-org_1",
+	} {
+		got, err := ParseSPIFFEID(id)
+		require.NoError(t, err, id)
+		assert.Equal(t, td, got)
+	}
+	for _, id := range []string{
+		"https://example.org/peer0",
+		"spiffe://Example.org/peer0",
+		"spiffe://example.org:8443/peer0",
+		"spiffe://user@example.org/peer0",
+		"spiffe://example.org/peer0?x=1",
+		"spiffe://example.org/a//b",
+		"spiffe://example.org/a/../b",
+		"spiffe:///peer0",
+	} {
+		_, err := ParseSPIFFEID(id)
+		assert.Error(t, err, id)
+	}
+}
+
+// testCA issues SVIDs of trust domain example.org
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		URIs:                  []*url.URL{{Scheme: "spiffe", Host: "example.org"}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns an X.509 SVID for id, usable by servers and clients
+func (ca *testCA) issue(t *testing.T, id string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	u, err := url.Parse(id)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{u},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writeSVID writes svid as spiffe-helper does, dated at
+func (ca *testCA) writeSVID(t *testing.T, dir string, svid tls.Certificate, at time.Time) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+	files := map[string][]byte{
+		SVIDCertFile:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svid.Certificate[0]}),
+		SVIDKeyFile:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		SVIDBundleFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}),
+	}
+	for name, raw := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, raw, 0o600))
+		require.NoError(t, os.Chtimes(path, at, at))
+	}
+}
+
+func TestSVIDSource(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	daemon := "spiffe://example.org/ns/crypto/sa/qlsignd"
+	ca.writeSVID(t, dir, ca.issue(t, daemon), time.Now().Add(-time.Minute))
+	svid, err := LoadSVID(dir)
+	require.NoError(t, err)
+	assert.Equal(t, daemon, svid.ID())
+	assert.Equal(t, "example.org", svid.TrustDomain())
+
+	ks, err := hybrid.NewKeyStore(t.TempDir())
+	require.NoError(t, err)
+	peer := "spiffe://example.org/ns/fabric/sa/peer0"
+	acl := &ACL{
+		Identities: map[string][]Role{peer: {RoleAdmin}},
+		Admins:     []AdminGrant{{Namespace: "Org1MSP", Identities: []string{peer}}},
+	}
+	require.NoError(t, acl.ValidateSPIFFE("example.org"))
+	assert.Error(t, acl.ValidateSPIFFE("other.org"))
+	assert.Error(t, (&ACL{Identities: map[string][]Role{"peer0": {RoleAdmin}}}).ValidateSPIFFE("example.org"))
+	assert.Error(t, (&ACL{Admins: []AdminGrant{{Namespace: "*", Identities: []string{"admin"}}}}).ValidateSPIFFE("example.org"))
+
+	server := NewServer(ks, acl, audit.NewMemorySink(10), nil)
+	server.SetIdentifier(SPIFFEIdentifier("example.org"))
+	ts := httptest.NewUnstartedServer(server.Handler())
+	ts.TLS = svid.TLSConfig()
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(id string) *http.Client {
+		cert := ca.issue(t, id)
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{cert},
+			// SVIDs carry no DNS names; clients check the SPIFFE ID instead
+			InsecureSkipVerify: true,
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				_, err := SPIFFEIdentifier("example.org")(cs.PeerCertificates[0])
+				return err
+			},
+		}}}
+	}
+	get := func(c *http.Client) (int, string) {
+		resp, err := c.Get(ts.URL + "/v1/keys?namespace=Org1MSP")
+		require.NoError(t, err)
+		resp.Body.Close()
+		served, err := SPIFFEID(resp.TLS.PeerCertificates[0])
+		require.NoError(t, err)
+		return resp.StatusCode, served
+	}
+
+	status, served := get(client(peer))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, daemon, served)
+	status, _ = get(client("spiffe://example.org/ns/fabric/sa/peer1"))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// A rotated SVID is served from the next handshake
+	rotated := "spiffe://example.org/ns/crypto/sa/qlsignd-2"
+	ca.writeSVID(t, dir, ca.issue(t, rotated), time.Now())
+	_, served = get(client(peer))
+	assert.Equal(t, rotated, served)
+
+	// Clients with an SVID check the server's SPIFFE ID
+	peerDir := t.TempDir()
+	ca.writeSVID(t, peerDir, ca.issue(t, peer), time.Now())
+	peerSVID, err := LoadSVID(peerDir)
+	require.NoError(t, err)
+	svidClient := func(serverID string) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: peerSVID.ClientTLSConfig(serverID)}}
+	}
+	status, _ = get(svidClient(rotated))
+	assert.Equal(t, http.StatusOK, status)
+	_, err = svidClient(daemon).Get(ts.URL + "/v1/keys?namespace=Org1MSP")
+	assert.ErrorContains(t, err, "expected "+daemon)
+}
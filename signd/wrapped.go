@@ -0,0 +1,115 @@
+package signd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/audit"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+)
+
+// WrappedKeyInfo is the KDF info of wrapped keys, binding the ciphertext to
+// this use of the recipient's KEM key
+var WrappedKeyInfo = []byte("qlsignd/wrapped-key")
+
+// WrappedKeyRequest asks for a new key in Namespace whose private material
+// is also returned, encrypted to a hybrid KEM public key
+type WrappedKeyRequest struct {
+	Namespace string `json:"namespace"`
+	// RecipientPublicKey is the marshaled hybrid KEM public key of the
+	// workload that will use the key
+	RecipientPublicKey []byte `json:"recipient_public_key"`
+}
+
+// WrappedKeyResponse carries the new key and its wrapped private material
+type WrappedKeyResponse struct {
+	SKI       string                `json:"ski"`
+	PublicKey *hybrid.PublicKeyJSON `json:"public_key"`
+	// WrappedKey is the ECDSA and PQC private key PEM blocks, hybrid KEM
+	// encrypted with WrappedKeyInfo; see OpenWrappedKey
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+func (s *Server) handleWrappedKeyGen(w http.ResponseWriter, r *http.Request) {
+	var req WrappedKeyRequest
+	id, ok := s.authorize(w, r, audit.OpKeyGenWrapped, RoleAdmin, &req)
+	if !ok {
+		return
+	}
+	if !s.authorizeNamespace(w, audit.OpKeyGenWrapped, id, req.Namespace) {
+		return
+	}
+	csp, err := s.provider(req.Namespace, 0)
+	if err != nil {
+		s.respond(w, audit.Event{Operation: audit.OpKeyGenWrapped, Identity: id}, nil, err)
+		return
+	}
+	recipient, err := csp.KeyImport(req.RecipientPublicKey, &hybrid.HybridKEMPublicKeyImportOpts{})
+	if err != nil {
+		s.fail(w, audit.Event{Operation: audit.OpKeyGenWrapped, Identity: id}, http.StatusBadRequest, fmt.Errorf("invalid recipient_public_key: %w", err))
+		return
+	}
+	resp, err := wrappedKeyGen(csp, recipient)
+	s.respond(w, audit.Event{Operation: audit.OpKeyGenWrapped, Identity: id, SKI: resp.SKI}, resp, err)
+}
+
+// wrappedKeyGen generates the key outside csp, the only way to see its
+// private material, and imports it into csp's namespace
+func wrappedKeyGen(csp bccsp.BCCSP, recipient bccsp.Key) (WrappedKeyResponse, error) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	pqc, err := hybrid.NewPQCSigner()
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	defer pqc.Clean()
+	priv := &hybridx509.PrivateKey{ECDSA: ecdsaKey, PQC: pqc}
+	ecdsaPEM, err := hybridx509.MarshalECDSAPrivateKeyPEM(priv)
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	pqcPEM, err := hybridx509.MarshalPQCPrivateKeyPEM(priv)
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	// Encrypt before storing, a failure must not leave a key behind
+	wrapped, err := csp.Encrypt(recipient, append(ecdsaPEM, pqcPEM...), &hybrid.HybridKEMEncrypterOpts{KDFInfo: WrappedKeyInfo})
+	if err != nil {
+		return WrappedKeyResponse{}, fmt.Errorf("failed to wrap key: %w", err)
+	}
+	k, err := csp.KeyImport(priv.Raw(), &hybrid.HybridPrivateKeyImportOpts{})
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	pub, err := hybrid.NewPublicKeyJSON(k, time.Now())
+	if err != nil {
+		return WrappedKeyResponse{}, err
+	}
+	return WrappedKeyResponse{SKI: hex.EncodeToString(k.SKI()), PublicKey: pub, WrappedKey: wrapped}, nil
+}
+
+// OpenWrappedKey decrypts a wrapped key with the recipient's hybrid KEM
+// private key kemKey of csp
+func OpenWrappedKey(csp bccsp.BCCSP, kemKey bccsp.Key, wrapped []byte) (*hybridx509.PrivateKey, error) {
+	plain, err := csp.Decrypt(kemKey, wrapped, &hybrid.HybridKEMDecrypterOpts{KDFInfo: WrappedKeyInfo})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key: %w", err)
+	}
+	// The ECDSA block comes first, the PQC one after it
+	block, rest := pem.Decode(plain)
+	if block == nil {
+		return nil, errors.New("wrapped key has no PEM blocks")
+	}
+	return hybridx509.ParsePrivateKeyPEM(plain, rest)
+}
@@ -0,0 +1,231 @@
+// Package snapshot protects ledger snapshots used to bootstrap peers: a
+// manifest lists the SHA3-256 hash of every snapshot file and is signed with
+// the hybrid key of the peer that produced the snapshot.
+package snapshot
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hyperledger/fabric-lib-go/bccsp"
+	"github.com/yourusername/quantum-ledger/msp"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// ManifestFile is written next to the snapshot files
+	ManifestFile = "_snapshot_hybrid_manifest.json"
+	// SignableMetadataFile is the metadata file Fabric writes in every
+	// snapshot
+	SignableMetadataFile = "_snapshot_signable_metadata.json"
+	// HashAlgorithm is the algorithm of manifest hashes and signatures
+	HashAlgorithm = "SHA3-256"
+)
+
+var (
+	// ErrHashMismatch is returned when a file does not match the manifest
+	ErrHashMismatch = errors.New("snapshot file hash mismatch")
+	// ErrUnlistedFile is returned for files missing from the manifest
+	ErrUnlistedFile = errors.New("snapshot file not in manifest")
+	// ErrInvalidSignature is returned when the manifest signature is invalid
+	ErrInvalidSignature = errors.New("invalid manifest signature")
+)
+
+// Manifest describes a snapshot
+type Manifest struct {
+	ChannelName       string            `json:"channel_name"`
+	LastBlockNumber   uint64            `json:"last_block_number"`
+	LastBlockHash     string            `json:"last_block_hash"`
+	PreviousBlockHash string            `json:"previous_block_hash"`
+	HashAlgorithm     string            `json:"hash_algorithm"`
+	Files             map[string]string `json:"files"`
+}
+
+// SignedManifest is the content of ManifestFile. Manifest is kept as the
+// exact bytes that were signed.
+type SignedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signer    []byte          `json:"signer"`
+	Signature []byte          `json:"signature"`
+}
+
+// signableMetadata is the part of Fabric's snapshot metadata carried over
+type signableMetadata struct {
+	ChannelName       string `json:"channel_name"`
+	LastBlockNumber   uint64 `json:"last_block_number"`
+	LastBlockHash     string `json:"last_block_hash"`
+	PreviousBlockHash string `json:"previous_block_hash"`
+}
+
+// NewManifest hashes every file of the snapshot in dir
+func NewManifest(dir string) (*Manifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, SignableMetadataFile))
+	if err != nil {
+		return nil, fmt.Errorf("not a ledger snapshot: %w", err)
+	}
+	var meta signableMetadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SignableMetadataFile, err)
+	}
+
+	names, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		ChannelName:       meta.ChannelName,
+		LastBlockNumber:   meta.LastBlockNumber,
+		LastBlockHash:     meta.LastBlockHash,
+		PreviousBlockHash: meta.PreviousBlockHash,
+		HashAlgorithm:     HashAlgorithm,
+		Files:             map[string]string{},
+	}
+	for _, name := range names {
+		sum, err := hashFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		m.Files[name] = sum
+	}
+	return m, nil
+}
+
+// Sign signs m with id
+func Sign(m *Manifest, id *msp.SigningIdentity) (*SignedManifest, error) {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := id.SignHashed(raw, &bccsp.SHA3_256Opts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign snapshot manifest: %w", err)
+	}
+	return &SignedManifest{Manifest: raw, Signer: id.Serialize(), Signature: sig}, nil
+}
+
+// WriteManifest builds, signs and writes the manifest of the snapshot in dir
+func WriteManifest(dir string, id *msp.SigningIdentity) (*Manifest, error) {
+	m, err := NewManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := Sign(m, id)
+	if err != nil {
+		return nil, err
+	}
+	// Not indented: that would reformat the signed manifest bytes
+	raw, err := json.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+	return m, os.WriteFile(filepath.Join(dir, ManifestFile), raw, 0o644)
+}
+
+// Verifier checks snapshots against the identities trusted to produce them
+type Verifier struct {
+	Deserializer *msp.Deserializer
+	CSP          bccsp.BCCSP
+}
+
+// VerifyManifest checks the signature of signed and returns its manifest
+// and signer
+func (v *Verifier) VerifyManifest(signed *SignedManifest) (*Manifest, *msp.Identity, error) {
+	id, err := v.Deserializer.DeserializeIdentity(signed.Signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest signer: %w", err)
+	}
+	digest, err := v.CSP.Hash(signed.Manifest, &bccsp.SHA3_256Opts{})
+	if err != nil {
+		return nil, nil, err
+	}
+	valid, err := v.CSP.Verify(id.Key, signed.Signature, digest, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(signed.Manifest, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if m.HashAlgorithm != HashAlgorithm {
+		return nil, nil, fmt.Errorf("unsupported manifest hash algorithm %q", m.HashAlgorithm)
+	}
+	return &m, id, nil
+}
+
+// VerifyDir checks the manifest of the snapshot in dir and that the files
+// in dir are exactly the ones it lists, with the listed hashes
+func (v *Verifier) VerifyDir(dir string) (*Manifest, *msp.Identity, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	var signed SignedManifest
+	if err := json.Unmarshal(raw, &signed); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	m, id, err := v.VerifyManifest(&signed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		want, ok := m.Files[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: %w", name, ErrUnlistedFile)
+		}
+		got, err := hashFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, nil, err
+		}
+		if got != want {
+			return nil, nil, fmt.Errorf("%s: %w", name, ErrHashMismatch)
+		}
+	}
+	if len(names) != len(m.Files) {
+		return nil, nil, fmt.Errorf("snapshot has %d of %d manifest files", len(names), len(m.Files))
+	}
+	return m, id, nil
+}
+
+// snapshotFiles lists the regular files of dir other than the manifest
+func snapshotFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && e.Name() != ManifestFile {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha3.New256()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"crypto/x509/pkix"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid"
+	"github.com/yourusername/quantum-ledger/bccsp/hybrid/hybridx509"
+	"github.com/yourusername/quantum-ledger/ca"
+	"github.com/yourusername/quantum-ledger/msp"
+)
+
+func TestManifestSignVerify(t *testing.T) {
+	csp, err := hybrid.New()
+	require.NoError(t, err)
+	root, err := ca.NewRoot(pkix.Name{CommonName: "ca.org1.example.com"}, 0)
+	require.NoError(t, err)
+	peer, err := root.Issue(ca.Request{CommonName: "peer0.org1.example.com", OrganizationalUnit: "peer"})
+	require.NoError(t, err)
+	mspDir := filepath.Join(t.TempDir(), "msp")
+	require.NoError(t, ca.WriteMSP(mspDir, root, peer, ca.MSPOptions{}))
+	id, err := msp.LoadSigningIdentity(csp, mspDir, "Org1MSP")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		SignableMetadataFile: `{"channel_name":"mychannel","last_block_number":9,"last_block_hash":"aa","previous_block_hash":"bb"}`,
+		"public_state.data":  "state",
+		"txids.data":         "txids",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	m, err := WriteManifest(dir, id)
+	require.NoError(t, err)
+	assert.Equal(t, "mychannel", m.ChannelName)
+	assert.Equal(t, uint64(9), m.LastBlockNumber)
+	assert.Len(t, m.Files, 3)
+
+	v := &Verifier{
+		Deserializer: &msp.Deserializer{CSP: csp, CAs: map[string][]*hybridx509.Certificate{"Org1MSP": {root.Cert}}},
+		CSP:          csp,
+	}
+	_, signer, err := v.VerifyDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Org1MSP", signer.MSPID)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public_state.data"), []byte("tampered"), 0o644))
+	_, _, err = v.VerifyDir(dir)
+	assert.True(t, errors.Is(err, ErrHashMismatch), err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public_state.data"), []byte("state"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.data"), []byte("x"), 0o644))
+	_, _, err = v.VerifyDir(dir)
+	assert.True(t, errors.Is(err, ErrUnlistedFile), err)
+}
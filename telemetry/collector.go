@@ -0,0 +1,191 @@
+// Package telemetry collects measurements from the network under test
+// while a benchmark runs and joins them into the result dataset.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourusername/quantum-ledger/clock"
+)
+
+// Phase is a Fabric processing phase timed by peer operations metrics
+type Phase string
+
+const (
+	PhaseEndorsement Phase = "endorsement"
+	PhaseValidation  Phase = "validation"
+	PhaseCommit      Phase = "commit"
+)
+
+// Phases lists the phases in dataset column order
+var Phases = []Phase{PhaseEndorsement, PhaseValidation, PhaseCommit}
+
+// DefaultPhaseMetrics are the histograms, in seconds, that Fabric peers
+// expose on their operations endpoint for each phase
+var DefaultPhaseMetrics = map[Phase]string{
+	PhaseEndorsement: "endorser_proposal_duration",
+	PhaseValidation:  "ledger_block_processing_time",
+	PhaseCommit:      "ledger_blockstorage_commit_time",
+}
+
+// Target is the operations metrics endpoint of a peer or orderer
+type Target struct {
+	Name string
+	URL  string
+}
+
+// Sample is the mean duration of a phase on a node between two scrapes
+type Sample struct {
+	Time  time.Time
+	Node  string
+	Phase Phase
+	// Count is the number of observations in the interval
+	Count float64
+	Mean  time.Duration
+}
+
+// Collector scrapes phase histograms from its targets. Each scrape yields
+// the observations made since the previous one.
+type Collector struct {
+	Targets []Target
+	// Metrics maps phases to histogram names, DefaultPhaseMetrics if nil
+	Metrics map[Phase]string
+	Client  *http.Client
+	// Clock stamps samples and paces Run, clock.Real if nil
+	Clock clock.Clock
+	// Energy, when set, is read on every scrape; the consumption between
+	// scrapes is kept as EnergySamples
+	Energy EnergyMeter
+
+	mutex sync.Mutex
+	// last holds the previous _sum and _count per target and phase
+	last map[string]histogram
+	// lastEnergy is the previous energy reading, taken at lastEnergyTime
+	lastEnergy     float64
+	lastEnergyTime time.Time
+	energy         []EnergySample
+}
+
+type histogram struct {
+	sum, count float64
+}
+
+// Scrape reads every target, and the energy meter if any, once. The first
+// scrape of a target only sets the baseline and returns no samples for it.
+func (c *Collector) Scrape(ctx context.Context) ([]Sample, error) {
+	metrics := c.Metrics
+	if metrics == nil {
+		metrics = DefaultPhaseMetrics
+	}
+	var samples []Sample
+	for _, t := range c.Targets {
+		values, err := c.fetch(ctx, t.URL)
+		if err != nil {
+			return samples, fmt.Errorf("scrape %s: %w", t.Name, err)
+		}
+		now := clock.Or(c.Clock).Now()
+		c.mutex.Lock()
+		if c.last == nil {
+			c.last = map[string]histogram{}
+		}
+		for _, phase := range Phases {
+			name, ok := metrics[phase]
+			if !ok {
+				continue
+			}
+			key := t.Name + "/" + string(phase)
+			h := histogram{sum: values[name+"_sum"], count: values[name+"_count"]}
+			prev, seen := c.last[key]
+			c.last[key] = h
+			// No new observations, or counters reset by a restart
+			if !seen || h.count <= prev.count {
+				continue
+			}
+			count := h.count - prev.count
+			samples = append(samples, Sample{
+				Time:  now,
+				Node:  t.Name,
+				Phase: phase,
+				Count: count,
+				Mean:  time.Duration((h.sum - prev.sum) / count * float64(time.Second)),
+			})
+		}
+		c.mutex.Unlock()
+	}
+	if c.Energy != nil {
+		if err := c.scrapeEnergy(); err != nil {
+			return samples, fmt.Errorf("scrape energy: %w", err)
+		}
+	}
+	return samples, nil
+}
+
+// scrapeEnergy records the energy consumed since the previous reading
+func (c *Collector) scrapeEnergy() error {
+	joules, err := c.Energy.Energy()
+	if err != nil {
+		return err
+	}
+	now := clock.Or(c.Clock).Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.lastEnergyTime.IsZero() {
+		c.energy = append(c.energy, EnergySample{
+			Time:     now,
+			Interval: now.Sub(c.lastEnergyTime),
+			Joules:   joules - c.lastEnergy,
+		})
+	}
+	c.lastEnergy, c.lastEnergyTime = joules, now
+	return nil
+}
+
+// EnergySamples returns the energy consumed between scrapes so far
+func (c *Collector) EnergySamples() []EnergySample {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]EnergySample(nil), c.energy...)
+}
+
+// Run scrapes every interval until ctx is done and returns all samples.
+// Failed scrapes are reported to onError, if set, and skipped.
+func (c *Collector) Run(ctx context.Context, interval time.Duration, onError func(error)) []Sample {
+	clk := clock.Or(c.Clock)
+	var all []Sample
+	next := clk.Now()
+	for {
+		samples, err := c.Scrape(ctx)
+		all = append(all, samples...)
+		if err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		next = next.Add(interval)
+		if clock.SleepUntil(ctx, clk, next) != nil {
+			return all
+		}
+	}
+}
+
+func (c *Collector) fetch(ctx context.Context, url string) (map[string]float64, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ParseMetrics(resp.Body)
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sampleColumns is the header written by WriteSamplesCSV
+var sampleColumns = []string{"timestamp_epoch_ms", "run_id", "node", "operation_phase", "count", "duration_ms"}
+
+// PhaseColumn is the dataset column Join fills with the duration of phase
+func PhaseColumn(phase Phase) string {
+	return string(phase) + "_duration_ms"
+}
+
+// WriteSamplesCSV writes the samples collected during run
+func WriteSamplesCSV(w io.Writer, run int, samples []Sample) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(sampleColumns); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		err := cw.Write([]string{
+			strconv.FormatInt(s.Time.UnixMilli(), 10),
+			strconv.Itoa(run),
+			s.Node,
+			string(s.Phase),
+			strconv.FormatFloat(s.Count, 'f', -1, 64),
+			formatMillis(s.Mean),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// scrape is the mean duration per phase over the nodes of one scrape
+type scrape struct {
+	time  time.Time
+	means map[Phase]time.Duration
+}
+
+// Join copies the dataset CSV src to dst with a PhaseColumn per phase.
+// Rows are matched to the samples of their run_id, RUN<N> or N, and to the
+// first scrape at or after their timestamp_epoch_ms, whose interval covers
+// them; rows without a timestamp get the mean over the run. Means are
+// weighted by the observations of each node.
+func Join(dst io.Writer, src io.Reader, runs map[int][]Sample) error {
+	reader := csv.NewReader(src)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty dataset")
+		}
+		return err
+	}
+	runCol, timeCol := -1, -1
+	for i, col := range header {
+		switch strings.TrimSpace(col) {
+		case "run_id":
+			runCol = i
+		case "timestamp_epoch_ms":
+			timeCol = i
+		}
+	}
+	if runCol < 0 {
+		return errors.New("dataset has no run_id column")
+	}
+
+	scrapes := map[int][]scrape{}
+	overall := map[int]map[Phase]time.Duration{}
+	for run, samples := range runs {
+		scrapes[run] = groupScrapes(samples)
+		overall[run] = weightedMeans(samples)
+	}
+
+	w := csv.NewWriter(dst)
+	out := append([]string(nil), header...)
+	for _, phase := range Phases {
+		out = append(out, PhaseColumn(phase))
+	}
+	if err := w.Write(out); err != nil {
+		return err
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		run, err := strconv.Atoi(strings.TrimPrefix(record[runCol], "RUN"))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid run_id %q", line, record[runCol])
+		}
+		means := overall[run]
+		if timeCol >= 0 {
+			if ms, err := strconv.ParseInt(record[timeCol], 10, 64); err == nil {
+				means = coveringScrape(scrapes[run], time.UnixMilli(ms), means)
+			}
+		}
+		out = append(out[:0], record...)
+		for _, phase := range Phases {
+			if d, ok := means[phase]; ok {
+				out = append(out, formatMillis(d))
+			} else {
+				out = append(out, "")
+			}
+		}
+		if err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func groupScrapes(samples []Sample) []scrape {
+	byTime := map[time.Time][]Sample{}
+	for _, s := range samples {
+		byTime[s.Time] = append(byTime[s.Time], s)
+	}
+	scrapes := make([]scrape, 0, len(byTime))
+	for t, group := range byTime {
+		scrapes = append(scrapes, scrape{time: t, means: weightedMeans(group)})
+	}
+	sort.Slice(scrapes, func(i, j int) bool { return scrapes[i].time.Before(scrapes[j].time) })
+	return scrapes
+}
+
+func weightedMeans(samples []Sample) map[Phase]time.Duration {
+	total := map[Phase]float64{}
+	counts := map[Phase]float64{}
+	for _, s := range samples {
+		total[s.Phase] += float64(s.Mean) * s.Count
+		counts[s.Phase] += s.Count
+	}
+	means := map[Phase]time.Duration{}
+	for phase, n := range counts {
+		means[phase] = time.Duration(total[phase] / n)
+	}
+	return means
+}
+
+// coveringScrape returns the means of the first scrape at or after t,
+// or fallback for times after the last one
+func coveringScrape(scrapes []scrape, t time.Time, fallback map[Phase]time.Duration) map[Phase]time.Duration {
+	i := sort.Search(len(scrapes), func(i int) bool { return !scrapes[i].time.Before(t) })
+	if i == len(scrapes) {
+		return fallback
+	}
+	return scrapes[i].means
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PowercapRoot is where Linux exposes RAPL energy counters
+const PowercapRoot = "/sys/class/powercap"
+
+// ErrNoRAPL is returned on hosts without readable RAPL package domains
+var ErrNoRAPL = errors.New("no RAPL package domains in powercap")
+
+// EnergyMeter reads the energy consumed by the host since it was opened
+type EnergyMeter interface {
+	Energy() (joules float64, err error)
+}
+
+// packageZone matches top-level RAPL zones; their subzones (core, uncore,
+// dram on some parts) are included in the package count
+var packageZone = regexp.MustCompile(`^intel-rapl:\d+$`)
+
+// raplZone is the energy counter of one CPU package
+type raplZone struct {
+	name string
+	path string
+	// max is the counter range in microjoules, it wraps to zero past it
+	max  uint64
+	last uint64
+}
+
+// RAPL is an EnergyMeter summing the package domains of the powercap
+// framework, which Intel and recent AMD CPUs expose. Counters wrap after a
+// few minutes at full load on large parts, so Energy must be called more
+// often than that.
+type RAPL struct {
+	mutex sync.Mutex
+	zones []*raplZone
+	// total is the energy counted since OpenRAPL in microjoules
+	total uint64
+}
+
+// OpenRAPL finds the package domains under root, PowercapRoot if empty.
+// Reading the counters usually requires root since Linux 5.10.
+func OpenRAPL(root string) (*RAPL, error) {
+	if root == "" {
+		root = PowercapRoot
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoRAPL
+		}
+		return nil, err
+	}
+	r := &RAPL{}
+	for _, e := range entries {
+		if !packageZone.MatchString(e.Name()) {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		z := &raplZone{name: e.Name(), path: filepath.Join(dir, "energy_uj")}
+		if name, err := readString(filepath.Join(dir, "name")); err == nil {
+			z.name = name
+		}
+		if z.max, err = readUint(filepath.Join(dir, "max_energy_range_uj")); err != nil {
+			return nil, fmt.Errorf("%s: %w", z.name, err)
+		}
+		if z.last, err = readUint(z.path); err != nil {
+			return nil, fmt.Errorf("%s: %w", z.name, err)
+		}
+		r.zones = append(r.zones, z)
+	}
+	if len(r.zones) == 0 {
+		return nil, ErrNoRAPL
+	}
+	return r, nil
+}
+
+// Zones returns the names of the package domains, e.g. package-0
+func (r *RAPL) Zones() []string {
+	names := make([]string, len(r.zones))
+	for i, z := range r.zones {
+		names[i] = z.name
+	}
+	return names
+}
+
+// Energy returns the Joules consumed by all packages since OpenRAPL
+func (r *RAPL) Energy() (float64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, z := range r.zones {
+		v, err := readUint(z.path)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", z.name, err)
+		}
+		if v >= z.last {
+			r.total += v - z.last
+		} else {
+			r.total += z.max - z.last + v
+		}
+		z.last = v
+	}
+	return float64(r.total) / 1e6, nil
+}
+
+func readString(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	return strings.TrimSpace(string(b)), err
+}
+
+func readUint(path string) (uint64, error) {
+	s, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// EnergySample is the energy the collecting host consumed between two
+// scrapes
+type EnergySample struct {
+	Time     time.Time
+	Interval time.Duration
+	Joules   float64
+}
+
+// Watts is the mean power over the interval
+func (s EnergySample) Watts() float64 {
+	if s.Interval <= 0 {
+		return 0
+	}
+	return s.Joules / s.Interval.Seconds()
+}
+
+// energyColumns is the header written by WriteEnergyCSV
+var energyColumns = []string{"timestamp_epoch_ms", "run_id", "interval_ms", "energy_j", "power_w"}
+
+// WriteEnergyCSV writes the energy samples collected during run
+func WriteEnergyCSV(w io.Writer, run int, samples []EnergySample) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(energyColumns); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		err := cw.Write([]string{
+			strconv.FormatInt(s.Time.UnixMilli(), 10),
+			strconv.Itoa(run),
+			formatMillis(s.Interval),
+			strconv.FormatFloat(s.Joules, 'f', 6, 64),
+			strconv.FormatFloat(s.Watts(), 'f', 3, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
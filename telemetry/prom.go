@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseMetrics reads the Prometheus text exposition format and returns
+// the value of every metric, summed over its label sets. Histograms appear
+// as their _sum, _count and _bucket series.
+func ParseMetrics(r io.Reader) (map[string]float64, error) {
+	values := map[string]float64{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		name, rest, err := splitSeries(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: missing value", line)
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		values[name] += v
+	}
+	return values, scanner.Err()
+}
+
+// splitSeries splits a sample line into the metric name and what follows
+// its labels
+func splitSeries(line string) (string, string, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return "", "", fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:end], line[end:]
+	if rest[0] != '{' {
+		return name, rest, nil
+	}
+	quoted := false
+	for i := 1; i < len(rest); i++ {
+		switch {
+		case rest[i] == '\\' && quoted:
+			i++
+		case rest[i] == '"':
+			quoted = !quoted
+		case rest[i] == '}' && !quoted:
+			return name, rest[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated labels in %q", line)
+}
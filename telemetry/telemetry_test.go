@@ -0,0 +1,191 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/clock"
+)
+
+func TestParseMetrics(t *testing.T) {
+	text := `# HELP endorser_proposal_duration The time to complete a proposal.
+# TYPE endorser_proposal_duration histogram
+endorser_proposal_duration_bucket{chaincode="basic",le="0.005"} 3
+endorser_proposal_duration_sum{channel="mychannel",chaincode="basic",success="true"} 0.25
+endorser_proposal_duration_sum{channel="odd}\"name",chaincode="basic",success="false"} 0.05
+endorser_proposal_duration_count{channel="mychannel",chaincode="basic",success="true"} 10
+ledger_block_processing_time_count 4 1700000000000
+up 1
+`
+	values, err := ParseMetrics(strings.NewReader(text))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.30, values["endorser_proposal_duration_sum"], 1e-9)
+	assert.Equal(t, 10.0, values["endorser_proposal_duration_count"])
+	assert.Equal(t, 4.0, values["ledger_block_processing_time_count"])
+	assert.Equal(t, 1.0, values["up"])
+
+	for _, bad := range []string{`x{a="b" 1`, `x`, `x abc`, `{a="b"} 1`} {
+		_, err := ParseMetrics(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+// peerMetrics serves endorsement histograms advancing by 10 proposals of
+// 2ms, and by 5ms blocks, per scrape
+func peerMetrics(scrapes *atomic.Int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := float64(scrapes.Add(1))
+		fmt.Fprintf(w, "endorser_proposal_duration_sum %g\n", n*10*0.002)
+		fmt.Fprintf(w, "endorser_proposal_duration_count %g\n", n*10)
+		fmt.Fprintf(w, "ledger_block_processing_time_sum %g\n", n*0.005)
+		fmt.Fprintf(w, "ledger_block_processing_time_count %g\n", n)
+	})
+}
+
+func TestCollector(t *testing.T) {
+	var scrapes atomic.Int64
+	srv := httptest.NewServer(peerMetrics(&scrapes))
+	defer srv.Close()
+	c := &Collector{Targets: []Target{{Name: "peer0", URL: srv.URL}}}
+
+	samples, err := c.Scrape(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, samples, "the first scrape sets the baseline")
+	samples, err = c.Scrape(context.Background())
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, PhaseEndorsement, samples[0].Phase)
+	assert.Equal(t, 10.0, samples[0].Count)
+	assert.InDelta(t, 2*time.Millisecond, samples[0].Mean, float64(time.Microsecond))
+	assert.Equal(t, PhaseValidation, samples[1].Phase)
+	assert.InDelta(t, 5*time.Millisecond, samples[1].Mean, float64(time.Microsecond))
+
+	// Three more scrapes five virtual seconds apart
+	start := time.Unix(1_700_000_000, 0)
+	v := clock.NewVirtual(start)
+	c.Clock = v
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []Sample)
+	go func() { done <- c.Run(ctx, 5*time.Second, func(err error) { t.Error(err) }) }()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, v.BlockUntil(context.Background(), 1))
+		v.AdvanceToNext()
+	}
+	require.NoError(t, v.BlockUntil(context.Background(), 1))
+	cancel()
+	all := <-done
+	require.Len(t, all, 6)
+	assert.Equal(t, start, all[0].Time)
+	assert.Equal(t, start.Add(10*time.Second), all[5].Time)
+
+	srv.Config.Handler = http.NotFoundHandler()
+	_, err = c.Scrape(context.Background())
+	assert.ErrorContains(t, err, "scrape peer0")
+}
+
+func TestJoin(t *testing.T) {
+	t0 := time.UnixMilli(1_700_000_000_000)
+	samples := []Sample{
+		{Time: t0, Node: "peer0", Phase: PhaseEndorsement, Count: 10, Mean: 2 * time.Millisecond},
+		{Time: t0, Node: "peer1", Phase: PhaseEndorsement, Count: 30, Mean: 4 * time.Millisecond},
+		{Time: t0.Add(5 * time.Second), Node: "peer0", Phase: PhaseEndorsement, Count: 10, Mean: 8 * time.Millisecond},
+		{Time: t0.Add(5 * time.Second), Node: "peer0", Phase: PhaseCommit, Count: 1, Mean: time.Millisecond},
+	}
+	dataset := "timestamp_epoch_ms,run_id,crypto_mode,latency_e2e_ms\n" +
+		fmt.Sprintf("%d,RUN1,HYBRID,10\n", t0.Add(-time.Second).UnixMilli()) +
+		fmt.Sprintf("%d,1,HYBRID,11\n", t0.Add(2*time.Second).UnixMilli()) +
+		fmt.Sprintf("%d,1,HYBRID,12\n", t0.Add(time.Minute).UnixMilli()) +
+		"x,2,HYBRID,13\n"
+
+	var out bytes.Buffer
+	require.NoError(t, Join(&out, strings.NewReader(dataset), map[int][]Sample{1: samples}))
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Equal(t, []string{"endorsement_duration_ms", "validation_duration_ms", "commit_duration_ms"}, rows[0][4:])
+	assert.Equal(t, []string{"3.500", "", ""}, rows[1][4:], "first scrape, weighted over peers")
+	assert.Equal(t, []string{"8.000", "", "1.000"}, rows[2][4:])
+	assert.Equal(t, []string{"4.400", "", "1.000"}, rows[3][4:], "after the last scrape: mean over the run")
+	assert.Equal(t, []string{"", "", ""}, rows[4][4:], "no samples for run 2")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSamplesCSV(&buf, 1, samples))
+	assert.Contains(t, buf.String(), "1700000000000,1,peer1,endorsement,30,4.000\n")
+
+	err = Join(&out, strings.NewReader("a,b\n1,2\n"), nil)
+	assert.ErrorContains(t, err, "run_id")
+}
+
+// writeZone writes a powercap zone with the given counter
+func writeZone(t *testing.T, root, zone, name string, max, energy uint64) {
+	dir := filepath.Join(root, zone)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "name"), []byte(name+"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "max_energy_range_uj"), []byte(fmt.Sprintln(max)), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "energy_uj"), []byte(fmt.Sprintln(energy)), 0o644))
+}
+
+func TestRAPL(t *testing.T) {
+	root := t.TempDir()
+	writeZone(t, root, "intel-rapl:0", "package-0", 1000000, 900000)
+	writeZone(t, root, "intel-rapl:1", "package-1", 1000000, 0)
+	// Subzones are part of their package and not counted twice
+	writeZone(t, root, "intel-rapl:0:0", "core", 1000000, 0)
+
+	r, err := OpenRAPL(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"package-0", "package-1"}, r.Zones())
+
+	// package-0 wraps past its range
+	writeZone(t, root, "intel-rapl:0", "package-0", 1000000, 100000)
+	writeZone(t, root, "intel-rapl:1", "package-1", 1000000, 500000)
+	j, err := r.Energy()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7, j, 1e-9)
+
+	_, err = OpenRAPL(t.TempDir())
+	assert.ErrorIs(t, err, ErrNoRAPL)
+	_, err = OpenRAPL(filepath.Join(root, "missing"))
+	assert.ErrorIs(t, err, ErrNoRAPL)
+}
+
+// fakeMeter consumes 10 J per reading
+type fakeMeter struct{ readings float64 }
+
+func (m *fakeMeter) Energy() (float64, error) {
+	m.readings++
+	return 10 * m.readings, nil
+}
+
+func TestCollectorEnergy(t *testing.T) {
+	v := clock.NewVirtual(time.Unix(0, 0))
+	c := &Collector{Energy: &fakeMeter{}, Clock: v}
+	for i := 0; i < 3; i++ {
+		_, err := c.Scrape(context.Background())
+		require.NoError(t, err)
+		v.Advance(2 * time.Second)
+	}
+	samples := c.EnergySamples()
+	require.Len(t, samples, 2, "the first scrape is the baseline")
+	assert.Equal(t, 10.0, samples[0].Joules)
+	assert.Equal(t, 2*time.Second, samples[0].Interval)
+	assert.Equal(t, 5.0, samples[1].Watts())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEnergyCSV(&buf, 3, samples))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2000", "3", "2000.000", "10.000000", "5.000"}, rows[1])
+}
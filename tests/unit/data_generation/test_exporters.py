@@ -120,3 +120,55 @@ def test_filename_generation_only_csvexporter(config):
 
 
 
+
+
+# ==============================================================================
+# TEST: DETERMINISTIC OUTPUT
+# ==============================================================================
+
+def test_format_value_fixed_precision():
+    """Floats use fixed-point notation and never a negative zero"""
+    assert exporters.format_value(1e-7) == "0.000"
+    assert exporters.format_value(-1e-7) == "0.000"
+    assert exporters.format_value(12345678.9, decimal_precision=1) == "12345678.9"
+    assert exporters.format_value(2.5) == "2.500"
+    assert exporters.format_value(7) == "7"
+    assert exporters.format_value(True) == "True"
+
+
+def test_export_is_byte_identical(sample_data, temp_output_dir, config):
+    """Exporting the same samples twice gives the same bytes"""
+    exporter = exporters.CSVExporter(config, output_dir=temp_output_dir)
+    first = Path(exporter.export_samples(sample_data, "a.csv")).read_bytes()
+    second = Path(exporter.export_samples(list(sample_data), "b.csv")).read_bytes()
+    assert first == second
+    assert b"\r\n" not in first
+
+
+def test_format_value_unrounded():
+    """Without a precision, floats keep every digit"""
+    assert exporters.format_value(0.1 + 0.2, decimal_precision=None) == "0.30000000000000004"
+    assert exporters.format_value(1e-7, decimal_precision=None) == "1e-07"
+    assert exporters.format_value(7, decimal_precision=None) == "7"
+
+
+def test_dump_json_canonical(temp_output_dir):
+    """JSON metadata has sorted keys, unrounded floats and LF line endings"""
+    path = os.path.join(temp_output_dir, "meta.json")
+    exporters.dump_json({"b": 0.1 + 0.2, "a": [1, -0.0000001]}, path)
+    with open(path, "rb") as f:
+        raw = f.read()
+    assert raw == b'{\n  "a": [\n    1,\n    -1e-07\n  ],\n  "b": 0.30000000000000004\n}\n'
+
+    reordered = os.path.join(temp_output_dir, "meta2.json")
+    exporters.dump_json({"a": [1, -0.0000001], "b": 0.30000000000000004}, reordered)
+    with open(reordered, "rb") as f:
+        assert f.read() == raw
+
+
+def test_dump_json_rounding_is_opt_in(temp_output_dir):
+    """Floats are rounded only when a precision is given"""
+    path = os.path.join(temp_output_dir, "meta.json")
+    exporters.dump_json({"b": 0.1 + 0.2, "a": [1, -0.0000001]}, path, decimal_precision=3)
+    with open(path, "rb") as f:
+        assert f.read() == b'{\n  "a": [\n    1,\n    0.0\n  ],\n  "b": 0.3\n}\n'
@@ -6,15 +6,19 @@ This module is a wrapper around CSV utilities and CSVExporter class.
 
 from tools.data_generation.utils.csv_utils import (
     CSVExporter,
+    dump_json,
     format_value,
     get_quoting_constant,
-    generate_filename
+    generate_filename,
+    round_floats
 )
 
 
 __all__ = [
     "CSVExporter",
+    "dump_json",
     "format_value",
     "get_quoting_constant",
     "generate_filename",
+    "round_floats",
 ]
@@ -8,6 +8,8 @@ from typing import Dict, List, Any, Optional
 import yaml
 from pathlib import Path
 
+from tools.data_generation.utils.csv_utils import dump_json, format_value
+
 
 class MonteCarloGenerator:
     """
@@ -153,12 +155,18 @@ class MonteCarloGenerator:
         print(f"✅ Validation passed for {len(self.samples)} samples")
         return True
     
-    def export_csv(self, output_path: str) -> str:
+    def export_csv(self, output_path: str, decimal_precision: Optional[int] = None) -> str:
         """
         Export samples to CSV file.
         
+        Floats are written in full, as the shortest text that reads back to
+        the same value, so exports of the same seed are byte-identical across
+        machines.
+        
         Args:
             output_path: Path to output CSV file
+            decimal_precision: Round float values to this many decimals,
+                unrounded if None
         
         Returns:
             Full path to created file
@@ -174,20 +182,26 @@ class MonteCarloGenerator:
         # Get all unique keys from samples
         fieldnames = list(self.samples[0].keys())
         
-        with open(path, 'w', newline='') as csvfile:
-            writer = csv.DictWriter(csvfile, fieldnames=fieldnames)
+        with open(path, 'w', newline='', encoding='utf-8') as csvfile:
+            writer = csv.DictWriter(csvfile, fieldnames=fieldnames, lineterminator='\n')
             writer.writeheader()
-            writer.writerows(self.samples)
+            for sample in self.samples:
+                writer.writerow({k: format_value(v, decimal_precision) for k, v in sample.items()})
         
         print(f"📊 Exported {len(self.samples)} samples to {path}")
         return str(path.absolute())
     
-    def export_json(self, output_path: str) -> str:
+    def export_json(self, output_path: str, decimal_precision: Optional[int] = None) -> str:
         """
         Export samples to JSON file.
         
+        Keys are sorted, so exports of the same seed are byte-identical
+        across machines.
+        
         Args:
             output_path: Path to output JSON file
+            decimal_precision: Round float values to this many decimals,
+                unrounded if None
         
         Returns:
             Full path to created file
@@ -195,13 +209,10 @@ class MonteCarloGenerator:
         if not self.samples:
             raise ValueError("No samples to export. Run generate() first.")
         
-        import json
-        
         path = Path(output_path)
         path.parent.mkdir(parents=True, exist_ok=True)
         
-        with open(path, 'w') as f:
-            json.dump(self.samples, f, indent=2)
+        dump_json(self.samples, str(path), decimal_precision)
         
         print(f"📄 Exported {len(self.samples)} samples to {path}")
         return str(path.absolute())
@@ -6,9 +6,11 @@ quoting, and exporting benchmark data to CSV.
 """
 
 import csv
+import json
+import numbers
 import os
 from pathlib import Path
-from typing import Any, List, Dict
+from typing import Any, List, Dict, Optional
 
 
 # ==============================================================================
@@ -26,13 +28,56 @@ def get_quoting_constant(quoting_str: str) -> int:
     return mapping.get(quoting_str.lower(), csv.QUOTE_MINIMAL)
 
 
-def format_value(value: Any, decimal_precision: int = 3, column_name: str = None) -> str:
-    """Format a value for CSV output with proper precision."""
-    if isinstance(value, float):
-        return f"{value:.{decimal_precision}f}"
+def _is_float(value: Any) -> bool:
+    """True for Python and numpy floats: numpy.float64 subclasses float, but
+    numpy.float32 and numpy.float16 do not."""
+    return isinstance(value, numbers.Real) and not isinstance(value, numbers.Integral)
+
+
+def format_value(value: Any, decimal_precision: Optional[int] = 3, column_name: str = None) -> str:
+    """Format a value for CSV output with proper precision.
+
+    Floats use fixed-point notation with decimal_precision decimals, and
+    negative zero is written as zero, so the same sample gives the same
+    bytes on every machine. With decimal_precision None, floats are written
+    unrounded, as the shortest text that reads back to the same value.
+    """
+    if _is_float(value):
+        if decimal_precision is None:
+            return repr(float(value))
+        text = f"{float(value):.{decimal_precision}f}"
+        if float(text) == 0:
+            text = text.lstrip("-")
+        return text
     return str(value)
 
 
+def round_floats(obj: Any, decimal_precision: Optional[int] = None) -> Any:
+    """Convert the numpy scalars of a JSON-like structure to Python numbers,
+    rounding floats to decimal_precision decimals if it is not None."""
+    if isinstance(obj, dict):
+        return {str(k): round_floats(v, decimal_precision) for k, v in obj.items()}
+    if isinstance(obj, (list, tuple)):
+        return [round_floats(v, decimal_precision) for v in obj]
+    if isinstance(obj, bool):
+        return obj
+    if isinstance(obj, numbers.Integral):
+        return int(obj)
+    if _is_float(obj):
+        if decimal_precision is None:
+            return float(obj)
+        return float(format_value(obj, decimal_precision))
+    return obj
+
+
+def dump_json(obj: Any, path: str, decimal_precision: Optional[int] = None) -> None:
+    """Write obj as canonical JSON: sorted keys and LF line endings, floats
+    rounded to decimal_precision decimals if it is not None."""
+    with open(path, 'w', newline='\n', encoding='utf-8') as f:
+        json.dump(round_floats(obj, decimal_precision), f, indent=2, sort_keys=True)
+        f.write('\n')
+
+
 def generate_filename(
     crypto_mode: str,
     load_profile: str,
@@ -0,0 +1,72 @@
+#!/bin/bash
+# Checks every build profile, so a change that only compiles with the
+# default backend is caught before downstream consumers pick another one.
+#
+#   tools/scripts/build_matrix.sh [profiles...]
+#
+#   oqs         liboqs through cgo, the default without tags
+#   purego      crypto/mldsa and crypto/mlkem, CGO_ENABLED=0 (Go 1.27+)
+#   verifyonly  crypto/mldsa verification only, CGO_ENABLED=0 (Go 1.27+);
+#               only the packages that never sign are tested
+#
+# Profiles default to all three. Each one is vetted and tested in both
+# modules; then every pair of profile tags must fail to build.
+set -euo pipefail
+
+if [ $# -eq 0 ]; then
+    set -- oqs purego verifyonly
+fi
+PROFILES=("$@")
+CORE=github.com/yourusername/quantum-ledger/core
+
+# Packages whose tests only verify, run in verifyonly builds
+VERIFY_ONLY_TESTS=(./bccsp/hybrid/testvectors/ "$CORE/wasmverify")
+
+status=0
+check() {
+    local name=$1
+    shift
+    if "$@"; then
+        echo "✅ $name"
+    else
+        echo "❌ $name" >&2
+        status=1
+    fi
+}
+
+for profile in "${PROFILES[@]}"; do
+    case "$profile" in
+    oqs)
+        check "oqs vet" env CGO_ENABLED=1 go vet -tags oqs ./... "$CORE/..."
+        check "oqs test" env CGO_ENABLED=1 go test -tags oqs ./... "$CORE/..."
+        ;;
+    purego)
+        check "purego vet" env CGO_ENABLED=0 go vet -tags purego ./... "$CORE/..."
+        check "purego test" env CGO_ENABLED=0 go test -tags purego ./... "$CORE/..."
+        ;;
+    verifyonly)
+        check "verifyonly vet" env CGO_ENABLED=0 go vet -tags verifyonly ./... "$CORE/..."
+        check "verifyonly test" env CGO_ENABLED=0 go test -tags verifyonly "${VERIFY_ONLY_TESTS[@]}"
+        check "verifyonly core test" env CGO_ENABLED=0 go test -tags verifyonly -run VerifyOnly "$CORE"
+        ;;
+    *)
+        echo "usage: $0 [oqs|purego|verifyonly...]" >&2
+        exit 2
+        ;;
+    esac
+done
+
+# Two backends at once must not compile
+for tags in oqs,purego oqs,verifyonly purego,verifyonly; do
+    if out=$(CGO_ENABLED=0 go build -tags "$tags" "$CORE" 2>&1); then
+        echo "❌ -tags $tags builds" >&2
+        status=1
+    elif grep -q selectExactlyOneOfTheOqsPuregoVerifyonlyBuildTags <<< "$out"; then
+        echo "✅ -tags $tags rejected"
+    else
+        echo "❌ -tags $tags failed without the backend conflict error:" >&2
+        echo "$out" >&2
+        status=1
+    fi
+done
+exit $status
@@ -0,0 +1,51 @@
+#!/bin/bash
+# Builds the Android and iOS bindings of qlmobile: receipt and signature
+# verification, hybrid KEM decryption.
+#
+#   tools/scripts/build_mobile.sh [android|ios]...
+#
+# Builds both platforms without arguments. The library is built with the
+# purego tag (Go 1.27+), so no liboqs has to be cross-compiled. Needs
+# gomobile and golang.org/x/mobile/bind in the module:
+#   go install golang.org/x/mobile/cmd/gomobile@latest && gomobile init
+#   go get golang.org/x/mobile/bind
+# Environment:
+#   OUT                  output directory (bin/mobile)
+#   ANDROID_API          minimum Android API level (24)
+#   JAVA_PKG             Java package prefix (org.quantumledger)
+#   IOS_PREFIX           Objective-C class prefix (QL)
+set -euo pipefail
+
+OUT=${OUT:-bin/mobile}
+ANDROID_API=${ANDROID_API:-24}
+JAVA_PKG=${JAVA_PKG:-org.quantumledger}
+IOS_PREFIX=${IOS_PREFIX:-QL}
+PKG=./qlmobile
+
+if ! command -v gomobile > /dev/null; then
+    echo "❌ gomobile not found, see the header of $0" >&2
+    exit 1
+fi
+
+platforms=("$@")
+[ ${#platforms[@]} -eq 0 ] && platforms=(android ios)
+
+mkdir -p "$OUT"
+for platform in "${platforms[@]}"; do
+    case "$platform" in
+    android)
+        gomobile bind -target android -androidapi "$ANDROID_API" -javapkg "$JAVA_PKG" \
+            -tags purego -trimpath -ldflags="-s -w" -o "$OUT/qlmobile.aar" "$PKG"
+        echo "✓ $OUT/qlmobile.aar"
+        ;;
+    ios)
+        gomobile bind -target ios,iossimulator -prefix "$IOS_PREFIX" \
+            -tags purego -trimpath -ldflags="-s -w" -o "$OUT/Qlmobile.xcframework" "$PKG"
+        echo "✓ $OUT/Qlmobile.xcframework"
+        ;;
+    *)
+        echo "unknown platform $platform, expected android or ios" >&2
+        exit 2
+        ;;
+    esac
+done
@@ -0,0 +1,100 @@
+#!/bin/bash
+# Builds self-contained binaries that run in distroless images, without a
+# liboqs.so to install and keep in sync on every node.
+#
+#   tools/scripts/build_static.sh [liboqs|purego|verifyonly] [packages...]
+#
+#   liboqs      (default) builds liboqs as a static archive without OpenSSL
+#               and links it, with libc, into static cgo binaries
+#   purego      builds with CGO_ENABLED=0 and the purego tag, which replaces
+#               liboqs with crypto/mldsa and crypto/mlkem (Go 1.27+)
+#   verifyonly  like purego, with the verifyonly tag: binaries verify hybrid
+#               signatures but cannot produce PQC ones
+#
+# Packages default to ./cmd/... of the current module; run it from a Fabric
+# checkout with ./cmd/peer to build the peer. Environment:
+#   OUT             output directory (bin/static)
+#   LIBOQS_VERSION  liboqs tag to build (0.10.1)
+#   OQS_PREFIX      install prefix of the static liboqs (build/liboqs-static)
+#   EXTRA_LDFLAGS   appended to -ldflags, e.g. Fabric's version metadata
+set -euo pipefail
+
+MODE=${1:-liboqs}
+shift || true
+PKGS=("${@:-./cmd/...}")
+OUT=${OUT:-bin/static}
+LIBOQS_VERSION=${LIBOQS_VERSION:-0.10.1}
+OQS_PREFIX=${OQS_PREFIX:-$PWD/build/liboqs-static}
+EXTRA_LDFLAGS=${EXTRA_LDFLAGS:-}
+
+mkdir -p "$OUT"
+
+build_liboqs() {
+    if [ -f "$OQS_PREFIX/lib/liboqs.a" ]; then
+        echo "Using static liboqs in $OQS_PREFIX"
+        return
+    fi
+    local src
+    src=$(mktemp -d)
+    git clone --depth 1 --branch "$LIBOQS_VERSION" https://github.com/open-quantum-safe/liboqs.git "$src"
+    # OQS_DIST_BUILD keeps runtime CPU dispatch, so one binary serves every
+    # host of the architecture; without OpenSSL there is nothing else to link
+    cmake -S "$src" -B "$src/build" -GNinja \
+        -DCMAKE_INSTALL_PREFIX="$OQS_PREFIX" \
+        -DCMAKE_INSTALL_LIBDIR=lib \
+        -DBUILD_SHARED_LIBS=OFF \
+        -DOQS_DIST_BUILD=ON \
+        -DOQS_USE_OPENSSL=OFF \
+        -DOQS_BUILD_ONLY_LIB=ON
+    cmake --build "$src/build" --target install
+    rm -rf "$src"
+}
+
+# liboqs-go resolves its flags through pkg-config; this file points it at
+# the archive instead of -loqs, which the linker would resolve to liboqs.so
+write_pkgconfig() {
+    mkdir -p "$OQS_PREFIX/lib/pkgconfig"
+    cat > "$OQS_PREFIX/lib/pkgconfig/liboqs-go.pc" << PC
+prefix=$OQS_PREFIX
+libdir=\${prefix}/lib
+includedir=\${prefix}/include
+
+Name: liboqs-go
+Description: static liboqs for liboqs-go
+Version: $LIBOQS_VERSION
+Cflags: -I\${includedir}
+Libs: \${libdir}/liboqs.a
+PC
+}
+
+case "$MODE" in
+liboqs)
+    build_liboqs
+    write_pkgconfig
+    # netgo and osusergo avoid the glibc NSS functions, which cannot be
+    # linked statically
+    CGO_ENABLED=1 PKG_CONFIG_PATH="$OQS_PREFIX/lib/pkgconfig" go build -trimpath \
+        -tags netgo,osusergo \
+        -ldflags "-linkmode external -extldflags '-static' $EXTRA_LDFLAGS" \
+        -o "$OUT/" "${PKGS[@]}"
+    ;;
+purego | verifyonly)
+    CGO_ENABLED=0 go build -trimpath -tags "$MODE" -ldflags "$EXTRA_LDFLAGS" -o "$OUT/" "${PKGS[@]}"
+    ;;
+*)
+    echo "usage: $0 [liboqs|purego|verifyonly] [packages...]" >&2
+    exit 2
+    ;;
+esac
+
+status=0
+for bin in "$OUT"/*; do
+    if ldd "$bin" > /dev/null 2>&1; then
+        echo "❌ $bin is dynamically linked:" >&2
+        ldd "$bin" >&2
+        status=1
+    else
+        echo "✅ $bin"
+    fi
+done
+exit $status
@@ -0,0 +1,39 @@
+#!/bin/bash
+# Builds the verify-only WebAssembly module of the Explorer and fails when
+# it outgrows its size budget, so a new dependency of core does not
+# silently add megabytes to every page load.
+#
+#   tools/scripts/build_wasm.sh
+#
+# Writes qlverify.wasm and the matching wasm_exec.js of the Go toolchain.
+# The module is built with the verifyonly tag (Go 1.27+): no cgo and no
+# signing code. Environment:
+#   OUT                  output directory (bin/wasm)
+#   WASM_BUDGET          maximum size in bytes (7 MiB)
+#   WASM_GZIP_BUDGET     maximum gzip -9 size in bytes, what browsers
+#                        download (2 MiB)
+set -euo pipefail
+
+OUT=${OUT:-bin/wasm}
+WASM_BUDGET=${WASM_BUDGET:-7340032}
+WASM_GZIP_BUDGET=${WASM_GZIP_BUDGET:-2097152}
+CORE=github.com/yourusername/quantum-ledger/core
+
+mkdir -p "$OUT"
+env CGO_ENABLED=0 GOOS=js GOARCH=wasm go build -tags verifyonly -trimpath -ldflags="-s -w" \
+    -o "$OUT/qlverify.wasm" "$CORE/cmd/qlverify-wasm"
+cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" "$OUT/"
+
+size=$(wc -c < "$OUT/qlverify.wasm")
+gzipped=$(gzip -9 -c "$OUT/qlverify.wasm" | wc -c)
+echo "qlverify.wasm: $size bytes, $gzipped gzipped"
+status=0
+if [ "$size" -gt "$WASM_BUDGET" ]; then
+    echo "❌ over the budget of $WASM_BUDGET bytes" >&2
+    status=1
+fi
+if [ "$gzipped" -gt "$WASM_GZIP_BUDGET" ]; then
+    echo "❌ over the gzip budget of $WASM_GZIP_BUDGET bytes" >&2
+    status=1
+fi
+exit $status
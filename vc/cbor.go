@@ -0,0 +1,140 @@
+package vc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The subset of CBOR (RFC 8949) needed for COSE_Sign1 with definite
+// lengths: unsigned integers, byte and text strings, arrays, maps with
+// unsigned integer keys and tags.
+
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborText  = 3
+	cborArray = 4
+	cborMap   = 5
+	cborTag   = 6
+)
+
+var errCBOR = errors.New("invalid or unsupported CBOR")
+
+func appendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= 0xff:
+		return append(b, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), n)
+}
+
+func appendBytes(b []byte, v []byte) []byte {
+	return append(appendHead(b, cborBytes, uint64(len(v))), v...)
+}
+
+func appendText(b []byte, v string) []byte {
+	return append(appendHead(b, cborText, uint64(len(v))), v...)
+}
+
+// cborDecoder reads values off a buffer
+type cborDecoder struct {
+	b []byte
+}
+
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if len(d.b) == 0 {
+		return 0, 0, errCBOR
+	}
+	major, info := d.b[0]>>5, d.b[0]&0x1f
+	d.b = d.b[1:]
+	size := 0
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, errCBOR
+	}
+	if len(d.b) < size {
+		return 0, 0, errCBOR
+	}
+	var n uint64
+	for _, c := range d.b[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.b = d.b[size:]
+	return major, n, nil
+}
+
+func (d *cborDecoder) expect(major byte) (uint64, error) {
+	m, n, err := d.head()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, fmt.Errorf("%w: major type %d, expected %d", errCBOR, m, major)
+	}
+	return n, nil
+}
+
+func (d *cborDecoder) bytes(major byte) ([]byte, error) {
+	n, err := d.expect(major)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.b)) < n {
+		return nil, errCBOR
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+// headerMap decodes a map of integer labels to text or byte string values
+func (d *cborDecoder) headerMap() (map[uint64]interface{}, error) {
+	n, err := d.expect(cborMap)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint64]interface{}, n)
+	for i := uint64(0); i < n; i++ {
+		label, err := d.expect(cborUint)
+		if err != nil {
+			return nil, err
+		}
+		if len(d.b) == 0 {
+			return nil, errCBOR
+		}
+		switch d.b[0] >> 5 {
+		case cborText:
+			v, err := d.bytes(cborText)
+			if err != nil {
+				return nil, err
+			}
+			m[label] = string(v)
+		case cborBytes:
+			v, err := d.bytes(cborBytes)
+			if err != nil {
+				return nil, err
+			}
+			m[label] = v
+		default:
+			return nil, fmt.Errorf("%w: header %d", errCBOR, label)
+		}
+	}
+	return m, nil
+}
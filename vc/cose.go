@@ -0,0 +1,101 @@
+package vc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// COSE header labels (RFC 9052) and VC-JOSE-COSE media types
+const (
+	coseAlg         = 1
+	coseContentType = 3
+	coseKid         = 4
+	coseTyp         = 16
+
+	coseSign1Tag     = 18
+	coseType         = "application/vc+cose"
+	coseContentVC    = "application/vc"
+	sigStructContext = "Signature1"
+)
+
+// SignCOSE secures credential, a JSON object whose issuer is i.DID, as a
+// tagged COSE_Sign1 with an empty unprotected header
+func (i *Issuer) SignCOSE(credential []byte) ([]byte, error) {
+	if err := checkIssuer(credential, i.DID); err != nil {
+		return nil, err
+	}
+	// Labels in ascending order, so the encoding is deterministic
+	protected := appendHead(nil, cborMap, 4)
+	protected = appendText(appendHead(protected, cborUint, coseAlg), Algorithm)
+	protected = appendText(appendHead(protected, cborUint, coseContentType), coseContentVC)
+	protected = appendBytes(appendHead(protected, cborUint, coseKid), []byte(i.DID))
+	protected = appendText(appendHead(protected, cborUint, coseTyp), coseType)
+
+	sig, err := core.SignMessage(i.Signer, sigStructure(protected, credential))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign credential: %w", err)
+	}
+	out := appendHead(nil, cborTag, coseSign1Tag)
+	out = appendHead(out, cborArray, 4)
+	out = appendBytes(out, protected)
+	out = appendHead(out, cborMap, 0)
+	out = appendBytes(out, credential)
+	return appendBytes(out, sig), nil
+}
+
+// VerifyCOSE checks a COSE_Sign1 issued by SignCOSE
+func VerifyCOSE(msg []byte, resolve Resolver) (*Verified, error) {
+	d := &cborDecoder{b: msg}
+	if len(d.b) > 0 && d.b[0]>>5 == cborTag {
+		if tag, err := d.expect(cborTag); err != nil || tag != coseSign1Tag {
+			return nil, errors.New("invalid COSE_Sign1: unexpected tag")
+		}
+	}
+	if n, err := d.expect(cborArray); err != nil || n != 4 {
+		return nil, errors.New("invalid COSE_Sign1: expected an array of four items")
+	}
+	protected, err := d.bytes(cborBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid COSE_Sign1 protected header: %w", err)
+	}
+	if _, err := d.headerMap(); err != nil {
+		return nil, fmt.Errorf("invalid COSE_Sign1 unprotected header: %w", err)
+	}
+	payload, err := d.bytes(cborBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid COSE_Sign1 payload: %w", err)
+	}
+	sig, err := d.bytes(cborBytes)
+	if err != nil || len(d.b) != 0 {
+		return nil, errors.New("invalid COSE_Sign1 signature")
+	}
+
+	header, err := (&cborDecoder{b: protected}).headerMap()
+	if err != nil {
+		return nil, fmt.Errorf("invalid COSE_Sign1 protected header: %w", err)
+	}
+	kid, _ := header[coseKid].([]byte)
+	if header[coseAlg] != Algorithm || header[coseTyp] != coseType || len(kid) == 0 {
+		return nil, fmt.Errorf("unsupported COSE alg %v typ %v", header[coseAlg], header[coseTyp])
+	}
+	issuer := string(kid)
+	if err := verify(resolve, issuer, sigStructure(protected, payload), sig); err != nil {
+		return nil, err
+	}
+	if err := checkIssuer(payload, issuer); err != nil {
+		return nil, err
+	}
+	return &Verified{Issuer: issuer, Credential: payload}, nil
+}
+
+// sigStructure is Sig_structure = ["Signature1", protected, external_aad, payload]
+// with an empty external_aad
+func sigStructure(protected, payload []byte) []byte {
+	b := appendHead(nil, cborArray, 4)
+	b = appendText(b, sigStructContext)
+	b = appendBytes(b, protected)
+	b = appendBytes(b, nil)
+	return appendBytes(b, payload)
+}
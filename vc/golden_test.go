@@ -0,0 +1,51 @@
+package vc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/did"
+	"github.com/yourusername/quantum-ledger/internal/golden"
+)
+
+// fixedSigner makes reproducible stand-in signatures, the golden files pin
+// the framing around them
+type fixedSigner struct{}
+
+func (fixedSigner) Sign(digest []byte) ([]byte, error) {
+	sum := sha256.Sum256(append([]byte("golden signature"), digest...))
+	return sum[:], nil
+}
+
+func (fixedSigner) Public() *core.PublicKey {
+	return nil
+}
+
+var errGoldenResolve = errors.New("golden files are not verified")
+
+// goldenResolve stops verification after parsing, which the stand-in
+// signatures would not pass
+func goldenResolve(string) (*did.Document, error) {
+	return nil, errGoldenResolve
+}
+
+func TestGoldenCredentials(t *testing.T) {
+	issuer := &Issuer{DID: "did:web:org1.example.com", Signer: fixedSigner{}}
+
+	token, err := issuer.SignJWT(credential(issuer.DID))
+	require.NoError(t, err)
+	golden.Assert(t, "credential.jwt", []byte(token+"\n"))
+	_, err = VerifyJWT(strings.TrimSpace(string(golden.Read(t, "credential.jwt"))), goldenResolve)
+	assert.ErrorIs(t, err, errGoldenResolve)
+
+	msg, err := issuer.SignCOSE(credential(issuer.DID))
+	require.NoError(t, err)
+	golden.Assert(t, "credential.cose", msg)
+	_, err = VerifyCOSE(golden.Read(t, "credential.cose"), goldenResolve)
+	assert.ErrorIs(t, err, errGoldenResolve)
+}
@@ -0,0 +1,87 @@
+package vc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/quantum-ledger/core"
+)
+
+// JWT media types of VC-JOSE-COSE
+const (
+	jwtType        = "vc+jwt"
+	jwtContentType = "vc"
+)
+
+// joseHeader is the protected header; kid is the issuer DID since both of
+// its verification methods are needed
+type joseHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+	Cty string `json:"cty"`
+}
+
+// SignJWT secures credential, a JSON object whose issuer is i.DID, as a
+// compact JWS
+func (i *Issuer) SignJWT(credential []byte) (string, error) {
+	if err := checkIssuer(credential, i.DID); err != nil {
+		return "", err
+	}
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, credential); err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(joseHeader{Alg: Algorithm, Kid: i.DID, Typ: jwtType, Cty: jwtContentType})
+	if err != nil {
+		return "", err
+	}
+	input := b64(header) + "." + b64(payload.Bytes())
+	sig, err := core.SignMessage(i.Signer, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign credential: %w", err)
+	}
+	return input + "." + b64(sig), nil
+}
+
+// VerifyJWT checks a compact JWS issued by SignJWT
+func VerifyJWT(token string, resolve Resolver) (*Verified, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid JWS: expected three parts")
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	var header joseHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	if header.Alg != Algorithm || header.Typ != jwtType {
+		return nil, fmt.Errorf("unsupported JWS alg %q typ %q", header.Alg, header.Typ)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS payload: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS signature: %w", err)
+	}
+	if err := verify(resolve, header.Kid, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+	if err := checkIssuer(payload, header.Kid); err != nil {
+		return nil, err
+	}
+	return &Verified{Issuer: header.Kid, Credential: payload}, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,95 @@
+// Package vc issues W3C Verifiable Credentials secured with hybrid
+// signatures, following VC-JOSE-COSE: a credential is the payload of a JWS
+// (application/vc+jwt) or of a COSE_Sign1 (application/vc+cose), signed with
+// the issuer's ECDSA P-256 + ML-DSA-65 key. The issuer is a DID whose
+// document carries both keys (package did); the algorithm is
+// ML-DSA-65-ES256 and the signature is a hybrid envelope over the SHA-256
+// of the signing input, so verifiers need both components to be valid.
+package vc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/did"
+)
+
+// Algorithm is the JOSE and COSE algorithm name of hybrid signatures
+const Algorithm = "ML-DSA-65-ES256"
+
+var (
+	// ErrInvalidSignature is returned when the hybrid signature does not verify
+	ErrInvalidSignature = errors.New("invalid credential signature")
+	// ErrIssuerMismatch is returned when the credential issuer is not the signer
+	ErrIssuerMismatch = errors.New("credential issuer does not match the signing key")
+)
+
+// Resolver returns the DID document of an issuer, e.g. did.Resolve with an
+// HTTPS fetcher
+type Resolver func(id string) (*did.Document, error)
+
+// Issuer signs credentials as DID
+type Issuer struct {
+	DID    string
+	Signer core.Signer
+}
+
+// Verified is a credential whose signature and issuer were checked
+type Verified struct {
+	Issuer     string
+	Credential json.RawMessage
+}
+
+// credentialIssuer extracts the issuer of a credential, a string or an
+// object with an id
+func credentialIssuer(credential []byte) (string, error) {
+	var c struct {
+		Issuer json.RawMessage `json:"issuer"`
+	}
+	if err := json.Unmarshal(credential, &c); err != nil {
+		return "", fmt.Errorf("credential is not a JSON object: %w", err)
+	}
+	var id string
+	if err := json.Unmarshal(c.Issuer, &id); err == nil {
+		return id, nil
+	}
+	var obj struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(c.Issuer, &obj); err != nil || obj.ID == "" {
+		return "", errors.New("credential has no issuer")
+	}
+	return obj.ID, nil
+}
+
+// checkIssuer makes sure the issuer signs its own credentials
+func checkIssuer(credential []byte, issuer string) error {
+	id, err := credentialIssuer(credential)
+	if err != nil {
+		return err
+	}
+	if id != issuer {
+		return fmt.Errorf("%w: %s signed by %s", ErrIssuerMismatch, id, issuer)
+	}
+	return nil
+}
+
+// verify checks signature over input with the keys of issuer
+func verify(resolve Resolver, issuer string, input, signature []byte) error {
+	doc, err := resolve(issuer)
+	if err != nil {
+		return fmt.Errorf("failed to resolve issuer %s: %w", issuer, err)
+	}
+	digest := sha256.Sum256(input)
+	valid, err := doc.Verify(digest[:], signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
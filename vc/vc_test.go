@@ -0,0 +1,88 @@
+package vc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/quantum-ledger/core"
+	"github.com/yourusername/quantum-ledger/did"
+)
+
+func resolve(id string) (*did.Document, error) {
+	return did.Resolve(id, nil)
+}
+
+func newIssuer(t *testing.T) *Issuer {
+	key, err := core.GenerateKey()
+	require.NoError(t, err)
+	t.Cleanup(key.Clean)
+	id, err := did.KeyDID(key.Public())
+	require.NoError(t, err)
+	return &Issuer{DID: id, Signer: key}
+}
+
+func credential(issuer string) []byte {
+	return []byte(`{
+		"@context": ["https://www.w3.org/ns/credentials/v2"],
+		"type": ["VerifiableCredential"],
+		"issuer": {"id": "` + issuer + `", "name": "Org1"},
+		"credentialSubject": {"id": "did:web:peer0.org1.example.com", "role": "endorser"}
+	}`)
+}
+
+func TestJWT(t *testing.T) {
+	issuer := newIssuer(t)
+	token, err := issuer.SignJWT(credential(issuer.DID))
+	require.NoError(t, err)
+
+	verified, err := VerifyJWT(token, resolve)
+	require.NoError(t, err)
+	assert.Equal(t, issuer.DID, verified.Issuer)
+	assert.True(t, json.Valid(verified.Credential))
+
+	parts := strings.Split(token, ".")
+	other, err := issuer.SignJWT([]byte(`{"issuer":"` + issuer.DID + `","credentialSubject":{"role":"admin"}}`))
+	require.NoError(t, err)
+	forged := parts[0] + "." + strings.Split(other, ".")[1] + "." + parts[2]
+	_, err = VerifyJWT(forged, resolve)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestCOSE(t *testing.T) {
+	issuer := newIssuer(t)
+	msg, err := issuer.SignCOSE(credential(issuer.DID))
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xd2), msg[0]) // tag 18
+
+	verified, err := VerifyCOSE(msg, resolve)
+	require.NoError(t, err)
+	assert.Equal(t, issuer.DID, verified.Issuer)
+	assert.Equal(t, credential(issuer.DID), []byte(verified.Credential))
+
+	// Flip a byte of the payload
+	i := strings.Index(string(msg), "endorser")
+	tampered := append([]byte(nil), msg...)
+	tampered[i] ^= 1
+	_, err = VerifyCOSE(tampered, resolve)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestIssuerMismatch(t *testing.T) {
+	issuer, other := newIssuer(t), newIssuer(t)
+	_, err := issuer.SignJWT(credential(other.DID))
+	assert.ErrorIs(t, err, ErrIssuerMismatch)
+
+	// Claiming another DID in the header fails with its keys
+	impostor := &Issuer{DID: other.DID, Signer: issuer.Signer}
+	token, err := impostor.SignJWT(credential(other.DID))
+	require.NoError(t, err)
+	_, err = VerifyJWT(token, resolve)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	msg, err := impostor.SignCOSE(credential(other.DID))
+	require.NoError(t, err)
+	_, err = VerifyCOSE(msg, resolve)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}